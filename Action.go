@@ -0,0 +1,121 @@
+package goatar
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// Action is an action that can be taken in a GoAtar game. Each Action
+// has the same integer value in every game, and every game uses these
+// actions internally.
+type Action = game.Action
+
+const (
+	NoOp  Action = game.NoOp
+	Left  Action = game.Left
+	Up    Action = game.Up
+	Right Action = game.Right
+	Down  Action = game.Down
+	Fire  Action = game.Fire
+)
+
+// ParseAction returns the Action with the given name, such as "Left"
+// or "NoOp". Names are matched ignoring case.
+func ParseAction(name string) (Action, error) {
+	for _, a := range game.Actions {
+		if strings.EqualFold(a.String(), name) {
+			return a, nil
+		}
+	}
+	return -1, fmt.Errorf("parseAction: no such action %v", name)
+}
+
+// WithActionSet returns an Option which restricts and reorders the
+// actions available in an Environment. Once set, action i passed to
+// Act refers to actions[i], and NumActions returns len(actions).
+// Actions may not be repeated.
+func WithActionSet(actions []Action) Option {
+	return func(e *Environment) error {
+		if len(actions) == 0 {
+			return fmt.Errorf("withActionSet: no actions")
+		}
+
+		seen := make(map[Action]bool, len(actions))
+		for _, a := range actions {
+			if a < 0 || int(a) >= NumActions {
+				return fmt.Errorf("withActionSet: invalid action %v", a)
+			}
+			if seen[a] {
+				return fmt.Errorf("withActionSet: repeated action %v", a)
+			}
+			seen[a] = true
+		}
+
+		e.actionSet = append([]Action(nil), actions...)
+		return nil
+	}
+}
+
+// ActionSet returns the actions available in the Environment, where
+// element i of the returned slice is the Action taken when i is passed
+// to Act.
+func (e *Environment) ActionSet() []Action {
+	if e.actionSet != nil {
+		return append([]Action(nil), e.actionSet...)
+	}
+
+	actions := make([]Action, NumActions)
+	for i := range actions {
+		actions[i] = Action(i)
+	}
+	return actions
+}
+
+// gameAction returns the game action corresponding to the Environment
+// action a
+func (e *Environment) gameAction(a int) (int, error) {
+	if a < 0 || a >= e.NumActions() {
+		return -1, fmt.Errorf("invalid action %v ∉ [0, %v)", a,
+			e.NumActions())
+	}
+
+	if e.actionSet == nil {
+		return a, nil
+	}
+	return int(e.actionSet[a]), nil
+}
+
+// ActionEffect returns a description of what action a does in the
+// current game. If the Environment was created with a custom action
+// set, a indexes into that action set.
+func (e *Environment) ActionEffect(a int) string {
+	action, err := e.gameAction(a)
+	if err != nil || action < 0 || action >= NumActions {
+		return "invalid action"
+	}
+	return e.Game.ActionEffect(Action(action))
+}
+
+// SampleAction returns an action which can be passed to Act, drawn
+// uniformly at random from all actions of the Environment using rng.
+// If the Environment was created with a custom action set, the action
+// indexes into that action set.
+func (e *Environment) SampleAction(rng *rand.Rand) int {
+	return rng.Intn(e.NumActions())
+}
+
+// SampleMinimalAction returns an action which can be passed to Act,
+// drawn uniformly at random from the minimal action set using rng, so
+// that actions without an effect are never sampled. If no action of a
+// custom action set has an effect, an action is drawn from all actions
+// instead.
+func (e *Environment) SampleMinimalAction(rng *rand.Rand) int {
+	minimal := e.MinimalActionSet()
+	if len(minimal) == 0 {
+		return e.SampleAction(rng)
+	}
+	return minimal[rng.Intn(len(minimal))]
+}
@@ -0,0 +1,171 @@
+package goatar
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// minAtarMinimalActionSets are the minimal action sets used by MinAtar
+var minAtarMinimalActionSets = map[GameName][]Action{
+	Asterix:       {NoOp, Left, Up, Right, Down},
+	Breakout:      {NoOp, Left, Right},
+	Freeway:       {NoOp, Up, Down},
+	SeaQuest:      {NoOp, Left, Up, Right, Down, Fire},
+	SpaceInvaders: {NoOp, Left, Right, Fire},
+}
+
+func TestMinimalActionSet(t *testing.T) {
+	for _, g := range Games() {
+		env, err := New(g, 0.0, true, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var want []int
+		for _, a := range minAtarMinimalActionSets[g] {
+			want = append(want, int(a))
+		}
+		if got := env.MinimalActionSet(); !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: minimal action set %v, want %v", g, got, want)
+		}
+	}
+}
+
+func TestFullActionSet(t *testing.T) {
+	env, err := New(Breakout, 0.0, true, 1,
+		WithActionSet([]Action{Fire, Left, Right}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := env.FullActionSet(), []int{0, 1, 2}; !reflect.DeepEqual(
+		got, want) {
+		t.Errorf("full action set %v, want %v", got, want)
+	}
+	if got, want := env.MinimalActionSet(), []int{1, 2}; !reflect.DeepEqual(
+		got, want) {
+		t.Errorf("minimal action set %v, want %v", got, want)
+	}
+}
+
+// TestNonMinimalActions checks that actions outside of the minimal
+// action set of each game never have an effect, by verifying that they
+// always lead to the same outcome as NoOp.
+func TestNonMinimalActions(t *testing.T) {
+	const steps = 5000
+
+	for _, g := range Games() {
+		env, err := New(g, 0.0, true, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		minimal := make(map[int]bool)
+		for _, a := range env.MinimalActionSet() {
+			minimal[a] = true
+		}
+		for _, a := range env.FullActionSet() {
+			if !minimal[a] {
+				checkEquivalent(t, g, env.MinimalActionSet(), a, int(NoOp),
+					steps)
+			}
+		}
+	}
+}
+
+// checkEquivalent checks that actions a and b have the same outcome in
+// game g. Two copies of the game are run in lockstep: on even steps
+// both take the same random action from actions, and on odd steps one
+// takes a and the other takes b, after which their states must match.
+func checkEquivalent(t *testing.T, g GameName, actions []int, a, b,
+	steps int) {
+	t.Helper()
+
+	envA, err := New(g, 0.0, true, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envB, err := New(g, 0.0, true, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < steps; i++ {
+		actA, actB := a, b
+		if i%2 == 0 {
+			actA = actions[rng.Intn(len(actions))]
+			actB = actA
+		}
+
+		rA, doneA, err := envA.Act(actA)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rB, doneB, err := envB.Act(actB)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stateA, err := envA.State()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stateB, err := envB.State()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rA != rB || doneA != doneB || envA.StateHash() != envB.StateHash() ||
+			!reflect.DeepEqual(stateA, stateB) {
+			t.Fatalf("%v: actions %v and %v differ at step %v", g, Action(a),
+				Action(b), i)
+		}
+
+		if doneA {
+			envA.Reset()
+			envB.Reset()
+		}
+	}
+}
+
+// TestSampleAction checks that sampled actions are valid, and that
+// minimal actions are drawn from the minimal action set, including
+// with a custom action set
+func TestSampleAction(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	for _, g := range Games() {
+		for _, opts := range [][]Option{
+			nil,
+			{WithActionSet([]Action{Fire, Down, NoOp})},
+		} {
+			env, err := New(g, 0, false, 0, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			minimal := make(map[int]bool)
+			for _, a := range env.MinimalActionSet() {
+				minimal[a] = true
+			}
+
+			seen := make(map[int]bool)
+			for i := 0; i < 200; i++ {
+				if a := env.SampleAction(rng); a < 0 ||
+					a >= env.NumActions() {
+					t.Fatalf("%v: sampled invalid action %v", g, a)
+				}
+				a := env.SampleMinimalAction(rng)
+				if !minimal[a] {
+					t.Fatalf("%v: sampled action %v outside the minimal "+
+						"action set %v", g, a, env.MinimalActionSet())
+				}
+				seen[a] = true
+			}
+			if len(seen) != len(minimal) {
+				t.Errorf("%v: sampled %v of %v minimal actions", g,
+					len(seen), len(minimal))
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package goatar
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// SpawnPosition is a position at which an enemy can spawn, chosen by
+// an adversary. Enemies moving right spawn at the left edge of the
+// screen in row Row, and enemies moving left spawn at the right edge.
+type SpawnPosition = game.SpawnPosition
+
+// MultiActor is implemented by environments which are stepped by two
+// players: the agent, which acts as in Act, and an adversary, which
+// chooses where enemies spawn. The game still decides when enemies
+// spawn and what kind of enemy spawns, and the adversary only acts on
+// the steps on which an enemy spawns. The game is zero-sum, so the
+// adversary's reward is the negation of the agent's reward.
+//
+// Adversaries are supported by Asterix, in which the adversary chooses
+// where both enemies and gold spawn, and SeaQuest, in which divers
+// still spawn randomly.
+type MultiActor interface {
+	// SpawnDue returns whether an enemy spawns on the next step, so
+	// that the adversary's action is used
+	SpawnDue() bool
+
+	// SpawnPositions returns the legal actions of the adversary on the
+	// next step, which are the positions at which the enemy can spawn.
+	// It is nil if no enemy is due to spawn, and empty if an enemy is
+	// due but there is no room for it.
+	SpawnPositions() []SpawnPosition
+
+	// ActMulti takes one environmental step with the agent's action
+	// and the adversary's action, and returns the agent's reward and
+	// whether the episode ended
+	ActMulti(agent int, adversary SpawnPosition) (float64, bool, error)
+}
+
+// SpawnDue returns whether an enemy spawns on the next step, so that
+// the adversary's action passed to ActMulti is used. It is always
+// false for games which do not support adversaries.
+func (e *Environment) SpawnDue() bool {
+	g, ok := e.Game.(game.Adversarial)
+	return ok && g.SpawnDue()
+}
+
+// SpawnPositions returns the legal actions of the adversary on the
+// next step, see MultiActor
+func (e *Environment) SpawnPositions() []SpawnPosition {
+	if g, ok := e.Game.(game.Adversarial); ok {
+		return g.SpawnPositions()
+	}
+	return nil
+}
+
+// ActMulti takes one environmental step in which the agent takes
+// action agent, as in Act, and an enemy due to spawn spawns at the
+// position chosen by the adversary. The adversary's action is ignored
+// if no enemy is due to spawn or there is no room for the enemy, and
+// must otherwise be one of SpawnPositions. Enemies spawned by Act
+// rather than ActMulti spawn at random positions.
+func (e *Environment) ActMulti(agent int, adversary SpawnPosition) (float64,
+	bool, error) {
+	g, ok := e.Game.(game.Adversarial)
+	if !ok {
+		return -1, false, fmt.Errorf("actMulti: adversaries are not "+
+			"supported by %v", e.gameName)
+	}
+
+	if len(g.SpawnPositions()) > 0 {
+		if err := g.SetSpawn(adversary); err != nil {
+			return -1, false, fmt.Errorf("actMulti: %v", err)
+		}
+	}
+
+	reward, done, err := e.Act(agent)
+	if err != nil {
+		return reward, done, fmt.Errorf("actMulti: %v", err)
+	}
+	return reward, done, nil
+}
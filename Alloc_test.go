@@ -0,0 +1,72 @@
+package goatar
+
+import (
+	"runtime"
+	"testing"
+)
+
+// allocWarmup is the number of steps taken before allocations are
+// counted, so that entity pools and slices reach a steady state
+const allocWarmup = 10000
+
+// step takes the i-th step of a fixed action sequence in env,
+// resetting env at the end of each episode
+func step(tb testing.TB, env *Environment, i int) {
+	_, done, err := env.Act((i*7 + i/5) % NumActions)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if done {
+		env.Reset()
+	}
+}
+
+func TestActAllocations(t *testing.T) {
+	for _, g := range Games() {
+		t.Run(g.String(), func(t *testing.T) {
+			env, err := New(g, 0.1, true, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < allocWarmup; i++ {
+				step(t, env, i)
+			}
+
+			// Count the total allocations rather than using
+			// testing.AllocsPerRun, which rounds down the mean number
+			// of allocations per step
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			for i := allocWarmup; i < 2*allocWarmup; i++ {
+				step(t, env, i)
+			}
+			runtime.ReadMemStats(&after)
+
+			if allocs := after.Mallocs - before.Mallocs; allocs != 0 {
+				t.Errorf("expected no allocations in %v steps but got %v",
+					allocWarmup, allocs)
+			}
+		})
+	}
+}
+
+func BenchmarkAct(b *testing.B) {
+	for _, g := range Games() {
+		b.Run(g.String(), func(b *testing.B) {
+			env, err := New(g, 0.1, true, 1)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for i := 0; i < allocWarmup; i++ {
+				step(b, env, i)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				step(b, env, i)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package goatar
+
+import "fmt"
+
+// WithBulletTrails returns an Option which adds an "enemy_bullet_trail"
+// channel to state observations, after the other channels. The channel
+// is active in the cell behind each enemy bullet, so that the
+// direction in which bullets move can be read from a single
+// observation, as the trail channels already allow for enemies. This
+// option can only be used with SpaceInvaders and SeaQuest.
+func WithBulletTrails() Option {
+	return func(e *Environment) error {
+		switch e.gameName {
+		case SpaceInvaders:
+			e.gameConfig.spaceInvaders.BulletTrails = true
+		case SeaQuest:
+			e.gameConfig.seaQuest.BulletTrails = true
+		default:
+			return fmt.Errorf("withBulletTrails: bullet trails are not "+
+				"supported by %v", e.gameName)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,210 @@
+package goatar
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// stateHash returns a hash of a state observation
+func stateHash(state []float64) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, v := range state {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+		h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+// mutation is a path through which the state of an Environment is
+// changed
+type mutation struct {
+	name  string
+	apply func(t *testing.T, env *Environment, rng *rand.Rand)
+}
+
+// mutations are the mutation paths exercised by TestStateCache. Each
+// must invalidate the cached state observation of the game.
+var mutations = []mutation{
+	{"act", func(t *testing.T, env *Environment, rng *rand.Rand) {
+		if _, done, err := env.Act(rng.Intn(NumActions)); err != nil {
+			t.Fatal(err)
+		} else if done && rng.Intn(4) != 0 {
+			env.Reset()
+		}
+	}},
+	{"reset", func(t *testing.T, env *Environment, rng *rand.Rand) {
+		env.Reset()
+	}},
+	{"seed", func(t *testing.T, env *Environment, rng *rand.Rand) {
+		env.Seed(rng.Int63())
+		env.Reset()
+	}},
+	{"undo", func(t *testing.T, env *Environment, rng *rand.Rand) {
+		if env.UndoSteps() > 0 {
+			if err := env.Undo(1); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}},
+	{"restore", func(t *testing.T, env *Environment, rng *rand.Rand) {
+		s := env.Snapshot()
+		for i := 0; i < rng.Intn(5); i++ {
+			if _, done, err := env.Act(rng.Intn(NumActions)); err != nil {
+				t.Fatal(err)
+			} else if done {
+				break
+			}
+		}
+		if err := env.Restore(s); err != nil {
+			t.Fatal(err)
+		}
+	}},
+	{"set param", func(t *testing.T, env *Environment, rng *rand.Rand) {
+		params := env.Params()
+		name := params[rng.Intn(len(params))]
+		value, err := env.Param(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Invalid values are rejected, which must not corrupt the
+		// cache either
+		env.SetParam(name, math.Max(0, value+float64(rng.Intn(3)-1)))
+	}},
+	{"decode", func(t *testing.T, env *Environment, rng *rand.Rand) {
+		en, err := env.Enumerate()
+		if err != nil {
+			return
+		}
+
+		// Decode the state reached after a few random steps, then
+		// return to the current state through Decode. States outside
+		// the enumerable state space cannot be encoded and are skipped.
+		code, err := en.Encode()
+		if err != nil {
+			return
+		}
+		for i := 0; i < rng.Intn(5)+1; i++ {
+			if _, done, err := env.Act(rng.Intn(NumActions)); err != nil {
+				t.Fatal(err)
+			} else if done {
+				break
+			}
+		}
+		next, err := en.Encode()
+		if err != nil {
+			next = code
+		}
+		if err := en.Decode(code); err != nil {
+			t.Fatal(err)
+		}
+		if err := en.Decode(new(big.Int).Set(next)); err != nil {
+			t.Fatal(err)
+		}
+	}},
+}
+
+// TestStateCache checks that the cached state observation of each game
+// is never stale. The state observation is computed, and so cached,
+// before each mutation, and the observation after the mutation is
+// compared to that of a clone of the game, which has an empty cache.
+func TestStateCache(t *testing.T) {
+	for _, g := range Games() {
+		t.Run(g.String(), func(t *testing.T) {
+			env, err := New(g, 0.1, true, 3, WithUndo(3))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rng := rand.New(rand.NewSource(3))
+
+			for i := 0; i < 2000; i++ {
+				before, err := env.State()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				m := mutations[rng.Intn(len(mutations))]
+				m.apply(t, env, rng)
+
+				cached, err := env.State()
+				if err != nil {
+					t.Fatal(err)
+				}
+				fresh, err := env.Game.Clone().State()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if stateHash(cached) != stateHash(fresh) {
+					t.Fatalf("step %v: stale state observation after %v",
+						i, m.name)
+				}
+
+				// Modifying an observation returned before the mutation
+				// must not modify the cached observation
+				for j := range before {
+					before[j] = 2
+				}
+				after, err := env.State()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if stateHash(after) != stateHash(cached) {
+					t.Fatalf("step %v: cached state observation modified "+
+						"after %v", i, m.name)
+				}
+			}
+		})
+	}
+}
+
+// TestStateCacheCopies checks that modifying a returned state
+// observation or channel does not modify the cached observation
+func TestStateCacheCopies(t *testing.T) {
+	for _, g := range Games() {
+		t.Run(g.String(), func(t *testing.T) {
+			env, err := New(g, 0.1, true, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 50; i++ {
+				if _, done, err := env.Act(i % NumActions); err != nil {
+					t.Fatal(err)
+				} else if done {
+					env.Reset()
+				}
+			}
+
+			state, err := env.State()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := stateHash(state)
+			for i := range state {
+				state[i] = 2
+			}
+			for i := 0; i < env.NChannels(); i++ {
+				ch, err := env.Channel(i)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for j := range ch {
+					ch[j] = 2
+				}
+			}
+
+			state, err = env.State()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := stateHash(state); got != want {
+				t.Errorf("state observation modified through returned " +
+					"slices")
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package goatar
+
+// StepInfo describes a step taken in an Environment. It is passed to
+// the callbacks registered with OnStep.
+type StepInfo struct {
+	Episode int // Index of the episode in which the step was taken
+	Step    int // Index of the step in the episode
+
+	// Action is the action passed to Act, while GameAction is the
+	// action executed by the game, after the custom action set and
+	// sticky actions have been applied
+	Action     int
+	GameAction Action
+
+	Reward float64
+	Done   bool
+	Info   Info
+}
+
+// stepCallback is a callback registered with OnStep
+type stepCallback struct {
+	id int
+	f  func(StepInfo)
+}
+
+// OnStep registers f to be called after every step taken with Act,
+// once the step has been fully processed. Callbacks are called
+// synchronously, in the order in which they were registered, so they
+// can be used to attach loggers, renderers, or assertions to an
+// Environment without wrapping it. The returned function removes the
+// callback.
+//
+// Callbacks may call methods of the Environment, such as State or
+// SetParam, but must not call Act or Reset.
+func (e *Environment) OnStep(f func(StepInfo)) (remove func()) {
+	id := e.callbackID
+	e.callbackID++
+	e.callbacks = append(e.callbacks, stepCallback{id: id, f: f})
+
+	return func() {
+		// Copy the callbacks so that removing a callback from within a
+		// callback does not affect the callbacks currently being run
+		callbacks := make([]stepCallback, 0, len(e.callbacks))
+		for _, c := range e.callbacks {
+			if c.id != id {
+				callbacks = append(callbacks, c)
+			}
+		}
+		e.callbacks = callbacks
+	}
+}
+
+// runStepCallbacks calls the callbacks registered with OnStep for the
+// step which has just been taken
+func (e *Environment) runStepCallbacks(action, gameAction int,
+	reward float64, done bool) {
+	if len(e.callbacks) == 0 {
+		return
+	}
+
+	step := StepInfo{
+		Episode:    e.episode,
+		Step:       e.episodeSteps - 1,
+		Action:     action,
+		GameAction: Action(gameAction),
+		Reward:     reward,
+		Done:       done,
+		Info:       e.Info(),
+	}
+	for _, c := range e.callbacks {
+		c.f(step)
+	}
+}
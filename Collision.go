@@ -0,0 +1,19 @@
+package goatar
+
+import "fmt"
+
+// WithSweptCollisions returns an Option which makes objects which pass
+// through each other in a single step collide, in addition to objects
+// which end the step in the same cell. This makes collisions
+// independent of the order in which a game updates its objects. Swept
+// collisions are currently only supported by SeaQuest.
+func WithSweptCollisions() Option {
+	return func(e *Environment) error {
+		if e.gameName != SeaQuest {
+			return fmt.Errorf("withSweptCollisions: swept collisions are "+
+				"not supported by %v", e.gameName)
+		}
+		e.gameConfig.seaQuest.SweptCollisions = true
+		return nil
+	}
+}
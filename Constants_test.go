@@ -0,0 +1,113 @@
+package goatar
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// minAtarConstants are the constants of the dynamics of each game in
+// MinAtar. The intervals at which difficulty ramping stops are not
+// named in MinAtar, but are hard-coded in the ramping conditions of
+// each game.
+var minAtarConstants = map[GameName]map[string]int{
+	Asterix: {
+		"ramp_interval":      100,
+		"init_spawn_speed":   10,
+		"init_move_interval": 5,
+		"shot_cool_down":     5,
+		"max_entities":       8,
+		"min_spawn_speed":    1,
+		"min_move_interval":  1,
+	},
+	SeaQuest: {
+		"ramp_interval":       100,
+		"max_oxygen":          200,
+		"max_divers":          6,
+		"init_spawn_speed":    20,
+		"diver_spawn_speed":   30,
+		"init_move_interval":  5,
+		"shot_cool_down":      5,
+		"enemy_shot_interval": 10,
+		"enemy_move_interval": 5,
+		"diver_move_interval": 5,
+		"min_spawn_speed":     1,
+		"min_move_interval":   2,
+	},
+	SpaceInvaders: {
+		"shot_cool_down":      5,
+		"enemy_move_interval": 12,
+		"enemy_shot_interval": 10,
+		"min_move_interval":   6,
+	},
+}
+
+// knownDeviations are the constants which intentionally differ from
+// MinAtar by default, and their default values
+var knownDeviations = map[GameName]map[string]int{
+	// Aliens keep speeding up until they move on every step, see
+	// SpaceInvadersConfig.MinMoveInterval
+	SpaceInvaders: {"min_move_interval": 0},
+}
+
+// TestGameConstants checks that the constants of each game match those
+// of MinAtar, other than the known deviations
+func TestGameConstants(t *testing.T) {
+	for _, g := range Games() {
+		env, err := New(g, 0.1, true, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := env.GameConstants()
+		want, hasWant := minAtarConstants[g]
+		if ok != hasWant {
+			t.Fatalf("%v: got constants %v, want %v", g, ok, hasWant)
+		}
+
+		for name, v := range want {
+			if d, ok := knownDeviations[g][name]; ok {
+				v = d
+			}
+			if c, ok := got[name]; !ok {
+				t.Errorf("%v: missing constant %v", g, name)
+			} else if c != v {
+				t.Errorf("%v: got %v = %v, want %v", g, name, c, v)
+			}
+		}
+		for name := range got {
+			if _, ok := want[name]; !ok {
+				t.Errorf("%v: constant %v has no MinAtar reference", g,
+					name)
+			}
+		}
+	}
+}
+
+// TestMinMoveInterval checks that the deviating minimum move interval
+// of SpaceInvaders can be configured, and that only valid intervals are
+// accepted
+func TestMinMoveInterval(t *testing.T) {
+	env, err := New(SpaceInvaders, 0.1, true, 1,
+		WithSpaceInvadersConfig(SpaceInvadersConfig{MinMoveInterval: 6}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := env.GameConstants()
+	for name, want := range minAtarConstants[SpaceInvaders] {
+		if got[name] != want {
+			t.Errorf("got %v = %v, want %v", name, got[name], want)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		interval := rng.Intn(40) - 20
+		_, err := New(SpaceInvaders, 0.1, true, 1,
+			WithSpaceInvadersConfig(SpaceInvadersConfig{
+				MinMoveInterval: interval,
+			}))
+		if valid := interval >= 0 && interval <= 12; valid != (err == nil) {
+			t.Errorf("minimum move interval %v: got error %v", interval,
+				err)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package goatar
+
+import (
+	"context"
+	"fmt"
+)
+
+// ActCtx takes one environmental step given some action a, as with Act,
+// unless ctx is done. A single step is never interrupted, so ctx is
+// only checked before stepping; if it is done, the environment is left
+// unchanged and the returned error wraps ctx.Err(), so that it can be
+// matched with errors.Is against context.Canceled or
+// context.DeadlineExceeded.
+func (e *Environment) ActCtx(ctx context.Context, a int) (float64, bool,
+	error) {
+	if err := ctx.Err(); err != nil {
+		return 0, false, fmt.Errorf("actCtx: %w", err)
+	}
+
+	reward, done, err := e.Act(a)
+	if err != nil {
+		return reward, done, fmt.Errorf("actCtx: %v", err)
+	}
+	return reward, done, nil
+}
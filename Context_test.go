@@ -0,0 +1,43 @@
+package goatar
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestActCtx checks that ActCtx steps as Act does until its context is
+// cancelled, after which the environment is left unchanged
+func TestActCtx(t *testing.T) {
+	env, err := New(Breakout, 0, false, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	twin, err := New(Breakout, 0, false, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 20; i++ {
+		a := i % env.NumActions()
+		r, done, err := env.ActCtx(ctx, a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rTwin, doneTwin, _ := twin.Act(a)
+		if r != rTwin || done != doneTwin {
+			t.Fatalf("step %v: got (%v, %v), want (%v, %v)", i, r, done,
+				rTwin, doneTwin)
+		}
+	}
+
+	cancel()
+	hash := env.StateHash()
+	if _, _, err := env.ActCtx(ctx, 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	if env.StateHash() != hash {
+		t.Fatal("cancelled step changed the state")
+	}
+}
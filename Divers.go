@@ -0,0 +1,19 @@
+package goatar
+
+import "fmt"
+
+// WithShootableDivers returns an Option which makes friendly bullets
+// hit divers in SeaQuest. A diver which is shot is removed along with
+// the bullet, and gives a reward of -1, which turns SeaQuest into a
+// cost-sensitive shooting task for safe reinforcement learning. This
+// option can only be used with SeaQuest.
+func WithShootableDivers() Option {
+	return func(e *Environment) error {
+		if e.gameName != SeaQuest {
+			return fmt.Errorf("withShootableDivers: shootable divers are "+
+				"not supported by %v", e.gameName)
+		}
+		e.gameConfig.seaQuest.ShootableDivers = true
+		return nil
+	}
+}
@@ -0,0 +1,31 @@
+package goatar
+
+import "fmt"
+
+// WithDriftingFish returns an Option which makes a fraction ∈ [0, 1]
+// of the enemy fish in SeaQuest drift vertically as well as
+// horizontally, which gives the game richer dynamics than MinAtar. A
+// drifting fish moves one row up or down every interval times it
+// moves, bouncing between the highest and lowest rows in which enemies
+// spawn, and its trail is always at its previous position. If interval
+// is 0, drifting fish drift every 2 moves. This option can only be
+// used with SeaQuest.
+func WithDriftingFish(fraction float64, interval int) Option {
+	return func(e *Environment) error {
+		if e.gameName != SeaQuest {
+			return fmt.Errorf("withDriftingFish: drifting fish are not "+
+				"supported by %v", e.gameName)
+		}
+		if fraction < 0 || fraction > 1 {
+			return fmt.Errorf("withDriftingFish: fraction %v ∉ [0, 1]",
+				fraction)
+		}
+		if interval < 0 {
+			return fmt.Errorf("withDriftingFish: interval must be "+
+				"non-negative but got %v", interval)
+		}
+		e.gameConfig.seaQuest.DriftingFish = fraction
+		e.gameConfig.seaQuest.DriftInterval = interval
+		return nil
+	}
+}
@@ -0,0 +1,77 @@
+package goatar
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// Enumerator exposes the underlying state of a game as an integer
+// encoding, for use in tabular reinforcement learning and exact
+// dynamic programming. Enumerators are available for Breakout and
+// Freeway.
+//
+// Every state has a unique encoding in [0, NumStates()), although not
+// every integer in that range encodes a reachable state. Breakout
+// encodings always fit in a uint64.
+type Enumerator struct {
+	env     *Environment
+	game    game.Enumerable
+	sampler game.Game // Copy of the game from which transitions are sampled
+}
+
+// Enumerate returns an Enumerator for the game played in the
+// Environment. An error is returned if the game cannot be enumerated.
+func (e *Environment) Enumerate() (*Enumerator, error) {
+	g, ok := e.Game.(game.Enumerable)
+	if !ok {
+		return nil, fmt.Errorf("enumerate: %v cannot be enumerated",
+			e.gameName)
+	}
+
+	return &Enumerator{env: e, game: g, sampler: e.Game.Clone()}, nil
+}
+
+// Encode returns the encoding of the current state of the Environment
+func (en *Enumerator) Encode() (*big.Int, error) {
+	return en.game.Encode()
+}
+
+// Decode sets the state of the Environment to the state encoded by
+// code
+func (en *Enumerator) Decode(code *big.Int) error {
+	return en.game.Decode(code)
+}
+
+// NumStates returns the number of encodable states
+func (en *Enumerator) NumStates() *big.Int {
+	return en.game.NumStates()
+}
+
+// Sample samples a transition from the state encoded by code given
+// action, and returns the encoding of the next state, the reward, and
+// whether the next state is terminal. Transitions are sampled without
+// sticky actions from a copy of the game made when the Enumerator was
+// created, whose random number generator advances with each sample, so
+// the Environment, including its random number generators, is left
+// unchanged.
+func (en *Enumerator) Sample(code *big.Int, action int) (*big.Int, float64,
+	bool, error) {
+	g := en.sampler.(game.Enumerable)
+	if err := g.Decode(code); err != nil {
+		return nil, 0, false, fmt.Errorf("sample: %v", err)
+	}
+
+	reward, done, err := en.sampler.Act(action)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("sample: %v", err)
+	}
+
+	next, err := g.Encode()
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("sample: %v", err)
+	}
+
+	return next, reward, done, nil
+}
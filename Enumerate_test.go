@@ -0,0 +1,63 @@
+package goatar
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// TestEnumeratorSample checks that sampling transitions which draw
+// random numbers leaves the Environment unchanged, by comparing its
+// trajectory with that of an identical Environment from which no
+// transitions are sampled
+func TestEnumeratorSample(t *testing.T) {
+	newEnv := func() (*Environment, *Enumerator) {
+		env, err := New(Freeway, 0.1, true, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		en, err := env.Enumerate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return env, en
+	}
+
+	// Find a state from which moving up reaches the top of the screen,
+	// after which the speeds of the cars are drawn at random
+	scout, en := newEnv()
+	var top *big.Int
+	for top == nil {
+		code, err := en.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		reward, done, err := scout.Act(int(Up))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reward > 0 {
+			top = code
+		} else if done {
+			t.Fatal("episode ended before reaching the top")
+		}
+	}
+
+	// The speeds of the cars are drawn again on reset
+	env, _ := newEnv()
+	env.Reset()
+	want := trajectory(t, env, 100)
+
+	env, en = newEnv()
+	for i := 0; i < 10; i++ {
+		if _, reward, _, err := en.Sample(top, int(Up)); err != nil {
+			t.Fatal(err)
+		} else if reward != 1 {
+			t.Fatalf("got reward %v reaching the top, want 1", reward)
+		}
+	}
+	env.Reset()
+	if got := trajectory(t, env, 100); !reflect.DeepEqual(got, want) {
+		t.Error("trajectory differs after sampling")
+	}
+}
@@ -5,40 +5,26 @@ package goatar
 
 import (
 	"fmt"
+	"image"
 	"image/color"
+	"image/png"
 	"math/rand"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/samuelfneumann/goatar/events"
 	"github.com/samuelfneumann/goatar/internal/game"
 	"github.com/samuelfneumann/goatar/internal/game/asterix"
 	"github.com/samuelfneumann/goatar/internal/game/breakout"
 	"github.com/samuelfneumann/goatar/internal/game/freeway"
 	"github.com/samuelfneumann/goatar/internal/game/seaquest"
 	"github.com/samuelfneumann/goatar/internal/game/spaceinvaders"
-	"gonum.org/v1/gonum/mat"
-	"gonum.org/v1/plot"
-	"gonum.org/v1/plot/font"
-	"gonum.org/v1/plot/plotter"
+	"github.com/samuelfneumann/goatar/render"
 )
 
 const NumActions int = 6 // All games have 6 actions
 
-// Default colour for plotting
-var defaultColours = newColours([]color.Color{
-	color.RGBA{3, 3, 3, 255},
-	color.RGBA{26, 71, 84, 255},
-	color.RGBA{93, 135, 55, 255},
-	color.RGBA{205, 126, 151, 255},
-	color.RGBA{199, 206, 243, 255},
-	color.RGBA{205, 229, 242, 255},
-	color.RGBA{205, 169, 230, 255},
-	color.RGBA{101, 132, 59, 255},
-	color.RGBA{32, 47, 73, 255},
-	color.RGBA{92, 109, 146, 255},
-	color.RGBA{132, 90, 108, 255},
-	color.RGBA{198, 185, 217, 255},
-})
-
 // GameName represents a legal game that can be played with GoAtar
 type GameName struct {
 	string // Hide the internals so that new GameNames can't be created
@@ -52,198 +38,510 @@ var (
 	SeaQuest      GameName = GameName{"SeaQuest"}
 )
 
+// Games returns all legal games that can be played with GoAtar
+func Games() []GameName {
+	return []GameName{Asterix, Breakout, Freeway, SeaQuest, SpaceInvaders}
+}
+
+// ParseGameName returns the GameName with the given name. Names are
+// matched ignoring case and spaces, so that "spaceinvaders" and
+// "Space Invaders" both refer to SpaceInvaders.
+func ParseGameName(name string) (GameName, error) {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.ReplaceAll(s, " ", ""))
+	}
+
+	for _, game := range Games() {
+		if normalize(game.string) == normalize(name) {
+			return game, nil
+		}
+	}
+	return GameName{}, fmt.Errorf("parseGameName: no such game %v", name)
+}
+
+// String returns the name of the game
+func (g GameName) String() string {
+	return g.string
+}
+
+// gameConfig holds the configuration of each game
+type gameConfig struct {
+	asterix       asterix.Config
+	breakout      breakout.Config
+	freeway       freeway.Config
+	seaQuest      seaquest.Config
+	spaceInvaders spaceinvaders.Config
+}
+
 // make is a static factory for creating a game.Game for an environment
-func makeEnv(game GameName, difficultyRamping bool, seed int64) (game.Game,
-	error) {
+func makeEnv(game GameName, difficultyRamping bool, seed int64,
+	config gameConfig) (game.Game, error) {
 	switch game {
 	case Asterix:
-		return asterix.New(difficultyRamping, seed)
+		return asterix.NewWithConfig(difficultyRamping, seed,
+			config.asterix)
 
 	case Breakout:
-		return breakout.New(difficultyRamping, seed)
+		return breakout.NewWithConfig(difficultyRamping, seed,
+			config.breakout)
 
 	case Freeway:
-		return freeway.New(difficultyRamping, seed)
+		return freeway.NewWithConfig(difficultyRamping, seed,
+			config.freeway)
 
 	case SeaQuest:
-		return seaquest.New(difficultyRamping, seed)
+		return seaquest.NewWithConfig(difficultyRamping, seed, config.seaQuest)
 
 	case SpaceInvaders:
-		return spaceinvaders.New(difficultyRamping, seed)
+		return spaceinvaders.NewWithConfig(difficultyRamping, seed,
+			config.spaceInvaders)
 
 	default:
 		return nil, fmt.Errorf("no such game")
 	}
 }
 
+// Shape is the shape of a state observation tensor, with named
+// Channels, Rows, and Cols fields
+type Shape = game.Shape
+
+// Env is the interface satisfied by an Environment and by any wrapper
+// around an Environment. Code which interacts with GoAtar environments
+// should accept an Env so that wrapped and unwrapped environments can
+// be used interchangeably.
+type Env interface {
+	// Act takes one environmental step given some action and returns
+	// the reward for that action and whether the episode has ended.
+	Act(int) (float64, bool, error)
+
+	// State returns the current state observation. See game.Game for
+	// the layout of the observation.
+	State() ([]float64, error)
+
+	// Reset resets the environment to some starting state
+	Reset()
+
+	// Shape returns the shape of state observations
+	Shape() Shape
+
+	// StateShape returns the shape of state observations as
+	// (channels, rows, cols). It is equivalent to Shape().Ints().
+	StateShape() []int
+
+	Channel(i int) ([]float64, error) // Returns the matrix at channel i
+	NChannels() int
+
+	NumActions() int
+	MinimalActionSet() []int
+	FullActionSet() []int
+	DifficultyRamp() int
+	GameName() string
+
+	// RewardRange returns the minimum and maximum reward that can be
+	// received on a single step
+	RewardRange() (min, max float64)
+
+	// Seed seeds all sources of randomness in the environment. The
+	// environment is not reset.
+	Seed(int64)
+
+	// StateHash returns a hash of the underlying game state, which can
+	// be used for count-based exploration or duplicate state detection.
+	// The hash does not depend on the state of the random number
+	// generators, so the same state always has the same hash.
+	StateHash() uint64
+}
+
 // Environment implements an environment that an agent can interact
 // with.
 type Environment struct {
 	game.Game
 	gameName          GameName
 	rng               *rand.Rand
+	stickySource      *game.Source // Source of rng, which can be copied
 	nChannels         int
 	stickyActionsProb float64
-	lastAction        int // Is this action the first?
+	stickyProbs       map[Action]float64 // Sticky probability of actions
+	stickyFunc        StickyFunc         // Replaces sticky actions if set
+	rewardOverride    RewardOverride     // Re-weights rewards if set
+	noReward          bool               // Whether rewards are zeroed
+	strictTermination bool               // Whether acting after done errors
+	lastAction        int                // Is this action the first?
 	firstAction       bool
 	closed            bool
+
+	metrics    *metricsRecorder
+	gameConfig gameConfig
+	actionSet  []Action // Custom action set, nil if all actions are used
+	bus        *events.Bus
+	history    *history // Snapshots of previous steps for Undo
+	logger     Logger   // Receives debug messages if set
+
+	callbacks  []stepCallback // Called after each step, in order
+	callbackID int            // ID of the next callback registered
+
+	stochasticity *float64 // Stochasticity level of the game, nil if 1
+	gameSeed      int64    // Seed of the game's random number generator
+	stickySeed    int64    // Seed of rng
+	version       int      // Version of the game's behaviour
+	rampChannel   bool     // Whether observations include a ramp channel
+	transforms    []Transform
+	shape         []int // Shape of transformed state observations
+
+	trailLengths map[string]int // Trail lengths requested by WithTrails
+	trails       *trailStack    // Lengthened trails, nil if unused
+
+	visitationChannel bool      // Whether observations include visits
+	visits            []float64 // Cells visited in the current episode
+
+	randomStarts int          // Maximum number of random start actions
+	startSource  *game.Source // Source of startRng, which can be copied
+	startRng     *rand.Rand   // Draws random start actions
+
+	evalSeeds     []int64 // Seeds of successive episodes, nil if unused
+	evalSeedIndex int     // Index of the seed of the current episode
+
+	// Statistics of the current episode
+	episode       int
+	episodeSteps  int
+	episodeReturn float64
+	episodeOver   bool
+	lastRamp      int
 }
 
+// Option configures an Environment when it is created with New
+type Option func(*Environment) error
+
 // New creates and returns a new Environment of the game specified
 // by name.
 func New(name GameName, stickyActionsProb float64, difficultyRamping bool,
-	seed int64) (*Environment, error) {
-	game, err := makeEnv(name, difficultyRamping, seed)
-	if err != nil {
-		return nil, fmt.Errorf("new: %v", err)
-	}
-
-	rng := rand.New(rand.NewSource(seed))
-
-	return &Environment{
-		Game:              game,
+	seed int64, opts ...Option) (*Environment, error) {
+	env := &Environment{
 		gameName:          name,
-		rng:               rng,
-		nChannels:         game.NChannels(),
 		stickyActionsProb: stickyActionsProb,
 		firstAction:       true,
 		lastAction:        -1,
 		closed:            false,
-	}, nil
+		gameSeed:          seed,
+		stickySeed:        seed,
+	}
+
+	for _, opt := range opts {
+		if err := opt(env); err != nil {
+			return nil, fmt.Errorf("new: %v", err)
+		}
+	}
+	if env.version == 0 {
+		env.version = LatestVersion(name)
+	}
+	env.stickySource = game.NewVersionedSource(env.stickySeed, env.version)
+	env.rng = rand.New(env.stickySource)
+	if env.evalSeeds != nil {
+		env.gameSeed = env.evalSeeds[0]
+		env.SeedSticky(env.gameSeed)
+	}
+
+	game, err := makeEnv(name, difficultyRamping, env.gameSeed,
+		env.gameConfig)
+	if err != nil {
+		return nil, fmt.Errorf("new: %v", err)
+	}
+	env.Game = game
+	env.setGameLogger()
+	env.nChannels = game.NChannels()
+	env.lastRamp = game.DifficultyRamp()
+	if err := env.initTrails(); err != nil {
+		return nil, fmt.Errorf("new: %v", err)
+	}
+
+	// Restart the first episode so that it is generated with the
+	// requested stochasticity level
+	if env.stochasticity != nil {
+		game.SetStochasticity(*env.stochasticity)
+		game.Seed(env.gameSeed)
+		game.Reset()
+	}
+	env.seedRandomStarts(env.gameSeed)
+	env.randomStart(env.Game.Reset)
+	env.resetVisits()
+
+	if env.transforms != nil {
+		if err := env.trackShape(); err != nil {
+			return nil, fmt.Errorf("new: %v", err)
+		}
+	}
+
+	return env, nil
 }
 
 // Act takes one environmental action
 func (e *Environment) Act(a int) (float64, bool, error) {
-	if e.firstAction {
-		e.firstAction = false
-	} else if e.rng.Float64() < e.stickyActionsProb {
-		a = e.lastAction
+	envAction := a
+	a, err := e.gameAction(a)
+	if err != nil {
+		return -1, false, fmt.Errorf("act: %v", err)
 	}
-	e.lastAction = a
-	return e.Game.Act(a)
+	return e.step(envAction, a)
 }
 
-// NumActions returns the total number of available actions
-func (e *Environment) NumActions() int {
-	return NumActions
-}
+// step takes one environmental step with game action a, which the agent
+// selected as action envAction of the Environment's action set
+func (e *Environment) step(envAction, a int) (float64, bool, error) {
+	if e.metrics != nil {
+		allocs := e.metrics.allocs()
+		start := time.Now()
+		defer func() {
+			e.metrics.metrics.Act.record(time.Since(start))
+			e.metrics.metrics.ActAllocs += e.metrics.allocs() - allocs
+		}()
+	}
 
-// GameName returns the name of the game
-func (e *Environment) GameName() string {
-	return e.gameName.string
-}
+	if e.strictTermination && e.episodeOver {
+		return 0, true, &TerminalError{
+			Episode: e.episode,
+			Reason:  e.Game.TerminationReason(),
+		}
+	}
 
-// Display state saves the current state as a png to a file
-func (e *Environment) DisplayState(filename string, w, h float64) error {
-	// Get current state
-	state, err := e.State()
+	if e.history != nil {
+		e.history.push(e.Snapshot())
+	}
+
+	var err error
+	if e.firstAction {
+		e.firstAction = false
+	} else if a, err = e.stickyAction(a); err != nil {
+		return -1, false, fmt.Errorf("act: %v", err)
+	}
+	e.lastAction = a
+
+	e.beginStep()
+	if err := e.pushTrails(); err != nil {
+		return -1, false, fmt.Errorf("act: %v", err)
+	}
+	reward, done, err := e.Game.Act(a)
 	if err != nil {
-		return fmt.Errorf("displayState: %v", err)
+		return reward, done, err
 	}
-	size := e.StateShape()
-	r, c := size[1], size[2]
-
-	// Combine data to create heatmap
-	data := mat.NewDense(size[1], size[2], nil)
-	for ch := 0; ch < size[0]; ch++ {
-		chData := state[r*c*ch : r*c*(ch+1)]
-		for row := 0; row < r; row++ {
-			for col := 0; col < c; col++ {
-				if chData[row*c+col] != 0 {
-					data.Set(r-row-1, col, chData[row*c+col]*float64(ch+1))
-				}
-			}
-		}
+	if e.rewardOverride != nil {
+		reward = e.overrideReward()
 	}
+	e.visit()
+	e.endStep(a, reward, done)
+	if e.noReward {
+		reward = 0
+	}
+	e.runStepCallbacks(envAction, a, reward, done)
 
-	// Set colours for heatmap
-	colours := defaultColours
+	return reward, done, nil
+}
 
-	// Generate random colours if above not enough
-	for e.NChannels() > len(colours.Colors()) {
-		rng := rand.New(rand.NewSource(10))
-		r := uint8(rng.Uint32() % 255)
-		g := uint8(rng.Uint32() % 255)
-		b := uint8(rng.Uint32() % 255)
-		colours.c = append(colours.c, color.RGBA{r, g, b, 255})
+// State returns the current state observation
+func (e *Environment) State() ([]float64, error) {
+	if e.metrics != nil {
+		allocs := e.metrics.allocs()
+		start := time.Now()
+		defer func() {
+			e.metrics.metrics.State.record(time.Since(start))
+			e.metrics.metrics.StateAllocs += e.metrics.allocs() - allocs
+		}()
 	}
 
-	// Create the plot
-	p := plot.New()
-	p.HideAxes()
+	state, err := e.observe()
+	if err != nil || e.transforms == nil {
+		return state, err
+	}
+	return e.transform(state)
+}
 
-	// Create the heatmap
-	heatMap := plotter.NewHeatMap(&Grid{data, e.NChannels()}, colours)
-	p.Add(heatMap)
+// Reset resets the environment to some starting state. From version 2
+// of each game, the first action of the new episode is never repeated
+// due to sticky actions.
+func (e *Environment) Reset() {
+	e.reset(e.Game.Reset)
+}
 
-	// Create the writer to write the plot to
-	writer, err := p.WriterTo(font.Length(w), font.Length(h), "png")
-	if err != nil {
-		return fmt.Errorf("displayState: %v", err)
+// reset begins a new episode, resetting the game with resetGame
+func (e *Environment) reset(resetGame func()) {
+	if e.history != nil {
+		e.history.clear()
 	}
-
-	// Create the file to save to
-	fnew, err := os.Create(fmt.Sprintf("%v.png", filename))
-	if err != nil {
-		return fmt.Errorf("displayState: %v", err)
+	e.endEpisode()
+	e.nextEvalSeed()
+	resetGame()
+	e.randomStart(resetGame)
+	e.resetVisits()
+	e.resetTrails()
+
+	// Version 1 continues sticky actions from the last episode, as the
+	// original port of MinAtar did
+	if e.version != 1 {
+		e.firstAction = true
+		e.lastAction = -1
 	}
-	defer fnew.Close()
+}
 
-	// Write to file
-	writer.WriteTo(fnew)
-	return nil
+// Seed seeds both the game and the sticky action random number
+// generators with seed. The Environment is not reset, so Seed should
+// usually be followed by a call to Reset to begin a reproducible
+// episode.
+func (e *Environment) Seed(seed int64) {
+	e.SeedGame(seed)
+	e.SeedSticky(seed)
 }
 
-type colours struct {
-	c []color.Color
+// SeedGame seeds only the game's random number generator, leaving the
+// sticky action random number generator unchanged
+func (e *Environment) SeedGame(seed int64) {
+	e.Game.Seed(seed)
+	e.seedRandomStarts(seed)
 }
 
-func newColours(cols []color.Color) *colours {
-	return &colours{cols}
+// SeedSticky seeds only the sticky action random number generator,
+// leaving the game's random number generator unchanged
+func (e *Environment) SeedSticky(seed int64) {
+	e.rng.Seed(seed)
 }
 
-func (c *colours) Colors() []color.Color {
-	return c.c
+// NumActions returns the total number of available actions
+func (e *Environment) NumActions() int {
+	if e.actionSet != nil {
+		return len(e.actionSet)
+	}
+	return NumActions
 }
 
-func (c *colours) Add(col color.Color) {
-	c.c = append(c.c, col)
+// MinimalActionSet returns the actions which actually have an effect
+// on the environment. If the Environment was created with a custom
+// action set, the returned actions index into that action set.
+func (e *Environment) MinimalActionSet() []int {
+	minimal := e.Game.MinimalActionSet()
+	if e.actionSet == nil {
+		return minimal
+	}
+
+	var actions []int
+	for i, action := range e.actionSet {
+		for _, m := range minimal {
+			if int(action) == m {
+				actions = append(actions, i)
+				break
+			}
+		}
+	}
+	return actions
 }
 
-type Grid struct {
-	*mat.Dense
-	nchannels int
+// FullActionSet returns all actions which can be passed to Act,
+// including those which have no effect in the current game
+func (e *Environment) FullActionSet() []int {
+	actions := make([]int, e.NumActions())
+	for i := range actions {
+		actions[i] = i
+	}
+	return actions
 }
 
-func (g *Grid) Min() float64 {
-	return 0.0
+// GameName returns the name of the game
+func (e *Environment) GameName() string {
+	return e.gameName.string
 }
 
-func (g *Grid) Max() float64 {
-	return float64(g.nchannels)
+// ZOrder returns the indices of the channels of the game from bottom
+// to top in the order they are drawn, so that when several channels
+// are active at a cell, the cell shows the most important of them,
+// e.g. the player rather than a trail. See render.Options.
+func (e *Environment) ZOrder() []int {
+	return e.Game.Manifest().DrawOrder()
 }
 
-func (g *Grid) Z(c, r int) float64 {
-	return g.Dense.At(r, c)
+// DisplayState saves the current state as a w × h PNG to the file
+// filename.png. Channels are drawn in the order given by ZOrder, and
+// any transforms are ignored. See the render package for more control
+// over rendering.
+func (e *Environment) DisplayState(filename string, w, h float64) error {
+	state, err := e.observe()
+	if err != nil {
+		return fmt.Errorf("displayState: %v", err)
+	}
+
+	// Draw cells as large as possible, then resize to fill any
+	// remaining pixels
+	shape := e.observedShape()
+	cellSize := game.MaxInt(1, game.MinInt(int(w)/shape.Cols,
+		int(h)/shape.Rows))
+	var img image.Image = render.Rasterize(state, shape.Ints(),
+		render.DefaultPalette, render.Options{
+			CellSize: cellSize,
+			ZOrder:   e.ZOrder(),
+		})
+	if img.Bounds().Dx() != int(w) || img.Bounds().Dy() != int(h) {
+		img = render.Resize(img, int(w), int(h))
+	}
+
+	f, err := os.Create(fmt.Sprintf("%v.png", filename))
+	if err != nil {
+		return fmt.Errorf("displayState: %v", err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		return fmt.Errorf("displayState: %v", err)
+	}
+	return nil
 }
 
-func (g *Grid) X(c int) float64 {
-	_, cols := g.Dims()
-	if c > cols {
-		panic("too large")
+// displayChannelsCellSize is the size in pixels of the cells drawn by
+// DisplayChannels
+const displayChannelsCellSize = 16
+
+// ChannelsImage renders the current state as a montage of grayscale
+// images, one per channel and labelled with the channel's name, with
+// each cell drawn as a square of cellSize pixels. Unlike DisplayState,
+// which colours each cell by a single channel, ChannelsImage shows
+// every channel, so that objects which share a cell are not hidden.
+// Any transforms are ignored. See render.Montage.
+func (e *Environment) ChannelsImage(cellSize int) (*image.RGBA, error) {
+	if cellSize <= 0 {
+		return nil, fmt.Errorf("channelsImage: cell size must be positive "+
+			"but got %v", cellSize)
 	}
-	if c < 0 {
-		panic("too small")
+
+	state, err := e.observe()
+	if err != nil {
+		return nil, fmt.Errorf("channelsImage: %v", err)
+	}
+
+	channels := e.Manifest().Channels
+	labels := make([]string, len(channels))
+	for i, ch := range channels {
+		labels[i] = ch.Name
 	}
-	return float64(c)
+
+	return render.Montage(state, e.observedShape().Ints(), labels,
+		render.Options{
+			CellSize:  cellSize,
+			GridLines: true,
+			GridColor: color.RGBA{40, 40, 40, 255},
+		}), nil
 }
 
-func (g *Grid) Y(r int) float64 {
-	if rows, _ := g.Dims(); rows < r {
-		panic("too large")
+// DisplayChannels saves a montage of the channels of the current state
+// as a PNG to the file prefix.png. See ChannelsImage.
+func (e *Environment) DisplayChannels(prefix string) error {
+	img, err := e.ChannelsImage(displayChannelsCellSize)
+	if err != nil {
+		return fmt.Errorf("displayChannels: %v", err)
 	}
-	if r < 0 {
-		panic("too small")
+
+	f, err := os.Create(fmt.Sprintf("%v.png", prefix))
+	if err != nil {
+		return fmt.Errorf("displayChannels: %v", err)
 	}
-	return float64(r)
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		return fmt.Errorf("displayChannels: %v", err)
+	}
+	return nil
 }
@@ -0,0 +1,131 @@
+package goatar
+
+import (
+	"time"
+
+	"github.com/samuelfneumann/goatar/events"
+)
+
+// WithEvents returns an Option which makes an Environment emit events
+// to bus. See the events package for the events which are emitted.
+func WithEvents(bus *events.Bus) Option {
+	return func(e *Environment) error {
+		e.bus = bus
+		return nil
+	}
+}
+
+// Episode returns the index of the current episode, starting at 0.
+// The index is incremented each time the Environment is reset after
+// at least one step has been taken.
+func (e *Environment) Episode() int {
+	return e.episode
+}
+
+// EpisodeSteps returns the number of steps taken in the current
+// episode
+func (e *Environment) EpisodeSteps() int {
+	return e.episodeSteps
+}
+
+// EpisodeReturn returns the sum of rewards received in the current
+// episode
+func (e *Environment) EpisodeReturn() float64 {
+	return e.episodeReturn
+}
+
+// header returns the header for a new event
+func (e *Environment) header() events.Header {
+	return events.Header{
+		Time:    time.Now(),
+		Game:    e.gameName.string,
+		Episode: e.episode,
+	}
+}
+
+// beginStep performs the housekeeping before a step is taken
+func (e *Environment) beginStep() {
+	if e.episodeSteps == 0 && e.bus != nil {
+		e.bus.Emit(events.EpisodeStart{Header: e.header()})
+	}
+}
+
+// endStep updates the episode statistics after action a was taken,
+// resulting in reward and whether the episode is done
+func (e *Environment) endStep(a int, reward float64, done bool) {
+	step := e.episodeSteps
+	e.episodeSteps++
+	e.episodeReturn += reward
+	ramp := e.Game.DifficultyRamp()
+
+	if e.bus != nil {
+		h := e.header()
+		e.bus.Emit(events.Step{
+			Header:   h,
+			Step:     step,
+			Action:   a,
+			Reward:   reward,
+			Terminal: done,
+		})
+		if reward != 0 {
+			e.bus.Emit(events.Reward{Header: h, Step: step, Reward: reward})
+		}
+		if ramp != e.lastRamp {
+			e.bus.Emit(events.DifficultyRamp{
+				Header: h,
+				Step:   step,
+				Level:  ramp,
+			})
+		}
+		if done && !e.episodeOver {
+			e.bus.Emit(events.EpisodeEnd{
+				Header:     h,
+				Steps:      e.episodeSteps,
+				Return:     e.episodeReturn,
+				Terminated: true,
+				Reason:     string(e.Game.TerminationReason()),
+			})
+		}
+	}
+
+	if e.logger != nil {
+		if ramp != e.lastRamp {
+			e.logger.Debugf("goatar: %v episode %v: difficulty ramp %v "+
+				"on step %v", e.gameName, e.episode, ramp, step)
+		}
+		if done && !e.episodeOver {
+			e.logger.Debugf("goatar: %v episode %v terminated after %v "+
+				"steps with return %v: %v", e.gameName, e.episode,
+				e.episodeSteps, e.episodeReturn, e.Game.TerminationReason())
+		}
+	}
+
+	e.lastRamp = ramp
+	e.episodeOver = e.episodeOver || done
+}
+
+// endEpisode performs the housekeeping at the end of an episode, when
+// the Environment is reset
+func (e *Environment) endEpisode() {
+	if e.episodeSteps == 0 {
+		return
+	}
+
+	if !e.episodeOver && e.bus != nil {
+		e.bus.Emit(events.EpisodeEnd{
+			Header: e.header(),
+			Steps:  e.episodeSteps,
+			Return: e.episodeReturn,
+		})
+	}
+	if !e.episodeOver && e.logger != nil {
+		e.logger.Debugf("goatar: %v episode %v truncated after %v steps "+
+			"with return %v", e.gameName, e.episode, e.episodeSteps,
+			e.episodeReturn)
+	}
+
+	e.episode++
+	e.episodeSteps = 0
+	e.episodeReturn = 0
+	e.episodeOver = false
+}
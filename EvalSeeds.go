@@ -0,0 +1,48 @@
+package goatar
+
+import "fmt"
+
+// WithEvalSeeds returns an Option which seeds each episode with the
+// next seed of a fixed list, cycling back to the first seed after the
+// last. The first episode is seeded with seeds[0], and each call to
+// Reset seeds both the game and sticky action random number generators
+// with the next seed before the new episode begins, so that the
+// episodes of an evaluation are the same across agents, runs, and
+// papers regardless of how many steps each episode lasted.
+//
+// Calling Seed seeds only the current episode; the following call to
+// Reset continues with the next seed of the list.
+func WithEvalSeeds(seeds []int64) Option {
+	return func(e *Environment) error {
+		if len(seeds) == 0 {
+			return fmt.Errorf("withEvalSeeds: at least one seed is required")
+		}
+		e.evalSeeds = append([]int64(nil), seeds...)
+		return nil
+	}
+}
+
+// EvalSeeds returns the seeds through which episodes cycle, or nil if
+// the Environment was not created with WithEvalSeeds
+func (e *Environment) EvalSeeds() []int64 {
+	return append([]int64(nil), e.evalSeeds...)
+}
+
+// EvalSeed returns the seed of the current episode from the seeds
+// given to WithEvalSeeds, and whether such seeds were given
+func (e *Environment) EvalSeed() (int64, bool) {
+	if e.evalSeeds == nil {
+		return 0, false
+	}
+	return e.evalSeeds[e.evalSeedIndex], true
+}
+
+// nextEvalSeed seeds the Environment with the next evaluation seed, if
+// any, before a new episode begins
+func (e *Environment) nextEvalSeed() {
+	if e.evalSeeds == nil {
+		return
+	}
+	e.evalSeedIndex = (e.evalSeedIndex + 1) % len(e.evalSeeds)
+	e.Seed(e.evalSeeds[e.evalSeedIndex])
+}
@@ -0,0 +1,60 @@
+package goatar
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestEvalSeeds checks that episodes cycle through the evaluation
+// seeds, and that each episode is the episode of a new Environment
+// created with its seed
+func TestEvalSeeds(t *testing.T) {
+	seeds := []int64{3, 7, 11}
+
+	// episode plays an episode of env with a fixed sequence of actions
+	// and returns the hash of each state
+	episode := func(env *Environment) []uint64 {
+		hashes := []uint64{env.StateHash()}
+		for i := 0; i < 500; i++ {
+			_, done, err := env.Act(i % NumActions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hashes = append(hashes, env.StateHash())
+			if done {
+				break
+			}
+		}
+		return hashes
+	}
+
+	for _, g := range Games() {
+		env, err := New(g, 0.25, true, 0, WithEvalSeeds(seeds))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 2*len(seeds); i++ {
+			seed := seeds[i%len(seeds)]
+			if got, ok := env.EvalSeed(); !ok || got != seed {
+				t.Fatalf("%v: got seed %v of episode %v, want %v", g, got,
+					i, seed)
+			}
+
+			fresh, err := New(g, 0.25, true, seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, want := episode(env), episode(fresh)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("%v: episode %v differs from the episode of seed %v",
+					g, i, seed)
+			}
+			env.Reset()
+		}
+	}
+
+	if _, err := New(Breakout, 0, false, 0, WithEvalSeeds(nil)); err == nil {
+		t.Error("created an Environment with no evaluation seeds")
+	}
+}
@@ -0,0 +1,182 @@
+package goatar_test
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+func ExampleNew() {
+	// Breakout with sticky actions and difficulty ramping, seeded so
+	// that runs are reproducible
+	env, err := goatar.New(goatar.Breakout, 0.1, true, 42)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(env.GameName(), env.Version())
+	fmt.Println(env.Shape())
+	fmt.Println(env.NumActions(), env.MinimalActionSet())
+	// Output:
+	// Breakout breakout-v2
+	// {4 10 10}
+	// 6 [0 1 3]
+}
+
+func ExampleEnvironment_Act() {
+	env, err := goatar.New(goatar.SpaceInvaders, 0, false, 7)
+	if err != nil {
+		panic(err)
+	}
+
+	// Fire on every step until the episode ends
+	var ret float64
+	for {
+		reward, done, err := env.Act(int(goatar.Fire))
+		if err != nil {
+			panic(err)
+		}
+		ret += reward
+		if done {
+			break
+		}
+	}
+	fmt.Println(ret, env.EpisodeSteps(), env.TerminationReason())
+	// Output:
+	// 3 18 hit-by-bullet
+}
+
+func ExampleEnvironment_State() {
+	env, err := goatar.New(goatar.Freeway, 0, false, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	// The state is a flat channels × rows × cols slice of binary
+	// values, with one channel per kind of object
+	state, err := env.State()
+	if err != nil {
+		panic(err)
+	}
+	shape := env.Shape()
+	fmt.Println(len(state), shape.Channels, shape.Rows, shape.Cols)
+	// Output:
+	// 700 7 10 10
+}
+
+func ExampleEnvironment_Observation() {
+	env, err := goatar.New(goatar.Breakout, 0, false, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	obs, err := env.Observation()
+	if err != nil {
+		panic(err)
+	}
+	paddle, err := obs.Channel("paddle")
+	if err != nil {
+		panic(err)
+	}
+	for col := 0; col < obs.Shape.Cols; col++ {
+		fmt.Print(paddle[(obs.Shape.Rows-1)*obs.Shape.Cols+col])
+	}
+	fmt.Println()
+	// Output:
+	// 0000100000
+}
+
+func ExampleEnvironment_Snapshot() {
+	env, err := goatar.New(goatar.Asterix, 0, false, 3)
+	if err != nil {
+		panic(err)
+	}
+
+	// Restoring a snapshot returns the environment to the same state,
+	// so that the same actions give the same rewards
+	snapshot := env.Snapshot()
+	ret := func() float64 {
+		var ret float64
+		for i := 0; i < 100; i++ {
+			reward, done, err := env.Act(i % env.NumActions())
+			if err != nil {
+				panic(err)
+			}
+			ret += reward
+			if done {
+				break
+			}
+		}
+		return ret
+	}
+
+	first := ret()
+	if err := env.Restore(snapshot); err != nil {
+		panic(err)
+	}
+	fmt.Println(first == ret())
+	// Output:
+	// true
+}
+
+func ExampleWithVersion() {
+	env, err := goatar.New(goatar.Breakout, 0.1, true, 0,
+		goatar.WithVersion(1))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(env.Version())
+	// Output:
+	// breakout-v1
+}
+
+func ExampleRewardWeights() {
+	// Destroying an enemy submarine is worth twice as much as usual
+	env, err := goatar.New(goatar.SeaQuest, 0, false, 0,
+		goatar.WithRewardOverride(goatar.RewardWeights(map[string]float64{
+			"enemy_sub": 2,
+		})))
+	if err != nil {
+		panic(err)
+	}
+
+	// Dive, turn right, and fire until the first enemy is destroyed
+	for i := 0; ; i++ {
+		a := goatar.Fire
+		if i < 3 {
+			a = goatar.Down
+		} else if i == 3 {
+			a = goatar.Right
+		}
+		reward, done, err := env.Act(int(a))
+		if err != nil {
+			panic(err)
+		}
+		if reward != 0 || done {
+			fmt.Println(reward, done)
+			break
+		}
+	}
+	// Output:
+	// 2 false
+}
+
+func ExampleWithStrictTermination() {
+	env, err := goatar.New(goatar.Freeway, 0, false, 0,
+		goatar.WithStrictTermination())
+	if err != nil {
+		panic(err)
+	}
+
+	for !env.NeedsReset() {
+		if _, _, err := env.Act(int(goatar.NoOp)); err != nil {
+			panic(err)
+		}
+	}
+
+	// Acting after the episode ended is an error
+	_, _, err = env.Act(int(goatar.NoOp))
+	fmt.Println(err)
+	// Output:
+	// act: episode 0 ended (time-limit) and must be reset before acting
+}
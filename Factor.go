@@ -0,0 +1,28 @@
+package goatar
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// Reflection describes how a factor changes when the screen is flipped
+// horizontally
+type Reflection = game.Reflection
+
+const (
+	Invariant  Reflection = game.Invariant  // Unchanged, e.g. a row
+	Column     Reflection = game.Column     // Column x becomes cols-1-x
+	Horizontal Reflection = game.Horizontal // A direction, negated
+)
+
+// Factor is a single named variable of a factored state, such as the
+// column of the player
+type Factor = game.Factor
+
+// Factors returns the factored state of the game: a small number of
+// named, discrete variables, such as the positions and directions of
+// objects and counters like SeaQuest's oxygen, which summarize the
+// game's internal state. Every state of a game has the same factors, in
+// the same order, so factored states can be used as feature vectors.
+// Factors need not determine the state exactly, e.g. Breakout's factors
+// count the remaining bricks rather than locating them.
+func (e *Environment) Factors() []Factor {
+	return e.Game.Factors()
+}
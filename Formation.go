@@ -0,0 +1,74 @@
+package goatar
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game/spaceinvaders"
+)
+
+// SpaceInvadersConfig configures the alien formation of SpaceInvaders:
+// the number of rows and columns of aliens in each wave, what happens
+// when a wave is cleared, and which alien shoots at the cannon. The
+// zero value matches MinAtar.
+type SpaceInvadersConfig = spaceinvaders.Config
+
+// Respawn determines what happens when a wave of aliens is cleared in
+// SpaceInvaders
+type Respawn = spaceinvaders.Respawn
+
+const (
+	// RespawnTop spawns each new wave at the top of the screen
+	RespawnTop Respawn = spaceinvaders.RespawnTop
+
+	// RespawnLower spawns each new wave one row lower than the last,
+	// increasing the pressure on the player as the game goes on
+	RespawnLower Respawn = spaceinvaders.RespawnLower
+
+	// NoRespawn ends the episode when a wave is cleared
+	NoRespawn Respawn = spaceinvaders.NoRespawn
+)
+
+// Targeting determines which alien shoots at the cannon in
+// SpaceInvaders. The alien which shoots is always the lowest alien in
+// its column.
+type Targeting = spaceinvaders.Targeting
+
+const (
+	// NearestColumn shoots from the column of aliens nearest the
+	// cannon, as in MinAtar
+	NearestColumn Targeting = spaceinvaders.NearestColumn
+
+	// RandomColumn shoots from a column of aliens chosen uniformly at
+	// random
+	RandomColumn Targeting = spaceinvaders.RandomColumn
+
+	// LeadingShot shoots from the column of aliens nearest to where the
+	// cannon will be when the bullet reaches the bottom row, if the
+	// cannon keeps moving as it moved on the last step
+	LeadingShot Targeting = spaceinvaders.LeadingShot
+)
+
+// WithSpaceInvadersConfig returns an Option which configures the alien
+// formation and difficulty ramp of SpaceInvaders. If config.Version is
+// 0, the version set by WithVersion, if any, is kept; otherwise it sets
+// the version of the game as WithVersion does. Bullet trails added by
+// WithBulletTrails are kept regardless of config.BulletTrails.
+// This option can only be used with SpaceInvaders.
+func WithSpaceInvadersConfig(config SpaceInvadersConfig) Option {
+	return func(e *Environment) error {
+		if e.gameName != SpaceInvaders {
+			return fmt.Errorf("withSpaceInvadersConfig: alien formations "+
+				"are not supported by %v", e.gameName)
+		}
+		if config.Version == 0 {
+			config.Version = e.gameConfig.spaceInvaders.Version
+		} else if err := WithVersion(config.Version)(e); err != nil {
+			return fmt.Errorf("withSpaceInvadersConfig: %v", err)
+		}
+		if e.gameConfig.spaceInvaders.BulletTrails {
+			config.BulletTrails = true
+		}
+		e.gameConfig.spaceInvaders = config
+		return nil
+	}
+}
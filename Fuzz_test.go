@@ -0,0 +1,78 @@
+//go:build go1.18
+// +build go1.18
+
+package goatar
+
+import (
+	"testing"
+)
+
+// fuzzSteps is the maximum number of steps taken for a single fuzz
+// input. Action sequences are repeated until this many steps are taken
+// so that short inputs still reach later stages of each game.
+const fuzzSteps = 2000
+
+// fuzzGame fuzzes sequences of actions, including invalid actions,
+// into the game g. Each byte of the input is interpreted as a signed
+// action, so that both negative actions and actions larger than the
+// number of actions are exercised.
+func fuzzGame(f *testing.F, g GameName) {
+	f.Add(int64(0), 0.0, true, []byte{0})
+	f.Add(int64(1), 0.1, true, []byte{1, 3, 5, 2, 4})
+	f.Add(int64(2), 0.0, false, []byte{5, 5, 5, 2, 2, 4, 1, 1, 3})
+	f.Add(int64(3), 0.5, true, []byte{255, 6, 128, 127, 0, 5})
+
+	f.Fuzz(func(t *testing.T, seed int64, sticky float64, ramping bool,
+		actions []byte) {
+		if len(actions) == 0 || sticky < 0 || sticky > 1 || sticky != sticky {
+			t.Skip()
+		}
+
+		env, err := New(g, sticky, ramping, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size := 1
+		for _, dim := range env.StateShape() {
+			size *= dim
+		}
+
+		for i := 0; i < fuzzSteps; i++ {
+			a := int(int8(actions[i%len(actions)]))
+			_, done, err := env.Act(a)
+
+			valid := a >= 0 && a < env.NumActions()
+			if valid && err != nil {
+				t.Fatalf("step %v: valid action %v returned error: %v", i,
+					a, err)
+			} else if !valid && err == nil {
+				t.Fatalf("step %v: invalid action %v returned no error",
+					i, a)
+			}
+
+			state, err := env.State()
+			if err != nil {
+				t.Fatalf("step %v: %v", i, err)
+			}
+			if len(state) != size {
+				t.Fatalf("step %v: state has length %v, want %v", i,
+					len(state), size)
+			}
+			for _, v := range state {
+				if v < 0 || v > 1 {
+					t.Fatalf("step %v: state value %v ∉ [0, 1]", i, v)
+				}
+			}
+
+			if done {
+				env.Reset()
+			}
+		}
+	})
+}
+
+func FuzzAsterix(f *testing.F)       { fuzzGame(f, Asterix) }
+func FuzzBreakout(f *testing.F)      { fuzzGame(f, Breakout) }
+func FuzzFreeway(f *testing.F)       { fuzzGame(f, Freeway) }
+func FuzzSeaQuest(f *testing.F)      { fuzzGame(f, SeaQuest) }
+func FuzzSpaceInvaders(f *testing.F) { fuzzGame(f, SpaceInvaders) }
@@ -0,0 +1,88 @@
+package goatar
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// RewardEventType is the kind of event which results in a reward. The
+// types are shared by all games.
+type RewardEventType = game.RewardEventType
+
+const (
+	Collect RewardEventType = game.Collect // An item is collected
+	Destroy RewardEventType = game.Destroy // An object is destroyed
+	Goal    RewardEventType = game.Goal    // A goal location is reached
+	Bonus   RewardEventType = game.Bonus   // A bonus is awarded
+)
+
+// RewardEvent is a single event which resulted in a reward, such as a
+// brick being destroyed in Breakout. The amounts of the reward events
+// of a step sum to the reward of the step.
+type RewardEvent = game.RewardEvent
+
+// TerminationReason is the reason an episode ended. The reasons are
+// shared by all games.
+type TerminationReason = game.TerminationReason
+
+const (
+	NotTerminated         TerminationReason = game.NotTerminated
+	HitByBullet           TerminationReason = game.HitByBullet
+	Collision             TerminationReason = game.Collision
+	OutOfOxygen           TerminationReason = game.OutOfOxygen
+	TimeLimit             TerminationReason = game.TimeLimit
+	SurfacedWithoutDivers TerminationReason = game.SurfacedWithoutDivers
+	AliensReachedBottom   TerminationReason = game.AliensReachedBottom
+	BallMissed            TerminationReason = game.BallMissed
+	WaveCleared           TerminationReason = game.WaveCleared
+)
+
+// Info holds auxiliary information about the last step taken in an
+// Environment, which is not part of the state observation
+type Info struct {
+	RewardEvents []RewardEvent
+
+	// Reward is the reward the game gave on the last step, which is the
+	// sum of the amounts of RewardEvents. It is the reward returned by
+	// Act, unless the Environment was created with WithNoReward.
+	Reward float64
+
+	// TerminationReason is the reason the episode ended, or
+	// NotTerminated if the episode has not ended
+	TerminationReason TerminationReason
+}
+
+// Info returns auxiliary information about the last step taken with
+// Act. After Reset, and before the first step of an episode, the
+// returned Info is empty.
+func (e *Environment) Info() Info {
+	var info Info
+	if events := e.Game.RewardEvents(); len(events) > 0 {
+		info.RewardEvents = append([]RewardEvent(nil), events...)
+		if e.rewardOverride != nil {
+			e.overrideEvents(info.RewardEvents)
+		}
+		for _, event := range info.RewardEvents {
+			info.Reward += event.Amount
+		}
+	}
+	info.TerminationReason = e.Game.TerminationReason()
+	return info
+}
+
+// RewardBySource returns the total reward of the step attributed to
+// each source, given by the Entity of each reward event. For example,
+// a SeaQuest step may attribute rewards to "enemy_fish", "enemy_sub",
+// and "oxygen", with one point of the surfacing bonus for each cell of
+// the oxygen gauge. The rewards of all sources sum to the reward of
+// the step.
+func (i Info) RewardBySource() map[string]float64 {
+	sources := make(map[string]float64, len(i.RewardEvents))
+	for _, event := range i.RewardEvents {
+		sources[event.Entity] += event.Amount
+	}
+	return sources
+}
+
+// TerminationReason returns the reason the current episode ended, or
+// NotTerminated if it has not ended
+func (e *Environment) TerminationReason() TerminationReason {
+	return e.Game.TerminationReason()
+}
@@ -0,0 +1,36 @@
+package goatar
+
+import "fmt"
+
+// WithBounceReward returns an Option which rewards the player with
+// +0.1 each time the ball bounces off the paddle in Breakout, in
+// addition to the reward for breaking bricks. Together with
+// WithJuggling, this gives dense and sparse reward variants of the same
+// dynamics for studies of reward shaping. The reward of each bounce is
+// reported through the reward events returned by Info. This option can
+// only be used with Breakout.
+func WithBounceReward() Option {
+	return func(e *Environment) error {
+		if e.gameName != Breakout {
+			return fmt.Errorf("withBounceReward: bounce rewards are not "+
+				"supported by %v", e.gameName)
+		}
+		e.gameConfig.breakout.BounceReward = true
+		return nil
+	}
+}
+
+// WithJuggling returns an Option which turns Breakout into a juggling
+// game: the player is rewarded with +0.1 each time the ball bounces off
+// the paddle, and breaking bricks is not rewarded. This option can only
+// be used with Breakout.
+func WithJuggling() Option {
+	return func(e *Environment) error {
+		if e.gameName != Breakout {
+			return fmt.Errorf("withJuggling: juggling is not supported "+
+				"by %v", e.gameName)
+		}
+		e.gameConfig.breakout.Juggling = true
+		return nil
+	}
+}
@@ -0,0 +1,47 @@
+package goatar
+
+import (
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// Logger receives debug messages describing what happens in an
+// Environment, such as spawns, collisions, difficulty ramps, and
+// terminations. The standard library's *log.Logger does not implement
+// Logger, but is easily adapted:
+//
+//	type debugLogger struct{ *log.Logger }
+//
+//	func (l debugLogger) Debugf(format string, args ...interface{}) {
+//		l.Printf(format, args...)
+//	}
+type Logger = game.Logger
+
+// WithLogger returns an Option which makes an Environment log debug
+// messages to l. Messages are formatted lazily by l, and nothing is
+// logged by default, so that logging costs nothing unless it is
+// enabled. Steps simulated from restored snapshots, such as by a tree
+// search, are logged as well, while Peek and ExpectedStateDensity are
+// not logged.
+func WithLogger(l Logger) Option {
+	return func(e *Environment) error {
+		e.logger = l
+		return nil
+	}
+}
+
+// setGameLogger makes the game log its events to the logger of the
+// Environment
+func (e *Environment) setGameLogger() {
+	if g, ok := e.Game.(game.Loggable); ok {
+		g.SetLogger(e.logger)
+	}
+}
+
+// silentClone returns a copy of g which does not log its events
+func silentClone(g game.Game) game.Game {
+	clone := g.Clone()
+	if l, ok := clone.(game.Loggable); ok {
+		l.SetLogger(nil)
+	}
+	return clone
+}
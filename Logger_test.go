@@ -0,0 +1,78 @@
+package goatar
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recordLogger records the messages logged to it
+type recordLogger struct {
+	messages []string
+}
+
+func (l *recordLogger) Debugf(format string, args ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+// count returns the number of messages containing substr
+func (l *recordLogger) count(substr string) int {
+	n := 0
+	for _, m := range l.messages {
+		if strings.Contains(m, substr) {
+			n++
+		}
+	}
+	return n
+}
+
+// TestLogger checks that games log their events and that the end of
+// each episode is logged, without changing the dynamics of the game
+func TestLogger(t *testing.T) {
+	for _, g := range Games() {
+		l := new(recordLogger)
+		env, err := New(g, 0.1, true, 2, WithLogger(l))
+		if err != nil {
+			t.Fatal(err)
+		}
+		twin, err := New(g, 0.1, true, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		episodes := 0
+		for i := 0; i < 3000; i++ {
+			a := (i*7 + i/5) % NumActions
+			_, done, err := env.Act(a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			twin.Act(a)
+			if env.StateHash() != twin.StateHash() {
+				t.Fatalf("%v: logging changed the dynamics on step %v", g, i)
+			}
+			if done {
+				episodes++
+				env.Reset()
+				twin.Reset()
+			}
+		}
+
+		if n := l.count(" terminated"); n != 2*episodes {
+			t.Errorf("%v: got %v termination messages, want %v", g, n,
+				2*episodes)
+		}
+		if l.count(" spawned ") == 0 {
+			t.Errorf("%v: no spawns were logged", g)
+		}
+
+		// Peeking does not log
+		n := len(l.messages)
+		if _, err := env.Peek(); err != nil {
+			t.Fatal(err)
+		}
+		if len(l.messages) != n {
+			t.Errorf("%v: peeking logged %v", g, l.messages[n:])
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package goatar
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// GameManifest describes a game as structured data: its channels,
+// the effects of its actions, the events which are rewarded, the
+// conditions under which episodes terminate, and how difficulty
+// ramping changes the game
+type GameManifest struct {
+	Game string
+	game.Manifest
+	MinimalActionSet []int // Indices of the actions which have an effect
+}
+
+// ChannelInfo describes a single channel of state observations
+type ChannelInfo = game.ChannelInfo
+
+// ActionInfo describes the effect of a single action
+type ActionInfo = game.ActionInfo
+
+// RewardInfo describes an event which results in a reward
+type RewardInfo = game.RewardInfo
+
+// Mirror describes how a horizontally symmetric game maps onto itself
+// when the screen is flipped horizontally
+type Mirror = game.Mirror
+
+// Manifest returns a description of the Environment's game. If the
+// Environment was created with a custom action set, the actions are
+// those of the action set, in order. If the Environment was created
+// with WithRampChannel, the ramp channel is the last channel. Trail
+// channels lengthened with WithTrails are flagged as NonBinary.
+func (e *Environment) Manifest() GameManifest {
+	m := e.Game.Manifest()
+
+	actions := e.ActionSet()
+	m.Actions = make([]ActionInfo, len(actions))
+	for i, a := range actions {
+		m.Actions[i] = ActionInfo{Action: a, Effect: e.ActionEffect(i)}
+	}
+	e.markTrails(m.Channels)
+	if e.rampChannel {
+		m.Channels = append(m.Channels, rampChannelInfo)
+	}
+	if e.visits != nil {
+		m.Channels = append(m.Channels, visitationChannelInfo)
+	}
+
+	return GameManifest{
+		Game:             e.GameName(),
+		Manifest:         m,
+		MinimalActionSet: e.MinimalActionSet(),
+	}
+}
@@ -0,0 +1,78 @@
+package goatar
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// densitySteps is the number of steps of random play over which
+// ExpectedStateDensity averages
+const densitySteps = 2000
+
+// MaxEpisodeSteps returns the number of steps after which every episode
+// of the game ends, and whether the game has such a limit. Freeway
+// episodes last 2501 steps, since, as in MinAtar, the episode ends once
+// its 2500 step timer falls below zero. Episodes of the other games are
+// unbounded. Random start actions count towards the limit, so episodes
+// may be shorter.
+func (e *Environment) MaxEpisodeSteps() (int, bool) {
+	if t, ok := e.Game.(game.TimeLimited); ok {
+		return t.MaxEpisodeSteps(), true
+	}
+	return 0, false
+}
+
+// GameConstants returns the constants of the game's dynamics, such as
+// spawn and move intervals, the intervals at which difficulty ramping
+// stops, and entity caps, by the name of each constant in MinAtar where
+// it has one, and whether the game reports its constants. Asterix,
+// SeaQuest, and SpaceInvaders report their constants. The constants
+// describe the game as configured when the Environment was created, so
+// parameters changed with SetParam are not reflected.
+func (e *Environment) GameConstants() (map[string]int, bool) {
+	if c, ok := e.Game.(game.ConstantReporter); ok {
+		return c.Constants(), true
+	}
+	return nil, false
+}
+
+// ExpectedStateDensity returns the expected fraction of the elements
+// of the game's state observations which are nonzero, for deciding
+// between dense and sparse storage of observations. The fraction is
+// estimated over 2000 steps of uniformly random play over the minimal
+// action set, in a copy of the game starting from a new episode, so
+// that the Environment is not changed and the estimate depends only on
+// the game and its configuration. Channels added by the Environment,
+// such as the ramp channel, and transforms are not included.
+func (e *Environment) ExpectedStateDensity() (float64, error) {
+	g := silentClone(e.Game)
+	g.Seed(0)
+	g.Reset()
+	rng := rand.New(rand.NewSource(0))
+	actions := g.MinimalActionSet()
+
+	nonZero, total := 0, 0
+	for i := 0; i < densitySteps; i++ {
+		state, err := g.State()
+		if err != nil {
+			return 0, fmt.Errorf("expectedStateDensity: %v", err)
+		}
+		for _, v := range state {
+			if v != 0 {
+				nonZero++
+			}
+		}
+		total += len(state)
+
+		_, done, err := g.Act(actions[rng.Intn(len(actions))])
+		if err != nil {
+			return 0, fmt.Errorf("expectedStateDensity: %v", err)
+		}
+		if done {
+			g.Reset()
+		}
+	}
+	return float64(nonZero) / float64(total), nil
+}
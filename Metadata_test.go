@@ -0,0 +1,67 @@
+package goatar
+
+import "testing"
+
+// TestMaxEpisodeSteps checks that Freeway episodes last exactly the
+// reported number of steps, and that other games report no limit
+func TestMaxEpisodeSteps(t *testing.T) {
+	for _, g := range Games() {
+		env, err := New(g, 0, true, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, ok := env.MaxEpisodeSteps()
+		if ok != (g == Freeway) {
+			t.Fatalf("%v: got limit %v, want %v", g, ok, g == Freeway)
+		}
+		if !ok {
+			continue
+		}
+
+		steps := 0
+		for done := false; !done; steps++ {
+			if _, done, err = env.Act(int(NoOp)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if steps != n {
+			t.Errorf("%v: episode lasted %v steps, want %v", g, steps, n)
+		}
+	}
+}
+
+// TestExpectedStateDensity checks that the expected state density is a
+// deterministic fraction, and that estimating it leaves the Environment
+// unchanged
+func TestExpectedStateDensity(t *testing.T) {
+	for _, g := range Games() {
+		env, err := New(g, 0.1, true, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		twin, err := New(g, 0.1, true, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		d, err := env.ExpectedStateDensity()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d <= 0 || d >= 1 {
+			t.Fatalf("%v: got density %v ∉ (0, 1)", g, d)
+		}
+		if again, _ := env.ExpectedStateDensity(); again != d {
+			t.Fatalf("%v: got densities %v and %v", g, d, again)
+		}
+
+		for i := 0; i < 100; i++ {
+			env.Act(i % env.NumActions())
+			twin.Act(i % env.NumActions())
+			if env.StateHash() != twin.StateHash() {
+				t.Fatalf("%v: estimating the density changed the "+
+					"environment", g)
+			}
+		}
+	}
+}
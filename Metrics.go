@@ -0,0 +1,170 @@
+package goatar
+
+import (
+	"fmt"
+	"runtime/metrics"
+	"strings"
+	"time"
+)
+
+// allocsMetric is the runtime metric used to count heap allocations
+const allocsMetric = "/gc/heap/allocs:objects"
+
+// histogramBounds are the upper bounds of the buckets of a Histogram.
+// Durations larger than the last bound fall in an overflow bucket.
+var histogramBounds = []time.Duration{
+	250 * time.Nanosecond,
+	500 * time.Nanosecond,
+	time.Microsecond,
+	2500 * time.Nanosecond,
+	5 * time.Microsecond,
+	10 * time.Microsecond,
+	25 * time.Microsecond,
+	50 * time.Microsecond,
+	100 * time.Microsecond,
+	250 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+}
+
+// Histogram is a latency histogram. Counts[i] is the number of
+// durations recorded which were at most Bounds[i] and larger than
+// Bounds[i-1]. The final element of Counts counts the durations larger
+// than every bound.
+type Histogram struct {
+	Bounds []time.Duration
+	Counts []int
+	Count  int
+	Sum    time.Duration
+	Min    time.Duration
+	Max    time.Duration
+}
+
+// newHistogram returns a new, empty Histogram
+func newHistogram() Histogram {
+	return Histogram{
+		Bounds: histogramBounds,
+		Counts: make([]int, len(histogramBounds)+1),
+	}
+}
+
+// record records a single duration in the histogram
+func (h *Histogram) record(d time.Duration) {
+	i := 0
+	for i < len(h.Bounds) && d > h.Bounds[i] {
+		i++
+	}
+	h.Counts[i]++
+
+	if h.Count == 0 || d < h.Min {
+		h.Min = d
+	}
+	if d > h.Max {
+		h.Max = d
+	}
+	h.Count++
+	h.Sum += d
+}
+
+// Mean returns the mean duration recorded in the histogram
+func (h Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// String returns a string representation of the histogram
+func (h Histogram) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "count=%v mean=%v min=%v max=%v", h.Count, h.Mean(),
+		h.Min, h.Max)
+	for i, count := range h.Counts {
+		if count == 0 {
+			continue
+		}
+		if i < len(h.Bounds) {
+			fmt.Fprintf(&b, " ≤%v:%v", h.Bounds[i], count)
+		} else {
+			fmt.Fprintf(&b, " >%v:%v", h.Bounds[len(h.Bounds)-1], count)
+		}
+	}
+	return b.String()
+}
+
+// Metrics holds timing and allocation information about calls to
+// an Environment's Act and State methods.
+//
+// Allocation counts are measured using the runtime's process-wide
+// heap allocation counter, and so they are only accurate when no
+// other goroutine is allocating concurrently with the Environment.
+type Metrics struct {
+	Act         Histogram
+	State       Histogram
+	ActAllocs   uint64
+	StateAllocs uint64
+}
+
+// clone returns a deep copy of the metrics
+func (m *Metrics) clone() Metrics {
+	c := *m
+	c.Act.Counts = append([]int(nil), m.Act.Counts...)
+	c.State.Counts = append([]int(nil), m.State.Counts...)
+	return c
+}
+
+// metricsRecorder records Metrics for an Environment
+type metricsRecorder struct {
+	metrics Metrics
+	sample  []metrics.Sample
+}
+
+// newMetricsRecorder returns a new metricsRecorder
+func newMetricsRecorder() *metricsRecorder {
+	return &metricsRecorder{
+		metrics: Metrics{
+			Act:   newHistogram(),
+			State: newHistogram(),
+		},
+		sample: []metrics.Sample{{Name: allocsMetric}},
+	}
+}
+
+// allocs returns the total number of heap allocations made by the
+// process so far
+func (m *metricsRecorder) allocs() uint64 {
+	metrics.Read(m.sample)
+	if m.sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return m.sample[0].Value.Uint64()
+}
+
+// WithMetrics returns an Option which enables the recording of
+// latency and allocation metrics for the Act and State methods of an
+// Environment. Recorded metrics are accessed through the Metrics
+// method.
+func WithMetrics() Option {
+	return func(e *Environment) error {
+		e.metrics = newMetricsRecorder()
+		return nil
+	}
+}
+
+// Metrics returns the metrics recorded by the Environment and whether
+// metrics are being recorded. Metrics are recorded only if the
+// Environment was created using the WithMetrics Option.
+func (e *Environment) Metrics() (Metrics, bool) {
+	if e.metrics == nil {
+		return Metrics{}, false
+	}
+	return e.metrics.metrics.clone(), true
+}
+
+// ResetMetrics clears all metrics recorded by the Environment
+func (e *Environment) ResetMetrics() {
+	if e.metrics != nil {
+		e.metrics = newMetricsRecorder()
+	}
+}
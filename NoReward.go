@@ -0,0 +1,26 @@
+package goatar
+
+// WithNoReward returns an Option which makes Act return a reward of 0
+// on every step, without changing the dynamics of the game, for
+// reward-free exploration and unsupervised pretraining. The reward the
+// game would have given is still available as Info().Reward, and the
+// reward events of Info keep their amounts, so that exploration can be
+// logged or evaluated on the task. Episode statistics, such as
+// EpisodeReturn, and emitted events also keep the game's rewards.
+// RewardRange returns (0, 0).
+func WithNoReward() Option {
+	return func(e *Environment) error {
+		e.noReward = true
+		return nil
+	}
+}
+
+// RewardRange returns the minimum and maximum reward that can be
+// received on a single step, which is (0, 0) if the Environment was
+// created with WithNoReward
+func (e *Environment) RewardRange() (min, max float64) {
+	if e.noReward {
+		return 0, 0
+	}
+	return e.Game.RewardRange()
+}
@@ -0,0 +1,52 @@
+package goatar
+
+import "testing"
+
+// TestNoReward checks that WithNoReward zeroes rewards without changing
+// the dynamics of the game, and that Info keeps the game's reward
+func TestNoReward(t *testing.T) {
+	for _, g := range Games() {
+		env, err := New(g, 0.1, true, 4, WithNoReward())
+		if err != nil {
+			t.Fatal(err)
+		}
+		twin, err := New(g, 0.1, true, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if min, max := env.RewardRange(); min != 0 || max != 0 {
+			t.Fatalf("%v: got reward range (%v, %v), want (0, 0)", g, min,
+				max)
+		}
+
+		total := 0.0
+		for i := 0; i < 1000; i++ {
+			a := i % env.NumActions()
+			r, done, err := env.Act(a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rTwin, _, _ := twin.Act(a)
+
+			if r != 0 {
+				t.Fatalf("%v: got reward %v, want 0", g, r)
+			}
+			if info := env.Info(); info.Reward != rTwin {
+				t.Fatalf("%v: got info reward %v, want %v", g, info.Reward,
+					rTwin)
+			}
+			if env.StateHash() != twin.StateHash() {
+				t.Fatalf("%v: dynamics differ on step %v", g, i)
+			}
+			total += rTwin
+
+			if done {
+				env.Reset()
+				twin.Reset()
+			}
+		}
+		if total == 0 {
+			t.Errorf("%v: no rewards were given to compare", g)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package goatar
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// Orientation is the direction in which an object faces or moves. The
+// orientations are shared by all games.
+type Orientation = game.Orientation
+
+const (
+	Unoriented      Orientation = game.Unoriented
+	FacingLeft      Orientation = game.FacingLeft
+	FacingRight     Orientation = game.FacingRight
+	FacingUp        Orientation = game.FacingUp
+	FacingDown      Orientation = game.FacingDown
+	FacingUpLeft    Orientation = game.FacingUpLeft
+	FacingUpRight   Orientation = game.FacingUpRight
+	FacingDownLeft  Orientation = game.FacingDownLeft
+	FacingDownRight Orientation = game.FacingDownRight
+)
+
+// Object is a single object in a game, such as an enemy or a bullet.
+// Type names the kind of object, e.g. "enemy_sub", and is the name of
+// the channel in which the object is shown where possible.
+type Object = game.Object
+
+// Objects returns the objects currently in the game, such as the
+// player, enemies, and bullets, as an alternative to the state
+// observation tensor for object-centric and relational agents. Objects
+// are derived from the game's internal state rather than from the
+// state observation, so objects which share a cell are all returned.
+// The order of the objects is deterministic, with the player first.
+func (e *Environment) Objects() []Object {
+	return e.Game.Objects()
+}
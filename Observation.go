@@ -0,0 +1,153 @@
+package goatar
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+	"gonum.org/v1/gonum/mat"
+)
+
+// ExtendedObservation is a state observation tensor together with
+// any scalar observations exposed by the game.
+type ExtendedObservation struct {
+	State       []float64 // State observation tensor, see State()
+	Shape       []int     // Shape of State as (channels, rows, cols)
+	Scalars     []float64 // Scalar observations
+	ScalarNames []string  // Name of each scalar observation
+}
+
+// WithScalarGauges returns an Option which removes the oxygen and
+// diver gauge channels from SeaQuest state observations. The gauges
+// are instead exposed as scalars, normalized to [0, 1], through
+// ExtendedObservation. This option can only be used with SeaQuest.
+func WithScalarGauges() Option {
+	return func(e *Environment) error {
+		if e.gameName != SeaQuest {
+			return fmt.Errorf("withScalarGauges: scalar gauges are not "+
+				"supported by %v", e.gameName)
+		}
+		e.gameConfig.seaQuest.ScalarGauges = true
+		return nil
+	}
+}
+
+// WithOxygenWarning returns an Option which adds an oxygen warning
+// channel to SeaQuest state observations, after the other channels.
+// The channel is active in every cell while the player's oxygen is
+// below the fraction level of its maximum, which must be in (0, 1]. A
+// level of 0 warns below a quarter of the maximum oxygen. This option
+// can only be used with SeaQuest.
+func WithOxygenWarning(level float64) Option {
+	return func(e *Environment) error {
+		if e.gameName != SeaQuest {
+			return fmt.Errorf("withOxygenWarning: oxygen warning is not "+
+				"supported by %v", e.gameName)
+		}
+		if level < 0 || level > 1 {
+			return fmt.Errorf("withOxygenWarning: level %v ∉ (0, 1]", level)
+		}
+		e.gameConfig.seaQuest.OxygenWarning = true
+		e.gameConfig.seaQuest.OxygenWarningLevel = level
+		return nil
+	}
+}
+
+// ExtendedObservation returns the current state observation tensor
+// together with any scalar observations exposed by the game. Games
+// which expose no scalar observations have nil Scalars.
+func (e *Environment) ExtendedObservation() (ExtendedObservation, error) {
+	state, err := e.State()
+	if err != nil {
+		return ExtendedObservation{}, fmt.Errorf("extendedObservation: %v",
+			err)
+	}
+
+	obs := ExtendedObservation{
+		State: state,
+		Shape: e.StateShape(),
+	}
+	if g, ok := e.Game.(game.ScalarObserver); ok {
+		obs.Scalars = g.Scalars()
+		obs.ScalarNames = g.ScalarNames()
+	}
+
+	return obs, nil
+}
+
+// Observation is a state observation together with its shape, which
+// provides access to the elements and channels of the observation
+// without manual offset computations. Channels and matrices returned
+// by an Observation share memory with Data.
+type Observation struct {
+	Data  []float64 // State observation tensor, see State()
+	Shape Shape
+
+	channels []string // Name of each channel, nil if unknown
+}
+
+// Observation returns the current state observation as an Observation.
+// Channel names are only known if the Environment has no transforms.
+func (e *Environment) Observation() (Observation, error) {
+	state, err := e.State()
+	if err != nil {
+		return Observation{}, fmt.Errorf("observation: %v", err)
+	}
+
+	obs := Observation{Data: state, Shape: e.Shape()}
+	if e.transforms == nil {
+		for _, ch := range e.Manifest().Channels {
+			obs.channels = append(obs.channels, ch.Name)
+		}
+	}
+	return obs, nil
+}
+
+// At returns the element of the observation at the given channel, row,
+// and column
+func (o Observation) At(channel, row, col int) float64 {
+	if row < 0 || row >= o.Shape.Rows || col < 0 || col >= o.Shape.Cols {
+		panic(fmt.Sprintf("at: position (%v, %v) out of range for shape %v",
+			row, col, o.Shape.Ints()))
+	}
+	return o.ChannelAt(channel)[row*o.Shape.Cols+col]
+}
+
+// ChannelAt returns channel i of the observation as a rows × cols
+// matrix in row-major order
+func (o Observation) ChannelAt(i int) []float64 {
+	if i < 0 || i >= o.Shape.Channels {
+		panic(fmt.Sprintf("channelAt: channel %v ∉ [0, %v)", i,
+			o.Shape.Channels))
+	}
+	size := o.Shape.ChannelSize()
+	return o.Data[i*size : (i+1)*size : (i+1)*size]
+}
+
+// ChannelIndex returns the index of the channel with the given name,
+// and whether the channel exists
+func (o Observation) ChannelIndex(name string) (int, bool) {
+	for i, ch := range o.channels {
+		if ch == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// Channel returns the channel with the given name, such as "paddle",
+// as a rows × cols matrix in row-major order
+func (o Observation) Channel(name string) ([]float64, error) {
+	if o.channels == nil {
+		return nil, fmt.Errorf("channel: channel names are unknown")
+	}
+	i, ok := o.ChannelIndex(name)
+	if !ok {
+		return nil, fmt.Errorf("channel: no such channel %v", name)
+	}
+	return o.ChannelAt(i), nil
+}
+
+// Dense returns channel i of the observation as a rows × cols matrix
+func (o Observation) Dense(i int) *mat.Dense {
+	return mat.NewDense(o.Shape.Rows, o.Shape.Cols, o.ChannelAt(i))
+}
@@ -0,0 +1,78 @@
+package goatar
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// Names of the parameters of an Environment which can be changed with
+// SetParam while the Environment is running
+const (
+	// StickyActionsParam is the sticky actions probability
+	StickyActionsParam = "sticky_actions_prob"
+
+	// RampingParam is non-zero if difficulty ramping is enabled
+	RampingParam = game.RampingParam
+
+	// SpawnIntervalParam is the number of steps between enemy spawns
+	// at the start of an episode. Supported by Asterix and SeaQuest.
+	SpawnIntervalParam = game.SpawnIntervalParam
+
+	// MoveIntervalParam is the number of steps between enemy moves at
+	// the start of an episode. Supported by Asterix, SeaQuest, and
+	// SpaceInvaders.
+	MoveIntervalParam = game.MoveIntervalParam
+)
+
+// Params returns the names of the parameters of the Environment which
+// can be changed with SetParam
+func (e *Environment) Params() []string {
+	params := []string{StickyActionsParam}
+	if g, ok := e.Game.(game.Parameterized); ok {
+		params = append(params, g.Params()...)
+	}
+	return params
+}
+
+// Param returns the current value of the named parameter
+func (e *Environment) Param(name string) (float64, error) {
+	if name == StickyActionsParam {
+		return e.stickyActionsProb, nil
+	}
+
+	if g, ok := e.Game.(game.Parameterized); ok {
+		if value, ok := g.Param(name); ok {
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("param: no such parameter %v for %v", name,
+		e.gameName)
+}
+
+// SetParam sets the value of the named parameter without recreating or
+// resetting the Environment. Game parameters which describe the start
+// of an episode, such as SpawnIntervalParam, take effect immediately
+// and persist across calls to Reset, and difficulty ramping continues
+// from the new value. Integer parameters are rounded to the nearest
+// integer.
+func (e *Environment) SetParam(name string, value float64) error {
+	if name == StickyActionsParam {
+		if value < 0 || value > 1 {
+			return fmt.Errorf("setParam: %v %v ∉ [0, 1]", name, value)
+		}
+		e.stickyActionsProb = value
+		return nil
+	}
+
+	g, ok := e.Game.(game.Parameterized)
+	if !ok {
+		return fmt.Errorf("setParam: no such parameter %v for %v", name,
+			e.gameName)
+	}
+	if _, ok := g.Param(name); !ok {
+		return fmt.Errorf("setParam: no such parameter %v for %v", name,
+			e.gameName)
+	}
+	return g.SetParam(name, value)
+}
@@ -0,0 +1,35 @@
+package goatar
+
+import (
+	"fmt"
+)
+
+// Peek returns the state observation which State would return after a
+// no-op were taken, without changing the Environment. The no-op is
+// taken in a clone of the game, so that the timers and random number
+// generators of the Environment are not advanced. Peeking ignores
+// sticky actions, and step callbacks, events, and logging are not
+// run.
+func (e *Environment) Peek() ([]float64, error) {
+	g := e.Game
+	e.Game = silentClone(g)
+	defer func() { e.Game = g }()
+
+	if _, _, err := e.Game.Act(int(NoOp)); err != nil {
+		return nil, fmt.Errorf("peek: %v", err)
+	}
+
+	state, err := e.observe()
+	if err != nil {
+		return nil, fmt.Errorf("peek: %v", err)
+	}
+	if e.transforms == nil {
+		return state, nil
+	}
+
+	state, err = e.transform(state)
+	if err != nil {
+		return nil, fmt.Errorf("peek: %v", err)
+	}
+	return state, nil
+}
@@ -0,0 +1,67 @@
+package goatar
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// MultiPlayer is implemented by environments in which more than one
+// player is controlled by agents, such as Freeway with a second
+// chicken. The players cooperate, sharing the reward of each step.
+type MultiPlayer interface {
+	// Players returns the number of players controlled by agents
+	Players() int
+
+	// ActPlayers takes one environmental step in which player i takes
+	// action actions[i], and returns the shared reward and whether the
+	// episode ended
+	ActPlayers(actions []int) (float64, bool, error)
+}
+
+// Players returns the number of players controlled by agents, which is
+// 1 for games with a single player
+func (e *Environment) Players() int {
+	if g, ok := e.Game.(game.MultiAgent); ok {
+		return g.Players()
+	}
+	return 1
+}
+
+// ActPlayers takes one environmental step in which player i takes
+// action actions[i], and returns the reward shared by all players and
+// whether the episode ended. Player 0 acts as in Act, so that its
+// action is subject to sticky actions and is reported to step
+// callbacks, while the actions of the other players are taken as
+// given. If the Environment was created with a custom action set, all
+// actions index into that action set.
+func (e *Environment) ActPlayers(actions []int) (float64, bool, error) {
+	if len(actions) != e.Players() {
+		return -1, false, fmt.Errorf("actPlayers: expected %v actions "+
+			"but got %v", e.Players(), len(actions))
+	}
+	if _, err := e.gameAction(actions[0]); err != nil {
+		return -1, false, fmt.Errorf("actPlayers: player 0: %v", err)
+	}
+
+	if g, ok := e.Game.(game.MultiAgent); ok {
+		others := make([]int, len(actions)-1)
+		for i, a := range actions[1:] {
+			action, err := e.gameAction(a)
+			if err != nil {
+				return -1, false, fmt.Errorf("actPlayers: player %v: %v",
+					i+1, err)
+			}
+			others[i] = action
+		}
+		if err := g.SetPlayerActions(others); err != nil {
+			return -1, false, fmt.Errorf("actPlayers: %v", err)
+		}
+	}
+
+	reward, done, err := e.Act(actions[0])
+	if err != nil {
+		return reward, done, fmt.Errorf("actPlayers: %v", err)
+	}
+	return reward, done, nil
+}
@@ -0,0 +1,106 @@
+package goatar
+
+// rampChannelName is the name of the channel added by WithRampChannel
+const rampChannelName = "ramp"
+
+// WithRampChannel returns an Option which appends an extra channel to
+// state observations encoding the current difficulty ramp level (see
+// DifficultyRamp), so that agents can observe how the game changes as
+// difficulty ramps. At level n, the first n cells of the channel in
+// row-major order are 1, so the top row fills from left to right,
+// followed by the rows below it. All cells are 1 at levels beyond the
+// number of cells. For games without difficulty ramping, or when
+// difficulty ramping is disabled, the channel is always 0.
+func WithRampChannel() Option {
+	return func(e *Environment) error {
+		e.rampChannel = true
+		return nil
+	}
+}
+
+// observedShape returns the shape of state observations before any
+// transforms, including the ramp channel if WithRampChannel was used
+// and the visitation channel if WithVisitationChannel was used
+func (e *Environment) observedShape() Shape {
+	shape := e.Game.Shape()
+	if e.rampChannel {
+		shape.Channels++
+	}
+	if e.visits != nil {
+		shape.Channels++
+	}
+	return shape
+}
+
+// observe returns the current state observation before any
+// transforms, including the ramp channel if WithRampChannel was used
+// and the visitation channel if WithVisitationChannel was used
+func (e *Environment) observe() ([]float64, error) {
+	state, err := e.Game.State()
+	if err != nil {
+		return state, err
+	}
+	if e.trails != nil {
+		state = e.stackTrails(state)
+	}
+	if e.rampChannel {
+		state = e.appendRamp(state)
+	}
+	if e.visits != nil {
+		state = append(state, e.visits...)
+	}
+	return state, nil
+}
+
+// observedChannel returns the matrix at channel i of the current state
+// observation before any transforms. If WithRampChannel was used, the
+// ramp channel follows the channels of the game, and if
+// WithVisitationChannel was used, the visitation channel is the last
+// channel.
+func (e *Environment) observedChannel(i int) ([]float64, error) {
+	n := e.Game.NChannels()
+	if e.rampChannel {
+		if i == n {
+			size := e.Game.Shape().ChannelSize()
+			return e.appendRamp(make([]float64, 0, size)), nil
+		}
+		n++
+	}
+	if e.visits != nil && i == n {
+		return append([]float64(nil), e.visits...), nil
+	}
+	if e.trails != nil {
+		state, err := e.Game.State()
+		if err != nil {
+			return nil, err
+		}
+		size := e.trails.size
+		return e.stackTrails(state)[i*size : (i+1)*size], nil
+	}
+	return e.Game.Channel(i)
+}
+
+// appendRamp appends the ramp channel of the current state to state
+func (e *Environment) appendRamp(state []float64) []float64 {
+	size := e.Game.Shape().ChannelSize()
+	filled := e.Game.DifficultyRamp()
+	if filled > size {
+		filled = size
+	}
+
+	for i := 0; i < size; i++ {
+		if i < filled {
+			state = append(state, 1)
+		} else {
+			state = append(state, 0)
+		}
+	}
+	return state
+}
+
+// rampChannelInfo describes the channel added by WithRampChannel
+var rampChannelInfo = ChannelInfo{
+	Name: rampChannelName,
+	Description: "Difficulty ramp level, with one cell filled in " +
+		"row-major order per level",
+}
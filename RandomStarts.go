@@ -0,0 +1,72 @@
+package goatar
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// WithRandomStarts returns an Option which begins each episode with a
+// random number of random actions, drawn uniformly from 0 to k
+// inclusive, as with the random starts of the Arcade Learning
+// Environment. Each random action is drawn uniformly from the game's
+// minimal action set. Random starts vary the starting state of each
+// episode, so that agents cannot memorize an open-loop sequence of
+// actions.
+//
+// Random start actions are taken before the episode begins: they are
+// not counted in the episode's statistics, their rewards are
+// discarded, and they are not affected by sticky actions. If a random
+// start action ends the episode, the game is reset and the episode
+// begins from the game's usual starting state. The random start
+// actions are drawn from their own random number generator, which is
+// seeded along with the game's.
+func WithRandomStarts(k int) Option {
+	return func(e *Environment) error {
+		if k < 0 {
+			return fmt.Errorf("withRandomStarts: number of actions must be "+
+				"non-negative but got %v", k)
+		}
+		e.randomStarts = k
+		return nil
+	}
+}
+
+// RandomStarts returns the maximum number of random actions taken at
+// the start of each episode, see WithRandomStarts
+func (e *Environment) RandomStarts() int {
+	return e.randomStarts
+}
+
+// seedRandomStarts seeds the random number generator of random start
+// actions, creating it if needed
+func (e *Environment) seedRandomStarts(seed int64) {
+	if e.randomStarts == 0 {
+		return
+	}
+	if e.startSource == nil {
+		e.startSource = game.NewVersionedSource(seed, e.version)
+		e.startRng = rand.New(e.startSource)
+		return
+	}
+	e.startSource.Seed(seed)
+}
+
+// randomStart takes the random start actions of a new episode, using
+// resetGame to reset the game if an episode ends during them
+func (e *Environment) randomStart(resetGame func()) {
+	if e.randomStarts == 0 {
+		return
+	}
+
+	actions := e.Game.MinimalActionSet()
+	n := e.startRng.Intn(e.randomStarts + 1)
+	for i := 0; i < n; i++ {
+		a := actions[e.startRng.Intn(len(actions))]
+		if _, done, err := e.Game.Act(a); err != nil || done {
+			resetGame()
+			return
+		}
+	}
+}
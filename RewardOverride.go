@@ -0,0 +1,64 @@
+package goatar
+
+import "fmt"
+
+// RewardOverride returns the reward of a single reward event of the
+// game with the given name, in place of the event's Amount. A
+// RewardOverride must be a pure function of its arguments.
+type RewardOverride func(game string, event RewardEvent) float64
+
+// RewardWeights returns a RewardOverride which scales the reward of
+// each event by the weight of its Entity, e.g. {"enemy_sub": 2} makes
+// destroying an enemy submarine in SeaQuest worth 2. Events of other
+// entities keep their reward.
+func RewardWeights(weights map[string]float64) RewardOverride {
+	weights = copyWeights(weights)
+	return func(_ string, event RewardEvent) float64 {
+		if w, ok := weights[event.Entity]; ok {
+			return w * event.Amount
+		}
+		return event.Amount
+	}
+}
+
+// copyWeights returns a copy of weights
+func copyWeights(weights map[string]float64) map[string]float64 {
+	c := make(map[string]float64, len(weights))
+	for entity, w := range weights {
+		c[entity] = w
+	}
+	return c
+}
+
+// WithRewardOverride returns an Option which re-weights the rewards of
+// the game without changing its behaviour. The reward of each step is
+// the sum of f over the reward events of the step, and the amounts of
+// the reward events returned by Info are given by f. RewardRange still
+// returns the range of the game's own rewards.
+func WithRewardOverride(f RewardOverride) Option {
+	return func(e *Environment) error {
+		if f == nil {
+			return fmt.Errorf("withRewardOverride: nil override")
+		}
+		e.rewardOverride = f
+		return nil
+	}
+}
+
+// overrideReward returns the reward of the last step given by the
+// Environment's reward override
+func (e *Environment) overrideReward() float64 {
+	reward := 0.0
+	for _, event := range e.Game.RewardEvents() {
+		reward += e.rewardOverride(e.GameName(), event)
+	}
+	return reward
+}
+
+// overrideEvents sets the amount of each of the reward events to the
+// amount given by the Environment's reward override
+func (e *Environment) overrideEvents(events []RewardEvent) {
+	for i, event := range events {
+		events[i].Amount = e.rewardOverride(e.GameName(), event)
+	}
+}
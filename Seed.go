@@ -0,0 +1,24 @@
+package goatar
+
+// The game and sticky actions use independent random number
+// generators. By default, both are seeded with the seed passed to New.
+// The options below seed them separately, so that one source of
+// randomness can be varied while the other is held fixed.
+
+// WithGameSeed returns an Option which seeds the game's random number
+// generator with seed instead of the seed passed to New
+func WithGameSeed(seed int64) Option {
+	return func(e *Environment) error {
+		e.gameSeed = seed
+		return nil
+	}
+}
+
+// WithStickySeed returns an Option which seeds the sticky action
+// random number generator with seed instead of the seed passed to New
+func WithStickySeed(seed int64) Option {
+	return func(e *Environment) error {
+		e.stickySeed = seed
+		return nil
+	}
+}
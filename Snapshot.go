@@ -0,0 +1,176 @@
+package goatar
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// Snapshot is a saved state of an Environment, including the state of
+// its random number generators. Restoring a Snapshot returns the
+// Environment to exactly the saved state, so that the same actions
+// produce the same transitions.
+type Snapshot struct {
+	gameName     GameName
+	version      int
+	game         game.Game
+	stickySource *game.Source
+	startSource  *game.Source
+	lastAction   int
+	firstAction  bool
+
+	episode       int
+	episodeSteps  int
+	episodeReturn float64
+	episodeOver   bool
+	lastRamp      int
+	visits        []float64
+	trails        [][]float64
+	evalSeedIndex int
+}
+
+// Snapshot returns a snapshot of the current state of the Environment
+func (e *Environment) Snapshot() *Snapshot {
+	s := &Snapshot{
+		gameName:      e.gameName,
+		version:       e.version,
+		game:          e.Game.Clone(),
+		stickySource:  e.stickySource.Clone(),
+		lastAction:    e.lastAction,
+		firstAction:   e.firstAction,
+		episode:       e.episode,
+		episodeSteps:  e.episodeSteps,
+		episodeReturn: e.episodeReturn,
+		episodeOver:   e.episodeOver,
+		lastRamp:      e.lastRamp,
+		visits:        append([]float64(nil), e.visits...),
+		trails:        e.saveTrails(),
+		evalSeedIndex: e.evalSeedIndex,
+	}
+	if e.startSource != nil {
+		s.startSource = e.startSource.Clone()
+	}
+	return s
+}
+
+// Restore returns the Environment to the state saved in s. A Snapshot
+// can be restored any number of times, but only to an Environment of
+// the same game and version.
+func (e *Environment) Restore(s *Snapshot) error {
+	if s.gameName != e.gameName {
+		return fmt.Errorf("restore: cannot restore snapshot of %v to %v",
+			s.gameName, e.gameName)
+	}
+	if s.version != e.version {
+		return fmt.Errorf("restore: cannot restore snapshot of version %v "+
+			"to version %v", s.version, e.version)
+	}
+
+	e.Game = s.game.Clone()
+	e.setGameLogger()
+	*e.stickySource = *s.stickySource.Clone()
+	if e.startSource != nil && s.startSource != nil {
+		*e.startSource = *s.startSource.Clone()
+	}
+	e.lastAction = s.lastAction
+	e.firstAction = s.firstAction
+	e.episode = s.episode
+	e.episodeSteps = s.episodeSteps
+	e.episodeReturn = s.episodeReturn
+	e.episodeOver = s.episodeOver
+	e.lastRamp = s.lastRamp
+	e.evalSeedIndex = s.evalSeedIndex
+	if e.visits != nil {
+		copy(e.visits, s.visits)
+	}
+	e.restoreTrails(s.trails)
+	return nil
+}
+
+// WithUndo returns an Option which makes an Environment keep snapshots
+// of its last k steps, so that they can be undone with Undo
+func WithUndo(k int) Option {
+	return func(e *Environment) error {
+		if k <= 0 {
+			return fmt.Errorf("withUndo: number of steps must be positive "+
+				"but got %v", k)
+		}
+		e.history = newHistory(k)
+		return nil
+	}
+}
+
+// Undo undoes the last n steps of the current episode, returning the
+// Environment to the state it was in n steps ago. Steps can only be
+// undone if the Environment was created with WithUndo, and steps taken
+// before the last call to Reset cannot be undone.
+func (e *Environment) Undo(n int) error {
+	if e.history == nil {
+		return fmt.Errorf("undo: undo is not enabled, use WithUndo")
+	}
+	if n <= 0 || n > e.history.len() {
+		return fmt.Errorf("undo: cannot undo %v steps, %v steps are "+
+			"available", n, e.history.len())
+	}
+
+	return e.Restore(e.history.pop(n))
+}
+
+// UndoSteps returns the number of steps which can currently be undone
+func (e *Environment) UndoSteps() int {
+	if e.history == nil {
+		return 0
+	}
+	return e.history.len()
+}
+
+// history is a ring buffer of the snapshots of the most recent steps
+type history struct {
+	snapshots []*Snapshot
+	start     int // Index of the oldest snapshot
+	n         int // Number of snapshots in the buffer
+}
+
+// newHistory returns a new history which holds at most k snapshots
+func newHistory(k int) *history {
+	return &history{snapshots: make([]*Snapshot, k)}
+}
+
+// len returns the number of snapshots in the history
+func (h *history) len() int {
+	return h.n
+}
+
+// push adds s to the history, discarding the oldest snapshot if the
+// history is full
+func (h *history) push(s *Snapshot) {
+	i := (h.start + h.n) % len(h.snapshots)
+	h.snapshots[i] = s
+	if h.n < len(h.snapshots) {
+		h.n++
+	} else {
+		h.start = (h.start + 1) % len(h.snapshots)
+	}
+}
+
+// pop removes the n most recent snapshots from the history and returns
+// the oldest of them
+func (h *history) pop(n int) *Snapshot {
+	h.n -= n
+	i := (h.start + h.n) % len(h.snapshots)
+	s := h.snapshots[i]
+
+	for j := 0; j < n; j++ {
+		h.snapshots[(i+j)%len(h.snapshots)] = nil
+	}
+	return s
+}
+
+// clear removes all snapshots from the history
+func (h *history) clear() {
+	for i := range h.snapshots {
+		h.snapshots[i] = nil
+	}
+	h.start = 0
+	h.n = 0
+}
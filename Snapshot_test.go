@@ -0,0 +1,185 @@
+package goatar
+
+import (
+	"reflect"
+	"testing"
+)
+
+// transition is a single step of an Environment
+type transition struct {
+	reward float64
+	done   bool
+	state  []float64
+}
+
+// trajectory takes n steps in env with fixed actions, resetting env
+// when an episode ends, and returns the transitions taken
+func trajectory(t *testing.T, env *Environment, n int) []transition {
+	var steps []transition
+	for i := 0; i < n; i++ {
+		reward, done, err := env.Act((i*5 + i/3) % NumActions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		state, err := env.State()
+		if err != nil {
+			t.Fatal(err)
+		}
+		steps = append(steps, transition{reward, done, state})
+		if done {
+			env.Reset()
+		}
+	}
+	return steps
+}
+
+// TestSnapshotRestore checks that the trajectory taken after restoring
+// a snapshot equals the trajectory taken after the snapshot was taken,
+// including sticky actions and episodes which end along the way
+func TestSnapshotRestore(t *testing.T) {
+	for _, g := range Games() {
+		for _, v := range []int{1, LatestVersion(g)} {
+			env, err := New(g, 0.25, true, 5, WithVersion(v))
+			if err != nil {
+				t.Fatal(err)
+			}
+			trajectory(t, env, 50)
+
+			s := env.Snapshot()
+			want := trajectory(t, env, 500)
+			for i := 0; i < 2; i++ {
+				if err := env.Restore(s); err != nil {
+					t.Fatal(err)
+				}
+				if got := trajectory(t, env, 500); !reflect.DeepEqual(got,
+					want) {
+					t.Errorf("%v version %v: trajectory differs after "+
+						"restore %v", g, v, i+1)
+				}
+			}
+		}
+	}
+}
+
+// TestRestoreMismatch checks that snapshots cannot be restored to an
+// Environment of another game or version
+func TestRestoreMismatch(t *testing.T) {
+	breakout, err := New(Breakout, 0.1, true, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invaders, err := New(SpaceInvaders, 0.1, true, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1, err := New(Breakout, 0.1, true, 1, WithVersion(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := breakout.Snapshot()
+	if err := invaders.Restore(s); err == nil {
+		t.Error("expected error restoring to another game")
+	}
+	if err := v1.Restore(s); err == nil {
+		t.Error("expected error restoring to another version")
+	}
+	if err := breakout.Restore(s); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestUndo checks that Undo returns to the state of n steps ago once
+// the history has wrapped around, and that steps beyond the history,
+// or before the last reset, cannot be undone
+func TestUndo(t *testing.T) {
+	env, err := New(Freeway, 0.25, true, 2, WithUndo(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(Freeway, 0.25, true, 2, WithUndo(0)); err == nil {
+		t.Error("expected error for no undo steps")
+	}
+
+	// states holds the state before each step of the episode
+	var states [][]float64
+	step := func(n int) {
+		for i := 0; i < n; i++ {
+			state, err := env.State()
+			if err != nil {
+				t.Fatal(err)
+			}
+			states = append(states, state)
+			if _, _, err := env.Act(i % NumActions); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	undo := func(n int) {
+		if err := env.Undo(n); err != nil {
+			t.Fatal(err)
+		}
+		state, err := env.State()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := states[len(states)-n]
+		states = states[:len(states)-n]
+		if !reflect.DeepEqual(state, want) {
+			t.Errorf("got a different state after undoing %v steps", n)
+		}
+	}
+	steps := func(want int) {
+		if got := env.UndoSteps(); got != want {
+			t.Errorf("got %v undo steps, want %v", got, want)
+		}
+	}
+
+	steps(0)
+	step(7)
+	steps(3)
+	for _, n := range []int{0, -1, 4} {
+		if err := env.Undo(n); err == nil {
+			t.Errorf("undo %v: expected error", n)
+		}
+	}
+
+	undo(1)
+	steps(2)
+	step(2)
+	steps(3)
+	undo(3)
+	steps(0)
+	if err := env.Undo(1); err == nil {
+		t.Error("expected error with no undo steps")
+	}
+
+	// The trajectory after undoing equals the trajectory undone
+	step(1)
+	want := trajectory(t, env, 3)
+	if err := env.Undo(3); err != nil {
+		t.Fatal(err)
+	}
+	if got := trajectory(t, env, 3); !reflect.DeepEqual(got, want) {
+		t.Error("trajectory differs after undo")
+	}
+
+	step(2)
+	env.Reset()
+	steps(0)
+	if err := env.Undo(1); err == nil {
+		t.Error("expected error undoing past a reset")
+	}
+
+	noUndo, err := New(Freeway, 0.25, true, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noUndo.Act(0)
+	if got := noUndo.UndoSteps(); got != 0 {
+		t.Errorf("got %v undo steps without WithUndo, want 0", got)
+	}
+	if err := noUndo.Undo(1); err == nil {
+		t.Error("expected error without WithUndo")
+	}
+}
@@ -0,0 +1,21 @@
+package goatar
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// SoftReset begins a new episode as Reset does, resetting the positions
+// of the player and all other objects, but keeps the current difficulty
+// ramp of the game (see DifficultyRamp). This allows training regimes
+// in which difficulty keeps increasing across episodes, rather than
+// restarting from the easiest level after every lost life.
+//
+// Asterix, SeaQuest, and Space Invaders keep their spawn rates and
+// speeds. Breakout and Freeway have no difficulty ramp, so SoftReset is
+// equivalent to Reset for them.
+func (e *Environment) SoftReset() {
+	soft, ok := e.Game.(game.SoftResetter)
+	if !ok {
+		e.Reset()
+		return
+	}
+	e.reset(soft.SoftReset)
+}
@@ -0,0 +1,55 @@
+package goatar
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// TestSoftReset checks that SoftReset keeps the difficulty ramp of
+// each game across episodes, and is equivalent to Reset for games
+// without a difficulty ramp
+func TestSoftReset(t *testing.T) {
+	for _, g := range Games() {
+		env, err := New(g, 0.1, true, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		twin, err := New(g, 0.1, true, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rng := rand.New(rand.NewSource(1))
+		maxRamp := 0
+		for i := 0; i < 20000; i++ {
+			a := rng.Intn(env.NumActions())
+			_, done, err := env.Act(a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			twin.Act(a)
+			if !done {
+				continue
+			}
+
+			ramp := env.DifficultyRamp()
+			env.SoftReset()
+			twin.Reset()
+			if env.DifficultyRamp() != ramp {
+				t.Fatalf("%v: soft reset changed difficulty ramp from %v "+
+					"to %v", g, ramp, env.DifficultyRamp())
+			}
+			if _, ok := env.Game.(game.SoftResetter); !ok &&
+				env.StateHash() != twin.StateHash() {
+				t.Fatalf("%v: soft reset differs from reset", g)
+			}
+			maxRamp = game.MaxInt(maxRamp, ramp)
+		}
+
+		if (g == Asterix || g == SeaQuest) && maxRamp == 0 {
+			t.Errorf("%v: difficulty never ramped", g)
+		}
+	}
+}
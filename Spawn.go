@@ -0,0 +1,52 @@
+package goatar
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// Spawn is an entity spawned into a game by a SpawnScheduler. Kind is
+// the type of the entity, named as in Objects, e.g. "gold". Entities
+// moving right spawn at the left edge of the screen in row Row, and
+// entities moving left spawn at the right edge.
+type Spawn = game.Spawn
+
+// SpawnScheduler decides when, where, and what entities spawn, in
+// place of a game's spawn timers and random number generator.
+// Spawns is called once at the start of every step with the step of
+// the episode, where the first step after a reset is step 0, and
+// returns the entities to spawn on that step.
+type SpawnScheduler = game.SpawnScheduler
+
+// SpawnSchedule is a SpawnScheduler which spawns a fixed list of
+// entities, each on its Step of every episode. Schedules replay
+// exactly the same spawns in every episode, regardless of seed.
+type SpawnSchedule = game.SpawnSchedule
+
+// WithSpawnScheduler returns an Option which spawns entities as chosen
+// by s rather than randomly. In Asterix, entities are "enemy" or "gold"
+// and spawn in rows 1 to 8, unless the row is already taken. In
+// SeaQuest, entities are "enemy_fish", "enemy_sub", or "diver" and
+// spawn in rows 1 to 8, and enemies are not spawned in rows the game
+// would not spawn them in. Acting returns an error if s spawns an
+// entity of another kind or in another row. This option can only be
+// used with Asterix and SeaQuest.
+func WithSpawnScheduler(s SpawnScheduler) Option {
+	return func(e *Environment) error {
+		if s == nil {
+			return fmt.Errorf("withSpawnScheduler: scheduler must not be nil")
+		}
+
+		switch e.gameName {
+		case Asterix:
+			e.gameConfig.asterix.SpawnScheduler = s
+		case SeaQuest:
+			e.gameConfig.seaQuest.SpawnScheduler = s
+		default:
+			return fmt.Errorf("withSpawnScheduler: spawn schedulers are not "+
+				"supported by %v", e.gameName)
+		}
+		return nil
+	}
+}
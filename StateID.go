@@ -0,0 +1,23 @@
+package goatar
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// StateID returns a compact integer identifying the current underlying
+// state of the game, for use as the index of a table in tabular
+// reinforcement learning or as a key for duplicate detection in search.
+//
+// If the game can be enumerated and the encoding of the current state
+// fits in 64 bits, as it always does in Breakout, the ID is that
+// encoding, which is distinct for distinct states, and exact is true.
+// Otherwise the ID is the hash of the state returned by StateHash,
+// which may rarely collide, and exact is false. Freeway can be
+// enumerated, but its encoding needs more than 64 bits, so its IDs are
+// hashed.
+func (e *Environment) StateID() (id uint64, exact bool) {
+	if g, ok := e.Game.(game.Enumerable); ok {
+		if code, err := g.Encode(); err == nil && code.IsUint64() {
+			return code.Uint64(), true
+		}
+	}
+	return e.StateHash(), false
+}
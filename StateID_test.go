@@ -0,0 +1,99 @@
+package goatar
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestStateID checks that state IDs are exact for Breakout, hashed
+// for the other games, and equal for environments in the same state
+func TestStateID(t *testing.T) {
+	for _, g := range Games() {
+		env, err := New(g, 0, true, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		twin, err := New(g, 0, true, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 100; i++ {
+			id, exact := env.StateID()
+			if twinID, _ := twin.StateID(); id != twinID {
+				t.Fatalf("%v: got different IDs %v and %v of the same "+
+					"state", g, id, twinID)
+			}
+			if exact != (g == Breakout) {
+				t.Fatalf("%v: got exact %v, want %v", g, exact, g == Breakout)
+			}
+			if !exact && id != env.StateHash() {
+				t.Fatalf("%v: got hashed ID %v, want the state hash %v", g,
+					id, env.StateHash())
+			}
+
+			a := i % NumActions
+			if _, done, err := env.Act(a); err != nil {
+				t.Fatal(err)
+			} else if done {
+				break
+			}
+			if _, _, err := twin.Act(a); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+// TestStateHashRNG checks that the state hash does not depend on the
+// state of the random number generators, which is hashed by RNGHash
+// instead
+func TestStateHashRNG(t *testing.T) {
+	newEnv := func(seed int64) *Environment {
+		env, err := New(Breakout, 0, true, seed, WithStochasticity(0.5))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return env
+	}
+
+	// Find an environment which starts in the same state as env, but
+	// whose random number generator is in a different state
+	env := newEnv(0)
+	want, err := env.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var twin *Environment
+	for seed := int64(1); twin == nil; seed++ {
+		other := newEnv(seed)
+		state, err := other.State()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reflect.DeepEqual(state, want) {
+			twin = other
+		}
+	}
+
+	if env.StateHash() != twin.StateHash() {
+		t.Error("got different state hashes of the same state")
+	}
+	if env.RNGHash() == twin.RNGHash() {
+		t.Error("got the same random number generator hash with " +
+			"different seeds")
+	}
+
+	s := env.Snapshot()
+	hash := env.RNGHash()
+	env.Reset()
+	if env.RNGHash() == hash {
+		t.Error("random number generator hash unchanged after reset")
+	}
+	if err := env.Restore(s); err != nil {
+		t.Fatal(err)
+	}
+	if env.RNGHash() != hash {
+		t.Error("random number generator hash changed by restore")
+	}
+}
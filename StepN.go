@@ -0,0 +1,35 @@
+package goatar
+
+import "fmt"
+
+// StepN takes action a for n steps, stopping early if the episode ends,
+// and returns the reward of each step taken and whether the episode
+// has ended. Each step is taken as by Act, with sticky actions applied
+// independently on each step, but the action is only validated once.
+// If an error occurs, the rewards of the steps taken before the error
+// are returned.
+func (e *Environment) StepN(a, n int) (rewards []float64, terminal bool,
+	err error) {
+	if n < 1 {
+		return nil, false, fmt.Errorf("stepN: number of steps must be "+
+			"positive but got %v", n)
+	}
+
+	envAction := a
+	if a, err = e.gameAction(a); err != nil {
+		return nil, false, fmt.Errorf("stepN: %v", err)
+	}
+
+	rewards = make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		reward, done, err := e.step(envAction, a)
+		if err != nil {
+			return rewards, done, err
+		}
+		rewards = append(rewards, reward)
+		if done {
+			return rewards, true, nil
+		}
+	}
+	return rewards, false, nil
+}
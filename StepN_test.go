@@ -0,0 +1,51 @@
+package goatar
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestStepN checks that StepN takes the same steps as repeated calls to
+// Act, including sticky actions, and stops when the episode ends
+func TestStepN(t *testing.T) {
+	for _, g := range Games() {
+		stepped, err := New(g, 0.25, true, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		acted, err := New(g, 0.25, true, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 200; i++ {
+			a, n := i%NumActions, i%7+1
+			rewards, terminal, err := stepped.StepN(a, n)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want []float64
+			var done bool
+			for len(want) < n && !done {
+				var reward float64
+				if reward, done, err = acted.Act(a); err != nil {
+					t.Fatal(err)
+				}
+				want = append(want, reward)
+			}
+			if !reflect.DeepEqual(rewards, want) || terminal != done {
+				t.Fatalf("%v: got rewards %v and terminal %v, want %v and %v",
+					g, rewards, terminal, want, done)
+			}
+			if stepped.StateHash() != acted.StateHash() {
+				t.Fatalf("%v: states differ after %v calls", g, i+1)
+			}
+
+			if done {
+				stepped.Reset()
+				acted.Reset()
+			}
+		}
+	}
+}
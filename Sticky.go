@@ -0,0 +1,82 @@
+package goatar
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// StickyFunc decides which action is executed when the agent intends
+// to take action intended and the previously executed action was last.
+// Random draws must be made using rng, which is the Environment's
+// sticky action random number generator, so that the Environment stays
+// reproducible.
+type StickyFunc func(intended, last Action, rng *rand.Rand) Action
+
+// WithStickyActionProbs returns an Option which sets the sticky action
+// probability of individual actions. When the agent intends to take an
+// action in probs, the previous action is repeated with the given
+// probability instead of the sticky action probability passed to New.
+// Other actions use the sticky action probability passed to New. Each
+// probability must be in [0, 1].
+func WithStickyActionProbs(probs map[Action]float64) Option {
+	return func(e *Environment) error {
+		if e.stickyFunc != nil {
+			return fmt.Errorf("withStickyActionProbs: cannot be used with " +
+				"WithStickyFunc")
+		}
+
+		e.stickyProbs = make(map[Action]float64, len(probs))
+		for a, p := range probs {
+			if a < 0 || int(a) >= NumActions {
+				return fmt.Errorf("withStickyActionProbs: invalid action %v",
+					a)
+			}
+			if p < 0 || p > 1 {
+				return fmt.Errorf("withStickyActionProbs: probability %v of "+
+					"%v ∉ [0, 1]", p, a)
+			}
+			e.stickyProbs[a] = p
+		}
+		return nil
+	}
+}
+
+// WithStickyFunc returns an Option which decides the action executed
+// on each step using f, in place of sticky actions. The first action of
+// each episode is always executed as intended, and f is not called for
+// it. The sticky action probability passed to New is ignored.
+func WithStickyFunc(f StickyFunc) Option {
+	return func(e *Environment) error {
+		if f == nil {
+			return fmt.Errorf("withStickyFunc: nil function")
+		}
+		if e.stickyProbs != nil {
+			return fmt.Errorf("withStickyFunc: cannot be used with " +
+				"WithStickyActionProbs")
+		}
+		e.stickyFunc = f
+		return nil
+	}
+}
+
+// stickyAction returns the game action executed when the agent intends
+// to take game action a on a step which is not the first of an episode
+func (e *Environment) stickyAction(a int) (int, error) {
+	if e.stickyFunc != nil {
+		executed := e.stickyFunc(Action(a), Action(e.lastAction), e.rng)
+		if executed < 0 || int(executed) >= NumActions {
+			return -1, fmt.Errorf("sticky function returned invalid "+
+				"action %v", executed)
+		}
+		return int(executed), nil
+	}
+
+	prob := e.stickyActionsProb
+	if p, ok := e.stickyProbs[Action(a)]; ok {
+		prob = p
+	}
+	if e.rng.Float64() < prob {
+		return e.lastAction, nil
+	}
+	return a, nil
+}
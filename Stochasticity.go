@@ -0,0 +1,39 @@
+package goatar
+
+import "fmt"
+
+// WithStochasticity returns an Option which sets the stochasticity
+// level of the game to level ∈ [0, 1]. The level is the fraction of
+// the random events in the game, such as the row in which an enemy
+// spawns, the side from which a diver spawns, or the speed of a car in
+// Freeway, which are random. The remaining events are drawn from
+// deterministic sequences which preserve the rates at which events
+// occur. A level of 1 is the default and matches MinAtar, while a
+// level of 0 makes the game fully deterministic, which is useful for
+// planning research.
+//
+// Sticky actions are not affected by the stochasticity level.
+func WithStochasticity(level float64) Option {
+	return func(e *Environment) error {
+		if level < 0 || level > 1 {
+			return fmt.Errorf("withStochasticity: level %v ∉ [0, 1]", level)
+		}
+		e.stochasticity = &level
+		return nil
+	}
+}
+
+// WithRandomDiverSide returns an Option which keeps the side from which
+// divers spawn random, as in MinAtar, regardless of the stochasticity
+// level set with WithStochasticity. This option can only be used with
+// SeaQuest.
+func WithRandomDiverSide() Option {
+	return func(e *Environment) error {
+		if e.gameName != SeaQuest {
+			return fmt.Errorf("withRandomDiverSide: random diver side is "+
+				"not supported by %v", e.gameName)
+		}
+		e.gameConfig.seaQuest.RandomDiverSide = true
+		return nil
+	}
+}
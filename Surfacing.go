@@ -0,0 +1,19 @@
+package goatar
+
+import "fmt"
+
+// WithSparseSurfacingReward returns an Option which removes the reward
+// for shooting enemies in SeaQuest, so that the only reward is the
+// bonus for surfacing with a full load of 6 divers. The sparse reward
+// makes SeaQuest a harder exploration problem. This option can only be
+// used with SeaQuest.
+func WithSparseSurfacingReward() Option {
+	return func(e *Environment) error {
+		if e.gameName != SeaQuest {
+			return fmt.Errorf("withSparseSurfacingReward: sparse surfacing "+
+				"reward is not supported by %v", e.gameName)
+		}
+		e.gameConfig.seaQuest.SparseSurfacingReward = true
+		return nil
+	}
+}
@@ -0,0 +1,59 @@
+package goatar
+
+import "fmt"
+
+// Symmetry describes how an Environment maps onto itself when its
+// screen is flipped horizontally, in terms of the Environment's
+// actions and observation channels
+type Symmetry struct {
+	// Actions[i] is the action whose effect mirrors that of action i.
+	// If the Environment was created with a custom action set, actions
+	// index into that action set.
+	Actions []int
+
+	// Channels[i] is the channel whose flipped contents give channel i
+	// of a horizontally flipped observation
+	Channels []int
+}
+
+// HorizontalSymmetry returns the Symmetry of the Environment under
+// horizontal flips of the screen. An error is returned if the game is
+// not horizontally symmetric, or if the Environment's action set
+// contains an action but not its mirror.
+func (e *Environment) HorizontalSymmetry() (Symmetry, error) {
+	m := e.Manifest()
+	if m.Mirror == nil {
+		return Symmetry{}, fmt.Errorf("horizontalSymmetry: %v is not "+
+			"horizontally symmetric", e.gameName)
+	}
+
+	actionSet := e.ActionSet()
+	index := make(map[Action]int, len(actionSet))
+	for i, a := range actionSet {
+		index[a] = i
+	}
+	actions := make([]int, len(actionSet))
+	for i, a := range actionSet {
+		mirrored, ok := index[m.Mirror.Actions[a]]
+		if !ok {
+			return Symmetry{}, fmt.Errorf("horizontalSymmetry: action "+
+				"set contains %v but not its mirror %v", a,
+				m.Mirror.Actions[a])
+		}
+		actions[i] = mirrored
+	}
+
+	channelIndex := make(map[string]int, len(m.Channels))
+	for i, ch := range m.Channels {
+		channelIndex[ch.Name] = i
+	}
+	channels := make([]int, len(m.Channels))
+	for i, ch := range m.Channels {
+		channels[i] = i
+		if mirrored, ok := m.Mirror.Channels[ch.Name]; ok {
+			channels[i] = channelIndex[mirrored]
+		}
+	}
+
+	return Symmetry{Actions: actions, Channels: channels}, nil
+}
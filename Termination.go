@@ -0,0 +1,35 @@
+package goatar
+
+import "fmt"
+
+// TerminalError is the error returned by Act when an action is taken
+// after the episode ended, in an Environment created with
+// WithStrictTermination
+type TerminalError struct {
+	Episode int               // Index of the episode which ended
+	Reason  TerminationReason // Reason the episode ended
+}
+
+// Error returns a description of the error
+func (err *TerminalError) Error() string {
+	return fmt.Sprintf("act: episode %v ended (%v) and must be reset "+
+		"before acting", err.Episode, err.Reason)
+}
+
+// WithStrictTermination returns an Option which makes Act return a
+// *TerminalError when an action is taken after the episode ended,
+// rather than returning a reward of 0 and ending the episode again.
+// The game is not stepped, so that training loops which forget to reset
+// the Environment fail rather than generating meaningless transitions.
+func WithStrictTermination() Option {
+	return func(e *Environment) error {
+		e.strictTermination = true
+		return nil
+	}
+}
+
+// NeedsReset returns whether the current episode has ended, so that
+// the Environment must be reset before further actions are taken
+func (e *Environment) NeedsReset() bool {
+	return e.episodeOver
+}
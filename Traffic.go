@@ -0,0 +1,47 @@
+package goatar
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game/freeway"
+)
+
+// FreewayConfig configures the traffic of Freeway: the number of lanes
+// with a car, what happens when a car leaves the screen, and whether a
+// second chicken crosses the road. The zero value matches MinAtar.
+type FreewayConfig = freeway.Config
+
+// CarRespawn determines what happens when a car leaves the screen in
+// Freeway
+type CarRespawn = freeway.CarRespawn
+
+const (
+	// WrapAround moves the car to the other side of the screen
+	WrapAround CarRespawn = freeway.WrapAround
+
+	// NewSpeed moves the car to the other side of the screen with a
+	// new random speed, so that traffic changes during a crossing
+	NewSpeed CarRespawn = freeway.NewSpeed
+)
+
+// WithFreewayConfig returns an Option which configures the traffic of
+// Freeway. If config.Version is 0, the version set by WithVersion, if
+// any, is kept; otherwise it sets the version of the game as
+// WithVersion does. With config.SecondChicken, the second chicken is
+// controlled through ActPlayers. This option can only be used with
+// Freeway.
+func WithFreewayConfig(config FreewayConfig) Option {
+	return func(e *Environment) error {
+		if e.gameName != Freeway {
+			return fmt.Errorf("withFreewayConfig: traffic configuration "+
+				"is not supported by %v", e.gameName)
+		}
+		if config.Version == 0 {
+			config.Version = e.gameConfig.freeway.Version
+		} else if err := WithVersion(config.Version)(e); err != nil {
+			return fmt.Errorf("withFreewayConfig: %v", err)
+		}
+		e.gameConfig.freeway = config
+		return nil
+	}
+}
@@ -0,0 +1,198 @@
+package goatar
+
+import (
+	"fmt"
+	"sort"
+)
+
+// WithTrails returns an Option which lengthens the trail channels of
+// the game, which mark the cell behind each moving object, so that
+// they show where objects were over the last k steps rather than only
+// on the last step. The trails are stacked from the history of each
+// channel: the trail drawn j steps ago has value 1 - j/k, so that with
+// k = 4 trails decay through 1, 0.75, 0.5, and 0.25, and the most
+// recent value of a cell is kept where trails overlap. A length of 1
+// keeps the single-step trails.
+//
+// If channels are given, only those trail channels are lengthened, and
+// otherwise all trail channels of the game are (see Manifest().Trails).
+// The option can be used several times to lengthen different channels
+// by different amounts, with lengths given for named channels taking
+// precedence. Lengthened channels are no longer binary, and are
+// flagged as NonBinary in the Manifest.
+func WithTrails(k int, channels ...string) Option {
+	return func(e *Environment) error {
+		if k < 1 {
+			return fmt.Errorf("withTrails: trail length must be positive "+
+				"but got %v", k)
+		}
+		if e.trailLengths == nil {
+			e.trailLengths = make(map[string]int)
+		}
+		if len(channels) == 0 {
+			e.trailLengths[""] = k
+		}
+		for _, ch := range channels {
+			if ch == "" {
+				return fmt.Errorf("withTrails: empty channel name")
+			}
+			e.trailLengths[ch] = k
+		}
+		return nil
+	}
+}
+
+// trailStack lengthens trail channels by stacking the history of each
+// channel into the channel itself
+type trailStack struct {
+	size     int   // Number of elements in a single channel
+	channels []int // Indices of the lengthened channels
+	lengths  []int // Length of the trail of each lengthened channel
+
+	// history[i] holds the last lengths[i]-1 frames of channel i, most
+	// recent first
+	history [][]float64
+}
+
+// initTrails resolves the trail lengths requested with WithTrails to
+// the channels of the game
+func (e *Environment) initTrails() error {
+	if e.trailLengths == nil {
+		return nil
+	}
+
+	m := e.Game.Manifest()
+	indices := make(map[string]int, len(m.Channels))
+	for i, ch := range m.Channels {
+		indices[ch.Name] = i
+	}
+	isTrail := make(map[string]bool, len(m.Trails))
+	for _, name := range m.Trails {
+		isTrail[name] = true
+	}
+
+	lengths := make(map[string]int)
+	if k, ok := e.trailLengths[""]; ok {
+		for _, name := range m.Trails {
+			lengths[name] = k
+		}
+	}
+	for name, k := range e.trailLengths {
+		if name == "" {
+			continue
+		}
+		if !isTrail[name] {
+			return fmt.Errorf("initTrails: %v has no trail channel %q",
+				e.gameName, name)
+		}
+		lengths[name] = k
+	}
+
+	t := &trailStack{size: e.Game.Shape().ChannelSize()}
+	for name, k := range lengths {
+		if k > 1 {
+			t.channels = append(t.channels, indices[name])
+		}
+	}
+	sort.Ints(t.channels)
+	for _, ch := range t.channels {
+		k := lengths[m.Channels[ch].Name]
+		t.lengths = append(t.lengths, k)
+		t.history = append(t.history, make([]float64, (k-1)*t.size))
+	}
+
+	if len(t.channels) > 0 {
+		e.trails = t
+	}
+	return nil
+}
+
+// pushTrails adds the current trail channels of the game to the
+// history of the trails, before the game is stepped
+func (e *Environment) pushTrails() error {
+	if e.trails == nil {
+		return nil
+	}
+
+	state, err := e.Game.State()
+	if err != nil {
+		return err
+	}
+	t := e.trails
+	for i, ch := range t.channels {
+		h := t.history[i]
+		copy(h[t.size:], h)
+		copy(h, state[ch*t.size:(ch+1)*t.size])
+	}
+	return nil
+}
+
+// resetTrails clears the history of the trails at the start of an
+// episode
+func (e *Environment) resetTrails() {
+	if e.trails == nil {
+		return
+	}
+	for _, h := range e.trails.history {
+		for i := range h {
+			h[i] = 0
+		}
+	}
+}
+
+// stackTrails returns a copy of the state observation of the game with
+// the history of each lengthened trail channel stacked into it
+func (e *Environment) stackTrails(state []float64) []float64 {
+	t := e.trails
+	stacked := append([]float64(nil), state...)
+
+	for i, ch := range t.channels {
+		k := t.lengths[i]
+		channel := stacked[ch*t.size : (ch+1)*t.size]
+		h := t.history[i]
+		for j := 1; j < k; j++ {
+			value := 1 - float64(j)/float64(k)
+			frame := h[(j-1)*t.size : j*t.size]
+			for c, v := range frame {
+				if v != 0 && channel[c] < value*v {
+					channel[c] = value * v
+				}
+			}
+		}
+	}
+	return stacked
+}
+
+// saveTrails returns a copy of the history of the trails, or nil if no
+// trails are lengthened
+func (e *Environment) saveTrails() [][]float64 {
+	if e.trails == nil {
+		return nil
+	}
+	history := make([][]float64, len(e.trails.history))
+	for i, h := range e.trails.history {
+		history[i] = append([]float64(nil), h...)
+	}
+	return history
+}
+
+// restoreTrails restores the history of the trails saved by saveTrails
+func (e *Environment) restoreTrails(history [][]float64) {
+	if e.trails == nil || len(history) != len(e.trails.history) {
+		return
+	}
+	for i, h := range e.trails.history {
+		copy(h, history[i])
+	}
+}
+
+// markTrails flags the lengthened trail channels in channels, which
+// describes the channels of the game, as non-binary
+func (e *Environment) markTrails(channels []ChannelInfo) {
+	if e.trails == nil {
+		return
+	}
+	for _, ch := range e.trails.channels {
+		channels[ch].NonBinary = true
+	}
+}
@@ -0,0 +1,72 @@
+package goatar
+
+import "testing"
+
+// TestTrails checks that lengthened trails in Breakout stack the single
+// step trails of previous steps with decaying values
+func TestTrails(t *testing.T) {
+	const k = 3
+	env, err := New(Breakout, 0, false, 2, WithTrails(k))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := New(Breakout, 0, false, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m := env.Manifest(); !m.Channels[2].NonBinary ||
+		m.Channels[1].NonBinary {
+		t.Fatalf("got channels %v, want only the trail to be non-binary",
+			m.Channels)
+	}
+
+	// trails holds the single step trails of each step so far, most
+	// recent first
+	var trails [][]float64
+	for i, done := 0, false; !done; i++ {
+		single, err := plain.Channel(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trails = append([][]float64{append([]float64(nil), single...)},
+			trails...)
+
+		trail, err := env.observedChannel(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for c := range trail {
+			want := 0.0
+			for j := k - 1; j >= 0; j-- {
+				if j < len(trails) && trails[j][c] != 0 {
+					want = 1 - float64(j)/k
+				}
+			}
+			if trail[c] != want {
+				t.Fatalf("step %v: got trail %v in cell %v, want %v", i,
+					trail[c], c, want)
+			}
+		}
+
+		if _, done, err = env.Act(int(NoOp)); err != nil {
+			t.Fatal(err)
+		}
+		plain.Act(int(NoOp))
+	}
+}
+
+// TestTrailsInvalid checks that only trail channels can be lengthened
+func TestTrailsInvalid(t *testing.T) {
+	if _, err := New(Breakout, 0, false, 0, WithTrails(2, "ball")); err ==
+		nil {
+		t.Error("lengthened a channel which is not a trail")
+	}
+	if _, err := New(Breakout, 0, false, 0, WithTrails(0)); err == nil {
+		t.Error("accepted a trail length of 0")
+	}
+	if _, err := New(SpaceInvaders, 0, false, 0, WithTrails(2,
+		"enemy_bullet_trail")); err == nil {
+		t.Error("lengthened a trail channel which is disabled")
+	}
+}
@@ -0,0 +1,215 @@
+package goatar
+
+import "fmt"
+
+// Transform transforms a state observation of the given shape,
+// returning the shape of the transformed observation and the
+// transformed observation itself. The first dimension of a shape is
+// the channel dimension. Transforms may modify state in place, and the
+// shape they return must depend only on the shape they are given.
+type Transform func(shape []int, state []float64) ([]int, []float64)
+
+// WithTransforms returns an Option which applies transforms, in order,
+// to each state observation returned by State, so that common
+// preprocessing such as dropping channels, flattening, and
+// normalization can be done without nesting many wrappers. The
+// transforms are applied after the ramp channel, if any, is appended.
+//
+// The shape of transformed observations is tracked, and returned by
+// StateShape. Shape, NChannels, and Channel treat a transformed
+// observation of fewer than 3 dimensions as having leading dimensions
+// of size 1, so that a flattened observation of n elements has shape
+// (1, 1, n), while dimensions beyond the third are merged into the
+// columns. Manifest and the rendering methods always describe the
+// observation before any transforms.
+func WithTransforms(ts ...Transform) Option {
+	return func(e *Environment) error {
+		for i, t := range ts {
+			if t == nil {
+				return fmt.Errorf("withTransforms: transform %v is nil", i)
+			}
+		}
+		e.transforms = append(e.transforms, ts...)
+		return nil
+	}
+}
+
+// DropChannels returns a Transform which removes the given channels
+// from state observations
+func DropChannels(channels ...int) Transform {
+	drop := make(map[int]bool, len(channels))
+	for _, ch := range channels {
+		drop[ch] = true
+	}
+
+	return func(shape []int, state []float64) ([]int, []float64) {
+		size := 1
+		for _, dim := range shape[1:] {
+			size *= dim
+		}
+
+		kept := 0
+		for ch := 0; ch < shape[0]; ch++ {
+			if drop[ch] {
+				continue
+			}
+			copy(state[kept*size:], state[ch*size:(ch+1)*size])
+			kept++
+		}
+
+		shape = append([]int{kept}, shape[1:]...)
+		return shape, state[:kept*size]
+	}
+}
+
+// Flatten returns a Transform which flattens state observations into a
+// single dimension
+func Flatten() Transform {
+	return func(shape []int, state []float64) ([]int, []float64) {
+		return []int{len(state)}, state
+	}
+}
+
+// Normalize returns a Transform which normalizes each element x of
+// state observations to (x - mean) / std. The standard deviation must
+// be positive.
+func Normalize(mean, std float64) (Transform, error) {
+	if std <= 0 {
+		return nil, fmt.Errorf("normalize: standard deviation must be "+
+			"positive but got %v", std)
+	}
+
+	return func(shape []int, state []float64) ([]int, []float64) {
+		for i := range state {
+			state[i] = (state[i] - mean) / std
+		}
+		return shape, state
+	}, nil
+}
+
+// Transforms returns the transforms applied to state observations, see
+// WithTransforms
+func (e *Environment) Transforms() []Transform {
+	return append([]Transform(nil), e.transforms...)
+}
+
+// trackShape computes the shape of transformed state observations
+func (e *Environment) trackShape() error {
+	e.shape = nil
+	state, err := e.observe()
+	if err != nil {
+		return fmt.Errorf("trackShape: %v", err)
+	}
+
+	shape := e.observedShape().Ints()
+	for i, t := range e.transforms {
+		shape, state = t(shape, state)
+		if err := checkShape(shape, len(state)); err != nil {
+			return fmt.Errorf("trackShape: transform %v: %v", i, err)
+		}
+	}
+
+	e.shape = shape
+	return nil
+}
+
+// transform applies the transforms to state
+func (e *Environment) transform(state []float64) ([]float64, error) {
+	shape := e.observedShape().Ints()
+	for _, t := range e.transforms {
+		shape, state = t(shape, state)
+	}
+
+	if len(shape) != len(e.shape) {
+		return nil, fmt.Errorf("transform: shape changed from %v to %v",
+			e.shape, shape)
+	}
+	for i := range shape {
+		if shape[i] != e.shape[i] {
+			return nil, fmt.Errorf("transform: shape changed from %v to %v",
+				e.shape, shape)
+		}
+	}
+	return state, nil
+}
+
+// checkShape returns an error if shape is invalid for an observation
+// of size elements
+func checkShape(shape []int, size int) error {
+	if len(shape) == 0 {
+		return fmt.Errorf("empty shape")
+	}
+
+	elems := 1
+	for _, dim := range shape {
+		if dim < 0 {
+			return fmt.Errorf("negative dimension in shape %v", shape)
+		}
+		elems *= dim
+	}
+	if elems != size {
+		return fmt.Errorf("shape %v does not match observation of %v "+
+			"elements", shape, size)
+	}
+	return nil
+}
+
+// Shape returns the shape of state observations, including the ramp
+// channel if WithRampChannel was used. See WithTransforms for the shape
+// of transformed observations.
+func (e *Environment) Shape() Shape {
+	if e.transforms == nil {
+		return e.observedShape()
+	}
+
+	dims := []int{1, 1, 1}
+	if len(e.shape) <= 3 {
+		copy(dims[3-len(e.shape):], e.shape)
+	} else {
+		copy(dims, e.shape[:3])
+		for _, dim := range e.shape[3:] {
+			dims[2] *= dim
+		}
+	}
+	return Shape{Channels: dims[0], Rows: dims[1], Cols: dims[2]}
+}
+
+// StateShape returns the shape of state observations. Without
+// transforms, it is the shape as (channels, rows, cols) and is
+// equivalent to Shape().Ints().
+func (e *Environment) StateShape() []int {
+	if e.transforms == nil {
+		return e.Shape().Ints()
+	}
+	return append([]int(nil), e.shape...)
+}
+
+// NChannels returns the number of channels in state observations,
+// including the ramp channel if WithRampChannel was used
+func (e *Environment) NChannels() int {
+	return e.Shape().Channels
+}
+
+// Channel returns the matrix at channel i of the current state
+// observation. If WithRampChannel was used, the last channel before
+// any transforms is the ramp channel.
+func (e *Environment) Channel(i int) ([]float64, error) {
+	if e.transforms == nil {
+		return e.observedChannel(i)
+	}
+
+	if i >= e.NChannels() {
+		return nil, fmt.Errorf("channel: index out of range [%v] with "+
+			"length %v", i, e.NChannels())
+	} else if i < 0 {
+		return nil, fmt.Errorf("channel: invalid slice index %v (index "+
+			"must be non-negative)", i)
+	}
+
+	state, err := e.State()
+	if err != nil {
+		return nil, fmt.Errorf("channel: %v", err)
+	}
+	size := e.Shape().ChannelSize()
+	return state[i*size : (i+1)*size], nil
+}
@@ -0,0 +1,21 @@
+package goatar
+
+import "fmt"
+
+// WithTreasureRamping returns an Option which makes treasure in
+// Asterix more valuable as the difficulty increases: treasure picked
+// up at difficulty level l is worth 1 + l/4. The reward of each pickup
+// is reported through the reward events returned by Info. This is
+// useful for studying non-stationary reward scales, and is only
+// meaningful with difficulty ramping enabled. This option can only be
+// used with Asterix.
+func WithTreasureRamping() Option {
+	return func(e *Environment) error {
+		if e.gameName != Asterix {
+			return fmt.Errorf("withTreasureRamping: treasure ramping is "+
+				"not supported by %v", e.gameName)
+		}
+		e.gameConfig.asterix.TreasureRamping = true
+		return nil
+	}
+}
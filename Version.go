@@ -0,0 +1,85 @@
+package goatar
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samuelfneumann/goatar/internal/game/asterix"
+	"github.com/samuelfneumann/goatar/internal/game/breakout"
+	"github.com/samuelfneumann/goatar/internal/game/freeway"
+	"github.com/samuelfneumann/goatar/internal/game/seaquest"
+	"github.com/samuelfneumann/goatar/internal/game/spaceinvaders"
+)
+
+// Each game is versioned so that experiments can pin the behaviour of
+// the games they use. Whenever a change alters the behaviour of a
+// game, such as a bug fix which changes its dynamics or observations,
+// the game's version is incremented and the previous behaviour remains
+// available through WithVersion. The changes made in each version are
+// listed by Changelog.
+
+// versions returns the description of each version of the game name,
+// where element i describes version i+1
+func versions(name GameName) []string {
+	switch name {
+	case Asterix:
+		return asterix.Versions
+	case Breakout:
+		return breakout.Versions
+	case Freeway:
+		return freeway.Versions
+	case SeaQuest:
+		return seaquest.Versions
+	case SpaceInvaders:
+		return spaceinvaders.Versions
+	default:
+		return nil
+	}
+}
+
+// LatestVersion returns the latest version of the game name, which is
+// used unless another version is selected with WithVersion
+func LatestVersion(name GameName) int {
+	return len(versions(name))
+}
+
+// Changelog returns a description of the behaviour of each version of
+// the game name, where element i describes version i+1
+func Changelog(name GameName) []string {
+	return append([]string(nil), versions(name)...)
+}
+
+// WithVersion returns an Option which selects version v of the game's
+// behaviour, so that results can be reproduced after later versions
+// change the game. See Changelog for the differences between versions.
+func WithVersion(v int) Option {
+	return func(e *Environment) error {
+		latest := LatestVersion(e.gameName)
+		if v < 1 || v > latest {
+			return fmt.Errorf("withVersion: %v version %v ∉ [1, %v]",
+				e.gameName, v, latest)
+		}
+
+		e.version = v
+		switch e.gameName {
+		case Asterix:
+			e.gameConfig.asterix.Version = v
+		case Breakout:
+			e.gameConfig.breakout.Version = v
+		case Freeway:
+			e.gameConfig.freeway.Version = v
+		case SeaQuest:
+			e.gameConfig.seaQuest.Version = v
+		case SpaceInvaders:
+			e.gameConfig.spaceInvaders.Version = v
+		}
+		return nil
+	}
+}
+
+// Version returns the version of the game's behaviour as the name of
+// the game followed by the version number, e.g. "seaquest-v1"
+func (e *Environment) Version() string {
+	name := strings.ToLower(strings.ReplaceAll(e.gameName.string, " ", ""))
+	return fmt.Sprintf("%v-v%v", name, e.version)
+}
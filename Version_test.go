@@ -0,0 +1,191 @@
+package goatar
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// versionHashes are the hashes of a fixed trajectory of each version of
+// each game. If a change alters one of these hashes, it has changed the
+// behaviour of a game. Such changes must instead add a new version of
+// the game, keep the old behaviour available through WithVersion,
+// describe the change in the game's Versions, and add the hash of the
+// new version here.
+var versionHashes = map[GameName][]uint64{
+	Asterix:  {0xc8eca988d82fbdc2, 0x4b1fdf435a951418},
+	Breakout: {0x6ee737852ac2eb4, 0x16db24b39cfd1fbd},
+	Freeway:  {0x9aba68a6fd5ada2, 0xbd0ef1fec3913e65},
+	SeaQuest: {0xd60c657e11cc1337, 0x63ece37c175cb298},
+	SpaceInvaders: {0xa02c6ec4371321b4, 0x19a8a063b295ca95,
+		0xc5e011834d8cbcb4},
+}
+
+// trajectoryHash returns a hash of the states, rewards, and terminals
+// of a fixed trajectory of version v of the game name. Half of the
+// actions of the trajectory fire, and the rest are chosen uniformly at
+// random, so that the trajectory scores in every game but Freeway and
+// shoots enough aliens in Space Invaders for them to speed up.
+func trajectoryHash(t *testing.T, name GameName, v int) uint64 {
+	env, err := New(name, 0.1, true, 7, WithVersion(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	h := fnv.New64a()
+	var buf [8]byte
+	for i := 0; i < 2000; i++ {
+		a := rng.Intn(NumActions)
+		if rng.Float64() < 0.5 {
+			a = int(Fire)
+		}
+		reward, done, err := env.Act(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		state, err := env.State()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fmt.Fprint(h, reward, done)
+		for _, v := range state {
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+			h.Write(buf[:])
+		}
+		if done {
+			env.Reset()
+		}
+	}
+	return h.Sum64()
+}
+
+func TestVersionHashes(t *testing.T) {
+	for _, g := range Games() {
+		want := versionHashes[g]
+		if len(want) != LatestVersion(g) {
+			t.Errorf("%v: %v version hashes for %v versions", g, len(want),
+				LatestVersion(g))
+			continue
+		}
+
+		// Each version must change the trajectory
+		versions := make(map[uint64]int)
+		for i, w := range want {
+			got := trajectoryHash(t, g, i+1)
+			if got != w {
+				t.Errorf("%v version %v: trajectory hash %#x, want %#x", g,
+					i+1, got, w)
+			}
+			if v, ok := versions[got]; ok {
+				t.Errorf("%v versions %v and %v have the same trajectory",
+					g, v, i+1)
+			}
+			versions[got] = i + 1
+		}
+	}
+}
+
+func TestVersion(t *testing.T) {
+	env, err := New(SpaceInvaders, 0.0, true, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := env.Version(), "spaceinvaders-v3"; got != want {
+		t.Errorf("version %q, want %q", got, want)
+	}
+
+	env, err = New(Breakout, 0.0, true, 1, WithVersion(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := env.Version(), "breakout-v1"; got != want {
+		t.Errorf("version %q, want %q", got, want)
+	}
+
+	for _, v := range []int{0, LatestVersion(Breakout) + 1} {
+		if _, err := New(Breakout, 0.0, true, 1, WithVersion(v)); err == nil {
+			t.Errorf("version %v: expected error", v)
+		}
+	}
+}
+
+// TestConfigVersion checks that a version set in a game's configuration
+// sets the version of the environment, whichever option comes last
+func TestConfigVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "configuration",
+			opts: []Option{
+				WithSpaceInvadersConfig(SpaceInvadersConfig{Version: 1}),
+			},
+			want: "spaceinvaders-v1",
+		},
+		{
+			name: "configuration after WithVersion",
+			opts: []Option{
+				WithVersion(2),
+				WithSpaceInvadersConfig(SpaceInvadersConfig{Version: 1}),
+			},
+			want: "spaceinvaders-v1",
+		},
+		{
+			name: "WithVersion after configuration",
+			opts: []Option{
+				WithSpaceInvadersConfig(SpaceInvadersConfig{Version: 1}),
+				WithVersion(2),
+			},
+			want: "spaceinvaders-v2",
+		},
+		{
+			name: "configuration without version",
+			opts: []Option{
+				WithVersion(2),
+				WithSpaceInvadersConfig(SpaceInvadersConfig{AlienRows: 3}),
+			},
+			want: "spaceinvaders-v2",
+		},
+	}
+
+	for _, test := range tests {
+		env, err := New(SpaceInvaders, 0.1, true, 1, test.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := env.Version(); got != test.want {
+			t.Errorf("%v: got version %q, want %q", test.name, got,
+				test.want)
+		}
+	}
+
+	if _, err := New(SpaceInvaders, 0.1, true, 1,
+		WithSpaceInvadersConfig(SpaceInvadersConfig{Version: 4})); err == nil {
+		t.Error("expected error for invalid version")
+	}
+}
+
+// TestFreewayConfigVersion checks that a version set in the traffic
+// configuration of Freeway sets the version of the environment
+func TestFreewayConfigVersion(t *testing.T) {
+	env, err := New(Freeway, 0.1, true, 1, WithVersion(2),
+		WithFreewayConfig(FreewayConfig{Version: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := env.Version(), "freeway-v1"; got != want {
+		t.Errorf("got version %q, want %q", got, want)
+	}
+
+	if _, err := New(Freeway, 0.1, true, 1,
+		WithFreewayConfig(FreewayConfig{Version: 3})); err == nil {
+		t.Error("expected error for invalid version")
+	}
+}
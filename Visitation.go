@@ -0,0 +1,72 @@
+package goatar
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// visitationChannelName is the name of the channel added by
+// WithVisitationChannel
+const visitationChannelName = "visited"
+
+// WithVisitationChannel returns an Option which appends an extra
+// channel to state observations marking each cell which the player has
+// occupied during the current episode, as an episodic memory for
+// exploration research. The channel follows the ramp channel if
+// WithRampChannel is also used. This option can only be used with
+// games in which the player moves around the screen, which are
+// Asterix, Freeway, and SeaQuest.
+func WithVisitationChannel() Option {
+	return func(e *Environment) error {
+		if e.gameName != Asterix && e.gameName != Freeway &&
+			e.gameName != SeaQuest {
+			return fmt.Errorf("withVisitationChannel: visitation channel "+
+				"is not supported by %v", e.gameName)
+		}
+		e.visitationChannel = true
+		return nil
+	}
+}
+
+// resetVisits clears the visitation channel and marks the player's
+// current position as visited
+func (e *Environment) resetVisits() {
+	if !e.visitationChannel {
+		return
+	}
+
+	size := e.Game.Shape().ChannelSize()
+	if len(e.visits) != size {
+		e.visits = make([]float64, size)
+	}
+	for i := range e.visits {
+		e.visits[i] = 0
+	}
+	e.visit()
+}
+
+// visit marks the player's current position as visited
+func (e *Environment) visit() {
+	if e.visits == nil {
+		return
+	}
+	g, ok := e.Game.(game.Navigable)
+	if !ok {
+		return
+	}
+
+	shape := e.Game.Shape()
+	x, y := g.PlayerPosition()
+	if x >= 0 && x < shape.Cols && y >= 0 && y < shape.Rows {
+		e.visits[y*shape.Cols+x] = 1
+	}
+}
+
+// visitationChannelInfo describes the channel added by
+// WithVisitationChannel
+var visitationChannelInfo = ChannelInfo{
+	Name: visitationChannelName,
+	Description: "Cells occupied by the player during the current " +
+		"episode",
+}
@@ -0,0 +1,131 @@
+// Package abstraction implements state abstractions of GoAtar games for
+// research on state abstraction and on agents which exploit symmetry.
+//
+// A Canonicalizer maps each state of a horizontally symmetric game onto
+// a canonical state, by flipping the screen of states whose orientation
+// is negative. For example, canonical Breakout states are those in
+// which the ball moves right, so that a state and its mirror image,
+// which have mirrored values and optimal actions, share a canonical
+// state:
+//
+//	c, err := abstraction.NewCanonicalizer(env)
+//	...
+//	state, err := c.State()
+//	...
+//	a, err := c.Action(agent.Act(state))
+//	...
+//	reward, done, err := env.Act(a)
+//
+// Factored states, returned by (*goatar.Environment).Factors, describe
+// states by a small number of named, discrete variables, and each
+// factor records how it changes under flips of the screen. Key and
+// Reflect operate on factored states.
+package abstraction
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// Canonicalizer maps the states of an environment onto canonical
+// states. The orientation of a state is the sign of the factor named
+// by the Orientation of the game's Mirror, and states with a negative
+// orientation are canonicalized by flipping the screen horizontally.
+//
+// Canonicalization is supported by Breakout, in which the ball moves
+// right in canonical states, and SpaceInvaders, in which the aliens
+// move right. Asterix is horizontally symmetric but its states have no
+// orientation, so it cannot be canonicalized.
+type Canonicalizer struct {
+	env         *goatar.Environment
+	symmetry    goatar.Symmetry
+	orientation string
+}
+
+// NewCanonicalizer returns a new Canonicalizer which canonicalizes the
+// states of env. The environment's state observations must not be
+// transformed, see goatar.WithTransforms.
+func NewCanonicalizer(env *goatar.Environment) (*Canonicalizer, error) {
+	if len(env.Transforms()) > 0 {
+		return nil, fmt.Errorf("newCanonicalizer: cannot canonicalize " +
+			"transformed state observations")
+	}
+
+	symmetry, err := env.HorizontalSymmetry()
+	if err != nil {
+		return nil, fmt.Errorf("newCanonicalizer: %v", err)
+	}
+	orientation := env.Manifest().Mirror.Orientation
+	if orientation == "" {
+		return nil, fmt.Errorf("newCanonicalizer: %v states have no "+
+			"orientation", env.GameName())
+	}
+
+	return &Canonicalizer{
+		env:         env,
+		symmetry:    symmetry,
+		orientation: orientation,
+	}, nil
+}
+
+// Reflected returns whether the current state is flipped horizontally
+// to canonicalize it
+func (c *Canonicalizer) Reflected() bool {
+	for _, f := range c.env.Factors() {
+		if f.Name == c.orientation {
+			return f.Value < 0
+		}
+	}
+	return false
+}
+
+// State returns the canonical state observation of the current state
+func (c *Canonicalizer) State() ([]float64, error) {
+	state, err := c.env.State()
+	if err != nil {
+		return nil, fmt.Errorf("state: %v", err)
+	}
+	if !c.Reflected() {
+		return state, nil
+	}
+
+	shape := c.env.Shape()
+	size := shape.Rows * shape.Cols
+	flipped := make([]float64, len(state))
+	for ch, src := range c.symmetry.Channels {
+		dst := flipped[size*ch : size*(ch+1)]
+		from := state[size*src : size*(src+1)]
+		for r := 0; r < shape.Rows; r++ {
+			for col := 0; col < shape.Cols; col++ {
+				dst[r*shape.Cols+col] = from[r*shape.Cols+shape.Cols-1-col]
+			}
+		}
+	}
+	return flipped, nil
+}
+
+// Factors returns the canonical factored state of the current state
+func (c *Canonicalizer) Factors() []goatar.Factor {
+	factors := c.env.Factors()
+	if !c.Reflected() {
+		return factors
+	}
+	return Reflect(factors, c.env.Shape().Cols)
+}
+
+// Action returns the action of the environment whose effect in the
+// current state is the effect of action a in the canonical state, so
+// that actions chosen from canonical states can be taken in the
+// environment
+func (c *Canonicalizer) Action(a int) (int, error) {
+	if a < 0 || a >= len(c.symmetry.Actions) {
+		return -1, fmt.Errorf("action: invalid action %v ∉ [0, %v)", a,
+			len(c.symmetry.Actions))
+	}
+
+	if c.Reflected() {
+		return c.symmetry.Actions[a], nil
+	}
+	return a, nil
+}
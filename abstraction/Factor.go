@@ -0,0 +1,33 @@
+package abstraction
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// Reflect returns the factored state of the mirror image of the state
+// with the given factors, on a screen with the given number of columns
+func Reflect(factors []goatar.Factor, cols int) []goatar.Factor {
+	reflected := make([]goatar.Factor, len(factors))
+	for i, f := range factors {
+		reflected[i] = f
+		reflected[i].Value = f.Reflect(cols)
+	}
+	return reflected
+}
+
+// Key returns a string which identifies the values of a factored
+// state, so that factored states of the same game can be used as map
+// keys, e.g. to aggregate the states of a tabular abstraction
+func Key(factors []goatar.Factor) string {
+	var b strings.Builder
+	for i, f := range factors {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Itoa(f.Value))
+	}
+	return b.String()
+}
@@ -0,0 +1,202 @@
+// Package arena implements head-to-head comparisons of two policies.
+// Both policies are evaluated on exactly the same episodes: each
+// episode is seeded identically for both policies, so that the game's
+// randomness and the sticky actions noise are drawn from the same
+// streams. The paired returns are then compared with a Wilcoxon
+// signed-rank test, which has much more power than comparing the two
+// policies' mean returns, since the variance between episodes is
+// removed.
+//
+// For example, to compare two policies on every game over 5 seeds:
+//
+//	report, err := arena.Run(arena.ArenaConfig{
+//		Seeds:    []int64{0, 1, 2, 3, 4},
+//		Episodes: 100,
+//		A:        newPolicyA,
+//		B:        newPolicyB,
+//	})
+//	...
+//	err = report.WriteCSV(os.Stdout)
+package arena
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/rollout"
+	"github.com/samuelfneumann/goatar/sweep"
+)
+
+// PolicyFactory returns the policy evaluated in env on the run with
+// the given seed, see sweep.PolicyFactory
+type PolicyFactory = sweep.PolicyFactory
+
+// ArenaConfig describes a head-to-head comparison. For each game and
+// seed, both policies are evaluated for a number of episodes, and
+// episode i of each policy is seeded with s*Episodes + i, where s is
+// the seed.
+type ArenaConfig struct {
+	// Games are the games to compare the policies on. If empty, all
+	// games are used.
+	Games []goatar.GameName
+
+	// Seeds are the seeds of each run. If empty, a single run with
+	// seed 0 is used.
+	Seeds []int64
+
+	StickyActionsProb float64
+	DifficultyRamping bool
+
+	// Options are applied to every environment of the comparison
+	Options []goatar.Option
+
+	Episodes int // Number of episodes of each policy in each run
+	MaxSteps int // Maximum steps per episode, or 0 for no limit
+
+	// Workers is the number of runs evaluated in parallel. If 0, runs
+	// are evaluated sequentially.
+	Workers int
+
+	// A and B construct the two policies compared
+	A, B PolicyFactory
+}
+
+// withDefaults returns the configuration with default values filled in
+func (c ArenaConfig) withDefaults() ArenaConfig {
+	if len(c.Games) == 0 {
+		c.Games = goatar.Games()
+	}
+	if len(c.Seeds) == 0 {
+		c.Seeds = []int64{0}
+	}
+	if c.Workers == 0 {
+		c.Workers = 1
+	}
+	return c
+}
+
+// validate returns an error if the configuration is invalid
+func (c ArenaConfig) validate() error {
+	if c.A == nil || c.B == nil {
+		return fmt.Errorf("both policies must be given")
+	}
+	if c.Episodes <= 0 {
+		return fmt.Errorf("episodes must be positive but got %v", c.Episodes)
+	}
+	if c.MaxSteps < 0 {
+		return fmt.Errorf("maximum steps must be non-negative but got %v",
+			c.MaxSteps)
+	}
+	if c.Workers < 0 {
+		return fmt.Errorf("workers must be non-negative but got %v",
+			c.Workers)
+	}
+	if c.StickyActionsProb < 0 || c.StickyActionsProb > 1 {
+		return fmt.Errorf("sticky actions probability %v ∉ [0, 1]",
+			c.StickyActionsProb)
+	}
+	return nil
+}
+
+// run is a single game and seed of a comparison
+type run struct {
+	game  goatar.GameName
+	seed  int64
+	pairs []Pair
+}
+
+// Run runs the comparison described by cfg. Runs are evaluated in
+// parallel by cfg.Workers goroutines, and the results are the same
+// regardless of the number of workers, provided the policies are
+// deterministic given their seeds.
+func Run(cfg ArenaConfig) (*Report, error) {
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("run: %v", err)
+	}
+
+	var runs []run
+	for _, g := range cfg.Games {
+		for _, seed := range cfg.Seeds {
+			runs = append(runs, run{game: g, seed: seed})
+		}
+	}
+	workers := cfg.Workers
+	if workers > len(runs) {
+		workers = len(runs)
+	}
+
+	jobs := make(chan int)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := cfg.evaluate(&runs[i]); err != nil {
+					errs <- fmt.Errorf("%v seed %v: %v", runs[i].game,
+						runs[i].seed, err)
+					return
+				}
+			}
+		}()
+	}
+
+	// Distribute runs to workers, stopping early on error
+	var err error
+	for i := 0; i < len(runs) && err == nil; i++ {
+		select {
+		case jobs <- i:
+		case err = <-errs:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err == nil && len(errs) > 0 {
+		err = <-errs
+	}
+	if err != nil {
+		return nil, fmt.Errorf("run: %v", err)
+	}
+
+	return newReport(runs), nil
+}
+
+// evaluate evaluates both policies on a single run and stores the
+// paired returns in run
+func (c ArenaConfig) evaluate(r *run) error {
+	results := make([]rollout.Result, 2)
+	for i, factory := range []PolicyFactory{c.A, c.B} {
+		env, err := goatar.New(r.game, c.StickyActionsProb,
+			c.DifficultyRamping, r.seed, c.Options...)
+		if err != nil {
+			return err
+		}
+
+		p, err := factory(env, r.seed)
+		if err != nil {
+			return err
+		}
+
+		results[i], err = rollout.Evaluate(env, p, c.Episodes,
+			rollout.WithSeed(r.seed*int64(c.Episodes)),
+			rollout.WithMaxSteps(c.MaxSteps))
+		if err != nil {
+			return err
+		}
+	}
+
+	r.pairs = make([]Pair, c.Episodes)
+	for i := range r.pairs {
+		r.pairs[i] = Pair{
+			Game:    r.game.String(),
+			Seed:    results[0].Seeds[i],
+			ReturnA: results[0].Returns[i],
+			ReturnB: results[1].Returns[i],
+		}
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package arena
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Pair is the pair of returns of the two policies on a single episode
+type Pair struct {
+	Game    string
+	Seed    int64 // Seed of the episode
+	ReturnA float64
+	ReturnB float64
+}
+
+// Comparison compares the two policies on a single game, over the
+// episodes of all seeds
+type Comparison struct {
+	Game     string
+	Episodes int
+
+	MeanReturnA float64
+	MeanReturnB float64
+
+	// MeanDiff is the mean of the paired differences ReturnA - ReturnB,
+	// and StdErrDiff is its standard error
+	MeanDiff   float64
+	StdErrDiff float64
+
+	// WinsA, WinsB, and Ties are the number of episodes on which each
+	// policy had the higher return, or the returns were equal
+	WinsA int
+	WinsB int
+	Ties  int
+
+	// Wilcoxon is the result of a Wilcoxon signed-rank test on the
+	// paired returns
+	Wilcoxon WilcoxonResult
+}
+
+// Report is the report of a comparison. Pairs has one row per episode
+// and Comparisons has one row per game, in the order given by the
+// comparison's configuration.
+type Report struct {
+	Pairs       []Pair
+	Comparisons []Comparison
+}
+
+// newReport returns the report of the given runs
+func newReport(runs []run) *Report {
+	var order []string
+	byGame := make(map[string][]Pair)
+	for _, r := range runs {
+		g := r.game.String()
+		if _, ok := byGame[g]; !ok {
+			order = append(order, g)
+		}
+		byGame[g] = append(byGame[g], r.pairs...)
+	}
+
+	report := &Report{Comparisons: make([]Comparison, len(order))}
+	for i, g := range order {
+		pairs := byGame[g]
+		report.Pairs = append(report.Pairs, pairs...)
+		report.Comparisons[i] = compare(g, pairs)
+	}
+	return report
+}
+
+// compare returns the comparison of the paired returns of a game
+func compare(game string, pairs []Pair) Comparison {
+	c := Comparison{Game: game, Episodes: len(pairs)}
+
+	a := make([]float64, len(pairs))
+	b := make([]float64, len(pairs))
+	diffs := make([]float64, len(pairs))
+	for i, p := range pairs {
+		a[i], b[i] = p.ReturnA, p.ReturnB
+		diffs[i] = p.ReturnA - p.ReturnB
+		switch {
+		case diffs[i] > 0:
+			c.WinsA++
+		case diffs[i] < 0:
+			c.WinsB++
+		default:
+			c.Ties++
+		}
+	}
+
+	c.MeanReturnA, _ = meanStdDev(a)
+	c.MeanReturnB, _ = meanStdDev(b)
+	var std float64
+	c.MeanDiff, std = meanStdDev(diffs)
+	c.StdErrDiff = std / math.Sqrt(float64(len(diffs)))
+	c.Wilcoxon = Wilcoxon(a, b)
+	return c
+}
+
+// WriteCSV writes the comparisons of the report to w as CSV, with a
+// header row
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"game", "episodes", "mean_return_a", "mean_return_b",
+		"mean_diff", "stderr_diff", "wins_a", "wins_b", "ties",
+		"wilcoxon_n", "wilcoxon_w", "wilcoxon_z", "p_value", "exact"})
+	for _, c := range r.Comparisons {
+		cw.Write([]string{
+			c.Game,
+			strconv.Itoa(c.Episodes),
+			formatFloat(c.MeanReturnA),
+			formatFloat(c.MeanReturnB),
+			formatFloat(c.MeanDiff),
+			formatFloat(c.StdErrDiff),
+			strconv.Itoa(c.WinsA),
+			strconv.Itoa(c.WinsB),
+			strconv.Itoa(c.Ties),
+			strconv.Itoa(c.Wilcoxon.N),
+			formatFloat(c.Wilcoxon.W),
+			formatFloat(c.Wilcoxon.Z),
+			formatFloat(c.Wilcoxon.PValue),
+			strconv.FormatBool(c.Wilcoxon.Exact),
+		})
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("writeCSV: %v", err)
+	}
+	return nil
+}
+
+// WritePairsCSV writes the paired returns of the report to w as CSV,
+// with a header row
+func (r *Report) WritePairsCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"game", "seed", "return_a", "return_b"})
+	for _, p := range r.Pairs {
+		cw.Write([]string{
+			p.Game,
+			strconv.FormatInt(p.Seed, 10),
+			formatFloat(p.ReturnA),
+			formatFloat(p.ReturnB),
+		})
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("writePairsCSV: %v", err)
+	}
+	return nil
+}
+
+// jsonComparison is the JSON encoding of a Comparison
+type jsonComparison struct {
+	Game        string  `json:"game"`
+	Episodes    int     `json:"episodes"`
+	MeanReturnA float64 `json:"mean_return_a"`
+	MeanReturnB float64 `json:"mean_return_b"`
+	MeanDiff    float64 `json:"mean_diff"`
+	StdErrDiff  float64 `json:"stderr_diff"`
+	WinsA       int     `json:"wins_a"`
+	WinsB       int     `json:"wins_b"`
+	Ties        int     `json:"ties"`
+	WilcoxonN   int     `json:"wilcoxon_n"`
+	WilcoxonW   float64 `json:"wilcoxon_w"`
+	WilcoxonZ   float64 `json:"wilcoxon_z"`
+	PValue      float64 `json:"p_value"`
+	Exact       bool    `json:"exact"`
+}
+
+// jsonPair is the JSON encoding of a Pair
+type jsonPair struct {
+	Game    string  `json:"game"`
+	Seed    int64   `json:"seed"`
+	ReturnA float64 `json:"return_a"`
+	ReturnB float64 `json:"return_b"`
+}
+
+// WriteJSON writes the report to w as a JSON object with the fields
+// "comparisons" and "pairs"
+func (r *Report) WriteJSON(w io.Writer) error {
+	report := struct {
+		Comparisons []jsonComparison `json:"comparisons"`
+		Pairs       []jsonPair       `json:"pairs"`
+	}{
+		Comparisons: make([]jsonComparison, len(r.Comparisons)),
+		Pairs:       make([]jsonPair, len(r.Pairs)),
+	}
+	for i, c := range r.Comparisons {
+		report.Comparisons[i] = jsonComparison{
+			Game:        c.Game,
+			Episodes:    c.Episodes,
+			MeanReturnA: c.MeanReturnA,
+			MeanReturnB: c.MeanReturnB,
+			MeanDiff:    c.MeanDiff,
+			StdErrDiff:  c.StdErrDiff,
+			WinsA:       c.WinsA,
+			WinsB:       c.WinsB,
+			Ties:        c.Ties,
+			WilcoxonN:   c.Wilcoxon.N,
+			WilcoxonW:   c.Wilcoxon.W,
+			WilcoxonZ:   c.Wilcoxon.Z,
+			PValue:      c.Wilcoxon.PValue,
+			Exact:       c.Wilcoxon.Exact,
+		}
+	}
+	for i, p := range r.Pairs {
+		report.Pairs[i] = jsonPair(p)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("writeJSON: %v", err)
+	}
+	return nil
+}
+
+// formatFloat formats x for CSV
+func formatFloat(x float64) string {
+	return strconv.FormatFloat(x, 'g', -1, 64)
+}
+
+// meanStdDev returns the mean and sample standard deviation of x
+func meanStdDev(x []float64) (float64, float64) {
+	mean := 0.0
+	for _, v := range x {
+		mean += v
+	}
+	mean /= float64(len(x))
+
+	if len(x) < 2 {
+		return mean, 0
+	}
+
+	variance := 0.0
+	for _, v := range x {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(x) - 1)
+
+	return mean, math.Sqrt(variance)
+}
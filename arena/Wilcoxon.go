@@ -0,0 +1,137 @@
+package arena
+
+import (
+	"math"
+	"sort"
+)
+
+// exactMaxPairs is the largest number of non-zero differences for
+// which the exact distribution of the Wilcoxon statistic is used
+const exactMaxPairs = 30
+
+// WilcoxonResult is the result of a Wilcoxon signed-rank test
+type WilcoxonResult struct {
+	// N is the number of non-zero differences, which are the only
+	// differences used by the test
+	N int
+
+	// W is the sum of the ranks of the positive differences
+	W float64
+
+	// Z is the normal approximation of W. It is 0 if the exact
+	// distribution of W was used.
+	Z float64
+
+	// PValue is the two-sided p-value of the null hypothesis that the
+	// differences are symmetric about zero. It is 1 if N is 0.
+	PValue float64
+
+	Exact bool // Whether the exact distribution of W was used
+}
+
+// Wilcoxon performs a two-sided Wilcoxon signed-rank test of the null
+// hypothesis that the distribution of x[i] - y[i] is symmetric about
+// zero. Zero differences are dropped, and tied absolute differences
+// are given their average rank. The exact distribution of the test
+// statistic is used if there are at most 30 non-zero differences and
+// no ties, and otherwise its normal approximation is used, with a
+// continuity and tie correction. Wilcoxon panics if x and y have
+// different lengths.
+func Wilcoxon(x, y []float64) WilcoxonResult {
+	if len(x) != len(y) {
+		panic("wilcoxon: samples must have the same length")
+	}
+
+	var diffs []float64
+	for i := range x {
+		if d := x[i] - y[i]; d != 0 {
+			diffs = append(diffs, d)
+		}
+	}
+	n := len(diffs)
+	if n == 0 {
+		return WilcoxonResult{PValue: 1}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return math.Abs(diffs[i]) < math.Abs(diffs[j])
+	})
+
+	// Rank the absolute differences, averaging the ranks of ties
+	w := 0.0
+	tieCorrection := 0.0
+	for i := 0; i < n; {
+		j := i
+		for j < n && math.Abs(diffs[j]) == math.Abs(diffs[i]) {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if diffs[k] > 0 {
+				w += rank
+			}
+		}
+		t := float64(j - i)
+		tieCorrection += t*t*t - t
+		i = j
+	}
+
+	if n <= exactMaxPairs && tieCorrection == 0 {
+		return WilcoxonResult{
+			N:      n,
+			W:      w,
+			PValue: exactPValue(n, w),
+			Exact:  true,
+		}
+	}
+
+	mean := float64(n*(n+1)) / 4
+	variance := float64(n*(n+1)*(2*n+1))/24 - tieCorrection/48
+	z := 0.0
+	if variance > 0 {
+		// Continuity correction towards the mean
+		d := w - mean
+		if d > 0 {
+			d = math.Max(d-0.5, 0)
+		} else {
+			d = math.Min(d+0.5, 0)
+		}
+		z = d / math.Sqrt(variance)
+	}
+
+	return WilcoxonResult{
+		N:      n,
+		W:      w,
+		Z:      z,
+		PValue: math.Min(1, math.Erfc(math.Abs(z)/math.Sqrt2)),
+	}
+}
+
+// exactPValue returns the two-sided p-value of the Wilcoxon statistic
+// w with n non-zero differences and no ties
+func exactPValue(n int, w float64) float64 {
+	// counts[s] is the number of subsets of the ranks 1, ..., n whose
+	// sum is s, each of which is an equally likely assignment of
+	// positive signs under the null hypothesis
+	max := n * (n + 1) / 2
+	counts := make([]float64, max+1)
+	counts[0] = 1
+	for rank := 1; rank <= n; rank++ {
+		for s := max; s >= rank; s-- {
+			counts[s] += counts[s-rank]
+		}
+	}
+
+	// The distribution is symmetric about max/2, so the two-sided
+	// p-value is twice the probability of the smaller tail
+	tail := w
+	if tail > float64(max)/2 {
+		tail = float64(max) - tail
+	}
+	p := 0.0
+	for s := 0; float64(s) <= tail; s++ {
+		p += counts[s]
+	}
+	p = 2 * p / math.Pow(2, float64(n))
+	return math.Min(1, p)
+}
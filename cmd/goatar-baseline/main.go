@@ -0,0 +1,115 @@
+// Command goatar-baseline runs simple baseline policies on every
+// GoAtar game and prints a table of their scores, which can be used to
+// sanity-check GoAtar against the reference numbers of MinAtar.
+//
+// Two kinds of policies are run: a uniform random policy over the
+// minimal action set of each game, and, for each action in the
+// minimal action set, a constant policy which always takes that
+// action:
+//
+//	goatar-baseline --episodes 100 --workers 8
+//
+// For each game and policy, the mean and standard deviation of the
+// episodic return and of the episode length are printed. Results are
+// reproducible for a fixed seed when a single worker is used.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"text/tabwriter"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/policy"
+	"github.com/samuelfneumann/goatar/rollout"
+)
+
+// baseline is a named policy evaluated on a single game
+type baseline struct {
+	name   string
+	policy rollout.Policy
+}
+
+func main() {
+	episodes := flag.Int("episodes", 100, "number of episodes per policy")
+	seed := flag.Int64("seed", 0, "seed of the first episode")
+	sticky := flag.Float64("sticky", 0.1, "sticky action probability")
+	ramping := flag.Bool("ramping", true, "enable difficulty ramping")
+	maxSteps := flag.Int("max-steps", 10000, "maximum number of steps "+
+		"per episode, or 0 for no limit")
+	workers := flag.Int("workers", 1, "number of episodes to run in "+
+		"parallel")
+	constant := flag.Bool("constant", true, "also run a constant policy "+
+		"for each action in the minimal action set")
+	flag.Parse()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "game\tpolicy\treturn\tstd\tlength\tstd")
+
+	for _, g := range goatar.Games() {
+		newEnv := func() (goatar.Env, error) {
+			return goatar.New(g, *sticky, *ramping, *seed)
+		}
+		env, err := goatar.New(g, *sticky, *ramping, *seed)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, b := range baselines(env, *seed, *constant) {
+			result, err := rollout.Evaluate(env, b.policy, *episodes,
+				rollout.WithSeed(*seed), rollout.WithMaxSteps(*maxSteps),
+				rollout.WithWorkers(*workers, newEnv))
+			if err != nil {
+				log.Fatalf("%v %v: %v", g, b.name, err)
+			}
+
+			meanLength, stdLength := lengthStats(result.Lengths)
+			fmt.Fprintf(w, "%v\t%v\t%.3f\t%.3f\t%.1f\t%.1f\n", g, b.name,
+				result.MeanReturn, result.StdDevReturn, meanLength,
+				stdLength)
+		}
+	}
+	w.Flush()
+}
+
+// baselines returns the baseline policies for env: a uniform random
+// policy over the minimal action set and, if constant is true, a
+// constant policy for each action in the minimal action set
+func baselines(env *goatar.Environment, seed int64,
+	constant bool) []baseline {
+	b := []baseline{{
+		name:   "random",
+		policy: policy.Random(env, policy.WithSeed(seed)),
+	}}
+	if !constant {
+		return b
+	}
+
+	for _, a := range env.MinimalActionSet() {
+		b = append(b, baseline{
+			name:   fmt.Sprintf("always %v", goatar.Action(a)),
+			policy: policy.Constant(a),
+		})
+	}
+	return b
+}
+
+// lengthStats returns the mean and sample standard deviation of the
+// episode lengths
+func lengthStats(lengths []int) (mean, std float64) {
+	for _, l := range lengths {
+		mean += float64(l)
+	}
+	mean /= float64(len(lengths))
+
+	if len(lengths) < 2 {
+		return mean, 0
+	}
+	for _, l := range lengths {
+		std += (float64(l) - mean) * (float64(l) - mean)
+	}
+	return mean, math.Sqrt(std / float64(len(lengths)-1))
+}
@@ -0,0 +1,225 @@
+// Command goatar-bench measures the headless throughput of GoAtar, in
+// environment steps per second, so that performance can be compared
+// with MinAtar and regressions can be caught:
+//
+//	goatar-bench --steps 1000000 --envs 64 --workers 8 --json bench.json
+//
+// Each game is stepped with uniformly random actions, first in a single
+// environment on the calling goroutine, and then in a pool.Pool of
+// --envs environments stepped by --workers worker goroutines. Episodes
+// which end are reset, and the state observation is copied after every
+// step unless --states=false, as a learner would. A table of the
+// throughput of each game is printed, and with --json the results are
+// also written as a JSON report, or to stdout if the path is "-".
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"os"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/pool"
+)
+
+// result is the measured throughput of a single game
+type result struct {
+	Game string `json:"game"`
+
+	// Single is the number of steps per second of a single environment
+	Single float64 `json:"single_steps_per_sec"`
+
+	// Pool is the number of steps per second of the pool, summed over
+	// its environments
+	Pool float64 `json:"pool_steps_per_sec"`
+}
+
+// report is the JSON report written with --json
+type report struct {
+	GoVersion string   `json:"go_version"`
+	OS        string   `json:"os"`
+	Arch      string   `json:"arch"`
+	CPUs      int      `json:"cpus"`
+	Steps     int      `json:"steps"`
+	Envs      int      `json:"envs"`
+	Workers   int      `json:"workers"`
+	States    bool     `json:"states"`
+	Results   []result `json:"results"`
+}
+
+func main() {
+	gameName := flag.String("game", "", "game to benchmark, or all "+
+		"games if empty")
+	steps := flag.Int("steps", 1000000, "number of steps per "+
+		"measurement")
+	envs := flag.Int("envs", 64, "number of environments in the pool")
+	workers := flag.Int("workers", runtime.GOMAXPROCS(0), "number of "+
+		"worker goroutines of the pool")
+	states := flag.Bool("states", true, "copy the state observation "+
+		"after each step")
+	seed := flag.Int64("seed", 0, "seed of the environments and actions")
+	sticky := flag.Float64("sticky", 0.1, "sticky action probability")
+	ramping := flag.Bool("ramping", true, "enable difficulty ramping")
+	jsonPath := flag.String("json", "", "file to write a JSON report to, "+
+		"or - for stdout")
+	flag.Parse()
+
+	if *steps <= 0 {
+		log.Fatalf("steps must be positive but got %v", *steps)
+	}
+
+	games := goatar.Games()
+	if *gameName != "" {
+		g, err := goatar.ParseGameName(*gameName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		games = []goatar.GameName{g}
+	}
+
+	r := report{
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		Steps:     *steps,
+		Envs:      *envs,
+		Workers:   *workers,
+		States:    *states,
+	}
+	for _, g := range games {
+		env, err := goatar.New(g, *sticky, *ramping, *seed)
+		if err != nil {
+			log.Fatal(err)
+		}
+		single, err := benchSingle(env, *steps, *seed, *states)
+		if err != nil {
+			log.Fatalf("%v: %v", g, err)
+		}
+
+		opts := []pool.Option{pool.WithWorkers(*workers),
+			pool.WithSeed(*seed), pool.WithStickyActions(*sticky)}
+		if *ramping {
+			opts = append(opts, pool.WithRamping())
+		}
+		p, err := pool.New(g, *envs, opts...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		r.Workers = p.Workers()
+		parallel, err := benchPool(p, *steps, *seed, *states)
+		p.Close()
+		if err != nil {
+			log.Fatalf("%v: %v", g, err)
+		}
+
+		r.Results = append(r.Results, result{
+			Game:   g.String(),
+			Single: single,
+			Pool:   parallel,
+		})
+	}
+
+	if *jsonPath != "-" {
+		printTable(r)
+	}
+	if *jsonPath != "" {
+		if err := writeReport(r, *jsonPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// benchSingle steps env with random actions for the given number of
+// steps and returns the number of steps taken per second
+func benchSingle(env *goatar.Environment, steps int, seed int64,
+	states bool) (float64, error) {
+	rng := rand.New(rand.NewSource(seed))
+	n := env.NumActions()
+
+	start := time.Now()
+	for i := 0; i < steps; i++ {
+		_, done, err := env.Act(rng.Intn(n))
+		if err != nil {
+			return 0, err
+		}
+		if done {
+			env.Reset()
+		}
+		if states {
+			if _, err := env.State(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return float64(steps) / time.Since(start).Seconds(), nil
+}
+
+// benchPool steps every environment of p with random actions until at
+// least the given number of steps have been taken in total, and returns
+// the number of steps taken per second
+func benchPool(p *pool.Pool, steps int, seed int64,
+	states bool) (float64, error) {
+	rng := rand.New(rand.NewSource(seed))
+	n := p.NumActions()
+	actions := make([]int, p.Len())
+	var buf []float64
+	if states {
+		buf = make([]float64, p.Len()*p.StateSize())
+	}
+
+	taken := 0
+	start := time.Now()
+	for taken < steps {
+		for i := range actions {
+			actions[i] = rng.Intn(n)
+		}
+		if _, _, err := p.Step(actions, buf); err != nil {
+			return 0, err
+		}
+		taken += p.Len()
+	}
+	return float64(taken) / time.Since(start).Seconds(), nil
+}
+
+// printTable prints the results of r as a table
+func printTable(r report) {
+	fmt.Printf("%v %v/%v, %v CPUs, pool of %v environments on %v "+
+		"workers\n\n", r.GoVersion, r.OS, r.Arch, r.CPUs, r.Envs,
+		r.Workers)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "game\tsingle steps/s\tpool steps/s\tspeedup\t")
+	for _, res := range r.Results {
+		fmt.Fprintf(w, "%v\t%.0f\t%.0f\t%.2fx\t\n", res.Game, res.Single,
+			res.Pool, res.Pool/res.Single)
+	}
+	w.Flush()
+}
+
+// writeReport writes r as JSON to the file at path, or to stdout if
+// path is "-"
+func writeReport(r report, path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("writeReport: %v", err)
+	}
+	data = append(data, '\n')
+
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = ioutil.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		return fmt.Errorf("writeReport: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/config"
+	"github.com/samuelfneumann/goatar/monitor"
+	"github.com/samuelfneumann/goatar/render"
+)
+
+// maxBodySize is the maximum size of a request body in bytes
+const maxBodySize = 1 << 20
+
+// instance is an environment served by the server. Requests to the same
+// environment are serialised by its mutex.
+type instance struct {
+	mu      sync.Mutex
+	env     goatar.Env
+	tracker *monitor.Tracker
+}
+
+// server serves environments over HTTP
+type server struct {
+	mu      sync.Mutex
+	envs    map[string]*instance
+	nextID  int
+	maxEnvs int
+
+	registry *monitor.Registry // Metrics of the served environments
+}
+
+// newServer returns a server which serves at most maxEnvs environments
+// at once and reports their steps to registry
+func newServer(maxEnvs int, registry *monitor.Registry) *server {
+	return &server{
+		envs:     make(map[string]*instance),
+		nextID:   1,
+		maxEnvs:  maxEnvs,
+		registry: registry,
+	}
+}
+
+// envInfo describes an environment
+type envInfo struct {
+	ID         string   `json:"id"`
+	Game       string   `json:"game"`
+	Shape      []int    `json:"shape"`
+	NumActions int      `json:"num_actions"`
+	Actions    []string `json:"actions"`
+	RewardMin  float64  `json:"reward_min"`
+	RewardMax  float64  `json:"reward_max"`
+}
+
+// resetRequest is the body of a reset request
+type resetRequest struct {
+	Seed *int64 `json:"seed"`
+}
+
+// stepRequest is the body of a step request
+type stepRequest struct {
+	Action *int `json:"action"`
+}
+
+// stepResponse is the body of the response to a reset or step request
+type stepResponse struct {
+	Observation []float64 `json:"observation"`
+	Reward      float64   `json:"reward"`
+	Done        bool      `json:"done"`
+}
+
+// ServeHTTP routes requests to the handler of their endpoint
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "envs" || len(parts) > 3 {
+		writeError(w, http.StatusNotFound, "no such endpoint %v", r.URL.Path)
+		return
+	}
+
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodPost:
+			s.create(w, r)
+		case http.MethodGet:
+			s.list(w)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method %v not "+
+				"allowed", r.Method)
+		}
+		return
+	}
+
+	id := parts[1]
+	inst, ok := s.instance(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "no such environment %v", id)
+		return
+	}
+
+	endpoint := ""
+	if len(parts) == 3 {
+		endpoint = parts[2]
+	}
+	method := map[string]string{
+		"":       http.MethodDelete,
+		"reset":  http.MethodPost,
+		"step":   http.MethodPost,
+		"render": http.MethodGet,
+	}
+	want, ok := method[endpoint]
+	if !ok {
+		writeError(w, http.StatusNotFound, "no such endpoint %v", r.URL.Path)
+		return
+	}
+	if r.Method != want {
+		writeError(w, http.StatusMethodNotAllowed, "method %v not allowed",
+			r.Method)
+		return
+	}
+
+	switch endpoint {
+	case "":
+		s.close(w, id)
+	case "reset":
+		inst.reset(w, r)
+	case "step":
+		inst.step(w, r)
+	case "render":
+		inst.render(w, r)
+	}
+}
+
+// instance returns the environment with the given ID
+func (s *server) instance(id string) (*instance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inst, ok := s.envs[id]
+	return inst, ok
+}
+
+// create creates an environment from the configuration in the request
+// body
+func (s *server) create(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	cfg, err := config.ParseJSON(data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	s.mu.Lock()
+	if len(s.envs) >= s.maxEnvs {
+		s.mu.Unlock()
+		writeError(w, http.StatusServiceUnavailable, "too many open "+
+			"environments, at most %v are allowed", s.maxEnvs)
+		return
+	}
+	id := strconv.Itoa(s.nextID)
+	s.nextID++
+	s.mu.Unlock()
+
+	env, err := config.NewEnvironment(cfg)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	s.mu.Lock()
+	s.envs[id] = &instance{
+		env:     env,
+		tracker: s.registry.Track(env.GameName()),
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, describe(id, env))
+}
+
+// list lists the open environments
+func (s *server) list(w http.ResponseWriter) {
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.envs))
+	for id := range s.envs {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+
+	// Sort numerically so that environments are listed in the order
+	// they were created
+	sort.Slice(ids, func(i, j int) bool {
+		a, _ := strconv.Atoi(ids[i])
+		b, _ := strconv.Atoi(ids[j])
+		return a < b
+	})
+
+	infos := make([]envInfo, 0, len(ids))
+	for _, id := range ids {
+		if inst, ok := s.instance(id); ok {
+			inst.mu.Lock()
+			infos = append(infos, describe(id, inst.env))
+			inst.mu.Unlock()
+		}
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// close closes the environment with the given ID
+func (s *server) close(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	delete(s.envs, id)
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// reset resets the environment, optionally seeding it first, and
+// responds with the first observation
+func (inst *instance) reset(w http.ResponseWriter, r *http.Request) {
+	var req resetRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	if req.Seed != nil {
+		inst.env.Seed(*req.Seed)
+	}
+	inst.env.Reset()
+	inst.tracker.Reset()
+
+	state, err := inst.env.State()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stepResponse{Observation: state})
+}
+
+// step takes the action in the request body and responds with the next
+// observation, the reward, and whether the episode ended
+func (inst *instance) step(w http.ResponseWriter, r *http.Request) {
+	var req stepRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if req.Action == nil {
+		writeError(w, http.StatusBadRequest, "missing action")
+		return
+	}
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	if *req.Action < 0 || *req.Action >= inst.env.NumActions() {
+		writeError(w, http.StatusBadRequest, "action %v ∉ [0, %v)",
+			*req.Action, inst.env.NumActions())
+		return
+	}
+
+	reward, done, err := inst.env.Act(*req.Action)
+	var terminal *goatar.TerminalError
+	if errors.As(err, &terminal) {
+		writeError(w, http.StatusConflict, "%v", err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	inst.tracker.Record(reward, done)
+	state, err := inst.env.State()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stepResponse{
+		Observation: state,
+		Reward:      reward,
+		Done:        done,
+	})
+}
+
+// render responds with the current state rendered as a PNG image. The
+// scale query parameter sets the width and height of each cell in
+// pixels, which is 20 by default.
+func (inst *instance) render(w http.ResponseWriter, r *http.Request) {
+	scale := 20
+	if s := r.URL.Query().Get("scale"); s != "" {
+		var err error
+		scale, err = strconv.Atoi(s)
+		if err != nil || scale <= 0 || scale > 100 {
+			writeError(w, http.StatusBadRequest, "scale must be an integer "+
+				"in [1, 100] but got %q", s)
+			return
+		}
+	}
+
+	inst.mu.Lock()
+	state, err := inst.env.State()
+	shape := inst.env.StateShape()
+	inst.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	if len(shape) != 3 {
+		writeError(w, http.StatusBadRequest, "cannot render observations "+
+			"of shape %v", shape)
+		return
+	}
+
+	img := render.Rasterize(state, shape, render.DefaultPalette,
+		render.Options{CellSize: scale})
+	w.Header().Set("Content-Type", "image/png")
+	png.Encode(w, img)
+}
+
+// describe returns the description of env with the given ID
+func describe(id string, env goatar.Env) envInfo {
+	minReward, maxReward := env.RewardRange()
+
+	// Wrapped environments without an action set use the full action
+	// set, in which action i is goatar.Action(i)
+	actionSet := make([]goatar.Action, env.NumActions())
+	for i := range actionSet {
+		actionSet[i] = goatar.Action(i)
+	}
+	if e, ok := env.(interface{ ActionSet() []goatar.Action }); ok {
+		actionSet = e.ActionSet()
+	}
+	actions := make([]string, len(actionSet))
+	for i, a := range actionSet {
+		actions[i] = a.String()
+	}
+	return envInfo{
+		ID:         id,
+		Game:       env.GameName(),
+		Shape:      env.StateShape(),
+		NumActions: env.NumActions(),
+		Actions:    actions,
+		RewardMin:  minReward,
+		RewardMax:  maxReward,
+	}
+}
+
+// decode decodes the JSON request body into v. An empty body leaves v
+// unchanged.
+func decode(r *http.Request, v interface{}) error {
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		return err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	return nil
+}
+
+// writeJSON writes v to w as JSON with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error to w as JSON with the given status code
+func writeError(w http.ResponseWriter, status int, format string,
+	args ...interface{}) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{fmt.Sprintf(format, args...)})
+}
@@ -0,0 +1,75 @@
+// Command goatar-http serves GoAtar environments over HTTP with JSON
+// payloads, so that agents written in any language can interact with
+// GoAtar using nothing but an HTTP client.
+//
+//	goatar-http --addr localhost:5000
+//
+// The server exposes the following endpoints:
+//
+//	POST   /envs              create an environment
+//	GET    /envs              list environments
+//	POST   /envs/{id}/reset   reset an environment
+//	POST   /envs/{id}/step    take a step
+//	GET    /envs/{id}/render  render the current state as a PNG image
+//	DELETE /envs/{id}         close an environment
+//	GET    /metrics           metrics in the Prometheus text format
+//	GET    /debug/vars        metrics as JSON through expvar
+//
+// Environments are created from a configuration in the JSON format of
+// the config package, and the response describes the environment:
+//
+//	$ curl -d '{"game": "breakout", "seed": 1}' localhost:5000/envs
+//	{"id":"1","game":"Breakout","shape":[4,10,10],"num_actions":6,...}
+//
+// Resetting returns the first observation. The request body may set
+// the seed of the environment before it is reset:
+//
+//	$ curl -d '{"seed": 2}' localhost:5000/envs/1/reset
+//	{"observation":[0,0,...]}
+//
+// Stepping takes an action and returns the next observation, the
+// reward, and whether the episode ended:
+//
+//	$ curl -d '{"action": 3}' localhost:5000/envs/1/step
+//	{"observation":[0,0,...],"reward":0,"done":false}
+//
+// Observations are flattened in (channels, rows, columns) order. Errors
+// are returned with an appropriate status code and a JSON body of the
+// form {"error": "..."}.
+//
+// The steps taken in the served environments are monitored. Step
+// counts, throughput, and histograms of episode returns and lengths of
+// each game are served in the Prometheus text format at /metrics, and
+// as JSON through expvar at /debug/vars.
+package main
+
+import (
+	"expvar"
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/samuelfneumann/goatar/monitor"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:5000", "address to serve on")
+	maxEnvs := flag.Int("max-envs", 64, "maximum number of open "+
+		"environments")
+	flag.Parse()
+
+	if *maxEnvs <= 0 {
+		log.Fatalf("max-envs must be positive but got %v", *maxEnvs)
+	}
+
+	registry := monitor.NewRegistry()
+	registry.Publish("goatar")
+
+	mux := http.NewServeMux()
+	mux.Handle("/", newServer(*maxEnvs, registry))
+	mux.Handle("/metrics", registry)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	log.Printf("serving on http://%v", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
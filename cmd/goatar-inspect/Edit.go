@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/scenario"
+)
+
+// applier is a scenario which can be applied to an ongoing game
+type applier interface {
+	Apply(env *goatar.Environment) error
+}
+
+// spawnArgs are the arguments of a spawn command
+type spawnArgs struct {
+	x, y  int
+	o     goatar.Orientation
+	speed int
+}
+
+// spawnKind is a kind of object which can be spawned in a game
+type spawnKind struct {
+	name  string
+	usage string             // Arguments after the kind
+	dir   goatar.Orientation // Direction used if none is given
+	place func(a spawnArgs) applier
+}
+
+// spawnKinds are the kinds of objects which can be spawned in each game
+var spawnKinds = map[goatar.GameName][]spawnKind{
+	goatar.Asterix: {
+		{"enemy", "X Y [DIR]", goatar.FacingLeft,
+			func(a spawnArgs) applier {
+				return scenario.Asterix().Enemy(a.x, a.y, a.o)
+			}},
+		{"gold", "X Y [DIR]", goatar.FacingLeft,
+			func(a spawnArgs) applier {
+				return scenario.Asterix().Gold(a.x, a.y, a.o)
+			}},
+	},
+	goatar.Breakout: {
+		{"brick", "X Y", goatar.Unoriented,
+			func(a spawnArgs) applier {
+				return scenario.Breakout().Brick(a.x, a.y)
+			}},
+		{"ball", "X Y [DIR]", goatar.FacingDownRight,
+			func(a spawnArgs) applier {
+				return scenario.Breakout().Ball(a.x, a.y, a.o)
+			}},
+	},
+	goatar.Freeway: {
+		{"car", "X Y [DIR] [SPEED]", goatar.FacingLeft,
+			func(a spawnArgs) applier {
+				return scenario.Freeway().Car(a.x, a.y, a.o, a.speed)
+			}},
+	},
+	goatar.SeaQuest: {
+		{"fish", "X Y [DIR]", goatar.FacingLeft,
+			func(a spawnArgs) applier {
+				return scenario.SeaQuest().Fish(a.x, a.y, a.o)
+			}},
+		{"sub", "X Y [DIR]", goatar.FacingLeft,
+			func(a spawnArgs) applier {
+				return scenario.SeaQuest().EnemySub(a.x, a.y, a.o)
+			}},
+		{"diver", "X Y [DIR]", goatar.FacingLeft,
+			func(a spawnArgs) applier {
+				return scenario.SeaQuest().Diver(a.x, a.y, a.o)
+			}},
+		{"bullet", "X Y [DIR]", goatar.FacingLeft,
+			func(a spawnArgs) applier {
+				return scenario.SeaQuest().FriendlyBullet(a.x, a.y, a.o)
+			}},
+		{"enemy_bullet", "X Y [DIR]", goatar.FacingLeft,
+			func(a spawnArgs) applier {
+				return scenario.SeaQuest().EnemyBullet(a.x, a.y, a.o)
+			}},
+	},
+	goatar.SpaceInvaders: {
+		{"alien", "X Y", goatar.Unoriented,
+			func(a spawnArgs) applier {
+				return scenario.SpaceInvaders().Alien(a.x, a.y)
+			}},
+		{"bullet", "X Y", goatar.Unoriented,
+			func(a spawnArgs) applier {
+				return scenario.SpaceInvaders().FriendlyBullet(a.x, a.y)
+			}},
+		{"enemy_bullet", "X Y", goatar.Unoriented,
+			func(a spawnArgs) applier {
+				return scenario.SpaceInvaders().EnemyBullet(a.x, a.y)
+			}},
+	},
+}
+
+// setting is a property of a game which can be set to an integer
+type setting struct {
+	name string
+	set  func(n int) applier
+}
+
+// settings are the properties which can be set in each game
+var settings = map[goatar.GameName][]setting{
+	goatar.Breakout: {
+		{"paddle", func(n int) applier {
+			return scenario.Breakout().Paddle(n)
+		}},
+	},
+	goatar.Freeway: {
+		{"chicken", func(n int) applier {
+			return scenario.Freeway().Chicken(n)
+		}},
+	},
+	goatar.SeaQuest: {
+		{"oxygen", func(n int) applier {
+			return scenario.SeaQuest().Oxygen(n)
+		}},
+		{"divers", func(n int) applier {
+			return scenario.SeaQuest().Divers(n)
+		}},
+	},
+	goatar.SpaceInvaders: {
+		{"cannon", func(n int) applier {
+			return scenario.SpaceInvaders().Cannon(n)
+		}},
+		{"shot_timer", func(n int) applier {
+			return scenario.SpaceInvaders().ShotTimer(n)
+		}},
+	},
+}
+
+// gameName returns the name of the inspected game
+func (in *inspector) gameName() goatar.GameName {
+	name, _ := goatar.ParseGameName(in.env.GameName())
+	return name
+}
+
+// spawn places an object in the current state of the game
+func (in *inspector) spawn(args []string) error {
+	kinds := spawnKinds[in.gameName()]
+	if len(args) == 0 {
+		for _, k := range kinds {
+			fmt.Fprintf(in.out, "  spawn %v %v\n", k.name, k.usage)
+		}
+		return nil
+	}
+
+	var kind *spawnKind
+	for i := range kinds {
+		if strings.EqualFold(kinds[i].name, args[0]) {
+			kind = &kinds[i]
+		}
+	}
+	if kind == nil {
+		return fmt.Errorf("cannot spawn %q in %v, see \"spawn\"", args[0],
+			in.env.GameName())
+	}
+
+	a, err := parseSpawnArgs(args[1:], kind.dir)
+	if err != nil {
+		return fmt.Errorf("%v, usage: spawn %v %v", err, kind.name,
+			kind.usage)
+	}
+	return in.apply(kind.place(a))
+}
+
+// parseSpawnArgs parses the position, direction, and speed of an
+// object to spawn. Arguments after the position are a direction if they
+// are not a number, and a speed otherwise.
+func parseSpawnArgs(args []string, dir goatar.Orientation) (spawnArgs,
+	error) {
+	if len(args) < 2 || len(args) > 4 {
+		return spawnArgs{}, fmt.Errorf("wrong number of arguments")
+	}
+
+	a := spawnArgs{o: dir, speed: 1}
+	var err error
+	if a.x, err = strconv.Atoi(args[0]); err != nil {
+		return spawnArgs{}, fmt.Errorf("invalid column %q", args[0])
+	}
+	if a.y, err = strconv.Atoi(args[1]); err != nil {
+		return spawnArgs{}, fmt.Errorf("invalid row %q", args[1])
+	}
+	for _, arg := range args[2:] {
+		if speed, err := strconv.Atoi(arg); err == nil {
+			a.speed = speed
+		} else {
+			a.o = goatar.Orientation(strings.ToLower(arg))
+		}
+	}
+	return a, nil
+}
+
+// remove removes the objects other than the player at a cell
+func (in *inspector) remove(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: remove X Y")
+	}
+	x, errX := strconv.Atoi(args[0])
+	y, errY := strconv.Atoi(args[1])
+	if errX != nil || errY != nil {
+		return fmt.Errorf("invalid position (%v, %v)", args[0], args[1])
+	}
+
+	n, err := scenario.Remove(in.env, x, y)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(in.out, "removed %v objects\n", n)
+	return in.show(nil)
+}
+
+// clear removes all objects other than the player from the screen
+func (in *inspector) clear([]string) error {
+	if err := scenario.Clear(in.env); err != nil {
+		return err
+	}
+	return in.show(nil)
+}
+
+// set sets a property of the game, such as the oxygen in SeaQuest
+func (in *inspector) set(args []string) error {
+	props := settings[in.gameName()]
+	if len(args) != 2 {
+		names := make([]string, len(props))
+		for i, p := range props {
+			names[i] = p.name
+		}
+		return fmt.Errorf("usage: set NAME N, where NAME is one of: %v",
+			strings.Join(names, ", "))
+	}
+
+	n, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid value %q", args[1])
+	}
+	for _, p := range props {
+		if strings.EqualFold(p.name, args[0]) {
+			return in.apply(p.set(n))
+		}
+	}
+	return fmt.Errorf("cannot set %q in %v", args[0], in.env.GameName())
+}
+
+// apply applies a scenario to the game and prints the new state
+func (in *inspector) apply(a applier) error {
+	if err := a.Apply(in.env); err != nil {
+		return err
+	}
+	return in.show(nil)
+}
@@ -0,0 +1,541 @@
+// Command goatar-inspect is an interactive shell for stepping GoAtar
+// environments by hand, which is useful for debugging games and
+// wrappers, and for checking what an agent sees:
+//
+//	goatar-inspect --game seaquest --seed 3
+//
+// Each line entered is a command. Lines which do not begin with a
+// command name are treated as a sequence of actions, given by name
+// (e.g. "left") or by index into the action set, which are taken in
+// order. The available commands are:
+//
+//	auto N             take N uniformly random actions
+//	show               print the state as a grid of characters
+//	png FILE [SCALE]   write the state to FILE as a PNG image
+//	channels           list the channels of the game
+//	channel C          print channel C, given by name or index
+//	dump [FILE]        write the state to FILE as JSON, or print it
+//	info               print the episode, step, return, and last step
+//	snapshot NAME      save the current state of the environment
+//	restore NAME       restore a saved state of the environment
+//	snapshots          list the saved states
+//	seed N             seed the environment and reset it
+//	reset              reset the environment
+//	spawn KIND X Y ... place an object at column X and row Y, e.g.
+//	                   "spawn enemy 3 5 right"; lists the kinds if
+//	                   none is given
+//	remove X Y         remove the objects at column X and row Y
+//	clear              remove all objects other than the player
+//	set NAME N         set a property of the game, e.g. "set oxygen 50"
+//	help               list the commands
+//	quit               exit
+//
+// Commands may be abbreviated to any unique prefix. The state is
+// printed after each command which changes it. The editing commands
+// spawn, remove, clear, and set change the current state in place
+// using the scenario package, so that situations can be set up by hand.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/render"
+)
+
+func main() {
+	gameName := flag.String("game", "breakout", "game to inspect")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "random seed")
+	sticky := flag.Float64("sticky", 0.0, "sticky action probability")
+	ramping := flag.Bool("ramping", true, "enable difficulty ramping")
+	flag.Parse()
+
+	name, err := goatar.ParseGameName(*gameName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	env, err := goatar.New(name, *sticky, *ramping, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	in := newInspector(env, *seed, os.Stdout)
+	if err := in.run(os.Stdin); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// command is a command of the shell
+type command struct {
+	name  string
+	usage string
+	run   func(in *inspector, args []string) error
+}
+
+// commands are the commands of the shell, in the order they are listed
+// by help
+var commands []command
+
+func init() {
+	commands = []command{
+		{"auto", "auto N", (*inspector).auto},
+		{"show", "show", (*inspector).show},
+		{"png", "png FILE [SCALE]", (*inspector).png},
+		{"channels", "channels", (*inspector).channels},
+		{"channel", "channel C", (*inspector).channel},
+		{"dump", "dump [FILE]", (*inspector).dump},
+		{"info", "info", (*inspector).info},
+		{"snapshot", "snapshot NAME", (*inspector).snapshot},
+		{"restore", "restore NAME", (*inspector).restore},
+		{"snapshots", "snapshots", (*inspector).listSnapshots},
+		{"seed", "seed N", (*inspector).seed},
+		{"reset", "reset", (*inspector).reset},
+		{"spawn", "spawn KIND X Y [DIR] [SPEED]", (*inspector).spawn},
+		{"remove", "remove X Y", (*inspector).remove},
+		{"clear", "clear", (*inspector).clear},
+		{"set", "set NAME N", (*inspector).set},
+		{"help", "help", (*inspector).help},
+	}
+}
+
+// inspector holds the state of an interactive session
+type inspector struct {
+	env       *goatar.Environment
+	rng       *rand.Rand
+	out       io.Writer
+	snapshots map[string]*goatar.Snapshot
+
+	lastReward float64
+	done       bool
+}
+
+// newInspector returns a new inspector of env which writes to out
+func newInspector(env *goatar.Environment, seed int64,
+	out io.Writer) *inspector {
+	return &inspector{
+		env:       env,
+		rng:       rand.New(rand.NewSource(seed)),
+		out:       out,
+		snapshots: make(map[string]*goatar.Snapshot),
+	}
+}
+
+// run runs the session, reading commands from r until the end of input
+// or a quit command
+func (in *inspector) run(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	in.show(nil)
+
+	for {
+		fmt.Fprint(in.out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(in.out)
+			return scanner.Err()
+		}
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if strings.EqualFold(fields[0], "quit") ||
+			strings.EqualFold(fields[0], "q") {
+			return nil
+		}
+
+		if err := in.exec(fields); err != nil {
+			fmt.Fprintf(in.out, "error: %v\n", err)
+		}
+	}
+}
+
+// exec executes a single line of input, split into fields
+func (in *inspector) exec(fields []string) error {
+	cmd, err := lookup(fields[0])
+	if err != nil {
+		// Not a command, so the line must be a sequence of actions
+		if _, actErr := in.parseAction(fields[0]); actErr != nil {
+			return err
+		}
+		return in.act(fields)
+	}
+	return cmd.run(in, fields[1:])
+}
+
+// lookup returns the command with the given name or unique prefix
+func lookup(name string) (command, error) {
+	name = strings.ToLower(name)
+	var matches []command
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, nil
+		}
+		if strings.HasPrefix(cmd.name, name) {
+			matches = append(matches, cmd)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return command{}, fmt.Errorf("unknown command or action %q", name)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, len(matches))
+		for i, cmd := range matches {
+			names[i] = cmd.name
+		}
+		return command{}, fmt.Errorf("ambiguous command %q: %v", name,
+			strings.Join(names, ", "))
+	}
+}
+
+// act takes the actions given by fields in order. Nothing is taken if
+// any field is not an action.
+func (in *inspector) act(fields []string) error {
+	actions := make([]int, len(fields))
+	for i, field := range fields {
+		a, err := in.parseAction(field)
+		if err != nil {
+			return err
+		}
+		actions[i] = a
+	}
+
+	for _, a := range actions {
+		if err := in.step(a); err != nil {
+			return err
+		}
+		if in.done {
+			break
+		}
+	}
+	return in.show(nil)
+}
+
+// parseAction returns the index into the action set of the action
+// given by name or by index
+func (in *inspector) parseAction(s string) (int, error) {
+	if i, err := strconv.Atoi(s); err == nil {
+		if i < 0 || i >= in.env.NumActions() {
+			return -1, fmt.Errorf("action %v ∉ [0, %v)", i,
+				in.env.NumActions())
+		}
+		return i, nil
+	}
+
+	action, err := goatar.ParseAction(s)
+	if err != nil {
+		return -1, err
+	}
+	for i, a := range in.env.ActionSet() {
+		if a == action {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("action %v is not in the action set", action)
+}
+
+// step takes action a. If the episode is over, the environment is
+// reset first.
+func (in *inspector) step(a int) error {
+	if in.done {
+		in.env.Reset()
+		in.done = false
+	}
+
+	reward, done, err := in.env.Act(a)
+	if err != nil {
+		return err
+	}
+	in.lastReward, in.done = reward, done
+	if done {
+		fmt.Fprintf(in.out, "episode %v over after %v steps with return "+
+			"%v: %v\n", in.env.Episode(), in.env.EpisodeSteps(),
+			in.env.EpisodeReturn(), in.env.TerminationReason())
+	}
+	return nil
+}
+
+// auto takes a number of uniformly random actions
+func (in *inspector) auto(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: auto N")
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid number of steps %q", args[0])
+	}
+
+	for i := 0; i < n; i++ {
+		if err := in.step(in.rng.Intn(in.env.NumActions())); err != nil {
+			return err
+		}
+	}
+	return in.show(nil)
+}
+
+// show prints the state as a grid of characters, where each non-empty
+// cell shows the index of the highest channel active at that cell
+func (in *inspector) show([]string) error {
+	state, err := in.env.State()
+	if err != nil {
+		return err
+	}
+	shape := in.env.Shape()
+	channels, rows, cols := shape.Channels, shape.Rows, shape.Cols
+
+	var b strings.Builder
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			cell := "."
+			for ch := 0; ch < channels; ch++ {
+				if state[ch*rows*cols+r*cols+c] != 0 {
+					cell = strconv.FormatInt(int64(ch), 36)
+				}
+			}
+			b.WriteString(cell)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprint(in.out, b.String())
+	return in.info(nil)
+}
+
+// png writes the state to a file as a PNG image
+func (in *inspector) png(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: png FILE [SCALE]")
+	}
+	scale := 16
+	if len(args) == 2 {
+		var err error
+		if scale, err = strconv.Atoi(args[1]); err != nil || scale <= 0 {
+			return fmt.Errorf("invalid scale %q", args[1])
+		}
+	}
+
+	state, err := in.env.State()
+	if err != nil {
+		return err
+	}
+	img := render.Rasterize(state, in.env.StateShape(),
+		render.DefaultPalette, render.Options{
+			CellSize: scale,
+			ZOrder:   in.env.ZOrder(),
+		})
+
+	f, err := os.Create(args[0])
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(in.out, "wrote %v\n", args[0])
+	return nil
+}
+
+// channels lists the channels of the game
+func (in *inspector) channels([]string) error {
+	for i, ch := range in.env.Manifest().Channels {
+		fmt.Fprintf(in.out, "%2v %-14v %v\n", strconv.FormatInt(int64(i),
+			36), ch.Name, ch.Description)
+	}
+	return nil
+}
+
+// channel prints a single channel, given by name or index, as a grid
+// of its values
+func (in *inspector) channel(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: channel C")
+	}
+	i, err := in.parseChannel(args[0])
+	if err != nil {
+		return err
+	}
+
+	ch, err := in.env.Channel(i)
+	if err != nil {
+		return err
+	}
+	shape := in.env.Shape()
+	rows, cols := shape.Rows, shape.Cols
+
+	var b strings.Builder
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			switch v := ch[r*cols+c]; v {
+			case 0:
+				b.WriteString(" .")
+			case 1:
+				b.WriteString(" #")
+			default:
+				fmt.Fprintf(&b, " %.2g", v)
+			}
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprint(in.out, b.String())
+	return nil
+}
+
+// parseChannel returns the index of the channel given by name or by
+// index
+func (in *inspector) parseChannel(s string) (int, error) {
+	channels := in.env.Manifest().Channels
+	for i, ch := range channels {
+		if strings.EqualFold(ch.Name, s) {
+			return i, nil
+		}
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return -1, fmt.Errorf("no such channel %q", s)
+	}
+	if i < 0 || i >= len(channels) {
+		return -1, fmt.Errorf("channel %v ∉ [0, %v)", i, len(channels))
+	}
+	return i, nil
+}
+
+// dump writes the state to a file, or prints it, as JSON
+func (in *inspector) dump(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: dump [FILE]")
+	}
+
+	state, err := in.env.State()
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(struct {
+		Game  string    `json:"game"`
+		Shape []int     `json:"shape"`
+		State []float64 `json:"state"`
+	}{in.env.GameName(), in.env.StateShape(), state})
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		fmt.Fprintln(in.out, string(b))
+		return nil
+	}
+	if err := ioutil.WriteFile(args[0], b, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(in.out, "wrote %v\n", args[0])
+	return nil
+}
+
+// info prints the episode, step, and return, and the reward events of
+// the last step
+func (in *inspector) info([]string) error {
+	fmt.Fprintf(in.out, "episode %v  step %v  return %v  reward %v  "+
+		"ramp %v\n", in.env.Episode(), in.env.EpisodeSteps(),
+		in.env.EpisodeReturn(), in.lastReward, in.env.DifficultyRamp())
+
+	for _, e := range in.env.Info().RewardEvents {
+		fmt.Fprintf(in.out, "  %+v\n", e)
+	}
+	return nil
+}
+
+// snapshot saves the current state of the environment under a name
+func (in *inspector) snapshot(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: snapshot NAME")
+	}
+	in.snapshots[args[0]] = in.env.Snapshot()
+	fmt.Fprintf(in.out, "saved %v\n", args[0])
+	return nil
+}
+
+// restore restores a saved state of the environment
+func (in *inspector) restore(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: restore NAME")
+	}
+	s, ok := in.snapshots[args[0]]
+	if !ok {
+		return fmt.Errorf("no snapshot %q", args[0])
+	}
+	if err := in.env.Restore(s); err != nil {
+		return err
+	}
+	in.lastReward, in.done = 0, false
+	return in.show(nil)
+}
+
+// listSnapshots lists the saved states
+func (in *inspector) listSnapshots([]string) error {
+	names := make([]string, 0, len(in.snapshots))
+	for name := range in.snapshots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(in.out, name)
+	}
+	return nil
+}
+
+// seed seeds the environment and the random actions of auto, and
+// resets the environment
+func (in *inspector) seed(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: seed N")
+	}
+	seed, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid seed %q", args[0])
+	}
+
+	in.env.Seed(seed)
+	in.rng.Seed(seed)
+	return in.reset(nil)
+}
+
+// reset resets the environment
+func (in *inspector) reset([]string) error {
+	in.env.Reset()
+	in.lastReward, in.done = 0, false
+	return in.show(nil)
+}
+
+// help lists the commands
+func (in *inspector) help([]string) error {
+	fmt.Fprintln(in.out, "enter actions by name or index, e.g. "+
+		"\"left left fire\", or a command:")
+	for _, cmd := range commands {
+		fmt.Fprintf(in.out, "  %v\n", cmd.usage)
+	}
+	fmt.Fprintln(in.out, "  quit")
+
+	fmt.Fprint(in.out, "actions:")
+	for i, a := range in.env.ActionSet() {
+		fmt.Fprintf(in.out, " %v=%v", i, a)
+	}
+	fmt.Fprintln(in.out)
+	return nil
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// Commands which are not actions
+const (
+	resetCommand = "reset"
+	quitCommand  = "quit"
+)
+
+// Bindings maps keys and gamepad inputs to commands. A command is the
+// name of an action, such as "left" or "fire", or one of "reset" and
+// "quit".
+type Bindings struct {
+	// Keys maps single characters to commands
+	Keys map[string]string `json:"keys"`
+
+	// Buttons maps gamepad button numbers to commands
+	Buttons map[string]string `json:"buttons,omitempty"`
+
+	// Axes maps gamepad axis directions to commands. A direction is an
+	// axis number followed by "-" or "+", e.g. "0-" is axis 0 pushed
+	// towards its minimum.
+	Axes map[string]string `json:"axes,omitempty"`
+}
+
+// defaultBindings returns the default bindings
+func defaultBindings() Bindings {
+	return Bindings{
+		Keys: map[string]string{
+			"n": "noop",
+			"a": "left",
+			"w": "up",
+			"d": "right",
+			"s": "down",
+			"f": "fire",
+			"r": resetCommand,
+			"q": quitCommand,
+		},
+		Buttons: map[string]string{
+			"0": "fire",
+			"1": "fire",
+			"6": resetCommand,
+			"7": quitCommand,
+		},
+		Axes: map[string]string{
+			"0-": "left",
+			"0+": "right",
+			"1-": "up",
+			"1+": "down",
+		},
+	}
+}
+
+// defaultBindingsPath returns the default path of the bindings file,
+// or an empty string if there is no user configuration directory
+func defaultBindingsPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "goatar", "play.json")
+}
+
+// loadBindings returns the bindings in the file at path. Sections
+// missing from the file are given their default bindings. If the file
+// does not exist and mustExist is false, the default bindings are
+// returned.
+func loadBindings(path string, mustExist bool) (Bindings, error) {
+	b := defaultBindings()
+	if path == "" {
+		return b, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !mustExist {
+		return b, nil
+	} else if err != nil {
+		return Bindings{}, fmt.Errorf("loadBindings: %v", err)
+	}
+
+	var file Bindings
+	if err := json.Unmarshal(data, &file); err != nil {
+		return Bindings{}, fmt.Errorf("loadBindings: %v: %v", path, err)
+	}
+	if file.Keys != nil {
+		b.Keys = file.Keys
+	}
+	if file.Buttons != nil {
+		b.Buttons = file.Buttons
+	}
+	if file.Axes != nil {
+		b.Axes = file.Axes
+	}
+
+	if err := b.validate(); err != nil {
+		return Bindings{}, fmt.Errorf("loadBindings: %v: %v", path, err)
+	}
+	return b, nil
+}
+
+// save writes the bindings to the file at path, creating its directory
+// if needed
+func (b Bindings) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("save: %v", err)
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("save: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("save: %v", err)
+	}
+	return nil
+}
+
+// bind binds keys to commands given as a comma-separated list of
+// key=command pairs, e.g. "j=left,l=right"
+func (b *Bindings) bind(pairs string) error {
+	for _, pair := range strings.Split(pairs, ",") {
+		key, cmd := pair, ""
+		if i := strings.LastIndex(pair, "="); i > 0 {
+			key, cmd = pair[:i], pair[i+1:]
+		}
+		if err := validateKey(key); err != nil {
+			return fmt.Errorf("bind: %v", err)
+		}
+		if err := validateCommand(cmd); err != nil {
+			return fmt.Errorf("bind: %v", err)
+		}
+		b.Keys[key] = cmd
+	}
+	return nil
+}
+
+// validate returns an error if any binding is invalid
+func (b Bindings) validate() error {
+	for key, cmd := range b.Keys {
+		if err := validateKey(key); err != nil {
+			return err
+		}
+		if err := validateCommand(cmd); err != nil {
+			return err
+		}
+	}
+	for button, cmd := range b.Buttons {
+		if n, err := strconv.Atoi(button); err != nil || n < 0 {
+			return fmt.Errorf("invalid button %q", button)
+		}
+		if err := validateCommand(cmd); err != nil {
+			return err
+		}
+	}
+	for axis, cmd := range b.Axes {
+		if _, _, err := parseAxis(axis); err != nil {
+			return err
+		}
+		if err := validateCommand(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateKey returns an error if key is not a single printable,
+// non-space character
+func validateKey(key string) error {
+	r, size := utf8.DecodeRuneInString(key)
+	if size == 0 || size != len(key) || unicode.IsSpace(r) ||
+		!unicode.IsPrint(r) {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	return nil
+}
+
+// validateCommand returns an error if cmd is not a command
+func validateCommand(cmd string) error {
+	if cmd == resetCommand || cmd == quitCommand {
+		return nil
+	}
+	if _, err := parseAction(cmd); err != nil {
+		return fmt.Errorf("invalid command %q", cmd)
+	}
+	return nil
+}
+
+// parseAction returns the action with the given name. "noop" and
+// "no-op" are accepted as names of goatar.NoOp.
+func parseAction(name string) (goatar.Action, error) {
+	return goatar.ParseAction(strings.ReplaceAll(name, "-", ""))
+}
+
+// parseAxis returns the axis number and direction of an axis
+// direction such as "0-"
+func parseAxis(axis string) (number int, positive bool, err error) {
+	if len(axis) < 2 {
+		return 0, false, fmt.Errorf("invalid axis %q", axis)
+	}
+
+	sign := axis[len(axis)-1]
+	number, err = strconv.Atoi(axis[:len(axis)-1])
+	if err != nil || number < 0 || (sign != '-' && sign != '+') {
+		return 0, false, fmt.Errorf("invalid axis %q", axis)
+	}
+	return number, sign == '+', nil
+}
+
+// keyCommand returns the command bound to key
+func (b Bindings) keyCommand(key rune) (string, bool) {
+	cmd, ok := b.Keys[string(key)]
+	return cmd, ok
+}
+
+// buttonCommand returns the command bound to a gamepad button
+func (b Bindings) buttonCommand(button int) (string, bool) {
+	cmd, ok := b.Buttons[strconv.Itoa(button)]
+	return cmd, ok
+}
+
+// axisCommand returns the command bound to a gamepad axis direction
+func (b Bindings) axisCommand(axis int, positive bool) (string, bool) {
+	sign := "-"
+	if positive {
+		sign = "+"
+	}
+	cmd, ok := b.Axes[strconv.Itoa(axis)+sign]
+	return cmd, ok
+}
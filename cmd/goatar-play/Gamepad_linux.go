@@ -0,0 +1,86 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Event types of the Linux joystick API, see
+// https://www.kernel.org/doc/html/latest/input/joydev/joystick-api.html
+const (
+	jsEventButton = 0x01 // Button pressed or released
+	jsEventAxis   = 0x02 // Axis moved
+	jsEventInit   = 0x80 // Initial state of the device
+)
+
+// axisThreshold is the absolute value past which an axis is considered
+// pushed in a direction. Axis values range over [-32767, 32767].
+const axisThreshold = 16384
+
+// openGamepad opens the joystick device at path, such as
+// /dev/input/js0, and sends the command bound to each button press
+// and axis push to commands until the device is closed or fails
+func openGamepad(path string, b Bindings, commands chan<- string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("openGamepad: %v", err)
+	}
+
+	go func() {
+		defer f.Close()
+
+		pushed := make(map[int]int) // Direction each axis is pushed in
+		var event [8]byte
+		for {
+			if _, err := io.ReadFull(f, event[:]); err != nil {
+				return
+			}
+
+			// Each event is a uint32 timestamp, an int16 value, a uint8
+			// type, and a uint8 button or axis number
+			value := int16(binary.LittleEndian.Uint16(event[4:6]))
+			kind, number := event[6], int(event[7])
+			if kind&jsEventInit != 0 {
+				continue
+			}
+
+			switch kind {
+			case jsEventButton:
+				if value == 0 {
+					continue
+				}
+				if cmd, ok := b.buttonCommand(number); ok {
+					commands <- cmd
+				}
+
+			case jsEventAxis:
+				direction := 0
+				if value <= -axisThreshold {
+					direction = -1
+				} else if value >= axisThreshold {
+					direction = 1
+				}
+
+				// Only pushing an axis in a new direction is a command,
+				// so that holding an axis issues a single command
+				if direction == pushed[number] {
+					continue
+				}
+				pushed[number] = direction
+				if direction == 0 {
+					continue
+				}
+				if cmd, ok := b.axisCommand(number, direction > 0); ok {
+					commands <- cmd
+				}
+			}
+		}
+	}()
+
+	return nil
+}
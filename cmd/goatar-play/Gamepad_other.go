@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import "fmt"
+
+// openGamepad returns an error, since gamepads are only supported on
+// Linux
+func openGamepad(path string, b Bindings, commands chan<- string) error {
+	return fmt.Errorf("openGamepad: gamepads are only supported on Linux")
+}
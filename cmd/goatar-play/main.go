@@ -0,0 +1,322 @@
+// Command goatar-play allows a human to play GoAtar games in the
+// terminal.
+//
+// Each turn the current state is printed as a grid of characters,
+// where each non-empty cell shows the index of the highest channel
+// active at that cell. By default, actions are entered one per line:
+//
+//	a	left
+//	d	right
+//	w	up
+//	s	down
+//	f	fire
+//	n	no-op (an empty line is also a no-op)
+//	r	reset the episode
+//	q	quit
+//
+// Several actions can be entered on the same line, e.g. "aaf", in
+// which case they are taken in order.
+//
+// Keys can be rebound with the --bind flag, which takes a list of
+// key=command pairs, where a command is the name of an action, "reset",
+// or "quit":
+//
+//	goatar-play --bind j=left,l=right,i=up,k=down,h=fire
+//
+// Bindings are loaded from the file given by --bindings, which
+// defaults to goatar/play.json in the user's configuration directory.
+// With --save-bindings, the bindings in effect, including any given by
+// --bind, are saved to that file so that they persist across sessions.
+// The file is a JSON object with "keys", "buttons", and "axes" fields
+// which map keys, gamepad button numbers, and gamepad axis directions
+// such as "0-" or "1+" to commands.
+//
+// On Linux, a gamepad can be used alongside the keyboard by passing
+// its joystick device to the --gamepad flag, e.g. /dev/input/js0. Each
+// button press or push of an axis issues the command bound to it.
+//
+// With the --record flag, each transition is saved in the GoAtar trace
+// format so that play sessions can be used as human demonstrations:
+//
+//	goatar-play --game breakout --record out.jsonl
+//
+// With --bitpack, states in the recorded trace are bit-packed rather
+// than written as arrays of floats, which makes the trace much smaller.
+// Files ending in .parquet are written as Apache Parquet files instead,
+// which can be loaded directly with pandas or polars.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/trace"
+)
+
+// settings are the settings of a play session
+type settings struct {
+	game         string
+	seed         int64
+	sticky       float64
+	ramping      bool
+	record       string
+	bitpack      bool
+	bindingsPath string
+	bind         string
+	saveBindings bool
+	gamepad      string
+
+	// explicitBindings is whether the bindings file was given
+	// explicitly, in which case it must exist unless it is about to be
+	// created
+	explicitBindings bool
+}
+
+func main() {
+	var s settings
+	flag.StringVar(&s.game, "game", "breakout", "game to play")
+	flag.Int64Var(&s.seed, "seed", time.Now().UnixNano(), "random seed")
+	flag.Float64Var(&s.sticky, "sticky", 0.0, "sticky action probability")
+	flag.BoolVar(&s.ramping, "ramping", true, "enable difficulty ramping")
+	flag.StringVar(&s.record, "record", "", "file to record the session "+
+		"to in the trace format")
+	flag.BoolVar(&s.bitpack, "bitpack", false, "bit-pack states in the "+
+		"recorded trace")
+	flag.StringVar(&s.bindingsPath, "bindings", defaultBindingsPath(),
+		"file to load key and gamepad bindings from")
+	flag.StringVar(&s.bind, "bind", "", "comma-separated key=command "+
+		"bindings, e.g. j=left,l=right")
+	flag.BoolVar(&s.saveBindings, "save-bindings", false, "save the "+
+		"bindings in effect to the bindings file")
+	flag.StringVar(&s.gamepad, "gamepad", "", "joystick device of a "+
+		"gamepad, e.g. /dev/input/js0")
+	flag.Parse()
+	flag.Visit(func(f *flag.Flag) {
+		s.explicitBindings = s.explicitBindings || f.Name == "bindings"
+	})
+
+	if err := run(s); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run plays a session with settings s. The recorded trace, if any, is
+// flushed and closed before run returns, even if the session fails.
+func run(s settings) (err error) {
+	bindings, err := loadBindings(s.bindingsPath,
+		s.explicitBindings && !s.saveBindings)
+	if err != nil {
+		return err
+	}
+	if s.bind != "" {
+		if err := bindings.bind(s.bind); err != nil {
+			return err
+		}
+	}
+	if s.saveBindings {
+		if s.bindingsPath == "" {
+			return fmt.Errorf("no bindings file to save to")
+		}
+		if err := bindings.save(s.bindingsPath); err != nil {
+			return err
+		}
+	}
+
+	var pad chan string
+	if s.gamepad != "" {
+		pad = make(chan string)
+		if err := openGamepad(s.gamepad, bindings, pad); err != nil {
+			return err
+		}
+	}
+
+	name, err := goatar.ParseGameName(s.game)
+	if err != nil {
+		return err
+	}
+
+	environment, err := goatar.New(name, s.sticky, s.ramping, s.seed)
+	if err != nil {
+		return err
+	}
+
+	var env goatar.Env = environment
+	if s.record != "" {
+		f, err := os.Create(s.record)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+
+		header := trace.Header{
+			Game:              env.GameName(),
+			Shape:             env.StateShape(),
+			Seed:              s.seed,
+			StickyActionsProb: s.sticky,
+			DifficultyRamping: s.ramping,
+		}
+		if s.bitpack {
+			header.Encoding = trace.Bitpack
+		}
+		var w trace.TransitionWriter
+		if filepath.Ext(s.record) == ".parquet" {
+			w, err = trace.NewParquetWriter(f, header)
+		} else {
+			w, err = trace.NewWriter(f, header)
+		}
+		if err != nil {
+			return err
+		}
+
+		recorder := trace.NewRecorder(env, w)
+		defer func() {
+			if ferr := recorder.Flush(); err == nil {
+				err = ferr
+			}
+			if c, ok := w.(io.Closer); ok {
+				if cerr := c.Close(); err == nil {
+					err = cerr
+				}
+			}
+		}()
+		env = recorder
+	}
+
+	return play(env, bindings, os.Stdin, pad, os.Stdout)
+}
+
+// play runs an interactive session of env, reading keys from in and
+// commands from pad, and writing the rendered states to out. If pad is
+// nil, only keys are read.
+func play(env goatar.Env, bindings Bindings, in io.Reader,
+	pad <-chan string, out io.Writer) error {
+	lines := make(chan string)
+	scanErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		scanErr <- scanner.Err()
+		close(lines)
+	}()
+
+	s := session{env: env}
+	for {
+		if err := render(env, out); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "return: %v", s.episodeReturn)
+		if s.done {
+			fmt.Fprint(out, " (episode over, press r to reset)")
+		}
+		fmt.Fprint(out, "\n> ")
+
+		var commands []string
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return <-scanErr
+			}
+			line = strings.TrimSpace(line)
+			if line == "" {
+				commands = append(commands, "noop")
+			}
+			for _, key := range line {
+				cmd, ok := bindings.keyCommand(key)
+				if !ok {
+					fmt.Fprintf(out, "unknown key %q\n", key)
+					continue
+				}
+				commands = append(commands, cmd)
+			}
+
+		case cmd := <-pad:
+			fmt.Fprintln(out)
+			commands = append(commands, cmd)
+		}
+
+		for _, cmd := range commands {
+			if quit, err := s.run(cmd); err != nil || quit {
+				return err
+			}
+		}
+	}
+}
+
+// session is the state of an interactive session
+type session struct {
+	env           goatar.Env
+	episodeReturn float64
+	done          bool
+}
+
+// run runs a single command and returns whether the session should end
+func (s *session) run(cmd string) (bool, error) {
+	switch cmd {
+	case quitCommand:
+		return true, nil
+
+	case resetCommand:
+		s.env.Reset()
+		s.episodeReturn = 0.0
+		s.done = false
+
+	default:
+		action, err := parseAction(cmd)
+		if err != nil {
+			return false, err
+		}
+		if s.done {
+			return false, nil
+		}
+
+		reward, terminal, err := s.env.Act(int(action))
+		if err != nil {
+			return false, err
+		}
+		s.episodeReturn += reward
+		s.done = terminal
+	}
+	return false, nil
+}
+
+// render writes the current state of env to out as a grid of
+// characters
+func render(env goatar.Env, out io.Writer) error {
+	state, err := env.State()
+	if err != nil {
+		return fmt.Errorf("render: %v", err)
+	}
+	shape := env.Shape()
+	channels, rows, cols := shape.Channels, shape.Rows, shape.Cols
+
+	var b strings.Builder
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			cell := "."
+			for ch := 0; ch < channels; ch++ {
+				if state[ch*rows*cols+r*cols+c] != 0 {
+					cell = fmt.Sprintf("%x", ch)
+				}
+			}
+			b.WriteString(cell)
+		}
+		b.WriteString("\n")
+	}
+
+	_, err = fmt.Fprint(out, b.String())
+	return err
+}
@@ -0,0 +1,386 @@
+// Command goatar-verify certifies that GoAtar games are deterministic.
+// It runs the same seed and action script several times and compares
+// every observation, reward, and termination signal of the runs:
+//
+//	goatar-verify --game breakout --seed 3 --steps 10000 --runs 3
+//
+// By default each game is verified. The action script is read from the
+// file given by --script, which holds action indices separated by
+// whitespace or commas, and is otherwise drawn uniformly at random
+// using the seed. Episodes which end are reset, and the script
+// continues in the next episode.
+//
+// With --processes, each run after the first is executed in a separate
+// process, which also catches nondeterminism across processes, such as
+// that caused by map iteration order or the addresses of objects.
+//
+// If two runs diverge, the first divergent step is printed together
+// with what differed on that step and the package of the game which
+// produced it, and the command exits with a non-zero status.
+package main
+
+import (
+	"encoding/gob"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// step is the outcome of a single step of a run
+type step struct {
+	Action int
+	Reward float64
+	Done   bool
+	State  []float64 // Observation after the step
+
+	// ResetState is the observation after the environment was reset,
+	// if the episode ended on the step
+	ResetState []float64
+}
+
+// settings are the settings of a run, which are passed on to the
+// processes executing runs
+type settings struct {
+	game    goatar.GameName
+	seed    int64
+	sticky  float64
+	ramping bool
+	steps   int
+	script  []int
+}
+
+func main() {
+	gameName := flag.String("game", "", "game to verify, or all games "+
+		"if empty")
+	seed := flag.Int64("seed", 0, "seed of the environment and of the "+
+		"random action script")
+	sticky := flag.Float64("sticky", 0.1, "sticky action probability")
+	ramping := flag.Bool("ramping", true, "enable difficulty ramping")
+	steps := flag.Int("steps", 10000, "number of steps per run")
+	runs := flag.Int("runs", 2, "number of runs to compare")
+	scriptPath := flag.String("script", "", "file of actions to take, "+
+		"random actions are taken if empty")
+	processes := flag.Bool("processes", false, "execute each run after "+
+		"the first in a separate process")
+	child := flag.Bool("child", false, "execute a single run and write "+
+		"it to stdout, used by --processes")
+	flag.Parse()
+
+	if *runs < 2 {
+		log.Fatalf("runs must be at least 2 but got %v", *runs)
+	}
+	if *steps <= 0 {
+		log.Fatalf("steps must be positive but got %v", *steps)
+	}
+
+	var script []int
+	if *scriptPath != "" {
+		var err error
+		script, err = readScript(*scriptPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	games := goatar.Games()
+	if *gameName != "" {
+		g, err := goatar.ParseGameName(*gameName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		games = []goatar.GameName{g}
+	}
+
+	if *child {
+		if len(games) != 1 {
+			log.Fatal("child runs must be given a single game")
+		}
+		s := settings{games[0], *seed, *sticky, *ramping, *steps, script}
+		trajectory, err := run(s)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := gob.NewEncoder(os.Stdout).Encode(trajectory); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	failed := false
+	for _, g := range games {
+		s := settings{g, *seed, *sticky, *ramping, *steps, script}
+		ok, err := verify(s, *runs, *processes)
+		if err != nil {
+			log.Fatal(err)
+		}
+		failed = failed || !ok
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// verify executes runs runs with the settings s and compares each to
+// the first, returning whether they are identical. Differences are
+// printed to stdout.
+func verify(s settings, runs int, processes bool) (bool, error) {
+	env, err := newEnv(s)
+	if err != nil {
+		return false, fmt.Errorf("verify: %v", err)
+	}
+	l := newLayout(env)
+
+	reference, err := run(s)
+	if err != nil {
+		return false, fmt.Errorf("verify: %v", err)
+	}
+
+	for i := 1; i < runs; i++ {
+		var trajectory []step
+		if processes {
+			trajectory, err = runProcess(s)
+		} else {
+			trajectory, err = run(s)
+		}
+		if err != nil {
+			return false, fmt.Errorf("verify: %v", err)
+		}
+
+		if t, diff := l.compare(reference, trajectory); diff != "" {
+			fmt.Printf("%v: run %v diverged from run 0 at step %v: %v\n",
+				s.game, i, t, diff)
+			// Actions only differ if the action script differs, which
+			// is not the fault of the game
+			if !strings.HasPrefix(diff, "action") {
+				fmt.Printf("%v: responsible module: %v\n", s.game,
+					module(env))
+			}
+			return false, nil
+		}
+	}
+
+	fmt.Printf("%v: %v runs of %v steps are identical\n", s.game, runs,
+		s.steps)
+	return true, nil
+}
+
+// newEnv returns a new environment with the settings s
+func newEnv(s settings) (*goatar.Environment, error) {
+	return goatar.New(s.game, s.sticky, s.ramping, s.seed)
+}
+
+// run executes a single run with the settings s
+func run(s settings) ([]step, error) {
+	env, err := newEnv(s)
+	if err != nil {
+		return nil, fmt.Errorf("run: %v", err)
+	}
+	rng := rand.New(rand.NewSource(s.seed))
+
+	trajectory := make([]step, s.steps)
+	for t := range trajectory {
+		var a int
+		if s.script != nil {
+			a = s.script[t%len(s.script)]
+		} else {
+			a = rng.Intn(env.NumActions())
+		}
+
+		reward, done, err := env.Act(a)
+		if err != nil {
+			return nil, fmt.Errorf("run: step %v: %v", t, err)
+		}
+		state, err := env.State()
+		if err != nil {
+			return nil, fmt.Errorf("run: step %v: %v", t, err)
+		}
+		trajectory[t] = step{Action: a, Reward: reward, Done: done,
+			State: state}
+
+		if done {
+			env.Reset()
+			state, err := env.State()
+			if err != nil {
+				return nil, fmt.Errorf("run: step %v: %v", t, err)
+			}
+			trajectory[t].ResetState = state
+		}
+	}
+	return trajectory, nil
+}
+
+// runProcess executes a single run with the settings s in a new process
+func runProcess(s settings) ([]step, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("runProcess: %v", err)
+	}
+
+	args := []string{
+		"--child",
+		"--game", s.game.String(),
+		"--seed", strconv.FormatInt(s.seed, 10),
+		"--sticky", strconv.FormatFloat(s.sticky, 'g', -1, 64),
+		"--ramping=" + strconv.FormatBool(s.ramping),
+		"--steps", strconv.Itoa(s.steps),
+	}
+	if s.script != nil {
+		f, err := ioutil.TempFile("", "goatar-verify-*.txt")
+		if err != nil {
+			return nil, fmt.Errorf("runProcess: %v", err)
+		}
+		defer os.Remove(f.Name())
+		if err := writeScript(f, s.script); err != nil {
+			return nil, fmt.Errorf("runProcess: %v", err)
+		}
+		args = append(args, "--script", f.Name())
+	}
+
+	cmd := exec.Command(executable, args...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("runProcess: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("runProcess: %v", err)
+	}
+
+	var trajectory []step
+	decodeErr := gob.NewDecoder(out).Decode(&trajectory)
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("runProcess: %v", err)
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("runProcess: %v", decodeErr)
+	}
+	return trajectory, nil
+}
+
+// layout describes the layout of the observations of a game, so that
+// differences between observations can be located
+type layout struct {
+	shape    goatar.Shape
+	channels []string // Name of each channel
+}
+
+// newLayout returns the layout of the observations of env
+func newLayout(env *goatar.Environment) layout {
+	l := layout{shape: env.Shape()}
+	for _, ch := range env.Manifest().Channels {
+		l.channels = append(l.channels, ch.Name)
+	}
+	return l
+}
+
+// compare returns the first step on which the runs a and b differ, and
+// a description of the difference. The description is empty if the
+// runs are identical.
+func (l layout) compare(a, b []step) (int, string) {
+	for t := range a {
+		if t >= len(b) {
+			return t, "run ended early"
+		}
+		sa, sb := a[t], b[t]
+
+		switch {
+		case sa.Action != sb.Action:
+			return t, fmt.Sprintf("action %v ≠ %v", sa.Action, sb.Action)
+		case sa.Reward != sb.Reward:
+			return t, fmt.Sprintf("reward %v ≠ %v", sa.Reward, sb.Reward)
+		case sa.Done != sb.Done:
+			return t, fmt.Sprintf("done %v ≠ %v", sa.Done, sb.Done)
+		}
+		if diff := l.compareStates(sa.State, sb.State); diff != "" {
+			return t, "observation " + diff
+		}
+		if diff := l.compareStates(sa.ResetState, sb.ResetState); diff != "" {
+			return t, "observation after reset " + diff
+		}
+	}
+	return -1, ""
+}
+
+// compareStates returns a description of the first element at which
+// the observations a and b differ, or an empty string if they are
+// identical
+func (l layout) compareStates(a, b []float64) string {
+	if len(a) != len(b) {
+		return fmt.Sprintf("size %v ≠ %v", len(a), len(b))
+	}
+	for i := range a {
+		if a[i] == b[i] {
+			continue
+		}
+
+		cells := l.shape.Rows * l.shape.Cols
+		ch, row, col := i/cells, i%cells/l.shape.Cols, i%l.shape.Cols
+		name := strconv.Itoa(ch)
+		if ch < len(l.channels) {
+			name = fmt.Sprintf("%v (%v)", ch, l.channels[ch])
+		}
+		return fmt.Sprintf("channel %v at row %v, column %v: %v ≠ %v", name,
+			row, col, a[i], b[i])
+	}
+	return ""
+}
+
+// module returns the package path of the game of env, which is
+// responsible for any divergence not caused by the environment's
+// sticky actions
+func module(env *goatar.Environment) string {
+	t := reflect.TypeOf(env.Game)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.PkgPath()
+}
+
+// readScript reads an action script from the file at path
+func readScript(path string) ([]int, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("readScript: %v", err)
+	}
+
+	fields := strings.FieldsFunc(string(data), func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("readScript: %v holds no actions", path)
+	}
+
+	script := make([]int, len(fields))
+	for i, field := range fields {
+		a, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("readScript: %v", err)
+		}
+		script[i] = a
+	}
+	return script, nil
+}
+
+// writeScript writes an action script to w in the format read by
+// readScript
+func writeScript(w io.WriteCloser, script []int) error {
+	fields := make([]string, len(script))
+	for i, a := range script {
+		fields[i] = strconv.Itoa(a)
+	}
+	if _, err := io.WriteString(w, strings.Join(fields, "\n")); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
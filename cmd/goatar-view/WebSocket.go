@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// webSocketGUID is the GUID used to compute the Sec-WebSocket-Accept
+// header, as defined in RFC 6455
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Opcodes of WebSocket frames
+const (
+	opText  byte = 0x1
+	opClose byte = 0x8
+)
+
+// webSocket is the server side of a WebSocket connection. Only the
+// parts of RFC 6455 needed to push text messages to a browser are
+// implemented: messages sent by the client are read and discarded.
+type webSocket struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+// upgrade performs the WebSocket opening handshake on an HTTP request
+// and returns the resulting connection
+func upgrade(w http.ResponseWriter, r *http.Request) (*webSocket, error) {
+	if !headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "expected a WebSocket request", http.StatusBadRequest)
+		return nil, fmt.Errorf("upgrade: not a WebSocket request")
+	}
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, fmt.Errorf("upgrade: missing Sec-WebSocket-Key")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "cannot upgrade connection",
+			http.StatusInternalServerError)
+		return nil, fmt.Errorf("upgrade: connection cannot be hijacked")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("upgrade: %v", err)
+	}
+
+	sum := sha1.Sum([]byte(key + webSocketGUID))
+	accept := base64.StdEncoding.EncodeToString(sum[:])
+	fmt.Fprint(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: "+accept+"\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("upgrade: %v", err)
+	}
+
+	return &webSocket{conn: conn, rw: rw}, nil
+}
+
+// WriteText sends msg to the client as a single text frame
+func (ws *webSocket) WriteText(msg []byte) error {
+	return ws.writeFrame(opText, msg)
+}
+
+// Close sends a close frame to the client and closes the connection
+func (ws *webSocket) Close() error {
+	ws.writeFrame(opClose, nil)
+	return ws.conn.Close()
+}
+
+// writeFrame writes a single unmasked frame with the given opcode
+func (ws *webSocket) writeFrame(opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode} // FIN bit set
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xffff:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+
+	if _, err := ws.rw.Write(header); err != nil {
+		return fmt.Errorf("writeFrame: %v", err)
+	}
+	if _, err := ws.rw.Write(payload); err != nil {
+		return fmt.Errorf("writeFrame: %v", err)
+	}
+	if err := ws.rw.Flush(); err != nil {
+		return fmt.Errorf("writeFrame: %v", err)
+	}
+	return nil
+}
+
+// discardReads reads and discards frames sent by the client until the
+// client closes the connection or an error occurs
+func (ws *webSocket) discardReads() error {
+	header := make([]byte, 2)
+	for {
+		if _, err := io.ReadFull(ws.rw, header); err != nil {
+			return err
+		}
+		opcode := header[0] & 0x0f
+		masked := header[1]&0x80 != 0
+
+		length := uint64(header[1] & 0x7f)
+		switch length {
+		case 126:
+			ext := make([]byte, 2)
+			if _, err := io.ReadFull(ws.rw, ext); err != nil {
+				return err
+			}
+			length = uint64(binary.BigEndian.Uint16(ext))
+		case 127:
+			ext := make([]byte, 8)
+			if _, err := io.ReadFull(ws.rw, ext); err != nil {
+				return err
+			}
+			length = binary.BigEndian.Uint64(ext)
+		}
+		if masked {
+			length += 4
+		}
+
+		if _, err := io.CopyN(io.Discard, ws.rw, int64(length)); err != nil {
+			return err
+		}
+		if opcode == opClose {
+			return io.EOF
+		}
+	}
+}
+
+// headerContains returns whether the comma-separated header key
+// contains the token value, ignoring case
+func headerContains(h http.Header, key, value string) bool {
+	for _, v := range h.Values(key) {
+		for _, token := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,302 @@
+// Command goatar-view serves a web page which renders a GoAtar game
+// live in the browser over WebSockets, so that environments running on
+// remote machines can be watched without installing anything.
+//
+// By default, goatar-view runs an environment with a uniform random
+// policy:
+//
+//	goatar-view --game breakout --addr :8080
+//
+// Alternatively, goatar-view replays a file in the GoAtar trace format.
+// If the file is "-", the trace is read from standard input, which
+// allows the frames of a running training job to be streamed to the
+// viewer:
+//
+//	train | goatar-view --trace -
+//
+// In both cases, open the served address in a browser to watch.
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/trace"
+)
+
+//go:embed index.html
+var index []byte
+
+// frame is a single frame sent to the browser. Cells holds, for each
+// cell of the grid in row-major order, the index of the highest
+// channel active at that cell, or -1 if the cell is empty.
+type frame struct {
+	Game     string  `json:"game"`
+	Rows     int     `json:"rows"`
+	Cols     int     `json:"cols"`
+	Channels int     `json:"channels"`
+	Cells    []int   `json:"cells"`
+	Episode  int     `json:"episode"`
+	Step     int     `json:"step"`
+	Reward   float64 `json:"reward"`
+	Return   float64 `json:"return"`
+	Terminal bool    `json:"terminal"`
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to serve on")
+	gameName := flag.String("game", "breakout", "game to run")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "random seed")
+	sticky := flag.Float64("sticky", 0.1, "sticky action probability")
+	ramping := flag.Bool("ramping", true, "enable difficulty ramping")
+	tracePath := flag.String("trace", "", "trace file to replay instead "+
+		"of running an environment, - for standard input")
+	fps := flag.Float64("fps", 10, "frames per second")
+	flag.Parse()
+
+	if *fps <= 0 {
+		log.Fatalf("fps must be positive but got %v", *fps)
+	}
+	period := time.Duration(float64(time.Second) / *fps)
+
+	h := newHub()
+	if *tracePath != "" {
+		var r io.Reader = os.Stdin
+		if *tracePath != "-" {
+			f, err := os.Open(*tracePath)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer f.Close()
+			r = f
+		}
+
+		// Frames read from standard input are sent as soon as they
+		// arrive, since the producer sets the pace
+		if *tracePath == "-" {
+			period = 0
+		}
+
+		go func() {
+			if err := replay(r, h, period); err != nil {
+				log.Print(err)
+			}
+		}()
+	} else {
+		name, err := goatar.ParseGameName(*gameName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		env, err := goatar.New(name, *sticky, *ramping, *seed)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		go func() {
+			if err := run(env, *seed, h, period); err != nil {
+				log.Print(err)
+			}
+		}()
+	}
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(index)
+	})
+	http.HandleFunc("/ws", h.serve)
+
+	log.Printf("serving on http://%v", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+// run runs env with a uniform random policy forever, publishing a frame
+// to h after each step
+func run(env goatar.Env, seed int64, h *hub, period time.Duration) error {
+	rng := rand.New(rand.NewSource(seed))
+	shape := env.Shape()
+	f := frame{
+		Game:     env.GameName(),
+		Channels: shape.Channels,
+		Rows:     shape.Rows,
+		Cols:     shape.Cols,
+	}
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		state, err := env.State()
+		if err != nil {
+			return err
+		}
+		f.Cells = cells(state, shape)
+		h.publish(f)
+		<-ticker.C
+
+		if f.Terminal {
+			env.Reset()
+			f.Episode++
+			f.Step = 0
+			f.Reward = 0
+			f.Return = 0
+			f.Terminal = false
+			continue
+		}
+
+		reward, done, err := env.Act(rng.Intn(env.NumActions()))
+		if err != nil {
+			return err
+		}
+		f.Step++
+		f.Reward = reward
+		f.Return += reward
+		f.Terminal = done
+	}
+}
+
+// replay reads the trace in r and publishes each transition to h,
+// waiting period between frames
+func replay(r io.Reader, h *hub, period time.Duration) error {
+	reader, err := trace.NewReader(r)
+	if err != nil {
+		return err
+	}
+	header := reader.Header()
+	shape := goatar.Shape{
+		Channels: header.Shape[0],
+		Rows:     header.Shape[1],
+		Cols:     header.Shape[2],
+	}
+	f := frame{
+		Game:     header.Game,
+		Channels: shape.Channels,
+		Rows:     shape.Rows,
+		Cols:     shape.Cols,
+	}
+
+	episodeReturn := 0.0
+	for {
+		t, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if t.Episode != f.Episode {
+			episodeReturn = 0.0
+		}
+		episodeReturn += t.Reward
+
+		f.Cells = cells(t.State, shape)
+		f.Episode = t.Episode
+		f.Step = t.Step
+		f.Reward = t.Reward
+		f.Return = episodeReturn
+		f.Terminal = t.Terminal
+		h.publish(f)
+
+		if period > 0 {
+			time.Sleep(period)
+		}
+	}
+}
+
+// cells returns, for each cell of a state observation with the given
+// shape, the index of the highest active channel, or -1 if no channel
+// is active
+func cells(state []float64, shape goatar.Shape) []int {
+	channels, rows, cols := shape.Channels, shape.Rows, shape.Cols
+	c := make([]int, rows*cols)
+	for i := range c {
+		c[i] = -1
+		for ch := 0; ch < channels; ch++ {
+			if state[ch*rows*cols+i] != 0 {
+				c[i] = ch
+			}
+		}
+	}
+	return c
+}
+
+// hub broadcasts frames to all connected browsers. Slow browsers skip
+// frames rather than slowing down the environment.
+type hub struct {
+	mu      sync.Mutex
+	clients map[chan []byte]bool
+	last    []byte
+}
+
+// newHub returns a new hub with no clients
+func newHub() *hub {
+	return &hub{clients: make(map[chan []byte]bool)}
+}
+
+// publish sends f to all connected clients
+func (h *hub) publish(f frame) {
+	msg, err := json.Marshal(f)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last = msg
+	for ch := range h.clients {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
+
+// serve upgrades r to a WebSocket connection and sends frames over it
+// until the client disconnects
+func (h *hub) serve(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgrade(w, r)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	defer ws.Close()
+
+	ch := make(chan []byte, 1)
+	h.mu.Lock()
+	if h.last != nil {
+		ch <- h.last
+	}
+	h.clients[ch] = true
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, ch)
+		h.mu.Unlock()
+	}()
+
+	closed := make(chan struct{})
+	go func() {
+		ws.discardReads()
+		close(closed)
+	}()
+
+	for {
+		select {
+		case msg := <-ch:
+			if err := ws.WriteText(msg); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
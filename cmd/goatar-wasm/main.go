@@ -0,0 +1,24 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Command goatar-wasm is a WebAssembly program which exposes GoAtar to
+// JavaScript through package wasm. Build it with:
+//
+//	GOOS=js GOARCH=wasm go build -o goatar.wasm ./cmd/goatar-wasm
+//	cp "$(go env GOROOT)/misc/wasm/wasm_exec.js" .
+//
+// then serve goatar.wasm, wasm_exec.js, and index.html from the same
+// directory with any static file server and open index.html in a
+// browser to play Breakout client-side.
+package main
+
+import (
+	"github.com/samuelfneumann/goatar/wasm"
+)
+
+func main() {
+	wasm.Register()
+
+	// Block forever so that the registered functions remain callable
+	select {}
+}
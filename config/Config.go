@@ -0,0 +1,584 @@
+// Package config implements experiment configuration files, which
+// describe how to construct a GoAtar environment: the game, seeds,
+// sticky actions probability, difficulty ramping, game-specific
+// options, and the wrappers applied to the environment. Configuration
+// files make experiment definitions reproducible, shareable, and
+// machine-readable.
+//
+// Configurations can be written in JSON or in YAML. For example:
+//
+//	game: spaceinvaders
+//	seed: 42
+//	sticky_actions_prob: 0.1
+//	difficulty_ramping: true
+//	space_invaders:
+//	  alien_rows: 3
+//	  respawn: lower
+//	wrappers:
+//	  - type: delay_reward
+//	    delay: 5
+//
+// Only the subset of YAML needed for configuration files is supported:
+// block mappings and sequences, flow sequences of scalars, quoted and
+// plain scalars, and comments. Anchors, aliases, tags, and multi-line
+// scalars are not supported.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/wrappers"
+)
+
+// EnvConfig describes how to construct an environment
+type EnvConfig struct {
+	// Game is the name of the game, matched as in
+	// goatar.ParseGameName
+	Game string `json:"game"`
+
+	// Seed seeds the environment. GameSeed and StickySeed override the
+	// seed of the game and of sticky actions respectively.
+	Seed       int64  `json:"seed"`
+	GameSeed   *int64 `json:"game_seed,omitempty"`
+	StickySeed *int64 `json:"sticky_seed,omitempty"`
+
+	StickyActionsProb float64 `json:"sticky_actions_prob"`
+	DifficultyRamping bool    `json:"difficulty_ramping"`
+
+	// StickyActionProbs overrides the sticky action probability of the
+	// actions it names, such as "Fire", see
+	// goatar.WithStickyActionProbs
+	StickyActionProbs map[string]float64 `json:"sticky_action_probs,omitempty"`
+
+	// Version pins the version of the game's behaviour, see
+	// goatar.WithVersion. If 0, the latest version is used.
+	Version int `json:"version,omitempty"`
+
+	// RampChannel appends a channel encoding the difficulty ramp level
+	// to state observations, see goatar.WithRampChannel
+	RampChannel bool `json:"ramp_channel,omitempty"`
+
+	// VisitationChannel appends a channel marking the cells visited by
+	// the player in the current episode, see
+	// goatar.WithVisitationChannel
+	VisitationChannel bool `json:"visitation_channel,omitempty"`
+
+	// NoReward zeroes the rewards returned by the environment, see
+	// goatar.WithNoReward
+	NoReward bool `json:"no_reward,omitempty"`
+
+	// Trails lengthens every trail channel of the game to trails of
+	// this many steps, and TrailLengths lengthens the named trail
+	// channels to the given number of steps, see goatar.WithTrails
+	Trails       int            `json:"trails,omitempty"`
+	TrailLengths map[string]int `json:"trail_lengths,omitempty"`
+
+	// RandomStarts is the maximum number of random actions taken at
+	// the start of each episode, see goatar.WithRandomStarts
+	RandomStarts int `json:"random_starts,omitempty"`
+
+	// EvalSeeds are the seeds of successive episodes, which are cycled
+	// through on each reset, see goatar.WithEvalSeeds
+	EvalSeeds []int64 `json:"eval_seeds,omitempty"`
+
+	// StrictTermination makes acting after the episode ended an error,
+	// see goatar.WithStrictTermination
+	StrictTermination bool `json:"strict_termination,omitempty"`
+
+	// Stochasticity is the stochasticity level of the game, see
+	// goatar.WithStochasticity. If nil, the game is fully random.
+	Stochasticity *float64 `json:"stochasticity,omitempty"`
+
+	// RewardWeights scales the reward of each reward event by the
+	// weight of its entity, e.g. {"enemy_sub": 2}, see
+	// goatar.RewardWeights
+	RewardWeights map[string]float64 `json:"reward_weights,omitempty"`
+
+	// ActionSet is a custom action set, given by action names such as
+	// "Left". If empty, all actions are used.
+	ActionSet []string `json:"action_set,omitempty"`
+
+	// Game-specific options, which may only be given for their game
+	Asterix       *AsterixConfig       `json:"asterix,omitempty"`
+	Breakout      *BreakoutConfig      `json:"breakout,omitempty"`
+	Freeway       *FreewayConfig       `json:"freeway,omitempty"`
+	SeaQuest      *SeaQuestConfig      `json:"seaquest,omitempty"`
+	SpaceInvaders *SpaceInvadersConfig `json:"space_invaders,omitempty"`
+
+	// SpawnSchedule spawns a fixed list of entities in Asterix or
+	// SeaQuest instead of random entities, see goatar.SpawnSchedule
+	SpawnSchedule []SpawnConfig `json:"spawn_schedule,omitempty"`
+
+	// Transforms are applied to state observations in order, see
+	// goatar.WithTransforms
+	Transforms []TransformConfig `json:"transforms,omitempty"`
+
+	// Wrappers are applied to the environment in order, so that the
+	// last wrapper is the outermost
+	Wrappers []WrapperConfig `json:"wrappers,omitempty"`
+}
+
+// AsterixConfig holds the options specific to Asterix
+type AsterixConfig struct {
+	TreasureRamping bool `json:"treasure_ramping"`
+}
+
+// BreakoutConfig holds the options specific to Breakout
+type BreakoutConfig struct {
+	BounceReward bool `json:"bounce_reward"`
+	Juggling     bool `json:"juggling"`
+}
+
+// FreewayConfig holds the options specific to Freeway, which configure
+// the traffic
+type FreewayConfig struct {
+	Lanes int `json:"lanes"`
+
+	// CarRespawn is one of "wrap" (the default) or "new_speed"
+	CarRespawn string `json:"car_respawn"`
+
+	SecondChicken bool `json:"second_chicken"`
+}
+
+// SeaQuestConfig holds the options specific to SeaQuest
+type SeaQuestConfig struct {
+	ScalarGauges    bool    `json:"scalar_gauges"`
+	RandomDiverSide bool    `json:"random_diver_side"`
+	SweptCollisions bool    `json:"swept_collisions"`
+	DriftingFish    float64 `json:"drifting_fish"`
+	DriftInterval   int     `json:"drift_interval"`
+
+	SparseSurfacingReward bool `json:"sparse_surfacing_reward"`
+
+	// OxygenWarning adds an oxygen warning channel, which is active
+	// below the fraction OxygenWarningLevel of the maximum oxygen, see
+	// goatar.WithOxygenWarning
+	OxygenWarning      bool    `json:"oxygen_warning"`
+	OxygenWarningLevel float64 `json:"oxygen_warning_level"`
+
+	// ShootableDivers makes friendly bullets hit divers for a reward of
+	// -1, see goatar.WithShootableDivers
+	ShootableDivers bool `json:"shootable_divers"`
+
+	// BulletTrails adds a trail channel behind enemy bullets, see
+	// goatar.WithBulletTrails
+	BulletTrails bool `json:"bullet_trails"`
+}
+
+// SpaceInvadersConfig holds the options specific to SpaceInvaders,
+// which configure the grid of aliens and the difficulty ramp
+type SpaceInvadersConfig struct {
+	AlienRows int `json:"alien_rows"`
+	AlienCols int `json:"alien_cols"`
+
+	// Respawn is one of "top" (the default), "lower", or "none"
+	Respawn string `json:"respawn"`
+
+	// UFO adds a bonus UFO which crosses the top row of the screen
+	UFO bool `json:"ufo"`
+
+	// Targeting is one of "nearest" (the default), "random", or
+	// "leading"
+	Targeting string `json:"targeting"`
+
+	// BulletTrails adds a trail channel behind alien bullets, see
+	// goatar.WithBulletTrails
+	BulletTrails bool `json:"bullet_trails"`
+
+	// MinMoveInterval is the alien move interval at which difficulty
+	// ramping stops, which is 6 in MinAtar
+	MinMoveInterval int `json:"min_move_interval"`
+}
+
+// SpawnConfig describes an entity spawned by a spawn schedule, see
+// goatar.Spawn
+type SpawnConfig struct {
+	Step  int    `json:"step"`
+	Kind  string `json:"kind"`
+	Row   int    `json:"row"`
+	Right bool   `json:"right"`
+}
+
+// WrapperConfig describes a wrapper applied to an environment. Type
+// selects the wrapper, and the remaining fields are its arguments:
+//
+//	delay_reward       Delay
+//	noisy_reward       Sigma, Seed
+//	crop_observation   Channels
+//	horizontal_flip
+//	frame_skip         Skip, MaxPool
+//	action_delay       Delay
+//	observation_delay  Delay
+//	normalize_reward   Gamma
+type WrapperConfig struct {
+	Type     string  `json:"type"`
+	Delay    int     `json:"delay,omitempty"`
+	Sigma    float64 `json:"sigma,omitempty"`
+	Seed     int64   `json:"seed,omitempty"`
+	Channels []int   `json:"channels,omitempty"`
+	Skip     int     `json:"skip,omitempty"`
+	MaxPool  bool    `json:"max_pool,omitempty"`
+	Gamma    float64 `json:"gamma,omitempty"`
+}
+
+// TransformConfig describes a transform of state observations. Type
+// selects the transform, and the remaining fields are its arguments:
+//
+//	drop_channels  Channels
+//	flatten
+//	normalize      Mean, Std
+type TransformConfig struct {
+	Type     string  `json:"type"`
+	Channels []int   `json:"channels,omitempty"`
+	Mean     float64 `json:"mean,omitempty"`
+	Std      float64 `json:"std,omitempty"`
+}
+
+// Load loads a configuration from the file at path. Files with the
+// extension .yaml or .yml are read as YAML, and all other files are
+// read as JSON.
+func Load(path string) (EnvConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return EnvConfig{}, fmt.Errorf("load: %v", err)
+	}
+
+	var cfg EnvConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		cfg, err = ParseYAML(data)
+	default:
+		cfg, err = ParseJSON(data)
+	}
+	if err != nil {
+		return EnvConfig{}, fmt.Errorf("load: %v", err)
+	}
+	return cfg, nil
+}
+
+// ParseJSON parses a configuration written in JSON. Unknown fields are
+// an error, so that misspelled options are not silently ignored.
+func ParseJSON(data []byte) (EnvConfig, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var cfg EnvConfig
+	if err := dec.Decode(&cfg); err != nil {
+		return EnvConfig{}, fmt.Errorf("parseJSON: %v", err)
+	}
+	return cfg, nil
+}
+
+// ParseYAML parses a configuration written in YAML. Unknown fields are
+// an error, so that misspelled options are not silently ignored.
+func ParseYAML(data []byte) (EnvConfig, error) {
+	v, err := parseYAML(data)
+	if err != nil {
+		return EnvConfig{}, fmt.Errorf("parseYAML: %v", err)
+	}
+
+	// Decode through JSON so that both formats share the same field
+	// names and validation
+	b, err := json.Marshal(v)
+	if err != nil {
+		return EnvConfig{}, fmt.Errorf("parseYAML: %v", err)
+	}
+	cfg, err := ParseJSON(b)
+	if err != nil {
+		return EnvConfig{}, fmt.Errorf("parseYAML: %v", err)
+	}
+	return cfg, nil
+}
+
+// NewEnvironment constructs the environment described by cfg, with
+// all wrappers applied
+func NewEnvironment(cfg EnvConfig) (goatar.Env, error) {
+	name, err := goatar.ParseGameName(cfg.Game)
+	if err != nil {
+		return nil, fmt.Errorf("newEnvironment: %v", err)
+	}
+
+	opts, err := cfg.options()
+	if err != nil {
+		return nil, fmt.Errorf("newEnvironment: %v", err)
+	}
+
+	var env goatar.Env
+	env, err = goatar.New(name, cfg.StickyActionsProb, cfg.DifficultyRamping,
+		cfg.Seed, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("newEnvironment: %v", err)
+	}
+
+	for i, w := range cfg.Wrappers {
+		env, err = w.wrap(env)
+		if err != nil {
+			return nil, fmt.Errorf("newEnvironment: wrapper %v: %v", i, err)
+		}
+	}
+	return env, nil
+}
+
+// options returns the options used to construct the environment
+// described by the configuration
+func (cfg EnvConfig) options() ([]goatar.Option, error) {
+	var opts []goatar.Option
+	if cfg.GameSeed != nil {
+		opts = append(opts, goatar.WithGameSeed(*cfg.GameSeed))
+	}
+	if cfg.StickySeed != nil {
+		opts = append(opts, goatar.WithStickySeed(*cfg.StickySeed))
+	}
+	if cfg.Stochasticity != nil {
+		opts = append(opts, goatar.WithStochasticity(*cfg.Stochasticity))
+	}
+	if cfg.Version != 0 {
+		opts = append(opts, goatar.WithVersion(cfg.Version))
+	}
+	if cfg.RampChannel {
+		opts = append(opts, goatar.WithRampChannel())
+	}
+	if cfg.VisitationChannel {
+		opts = append(opts, goatar.WithVisitationChannel())
+	}
+	if cfg.NoReward {
+		opts = append(opts, goatar.WithNoReward())
+	}
+	if cfg.Trails != 0 {
+		opts = append(opts, goatar.WithTrails(cfg.Trails))
+	}
+	for channel, k := range cfg.TrailLengths {
+		opts = append(opts, goatar.WithTrails(k, channel))
+	}
+	if cfg.StrictTermination {
+		opts = append(opts, goatar.WithStrictTermination())
+	}
+	if cfg.RandomStarts != 0 {
+		opts = append(opts, goatar.WithRandomStarts(cfg.RandomStarts))
+	}
+	if len(cfg.EvalSeeds) > 0 {
+		opts = append(opts, goatar.WithEvalSeeds(cfg.EvalSeeds))
+	}
+	if len(cfg.SpawnSchedule) > 0 {
+		schedule := make(goatar.SpawnSchedule, len(cfg.SpawnSchedule))
+		for i, s := range cfg.SpawnSchedule {
+			schedule[i] = goatar.Spawn(s)
+		}
+		opts = append(opts, goatar.WithSpawnScheduler(schedule))
+	}
+	if len(cfg.Transforms) > 0 {
+		transforms := make([]goatar.Transform, len(cfg.Transforms))
+		for i, t := range cfg.Transforms {
+			transform, err := t.transform()
+			if err != nil {
+				return nil, fmt.Errorf("transform %v: %v", i, err)
+			}
+			transforms[i] = transform
+		}
+		opts = append(opts, goatar.WithTransforms(transforms...))
+	}
+
+	if len(cfg.ActionSet) > 0 {
+		actions := make([]goatar.Action, len(cfg.ActionSet))
+		for i, name := range cfg.ActionSet {
+			a, err := goatar.ParseAction(name)
+			if err != nil {
+				return nil, err
+			}
+			actions[i] = a
+		}
+		opts = append(opts, goatar.WithActionSet(actions))
+	}
+
+	if len(cfg.RewardWeights) > 0 {
+		opts = append(opts, goatar.WithRewardOverride(
+			goatar.RewardWeights(cfg.RewardWeights)))
+	}
+
+	if len(cfg.StickyActionProbs) > 0 {
+		probs := make(map[goatar.Action]float64, len(cfg.StickyActionProbs))
+		for name, p := range cfg.StickyActionProbs {
+			a, err := goatar.ParseAction(name)
+			if err != nil {
+				return nil, err
+			}
+			probs[a] = p
+		}
+		opts = append(opts, goatar.WithStickyActionProbs(probs))
+	}
+
+	if c := cfg.Asterix; c != nil && c.TreasureRamping {
+		opts = append(opts, goatar.WithTreasureRamping())
+	}
+
+	if c := cfg.Breakout; c != nil {
+		if c.BounceReward {
+			opts = append(opts, goatar.WithBounceReward())
+		}
+		if c.Juggling {
+			opts = append(opts, goatar.WithJuggling())
+		}
+	}
+
+	if c := cfg.Freeway; c != nil {
+		respawn, err := parseCarRespawn(c.CarRespawn)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, goatar.WithFreewayConfig(goatar.FreewayConfig{
+			Lanes:         c.Lanes,
+			CarRespawn:    respawn,
+			SecondChicken: c.SecondChicken,
+		}))
+	}
+
+	if c := cfg.SeaQuest; c != nil {
+		if c.ScalarGauges {
+			opts = append(opts, goatar.WithScalarGauges())
+		}
+		if c.RandomDiverSide {
+			opts = append(opts, goatar.WithRandomDiverSide())
+		}
+		if c.SweptCollisions {
+			opts = append(opts, goatar.WithSweptCollisions())
+		}
+		if c.DriftingFish != 0 || c.DriftInterval != 0 {
+			opts = append(opts, goatar.WithDriftingFish(c.DriftingFish,
+				c.DriftInterval))
+		}
+		if c.SparseSurfacingReward {
+			opts = append(opts, goatar.WithSparseSurfacingReward())
+		}
+		if c.OxygenWarning {
+			opts = append(opts, goatar.WithOxygenWarning(
+				c.OxygenWarningLevel))
+		}
+		if c.ShootableDivers {
+			opts = append(opts, goatar.WithShootableDivers())
+		}
+		if c.BulletTrails {
+			opts = append(opts, goatar.WithBulletTrails())
+		}
+	}
+
+	if c := cfg.SpaceInvaders; c != nil {
+		respawn, err := parseRespawn(c.Respawn)
+		if err != nil {
+			return nil, err
+		}
+		targeting, err := parseTargeting(c.Targeting)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, goatar.WithSpaceInvadersConfig(
+			goatar.SpaceInvadersConfig{
+				AlienRows:       c.AlienRows,
+				AlienCols:       c.AlienCols,
+				Respawn:         respawn,
+				UFO:             c.UFO,
+				Targeting:       targeting,
+				MinMoveInterval: c.MinMoveInterval,
+			}))
+		if c.BulletTrails {
+			opts = append(opts, goatar.WithBulletTrails())
+		}
+	}
+
+	return opts, nil
+}
+
+// parseRespawn returns the respawn behaviour with the given name
+func parseRespawn(name string) (goatar.Respawn, error) {
+	switch strings.ToLower(name) {
+	case "", "top":
+		return goatar.RespawnTop, nil
+	case "lower":
+		return goatar.RespawnLower, nil
+	case "none":
+		return goatar.NoRespawn, nil
+	default:
+		return 0, fmt.Errorf("unknown respawn behaviour %v", name)
+	}
+}
+
+// parseTargeting returns the alien targeting with the given name
+func parseTargeting(name string) (goatar.Targeting, error) {
+	switch strings.ToLower(name) {
+	case "", "nearest":
+		return goatar.NearestColumn, nil
+	case "random":
+		return goatar.RandomColumn, nil
+	case "leading":
+		return goatar.LeadingShot, nil
+	default:
+		return 0, fmt.Errorf("unknown targeting %v", name)
+	}
+}
+
+// parseCarRespawn returns the car respawn behaviour with the given name
+func parseCarRespawn(name string) (goatar.CarRespawn, error) {
+	switch strings.ToLower(name) {
+	case "", "wrap":
+		return goatar.WrapAround, nil
+	case "new_speed":
+		return goatar.NewSpeed, nil
+	default:
+		return 0, fmt.Errorf("unknown car respawn behaviour %v", name)
+	}
+}
+
+// transform returns the transform described by the configuration
+func (t TransformConfig) transform() (goatar.Transform, error) {
+	switch t.Type {
+	case "drop_channels":
+		return goatar.DropChannels(t.Channels...), nil
+
+	case "flatten":
+		return goatar.Flatten(), nil
+
+	case "normalize":
+		return goatar.Normalize(t.Mean, t.Std)
+
+	default:
+		return nil, fmt.Errorf("unknown transform type %q", t.Type)
+	}
+}
+
+// wrap applies the wrapper described by the configuration to env
+func (w WrapperConfig) wrap(env goatar.Env) (goatar.Env, error) {
+	switch w.Type {
+	case "delay_reward":
+		return wrappers.DelayReward(env, w.Delay)
+
+	case "noisy_reward":
+		return wrappers.NoisyReward(env, w.Sigma,
+			wrappers.WithNoiseSeed(w.Seed))
+
+	case "crop_observation":
+		return wrappers.CropObservation(env, w.Channels)
+
+	case "horizontal_flip":
+		return wrappers.HorizontalFlip(env)
+
+	case "frame_skip":
+		return wrappers.FrameSkip(env, w.Skip, w.MaxPool)
+
+	case "action_delay":
+		return wrappers.ActionDelay(env, w.Delay)
+
+	case "observation_delay":
+		return wrappers.ObservationDelay(env, w.Delay)
+
+	case "normalize_reward":
+		return wrappers.NormalizeReward(env, w.Gamma)
+
+	default:
+		return nil, fmt.Errorf("unknown wrapper type %q", w.Type)
+	}
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/wrappers"
+)
+
+// configJSON and configYAML describe the same configuration
+const (
+	configJSON = `{
+	"game": "spaceinvaders",
+	"seed": 42,
+	"sticky_actions_prob": 0.1,
+	"difficulty_ramping": true,
+	"version": 2,
+	"space_invaders": {"alien_rows": 3, "respawn": "lower"},
+	"eval_seeds": [1, 2, 3],
+	"wrappers": [{"type": "delay_reward", "delay": 5}]
+}`
+
+	configYAML = `# Space Invaders with a smaller formation
+game: spaceinvaders
+seed: 42
+sticky_actions_prob: 0.1   # the default of MinAtar
+difficulty_ramping: true
+version: 2
+
+space_invaders:
+  alien_rows: 3
+  respawn: 'lower' # one of "top", "lower", or "none", '#' quoted
+eval_seeds: [1, 2, 3]
+wrappers:
+- type: delay_reward
+  delay: 5
+`
+)
+
+// wantConfig is the configuration described by configJSON and
+// configYAML
+var wantConfig = EnvConfig{
+	Game:              "spaceinvaders",
+	Seed:              42,
+	StickyActionsProb: 0.1,
+	DifficultyRamping: true,
+	Version:           2,
+	SpaceInvaders:     &SpaceInvadersConfig{AlienRows: 3, Respawn: "lower"},
+	EvalSeeds:         []int64{1, 2, 3},
+	Wrappers:          []WrapperConfig{{Type: "delay_reward", Delay: 5}},
+}
+
+// TestParse checks that the same configuration written in JSON and in
+// YAML is parsed to the same value, and that Load chooses the format
+// from the file extension
+func TestParse(t *testing.T) {
+	fromJSON, err := ParseJSON([]byte(configJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromJSON, wantConfig) {
+		t.Errorf("got JSON configuration %+v, want %+v", fromJSON,
+			wantConfig)
+	}
+
+	fromYAML, err := ParseYAML([]byte(configYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromYAML, wantConfig) {
+		t.Errorf("got YAML configuration %+v, want %+v", fromYAML,
+			wantConfig)
+	}
+
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"config.json": configJSON,
+		"config.yaml": configYAML,
+		"config.YML":  configYAML,
+	} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if !reflect.DeepEqual(cfg, wantConfig) {
+			t.Errorf("%v: got configuration %+v, want %+v", name, cfg,
+				wantConfig)
+		}
+	}
+
+	// YAML is not valid JSON
+	path := filepath.Join(dir, "config.txt")
+	if err := ioutil.WriteFile(path, []byte(configYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected error loading YAML from a file without a YAML " +
+			"extension")
+	}
+	if _, err := Load(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected error loading a missing file")
+	}
+}
+
+// TestParseErrors checks that unknown fields, values of the wrong
+// type, and invalid YAML are rejected
+func TestParseErrors(t *testing.T) {
+	for _, test := range []struct {
+		name, json, yaml string
+	}{
+		{
+			name: "unknown field",
+			json: `{"game": "breakout", "sede": 1}`,
+			yaml: "game: breakout\nsede: 1\n",
+		},
+		{
+			name: "unknown nested field",
+			json: `{"game": "spaceinvaders",
+				"space_invaders": {"alien_row": 3}}`,
+			yaml: "game: spaceinvaders\nspace_invaders:\n  alien_row: 3\n",
+		},
+		{
+			name: "unknown wrapper field",
+			json: `{"game": "breakout",
+				"wrappers": [{"type": "delay_reward", "dely": 2}]}`,
+			yaml: "game: breakout\nwrappers:\n  - type: delay_reward\n" +
+				"    dely: 2\n",
+		},
+		{
+			name: "wrong type",
+			json: `{"game": "breakout", "seed": "1"}`,
+			yaml: "game: breakout\nseed: '1'\n",
+		},
+		{
+			name: "tab indentation",
+			yaml: "game: spaceinvaders\nspace_invaders:\n\talien_rows: 3\n",
+		},
+		{
+			name: "bad indentation",
+			yaml: "game: spaceinvaders\nspace_invaders:\n    alien_rows: 3\n" +
+				"  respawn: lower\n",
+		},
+	} {
+		if test.json != "" {
+			if _, err := ParseJSON([]byte(test.json)); err == nil {
+				t.Errorf("%v: expected error parsing JSON", test.name)
+			}
+		}
+		if _, err := ParseYAML([]byte(test.yaml)); err == nil {
+			t.Errorf("%v: expected error parsing YAML", test.name)
+		}
+	}
+
+	_, err := ParseYAML([]byte("game: breakout\n\tseed: 1\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2: tabs") {
+		t.Errorf("got error %v, want an error about the tab on line 2",
+			err)
+	}
+}
+
+// TestNewEnvironment checks that the environment constructed from a
+// configuration behaves as the environment constructed directly with
+// the same options and wrappers
+func TestNewEnvironment(t *testing.T) {
+	cfg, err := ParseYAML([]byte(configYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewEnvironment(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := goatar.New(goatar.SpaceInvaders, 0.1, true, 42,
+		goatar.WithVersion(2),
+		goatar.WithSpaceInvadersConfig(goatar.SpaceInvadersConfig{
+			AlienRows: 3,
+			Respawn:   goatar.RespawnLower,
+		}),
+		goatar.WithEvalSeeds([]int64{1, 2, 3}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := wrappers.DelayReward(env, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := got.(*wrappers.DelayedReward); !ok {
+		t.Fatalf("got environment of type %T, want %T", got, want)
+	}
+	if g, w := got.GameName(), want.GameName(); g != w {
+		t.Errorf("got game %v, want %v", g, w)
+	}
+
+	for i := 0; i < 500; i++ {
+		gotState, err := got.State()
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantState, err := want.State()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(gotState, wantState) {
+			t.Fatalf("step %v: states differ", i)
+		}
+
+		a := (i*5 + i/3) % got.NumActions()
+		gotReward, gotDone, err := got.Act(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantReward, wantDone, err := want.Act(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotReward != wantReward || gotDone != wantDone {
+			t.Fatalf("step %v: got reward %v and done %v, want %v and %v",
+				i, gotReward, gotDone, wantReward, wantDone)
+		}
+		if gotDone {
+			got.Reset()
+			want.Reset()
+		}
+	}
+
+	for _, bad := range []EnvConfig{
+		{Game: "pong"},
+		{Game: "breakout", SpaceInvaders: &SpaceInvadersConfig{}},
+		{Game: "spaceinvaders",
+			SpaceInvaders: &SpaceInvadersConfig{Respawn: "bottom"}},
+		{Game: "breakout", Wrappers: []WrapperConfig{{Type: "blur"}}},
+		{Game: "breakout",
+			Wrappers: []WrapperConfig{{Type: "delay_reward", Delay: -1}}},
+	} {
+		if _, err := NewEnvironment(bad); err == nil {
+			t.Errorf("%+v: expected error", bad)
+		}
+	}
+}
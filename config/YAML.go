@@ -0,0 +1,350 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a single non-empty line of a YAML document
+type yamlLine struct {
+	num    int // Line number, starting at 1
+	indent int
+	text   string // Content of the line without indentation or comments
+}
+
+// yamlParser parses the subset of YAML used by configuration files
+// into maps, slices, and scalars which can be marshalled as JSON
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// parseYAML parses a YAML document
+func parseYAML(data []byte) (interface{}, error) {
+	var p yamlParser
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, " \r")
+		text := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %v: tabs cannot be used for "+
+				"indentation", i+1)
+		}
+
+		text = strings.TrimSpace(stripComment(text))
+		if text == "" || text == "---" {
+			continue
+		}
+		p.lines = append(p.lines, yamlLine{
+			num:    i + 1,
+			indent: len(raw) - len(strings.TrimLeft(raw, " ")),
+			text:   text,
+		})
+	}
+
+	if len(p.lines) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	v, err := p.parseNode(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		if p.lines[p.pos].indent == p.lines[0].indent {
+			return nil, p.errorf("expected sequence item")
+		}
+		return nil, p.errorf("unexpected indentation")
+	}
+	return v, nil
+}
+
+// parseNode parses the block beginning at the current line, which has
+// the given indentation
+func (p *yamlParser) parseNode(indent int) (interface{}, error) {
+	if isSequenceItem(p.lines[p.pos].text) {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+// parseSequence parses a block sequence whose items have the given
+// indentation
+func (p *yamlParser) parseSequence(indent int) ([]interface{}, error) {
+	items := []interface{}{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		line := p.lines[p.pos]
+		if !isSequenceItem(line.text) {
+			// A sequence indented at the same level as its key ends at
+			// the next key, which the enclosing mapping parses
+			break
+		}
+
+		rest := strings.TrimLeft(line.text[1:], " ")
+		switch {
+		case rest == "":
+			// The item is a block on the following lines
+			p.pos++
+			if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+				item, err := p.parseNode(p.lines[p.pos].indent)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			} else {
+				items = append(items, nil)
+			}
+
+		case isSequenceItem(rest) || isMappingEntry(rest):
+			// The item is a block which begins on this line, so treat
+			// the remainder of the line as the first line of the block
+			p.lines[p.pos] = yamlLine{
+				num:    line.num,
+				indent: indent + len(line.text) - len(rest),
+				text:   rest,
+			}
+			item, err := p.parseNode(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+
+		default:
+			item, err := parseScalar(rest)
+			if err != nil {
+				return nil, p.errorf("%v", err)
+			}
+			items = append(items, item)
+			p.pos++
+		}
+	}
+
+	if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return items, nil
+}
+
+// parseMapping parses a block mapping whose keys have the given
+// indentation
+func (p *yamlParser) parseMapping(indent int) (map[string]interface{},
+	error) {
+	m := map[string]interface{}{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		line := p.lines[p.pos]
+		if isSequenceItem(line.text) {
+			return nil, p.errorf("unexpected sequence item")
+		}
+
+		key, value, err := splitMappingEntry(line.text)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		if _, ok := m[key]; ok {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		p.pos++
+
+		if value != "" {
+			if m[key], err = parseScalar(value); err != nil {
+				p.pos--
+				return nil, p.errorf("%v", err)
+			}
+			continue
+		}
+
+		// The value is a block on the following lines. Block sequences
+		// may be indented at the same level as their key.
+		switch {
+		case p.pos < len(p.lines) && p.lines[p.pos].indent > indent:
+			m[key], err = p.parseNode(p.lines[p.pos].indent)
+		case p.pos < len(p.lines) && p.lines[p.pos].indent == indent &&
+			isSequenceItem(p.lines[p.pos].text):
+			m[key], err = p.parseSequence(indent)
+		default:
+			m[key] = nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return m, nil
+}
+
+// errorf returns an error at the current line
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	num := p.lines[len(p.lines)-1].num
+	if p.pos < len(p.lines) {
+		num = p.lines[p.pos].num
+	}
+	return fmt.Errorf("line %v: %v", num, fmt.Sprintf(format, args...))
+}
+
+// isSequenceItem returns whether text is an item of a block sequence
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// isMappingEntry returns whether text is an entry of a block mapping
+func isMappingEntry(text string) bool {
+	_, _, err := splitMappingEntry(text)
+	return err == nil
+}
+
+// splitMappingEntry splits an entry of a block mapping into its key
+// and the unparsed value, which is empty if the value is a block
+func splitMappingEntry(text string) (key, value string, err error) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text)
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quoted key")
+		}
+		quoted, err := parseScalar(text[:end+1])
+		if err != nil {
+			return "", "", err
+		}
+		rest := text[end+1:]
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return "", "", fmt.Errorf("expected \":\" after key")
+		}
+		return quoted.(string), strings.TrimSpace(rest[1:]), nil
+	}
+
+	i := strings.Index(text, ": ")
+	if i < 0 {
+		if !strings.HasSuffix(text, ":") {
+			return "", "", fmt.Errorf("expected \"key: value\"")
+		}
+		i = len(text) - 1
+	}
+	key = strings.TrimSpace(text[:i])
+	if key == "" || strings.ContainsAny(key[:1], "[]{},&*!|>%@`") {
+		return "", "", fmt.Errorf("invalid key %q", key)
+	}
+	return key, strings.TrimSpace(text[i+1:]), nil
+}
+
+// parseScalar parses a scalar or a flow sequence of scalars
+func parseScalar(text string) (interface{}, error) {
+	switch {
+	case text == "{}":
+		return map[string]interface{}{}, nil
+
+	case strings.HasPrefix(text, "["):
+		if !strings.HasSuffix(text, "]") {
+			return nil, fmt.Errorf("unterminated flow sequence")
+		}
+		items := []interface{}{}
+		inner := strings.TrimSpace(text[1 : len(text)-1])
+		if inner == "" {
+			return items, nil
+		}
+		for _, field := range splitFlow(inner) {
+			item, err := parseScalar(strings.TrimSpace(field))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+
+	case strings.HasPrefix(text, "{"):
+		return nil, fmt.Errorf("flow mappings are not supported")
+
+	case text[0] == '"':
+		if closingQuote(text) != len(text)-1 {
+			return nil, fmt.Errorf("invalid double-quoted string %v", text)
+		}
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid double-quoted string %v", text)
+		}
+		return s, nil
+
+	case text[0] == '\'':
+		if closingQuote(text) != len(text)-1 {
+			return nil, fmt.Errorf("invalid single-quoted string %v", text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+
+	case strings.ContainsAny(text[:1], "&*!|>%@`"):
+		return nil, fmt.Errorf("unsupported YAML syntax %v", text)
+	}
+
+	switch text {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f, nil
+	}
+	return text, nil
+}
+
+// closingQuote returns the index of the quote which closes the quoted
+// string at the start of text, or -1 if the string is unterminated
+func closingQuote(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case quote == '\'' && text[i] == '\'' && i+1 < len(text) &&
+			text[i+1] == '\'':
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// splitFlow splits the contents of a flow sequence at commas which
+// are not within quotes
+func splitFlow(text string) []string {
+	var fields []string
+	start := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '"', '\'':
+			if end := closingQuote(text[i:]); end > 0 {
+				i += end
+			}
+		case ',':
+			fields = append(fields, text[start:i])
+			start = i + 1
+		}
+	}
+	return append(fields, text[start:])
+}
+
+// stripComment removes a trailing comment from a line. A comment
+// begins with "#" at the start of the line or after whitespace, and
+// outside of quotes.
+func stripComment(text string) string {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '"', '\'':
+			if end := closingQuote(text[i:]); end > 0 {
+				i += end
+			}
+		case '#':
+			if i == 0 || text[i-1] == ' ' {
+				return text[:i]
+			}
+		}
+	}
+	return text
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseYAML checks the values parsed from YAML documents using
+// each supported construct
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want interface{}
+	}{
+		{
+			name: "empty",
+			yaml: "# nothing here\n---\n",
+			want: map[string]interface{}{},
+		},
+		{
+			name: "scalars",
+			yaml: "int: -3\nfloat: 0.25\nyes: true\nno: False\n" +
+				"none: ~\nnull: null\nplain: hello world\n" +
+				"double: \"a\\tb\"\nsingle: 'it''s'\nempty: {}\n",
+			want: map[string]interface{}{
+				"int":    int64(-3),
+				"float":  0.25,
+				"yes":    true,
+				"no":     false,
+				"none":   nil,
+				"null":   nil,
+				"plain":  "hello world",
+				"double": "a\tb",
+				"single": "it's",
+				"empty":  map[string]interface{}{},
+			},
+		},
+		{
+			name: "comments",
+			yaml: "# leading comment\n" +
+				"a: 1 # trailing comment\n" +
+				"  # indented comment\n" +
+				"b: \"# not a comment\" # but this is\n" +
+				"c: 'x # y'\n" +
+				"d: x#y\n" +
+				"e: [1, \"#\", '# z'] # comment\n",
+			want: map[string]interface{}{
+				"a": int64(1),
+				"b": "# not a comment",
+				"c": "x # y",
+				"d": "x#y",
+				"e": []interface{}{int64(1), "#", "# z"},
+			},
+		},
+		{
+			name: "nested",
+			yaml: "outer:\n" +
+				"  inner:\n" +
+				"    value: 1\n" +
+				"  other: 2\n" +
+				"\"quoted key\": 3\n" +
+				"after:\n",
+			want: map[string]interface{}{
+				"outer": map[string]interface{}{
+					"inner": map[string]interface{}{"value": int64(1)},
+					"other": int64(2),
+				},
+				"quoted key": int64(3),
+				"after":      nil,
+			},
+		},
+		{
+			name: "sequences",
+			yaml: "indented:\n" +
+				"  - 1\n" +
+				"  - two\n" +
+				"unindented:\n" +
+				"- a: 1\n" +
+				"  b: 2\n" +
+				"- -\n" +
+				"  - 3\n" +
+				"- \n" +
+				"flow: []\n",
+			want: map[string]interface{}{
+				"indented": []interface{}{int64(1), "two"},
+				"unindented": []interface{}{
+					map[string]interface{}{"a": int64(1), "b": int64(2)},
+					[]interface{}{nil, int64(3)},
+					nil,
+				},
+				"flow": []interface{}{},
+			},
+		},
+		{
+			name: "top-level sequence",
+			yaml: "- 1\n- 2\n",
+			want: []interface{}{int64(1), int64(2)},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := parseYAML([]byte(test.yaml))
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v: got %#v, want %#v", test.name, got, test.want)
+		}
+	}
+}
+
+// TestParseYAMLErrors checks that invalid and unsupported YAML is
+// rejected
+func TestParseYAMLErrors(t *testing.T) {
+	for name, yaml := range map[string]string{
+		"tab indentation":      "a:\n\tb: 1\n",
+		"tab after spaces":     "a:\n  \tb: 1\n",
+		"over-indented key":    "a: 1\n  b: 2\n",
+		"under-indented key":   "a:\n    b: 1\n  c: 2\n",
+		"over-indented item":   "a:\n  - 1\n    - 2\n",
+		"item in mapping":      "a: 1\n- 2\n",
+		"key in sequence":      "- 1\na: 2\n",
+		"duplicate key":        "a: 1\na: 2\n",
+		"missing colon":        "a\n",
+		"unterminated quote":   "a: \"b\n",
+		"unterminated key":     "\"a: 1\n",
+		"unterminated flow":    "a: [1, 2\n",
+		"flow mapping":         "a: {b: 1}\n",
+		"anchor":               "a: &anchor 1\n",
+		"block scalar":         "a: |\n  text\n",
+		"trailing after quote": "a: \"b\" c\n",
+	} {
+		if _, err := parseYAML([]byte(yaml)); err == nil {
+			t.Errorf("%v: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Package curriculum implements schedules which change the parameters
+// of GoAtar environments over the course of training, such as the
+// sticky actions probability, whether difficulty ramping is enabled,
+// or how often enemies spawn. Parameters are changed in place with
+// goatar.Environment.SetParam, so environments never need to be
+// recreated when the curriculum advances.
+//
+// A Schedule is usually attached to an environment through the
+// environment's event bus, so that it advances with every step taken:
+//
+//	bus := events.NewBus()
+//	env, err := goatar.New(goatar.Asterix, 0.0, false, seed,
+//		goatar.WithEvents(bus))
+//	...
+//	schedule := curriculum.NewSchedule()
+//	schedule.Add(goatar.SpawnIntervalParam, curriculum.Linear(20, 5,
+//		0, 100000)...)
+//	cancel, err := schedule.Attach(env, bus)
+package curriculum
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/samuelfneumann/goatar/events"
+)
+
+// Parameterized is implemented by environments whose parameters can be
+// changed while they are running, such as *goatar.Environment
+type Parameterized interface {
+	Params() []string
+	SetParam(name string, value float64) error
+}
+
+// Point is the value of a parameter at some training step
+type Point struct {
+	Step  int
+	Value float64
+}
+
+// Linear returns the points of a schedule which linearly interpolates
+// between from at step start and to at step end
+func Linear(from, to float64, start, end int) []Point {
+	return []Point{{Step: start, Value: from}, {Step: end, Value: to}}
+}
+
+// Schedule interpolates the values of environment parameters over
+// training steps. The value of each parameter is piecewise linear
+// between the points added for it, and is constant before its first
+// point and after its last point.
+//
+// A Schedule is safe for concurrent use.
+type Schedule struct {
+	mu     sync.Mutex
+	curves []curve
+	step   int   // Number of steps observed by attached environments
+	err    error // First error encountered while applying the schedule
+}
+
+// curve is the schedule of a single parameter
+type curve struct {
+	name   string
+	points []Point // Sorted by step
+}
+
+// NewSchedule returns a new Schedule with no parameters
+func NewSchedule() *Schedule {
+	return &Schedule{}
+}
+
+// Add schedules the named parameter to take the values given by points.
+// Points must have distinct steps, but need not be sorted. Adding a
+// parameter which is already scheduled replaces its schedule.
+func (s *Schedule) Add(name string, points ...Point) error {
+	if len(points) == 0 {
+		return fmt.Errorf("add: no points for parameter %v", name)
+	}
+
+	sorted := append([]Point(nil), points...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Step < sorted[j].Step
+	})
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Step == sorted[i-1].Step {
+			return fmt.Errorf("add: repeated step %v for parameter %v",
+				sorted[i].Step, name)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.curves {
+		if s.curves[i].name == name {
+			s.curves[i].points = sorted
+			return nil
+		}
+	}
+	s.curves = append(s.curves, curve{name: name, points: sorted})
+	return nil
+}
+
+// Values returns the value of each scheduled parameter at step
+func (s *Schedule) Values(step int) map[string]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := make(map[string]float64, len(s.curves))
+	for _, c := range s.curves {
+		values[c.name] = c.at(step)
+	}
+	return values
+}
+
+// Apply sets the parameters of env to their scheduled values at step.
+// Parameters are set in the order in which they were added.
+func (s *Schedule) Apply(env Parameterized, step int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.apply(env, step)
+}
+
+// Attach applies the schedule to env at step 0, then advances the
+// schedule by one step and applies it to env each time a step is
+// emitted on bus. The schedule counts every step emitted on bus, so
+// when a bus is shared by several environments which are each
+// attached to the same Schedule, the parameters of all environments
+// follow the total number of steps taken.
+//
+// Attach first checks that every scheduled value can be set on env, so
+// that errors are reported before training begins. Errors which occur
+// later are returned by Err. The returned function detaches the
+// schedule from env.
+func (s *Schedule) Attach(env Parameterized, bus *events.Bus) (
+	cancel func(), err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.validate(env); err != nil {
+		return nil, fmt.Errorf("attach: %v", err)
+	}
+	if err := s.apply(env, s.step); err != nil {
+		return nil, fmt.Errorf("attach: %v", err)
+	}
+
+	return bus.Subscribe(func(e events.Event) {
+		if _, ok := e.(events.Step); !ok {
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.step++
+		if err := s.apply(env, s.step); err != nil && s.err == nil {
+			s.err = err
+		}
+	}), nil
+}
+
+// Step returns the number of steps observed by attached environments
+func (s *Schedule) Step() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.step
+}
+
+// Err returns the first error encountered while applying the schedule
+// to an attached environment
+func (s *Schedule) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// apply sets the parameters of env to their values at step
+func (s *Schedule) apply(env Parameterized, step int) error {
+	for _, c := range s.curves {
+		if err := env.SetParam(c.name, c.at(step)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate returns an error if env does not have a scheduled parameter
+// or if a scheduled value cannot be set. Since values are interpolated
+// linearly, every value between the first and last point of a curve is
+// valid whenever the values at the points are.
+func (s *Schedule) validate(env Parameterized) error {
+	params := make(map[string]bool)
+	for _, name := range env.Params() {
+		params[name] = true
+	}
+
+	for _, c := range s.curves {
+		if !params[c.name] {
+			return fmt.Errorf("no such parameter %v", c.name)
+		}
+		for _, p := range c.points {
+			if err := env.SetParam(c.name, p.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// at returns the value of the curve at step
+func (c curve) at(step int) float64 {
+	points := c.points
+	if step <= points[0].Step {
+		return points[0].Value
+	}
+	if last := points[len(points)-1]; step >= last.Step {
+		return last.Value
+	}
+
+	// Find the segment containing step
+	i := sort.Search(len(points), func(i int) bool {
+		return points[i].Step > step
+	})
+	from, to := points[i-1], points[i]
+	frac := float64(step-from.Step) / float64(to.Step-from.Step)
+	return from.Value + frac*(to.Value-from.Value)
+}
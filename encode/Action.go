@@ -0,0 +1,77 @@
+package encode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// actionAliases maps lower case words to the actions they name, in
+// addition to the names of the actions themselves
+var actionAliases = map[string]goatar.Action{
+	"no-op":   goatar.NoOp,
+	"no_op":   goatar.NoOp,
+	"nothing": goatar.NoOp,
+	"wait":    goatar.NoOp,
+	"stay":    goatar.NoOp,
+	"shoot":   goatar.Fire,
+}
+
+// ParseAction parses an action from text, such as the reply of a
+// language model to a prompt built with Text. The text may be an
+// action index, such as "3", or an action name, such as "LEFT" or
+// "no-op", optionally surrounded by other words and punctuation, e.g.
+// "Action: left." Names are matched ignoring case. An error is returned
+// if the text names no action or more than one distinct action.
+//
+// The returned action is the integer value of the goatar.Action, which
+// is the index of the action in the full action set. Environments with
+// a custom action set must map it to the index of the action in their
+// action set.
+func ParseAction(text string) (int, error) {
+	found := -1
+	for _, word := range words(text) {
+		a, ok := parseWord(word)
+		if !ok {
+			continue
+		}
+		if found >= 0 && int(a) != found {
+			return -1, fmt.Errorf("parseAction: ambiguous action in %q",
+				text)
+		}
+		found = int(a)
+	}
+
+	if found < 0 {
+		return -1, fmt.Errorf("parseAction: no action in %q", text)
+	}
+	return found, nil
+}
+
+// words splits text into lower case words, which are separated by
+// characters other than letters, digits, hyphens, and underscores
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' &&
+			r != '_'
+	})
+}
+
+// parseWord returns the action named by a single lower case word
+func parseWord(word string) (goatar.Action, bool) {
+	word = strings.Trim(word, "-_")
+	if a, ok := actionAliases[word]; ok {
+		return a, true
+	}
+	if a, err := goatar.ParseAction(word); err == nil {
+		return a, true
+	}
+	if i, err := strconv.Atoi(word); err == nil && i >= 0 &&
+		i < goatar.NumActions {
+		return goatar.Action(i), true
+	}
+	return 0, false
+}
@@ -0,0 +1,149 @@
+// Package encode encodes GoAtar states as text, and parses actions
+// from text, so that language-model agents can play GoAtar games
+// without knowing the layout of state observation channels.
+//
+// For example, Text describes a SeaQuest state as:
+//
+//	game: SeaQuest
+//	grid: 10 columns x 10 rows, (x, y) is (column, row) with (0, 0) at the top left
+//	difficulty: 0
+//	objects:
+//	  sub: (4, 0) facing left
+//	  friendly_bullet: (9, 1) facing right
+//	  enemy_fish: (0, 3) facing right, (9, 6) facing left
+//	gauges (filled cells):
+//	  oxygen: 7
+//	  diver: 0
+//	actions: 0=noop, 1=left, 2=up, 3=right, 4=down, 5=fire
+package encode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// Describable is implemented by environments whose current state can
+// be described as text, such as *goatar.Environment
+type Describable interface {
+	GameName() string
+	DifficultyRamp() int
+	Objects() []goatar.Object
+	Manifest() goatar.GameManifest
+	State() ([]float64, error)
+	Shape() goatar.Shape
+}
+
+// gaugeSuffixes are the suffixes of the names of channels which show
+// a gauge as a bar of cells
+var gaugeSuffixes = []string{"_guage", "_gauge"}
+
+// Text returns a compact textual description of the current state of
+// env: the game, the size of the grid, the difficulty ramp level, the
+// objects in the game grouped by type with their coordinates and
+// orientations, any gauges with the number of cells filled, and the
+// available actions. Objects are listed in the order returned by
+// env.Objects, so the player is listed first.
+//
+// Gauges are read from the state observation. They are omitted if the
+// state observation cannot be computed, or if its channels do not
+// match those of env's manifest, as happens when observations are
+// transformed.
+func Text(env Describable) string {
+	var b strings.Builder
+	shape := env.Shape()
+	manifest := env.Manifest()
+
+	fmt.Fprintf(&b, "game: %v\n", env.GameName())
+	fmt.Fprintf(&b, "grid: %v columns x %v rows, (x, y) is (column, row) "+
+		"with (0, 0) at the top left\n", shape.Cols, shape.Rows)
+	fmt.Fprintf(&b, "difficulty: %v\n", env.DifficultyRamp())
+
+	b.WriteString("objects:\n")
+	writeObjects(&b, env.Objects())
+
+	if gauges := gauges(env, manifest); len(gauges) > 0 {
+		b.WriteString("gauges (filled cells):\n")
+		for _, g := range gauges {
+			fmt.Fprintf(&b, "  %v\n", g)
+		}
+	}
+
+	actions := make([]string, len(manifest.Actions))
+	for i, a := range manifest.Actions {
+		actions[i] = fmt.Sprintf("%v=%v", i,
+			strings.ToLower(a.Action.String()))
+	}
+	fmt.Fprintf(&b, "actions: %v\n", strings.Join(actions, ", "))
+
+	return b.String()
+}
+
+// writeObjects writes the objects to b, one line per type of object,
+// with types in order of their first object
+func writeObjects(b *strings.Builder, objects []goatar.Object) {
+	var types []string
+	byType := make(map[string][]string)
+	for _, o := range objects {
+		if _, ok := byType[o.Type]; !ok {
+			types = append(types, o.Type)
+		}
+
+		desc := fmt.Sprintf("(%v, %v)", o.X, o.Y)
+		if o.Orientation != goatar.Unoriented {
+			desc += fmt.Sprintf(" facing %v", o.Orientation)
+		}
+		byType[o.Type] = append(byType[o.Type], desc)
+	}
+
+	if len(types) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, typ := range types {
+		fmt.Fprintf(b, "  %v: %v\n", typ, strings.Join(byType[typ], ", "))
+	}
+}
+
+// gauges returns a description of each gauge shown in the state
+// observation of env, as the number of cells of the gauge's channel
+// which are filled
+func gauges(env Describable, manifest goatar.GameManifest) []string {
+	shape := env.Shape()
+	if shape.Channels != len(manifest.Channels) {
+		return nil
+	}
+
+	var state []float64
+	var descs []string
+	for i, ch := range manifest.Channels {
+		name := ch.Name
+		isGauge := false
+		for _, suffix := range gaugeSuffixes {
+			if strings.HasSuffix(name, suffix) {
+				name = strings.TrimSuffix(name, suffix)
+				isGauge = true
+			}
+		}
+		if !isGauge {
+			continue
+		}
+
+		if state == nil {
+			var err error
+			if state, err = env.State(); err != nil {
+				return nil
+			}
+		}
+
+		filled := 0
+		size := shape.ChannelSize()
+		for _, v := range state[i*size : (i+1)*size] {
+			if v != 0 {
+				filled++
+			}
+		}
+		descs = append(descs, fmt.Sprintf("%v: %v", name, filled))
+	}
+	return descs
+}
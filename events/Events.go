@@ -0,0 +1,193 @@
+// Package events implements a stream of typed events emitted by GoAtar
+// environments, such as the start and end of episodes, steps, rewards,
+// and changes in difficulty. Subscribers can observe an environment
+// through a Bus without modifying the code which interacts with the
+// environment.
+//
+// To observe an environment, create a Bus, subscribe to it, and pass
+// it to the environment with goatar.WithEvents:
+//
+//	bus := events.NewBus()
+//	bus.Subscribe(func(e events.Event) {
+//		if end, ok := e.(events.EpisodeEnd); ok {
+//			fmt.Println("return:", end.Return)
+//		}
+//	})
+//	env, err := goatar.New(goatar.Breakout, 0.1, true, seed,
+//		goatar.WithEvents(bus))
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is an event emitted by an environment
+type Event interface {
+	// Kind returns the name of the kind of event
+	Kind() string
+
+	// Timestamp returns the time at which the event occurred
+	Timestamp() time.Time
+}
+
+// Header holds the information common to all events
+type Header struct {
+	Time    time.Time
+	Game    string
+	Episode int // Index of the episode in which the event occurred
+}
+
+// Timestamp returns the time at which the event occurred
+func (h Header) Timestamp() time.Time {
+	return h.Time
+}
+
+// EpisodeStart is emitted when the first step of an episode is taken
+type EpisodeStart struct {
+	Header
+}
+
+// Kind returns the name of the kind of event
+func (EpisodeStart) Kind() string { return "EpisodeStart" }
+
+// Step is emitted after each step of an episode
+type Step struct {
+	Header
+	Step     int // Index of the step in the episode
+	Action   int // Game action executed, after sticky actions
+	Reward   float64
+	Terminal bool
+}
+
+// Kind returns the name of the kind of event
+func (Step) Kind() string { return "Step" }
+
+// Reward is emitted after each step on which a non-zero reward is
+// received
+type Reward struct {
+	Header
+	Step   int // Index of the step in the episode
+	Reward float64
+}
+
+// Kind returns the name of the kind of event
+func (Reward) Kind() string { return "Reward" }
+
+// EpisodeEnd is emitted when an episode terminates, or when the
+// environment is reset before the episode terminates
+type EpisodeEnd struct {
+	Header
+	Steps      int
+	Return     float64
+	Terminated bool   // Whether the episode ended due to termination
+	Reason     string // Why the episode terminated, if it did
+}
+
+// Kind returns the name of the kind of event
+func (EpisodeEnd) Kind() string { return "EpisodeEnd" }
+
+// DifficultyRamp is emitted when the difficulty level of the game
+// changes
+type DifficultyRamp struct {
+	Header
+	Step  int // Index of the step in the episode
+	Level int // New difficulty level
+}
+
+// Kind returns the name of the kind of event
+func (DifficultyRamp) Kind() string { return "DifficultyRamp" }
+
+// Bus delivers events to subscribers. Callback subscribers are called
+// synchronously, in the order in which they subscribed. Channel
+// subscribers never block the emitting environment: if a channel is
+// full, the event is dropped for that subscriber.
+//
+// A Bus is safe for concurrent use, and a single Bus may be shared by
+// many environments.
+type Bus struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers []subscriber
+	dropped     uint64
+}
+
+// subscriber is a single subscriber to a Bus
+type subscriber struct {
+	id       int
+	callback func(Event)
+	ch       chan<- Event
+}
+
+// NewBus returns a new Bus with no subscribers
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers f to be called with every event emitted on the
+// bus. The returned function cancels the subscription.
+func (b *Bus) Subscribe(f func(Event)) (cancel func()) {
+	return b.add(subscriber{callback: f})
+}
+
+// SubscribeChan registers ch to receive every event emitted on the
+// bus. Events are dropped if ch is full. The returned function cancels
+// the subscription.
+func (b *Bus) SubscribeChan(ch chan<- Event) (cancel func()) {
+	return b.add(subscriber{ch: ch})
+}
+
+// add adds a subscriber to the bus
+func (b *Bus) add(s subscriber) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s.id = b.nextID
+	b.nextID++
+	b.subscribers = append(b.subscribers, s)
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		// Copy the subscribers so that concurrent calls to Emit can
+		// continue to use the old slice
+		subscribers := make([]subscriber, 0, len(b.subscribers))
+		for _, sub := range b.subscribers {
+			if sub.id != s.id {
+				subscribers = append(subscribers, sub)
+			}
+		}
+		b.subscribers = subscribers
+	}
+}
+
+// Emit delivers e to all subscribers
+func (b *Bus) Emit(e Event) {
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+
+	for _, s := range subscribers {
+		if s.callback != nil {
+			s.callback(e)
+			continue
+		}
+
+		select {
+		case s.ch <- e:
+		default:
+			b.mu.Lock()
+			b.dropped++
+			b.mu.Unlock()
+		}
+	}
+}
+
+// Dropped returns the number of events dropped because a channel
+// subscriber was full
+func (b *Bus) Dropped() uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.dropped
+}
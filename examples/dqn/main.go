@@ -0,0 +1,191 @@
+// Command dqn is a minimal example of the training loop of a DQN agent
+// in a GoAtar environment. The agent stores transitions in an
+// experience replay buffer, acts epsilon-greedily with respect to its
+// action values, and regresses its action values towards one-step
+// targets computed with a periodically updated target network:
+//
+//	go run ./examples/dqn --game breakout --steps 100000
+//
+// To keep the example free of dependencies, the action values are a
+// linear function of the state observation. Replacing the linear model
+// with a convolutional network, as in MinAtar, only requires changing
+// the linear type.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/policy"
+)
+
+// transition is a single transition stored in the replay buffer
+type transition struct {
+	state, next []float64
+	action      int
+	reward      float64
+	done        bool
+}
+
+// replay is a fixed-capacity experience replay buffer which overwrites
+// the oldest transitions once full
+type replay struct {
+	transitions []transition
+	next        int
+}
+
+// add adds t to the buffer
+func (r *replay) add(t transition, capacity int) {
+	if len(r.transitions) < capacity {
+		r.transitions = append(r.transitions, t)
+		return
+	}
+	r.transitions[r.next] = t
+	r.next = (r.next + 1) % capacity
+}
+
+// sample returns a uniformly random transition of the buffer
+func (r *replay) sample(rng *rand.Rand) transition {
+	return r.transitions[rng.Intn(len(r.transitions))]
+}
+
+// linear is a linear action-value function, with one weight vector and
+// bias per action
+type linear struct {
+	weights [][]float64
+	bias    []float64
+}
+
+// newLinear returns a linear action-value function of states with n
+// elements
+func newLinear(n, actions int) *linear {
+	l := &linear{
+		weights: make([][]float64, actions),
+		bias:    make([]float64, actions),
+	}
+	for a := range l.weights {
+		l.weights[a] = make([]float64, n)
+	}
+	return l
+}
+
+// values returns the value of each action in state
+func (l *linear) values(state []float64) []float64 {
+	q := make([]float64, len(l.weights))
+	for a, w := range l.weights {
+		q[a] = l.bias[a]
+		for i, x := range state {
+			q[a] += w[i] * x
+		}
+	}
+	return q
+}
+
+// update moves the value of action a in state towards target
+func (l *linear) update(state []float64, a int, target, stepSize float64) {
+	delta := target - l.values(state)[a]
+	l.bias[a] += stepSize * delta
+	for i, x := range state {
+		l.weights[a][i] += stepSize * delta * x
+	}
+}
+
+// copyFrom sets the weights of l to those of src
+func (l *linear) copyFrom(src *linear) {
+	for a := range l.weights {
+		copy(l.weights[a], src.weights[a])
+	}
+	copy(l.bias, src.bias)
+}
+
+// max returns the largest element of x
+func max(x []float64) float64 {
+	m := x[0]
+	for _, v := range x[1:] {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
+
+func main() {
+	name := flag.String("game", "breakout", "game to play")
+	steps := flag.Int("steps", 100000, "number of training steps")
+	seed := flag.Int64("seed", 0, "seed of the environment and agent")
+	capacity := flag.Int("replay", 100000, "capacity of the replay buffer")
+	batch := flag.Int("batch", 32, "number of transitions per update")
+	gamma := flag.Float64("gamma", 0.99, "discount factor")
+	stepSize := flag.Float64("step-size", 0.00025, "step size of updates")
+	targetInterval := flag.Int("target-interval", 1000,
+		"number of steps between target network updates")
+	start := flag.Int("start", 5000, "number of steps before learning")
+	flag.Parse()
+
+	game, err := goatar.ParseGameName(*name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	env, err := goatar.New(game, 0.1, true, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	actions := env.MinimalActionSet()
+	q := newLinear(env.Shape().Size(), len(actions))
+	target := newLinear(env.Shape().Size(), len(actions))
+	var buffer replay
+
+	state, err := env.State()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for t := 0; t < *steps; t++ {
+		// Anneal epsilon linearly from 1 to 0.1 over the first tenth of
+		// training
+		eps := 1 - 0.9*float64(t)/(0.1*float64(*steps))
+		if eps < 0.1 {
+			eps = 0.1
+		}
+
+		a := policy.EpsilonGreedy(q.values(state), eps, rng)
+		reward, done, err := env.Act(actions[a])
+		if err != nil {
+			log.Fatal(err)
+		}
+		next, err := env.State()
+		if err != nil {
+			log.Fatal(err)
+		}
+		buffer.add(transition{state, next, a, reward, done}, *capacity)
+		state = next
+
+		if done {
+			fmt.Printf("step %v: episode %v return %v\n", t, env.Episode(),
+				env.EpisodeReturn())
+			env.Reset()
+			if state, err = env.State(); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if t < *start {
+			continue
+		}
+		for i := 0; i < *batch; i++ {
+			tr := buffer.sample(rng)
+			y := tr.reward
+			if !tr.done {
+				y += *gamma * max(target.values(tr.next))
+			}
+			q.update(tr.state, tr.action, y, *stepSize)
+		}
+		if t%*targetInterval == 0 {
+			target.copyFrom(q)
+		}
+	}
+}
@@ -5,5 +5,4 @@ go 1.16
 require (
 	gioui.org v0.0.0-20210308172011-57750fc8a0a6
 	gonum.org/v1/gonum v0.9.3
-	gonum.org/v1/plot v0.9.0
 )
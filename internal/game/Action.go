@@ -0,0 +1,30 @@
+package game
+
+import "fmt"
+
+// Action is an action that can be taken in a game. Each Action has
+// the same integer value in every game.
+type Action int
+
+const (
+	NoOp Action = iota
+	Left
+	Up
+	Right
+	Down
+	Fire
+)
+
+// Actions is the full set of actions, in order
+var Actions = []Action{NoOp, Left, Up, Right, Down, Fire}
+
+// actionNames are the names of each Action
+var actionNames = [...]string{"NoOp", "Left", "Up", "Right", "Down", "Fire"}
+
+// String returns the name of the action
+func (a Action) String() string {
+	if a < 0 || int(a) >= len(actionNames) {
+		return fmt.Sprintf("Action(%d)", int(a))
+	}
+	return actionNames[a]
+}
@@ -0,0 +1,28 @@
+package game
+
+// SpawnPosition is a position at which an enemy can spawn. Enemies
+// moving right spawn at the left edge of the screen, and enemies
+// moving left spawn at the right edge.
+type SpawnPosition struct {
+	Row   int
+	Right bool
+}
+
+// Adversarial is implemented by games in which an adversary can choose
+// where enemies spawn, in place of the game's random number generator.
+// What kind of enemy spawns, and when, is still decided by the game.
+type Adversarial interface {
+	// SpawnDue returns whether an enemy spawns on the next step
+	SpawnDue() bool
+
+	// SpawnPositions returns the positions at which the enemy spawned
+	// on the next step can legally spawn. It returns nil if no enemy
+	// is due to spawn, and may be empty if an enemy is due but there
+	// is no room for it.
+	SpawnPositions() []SpawnPosition
+
+	// SetSpawn sets the position of the enemy spawned on the next
+	// step, which must be one of the positions returned by
+	// SpawnPositions. The position is only used for the next step.
+	SetSpawn(p SpawnPosition) error
+}
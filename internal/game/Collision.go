@@ -0,0 +1,49 @@
+package game
+
+// Positioned is an object which occupies a single cell of the screen
+type Positioned interface {
+	// Position returns the column and row of the object
+	Position() (x, y int)
+}
+
+// Point is a cell of the screen
+type Point struct {
+	X, Y int
+}
+
+// PointOf returns the cell occupied by p
+func PointOf(p Positioned) Point {
+	x, y := p.Position()
+	return Point{x, y}
+}
+
+// Position returns the column and row of the point
+func (p Point) Position() (x, y int) {
+	return p.X, p.Y
+}
+
+// Collides returns whether a and b occupy the same cell
+func Collides(a, b Positioned) bool {
+	ax, ay := a.Position()
+	bx, by := b.Position()
+	return ax == bx && ay == by
+}
+
+// Swapped returns whether two objects swapped cells in a single step,
+// passing through each other without ever occupying the same cell. The
+// first object moved from prevA to a, and the second from prevB to b.
+// Objects which did not move never swap.
+func Swapped(prevA, a, prevB, b Positioned) bool {
+	if Collides(prevA, a) || Collides(prevB, b) {
+		return false
+	}
+	return Collides(prevA, b) && Collides(a, prevB)
+}
+
+// SweptCollides returns whether two objects collided in a single step,
+// either by ending the step in the same cell or by passing through
+// each other. The first object moved from prevA to a, and the second
+// from prevB to b.
+func SweptCollides(prevA, a, prevB, b Positioned) bool {
+	return Collides(a, b) || Swapped(prevA, a, prevB, b)
+}
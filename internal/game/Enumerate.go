@@ -0,0 +1,110 @@
+package game
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Enumerable is implemented by games whose underlying state can be
+// encoded exactly as an integer. Each state has a unique encoding in
+// [0, NumStates()), although not every integer in that range encodes a
+// reachable state.
+type Enumerable interface {
+	// Encode returns the integer encoding of the current state
+	Encode() (*big.Int, error)
+
+	// Decode sets the current state to the state encoded by code
+	Decode(code *big.Int) error
+
+	// NumStates returns the number of encodable states
+	NumStates() *big.Int
+}
+
+// Encoder encodes a sequence of bounded integers as a single integer
+// using a mixed radix representation.
+type Encoder struct {
+	code       *big.Int
+	multiplier *big.Int
+	tmp        *big.Int
+}
+
+// NewEncoder returns a new Encoder
+func NewEncoder() *Encoder {
+	return &Encoder{
+		code:       big.NewInt(0),
+		multiplier: big.NewInt(1),
+		tmp:        new(big.Int),
+	}
+}
+
+// Put adds value to the encoding. Value must be in [0, base).
+func (e *Encoder) Put(value, base int) error {
+	if value < 0 || value >= base {
+		return fmt.Errorf("put: value %v ∉ [0, %v)", value, base)
+	}
+
+	e.tmp.SetInt64(int64(value))
+	e.tmp.Mul(e.tmp, e.multiplier)
+	e.code.Add(e.code, e.tmp)
+	e.multiplier.Mul(e.multiplier, e.tmp.SetInt64(int64(base)))
+	return nil
+}
+
+// PutBool adds b to the encoding
+func (e *Encoder) PutBool(b bool) {
+	if b {
+		e.Put(1, 2)
+	} else {
+		e.Put(0, 2)
+	}
+}
+
+// Code returns the encoding of all values put so far
+func (e *Encoder) Code() *big.Int {
+	return new(big.Int).Set(e.code)
+}
+
+// Size returns the number of distinct encodings possible with the
+// bases put so far
+func (e *Encoder) Size() *big.Int {
+	return new(big.Int).Set(e.multiplier)
+}
+
+// Decoder decodes an integer encoded by an Encoder. Values must be
+// taken from the Decoder in the same order and with the same bases as
+// they were put in the Encoder.
+type Decoder struct {
+	code *big.Int
+	base *big.Int
+	rem  *big.Int
+}
+
+// NewDecoder returns a new Decoder of code
+func NewDecoder(code *big.Int) *Decoder {
+	return &Decoder{
+		code: new(big.Int).Set(code),
+		base: new(big.Int),
+		rem:  new(big.Int),
+	}
+}
+
+// Take returns the next value in the encoding
+func (d *Decoder) Take(base int) int {
+	d.base.SetInt64(int64(base))
+	d.code.DivMod(d.code, d.base, d.rem)
+	return int(d.rem.Int64())
+}
+
+// TakeBool returns the next value in the encoding as a bool
+func (d *Decoder) TakeBool() bool {
+	return d.Take(2) == 1
+}
+
+// Done returns an error if any part of the code has not yet been
+// taken, which indicates the code was out of range.
+func (d *Decoder) Done() error {
+	if d.code.Sign() != 0 {
+		return fmt.Errorf("done: code out of range")
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package game
+
+// Reflection describes how a factor changes when the screen is flipped
+// horizontally
+type Reflection int
+
+const (
+	// Invariant factors, such as rows and counts, are unchanged
+	Invariant Reflection = iota
+
+	// Column factors hold a column x, which becomes cols-1-x. Factors
+	// of absent objects, which hold column -1, are unchanged.
+	Column
+
+	// Horizontal factors hold a signed horizontal direction or
+	// velocity, which is negated
+	Horizontal
+)
+
+// Factor is a single named variable of a factored state, such as the
+// column of the player
+type Factor struct {
+	Name       string
+	Value      int
+	Reflection Reflection
+}
+
+// Reflect returns the value of f when the screen, which has the given
+// number of columns, is flipped horizontally
+func (f Factor) Reflect(cols int) int {
+	switch f.Reflection {
+	case Column:
+		if f.Value < 0 {
+			return f.Value
+		}
+		return cols - 1 - f.Value
+	case Horizontal:
+		return -f.Value
+	default:
+		return f.Value
+	}
+}
@@ -1,7 +1,7 @@
 package game
 
 import (
-	"gonum.org/v1/gonum/mat"
+	"github.com/samuelfneumann/goatar/internal/grid"
 )
 
 // Concrete implementations of games
@@ -9,22 +9,90 @@ type Game interface {
 	Act(int) (float64, bool, error)
 
 	// State returns the state observation in row-major order.
-	// Since observations are of the form (rows, cols, channels),
+	// Since observations are of the form (channels, rows, cols),
 	// the elements at n*rows*cols to (n+1)*rows*cols are the rows and
 	// columns of channel n in row major order.
 	State() ([]float64, error)
 
 	Reset()
 
-	// Returns the shape of the state observation in rows, columns,
-	// chnnels
-	StateShape() []int
+	// Shape returns the shape of the state observation
+	Shape() Shape
 
 	Channel(i int) ([]float64, error) // Returns the matrix at channel i
 	NChannels() int
 
 	MinimalActionSet() []int
+	ActionEffect(Action) string // Describes the effect of an action
 	DifficultyRamp() int
+
+	// RewardRange returns the minimum and maximum reward that can be
+	// received on a single step
+	RewardRange() (min, max float64)
+
+	// Seed seeds the game's random number generator
+	Seed(int64)
+
+	// SetStochasticity sets the fraction of the game's random draws
+	// which are random rather than deterministic. See Random.
+	SetStochasticity(level float64)
+
+	// StateHash returns a hash of the underlying game state, which
+	// does not include the state of the game's random number generator
+	StateHash() uint64
+
+	// RNGHash returns a hash of the state of the game's random number
+	// generator
+	RNGHash() uint64
+
+	// Manifest returns a description of the game
+	Manifest() Manifest
+
+	// RewardEvents returns the events which resulted in rewards on the
+	// last step
+	RewardEvents() []RewardEvent
+
+	// TerminationReason returns the reason the episode ended, or
+	// NotTerminated if the episode has not ended
+	TerminationReason() TerminationReason
+
+	// Clone returns a deep copy of the game
+	Clone() Game
+
+	// Objects returns the objects currently in the game, in a
+	// deterministic order
+	Objects() []Object
+
+	// Factors returns the factored state of the game. Every state of
+	// a game has the same factors, in the same order.
+	Factors() []Factor
+}
+
+// ScalarObserver is implemented by games which expose scalar
+// observations in addition to the state observation tensor
+type ScalarObserver interface {
+	// Scalars returns the current scalar observations
+	Scalars() []float64
+
+	// ScalarNames returns the name of each scalar observation
+	ScalarNames() []string
+}
+
+// SoftResetter is implemented by games with a difficulty ramp, which
+// can begin a new episode without resetting the difficulty
+type SoftResetter interface {
+	// SoftReset resets the game to a starting state as Reset does, but
+	// keeps the current difficulty ramp
+	SoftReset()
+}
+
+// ConstantReporter is implemented by games which report the constants
+// of their dynamics, such as spawn and move intervals and entity caps,
+// so that they can be compared with MinAtar
+type ConstantReporter interface {
+	// Constants returns the value of each constant of the game, by the
+	// name of the constant in MinAtar where it has one
+	Constants() map[string]int
 }
 
 // minInt retruns the minimum int in a group of ints
@@ -59,10 +127,10 @@ func ClipInt(value, min, max int) int {
 	return value
 }
 
-// containsNonZero returns whether a matrix contains any non-zero
+// containsNonZero returns whether a grid contains any non-zero
 // elements
-func ContainsNonZero(matrix *mat.Dense) bool {
-	for _, val := range matrix.RawMatrix().Data {
+func ContainsNonZero(g *grid.Grid) bool {
+	for _, val := range g.Data() {
 		if val != 0.0 {
 			return true
 		}
@@ -70,11 +138,11 @@ func ContainsNonZero(matrix *mat.Dense) bool {
 	return false
 }
 
-// CountNonZero returns the number of nonzero elements in the matrix
-func CountNonZero(matrix *mat.Dense) int {
+// CountNonZero returns the number of nonzero elements in the grid
+func CountNonZero(g *grid.Grid) int {
 	total := 0
-	for _, elem := range matrix.RawMatrix().Data {
-		if elem == 0.0 {
+	for _, elem := range g.Data() {
+		if elem != 0.0 {
 			total++
 		}
 	}
@@ -82,88 +150,12 @@ func CountNonZero(matrix *mat.Dense) int {
 }
 
 // Where returns the indices in slice where condition is true
-func Where(vec mat.Vector, condition func(i float64) bool) []int {
+func Where(slice []float64, condition func(i float64) bool) []int {
 	var indices []int
-	for i := 0; i < vec.Len(); i++ {
-		if condition(vec.AtVec(i)) {
+	for i, val := range slice {
+		if condition(val) {
 			indices = append(indices, i)
 		}
 	}
 	return indices
 }
-
-// RollRowsUp rolls the rows of the matrix upwards. Rows that would go
-// off the matrix's top wrap around back to the bottom.
-func RollRowsUp(matrix *mat.Dense) {
-	r, c := matrix.Dims()
-	tmp1 := make([]float64, c)
-	tmp2 := make([]float64, c)
-
-	copy(tmp1, matrix.RawRowView(r-1))
-	for i := r - 1; i > 0; i-- {
-		copy(tmp2, matrix.RawRowView(i-1))
-		matrix.SetRow(i-1, tmp1)
-		copy(tmp1, tmp2)
-	}
-	matrix.SetRow(r-1, tmp1)
-}
-
-// RollRowsDown rolls the rows of the matrix downwards. Rows that
-// would go off the matrix's bottom wrap around back to the top.
-func RollRowsDown(matrix *mat.Dense) {
-	r, c := matrix.Dims()
-	tmp1 := make([]float64, c)
-	tmp2 := make([]float64, c)
-
-	copy(tmp1, matrix.RawRowView(0))
-	for i := 0; i < r-1; i++ {
-		copy(tmp2, matrix.RawRowView(i+1))
-		matrix.SetRow(i+1, tmp1)
-		copy(tmp1, tmp2)
-	}
-	matrix.SetRow(0, tmp1)
-}
-
-// RollColsLeft rolls the columns of the matrix left. Columns that
-// would go off the matrix's side wrap around back to the other side.
-func RollColsLeft(matrix *mat.Dense) {
-	r, c := matrix.Dims()
-	tmp1 := make([]float64, r)
-	tmp2 := make([]float64, r)
-
-	vecToSlice := func(slice []float64, vec mat.Vector) {
-		for i := 0; i < vec.Len(); i++ {
-			slice[i] = vec.AtVec(i)
-		}
-	}
-
-	vecToSlice(tmp1, matrix.ColView(c-1))
-	for i := c - 1; i > 0; i-- {
-		vecToSlice(tmp2, matrix.ColView(i-1))
-		matrix.SetCol(i-1, tmp1)
-		copy(tmp1, tmp2)
-	}
-	matrix.SetCol(c-1, tmp1)
-}
-
-// RollColsRight rolls the columns of the matrix right. Columns that
-// would go off the matrix's side wrap around back to the other side.
-func RollColsRight(matrix *mat.Dense) {
-	r, c := matrix.Dims()
-	tmp1 := make([]float64, r)
-	tmp2 := make([]float64, r)
-
-	vecToSlice := func(slice []float64, vec mat.Vector) {
-		for i := 0; i < vec.Len(); i++ {
-			slice[i] = vec.AtVec(i)
-		}
-	}
-
-	vecToSlice(tmp1, matrix.ColView(0))
-	for i := 0; i < c-1; i++ {
-		vecToSlice(tmp2, matrix.ColView(i+1))
-		matrix.SetCol(i+1, tmp1)
-		copy(tmp1, tmp2)
-	}
-	matrix.SetCol(0, tmp1)
-}
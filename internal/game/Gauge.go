@@ -0,0 +1,63 @@
+package game
+
+// Gauge is a bar of cells drawn along a row of a channel of state
+// observations, whose length shows a quantity such as the remaining
+// oxygen in SeaQuest. A gauge describes only where and how the bar is
+// drawn; the value it shows is kept by the game.
+type Gauge struct {
+	Channel string // Name of the channel the gauge is drawn in
+	Row     int    // Row the gauge is drawn along
+	Col     int    // Column of the leftmost cell of the gauge
+	Cells   int    // Number of cells of a full gauge
+	Max     int    // Value shown by a full gauge
+
+	// Reverse makes the gauge fill leftwards from its rightmost cell
+	// rather than rightwards from its leftmost cell
+	Reverse bool
+}
+
+// Filled returns the number of cells of the gauge which are filled
+// when it shows value. Cells are filled in proportion to value, rounded
+// down, and values outside [0, g.Max] show an empty or full gauge.
+func (g Gauge) Filled(value int) int {
+	if g.Max <= 0 {
+		return 0
+	}
+	return MaxInt(0, MinInt(value*g.Cells/g.Max, g.Cells))
+}
+
+// Span returns the columns [from, to) of the filled cells of the gauge
+// when it shows value
+func (g Gauge) Span(value int) (from, to int) {
+	n := g.Filled(value)
+	if g.Reverse {
+		return g.Col + g.Cells - n, g.Col + g.Cells
+	}
+	return g.Col, g.Col + n
+}
+
+// Fraction returns the fraction of the gauge which is filled when it
+// shows value, in [0, 1], without rounding to whole cells
+func (g Gauge) Fraction(value int) float64 {
+	if g.Max <= 0 {
+		return 0
+	}
+	f := float64(value) / float64(g.Max)
+	if f < 0 {
+		return 0
+	} else if f > 1 {
+		return 1
+	}
+	return f
+}
+
+// Draw fills the cells of the gauge which show value in channel, a
+// single channel of a state observation with the given number of
+// columns. Cells which fall outside the row are not drawn.
+func (g Gauge) Draw(channel []float64, cols, value int) {
+	from, to := g.Span(value)
+	row := channel[g.Row*cols : (g.Row+1)*cols]
+	for c := MaxInt(from, 0); c < MinInt(to, cols); c++ {
+		row[c] = 1.0
+	}
+}
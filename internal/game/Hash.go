@@ -0,0 +1,62 @@
+package game
+
+import "math"
+
+const (
+	fnvOffset uint64 = 14695981039346656037
+	fnvPrime  uint64 = 1099511628211
+)
+
+// Hasher computes a 64-bit hash of a sequence of values using the
+// FNV-1a hash function. Hasher does not allocate, so it is suitable for
+// hashing game states on every step.
+type Hasher struct {
+	h uint64
+}
+
+// NewHasher returns a new Hasher
+func NewHasher() Hasher {
+	return Hasher{h: fnvOffset}
+}
+
+// Uint64 adds v to the hash
+func (h *Hasher) Uint64(v uint64) {
+	for i := 0; i < 8; i++ {
+		h.h ^= v & 0xff
+		h.h *= fnvPrime
+		v >>= 8
+	}
+}
+
+// Int adds each of ints to the hash
+func (h *Hasher) Int(ints ...int) {
+	for _, v := range ints {
+		h.Uint64(uint64(v))
+	}
+}
+
+// Bool adds b to the hash
+func (h *Hasher) Bool(b bool) {
+	if b {
+		h.Uint64(1)
+	} else {
+		h.Uint64(0)
+	}
+}
+
+// Float adds each of floats to the hash
+func (h *Hasher) Float(floats ...float64) {
+	for _, v := range floats {
+		h.Uint64(math.Float64bits(v))
+	}
+}
+
+// Sum returns the hash of all values added so far. The FNV-1a hash is
+// passed through a finalizer so that similar states produce hashes
+// which differ in many bits.
+func (h *Hasher) Sum() uint64 {
+	z := h.h
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
@@ -0,0 +1,39 @@
+package game
+
+// Logger receives debug messages describing the events of a game, such
+// as spawns, collisions, difficulty ramps, and terminations
+type Logger interface {
+	Debugf(format string, args ...interface{})
+}
+
+// Loggable is implemented by games which log their events
+type Loggable interface {
+	// SetLogger makes the game log its events to l, or discard them if
+	// l is nil
+	SetLogger(l Logger)
+}
+
+// Log logs the events of a game to a Logger. The zero value discards
+// all messages. Since the arguments of Debugf are boxed even when no
+// Logger is set, calls on the hot path of a game should be guarded by
+// On so that games do not allocate when logging is disabled.
+type Log struct {
+	logger Logger
+}
+
+// SetLogger logs messages to l, or discards them if l is nil
+func (l *Log) SetLogger(logger Logger) {
+	l.logger = logger
+}
+
+// On returns whether messages are logged
+func (l *Log) On() bool {
+	return l.logger != nil
+}
+
+// Debugf logs a debug message, formatted as with fmt.Sprintf
+func (l *Log) Debugf(format string, args ...interface{}) {
+	if l.logger != nil {
+		l.logger.Debugf(format, args...)
+	}
+}
@@ -0,0 +1,156 @@
+package game
+
+import "sort"
+
+// Manifest describes a game as structured data so that it can be
+// introspected, e.g. by UIs or documentation generators
+type Manifest struct {
+	Description string
+	Channels    []ChannelInfo // Channels in the order of observations
+	Actions     []ActionInfo
+	Rewards     []RewardInfo
+	Termination []string // Conditions under which an episode ends
+	Ramping     string   // How difficulty ramping changes the game
+
+	// Mirror describes how the game maps onto itself when the screen
+	// is flipped horizontally, or is nil if the game is not
+	// horizontally symmetric
+	Mirror *Mirror
+
+	// ZOrder names the channels in the order they should be drawn when
+	// rendering, from bottom to top, so that e.g. the player is drawn
+	// above a trail which shares its cell. Channels which are not
+	// named, or which are not in Channels, are ignored.
+	ZOrder []string
+
+	// Gauges describes the channels which show a quantity as a bar of
+	// cells rather than the positions of objects, such as SeaQuest's
+	// oxygen gauge
+	Gauges []Gauge
+
+	// Trails names the channels which mark the cells behind moving
+	// objects, indicating their direction of movement
+	Trails []string
+}
+
+// Mirror describes how a horizontally symmetric game maps onto itself
+// when the screen is flipped horizontally
+type Mirror struct {
+	// Actions maps each action to the action with the mirrored effect
+	Actions map[Action]Action
+
+	// Channels maps the name of each channel whose meaning is mirrored,
+	// such as a channel of objects moving left, to the name of the
+	// mirrored channel. Channels which are not in the map are their
+	// own mirror.
+	Channels map[string]string
+
+	// Orientation names the factor whose sign gives the orientation of
+	// a state, such as the horizontal direction of the ball, so that
+	// states can be canonicalized by flipping the screen whenever the
+	// factor is negative. It is empty if states have no orientation.
+	Orientation string
+}
+
+// ChannelInfo describes a single channel of state observations
+type ChannelInfo struct {
+	Name        string
+	Description string
+
+	// NonBinary is true if the channel takes values between 0 and 1, as
+	// well as 0 and 1 themselves
+	NonBinary bool
+}
+
+// ActionInfo describes the effect of a single action
+type ActionInfo struct {
+	Action Action
+	Effect string
+}
+
+// RewardInfo describes an event which results in a reward
+type RewardInfo struct {
+	Event  string
+	Reward string // Reward received, which may depend on the game state
+}
+
+// ChannelInfos returns the ChannelInfo of each channel in channels,
+// which maps channel names to indices, ordered by index. Descriptions
+// are looked up by channel name.
+func ChannelInfos(channels map[string]int,
+	descriptions map[string]string) []ChannelInfo {
+	names := make([]string, 0, len(channels))
+	for name := range channels {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return channels[names[i]] < channels[names[j]]
+	})
+
+	infos := make([]ChannelInfo, len(names))
+	for i, name := range names {
+		infos[i] = ChannelInfo{Name: name, Description: descriptions[name]}
+	}
+	return infos
+}
+
+// DrawOrder returns the indices of the channels of m from bottom to
+// top, as given by m.ZOrder. Channels which are not named in m.ZOrder
+// are drawn below all others, in channel order.
+func (m Manifest) DrawOrder() []int {
+	indices := make(map[string]int, len(m.Channels))
+	for i, ch := range m.Channels {
+		indices[ch.Name] = i
+	}
+
+	ordered := make(map[int]bool, len(m.ZOrder))
+	var top []int
+	for _, name := range m.ZOrder {
+		if i, ok := indices[name]; ok && !ordered[i] {
+			ordered[i] = true
+			top = append(top, i)
+		}
+	}
+
+	order := make([]int, 0, len(m.Channels))
+	for i := range m.Channels {
+		if !ordered[i] {
+			order = append(order, i)
+		}
+	}
+	return append(order, top...)
+}
+
+// ActionInfos returns the ActionInfo of each action of g
+func ActionInfos(g Game) []ActionInfo {
+	infos := make([]ActionInfo, len(Actions))
+	for i, a := range Actions {
+		infos[i] = ActionInfo{Action: a, Effect: g.ActionEffect(a)}
+	}
+	return infos
+}
+
+// HorizontalMirror returns a Mirror which swaps the Left and Right
+// actions and swaps the given pairs of channels
+func HorizontalMirror(channelPairs ...[2]string) *Mirror {
+	actions := make(map[Action]Action, len(Actions))
+	for _, a := range Actions {
+		actions[a] = a
+	}
+	actions[Left], actions[Right] = Right, Left
+
+	channels := make(map[string]string, 2*len(channelPairs))
+	for _, pair := range channelPairs {
+		channels[pair[0]] = pair[1]
+		channels[pair[1]] = pair[0]
+	}
+
+	return &Mirror{Actions: actions, Channels: channels}
+}
+
+// OrientedBy sets the Orientation of m to the given factor and returns
+// m
+func (m *Mirror) OrientedBy(factor string) *Mirror {
+	m.Orientation = factor
+	return m
+}
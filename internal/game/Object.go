@@ -0,0 +1,45 @@
+package game
+
+// Orientation is the direction in which an object faces or moves. The
+// orientations are shared by all games.
+type Orientation string
+
+const (
+	// Unoriented is the orientation of an object which does not face
+	// or move in any particular direction
+	Unoriented Orientation = ""
+
+	FacingLeft      Orientation = "left"
+	FacingRight     Orientation = "right"
+	FacingUp        Orientation = "up"
+	FacingDown      Orientation = "down"
+	FacingUpLeft    Orientation = "up-left"
+	FacingUpRight   Orientation = "up-right"
+	FacingDownLeft  Orientation = "down-left"
+	FacingDownRight Orientation = "down-right"
+)
+
+// HorizontalOrientation returns FacingRight if direction is positive,
+// FacingLeft if it is negative, and Unoriented otherwise
+func HorizontalOrientation(direction int) Orientation {
+	switch {
+	case direction > 0:
+		return FacingRight
+	case direction < 0:
+		return FacingLeft
+	default:
+		return Unoriented
+	}
+}
+
+// Object is a single object in a game, such as an enemy or a bullet,
+// described by the game's internal state rather than by the state
+// observation
+type Object struct {
+	// Type is the kind of object. Where possible, it is the name of
+	// the channel in which the object is shown, e.g. "enemy_sub".
+	Type string
+
+	X, Y        int // Column and row of the cell occupied by the object
+	Orientation Orientation
+}
@@ -0,0 +1,51 @@
+package game
+
+import "math"
+
+// Names of the parameters of Parameterized games
+const (
+	// RampingParam is non-zero if difficulty ramping is enabled
+	RampingParam = "ramping"
+
+	// SpawnIntervalParam is the number of steps between enemy spawns
+	// at the start of an episode
+	SpawnIntervalParam = "spawn_interval"
+
+	// MoveIntervalParam is the number of steps between enemy moves at
+	// the start of an episode
+	MoveIntervalParam = "move_interval"
+)
+
+// Parameterized is implemented by games whose parameters can be
+// changed while the game is running, without recreating the game.
+// Parameters which describe the start of an episode, such as
+// SpawnIntervalParam, take effect immediately and persist across
+// calls to Reset. Difficulty ramping continues from the new values.
+type Parameterized interface {
+	// Params returns the names of the game's parameters
+	Params() []string
+
+	// Param returns the current value of the named parameter and
+	// whether the game has such a parameter
+	Param(name string) (float64, bool)
+
+	// SetParam sets the value of the named parameter
+	SetParam(name string, value float64) error
+}
+
+// IntParam rounds value to the nearest integer and returns it if it is
+// at least min
+func IntParam(value float64, min int) (int, bool) {
+	if math.IsNaN(value) || value < float64(min) || value > math.MaxInt32 {
+		return 0, false
+	}
+	return int(math.Round(value)), true
+}
+
+// BoolParam returns the float64 value of a boolean parameter
+func BoolParam(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,13 @@
+package game
+
+// MultiAgent is implemented by games in which more than one player is
+// controlled by agents. Player 0 is controlled through Act, and the
+// remaining players act as set by SetPlayerActions.
+type MultiAgent interface {
+	// Players returns the number of players controlled by agents
+	Players() int
+
+	// SetPlayerActions sets the actions of players 1, 2, ... on the
+	// next step. Players whose action is not set take no action.
+	SetPlayerActions(actions []int) error
+}
@@ -0,0 +1,9 @@
+package game
+
+// Navigable is implemented by games in which the player moves around
+// the screen, rather than along a single row
+type Navigable interface {
+	// PlayerPosition returns the column and row of the cell occupied by
+	// the player
+	PlayerPosition() (x, y int)
+}
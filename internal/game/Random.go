@@ -0,0 +1,161 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+)
+
+// goldenRatio is the fractional part of the golden ratio, which is
+// used to generate evenly spread deterministic draws
+var goldenRatio = (math.Sqrt(5) - 1) / 2
+
+// Draw names a kind of random draw made by a game, such as the row in
+// which an enemy spawns
+type Draw string
+
+// Random is the source of all randomness in a game. Every draw is
+// named, and the stochasticity level controls what fraction of draws
+// are random.
+//
+// With a stochasticity level of 1, every draw comes from the
+// underlying random number generator, as in MinAtar. With a
+// stochasticity level of 0, no random numbers are used: the k-th draw
+// of each name is instead the k-th element of a deterministic,
+// evenly spread sequence in [0, 1), so that events happen at the same
+// rates as with full stochasticity but predictably. With a level
+// between 0 and 1, each draw is random with probability equal to the
+// level. Draws marked with SetAlwaysRandom are random regardless of the
+// stochasticity level.
+type Random struct {
+	src    *Source
+	rng    *rand.Rand
+	level  float64
+	draws  map[Draw]uint64 // Number of deterministic draws of each name
+	always map[Draw]bool   // Draws which are always random
+}
+
+// NewRandom returns a new Random with a stochasticity level of 1
+func NewRandom(seed int64) *Random {
+	return newRandom(NewSource(seed))
+}
+
+// NewVersionedRandom returns a new Random with a stochasticity level of
+// 1 for version v of a game, whose random numbers are drawn from
+// NewVersionedSource(seed, v)
+func NewVersionedRandom(seed int64, v int) *Random {
+	return newRandom(NewVersionedSource(seed, v))
+}
+
+// newRandom returns a new Random with a stochasticity level of 1 which
+// draws its random numbers from src
+func newRandom(src *Source) *Random {
+	return &Random{
+		src:    src,
+		rng:    rand.New(src),
+		level:  1,
+		draws:  make(map[Draw]uint64),
+		always: make(map[Draw]bool),
+	}
+}
+
+// Clone returns a deep copy of r, which makes the same draws as r
+func (r *Random) Clone() *Random {
+	src := r.src.Clone()
+	clone := &Random{
+		src:    src,
+		rng:    rand.New(src),
+		level:  r.level,
+		draws:  make(map[Draw]uint64, len(r.draws)),
+		always: make(map[Draw]bool, len(r.always)),
+	}
+	for d, n := range r.draws {
+		clone.draws[d] = n
+	}
+	for d := range r.always {
+		clone.always[d] = true
+	}
+	return clone
+}
+
+// Seed seeds the underlying random number generator and restarts the
+// deterministic sequences of all draws
+func (r *Random) Seed(seed int64) {
+	r.rng.Seed(seed)
+	for d := range r.draws {
+		delete(r.draws, d)
+	}
+}
+
+// SetStochasticity sets the stochasticity level, which is clipped to
+// [0, 1]
+func (r *Random) SetStochasticity(level float64) {
+	r.level = math.Max(0, math.Min(1, level))
+}
+
+// SetAlwaysRandom marks draw d as random regardless of the
+// stochasticity level
+func (r *Random) SetAlwaysRandom(d Draw) {
+	r.always[d] = true
+}
+
+// Stochasticity returns the stochasticity level
+func (r *Random) Stochasticity() float64 {
+	return r.level
+}
+
+// Float64 returns a draw d in [0, 1)
+func (r *Random) Float64(d Draw) float64 {
+	if r.random(d) {
+		return r.rng.Float64()
+	}
+	return r.deterministic(d)
+}
+
+// Intn returns a draw d in [0, n). It panics if n <= 0.
+func (r *Random) Intn(d Draw, n int) int {
+	if r.random(d) {
+		return r.rng.Intn(n)
+	}
+
+	if n <= 0 {
+		panic("invalid argument to Intn")
+	}
+	return int(r.deterministic(d) * float64(n))
+}
+
+// random returns whether the next draw d should be random
+func (r *Random) random(d Draw) bool {
+	return r.level >= 1 || r.always[d] ||
+		(r.level > 0 && r.rng.Float64() < r.level)
+}
+
+// deterministic returns the next element of the deterministic sequence
+// of draw d
+func (r *Random) deterministic(d Draw) float64 {
+	r.draws[d]++
+	_, frac := math.Modf(float64(r.draws[d]) * goldenRatio)
+	return frac
+}
+
+// Hash returns a hash of the state of r, including the stochasticity
+// level, the state of the underlying random number generator, and the
+// state of the deterministic sequences
+func (r *Random) Hash() uint64 {
+	h := NewHasher()
+	h.Float(r.level)
+	r.src.hash(&h)
+
+	// Map iteration order is random, so the counts are combined in an
+	// order-independent way
+	var sum uint64
+	for d, n := range r.draws {
+		g := NewHasher()
+		for i := 0; i < len(d); i++ {
+			g.Uint64(uint64(d[i]))
+		}
+		g.Uint64(n)
+		sum += g.Sum()
+	}
+	h.Uint64(sum)
+	return h.Sum()
+}
@@ -0,0 +1,21 @@
+package game
+
+// RewardEventType is the kind of event which results in a reward. The
+// types are shared across games so that reward events can be compared
+// between games.
+type RewardEventType string
+
+const (
+	Collect RewardEventType = "collect" // An item is collected
+	Destroy RewardEventType = "destroy" // An object is destroyed
+	Goal    RewardEventType = "goal"    // A goal location is reached
+	Bonus   RewardEventType = "bonus"   // A bonus is awarded
+)
+
+// RewardEvent is a single event which resulted in a reward
+type RewardEvent struct {
+	Type   RewardEventType
+	Amount float64
+	X, Y   int    // Position at which the event occurred
+	Entity string // Name of the entity involved, e.g. "gold"
+}
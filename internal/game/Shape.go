@@ -0,0 +1,26 @@
+package game
+
+// Shape is the shape of a state observation tensor. Observations are
+// laid out channel by channel, with the cells of each channel in
+// row-major order.
+type Shape struct {
+	Channels int
+	Rows     int
+	Cols     int
+}
+
+// Size returns the number of elements in an observation of the shape
+func (s Shape) Size() int {
+	return s.Channels * s.Rows * s.Cols
+}
+
+// ChannelSize returns the number of elements in a single channel of an
+// observation of the shape
+func (s Shape) ChannelSize() int {
+	return s.Rows * s.Cols
+}
+
+// Ints returns the shape as (channels, rows, cols)
+func (s Shape) Ints() []int {
+	return []int{s.Channels, s.Rows, s.Cols}
+}
@@ -0,0 +1,177 @@
+package game
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// Source is a random number source implementing rand.Source64. Unlike
+// the sources in math/rand, the state of a Source can be copied with
+// Clone, which makes it possible to snapshot and restore games exactly.
+//
+// A Source created with NewSource uses the xoshiro256** algorithm. A
+// Source created with NewLegacySource draws the same numbers as the
+// sources in math/rand, which version 1 of each game uses so that
+// seeded runs of the original port of MinAtar can be reproduced.
+type Source struct {
+	s      [4]uint64
+	legacy *legacySource // Used instead of s if not nil
+}
+
+// NewSource returns a new xoshiro256** Source seeded with seed
+func NewSource(seed int64) *Source {
+	src := &Source{}
+	src.Seed(seed)
+	return src
+}
+
+// NewLegacySource returns a new Source seeded with seed, which draws
+// the same numbers as rand.NewSource(seed)
+func NewLegacySource(seed int64) *Source {
+	src := &Source{legacy: &legacySource{}}
+	src.Seed(seed)
+	return src
+}
+
+// NewVersionedSource returns a new Source seeded with seed for version
+// v of a game. Version 1 of each game draws the same numbers as
+// math/rand, and later versions use xoshiro256**.
+func NewVersionedSource(seed int64, v int) *Source {
+	if v == 1 {
+		return NewLegacySource(seed)
+	}
+	return NewSource(seed)
+}
+
+// Clone returns a copy of src, which draws the same numbers as src but
+// does not share any state with it
+func (src *Source) Clone() *Source {
+	clone := *src
+	if src.legacy != nil {
+		legacy := *src.legacy
+		clone.legacy = &legacy
+	}
+	return &clone
+}
+
+// Seed seeds the source. The state of a xoshiro256** Source is
+// initialized with splitmix64, as recommended by the authors of
+// xoshiro256**.
+func (src *Source) Seed(seed int64) {
+	if src.legacy != nil {
+		src.legacy.seed(seed)
+		return
+	}
+
+	x := uint64(seed)
+	for i := range src.s {
+		x += 0x9e3779b97f4a7c15
+		z := x
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		src.s[i] = z ^ (z >> 31)
+	}
+}
+
+// Uint64 returns a pseudo-random 64-bit integer
+func (src *Source) Uint64() uint64 {
+	if src.legacy != nil {
+		return src.legacy.uint64()
+	}
+
+	s := &src.s
+	result := bits.RotateLeft64(s[1]*5, 7) * 9
+	t := s[1] << 17
+
+	s[2] ^= s[0]
+	s[3] ^= s[1]
+	s[1] ^= s[2]
+	s[0] ^= s[3]
+	s[2] ^= t
+	s[3] = bits.RotateLeft64(s[3], 45)
+
+	return result
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer
+func (src *Source) Int63() int64 {
+	if src.legacy != nil {
+		return int64(src.legacy.uint64() & (1<<63 - 1))
+	}
+	return int64(src.Uint64() >> 1)
+}
+
+// hash adds the state of src to h
+func (src *Source) hash(h *Hasher) {
+	if src.legacy != nil {
+		h.Int(src.legacy.tap, src.legacy.feed)
+		for _, v := range src.legacy.vec {
+			h.Uint64(uint64(v))
+		}
+		return
+	}
+	for _, v := range src.s {
+		h.Uint64(v)
+	}
+}
+
+const (
+	legacyLen = 607 // Length of the feedback register of math/rand
+	legacyTap = 273 // Distance between the taps of math/rand
+)
+
+// legacySource is the additive lagged Fibonacci generator of the
+// sources in math/rand, with state which can be copied
+type legacySource struct {
+	tap  int
+	feed int
+	vec  [legacyLen]int64
+}
+
+// seed sets the state of the generator to that of rand.NewSource(seed).
+// The state of a math/rand source cannot be read, but each of its first
+// legacyLen draws adds two elements of its initial feedback register,
+// so the register is recovered from those draws.
+func (l *legacySource) seed(seed int64) {
+	src := rand.NewSource(seed).(rand.Source64)
+	var x [legacyLen + 1]int64 // x[k] is draw k, counting from 1
+	for k := 1; k <= legacyLen; k++ {
+		x[k] = int64(src.Uint64())
+	}
+
+	// Draw k adds vec[feed] to vec[tap], where tap = -k and feed =
+	// legacyLen-legacyTap-k modulo legacyLen. From draw legacyTap+1
+	// onwards, the tap is an element overwritten by draw k-legacyTap,
+	// while the feed has not yet been overwritten.
+	feed := func(k int) int {
+		return ((legacyLen-legacyTap-k)%legacyLen + legacyLen) % legacyLen
+	}
+	for k := legacyTap + 1; k <= legacyLen; k++ {
+		l.vec[feed(k)] = x[k] - x[k-legacyTap]
+	}
+
+	// Before then, both elements are from the initial register
+	for k := 1; k <= legacyTap; k++ {
+		l.vec[feed(k)] = x[k] - l.vec[legacyLen-k]
+	}
+
+	l.tap = 0
+	l.feed = legacyLen - legacyTap
+}
+
+// uint64 returns the next draw of the generator
+func (l *legacySource) uint64() uint64 {
+	l.tap--
+	if l.tap < 0 {
+		l.tap += legacyLen
+	}
+
+	l.feed--
+	if l.feed < 0 {
+		l.feed += legacyLen
+	}
+
+	x := l.vec[l.feed] + l.vec[l.tap]
+	l.vec[l.feed] = x
+	return uint64(x)
+}
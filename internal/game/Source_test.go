@@ -0,0 +1,43 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestLegacySource checks that a legacy Source draws the same numbers
+// as the sources in math/rand, including after being cloned and
+// reseeded
+func TestLegacySource(t *testing.T) {
+	for _, seed := range []int64{0, 1, 7, -3, 1 << 40} {
+		want := rand.New(rand.NewSource(seed))
+		src := NewLegacySource(seed)
+		got := rand.New(src)
+		for i := 0; i < 5000; i++ {
+			if g, w := got.Int63(), want.Int63(); g != w {
+				t.Fatalf("seed %v draw %v: got %v, want %v", seed, i, g, w)
+			}
+		}
+
+		clone := rand.New(src.Clone())
+		for i := 0; i < 1000; i++ {
+			w := want.Uint64()
+			if g := got.Uint64(); g != w {
+				t.Fatalf("seed %v draw %v: got %v, want %v", seed, i, g, w)
+			}
+			if g := clone.Uint64(); g != w {
+				t.Fatalf("seed %v draw %v of clone: got %v, want %v", seed,
+					i, g, w)
+			}
+		}
+
+		src.Seed(seed + 1)
+		want.Seed(seed + 1)
+		for i := 0; i < 100; i++ {
+			if g, w := got.Intn(10), want.Intn(10); g != w {
+				t.Fatalf("seed %v draw %v after reseeding: got %v, want %v",
+					seed+1, i, g, w)
+			}
+		}
+	}
+}
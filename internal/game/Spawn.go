@@ -0,0 +1,44 @@
+package game
+
+// Spawn is an entity spawned into a game by a SpawnScheduler
+type Spawn struct {
+	// Step is the step of the episode on which the entity spawns,
+	// where the first step after a reset is step 0. It is only used by
+	// SpawnSchedule.
+	Step int
+
+	// Kind is the type of the entity, named as in the game's objects,
+	// e.g. "gold" or "enemy_sub"
+	Kind string
+
+	// Row is the row in which the entity spawns. Entities moving right
+	// spawn at the left edge of the screen, and entities moving left
+	// spawn at the right edge.
+	Row   int
+	Right bool
+}
+
+// SpawnScheduler decides when, where, and what entities spawn in a
+// game, in place of the game's spawn timers and random number
+// generator
+type SpawnScheduler interface {
+	// Spawns returns the entities to spawn on the given step of an
+	// episode, where the first step after a reset is step 0
+	Spawns(step int) []Spawn
+}
+
+// SpawnSchedule is a SpawnScheduler which spawns a fixed list of
+// entities on the same steps of every episode
+type SpawnSchedule []Spawn
+
+// Spawns returns the entities of the schedule to spawn on the given
+// step
+func (s SpawnSchedule) Spawns(step int) []Spawn {
+	var spawns []Spawn
+	for _, spawn := range s {
+		if spawn.Step == step {
+			spawns = append(spawns, spawn)
+		}
+	}
+	return spawns
+}
@@ -0,0 +1,48 @@
+package game
+
+// StateCache caches the state observation of a game, so that the
+// observation is computed at most once between mutations of the game,
+// however many times State or Channel are called.
+//
+// The cache is only correct if every method which mutates the game
+// calls Invalidate, which marks the cached observation as dirty. Since
+// stale observations are hard to notice, games should invalidate the
+// cache on every mutation path, even those which do not currently
+// change the observation, such as setting a parameter. A game must
+// not copy its StateCache when it is cloned.
+type StateCache struct {
+	state []float64 // Buffer holding the cached observation
+	dirty bool
+}
+
+// Invalidate marks the cached state observation as dirty, so that it
+// is recomputed the next time it is requested
+func (c *StateCache) Invalidate() {
+	c.dirty = true
+}
+
+// State returns a copy of the cached state observation of the given
+// size. If the cache is dirty, the observation is first recomputed by
+// observe, which is given a zeroed slice of the given size to fill. A
+// copy is returned so that callers may modify the observation without
+// corrupting the cache.
+func (c *StateCache) State(size int, observe func(state []float64) error) (
+	[]float64, error) {
+	if c.dirty || len(c.state) != size {
+		if cap(c.state) < size {
+			c.state = make([]float64, size)
+		}
+		c.state = c.state[:size]
+		for i := range c.state {
+			c.state[i] = 0
+		}
+
+		if err := observe(c.state); err != nil {
+			c.state = c.state[:0]
+			return nil, err
+		}
+		c.dirty = false
+	}
+
+	return append([]float64(nil), c.state...), nil
+}
@@ -0,0 +1,27 @@
+package game
+
+// TerminationReason is the reason an episode ended. The reasons are
+// shared by all games.
+type TerminationReason string
+
+const (
+	// NotTerminated is the reason of an episode which has not ended
+	NotTerminated TerminationReason = ""
+
+	HitByBullet           TerminationReason = "hit-by-bullet"
+	Collision             TerminationReason = "collision"
+	OutOfOxygen           TerminationReason = "out-of-oxygen"
+	TimeLimit             TerminationReason = "time-limit"
+	SurfacedWithoutDivers TerminationReason = "surfaced-without-divers"
+	AliensReachedBottom   TerminationReason = "aliens-reached-bottom"
+	BallMissed            TerminationReason = "ball-missed"
+	WaveCleared           TerminationReason = "wave-cleared"
+)
+
+// TimeLimited is implemented by games whose episodes end after a fixed
+// number of steps
+type TimeLimited interface {
+	// MaxEpisodeSteps returns the number of steps after which every
+	// episode ends
+	MaxEpisodeSteps() int
+}
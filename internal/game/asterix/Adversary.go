@@ -0,0 +1,53 @@
+package asterix
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/entity"
+)
+
+// SpawnDue returns whether an entity spawns on the next step. Entities
+// spawned by a spawn scheduler are never due.
+func (a *Asterix) SpawnDue() bool {
+	return a.config.SpawnScheduler == nil && a.spawnTimer <= 0 &&
+		!a.terminal
+}
+
+// SpawnPositions returns the positions at which the entity spawned on
+// the next step can spawn, which are both sides of each row not
+// already taken by an entity, or nil if no entity is due to spawn
+func (a *Asterix) SpawnPositions() []game.SpawnPosition {
+	if !a.SpawnDue() {
+		return nil
+	}
+
+	taken := make([]bool, maxEntities)
+	a.entities.Each(func(_ entity.ID, e entity.Entity) {
+		_, y := e.Position()
+		taken[y-1] = true
+	})
+
+	positions := []game.SpawnPosition{}
+	for i := range taken {
+		if !taken[i] {
+			positions = append(positions,
+				game.SpawnPosition{Row: i + 1, Right: false},
+				game.SpawnPosition{Row: i + 1, Right: true})
+		}
+	}
+	return positions
+}
+
+// SetSpawn sets the position of the entity spawned on the next step
+func (a *Asterix) SetSpawn(p game.SpawnPosition) error {
+	for _, legal := range a.SpawnPositions() {
+		if p == legal {
+			a.chosenSpawn = p
+			a.spawnChosen = true
+			return nil
+		}
+	}
+	return fmt.Errorf("setSpawn: cannot spawn in row %v moving right %v",
+		p.Row, p.Right)
+}
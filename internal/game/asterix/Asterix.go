@@ -12,9 +12,9 @@ package asterix
 
 import (
 	"fmt"
-	"math/rand"
 
 	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/entity"
 )
 
 const (
@@ -26,6 +26,10 @@ const (
 	shotCoolDown     int = 5
 	rampInterval     int = 100
 
+	// Spawn and move intervals at which difficulty ramping stops
+	minSpawnSpeed   int = 1
+	minMoveInterval int = 1
+
 	maxEntities int = 8
 )
 
@@ -34,7 +38,8 @@ const (
 //
 // See the package documentation for more details
 //
-// Underlying state is represented as a slice of *Entity and a *player.
+// Underlying state is represented as an *entity.Manager of *object and
+// a *player.
 // Each of these structs holds the position and orientation of the
 // corresponding game element.
 //
@@ -53,37 +58,118 @@ const (
 // a gold is in position (j, i).
 type Asterix struct {
 	channels  map[string]int
-	actionMap []rune
-	rng       *rand.Rand
+	actionMap []game.Action
+	rng       *game.Random
 	ramping   bool
+	config    Config
 
 	agent    *player
-	entities []*entity
+	entities *entity.Manager // Enemies and gold, each an *object
 
 	spawnSpeed int
 	spawnTimer int
 	moveSpeed  int
 	rampTimer  int
 	rampIndex  int
+	steps      int // Steps taken in the current episode
 	terminal   bool
+	reason     game.TerminationReason // Why the episode ended, if it has
+
+	// Position of the next spawn, if chosen by an adversary
+	chosenSpawn game.SpawnPosition
+	spawnChosen bool
+
+	// Spawn and move intervals at the start of each episode
+	initSpawnSpeed int
+	initMoveSpeed  int
+
+	rewardEvents []game.RewardEvent // Reward events of the last step
+
+	cache game.StateCache // Cached state observation
+	log   game.Log        // Logs the events of the game
+}
+
+// Versions describes the changes in behaviour of each version of the
+// game, where element i describes version i+1
+var Versions = []string{
+	"the original port of MinAtar, in which the player is shown in the " +
+		"enemy channel of state observations",
+	"the player is shown in the player channel, as in MinAtar; " +
+		"random numbers are drawn with xoshiro256** rather than " +
+		"math/rand, and sticky actions restart with each episode",
+}
+
+// LatestVersion is the latest version of the game
+const LatestVersion = 2
+
+// Config configures an Asterix game. The zero value is the default
+// configuration, which matches MinAtar.
+type Config struct {
+	// TreasureRamping makes treasure more valuable as the difficulty
+	// increases. Treasure picked up at difficulty level l is worth
+	// 1 + l/4, which can be used to study non-stationary reward scales.
+	TreasureRamping bool
+
+	// SpawnScheduler decides when, where, and what entities spawn, in
+	// place of the spawn timer. Entities are "enemy" or "gold", and
+	// spawn in rows 1 to 8. An entity is not spawned if its row is
+	// already taken. If nil, entities spawn randomly.
+	SpawnScheduler game.SpawnScheduler
+
+	// Version is the version of the game's behaviour, see Versions. If
+	// 0, the latest version is used.
+	Version int
+}
+
+// withDefaults returns the configuration with zero values replaced by
+// their defaults
+func (c Config) withDefaults() Config {
+	if c.Version == 0 {
+		c.Version = LatestVersion
+	}
+	return c
+}
+
+// validate returns an error if the configuration is invalid
+func (c Config) validate() error {
+	if c.Version < 0 || c.Version > LatestVersion {
+		return fmt.Errorf("version %v ∉ [1, %v]", c.Version, LatestVersion)
+	}
+	return nil
 }
 
 // New returns a new Asterix game
 func New(ramping bool, seed int64) (game.Game, error) {
+	return NewWithConfig(ramping, seed, Config{})
+}
+
+// NewWithConfig returns a new Asterix game with the given
+// configuration
+func NewWithConfig(ramping bool, seed int64, config Config) (game.Game,
+	error) {
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("newWithConfig: %v", err)
+	}
+
 	channels := map[string]int{
 		"player": 0,
 		"enemy":  1,
 		"trail":  2,
 		"gold":   3,
 	}
-	actionMap := []rune{'n', 'l', 'u', 'r', 'd', 'f'}
-	rng := rand.New(rand.NewSource(seed))
+	actionMap := []game.Action{game.NoOp, game.Left, game.Up, game.Right,
+		game.Down, game.Fire}
+	rng := game.NewVersionedRandom(seed, config.withDefaults().Version)
 
 	asterix := &Asterix{
 		channels:  channels,
 		actionMap: actionMap,
 		rng:       rng,
 		ramping:   ramping,
+		config:    config.withDefaults(),
+
+		initSpawnSpeed: initSpawnSpeed,
+		initMoveSpeed:  initMoveInterval,
 	}
 	asterix.Reset()
 
@@ -92,14 +178,39 @@ func New(ramping bool, seed int64) (game.Game, error) {
 
 // Reset resets the environment to some starting state
 func (a *Asterix) Reset() {
-	a.entities = make([]*entity, maxEntities)
-	a.spawnSpeed = initSpawnSpeed
+	a.cache.Invalidate()
+	a.rewardEvents = a.rewardEvents[:0]
+	if a.entities == nil {
+		a.entities = entity.NewManager()
+	} else {
+		a.entities.Clear()
+	}
+	a.spawnSpeed = a.initSpawnSpeed
 	a.spawnTimer = a.spawnSpeed
-	a.moveSpeed = initMoveInterval
-	a.agent = newPlayer(rows/2, cols/2, a.moveSpeed)
+	a.moveSpeed = a.initMoveSpeed
+	if a.agent == nil {
+		a.agent = newPlayer(rows/2, cols/2, a.moveSpeed)
+	} else {
+		*a.agent = player{rows / 2, cols / 2, a.moveSpeed}
+	}
 	a.rampTimer = rampInterval
 	a.rampIndex = 0
+	a.steps = 0
+	a.spawnChosen = false
 	a.terminal = false
+	a.reason = game.NotTerminated
+}
+
+// SoftReset resets the game to a starting state, but keeps the current
+// difficulty ramp, including the progress towards the next ramp
+func (a *Asterix) SoftReset() {
+	spawnSpeed, moveSpeed := a.spawnSpeed, a.moveSpeed
+	rampTimer, rampIndex := a.rampTimer, a.rampIndex
+	a.Reset()
+
+	a.spawnSpeed, a.spawnTimer = spawnSpeed, spawnSpeed
+	a.moveSpeed, a.agent.moveTimer = moveSpeed, moveSpeed
+	a.rampTimer, a.rampIndex = rampTimer, rampIndex
 }
 
 // Act takes one environmental step given some action and returns the
@@ -111,76 +222,68 @@ func (a *Asterix) Act(act int) (float64, bool, error) {
 			act, len(a.actionMap))
 	}
 
+	a.cache.Invalidate()
+	a.rewardEvents = a.rewardEvents[:0]
+	chosen := a.spawnChosen
+	a.spawnChosen = false
 	reward := 0.0
 	if a.terminal {
 		return reward, a.terminal, nil
 	}
 
-	// Spawn enemy if timer is up
-	if a.spawnTimer <= 0 {
-		a.spawnEntity()
+	// Spawn enemy if timer is up, or as scheduled
+	if a.config.SpawnScheduler != nil {
+		for _, spawn := range a.config.SpawnScheduler.Spawns(a.steps) {
+			if err := a.spawn(spawn); err != nil {
+				return -1, a.terminal, fmt.Errorf("act: %v", err)
+			}
+		}
+	} else if a.spawnTimer <= 0 {
+		a.spawnEntity(chosen)
 		a.spawnTimer = a.spawnSpeed
 	}
+	a.steps++
 
 	// Resolve player action
 	action := a.actionMap[act]
 	switch action {
-	case 'l':
+	case game.Left:
 		a.agent.moveLeft()
 
-	case 'r':
+	case game.Right:
 		a.agent.moveRight()
 
-	case 'u':
+	case game.Up:
 		a.agent.moveUp()
 
-	case 'd':
+	case game.Down:
 		a.agent.moveDown()
 	}
 
 	// Update entities
-	for i, entity := range a.entities {
-		if entity == nil {
-			continue
-		}
-
-		if entity.x() == a.agent.x() && entity.y() == a.agent.y() {
-			if entity.isGold() {
-				a.entities[i] = nil
-				reward++
-			} else {
-				a.terminal = true
-			}
-		}
-	}
+	a.entities.Each(func(id entity.ID, e entity.Entity) {
+		reward += a.collide(id, e.(*object))
+	})
 
 	// Housekeeping when the agent can move
 	if a.agent.canMove() {
 		a.agent.setMoveTimer(a.moveSpeed)
 
 		// Entities get updated and moved when the agent moves
-		for i, entity := range a.entities {
-			if entity == nil {
-				continue
-			}
+		a.entities.Each(func(id entity.ID, e entity.Entity) {
+			obj := e.(*object)
 
 			// Entities only move when the agent moves
-			entity.move()
+			obj.move()
 
-			if entity.x() < 0 || entity.x() > cols-1 {
+			if obj.x() < 0 || obj.x() > cols-1 {
 				// Entity moves off the screen
-				a.entities[i] = nil
+				a.entities.Remove(id)
+				return
 			}
 
-			if entity.x() == a.agent.x() && entity.y() == a.agent.y() {
-				if entity.isGold() {
-					a.entities[i] = nil
-					reward++
-				} else {
-					a.terminal = true
-				}
-			}
-		}
+			reward += a.collide(id, obj)
+		})
 	}
 
 	// Update timers
@@ -193,20 +296,26 @@ func (a *Asterix) Act(act int) (float64, bool, error) {
 	}
 
 	// Update the difficulty
-	if a.ramping && (a.spawnSpeed > 1 || a.moveSpeed > 1) {
+	if a.ramping && (a.spawnSpeed > minSpawnSpeed ||
+		a.moveSpeed > minMoveInterval) {
 		if a.rampTimer >= 0 {
 			a.rampTimer--
 		} else {
-			if a.moveSpeed > 1 && a.rampIndex%2 == 1 {
+			if a.moveSpeed > minMoveInterval && a.rampIndex%2 == 1 {
 				a.moveSpeed--
 			}
 
-			if a.spawnSpeed > 1 {
+			if a.spawnSpeed > minSpawnSpeed {
 				a.spawnSpeed--
 			}
 
 			a.rampIndex++
 			a.rampTimer = rampInterval
+			if a.log.On() {
+				a.log.Debugf("asterix: difficulty ramp %v: spawn "+
+					"interval %v, move interval %v", a.rampIndex,
+					a.spawnSpeed, a.moveSpeed)
+			}
 		}
 	}
 
@@ -215,37 +324,43 @@ func (a *Asterix) Act(act int) (float64, bool, error) {
 
 // State returns the state observation tensor
 func (a *Asterix) State() ([]float64, error) {
-	state := make([]float64, rows*cols*a.NChannels())
+	return a.cache.State(rows*cols*a.NChannels(), a.observe)
+}
 
+// observe fills the zeroed slice state with the state observation
+func (a *Asterix) observe(state []float64) error {
 	// Set player location
-	state[rows*cols+a.channels["player"]+a.agent.y()*cols+a.agent.x()] = 1.0
+	player := rows*cols*a.channels["player"] + a.agent.y()*cols + a.agent.x()
+	if a.config.Version == 1 {
+		player = rows*cols + a.channels["player"] + a.agent.y()*cols +
+			a.agent.x()
+	}
+	state[player] = 1.0
 
 	// Set each entity
-	for _, entity := range a.entities {
-		if entity == nil {
-			continue
-		}
+	a.entities.Each(func(_ entity.ID, e entity.Entity) {
+		obj := e.(*object)
 
 		// Get the channel for the entity
 		ch := a.channels["enemy"]
-		if entity.isGold() {
+		if obj.isGold() {
 			ch = a.channels["gold"]
 		}
 
 		// Set the entity in the state observation tensor
-		state[rows*cols*ch+entity.y()*cols+entity.x()] = 1.0
+		state[rows*cols*ch+obj.y()*cols+obj.x()] = 1.0
 
 		// Set the trail for the entity, which denotes movement
-		backX := entity.x() + 1
-		if entity.orientedRight() {
-			backX = entity.x() - 1
+		backX := obj.x() + 1
+		if obj.orientedRight() {
+			backX = obj.x() - 1
 		}
 
 		if backX >= 0 && backX <= cols-1 {
-			state[rows*cols*a.channels["trail"]+entity.y()*cols+backX] = 1.0
+			state[rows*cols*a.channels["trail"]+obj.y()*cols+backX] = 1.0
 		}
-	}
-	return state, nil
+	})
+	return nil
 }
 
 // Channel returns the channel at index i of the state observation
@@ -272,22 +387,95 @@ func (a *Asterix) DifficultyRamp() int {
 	return a.rampIndex
 }
 
+// RewardRange returns the minimum and maximum reward that can be
+// received on a single step. At most one gold can be picked up each
+// step.
+func (a *Asterix) RewardRange() (min, max float64) {
+	if !a.config.TreasureRamping {
+		return 0, 1
+	}
+	return 0, a.goldValue(a.maxRampIndex())
+}
+
+// Seed seeds the random number generator of the game. The game is not
+// reset.
+func (a *Asterix) Seed(seed int64) {
+	a.rng.Seed(seed)
+}
+
+// Clone returns a deep copy of the game. The copy behaves identically
+// to the original given the same actions, but does not share any state
+// with it.
+func (a *Asterix) Clone() game.Game {
+	clone := *a
+	clone.rng = a.rng.Clone()
+	clone.cache = game.StateCache{}
+
+	agent := *a.agent
+	clone.agent = &agent
+
+	clone.entities = a.entities.Clone(func(e entity.Entity) entity.Entity {
+		obj := *e.(*object)
+		return &obj
+	})
+
+	clone.rewardEvents = append([]game.RewardEvent(nil),
+		a.rewardEvents...)
+	return &clone
+}
+
+// SetStochasticity sets the fraction of random draws made by the game
+// which are random rather than deterministic
+func (a *Asterix) SetStochasticity(level float64) {
+	a.rng.SetStochasticity(level)
+}
+
+// StateHash returns a hash of the underlying game state, including
+// all timers. Two games with the same hash will, with high
+// probability, behave identically given the same actions and random
+// numbers.
+func (a *Asterix) StateHash() uint64 {
+	h := game.NewHasher()
+	h.Int(a.agent.x(), a.agent.y(), a.agent.moveTimer)
+	h.Int(a.entities.Len())
+	a.entities.Each(func(_ entity.ID, e entity.Entity) {
+		obj := e.(*object)
+		h.Int(obj.x(), obj.y(), obj.direction())
+		h.Bool(obj.isGold())
+	})
+	h.Int(a.spawnSpeed, a.spawnTimer, a.moveSpeed, a.rampTimer, a.rampIndex)
+	h.Bool(a.terminal)
+
+	return h.Sum()
+}
+
+// RNGHash returns a hash of the state of the game's random number
+// generator, which is not included in StateHash
+func (a *Asterix) RNGHash() uint64 {
+	return a.rng.Hash()
+}
+
+// RewardEvents returns the events which resulted in rewards on the
+// last step. The returned slice is only valid until the next step.
+func (a *Asterix) RewardEvents() []game.RewardEvent {
+	return a.rewardEvents
+}
+
 // NChannels returns the number of channels in a state observation
 // tensor
 func (a *Asterix) NChannels() int {
 	return len(a.channels)
 }
 
-// StateShape returns the shape of the state observation tensors as
-// (channels, rows, cols)
-func (a *Asterix) StateShape() []int {
-	return []int{a.NChannels(), rows, cols}
+// Shape returns the shape of the state observation tensors
+func (a *Asterix) Shape() game.Shape {
+	return game.Shape{Channels: a.NChannels(), Rows: rows, Cols: cols}
 }
 
 // MinimalActionSet returns the actions which actually have an effect
 // on the environment.
 func (a *Asterix) MinimalActionSet() []int {
-	minimalActions := []rune{'n', 'l', 'u', 'r', 'd'}
+	minimalActions := []game.Action{game.NoOp, game.Left, game.Up, game.Right, game.Down}
 	minimalIntActions := make([]int, len(minimalActions))
 
 	for i, minimalAction := range minimalActions {
@@ -300,10 +488,91 @@ func (a *Asterix) MinimalActionSet() []int {
 	return minimalIntActions
 }
 
-// spawnEntity spawns an entity into the game
-func (a *Asterix) spawnEntity() {
-	lr := a.rng.Intn(2)
-	isGold := a.rng.Intn(3) == 0
+// ActionEffect returns a description of the effect of action act
+func (a *Asterix) ActionEffect(act game.Action) string {
+	effects := map[game.Action]string{
+		game.NoOp:  "do nothing",
+		game.Left:  "move the player left",
+		game.Up:    "move the player up",
+		game.Right: "move the player right",
+		game.Down:  "move the player down",
+	}
+	if effect, ok := effects[act]; ok {
+		return effect
+	}
+	return "no effect"
+}
+
+// collide resolves a collision between the player and the object with
+// the given ID, if they occupy the same position. Gold is collected,
+// while enemies end the game. The reward for the collision is
+// returned.
+func (a *Asterix) collide(id entity.ID, obj *object) float64 {
+	if obj.x() != a.agent.x() || obj.y() != a.agent.y() {
+		return 0
+	}
+	if a.log.On() {
+		a.log.Debugf("asterix: player collided with %v at (%v, %v)",
+			obj.kind(), obj.x(), obj.y())
+	}
+
+	if !obj.isGold() {
+		a.terminate(game.Collision)
+		return 0
+	}
+
+	a.entities.Remove(id)
+	value := a.goldValue(a.rampIndex)
+	a.collectGold(value)
+	return value
+}
+
+// goldValue returns the reward for picking up gold at the difficulty
+// level rampIndex
+func (a *Asterix) goldValue(rampIndex int) float64 {
+	if !a.config.TreasureRamping {
+		return 1
+	}
+	return 1 + float64(rampIndex)/4
+}
+
+// maxRampIndex returns an upper bound on the difficulty level which
+// can be reached in the current episode or in later episodes
+func (a *Asterix) maxRampIndex() int {
+	if !a.ramping {
+		return a.rampIndex
+	}
+
+	// Each ramp decreases the spawn interval, while the move interval
+	// is decreased on every second ramp
+	current := a.rampIndex + game.MaxInt(a.spawnSpeed, 2*a.moveSpeed)
+	later := game.MaxInt(a.initSpawnSpeed, 2*a.initMoveSpeed)
+	return game.MaxInt(current, later)
+}
+
+// collectGold records the reward event of the player collecting gold
+// worth value
+func (a *Asterix) collectGold(value float64) {
+	a.rewardEvents = append(a.rewardEvents, game.RewardEvent{
+		Type:   game.Collect,
+		Amount: value,
+		X:      a.agent.x(),
+		Y:      a.agent.y(),
+		Entity: "gold",
+	})
+}
+
+// spawnEntity spawns an entity into the game. If chosen, the entity
+// spawns at the position chosen by an adversary, and otherwise at a
+// random position.
+func (a *Asterix) spawnEntity(chosen bool) {
+	var lr int
+	if !chosen {
+		lr = a.rng.Intn("spawn side", 2)
+	} else if a.chosenSpawn.Right {
+		lr = 1
+	}
+	isGold := a.rng.Intn("gold", 3) == 0
 
 	var x int
 	if lr == 1 {
@@ -312,10 +581,17 @@ func (a *Asterix) spawnEntity() {
 		x = cols - 1
 	}
 
-	// Get the non-nil slots for entities
+	// Get the free slots for entities. Each slot is a row of the
+	// screen, and slot i is row i+1.
+	taken := make([]bool, maxEntities)
+	a.entities.Each(func(_ entity.ID, e entity.Entity) {
+		_, y := e.Position()
+		taken[y-1] = true
+	})
+
 	slotOptions := make([]int, 0, maxEntities)
-	for i, entity := range a.entities {
-		if entity == nil {
+	for i := range taken {
+		if !taken[i] {
 			slotOptions = append(slotOptions, i)
 		}
 	}
@@ -326,6 +602,77 @@ func (a *Asterix) spawnEntity() {
 	}
 
 	// Get a random slot at which to add an entity
-	slot := slotOptions[a.rng.Intn(len(slotOptions))]
-	a.entities[slot] = newEntity(x, slot+1, lr == 1, isGold)
+	var slot int
+	if chosen {
+		slot = a.chosenSpawn.Row - 1
+	} else {
+		slot = slotOptions[a.rng.Intn("spawn slot", len(slotOptions))]
+	}
+	obj, ok := a.entities.Recycled().(*object)
+	if !ok {
+		obj = new(object)
+	}
+	a.entities.Add(obj.init(x, slot+1, lr == 1, isGold))
+	if a.log.On() {
+		a.log.Debugf("asterix: spawned %v at (%v, %v)", obj.kind(), x,
+			slot+1)
+	}
+}
+
+// spawn spawns the entity chosen by the spawn scheduler, unless its
+// row is already taken
+func (a *Asterix) spawn(s game.Spawn) error {
+	if s.Kind != "enemy" && s.Kind != "gold" {
+		return fmt.Errorf("spawn: cannot spawn %q", s.Kind)
+	}
+	if s.Row < 1 || s.Row > maxEntities {
+		return fmt.Errorf("spawn: row %v ∉ [1, %v]", s.Row, maxEntities)
+	}
+
+	taken := a.entities.Any(func(e entity.Entity) bool {
+		_, y := e.Position()
+		return y == s.Row
+	})
+	if taken {
+		return nil
+	}
+
+	x := cols - 1
+	if s.Right {
+		x = 0
+	}
+	obj, ok := a.entities.Recycled().(*object)
+	if !ok {
+		obj = new(object)
+	}
+	a.entities.Add(obj.init(x, s.Row, s.Right, s.Kind == "gold"))
+	if a.log.On() {
+		a.log.Debugf("asterix: spawned %v at (%v, %v)", s.Kind, x, s.Row)
+	}
+	return nil
+}
+
+// SetLogger makes the game log its events to l, or discard them if l
+// is nil
+func (a *Asterix) SetLogger(l game.Logger) {
+	a.log.SetLogger(l)
+}
+
+// TerminationReason returns the reason the episode ended, or
+// game.NotTerminated if the episode has not ended
+func (a *Asterix) TerminationReason() game.TerminationReason {
+	return a.reason
+}
+
+// terminate ends the episode for the given reason. If the episode has
+// already ended on this step, the first reason is kept.
+func (a *Asterix) terminate(reason game.TerminationReason) {
+	if !a.terminal {
+		a.reason = reason
+		if a.log.On() {
+			a.log.Debugf("asterix: terminated after %v steps: %v",
+				a.steps, reason)
+		}
+	}
+	a.terminal = true
 }
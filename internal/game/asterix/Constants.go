@@ -0,0 +1,16 @@
+package asterix
+
+// Constants returns the constants of the game's dynamics, by the name
+// of the constant in MinAtar where it has one. Parameters changed with
+// SetParam are not reflected.
+func (a *Asterix) Constants() map[string]int {
+	return map[string]int{
+		"ramp_interval":      rampInterval,
+		"init_spawn_speed":   initSpawnSpeed,
+		"init_move_interval": initMoveInterval,
+		"shot_cool_down":     shotCoolDown,
+		"max_entities":       maxEntities,
+		"min_spawn_speed":    minSpawnSpeed,
+		"min_move_interval":  minMoveInterval,
+	}
+}
@@ -0,0 +1,64 @@
+package asterix
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/entity"
+)
+
+// Kinds of entity in a row, as given by the rowN_kind factors
+const (
+	noEntity = iota
+	enemyEntity
+	goldEntity
+)
+
+// Factors returns the position of the player, followed by the kind,
+// column, and direction of the entity in each of rows 1 to 8. Rows
+// without an entity have kind 0, column -1, and direction 0, and
+// enemies and gold have kinds 1 and 2.
+func (a *Asterix) Factors() []game.Factor {
+	kinds := make([]int, rows)
+	xs := make([]int, rows)
+	dirs := make([]int, rows)
+	for y := range xs {
+		xs[y] = -1
+	}
+
+	a.entities.Each(func(_ entity.ID, e entity.Entity) {
+		obj := e.(*object)
+		y := obj.y()
+		if kinds[y] != noEntity {
+			return
+		}
+
+		kinds[y] = enemyEntity
+		if obj.isGold() {
+			kinds[y] = goldEntity
+		}
+		xs[y] = obj.x()
+		dirs[y] = obj.direction()
+	})
+
+	factors := []game.Factor{
+		{Name: "player_x", Value: a.agent.x(), Reflection: game.Column},
+		{Name: "player_y", Value: a.agent.y()},
+	}
+	for y := 1; y < rows-1; y++ {
+		factors = append(factors,
+			game.Factor{Name: fmt.Sprintf("row%v_kind", y), Value: kinds[y]},
+			game.Factor{
+				Name:       fmt.Sprintf("row%v_x", y),
+				Value:      xs[y],
+				Reflection: game.Column,
+			},
+			game.Factor{
+				Name:       fmt.Sprintf("row%v_dx", y),
+				Value:      dirs[y],
+				Reflection: game.Horizontal,
+			},
+		)
+	}
+	return factors
+}
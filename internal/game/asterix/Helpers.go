@@ -71,56 +71,71 @@ func (p *player) moveDown() {
 	p.setY(game.MinInt(rows-2, p.y()+1))
 }
 
-// entity implements an entity in the Asterix game, which is either an
+// object implements an entity in the Asterix game, which is either an
 // enemy or a gold
-type entity struct {
+type object struct {
 	xPos          int
 	yPos          int
 	moveDirection int
 	gold          bool
 }
 
-// newentity returns a new entity
-func newEntity(x, y int, orientedRight, isGold bool) *entity {
+// init initializes the object, overwriting all its fields, and returns
+// the object. This allows objects removed from the game to be reused.
+func (e *object) init(x, y int, orientedRight, isGold bool) *object {
 	direction := -1
 	if orientedRight {
 		direction = 1
 	}
 
-	return &entity{
+	*e = object{
 		xPos:          x,
 		yPos:          y,
 		moveDirection: direction,
 		gold:          isGold,
 	}
+	return e
 }
 
-// move moves the entity in its movement direction
-func (e *entity) move() {
+// move moves the object in its movement direction
+func (e *object) move() {
 	e.xPos += e.moveDirection
 }
 
-// isGold returns whether the entity is gold or not
-func (e *entity) isGold() bool {
+// isGold returns whether the object is gold or not
+func (e *object) isGold() bool {
 	return e.gold
 }
 
-// direction returns the direction of movement of the entity
-func (e *entity) direction() int {
+// kind returns the kind of the object, "gold" or "enemy"
+func (e *object) kind() string {
+	if e.gold {
+		return "gold"
+	}
+	return "enemy"
+}
+
+// direction returns the direction of movement of the object
+func (e *object) direction() int {
 	return e.moveDirection
 }
 
-// orientedRight returns whether the entity is moving to the right
-func (e *entity) orientedRight() bool {
+// orientedRight returns whether the object is moving to the right
+func (e *object) orientedRight() bool {
 	return e.direction() == 1
 }
 
-// x returns the x position of the entity
-func (e *entity) x() int {
+// Position returns the x and y position of the object
+func (e *object) Position() (x, y int) {
+	return e.xPos, e.yPos
+}
+
+// x returns the x position of the object
+func (e *object) x() int {
 	return e.xPos
 }
 
-// y returns the y position of the entity
-func (e *entity) y() int {
+// y returns the y position of the object
+func (e *object) y() int {
 	return e.yPos
 }
@@ -0,0 +1,37 @@
+package asterix
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// channelDescriptions describes each channel of state observations
+var channelDescriptions = map[string]string{
+	"player": "position of the player",
+	"enemy":  "positions of enemies",
+	"trail": "previous positions of enemies and treasure, indicating " +
+		"their direction of movement",
+	"gold": "positions of treasure",
+}
+
+// Manifest returns a description of the game
+func (a *Asterix) Manifest() game.Manifest {
+	reward := "+1"
+	if a.config.TreasureRamping {
+		reward = "+1 + (difficulty level)/4"
+	}
+
+	return game.Manifest{
+		Description: "The player moves freely in the four cardinal " +
+			"directions, collecting treasure and avoiding enemies " +
+			"which spawn from the sides of the screen.",
+		Channels: game.ChannelInfos(a.channels, channelDescriptions),
+		Actions:  game.ActionInfos(a),
+		Rewards: []game.RewardInfo{
+			{Event: "treasure collected", Reward: reward},
+		},
+		Termination: []string{"the player touches an enemy"},
+		Ramping: "every 100 steps, enemies and treasure spawn more " +
+			"often and move faster",
+		Mirror: game.HorizontalMirror(),
+		ZOrder: []string{"trail", "gold", "enemy", "player"},
+		Trails: []string{"trail"},
+	}
+}
@@ -0,0 +1,35 @@
+package asterix
+
+import (
+	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/entity"
+)
+
+// Objects returns the player followed by each enemy and treasure, in
+// the order they spawned
+func (a *Asterix) Objects() []game.Object {
+	objects := []game.Object{
+		{Type: "player", X: a.agent.x(), Y: a.agent.y()},
+	}
+
+	a.entities.Each(func(_ entity.ID, e entity.Entity) {
+		obj := e.(*object)
+		typ := "enemy"
+		if obj.isGold() {
+			typ = "gold"
+		}
+
+		objects = append(objects, game.Object{
+			Type:        typ,
+			X:           obj.x(),
+			Y:           obj.y(),
+			Orientation: game.HorizontalOrientation(obj.direction()),
+		})
+	})
+	return objects
+}
+
+// PlayerPosition returns the column and row of the player
+func (a *Asterix) PlayerPosition() (x, y int) {
+	return a.agent.x(), a.agent.y()
+}
@@ -0,0 +1,63 @@
+package asterix
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// Params returns the names of the game's parameters
+func (a *Asterix) Params() []string {
+	return []string{game.RampingParam, game.SpawnIntervalParam,
+		game.MoveIntervalParam}
+}
+
+// Param returns the current value of the named parameter
+func (a *Asterix) Param(name string) (float64, bool) {
+	switch name {
+	case game.RampingParam:
+		return game.BoolParam(a.ramping), true
+
+	case game.SpawnIntervalParam:
+		return float64(a.spawnSpeed), true
+
+	case game.MoveIntervalParam:
+		return float64(a.moveSpeed), true
+
+	default:
+		return 0, false
+	}
+}
+
+// SetParam sets the value of the named parameter. Spawn and move
+// intervals must be at least 1 and are rounded to the nearest integer.
+func (a *Asterix) SetParam(name string, value float64) error {
+	a.cache.Invalidate()
+	switch name {
+	case game.RampingParam:
+		a.ramping = value != 0
+
+	case game.SpawnIntervalParam:
+		interval, ok := game.IntParam(value, 1)
+		if !ok {
+			return fmt.Errorf("setParam: %v must be at least 1 but got %v",
+				name, value)
+		}
+		a.initSpawnSpeed = interval
+		a.spawnSpeed = interval
+		a.spawnTimer = game.MinInt(a.spawnTimer, interval)
+
+	case game.MoveIntervalParam:
+		interval, ok := game.IntParam(value, 1)
+		if !ok {
+			return fmt.Errorf("setParam: %v must be at least 1 but got %v",
+				name, value)
+		}
+		a.initMoveSpeed = interval
+		a.moveSpeed = interval
+
+	default:
+		return fmt.Errorf("setParam: no such parameter %v", name)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package asterix
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// The methods in this file set the underlying state of the game by
+// hand, so that scripted scenarios can be constructed and stepped.
+
+// Empty removes all enemies and gold from the screen
+func (a *Asterix) Empty() {
+	a.cache.Invalidate()
+	a.entities.Clear()
+}
+
+// PlacePlayer moves the player to column x and row y
+func (a *Asterix) PlacePlayer(x, y int) error {
+	if x < 0 || x >= cols || y < 1 || y > rows-2 {
+		return fmt.Errorf("placePlayer: position (%v, %v) out of bounds",
+			x, y)
+	}
+	a.cache.Invalidate()
+	a.agent.setX(x)
+	a.agent.setY(y)
+	return nil
+}
+
+// SetTimers sets the number of steps until the player and entities
+// next move, and until the next entity spawns
+func (a *Asterix) SetTimers(move, spawn int) error {
+	if move < 0 || spawn < 0 {
+		return fmt.Errorf("setTimers: timers must be non-negative but got "+
+			"%v and %v", move, spawn)
+	}
+	a.agent.setMoveTimer(move)
+	a.spawnTimer = spawn
+	return nil
+}
+
+// PlaceEnemy places an enemy at column x and row y, moving left or
+// right
+func (a *Asterix) PlaceEnemy(x, y int, o game.Orientation) error {
+	if err := a.place(x, y, o, false); err != nil {
+		return fmt.Errorf("placeEnemy: %v", err)
+	}
+	return nil
+}
+
+// PlaceGold places gold at column x and row y, moving left or right
+func (a *Asterix) PlaceGold(x, y int, o game.Orientation) error {
+	if err := a.place(x, y, o, true); err != nil {
+		return fmt.Errorf("placeGold: %v", err)
+	}
+	return nil
+}
+
+// place adds an enemy or gold at column x and row y, moving in the
+// horizontal direction o
+func (a *Asterix) place(x, y int, o game.Orientation, isGold bool) error {
+	if o != game.FacingLeft && o != game.FacingRight {
+		return fmt.Errorf("cannot move %q", o)
+	}
+	if x < 0 || x >= cols || y < 1 || y > maxEntities {
+		return fmt.Errorf("position (%v, %v) out of bounds", x, y)
+	}
+
+	a.cache.Invalidate()
+	a.entities.Add(new(object).init(x, y, o == game.FacingRight, isGold))
+	return nil
+}
+
+// RemoveAt removes all enemies and gold at column x and row y, and
+// returns the number of objects removed
+func (a *Asterix) RemoveAt(x, y int) int {
+	a.cache.Invalidate()
+	return a.entities.RemoveAt(x, y)
+}
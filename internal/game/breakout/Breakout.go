@@ -13,10 +13,9 @@ package breakout
 
 import (
 	"fmt"
-	"math/rand"
 
 	"github.com/samuelfneumann/goatar/internal/game"
-	"gonum.org/v1/gonum/mat"
+	"github.com/samuelfneumann/goatar/internal/grid"
 )
 
 const (
@@ -24,6 +23,10 @@ const (
 	cols int = rows
 )
 
+// bounceReward is the reward for the ball bouncing off the paddle, if
+// bounces are rewarded
+const bounceReward float64 = 0.1
+
 // Breakout implements the Breakout game. In this game, the player must
 // destroy all bricks at the top of the screen by bouncing a ball off
 // a paddle.
@@ -48,37 +51,102 @@ const (
 // while values of 1 indicate that brick exists at that position.
 type Breakout struct {
 	channels  map[string]int
-	actionMap []rune
-	rng       *rand.Rand
+	actionMap []game.Action
+	rng       *game.Random
+	config    Config
 
 	ballY     int
 	ballStart int
 	ballX     int
 	ballDir   int
 	position  int
-	brickMap  *mat.Dense
+	brickMap  *grid.Grid
 	strike    bool
 	lastX     int
 	lastY     int
 
 	terminal bool
+	reason   game.TerminationReason // Why the episode ended, if it has
+
+	rewardEvents []game.RewardEvent // Reward events of the last step
+
+	cache game.StateCache // Cached state observation
+	log   game.Log        // Logs the events of the game
+}
+
+// Versions describes the changes in behaviour of each version of the
+// game, where element i describes version i+1
+var Versions = []string{
+	"the original port of MinAtar, in which moving right moves the " +
+		"paddle to the right edge of the screen",
+	"moving right moves the paddle one cell to the right, as in MinAtar; " +
+		"random numbers are drawn with xoshiro256** rather than " +
+		"math/rand, and sticky actions restart with each episode",
+}
+
+// LatestVersion is the latest version of the game
+const LatestVersion = 2
+
+// Config configures a Breakout game. The zero value is the default
+// configuration.
+type Config struct {
+	// Version is the version of the game's behaviour, see Versions. If
+	// 0, the latest version is used.
+	Version int
+
+	// BounceReward rewards the player each time the ball bounces off
+	// the paddle, in addition to the reward for breaking bricks
+	BounceReward bool
+
+	// Juggling rewards the player each time the ball bounces off the
+	// paddle, and removes the reward for breaking bricks
+	Juggling bool
+}
+
+// withDefaults returns the configuration with zero values replaced by
+// their defaults
+func (c Config) withDefaults() Config {
+	if c.Version == 0 {
+		c.Version = LatestVersion
+	}
+	return c
+}
+
+// validate returns an error if the configuration is invalid
+func (c Config) validate() error {
+	if c.Version < 0 || c.Version > LatestVersion {
+		return fmt.Errorf("version %v ∉ [1, %v]", c.Version, LatestVersion)
+	}
+	return nil
 }
 
 // New returns a new Breakout game
-func New(_ bool, seed int64) (game.Game, error) {
+func New(ramping bool, seed int64) (game.Game, error) {
+	return NewWithConfig(ramping, seed, Config{})
+}
+
+// NewWithConfig returns a new Breakout game with the given
+// configuration
+func NewWithConfig(_ bool, seed int64, config Config) (game.Game, error) {
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("newWithConfig: %v", err)
+	}
+
 	channels := map[string]int{
 		"paddle": 0,
 		"ball":   1,
 		"trail":  2,
 		"brick":  3,
 	}
-	actionMap := []rune{'n', 'l', 'u', 'r', 'd', 'f'}
-	rng := rand.New(rand.NewSource(seed))
+	actionMap := []game.Action{game.NoOp, game.Left, game.Up, game.Right,
+		game.Down, game.Fire}
+	rng := game.NewVersionedRandom(seed, config.withDefaults().Version)
 
 	breakout := &Breakout{
 		channels:  channels,
 		actionMap: actionMap,
 		rng:       rng,
+		config:    config.withDefaults(),
 	}
 	breakout.Reset()
 
@@ -94,6 +162,8 @@ func (b *Breakout) Act(a int) (float64, bool, error) {
 			a, len(b.actionMap))
 	}
 
+	b.cache.Invalidate()
+	b.rewardEvents = b.rewardEvents[:0]
 	reward := 0.0
 	if b.terminal {
 		return reward, b.terminal, nil
@@ -102,10 +172,14 @@ func (b *Breakout) Act(a int) (float64, bool, error) {
 	// Resolve player action
 	action := b.actionMap[a]
 	switch action {
-	case 'l':
+	case game.Left:
 		b.position = game.MaxInt(0, b.position-1)
-	case 'r':
-		b.position = game.MaxInt(rows-1, b.position+1)
+	case game.Right:
+		if b.config.Version == 1 {
+			b.position = game.MaxInt(rows-1, b.position+1)
+		} else {
+			b.position = game.MinInt(cols-1, b.position+1)
+		}
 	}
 
 	// Update ball position
@@ -145,7 +219,7 @@ func (b *Breakout) Act(a int) (float64, bool, error) {
 	} else if b.brickMap.At(newY, newX) == 1.0 {
 		strikeToggle = true
 		if !b.strike {
-			reward++
+			reward += b.breakBrick(newX, newY)
 			b.strike = true
 			b.brickMap.Set(newY, newX, 0.0)
 			newY = b.lastY
@@ -165,11 +239,13 @@ func (b *Breakout) Act(a int) (float64, bool, error) {
 		if b.ballX == b.position {
 			b.ballDir = [4]int{3, 2, 1, 0}[b.ballDir]
 			newY = b.lastY
+			reward += b.bounce()
 		} else if newX == b.position {
 			b.ballDir = [4]int{2, 3, 0, 1}[b.ballDir]
 			newY = b.lastY
+			reward += b.bounce()
 		} else {
-			b.terminal = true
+			b.terminate(game.BallMissed)
 		}
 	}
 
@@ -184,39 +260,50 @@ func (b *Breakout) Act(a int) (float64, bool, error) {
 
 // State returns the current state observation
 func (b *Breakout) State() ([]float64, error) {
-	state := make([]float64, rows*cols*b.NChannels())
+	return b.cache.State(rows*cols*b.NChannels(), b.observe)
+}
 
+// observe fills the zeroed slice state with the state observation
+func (b *Breakout) observe(state []float64) error {
 	state[rows*cols*b.channels["ball"]+cols*b.ballY+b.ballX] = 1.0
 
 	state[rows*cols*b.channels["paddle"]+(rows-1)*cols+b.position] = 1.0
 	state[rows*cols*b.channels["trail"]+b.lastY*cols+b.lastX] = 1.0
-	copy(state[rows*cols*b.channels["brick"]:], b.brickMap.RawMatrix().Data)
+	copy(state[rows*cols*b.channels["brick"]:], b.brickMap.Data())
 
-	return state, nil
+	return nil
 }
 
 // Reset resets the environment to some starting state
 func (b *Breakout) Reset() {
+	b.cache.Invalidate()
+	b.rewardEvents = b.rewardEvents[:0]
 	b.ballY = 3
-	b.ballStart = b.rng.Intn(2)
+	b.ballStart = b.rng.Intn("ball start", 2)
 	b.ballX = [2]int{0, 9}[b.ballStart]
 	b.ballDir = [2]int{2, 3}[b.ballStart]
 	b.position = 4
-	b.brickMap = mat.NewDense(rows, cols, nil)
+	if b.log.On() {
+		b.log.Debugf("breakout: spawned ball at (%v, %v)", b.ballX, b.ballY)
+	}
+	if b.brickMap == nil {
+		b.brickMap = grid.New(rows, cols, nil)
+	} else {
+		b.brickMap.Zero()
+	}
 
 	// Set the bricks
-	bricks := make([]float64, cols)
-	for i := range bricks {
-		bricks[i] = 1.0
-	}
 	for i := 0; i < 4*rows/10; i++ {
-		b.brickMap.SetRow(i, bricks)
+		for j := 0; j < cols; j++ {
+			b.brickMap.Set(i, j, 1.0)
+		}
 	}
 
 	b.strike = false
 	b.lastX = b.ballX
 	b.lastY = b.ballY
 	b.terminal = false
+	b.reason = game.NotTerminated
 }
 
 // NChannels returns the number of channels in the state observation
@@ -231,9 +318,112 @@ func (b *Breakout) DifficultyRamp() int {
 	return 0
 }
 
-// StateShape returns the shape of state observations
-func (b *Breakout) StateShape() []int {
-	return []int{b.NChannels(), rows, cols}
+// RewardRange returns the minimum and maximum reward that can be
+// received on a single step. At most one brick can be broken each
+// step, and the ball cannot break a brick and bounce off the paddle on
+// the same step.
+func (b *Breakout) RewardRange() (min, max float64) {
+	if b.config.Juggling {
+		return 0, bounceReward
+	}
+	return 0, 1
+}
+
+// breakBrick records the reward event of the ball breaking the brick
+// at position (x, y), and returns the reward for breaking it
+func (b *Breakout) breakBrick(x, y int) float64 {
+	if b.log.On() {
+		b.log.Debugf("breakout: ball collided with brick at (%v, %v)", x, y)
+	}
+	if b.config.Juggling {
+		return 0
+	}
+
+	b.rewardEvents = append(b.rewardEvents, game.RewardEvent{
+		Type:   game.Destroy,
+		Amount: 1,
+		X:      x,
+		Y:      y,
+		Entity: "brick",
+	})
+	return 1
+}
+
+// bounce records the reward event of the ball bouncing off the paddle,
+// and returns the reward for the bounce
+func (b *Breakout) bounce() float64 {
+	if b.log.On() {
+		b.log.Debugf("breakout: ball collided with paddle at (%v, %v)",
+			b.position, rows-1)
+	}
+	if !b.config.BounceReward && !b.config.Juggling {
+		return 0
+	}
+
+	b.rewardEvents = append(b.rewardEvents, game.RewardEvent{
+		Type:   game.Bonus,
+		Amount: bounceReward,
+		X:      b.position,
+		Y:      rows - 1,
+		Entity: "paddle",
+	})
+	return bounceReward
+}
+
+// Seed seeds the random number generator of the game. The game is not
+// reset.
+func (b *Breakout) Seed(seed int64) {
+	b.rng.Seed(seed)
+}
+
+// Clone returns a deep copy of the game. The copy behaves identically
+// to the original given the same actions, but does not share any state
+// with it.
+func (b *Breakout) Clone() game.Game {
+	clone := *b
+	clone.rng = b.rng.Clone()
+	clone.brickMap = b.brickMap.Clone()
+	clone.cache = game.StateCache{}
+	clone.rewardEvents = append([]game.RewardEvent(nil),
+		b.rewardEvents...)
+	return &clone
+}
+
+// SetStochasticity sets the fraction of random draws made by the game
+// which are random rather than deterministic
+func (b *Breakout) SetStochasticity(level float64) {
+	b.rng.SetStochasticity(level)
+}
+
+// StateHash returns a hash of the underlying game state, including
+// all timers. Two games with the same hash will, with high
+// probability, behave identically given the same actions and random
+// numbers.
+func (b *Breakout) StateHash() uint64 {
+	h := game.NewHasher()
+	h.Int(b.ballX, b.ballY, b.ballDir, b.position, b.lastX, b.lastY)
+	h.Bool(b.strike)
+	h.Bool(b.terminal)
+	h.Float(b.brickMap.Data()...)
+
+	return h.Sum()
+}
+
+// RNGHash returns a hash of the state of the game's random number
+// generator, which is not included in StateHash
+func (b *Breakout) RNGHash() uint64 {
+	return b.rng.Hash()
+}
+
+// RewardEvents returns the events which resulted in rewards on the
+// last step. The returned slice is only valid until the next step.
+func (b *Breakout) RewardEvents() []game.RewardEvent {
+	return b.rewardEvents
+}
+
+// Shape returns the shape of state observations
+func (b *Breakout) Shape() game.Shape {
+	return game.Shape{Channels: b.NChannels(), Rows: rows, Cols: cols}
 }
 
 // Channel returns the state observation channel at index i
@@ -257,7 +447,7 @@ func (b *Breakout) Channel(i int) ([]float64, error) {
 // MinimalActionSet returns the actions which actually have an effect
 // on the environment.
 func (b *Breakout) MinimalActionSet() []int {
-	minimalActions := []rune{'n', 'l', 'r'}
+	minimalActions := []game.Action{game.NoOp, game.Left, game.Right}
 	minimalIntActions := make([]int, len(minimalActions))
 
 	for i, minimalAction := range minimalActions {
@@ -269,3 +459,40 @@ func (b *Breakout) MinimalActionSet() []int {
 	}
 	return minimalIntActions
 }
+
+// ActionEffect returns a description of the effect of action a
+func (b *Breakout) ActionEffect(a game.Action) string {
+	effects := map[game.Action]string{
+		game.NoOp:  "do nothing",
+		game.Left:  "move the paddle left",
+		game.Right: "move the paddle right",
+	}
+	if effect, ok := effects[a]; ok {
+		return effect
+	}
+	return "no effect"
+}
+
+// SetLogger makes the game log its events to l, or discard them if l
+// is nil
+func (b *Breakout) SetLogger(l game.Logger) {
+	b.log.SetLogger(l)
+}
+
+// TerminationReason returns the reason the episode ended, or
+// game.NotTerminated if the episode has not ended
+func (b *Breakout) TerminationReason() game.TerminationReason {
+	return b.reason
+}
+
+// terminate ends the episode for the given reason. If the episode has
+// already ended on this step, the first reason is kept.
+func (b *Breakout) terminate(reason game.TerminationReason) {
+	if !b.terminal {
+		b.reason = reason
+		if b.log.On() {
+			b.log.Debugf("breakout: terminated: %v", reason)
+		}
+	}
+	b.terminal = true
+}
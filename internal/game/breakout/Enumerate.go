@@ -0,0 +1,91 @@
+package breakout
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// brickRows is the number of rows of the screen in which bricks can
+// exist
+const brickRows int = 4 * rows / 10
+
+// encode encodes the underlying state of the game with e
+func (b *Breakout) encode(e *game.Encoder) error {
+	for _, v := range []int{b.ballX, b.ballY, b.lastX, b.lastY, b.position} {
+		if err := e.Put(v, cols); err != nil {
+			return err
+		}
+	}
+	if err := e.Put(b.ballDir, 4); err != nil {
+		return err
+	}
+	e.PutBool(b.strike)
+	e.PutBool(b.terminal)
+
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			brick := b.brickMap.At(r, c) != 0
+			if r >= brickRows {
+				if brick {
+					return fmt.Errorf("brick at row %v", r)
+				}
+				continue
+			}
+			e.PutBool(brick)
+		}
+	}
+	return nil
+}
+
+// Encode returns the integer encoding of the current state. Every
+// encoding fits in a uint64.
+func (b *Breakout) Encode() (*big.Int, error) {
+	e := game.NewEncoder()
+	if err := b.encode(e); err != nil {
+		return nil, fmt.Errorf("encode: %v", err)
+	}
+	return e.Code(), nil
+}
+
+// Decode sets the current state to the state encoded by code
+func (b *Breakout) Decode(code *big.Int) error {
+	b.cache.Invalidate()
+	d := game.NewDecoder(code)
+
+	ballX, ballY := d.Take(cols), d.Take(cols)
+	lastX, lastY := d.Take(cols), d.Take(cols)
+	position := d.Take(cols)
+	ballDir := d.Take(4)
+	strike := d.TakeBool()
+	terminal := d.TakeBool()
+
+	bricks := make([]float64, rows*cols)
+	for i := 0; i < brickRows*cols; i++ {
+		if d.TakeBool() {
+			bricks[i] = 1.0
+		}
+	}
+
+	if err := d.Done(); err != nil {
+		return fmt.Errorf("decode: %v", err)
+	}
+
+	b.ballX, b.ballY = ballX, ballY
+	b.lastX, b.lastY = lastX, lastY
+	b.position = position
+	b.ballDir = ballDir
+	b.strike = strike
+	b.terminal = terminal
+	copy(b.brickMap.Data(), bricks)
+
+	return nil
+}
+
+// NumStates returns the number of encodable states
+func (b *Breakout) NumStates() *big.Int {
+	n := new(big.Int).Exp(big.NewInt(int64(cols)), big.NewInt(5), nil)
+	n.Mul(n, big.NewInt(4*2*2))
+	return n.Lsh(n, uint(brickRows*cols))
+}
@@ -0,0 +1,33 @@
+package breakout
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// Factors returns the column of the paddle, the position and direction
+// of the ball, and the number of remaining bricks
+func (b *Breakout) Factors() []game.Factor {
+	dx, dy := 1, 1
+	if b.ballDir == 0 || b.ballDir == 3 {
+		dx = -1
+	}
+	if b.ballDir == 0 || b.ballDir == 1 {
+		dy = -1
+	}
+
+	bricks := 0
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			if b.brickMap.At(y, x) != 0 {
+				bricks++
+			}
+		}
+	}
+
+	return []game.Factor{
+		{Name: "paddle_x", Value: b.position, Reflection: game.Column},
+		{Name: "ball_x", Value: b.ballX, Reflection: game.Column},
+		{Name: "ball_y", Value: b.ballY},
+		{Name: "ball_dx", Value: dx, Reflection: game.Horizontal},
+		{Name: "ball_dy", Value: dy},
+		{Name: "bricks", Value: bricks},
+	}
+}
@@ -0,0 +1,44 @@
+package breakout
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// channelDescriptions describes each channel of state observations
+var channelDescriptions = map[string]string{
+	"paddle": "position of the paddle",
+	"ball":   "position of the ball",
+	"trail": "previous position of the ball, indicating its direction " +
+		"of movement",
+	"brick": "positions of the remaining bricks",
+}
+
+// Manifest returns a description of the game
+func (b *Breakout) Manifest() game.Manifest {
+	var rewards []game.RewardInfo
+	if !b.config.Juggling {
+		rewards = append(rewards, game.RewardInfo{
+			Event:  "brick broken",
+			Reward: "+1",
+		})
+	}
+	if b.config.BounceReward || b.config.Juggling {
+		rewards = append(rewards, game.RewardInfo{
+			Event:  "ball bounced off the paddle",
+			Reward: "+0.1",
+		})
+	}
+
+	return game.Manifest{
+		Description: "The player moves a paddle along the bottom of the " +
+			"screen, bouncing a ball diagonally to break rows of bricks " +
+			"along the top of the screen. When all bricks are broken, " +
+			"the bricks are replaced.",
+		Channels:    game.ChannelInfos(b.channels, channelDescriptions),
+		Actions:     game.ActionInfos(b),
+		Rewards:     rewards,
+		Termination: []string{"the ball reaches the bottom of the screen"},
+		Ramping:     "none",
+		Mirror:      game.HorizontalMirror().OrientedBy("ball_dx"),
+		ZOrder:      []string{"brick", "trail", "ball", "paddle"},
+		Trails:      []string{"trail"},
+	}
+}
@@ -0,0 +1,37 @@
+package breakout
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// ballOrientations is the orientation of the ball in each direction
+var ballOrientations = [4]game.Orientation{
+	game.FacingUpLeft,
+	game.FacingUpRight,
+	game.FacingDownRight,
+	game.FacingDownLeft,
+}
+
+// Objects returns the paddle, the ball, and each remaining brick
+func (b *Breakout) Objects() []game.Object {
+	objects := []game.Object{
+		{Type: "paddle", X: b.position, Y: rows - 1},
+		{
+			Type:        "ball",
+			X:           b.ballX,
+			Y:           b.ballY,
+			Orientation: ballOrientations[b.ballDir],
+		},
+	}
+
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			if b.brickMap.At(y, x) != 0 {
+				objects = append(objects, game.Object{
+					Type: "brick",
+					X:    x,
+					Y:    y,
+				})
+			}
+		}
+	}
+	return objects
+}
@@ -0,0 +1,78 @@
+package breakout
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// The methods in this file set the underlying state of the game by
+// hand, so that scripted scenarios can be constructed and stepped.
+
+// Empty removes all bricks from the screen
+func (b *Breakout) Empty() {
+	b.cache.Invalidate()
+	b.brickMap.Zero()
+}
+
+// PlacePaddle moves the paddle to column x
+func (b *Breakout) PlacePaddle(x int) error {
+	if x < 0 || x >= cols {
+		return fmt.Errorf("placePaddle: column %v ∉ [0, %v)", x, cols)
+	}
+	b.cache.Invalidate()
+	b.position = x
+	return nil
+}
+
+// PlaceBall moves the ball to column x and row y, travelling in
+// the diagonal direction o. The trail is placed in the cell the ball
+// would have come from, or under the ball if that cell is off the
+// screen.
+func (b *Breakout) PlaceBall(x, y int, o game.Orientation) error {
+	if x < 0 || x >= cols || y < 0 || y >= rows {
+		return fmt.Errorf("placeBall: position (%v, %v) out of bounds", x, y)
+	}
+	dir := -1
+	for i, orientation := range ballOrientations {
+		if orientation == o {
+			dir = i
+		}
+	}
+	if dir < 0 {
+		return fmt.Errorf("placeBall: ball cannot travel %q", o)
+	}
+
+	b.cache.Invalidate()
+	b.ballX, b.ballY, b.ballDir = x, y, dir
+	b.strike = false
+
+	dx, dy := [4]int{-1, 1, 1, -1}[dir], [4]int{-1, -1, 1, 1}[dir]
+	b.lastX, b.lastY = x-dx, y-dy
+	if b.lastX < 0 || b.lastX >= cols || b.lastY < 0 || b.lastY >= rows {
+		b.lastX, b.lastY = x, y
+	}
+	return nil
+}
+
+// PlaceBrick places a brick at column x and row y
+func (b *Breakout) PlaceBrick(x, y int) error {
+	if x < 0 || x >= cols || y < 0 || y >= rows {
+		return fmt.Errorf("placeBrick: position (%v, %v) out of bounds", x, y)
+	}
+	b.cache.Invalidate()
+	b.brickMap.Set(y, x, 1.0)
+	return nil
+}
+
+// RemoveAt removes the brick at column x and row y, and returns the
+// number of bricks removed
+func (b *Breakout) RemoveAt(x, y int) int {
+	if x < 0 || x >= cols || y < 0 || y >= rows ||
+		b.brickMap.At(y, x) == 0 {
+		return 0
+	}
+	b.cache.Invalidate()
+	b.brickMap.Set(y, x, 0)
+	return 1
+}
@@ -0,0 +1,248 @@
+// Package entity implements a manager for the entities of a game, such
+// as enemies, treasure, and bullets, which spawn, move, and despawn.
+//
+// Each entity added to a Manager is given a stable ID which is never
+// reused. Entities may be added and removed while iterating over a
+// Manager. Removal during iteration is deferred until the outermost
+// iteration finishes, so that removing an entity never invalidates
+// the iteration or shifts the entities which have not yet been
+// visited. Removed entities are never visited again and are ignored by
+// all queries.
+//
+// Once no iteration can visit a removed entity, it is kept by the
+// Manager so that its memory can be reused by a new entity of the same
+// kind, see Recycled. Games which reuse removed entities allocate
+// nothing while stepping once their entities reach a steady state.
+package entity
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// Entity is an object in a game which occupies a single cell
+type Entity = game.Positioned
+
+// ID uniquely identifies an entity within a Manager
+type ID int
+
+// item is an entity held by a Manager
+type item struct {
+	id      ID
+	entity  Entity
+	removed bool
+}
+
+// Manager holds the entities of a game in the order in which they were
+// added
+type Manager struct {
+	items     []item
+	nextID    ID
+	live      int
+	iterating int // Depth of nested iterations
+
+	recycled []Entity // Removed entities which may be reused
+}
+
+// NewManager returns a new Manager with no entities
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Add adds an entity to the Manager and returns its ID. Entities added
+// while iterating are not visited by the iterations in progress.
+func (m *Manager) Add(e Entity) ID {
+	id := m.nextID
+	m.nextID++
+	m.items = append(m.items, item{id: id, entity: e})
+	m.live++
+	return id
+}
+
+// Remove removes the entity with the given ID. Removing an entity
+// which does not exist or which was already removed has no effect.
+func (m *Manager) Remove(id ID) {
+	i := m.index(id)
+	if i < 0 || m.items[i].removed {
+		return
+	}
+
+	m.items[i].removed = true
+	m.live--
+	if m.iterating == 0 {
+		m.flush()
+	}
+}
+
+// Get returns the entity with the given ID, and whether it exists
+func (m *Manager) Get(id ID) (Entity, bool) {
+	i := m.index(id)
+	if i < 0 || m.items[i].removed {
+		return nil, false
+	}
+	return m.items[i].entity, true
+}
+
+// Len returns the number of entities in the Manager
+func (m *Manager) Len() int {
+	return m.live
+}
+
+// Clear removes all entities from the Manager. IDs are not reused
+// after clearing.
+func (m *Manager) Clear() {
+	for i := range m.items {
+		m.items[i].removed = true
+	}
+	m.live = 0
+	if m.iterating == 0 {
+		m.flush()
+	}
+}
+
+// Recycled returns an entity which was removed from the Manager and
+// can no longer be visited by any iteration, or nil if there is no
+// such entity. The Manager forgets the returned entity, so the caller
+// may overwrite it and add it to the Manager as a new entity, avoiding
+// an allocation.
+func (m *Manager) Recycled() Entity {
+	n := len(m.recycled)
+	if n == 0 {
+		return nil
+	}
+
+	e := m.recycled[n-1]
+	m.recycled[n-1] = nil
+	m.recycled = m.recycled[:n-1]
+	return e
+}
+
+// Each calls f with each entity, from the oldest to the newest
+func (m *Manager) Each(f func(id ID, e Entity)) {
+	m.iterating++
+	defer m.done()
+
+	n := len(m.items)
+	for i := 0; i < n; i++ {
+		if !m.items[i].removed {
+			f(m.items[i].id, m.items[i].entity)
+		}
+	}
+}
+
+// EachReverse calls f with each entity, from the newest to the oldest
+func (m *Manager) EachReverse(f func(id ID, e Entity)) {
+	m.iterating++
+	defer m.done()
+
+	for i := len(m.items) - 1; i >= 0; i-- {
+		if !m.items[i].removed {
+			f(m.items[i].id, m.items[i].entity)
+		}
+	}
+}
+
+// At returns the oldest entity at column x and row y, and whether such
+// an entity exists
+func (m *Manager) At(x, y int) (ID, Entity, bool) {
+	for _, it := range m.items {
+		if it.removed {
+			continue
+		}
+		if ex, ey := it.entity.Position(); ex == x && ey == y {
+			return it.id, it.entity, true
+		}
+	}
+	return -1, nil, false
+}
+
+// RemoveAt removes every entity at column x and row y, and returns the
+// number of entities removed
+func (m *Manager) RemoveAt(x, y int) int {
+	n := 0
+	for {
+		id, _, ok := m.At(x, y)
+		if !ok {
+			return n
+		}
+		m.Remove(id)
+		n++
+	}
+}
+
+// Any returns whether any entity satisfies pred
+func (m *Manager) Any(pred func(e Entity) bool) bool {
+	for _, it := range m.items {
+		if !it.removed && pred(it.entity) {
+			return true
+		}
+	}
+	return false
+}
+
+// Clone returns a copy of the Manager, where each entity is copied
+// with copyEntity. IDs are preserved.
+func (m *Manager) Clone(copyEntity func(e Entity) Entity) *Manager {
+	clone := &Manager{
+		items:  make([]item, 0, cap(m.items)),
+		nextID: m.nextID,
+		live:   m.live,
+	}
+	for _, it := range m.items {
+		if !it.removed {
+			clone.items = append(clone.items, item{
+				id:     it.id,
+				entity: copyEntity(it.entity),
+			})
+		}
+	}
+	return clone
+}
+
+// done finishes an iteration, removing deferred entities once the
+// outermost iteration finishes
+func (m *Manager) done() {
+	m.iterating--
+	if m.iterating == 0 {
+		m.flush()
+	}
+}
+
+// flush removes all entities marked for removal, preserving the order
+// of the remaining entities, and keeps the removed entities so that
+// they can be recycled
+func (m *Manager) flush() {
+	if len(m.items) == m.live {
+		return
+	}
+
+	n := 0
+	for _, it := range m.items {
+		if !it.removed {
+			m.items[n] = it
+			n++
+		} else {
+			m.recycled = append(m.recycled, it.entity)
+		}
+	}
+	for i := n; i < len(m.items); i++ {
+		m.items[i] = item{}
+	}
+	m.items = m.items[:n]
+}
+
+// index returns the index of the entity with the given ID in m.items,
+// or -1 if no such entity exists. Since entities are appended in order
+// of increasing ID, the index is found with a binary search.
+func (m *Manager) index(id ID) int {
+	lo, hi := 0, len(m.items)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if m.items[mid].id < id {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo < len(m.items) && m.items[lo].id == id {
+		return lo
+	}
+	return -1
+}
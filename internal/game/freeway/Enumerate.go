@@ -0,0 +1,110 @@
+package freeway
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+const (
+	maxSpeed int = 4 // Maximum number of frames between car movements
+
+	// Bases of the encoding of each variable in the underlying state
+	xBase         int = observationCols
+	carTimerBase  int = maxSpeed + 1
+	speedBase     int = 2*maxSpeed + 1
+	positionBase  int = observationRows
+	moveTimerBase int = int(playerSpeed) + 1
+	timerBase     int = timeLimit + 2
+)
+
+// Encode returns the integer encoding of the current state
+func (f *Freeway) Encode() (*big.Int, error) {
+	e := game.NewEncoder()
+
+	for i := 0; i < rows; i++ {
+		if err := e.Put(int(f.cars.At(i, 0)), xBase); err != nil {
+			return nil, fmt.Errorf("encode: car %v x position: %v", i, err)
+		}
+		if err := e.Put(int(f.cars.At(i, 2)), carTimerBase); err != nil {
+			return nil, fmt.Errorf("encode: car %v timer: %v", i, err)
+		}
+		if err := e.Put(int(f.cars.At(i, 3))+maxSpeed, speedBase); err != nil {
+			return nil, fmt.Errorf("encode: car %v speed: %v", i, err)
+		}
+	}
+
+	if err := e.Put(f.position, positionBase); err != nil {
+		return nil, fmt.Errorf("encode: position: %v", err)
+	}
+	if err := e.Put(int(f.moveTimer), moveTimerBase); err != nil {
+		return nil, fmt.Errorf("encode: move timer: %v", err)
+	}
+	if f.config.SecondChicken {
+		if err := e.Put(f.partner, positionBase); err != nil {
+			return nil, fmt.Errorf("encode: second position: %v", err)
+		}
+		if err := e.Put(int(f.partnerTimer), moveTimerBase); err != nil {
+			return nil, fmt.Errorf("encode: second move timer: %v", err)
+		}
+	}
+	if err := e.Put(f.terminateTimer+1, timerBase); err != nil {
+		return nil, fmt.Errorf("encode: terminate timer: %v", err)
+	}
+	e.PutBool(f.terminal)
+
+	return e.Code(), nil
+}
+
+// Decode sets the current state to the state encoded by code
+func (f *Freeway) Decode(code *big.Int) error {
+	f.cache.Invalidate()
+	d := game.NewDecoder(code)
+
+	cars := make([]float64, rows*cols)
+	for i := 0; i < rows; i++ {
+		cars[cols*i] = float64(d.Take(xBase))
+		cars[cols*i+1] = float64(i + 1)
+		cars[cols*i+2] = float64(d.Take(carTimerBase))
+		cars[cols*i+3] = float64(d.Take(speedBase) - maxSpeed)
+
+		if cars[cols*i+3] == 0 {
+			return fmt.Errorf("decode: car %v has no speed", i)
+		}
+	}
+
+	position := d.Take(positionBase)
+	moveTimer := float64(d.Take(moveTimerBase))
+	partner, partnerTimer := 9, playerSpeed
+	if f.config.SecondChicken {
+		partner = d.Take(positionBase)
+		partnerTimer = float64(d.Take(moveTimerBase))
+	}
+	terminateTimer := d.Take(timerBase) - 1
+	terminal := d.TakeBool()
+
+	if err := d.Done(); err != nil {
+		return fmt.Errorf("decode: %v", err)
+	}
+
+	copy(f.cars.Data(), cars)
+	f.position = position
+	f.moveTimer = moveTimer
+	f.partner = partner
+	f.partnerTimer = partnerTimer
+	f.terminateTimer = terminateTimer
+	f.terminal = terminal
+
+	return nil
+}
+
+// NumStates returns the number of encodable states
+func (f *Freeway) NumStates() *big.Int {
+	perCar := big.NewInt(int64(xBase * carTimerBase * speedBase))
+	n := new(big.Int).Exp(perCar, big.NewInt(int64(rows)), nil)
+	if f.config.SecondChicken {
+		n.Mul(n, big.NewInt(int64(positionBase*moveTimerBase)))
+	}
+	return n.Mul(n, big.NewInt(int64(positionBase*moveTimerBase*timerBase*2)))
+}
@@ -0,0 +1,43 @@
+package freeway
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// Factors returns the row of the chicken, and of the second chicken if
+// there is one, followed by the column and velocity of the car in each
+// lane with a car, from the top lane to the bottom lane. The velocity of a car is positive if it travels right and
+// negative if it travels left, and its magnitude is the car's speed.
+func (f *Freeway) Factors() []game.Factor {
+	factors := []game.Factor{{Name: "chicken_y", Value: f.position}}
+	if f.config.SecondChicken {
+		factors = append(factors, game.Factor{
+			Name:  "chicken2_y",
+			Value: f.partner,
+		})
+	}
+
+	lanes, _ := f.cars.Dims()
+	for i := 0; i < lanes; i++ {
+		if !f.lanes[i] {
+			continue
+		}
+		car := f.cars.Row(i)
+		y := int(car[1])
+		factors = append(factors,
+			game.Factor{
+				Name:       fmt.Sprintf("lane%v_x", y),
+				Value:      int(car[0]),
+				Reflection: game.Column,
+			},
+			game.Factor{
+				Name:       fmt.Sprintf("lane%v_velocity", y),
+				Value:      int(car[3]),
+				Reflection: game.Horizontal,
+			},
+		)
+	}
+	return factors
+}
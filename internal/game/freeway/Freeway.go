@@ -19,10 +19,9 @@ package freeway
 import (
 	"fmt"
 	"math"
-	"math/rand"
 
 	"github.com/samuelfneumann/goatar/internal/game"
-	"gonum.org/v1/gonum/mat"
+	"github.com/samuelfneumann/goatar/internal/grid"
 )
 
 const (
@@ -36,6 +35,10 @@ const (
 	// Rows and columns for observation matrix
 	observationRows int = rows + 2
 	observationCols int = rows + 2
+
+	// Columns in which the first and second chicken cross the road
+	chickenX int = 4
+	partnerX int = 6
 )
 
 // Freeway implements the Freeway game. In this game, an agent must
@@ -62,19 +65,115 @@ const (
 // representation.
 type Freeway struct {
 	channels  map[string]int
-	actionMap []rune
-	rng       *rand.Rand
+	actionMap []game.Action
+	rng       *game.Random
+	config    Config
 
-	cars     *mat.Dense // Matrix representing info on each car
+	cars     *grid.Grid // Matrix representing info on each car
 	position int        // Position of agent
+	lanes    [rows]bool // Whether each lane has a car
+
+	// Position and move timer of the second chicken, and its action on
+	// the next step, if Config.SecondChicken is set
+	partner       int
+	partnerTimer  float64
+	partnerAction game.Action
 
 	moveTimer      float64
 	terminateTimer int
 	terminal       bool
+	reason         game.TerminationReason // Why the episode ended
+
+	rewardEvents []game.RewardEvent // Reward events of the last step
+
+	cache game.StateCache // Cached state observation
+	log   game.Log        // Logs the events of the game
+}
+
+// Versions describes the changes in behaviour of each version of the
+// game, where element i describes version i+1
+var Versions = []string{
+	"the original port of MinAtar, in which each move of a car " +
+		"travelling left moves it to the right edge of the screen",
+	"cars travelling left move one cell to the left, as in MinAtar; " +
+		"random numbers are drawn with xoshiro256** rather than " +
+		"math/rand, and sticky actions restart with each episode",
+}
+
+// LatestVersion is the latest version of the game
+const LatestVersion = 2
+
+// Config configures a Freeway game. The zero value is the default
+// configuration.
+type Config struct {
+	// Version is the version of the game's behaviour, see Versions. If
+	// 0, the latest version is used.
+	Version int
+
+	// Lanes is the number of lanes with a car, which are spread evenly
+	// from the top lane down. If zero, all 8 lanes have a car.
+	Lanes int
+
+	// CarRespawn determines what happens when a car leaves the screen
+	CarRespawn CarRespawn
+
+	// SecondChicken adds a second chicken, which crosses the road to
+	// the right of the first and is controlled by a second agent. The
+	// agents share the reward of +1 for either chicken crossing.
+	SecondChicken bool
+}
+
+// CarRespawn determines what happens when a car leaves the screen
+type CarRespawn int
+
+const (
+	// WrapAround moves the car to the other side of the screen, as in
+	// MinAtar
+	WrapAround CarRespawn = iota
+
+	// NewSpeed moves the car to the other side of the screen with a
+	// new random speed, keeping its direction
+	NewSpeed
+)
+
+// withDefaults returns the configuration with zero values replaced by
+// their defaults
+func (c Config) withDefaults() Config {
+	if c.Version == 0 {
+		c.Version = LatestVersion
+	}
+	if c.Lanes == 0 {
+		c.Lanes = rows
+	}
+	return c
+}
+
+// validate returns an error if the configuration is invalid
+func (c Config) validate() error {
+	if c.Version < 0 || c.Version > LatestVersion {
+		return fmt.Errorf("version %v ∉ [1, %v]", c.Version, LatestVersion)
+	}
+	if c.Lanes < 0 || c.Lanes > rows {
+		return fmt.Errorf("lanes %v ∉ [0, %v]", c.Lanes, rows)
+	}
+	if c.CarRespawn < WrapAround || c.CarRespawn > NewSpeed {
+		return fmt.Errorf("unknown car respawn behaviour %v", c.CarRespawn)
+	}
+	return nil
 }
 
 // New returns a new Freeway game
-func New(_ bool, seed int64) (game.Game, error) {
+func New(ramping bool, seed int64) (game.Game, error) {
+	return NewWithConfig(ramping, seed, Config{})
+}
+
+// NewWithConfig returns a new Freeway game with the given
+// configuration
+func NewWithConfig(_ bool, seed int64, config Config) (game.Game, error) {
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("newWithConfig: %v", err)
+	}
+
 	channels := map[string]int{
 		"chicken": 0,
 		"car":     1,
@@ -84,13 +183,21 @@ func New(_ bool, seed int64) (game.Game, error) {
 		"speed4":  5,
 		"speed5":  6,
 	}
-	actionMap := []rune{'n', 'l', 'u', 'r', 'd', 'f'}
-	rng := rand.New(rand.NewSource(seed))
+	if config.SecondChicken {
+		channels["chicken2"] = len(channels)
+	}
+	actionMap := []game.Action{game.NoOp, game.Left, game.Up, game.Right,
+		game.Down, game.Fire}
+	rng := game.NewVersionedRandom(seed, config.withDefaults().Version)
 
 	freeway := &Freeway{
 		channels:  channels,
 		actionMap: actionMap,
 		rng:       rng,
+		config:    config.withDefaults(),
+	}
+	for i := 0; i < freeway.config.Lanes; i++ {
+		freeway.lanes[i*rows/freeway.config.Lanes] = true
 	}
 	freeway.Reset()
 
@@ -99,23 +206,34 @@ func New(_ bool, seed int64) (game.Game, error) {
 
 // State returns the current state observation
 func (f *Freeway) State() ([]float64, error) {
+	return f.cache.State(observationRows*observationCols*f.NChannels(),
+		f.observe)
+}
+
+// observe fills the zeroed slice state with the state observation
+func (f *Freeway) observe(state []float64) error {
 	r, c := observationRows, observationCols
-	state := make([]float64, r*c*f.NChannels())
 
 	// Set the agent's position in the observation matrix
-	state[r*c*f.channels["chicken"]+f.position*c+4] = 1.0
+	state[r*c*f.channels["chicken"]+f.position*c+chickenX] = 1.0
+	if f.config.SecondChicken {
+		state[r*c*f.channels["chicken2"]+f.partner*c+partnerX] = 1.0
+	}
 
 	// Set each car's position in the observation matrix
 	for i := 0; i < 8; i++ {
-		car := f.cars.RowView(i)
-		y, x := int(car.AtVec(1)), int(car.AtVec(0))
+		if !f.lanes[i] {
+			continue
+		}
+		car := f.cars.Row(i)
+		y, x := int(car[1]), int(car[0])
 		state[r*c*f.channels["car"]+y*c+x] = 1.0
 
 		var backX int
-		if car.AtVec(3) > 0 {
-			backX = int(car.AtVec(0)) - 1
+		if car[3] > 0 {
+			backX = int(car[0]) - 1
 		} else {
-			backX = int(car.AtVec(0)) + 1
+			backX = int(car[0]) + 1
 		}
 
 		if backX < 0 {
@@ -127,7 +245,7 @@ func (f *Freeway) State() ([]float64, error) {
 		// Find the channel at which to place the car. Each channel
 		// refers to a different speed.
 		var trail int
-		switch int(math.Abs(car.AtVec(3))) {
+		switch int(math.Abs(car[3])) {
 		case 1:
 			trail = f.channels["speed1"]
 
@@ -144,14 +262,22 @@ func (f *Freeway) State() ([]float64, error) {
 			trail = f.channels["speed5"]
 
 		default:
-			return nil, fmt.Errorf("state: no such speed value %v",
-				int(math.Abs(car.AtVec(3))))
+			return fmt.Errorf("state: no such speed value %v",
+				int(math.Abs(car[3])))
 		}
 
-		backY := int(car.AtVec(1))
+		backY := int(car[1])
 		state[r*c*trail+backY*c+backX] = 1.0
 	}
-	return state, nil
+	return nil
+}
+
+// MaxEpisodeSteps returns the number of steps after which every
+// episode ends. As in MinAtar, the episode ends once the time limit
+// timer falls below zero, which takes one step more than the time
+// limit.
+func (f *Freeway) MaxEpisodeSteps() int {
+	return timeLimit + 1
 }
 
 // DifficultyRamp returns the current difficulty level.
@@ -161,6 +287,71 @@ func (f *Freeway) DifficultyRamp() int {
 	return 0
 }
 
+// RewardRange returns the minimum and maximum reward that can be
+// received on a single step, which is 2 if both chickens cross on the
+// same step
+func (f *Freeway) RewardRange() (min, max float64) {
+	if f.config.SecondChicken {
+		return 0, 2
+	}
+	return 0, 1
+}
+
+// Seed seeds the random number generator of the game. The game is not
+// reset.
+func (f *Freeway) Seed(seed int64) {
+	f.rng.Seed(seed)
+}
+
+// Clone returns a deep copy of the game. The copy behaves identically
+// to the original given the same actions, but does not share any state
+// with it.
+func (f *Freeway) Clone() game.Game {
+	clone := *f
+	clone.rng = f.rng.Clone()
+	clone.cars = f.cars.Clone()
+	clone.cache = game.StateCache{}
+	clone.rewardEvents = append([]game.RewardEvent(nil),
+		f.rewardEvents...)
+	return &clone
+}
+
+// SetStochasticity sets the fraction of random draws made by the game
+// which are random rather than deterministic
+func (f *Freeway) SetStochasticity(level float64) {
+	f.rng.SetStochasticity(level)
+}
+
+// StateHash returns a hash of the underlying game state, including
+// all timers. Two games with the same hash will, with high
+// probability, behave identically given the same actions and random
+// numbers.
+func (f *Freeway) StateHash() uint64 {
+	h := game.NewHasher()
+	h.Float(f.cars.Data()...)
+	h.Float(f.moveTimer)
+	h.Int(f.position, f.terminateTimer)
+	h.Bool(f.terminal)
+	if f.config.SecondChicken {
+		h.Float(f.partnerTimer)
+		h.Int(f.partner, int(f.partnerAction))
+	}
+
+	return h.Sum()
+}
+
+// RNGHash returns a hash of the state of the game's random number
+// generator, which is not included in StateHash
+func (f *Freeway) RNGHash() uint64 {
+	return f.rng.Hash()
+}
+
+// RewardEvents returns the events which resulted in rewards on the
+// last step. The returned slice is only valid until the next step.
+func (f *Freeway) RewardEvents() []game.RewardEvent {
+	return f.rewardEvents
+}
+
 // Act takes a single environmental step given an action a.
 func (f *Freeway) Act(a int) (float64, bool, error) {
 	if a >= len(f.actionMap) || a < 0 {
@@ -168,58 +359,55 @@ func (f *Freeway) Act(a int) (float64, bool, error) {
 			a, len(f.actionMap))
 	}
 
+	f.cache.Invalidate()
+	f.rewardEvents = f.rewardEvents[:0]
 	reward := 0.0
 	if f.terminal {
 		return reward, f.terminal, nil
 	}
 
 	// Update the environment with respect to the action
-	action := f.actionMap[a]
-	if action == 'u' && f.moveTimer == 0 {
-		f.moveTimer = playerSpeed
-		if 0 > f.position-1 {
-			f.position = 0
-		} else {
-			f.position--
-		}
-	} else if action == 'd' && f.moveTimer == 0 {
-		f.moveTimer = playerSpeed
-		if 9 < f.position {
-			f.position = 9
-		} else {
-			f.position++
-		}
+	moveChicken(f.actionMap[a], &f.position, &f.moveTimer)
+	if f.config.SecondChicken {
+		moveChicken(f.partnerAction, &f.partner, &f.partnerTimer)
+		f.partnerAction = game.NoOp
 	}
 
 	// Win condition
-	if f.position == 0 {
-		reward += 1
+	reward += f.cross(&f.position, chickenX, "chicken")
+	if f.config.SecondChicken {
+		reward += f.cross(&f.partner, partnerX, "chicken2")
+	}
+	if reward > 0 {
 		f.randomizeCars(false)
-		f.position = 9
 	}
 
 	r, _ := f.cars.Dims()
 	for i := 0; i < r; i++ {
-		if f.cars.At(i, 0) == 4 && f.cars.At(i, 1) == float64(f.position) {
-			f.position = 9
+		if !f.lanes[i] {
+			continue
 		}
+		f.collide(i)
 		if f.cars.At(i, 2) == 0.0 {
 			f.cars.Set(i, 2, math.Abs(f.cars.At(i, 3)))
 
 			if f.cars.At(i, 3) > 0 {
 				f.cars.Set(i, 0, f.cars.At(i, 0)+1)
-			} else {
+			} else if f.config.Version == 1 {
 				f.cars.Set(i, 0, 9)
+			} else {
+				f.cars.Set(i, 0, f.cars.At(i, 0)-1)
 			}
 
 			if f.cars.At(i, 0) > 9 {
 				f.cars.Set(i, 0, 0)
+				f.respawnCar(i)
+			} else if f.cars.At(i, 0) < 0 {
+				f.cars.Set(i, 0, 9)
+				f.respawnCar(i)
 			}
 
-			if f.cars.At(i, 0) == 4.0 &&
-				f.cars.At(i, 1) == float64(f.position) {
-				f.position = 9
-			}
+			f.collide(i)
 		} else {
 			f.cars.Set(i, 2, f.cars.At(i, 2)-1)
 		}
@@ -229,20 +417,99 @@ func (f *Freeway) Act(a int) (float64, bool, error) {
 	if f.moveTimer > 0 {
 		f.moveTimer--
 	}
+	if f.partnerTimer > 0 {
+		f.partnerTimer--
+	}
 	f.terminateTimer -= 1
 	if f.terminateTimer < 0 {
-		f.terminal = true
+		f.terminate(game.TimeLimit)
 	}
 
 	return reward, f.terminal, nil
 }
 
+// moveChicken moves the chicken at row position up or down as given
+// by action, if its move timer has run out
+func moveChicken(action game.Action, position *int, moveTimer *float64) {
+	if action == game.Up && *moveTimer == 0 {
+		*moveTimer = playerSpeed
+		if 0 > *position-1 {
+			*position = 0
+		} else {
+			*position--
+		}
+	} else if action == game.Down && *moveTimer == 0 {
+		*moveTimer = playerSpeed
+		if 9 < *position {
+			*position = 9
+		} else {
+			*position++
+		}
+	}
+}
+
+// cross returns the chicken at row position, which crosses the road in
+// column x, to the bottom of the screen if it has reached the top, and
+// returns the reward for crossing
+func (f *Freeway) cross(position *int, x int, entity string) float64 {
+	if *position != 0 {
+		return 0
+	}
+
+	f.rewardEvents = append(f.rewardEvents, game.RewardEvent{
+		Type:   game.Goal,
+		Amount: 1,
+		X:      x,
+		Y:      0,
+		Entity: entity,
+	})
+	*position = 9
+	return 1
+}
+
+// collide returns each chicken hit by the car in lane i to the bottom
+// of the screen
+func (f *Freeway) collide(i int) {
+	x, y := f.cars.At(i, 0), f.cars.At(i, 1)
+	if x == float64(chickenX) && y == float64(f.position) {
+		if f.log.On() {
+			f.log.Debugf("freeway: chicken collided with car at (%v, %v)",
+				x, y)
+		}
+		f.position = 9
+	}
+	if f.config.SecondChicken && x == float64(partnerX) &&
+		y == float64(f.partner) {
+		if f.log.On() {
+			f.log.Debugf("freeway: second chicken collided with car at "+
+				"(%v, %v)", x, y)
+		}
+		f.partner = 9
+	}
+}
+
+// respawnCar performs the housekeeping when the car in lane i leaves
+// the screen and reappears on the other side
+func (f *Freeway) respawnCar(i int) {
+	if f.config.CarRespawn != NewSpeed {
+		return
+	}
+
+	speed := float64(f.rng.Intn("car speed", 4) + 1)
+	f.cars.Set(i, 2, speed)
+	f.cars.Set(i, 3, math.Copysign(speed, f.cars.At(i, 3)))
+	if f.log.On() {
+		f.log.Debugf("freeway: respawned car in lane %v with speed %v", i,
+			speed)
+	}
+}
+
 // randomizeCars randomizes all the car directions and speed for the
 // start of a new episode.
 func (f *Freeway) randomizeCars(init bool) {
 	var directions [rows]float64
 	for i := range directions {
-		if float64(f.rng.Intn(2)-1) == 0 {
+		if float64(f.rng.Intn("car direction", 2)-1) == 0 {
 			directions[i] = -1.0
 		} else {
 			directions[i] = 1.0
@@ -251,38 +518,53 @@ func (f *Freeway) randomizeCars(init bool) {
 
 	var speeds [rows]float64
 	for i := range speeds {
-		speeds[i] = directions[i] * float64(f.rng.Intn(4)+1)
+		speeds[i] = directions[i] * float64(f.rng.Intn("car speed", 4)+1)
 	}
 
 	if init {
-		cars := make([]float64, rows*cols)
+		if f.cars == nil {
+			f.cars = grid.New(rows, cols, nil)
+		} else {
+			f.cars.Zero()
+		}
 		for i := 0; i < rows; i++ {
-			cars[cols*i] = 0.0
-			cars[cols*i+1] = float64(i + 1)
-			cars[cols*i+2] = math.Abs(speeds[i])
-			cars[cols*i+3] = speeds[i]
+			f.cars.Set(i, 1, float64(i+1))
+			f.cars.Set(i, 2, math.Abs(speeds[i]))
+			f.cars.Set(i, 3, speeds[i])
 		}
-		f.cars = mat.NewDense(rows, cols, cars)
 	} else {
 		for i := 0; i < rows; i++ {
 			f.cars.Set(i, 2, math.Abs(speeds[i]))
 			f.cars.Set(i, 3, speeds[i])
 		}
 	}
+	if f.log.On() {
+		f.log.Debugf("freeway: spawned cars with velocities %v", speeds)
+	}
 }
 
 // Reset resets the environment to some starting state.
 func (f *Freeway) Reset() {
+	f.cache.Invalidate()
+	f.rewardEvents = f.rewardEvents[:0]
 	f.randomizeCars(true)
 	f.position = 9
 	f.moveTimer = playerSpeed
+	f.partner = 9
+	f.partnerTimer = playerSpeed
+	f.partnerAction = game.NoOp
 	f.terminateTimer = timeLimit
 	f.terminal = false
+	f.reason = game.NotTerminated
 }
 
-// StateShape returns the shape of the state observations
-func (f *Freeway) StateShape() []int {
-	return []int{f.NChannels(), observationRows, observationCols}
+// Shape returns the shape of the state observations
+func (f *Freeway) Shape() game.Shape {
+	return game.Shape{
+		Channels: f.NChannels(),
+		Rows:     observationRows,
+		Cols:     observationCols,
+	}
 }
 
 // NChannels returns the number of channels in each state observation
@@ -293,7 +575,7 @@ func (f *Freeway) NChannels() int {
 // MinimalActionSet returns the actions which actually have an effect
 // on the environment.
 func (f *Freeway) MinimalActionSet() []int {
-	minimalActions := []rune{'n', 'u', 'd'}
+	minimalActions := []game.Action{game.NoOp, game.Up, game.Down}
 	minimalIntActions := make([]int, len(minimalActions))
 
 	for i, minimalAction := range minimalActions {
@@ -306,6 +588,19 @@ func (f *Freeway) MinimalActionSet() []int {
 	return minimalIntActions
 }
 
+// ActionEffect returns a description of the effect of action a
+func (f *Freeway) ActionEffect(a game.Action) string {
+	effects := map[game.Action]string{
+		game.NoOp: "do nothing",
+		game.Up:   "move the chicken up",
+		game.Down: "move the chicken down",
+	}
+	if effect, ok := effects[a]; ok {
+		return effect
+	}
+	return "no effect"
+}
+
 // Channel returns the state observation channel at index i
 func (f *Freeway) Channel(i int) ([]float64, error) {
 	if i >= f.NChannels() {
@@ -323,3 +618,28 @@ func (f *Freeway) Channel(i int) ([]float64, error) {
 
 	return state[rows*cols*i : rows*cols*(i+1)], nil
 }
+
+// SetLogger makes the game log its events to l, or discard them if l
+// is nil
+func (f *Freeway) SetLogger(l game.Logger) {
+	f.log.SetLogger(l)
+}
+
+// TerminationReason returns the reason the episode ended, or
+// game.NotTerminated if the episode has not ended
+func (f *Freeway) TerminationReason() game.TerminationReason {
+	return f.reason
+}
+
+// terminate ends the episode for the given reason. If the episode has
+// already ended on this step, the first reason is kept.
+func (f *Freeway) terminate(reason game.TerminationReason) {
+	if !f.terminal {
+		f.reason = reason
+		if f.log.On() {
+			f.log.Debugf("freeway: terminated after %v steps: %v",
+				timeLimit-f.terminateTimer, reason)
+		}
+	}
+	f.terminal = true
+}
@@ -0,0 +1,43 @@
+package freeway
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// channelDescriptions describes each channel of state observations
+var channelDescriptions = map[string]string{
+	"chicken":  "position of the chicken",
+	"chicken2": "position of the second chicken",
+	"car":      "positions of cars",
+	"speed1":   "trails of cars which move every step",
+	"speed2":   "trails of cars which move every 2 steps",
+	"speed3":   "trails of cars which move every 3 steps",
+	"speed4":   "trails of cars which move every 4 steps",
+	"speed5":   "trails of cars which move every 5 steps",
+}
+
+// Manifest returns a description of the game
+func (f *Freeway) Manifest() game.Manifest {
+	rewards := []game.RewardInfo{
+		{Event: "chicken reaches the top of the screen", Reward: "+1"},
+	}
+	if f.config.SecondChicken {
+		rewards[0].Event = "either chicken reaches the top of the screen"
+	}
+
+	return game.Manifest{
+		Description: "The player controls a chicken which must cross a " +
+			"road of cars travelling horizontally. The chicken can only " +
+			"move up and down, and is returned to the bottom of the " +
+			"screen when hit by a car.",
+		Channels:    game.ChannelInfos(f.channels, channelDescriptions),
+		Actions:     game.ActionInfos(f),
+		Rewards:     rewards,
+		Termination: []string{"2500 steps have elapsed"},
+		Ramping: "none, but car speeds are randomized each time the " +
+			"chicken reaches the top of the screen",
+		ZOrder: []string{
+			"speed1", "speed2", "speed3", "speed4", "speed5", "car",
+			"chicken", "chicken2",
+		},
+		Trails: []string{"speed1", "speed2", "speed3", "speed4", "speed5"},
+	}
+}
@@ -0,0 +1,39 @@
+package freeway
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// Objects returns the chicken, and the second chicken if there is one,
+// followed by each car, from the top lane to the bottom lane
+func (f *Freeway) Objects() []game.Object {
+	objects := []game.Object{
+		{Type: "chicken", X: chickenX, Y: f.position},
+	}
+	if f.config.SecondChicken {
+		objects = append(objects, game.Object{
+			Type: "chicken2",
+			X:    partnerX,
+			Y:    f.partner,
+		})
+	}
+
+	lanes, _ := f.cars.Dims()
+	for i := 0; i < lanes; i++ {
+		if !f.lanes[i] {
+			continue
+		}
+		car := f.cars.Row(i)
+		objects = append(objects, game.Object{
+			Type:        "car",
+			X:           int(car[0]),
+			Y:           int(car[1]),
+			Orientation: game.HorizontalOrientation(int(car[3])),
+		})
+	}
+	return objects
+}
+
+// PlayerPosition returns the column and row of the chicken controlled
+// through Act
+func (f *Freeway) PlayerPosition() (x, y int) {
+	return chickenX, f.position
+}
@@ -0,0 +1,29 @@
+package freeway
+
+import "fmt"
+
+// Players returns the number of chickens, which is 2 if
+// Config.SecondChicken is set and 1 otherwise
+func (f *Freeway) Players() int {
+	if f.config.SecondChicken {
+		return 2
+	}
+	return 1
+}
+
+// SetPlayerActions sets the action of the second chicken on the next
+// step
+func (f *Freeway) SetPlayerActions(actions []int) error {
+	if len(actions) != f.Players()-1 {
+		return fmt.Errorf("setPlayerActions: expected %v actions but got "+
+			"%v", f.Players()-1, len(actions))
+	}
+	for _, a := range actions {
+		if a < 0 || a >= len(f.actionMap) {
+			return fmt.Errorf("setPlayerActions: invalid action %v ∉ "+
+				"[0, %v)", a, len(f.actionMap))
+		}
+		f.partnerAction = f.actionMap[a]
+	}
+	return nil
+}
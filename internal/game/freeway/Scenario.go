@@ -0,0 +1,57 @@
+package freeway
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// The methods in this file set the underlying state of the game by
+// hand, so that scripted scenarios can be constructed and stepped.
+
+// PlaceChicken moves the chicken to row y
+func (f *Freeway) PlaceChicken(y int) error {
+	if y < 0 || y >= observationRows {
+		return fmt.Errorf("placeChicken: row %v ∉ [0, %v)", y,
+			observationRows)
+	}
+	f.cache.Invalidate()
+	f.position = y
+	return nil
+}
+
+// SetMoveTimer sets the number of steps until the chicken can move
+func (f *Freeway) SetMoveTimer(steps int) error {
+	if steps < 0 {
+		return fmt.Errorf("setMoveTimer: steps must be non-negative but "+
+			"got %v", steps)
+	}
+	f.moveTimer = float64(steps)
+	return nil
+}
+
+// PlaceCar places the car of the lane in row y at column x, driving
+// left or right with the given speed. A car with speed s moves one cell
+// every s+1 steps, and first moves on step s+1.
+func (f *Freeway) PlaceCar(x, y int, o game.Orientation, speed int) error {
+	var dir int
+	switch o {
+	case game.FacingLeft:
+		dir = -1
+	case game.FacingRight:
+		dir = 1
+	default:
+		return fmt.Errorf("placeCar: car cannot drive %q", o)
+	}
+	if x < 0 || x >= observationCols || y < 1 || y > rows {
+		return fmt.Errorf("placeCar: position (%v, %v) out of bounds", x, y)
+	}
+	if speed < 1 || speed > 4 {
+		return fmt.Errorf("placeCar: speed %v ∉ [1, 4]", speed)
+	}
+
+	f.cache.Invalidate()
+	f.cars.SetRow(y-1, []float64{float64(x), float64(y), float64(speed),
+		float64(dir * speed)})
+	return nil
+}
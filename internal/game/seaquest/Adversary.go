@@ -0,0 +1,49 @@
+package seaquest
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// SpawnDue returns whether an enemy spawns on the next step. Divers
+// are not chosen by an adversary, and enemies spawned by a spawn
+// scheduler are never due.
+func (s *SeaQuest) SpawnDue() bool {
+	return s.config.SpawnScheduler == nil && s.eSpawnTimer == 0 &&
+		!s.terminal
+}
+
+// SpawnPositions returns the positions at which the enemy spawned on
+// the next step can spawn, which are both sides of each row in which
+// enemies spawn, except where the enemy would be blocked by another
+// enemy in the row. It returns nil if no enemy is due to spawn.
+func (s *SeaQuest) SpawnPositions() []game.SpawnPosition {
+	if !s.SpawnDue() {
+		return nil
+	}
+
+	positions := []game.SpawnPosition{}
+	for y := 1; y <= rows-2; y++ {
+		for _, right := range []bool{false, true} {
+			if !s.blocked(y, right) {
+				positions = append(positions,
+					game.SpawnPosition{Row: y, Right: right})
+			}
+		}
+	}
+	return positions
+}
+
+// SetSpawn sets the position of the enemy spawned on the next step
+func (s *SeaQuest) SetSpawn(p game.SpawnPosition) error {
+	for _, legal := range s.SpawnPositions() {
+		if p == legal {
+			s.chosenSpawn = p
+			s.spawnChosen = true
+			return nil
+		}
+	}
+	return fmt.Errorf("setSpawn: cannot spawn in row %v moving right %v",
+		p.Row, p.Right)
+}
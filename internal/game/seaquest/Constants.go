@@ -0,0 +1,21 @@
+package seaquest
+
+// Constants returns the constants of the game's dynamics, by the name
+// of the constant in MinAtar where it has one. Parameters changed with
+// SetParam are not reflected.
+func (s *SeaQuest) Constants() map[string]int {
+	return map[string]int{
+		"ramp_interval":       rampInterval,
+		"max_oxygen":          maxOxygen,
+		"max_divers":          maxDivers,
+		"init_spawn_speed":    initSpawnSpeed,
+		"diver_spawn_speed":   diverSpawnSpeed,
+		"init_move_interval":  initMoveInterval,
+		"shot_cool_down":      shotCoolDown,
+		"enemy_shot_interval": enemyShotInterval,
+		"enemy_move_interval": enemyMoveInterval,
+		"diver_move_interval": diverMoveInterval,
+		"min_spawn_speed":     minSpawnSpeed,
+		"min_move_interval":   minMoveInterval,
+	}
+}
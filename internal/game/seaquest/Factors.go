@@ -0,0 +1,102 @@
+package seaquest
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/entity"
+)
+
+// Kinds of enemy in a row, as given by the rowN_enemy factors
+const (
+	noEnemy = iota
+	fishEnemy
+	subEnemy
+)
+
+// rowFactors holds the factors of a single row of the screen
+type rowFactors struct {
+	enemy, enemyX, enemyDir int
+	diverX, diverDir        int
+}
+
+// Factors returns the position and direction of the player's
+// submarine and the player's oxygen and number of divers, followed by
+// the kind, column, and direction of the enemy and the column and
+// direction of the diver in each of rows 1 to 8. Rows without an
+// enemy have enemy kind 0, and fish and submarines have kinds 1 and 2.
+// Absent enemies and divers have column -1 and direction 0. If a row
+// holds more than one enemy or diver, the factors describe the one
+// which spawned first.
+func (s *SeaQuest) Factors() []game.Factor {
+	rowsOf := make([]rowFactors, rows)
+	for y := range rowsOf {
+		rowsOf[y] = rowFactors{enemyX: -1, diverX: -1}
+	}
+
+	each := func(entities *entity.Manager, f func(r *rowFactors,
+		sw *swimmer)) {
+		entities.Each(func(_ entity.ID, e entity.Entity) {
+			sw, ok := e.(*swimmer)
+			if !ok {
+				sw = e.(*submarine).swimmer
+			}
+			if y := sw.y(); y >= 0 && y < rows {
+				f(&rowsOf[y], sw)
+			}
+		})
+	}
+	addEnemy := func(kind int) func(*rowFactors, *swimmer) {
+		return func(r *rowFactors, sw *swimmer) {
+			if r.enemy == noEnemy {
+				r.enemy, r.enemyX, r.enemyDir = kind, sw.x(), sw.direction()
+			}
+		}
+	}
+	each(s.eFish, addEnemy(fishEnemy))
+	each(s.eSubs, addEnemy(subEnemy))
+	each(s.divers, func(r *rowFactors, sw *swimmer) {
+		if r.diverX < 0 {
+			r.diverX, r.diverDir = sw.x(), sw.direction()
+		}
+	})
+
+	factors := []game.Factor{
+		{Name: "sub_x", Value: s.agent.x(), Reflection: game.Column},
+		{Name: "sub_y", Value: s.agent.y()},
+		{
+			Name:       "sub_dx",
+			Value:      s.agent.direction(),
+			Reflection: game.Horizontal,
+		},
+		{Name: "oxygen", Value: game.MaxInt(s.agent.oxygen(), 0)},
+		{Name: "divers", Value: s.agent.divers()},
+	}
+	for y := 1; y < rows-1; y++ {
+		r := rowsOf[y]
+		factors = append(factors,
+			game.Factor{Name: fmt.Sprintf("row%v_enemy", y), Value: r.enemy},
+			game.Factor{
+				Name:       fmt.Sprintf("row%v_enemy_x", y),
+				Value:      r.enemyX,
+				Reflection: game.Column,
+			},
+			game.Factor{
+				Name:       fmt.Sprintf("row%v_enemy_dx", y),
+				Value:      r.enemyDir,
+				Reflection: game.Horizontal,
+			},
+			game.Factor{
+				Name:       fmt.Sprintf("row%v_diver_x", y),
+				Value:      r.diverX,
+				Reflection: game.Column,
+			},
+			game.Factor{
+				Name:       fmt.Sprintf("row%v_diver_dx", y),
+				Value:      r.diverDir,
+				Reflection: game.Horizontal,
+			},
+		)
+	}
+	return factors
+}
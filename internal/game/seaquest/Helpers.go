@@ -1,6 +1,9 @@
 package seaquest
 
-import "github.com/samuelfneumann/goatar/internal/game"
+import (
+	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/entity"
+)
 
 // submarine implements a submarine in the SeaQuest game
 type submarine struct {
@@ -8,16 +11,26 @@ type submarine struct {
 	shotTimer int // Can only shoot once this reaches 0
 }
 
-// newSubmarine returns a new submarine
-func newSubmarine(x, y int, right bool, moveTimer, shotTimer int) *submarine {
-	swimmer := newSwimmer(x, y, right, moveTimer)
-
-	sub := &submarine{
-		swimmer:   swimmer,
-		shotTimer: shotTimer,
+// init initializes the submarine, overwriting all its fields, and
+// returns the submarine. This allows submarines removed from the game
+// to be reused.
+func (s *submarine) init(x, y int, right bool, moveTimer,
+	shotTimer int) *submarine {
+	if s.swimmer == nil {
+		s.swimmer = new(swimmer)
 	}
+	s.swimmer.init(x, y, right, moveTimer)
+	s.shotTimer = shotTimer
+	return s
+}
 
-	return sub
+// recycledSubmarine returns a submarine removed from m which can be
+// reused, or a new submarine if there is none
+func recycledSubmarine(m *entity.Manager) *submarine {
+	if s, ok := m.Recycled().(*submarine); ok {
+		return s
+	}
+	return new(submarine)
 }
 
 // canShoot returns whether the submarine is allowed to shoot yet or not
@@ -44,10 +57,20 @@ type swimmer struct {
 	yPos          int
 	moveDirection int
 	moveTimer     int // Can only move once this reaches 0
+
+	// Vertical drift of the swimmer, which is only used by drifting
+	// fish. The swimmer drifts by driftDirection rows every
+	// driftInterval moves.
+	driftDirection int
+	driftInterval  int
+	driftTimer     int // Number of moves until the next drift
+	lastDrift      int // Rows drifted on the last move
 }
 
-// newSwimmer returns a new swimmer
-func newSwimmer(x, y int, right bool, moveTimer int) *swimmer {
+// init initializes the swimmer, overwriting all its fields, and
+// returns the swimmer. This allows swimmers removed from the game to
+// be reused.
+func (s *swimmer) init(x, y int, right bool, moveTimer int) *swimmer {
 	var direction int
 	if right {
 		direction = 1
@@ -55,17 +78,22 @@ func newSwimmer(x, y int, right bool, moveTimer int) *swimmer {
 		direction = -1
 	}
 
-	return &swimmer{
+	*s = swimmer{
 		xPos:          x,
 		yPos:          y,
 		moveDirection: direction,
 		moveTimer:     moveTimer,
 	}
+	return s
 }
 
-// newBullet returns a new bullet, which is a swimmer with no move timer
-func newBullet(x, y int, right bool) *swimmer {
-	return newSwimmer(x, y, right, 0)
+// recycledSwimmer returns a swimmer removed from m which can be
+// reused, or a new swimmer if there is none
+func recycledSwimmer(m *entity.Manager) *swimmer {
+	if s, ok := m.Recycled().(*swimmer); ok {
+		return s
+	}
+	return new(swimmer)
 }
 
 // direction returns the direction of movement of the swimmer. +1
@@ -95,6 +123,11 @@ func (s *swimmer) setY(pos int) {
 	s.yPos = pos
 }
 
+// Position returns the x and y position of the swimmer
+func (s *swimmer) Position() (x, y int) {
+	return s.xPos, s.yPos
+}
+
 // x returns the x position of the swimmer
 func (s *swimmer) x() int {
 	return s.xPos
@@ -116,6 +149,39 @@ func (s *swimmer) move() {
 	s.xPos += s.direction()
 }
 
+// setDrift makes the swimmer drift vertically by one row every
+// interval moves, beginning upwards if up is true
+func (s *swimmer) setDrift(up bool, interval int) {
+	s.driftDirection = 1
+	if up {
+		s.driftDirection = -1
+	}
+	s.driftInterval = interval
+	s.driftTimer = interval
+}
+
+// drift moves a drifting swimmer vertically if it is time to do so,
+// reversing its vertical direction to stay within rows [top, bottom].
+// It should be called each time the swimmer moves.
+func (s *swimmer) drift(top, bottom int) {
+	s.lastDrift = 0
+	if s.driftDirection == 0 {
+		return
+	}
+
+	s.driftTimer--
+	if s.driftTimer > 0 {
+		return
+	}
+	s.driftTimer = s.driftInterval
+
+	if y := s.yPos + s.driftDirection; y < top || y > bottom {
+		s.driftDirection = -s.driftDirection
+	}
+	s.yPos += s.driftDirection
+	s.lastDrift = s.driftDirection
+}
+
 // canMove returns whether the swimmer can move or not
 func (s *swimmer) canMove() bool {
 	return s.moveTimer == 0
@@ -141,13 +207,20 @@ type player struct {
 // newPlayer returns a new player
 func newPlayer(x, y int, right bool, moveTimer, shotTimer,
 	oxygen int) *player {
-	sub := newSubmarine(x, y, right, moveTimer, shotTimer)
+	return new(player).init(x, y, right, moveTimer, shotTimer, oxygen)
+}
 
-	return &player{
-		submarine:       sub,
-		remainingOxygen: oxygen,
-		diverCount:      0,
+// init initializes the player, overwriting all its fields, and returns
+// the player
+func (p *player) init(x, y int, right bool, moveTimer, shotTimer,
+	oxygen int) *player {
+	if p.submarine == nil {
+		p.submarine = new(submarine)
 	}
+	p.submarine.init(x, y, right, moveTimer, shotTimer)
+	p.remainingOxygen = oxygen
+	p.diverCount = 0
+	return p
 }
 
 // setDivers sets the number of divers held in the player's submarine
@@ -0,0 +1,75 @@
+package seaquest
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// channelDescriptions describes each channel of state observations
+var channelDescriptions = map[string]string{
+	"sub_front":       "position of the front of the player's submarine",
+	"sub_back":        "position of the back of the player's submarine",
+	"friendly_bullet": "positions of the player's bullets",
+	"trail": "previous positions of enemies and divers, indicating " +
+		"their direction of movement",
+	"enemy_bullet": "positions of enemy bullets",
+	"enemy_fish":   "positions of enemy fish",
+	"enemy_sub":    "positions of enemy submarines",
+	"oxygen_guage": "remaining oxygen, as a bar along the bottom row",
+	"diver_guage": "rescued divers, as a bar along the bottom row " +
+		"ending at the second last column",
+	"diver":          "positions of divers",
+	"oxygen_warning": "active in every cell while oxygen is low",
+	"enemy_bullet_trail": "previous positions of enemy bullets, " +
+		"indicating their direction of movement",
+}
+
+// Manifest returns a description of the game
+func (s *SeaQuest) Manifest() game.Manifest {
+	rewards := []game.RewardInfo{
+		{Event: "enemy shot", Reward: "+1"},
+		{
+			Event:  "surfacing with 6 divers",
+			Reward: "+1 for each tenth of oxygen remaining",
+		},
+	}
+	if s.config.SparseSurfacingReward {
+		rewards = rewards[1:]
+	}
+	if s.config.ShootableDivers {
+		rewards = append(rewards, game.RewardInfo{
+			Event:  "diver shot",
+			Reward: "-1",
+		})
+	}
+
+	var gauges []game.Gauge
+	if !s.config.ScalarGauges {
+		gauges = []game.Gauge{oxygenGauge, diverGauge}
+	}
+
+	trails := []string{"trail"}
+	if s.config.BulletTrails {
+		trails = append(trails, "enemy_bullet_trail")
+	}
+
+	return game.Manifest{
+		Description: "The player controls a submarine which shoots enemy " +
+			"fish and submarines and rescues divers, surfacing to " +
+			"replenish oxygen.",
+		Channels: game.ChannelInfos(s.channels, channelDescriptions),
+		Actions:  game.ActionInfos(s),
+		Rewards:  rewards,
+		Termination: []string{
+			"the submarine is hit by an enemy or enemy bullet",
+			"oxygen runs out",
+			"surfacing with no rescued divers",
+		},
+		Ramping: "each time the player surfaces, enemies spawn more " +
+			"often and move faster",
+		ZOrder: []string{
+			"oxygen_warning", "oxygen_guage", "diver_guage", "trail",
+			"enemy_bullet_trail", "diver", "enemy_fish", "enemy_sub",
+			"enemy_bullet", "friendly_bullet", "sub_back", "sub_front",
+		},
+		Gauges: gauges,
+		Trails: trails,
+	}
+}
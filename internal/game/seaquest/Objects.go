@@ -0,0 +1,51 @@
+package seaquest
+
+import (
+	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/entity"
+)
+
+// Objects returns the player's submarine, followed by the player's
+// bullets, enemy bullets, enemy fish, enemy submarines, and divers,
+// each in the order they spawned. The position of the player's
+// submarine is the position of its front.
+func (s *SeaQuest) Objects() []game.Object {
+	objects := []game.Object{{
+		Type:        "sub",
+		X:           s.agent.x(),
+		Y:           s.agent.y(),
+		Orientation: game.HorizontalOrientation(s.agent.direction()),
+	}}
+
+	add := func(typ string, entities *entity.Manager) {
+		entities.Each(func(_ entity.ID, e entity.Entity) {
+			var sw *swimmer
+			switch e := e.(type) {
+			case *swimmer:
+				sw = e
+			case *submarine:
+				sw = e.swimmer
+			}
+
+			objects = append(objects, game.Object{
+				Type:        typ,
+				X:           sw.x(),
+				Y:           sw.y(),
+				Orientation: game.HorizontalOrientation(sw.direction()),
+			})
+		})
+	}
+	add("friendly_bullet", s.fBullets)
+	add("enemy_bullet", s.eBullets)
+	add("enemy_fish", s.eFish)
+	add("enemy_sub", s.eSubs)
+	add("diver", s.divers)
+
+	return objects
+}
+
+// PlayerPosition returns the column and row of the front of the
+// player's submarine
+func (s *SeaQuest) PlayerPosition() (x, y int) {
+	return s.agent.x(), s.agent.y()
+}
@@ -0,0 +1,65 @@
+package seaquest
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// Params returns the names of the game's parameters
+func (s *SeaQuest) Params() []string {
+	return []string{game.RampingParam, game.SpawnIntervalParam,
+		game.MoveIntervalParam}
+}
+
+// Param returns the current value of the named parameter
+func (s *SeaQuest) Param(name string) (float64, bool) {
+	switch name {
+	case game.RampingParam:
+		return game.BoolParam(s.ramping), true
+
+	case game.SpawnIntervalParam:
+		return float64(s.eSpawnSpeed), true
+
+	case game.MoveIntervalParam:
+		return float64(s.moveSpeed), true
+
+	default:
+		return 0, false
+	}
+}
+
+// SetParam sets the value of the named parameter. The enemy spawn and
+// move intervals must be at least 1 and are rounded to the nearest
+// integer. Enemies which have already spawned keep their move interval
+// until they next move.
+func (s *SeaQuest) SetParam(name string, value float64) error {
+	s.cache.Invalidate()
+	switch name {
+	case game.RampingParam:
+		s.ramping = value != 0
+
+	case game.SpawnIntervalParam:
+		interval, ok := game.IntParam(value, 1)
+		if !ok {
+			return fmt.Errorf("setParam: %v must be at least 1 but got %v",
+				name, value)
+		}
+		s.initSpawnSpeed = interval
+		s.eSpawnSpeed = interval
+		s.eSpawnTimer = game.MinInt(s.eSpawnTimer, interval)
+
+	case game.MoveIntervalParam:
+		interval, ok := game.IntParam(value, 1)
+		if !ok {
+			return fmt.Errorf("setParam: %v must be at least 1 but got %v",
+				name, value)
+		}
+		s.initMoveSpeed = interval
+		s.moveSpeed = interval
+
+	default:
+		return fmt.Errorf("setParam: no such parameter %v", name)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package seaquest
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/entity"
+)
+
+// The methods in this file set the underlying state of the game by
+// hand, so that scripted scenarios can be constructed and stepped.
+
+// Empty removes all bullets, enemies, and divers from the screen
+func (s *SeaQuest) Empty() {
+	s.cache.Invalidate()
+	s.fBullets.Clear()
+	s.eBullets.Clear()
+	s.eFish.Clear()
+	s.eSubs.Clear()
+	s.divers.Clear()
+}
+
+// PlaceSub moves the front of the player's submarine to column x and
+// row y, facing left or right. A submarine placed at the surface is
+// considered to have already surfaced.
+func (s *SeaQuest) PlaceSub(x, y int, o game.Orientation) error {
+	right, err := facing(o)
+	if err != nil {
+		return fmt.Errorf("placeSub: %v", err)
+	}
+	if x < 0 || x >= cols || y < 0 || y > rows-2 {
+		return fmt.Errorf("placeSub: position (%v, %v) out of bounds", x, y)
+	}
+
+	s.cache.Invalidate()
+	s.agent.setX(x)
+	s.agent.setY(y)
+	s.agent.setDirection(right)
+	s.atSurface = y == 0
+	return nil
+}
+
+// SetOxygen sets the oxygen remaining in the player's submarine
+func (s *SeaQuest) SetOxygen(oxygen int) error {
+	if oxygen < 0 || oxygen > maxOxygen {
+		return fmt.Errorf("setOxygen: oxygen %v ∉ [0, %v]", oxygen, maxOxygen)
+	}
+	s.cache.Invalidate()
+	s.agent.setOxygen(oxygen)
+	return nil
+}
+
+// SetDivers sets the number of divers in the player's submarine
+func (s *SeaQuest) SetDivers(divers int) error {
+	if divers < 0 || divers > maxDivers {
+		return fmt.Errorf("setDivers: divers %v ∉ [0, %v]", divers, maxDivers)
+	}
+	s.cache.Invalidate()
+	s.agent.setDivers(divers)
+	return nil
+}
+
+// SetSpawnTimers sets the number of steps until the next enemy and
+// diver spawn
+func (s *SeaQuest) SetSpawnTimers(enemy, diver int) error {
+	if enemy < 0 || diver < 0 {
+		return fmt.Errorf("setSpawnTimers: timers must be non-negative "+
+			"but got %v and %v", enemy, diver)
+	}
+	s.eSpawnTimer = enemy
+	s.dSpawnTimer = diver
+	return nil
+}
+
+// PlaceFriendlyBullet places a bullet fired by the player at column x
+// and row y, travelling left or right
+func (s *SeaQuest) PlaceFriendlyBullet(x, y int, o game.Orientation) error {
+	if err := s.placeSwimmer(s.fBullets, x, y, o, 0); err != nil {
+		return fmt.Errorf("placeFriendlyBullet: %v", err)
+	}
+	return nil
+}
+
+// PlaceEnemyBullet places a bullet fired by an enemy submarine at
+// column x and row y, travelling left or right
+func (s *SeaQuest) PlaceEnemyBullet(x, y int, o game.Orientation) error {
+	if err := s.placeSwimmer(s.eBullets, x, y, o, 0); err != nil {
+		return fmt.Errorf("placeEnemyBullet: %v", err)
+	}
+	return nil
+}
+
+// PlaceFish places an enemy fish at column x and row y, swimming left
+// or right
+func (s *SeaQuest) PlaceFish(x, y int, o game.Orientation) error {
+	if err := s.placeSwimmer(s.eFish, x, y, o, s.moveSpeed); err != nil {
+		return fmt.Errorf("placeFish: %v", err)
+	}
+	return nil
+}
+
+// PlaceDiver places a diver at column x and row y, swimming left or
+// right
+func (s *SeaQuest) PlaceDiver(x, y int, o game.Orientation) error {
+	if err := s.placeSwimmer(s.divers, x, y, o,
+		diverMoveInterval); err != nil {
+		return fmt.Errorf("placeDiver: %v", err)
+	}
+	return nil
+}
+
+// PlaceEnemySub places an enemy submarine at column x and row y,
+// swimming left or right
+func (s *SeaQuest) PlaceEnemySub(x, y int, o game.Orientation) error {
+	right, err := facing(o)
+	if err != nil {
+		return fmt.Errorf("placeEnemySub: %v", err)
+	}
+	if x < 0 || x >= cols || y < 1 || y > rows-2 {
+		return fmt.Errorf("placeEnemySub: position (%v, %v) out of bounds",
+			x, y)
+	}
+
+	s.cache.Invalidate()
+	s.eSubs.Add(recycledSubmarine(s.eSubs).init(x, y, right, s.moveSpeed,
+		enemyShotInterval))
+	return nil
+}
+
+// placeSwimmer adds a swimmer at column x and row y below the surface
+// to m, facing in the horizontal direction o
+func (s *SeaQuest) placeSwimmer(m *entity.Manager, x, y int,
+	o game.Orientation, moveTimer int) error {
+	right, err := facing(o)
+	if err != nil {
+		return err
+	}
+	if x < 0 || x >= cols || y < 1 || y > rows-2 {
+		return fmt.Errorf("position (%v, %v) out of bounds", x, y)
+	}
+
+	s.cache.Invalidate()
+	m.Add(recycledSwimmer(m).init(x, y, right, moveTimer))
+	return nil
+}
+
+// facing returns whether the horizontal orientation o faces right
+func facing(o game.Orientation) (bool, error) {
+	switch o {
+	case game.FacingLeft:
+		return false, nil
+	case game.FacingRight:
+		return true, nil
+	default:
+		return false, fmt.Errorf("cannot face %q", o)
+	}
+}
+
+// RemoveAt removes all bullets, enemies, and divers at column x and
+// row y, and returns the number of objects removed
+func (s *SeaQuest) RemoveAt(x, y int) int {
+	s.cache.Invalidate()
+	n := 0
+	for _, m := range []*entity.Manager{s.fBullets, s.eBullets, s.eFish,
+		s.eSubs, s.divers} {
+		n += m.RemoveAt(x, y)
+	}
+	return n
+}
@@ -26,9 +26,9 @@ package seaquest
 
 import (
 	"fmt"
-	"math/rand"
 
 	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/entity"
 )
 
 const (
@@ -42,6 +42,10 @@ const (
 	initMoveInterval int = 5
 	shotCoolDown     int = 5
 
+	// Enemy spawn and move intervals at which difficulty ramping stops
+	minSpawnSpeed   int = 1
+	minMoveInterval int = 2
+
 	enemyShotInterval int = 10
 	enemyMoveInterval int = 5
 
@@ -49,6 +53,28 @@ const (
 	diverMoveInterval int = 5
 )
 
+var (
+	// oxygenGauge shows the remaining oxygen along the bottom row,
+	// filling one cell for each tenth of the maximum oxygen
+	oxygenGauge = game.Gauge{
+		Channel: "oxygen_guage",
+		Row:     rows - 1,
+		Cells:   cols,
+		Max:     maxOxygen,
+	}
+
+	// diverGauge shows the number of divers carried along the bottom
+	// row, filling leftwards from the second last column, as in MinAtar
+	diverGauge = game.Gauge{
+		Channel: "diver_guage",
+		Row:     rows - 1,
+		Col:     cols - 1 - maxDivers,
+		Cells:   maxDivers,
+		Max:     maxDivers,
+		Reverse: true,
+	}
+)
+
 // SeaQuest implements the SeaQuest game. In this game, the play must
 // control a submarine to rescue as many divers as possible, while
 // destroying or avoiding enemies.
@@ -80,52 +106,233 @@ const (
 // that no entity exists at that position. For example, if a 1 exists
 // at row i and column j of channel 10, this means that a diver is in
 // position (j, i).
+//
+// If the game is configured with ScalarGauges, channels 8 and 9 are
+// removed from the state observation tensor and the remaining channels
+// are shifted down accordingly. If the game is configured with
+// OxygenWarning, an oxygen warning channel is added after the other
+// channels, which is active in every cell while oxygen is low.
 type SeaQuest struct {
 	channels  map[string]int
-	actionMap []rune
-	rng       *rand.Rand
+	actionMap []game.Action
+	rng       *game.Random
 	ramping   bool
+	config    Config
 
 	agent     *player
-	fBullets  []*swimmer
+	fBullets  *entity.Manager // Friendly bullets, each a *swimmer
 	moveSpeed int
 	atSurface bool
 
-	eBullets    []*swimmer
-	eFish       []*swimmer
-	eSubs       []*submarine
+	eBullets    *entity.Manager // Enemy bullets, each a *swimmer
+	eFish       *entity.Manager // Enemy fish, each a *swimmer
+	eSubs       *entity.Manager // Enemy submarines, each a *submarine
 	eSpawnSpeed int
 	eSpawnTimer int
 
-	divers      []*swimmer
+	divers      *entity.Manager // Divers, each a *swimmer
 	dSpawnTimer int
 
 	rampIndex int
+	steps     int // Steps taken in the current episode
 	terminal  bool
+	reason    game.TerminationReason // Why the episode ended, if it has
+
+	// Position of the next enemy spawn, if chosen by an adversary
+	chosenSpawn game.SpawnPosition
+	spawnChosen bool
+
+	// Enemy spawn and move intervals at the start of each episode
+	initSpawnSpeed int
+	initMoveSpeed  int
+
+	rewardEvents []game.RewardEvent // Reward events of the last step
+
+	cache game.StateCache // Cached state observation
+	log   game.Log        // Logs the events of the game
+}
+
+// diverSide is the random draw of the side from which a diver spawns
+const diverSide game.Draw = "diver side"
+
+// Versions describes the changes in behaviour of each version of the
+// game, where element i describes version i+1
+var Versions = []string{
+	"the original port of MinAtar, in which bullets and fish which leave " +
+		"the right edge of the screen are never removed, and submarines " +
+		"which leave the screen fire one last bullet",
+	"bullets and fish which leave the right edge of the screen are " +
+		"removed, and submarines which leave the screen do not fire, as " +
+		"in MinAtar; " +
+		"random numbers are drawn with xoshiro256** rather than " +
+		"math/rand, and sticky actions restart with each episode",
+}
+
+// LatestVersion is the latest version of the game
+const LatestVersion = 2
+
+// Config configures a SeaQuest game. The zero value is the default
+// configuration, which matches MinAtar.
+type Config struct {
+	// Version is the version of the game's behaviour, see Versions. If
+	// 0, the latest version is used.
+	Version int
+
+	// ScalarGauges removes the oxygen and diver gauge channels from
+	// state observations. The gauges are instead exposed as scalars
+	// through the Scalars method.
+	ScalarGauges bool
+
+	// RandomDiverSide makes the side from which divers spawn random,
+	// as in MinAtar, even when the stochasticity level of the game is
+	// lowered with SetStochasticity.
+	RandomDiverSide bool
+
+	// SweptCollisions makes objects which pass through each other in a
+	// single step collide, in addition to objects which end the step
+	// in the same cell. Collisions then no longer depend on the order
+	// in which objects are updated.
+	SweptCollisions bool
+
+	// DriftingFish is the fraction of enemy fish, in [0, 1], which also
+	// drift vertically. A drifting fish moves one row up or down every
+	// DriftInterval times it moves, bouncing between the highest and
+	// lowest rows in which enemies spawn. The trail of a fish is always
+	// at its previous position, so the trail of a drifting fish is
+	// diagonal after it drifts. By default no fish drift, as in
+	// MinAtar.
+	DriftingFish float64
+
+	// DriftInterval is the number of moves between vertical moves of
+	// drifting fish. If zero, drifting fish drift every 2 moves.
+	DriftInterval int
+
+	// SparseSurfacingReward removes the reward for shooting enemies,
+	// so that the only reward is the bonus for surfacing with a full
+	// load of divers
+	SparseSurfacingReward bool
+
+	// SpawnScheduler decides when, where, and what entities spawn, in
+	// place of the enemy and diver spawn timers. Entities are
+	// "enemy_fish", "enemy_sub", or "diver", and spawn in rows 1 to 8.
+	// Enemies are not spawned where the game would not spawn them, such
+	// as in a row taken by an enemy moving in the opposite direction.
+	// If nil, entities spawn randomly.
+	SpawnScheduler game.SpawnScheduler
+
+	// OxygenWarning adds an "oxygen_warning" channel, which is active
+	// in every cell while the player's oxygen is below
+	// OxygenWarningLevel, so that the oxygen running out is not a cliff
+	// for agents which cannot read the oxygen gauge
+	OxygenWarning bool
+
+	// OxygenWarningLevel is the fraction of the maximum oxygen, in
+	// (0, 1], below which the oxygen warning is active. If zero, the
+	// warning is active below a quarter of the maximum oxygen.
+	OxygenWarningLevel float64
+
+	// BulletTrails adds an "enemy_bullet_trail" channel, which is
+	// active in the cell behind each enemy bullet, so that the
+	// direction in which bullets move can be read from a single
+	// observation as it can for enemies and divers
+	BulletTrails bool
+
+	// ShootableDivers makes friendly bullets hit divers, which removes
+	// both the bullet and the diver and gives a reward of -1, so that
+	// shooting carelessly has a cost
+	ShootableDivers bool
+}
+
+// withDefaults returns the configuration with zero values replaced by
+// their defaults
+func (c Config) withDefaults() Config {
+	if c.Version == 0 {
+		c.Version = LatestVersion
+	}
+	if c.DriftInterval == 0 {
+		c.DriftInterval = 2
+	}
+	if c.OxygenWarningLevel == 0 {
+		c.OxygenWarningLevel = 0.25
+	}
+	return c
+}
+
+// validate returns an error if the configuration is invalid
+func (c Config) validate() error {
+	if c.Version < 0 || c.Version > LatestVersion {
+		return fmt.Errorf("version %v ∉ [1, %v]", c.Version, LatestVersion)
+	}
+	if c.DriftingFish < 0 || c.DriftingFish > 1 {
+		return fmt.Errorf("drifting fish fraction %v ∉ [0, 1]",
+			c.DriftingFish)
+	}
+	if c.DriftInterval < 0 {
+		return fmt.Errorf("drift interval must be non-negative but got %v",
+			c.DriftInterval)
+	}
+	if c.OxygenWarningLevel < 0 || c.OxygenWarningLevel > 1 {
+		return fmt.Errorf("oxygen warning level %v ∉ (0, 1]",
+			c.OxygenWarningLevel)
+	}
+	return nil
 }
 
 // New returns a new SeaQuest game
 func New(ramping bool, seed int64) (game.Game, error) {
-	channels := map[string]int{
-		"sub_front":       0,
-		"sub_back":        1,
-		"friendly_bullet": 2,
-		"trail":           3,
-		"enemy_bullet":    4,
-		"enemy_fish":      5,
-		"enemy_sub":       6,
-		"oxygen_guage":    7,
-		"diver_guage":     8,
-		"diver":           9,
-	}
-	actionMap := []rune{'n', 'l', 'u', 'r', 'd', 'f'}
-	rng := rand.New(rand.NewSource(seed))
+	return NewWithConfig(ramping, seed, Config{})
+}
+
+// NewWithConfig returns a new SeaQuest game with the given
+// configuration
+func NewWithConfig(ramping bool, seed int64, config Config) (game.Game,
+	error) {
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("newWithConfig: %v", err)
+	}
+
+	channelNames := []string{
+		"sub_front",
+		"sub_back",
+		"friendly_bullet",
+		"trail",
+		"enemy_bullet",
+		"enemy_fish",
+		"enemy_sub",
+		oxygenGauge.Channel,
+		diverGauge.Channel,
+		"diver",
+	}
+	channels := make(map[string]int, len(channelNames))
+	for _, name := range channelNames {
+		if config.ScalarGauges &&
+			(name == oxygenGauge.Channel || name == diverGauge.Channel) {
+			continue
+		}
+		channels[name] = len(channels)
+	}
+	if config.OxygenWarning {
+		channels["oxygen_warning"] = len(channels)
+	}
+	if config.BulletTrails {
+		channels["enemy_bullet_trail"] = len(channels)
+	}
+	actionMap := []game.Action{game.NoOp, game.Left, game.Up, game.Right,
+		game.Down, game.Fire}
+	rng := game.NewVersionedRandom(seed, config.withDefaults().Version)
+	if config.RandomDiverSide {
+		rng.SetAlwaysRandom(diverSide)
+	}
 
 	seaquest := &SeaQuest{
 		channels:  channels,
 		actionMap: actionMap,
 		rng:       rng,
 		ramping:   ramping,
+		config:    config.withDefaults(),
+
+		initSpawnSpeed: initSpawnSpeed,
+		initMoveSpeed:  initMoveInterval,
 	}
 	seaquest.Reset()
 
@@ -134,20 +341,49 @@ func New(ramping bool, seed int64) (game.Game, error) {
 
 // Reset resets the environment to some starting state
 func (s *SeaQuest) Reset() {
-	s.agent = newPlayer(5, 0, false, initMoveInterval, 0, maxOxygen)
-
-	s.fBullets = make([]*swimmer, 0, 10)
-	s.eBullets = make([]*swimmer, 0, 10)
-	s.eFish = make([]*swimmer, 0, 10)
-	s.eSubs = make([]*submarine, 0, 10)
-	s.divers = make([]*swimmer, 0, 10)
-	s.eSpawnSpeed = initSpawnSpeed
+	s.cache.Invalidate()
+	s.rewardEvents = s.rewardEvents[:0]
+	if s.agent == nil {
+		s.agent = newPlayer(5, 0, false, initMoveInterval, 0, maxOxygen)
+	} else {
+		s.agent.init(5, 0, false, initMoveInterval, 0, maxOxygen)
+	}
+
+	if s.fBullets == nil {
+		s.fBullets = entity.NewManager()
+		s.eBullets = entity.NewManager()
+		s.eFish = entity.NewManager()
+		s.eSubs = entity.NewManager()
+		s.divers = entity.NewManager()
+	} else {
+		s.fBullets.Clear()
+		s.eBullets.Clear()
+		s.eFish.Clear()
+		s.eSubs.Clear()
+		s.divers.Clear()
+	}
+	s.eSpawnSpeed = s.initSpawnSpeed
 	s.eSpawnTimer = s.eSpawnSpeed
 	s.dSpawnTimer = diverSpawnSpeed
-	s.moveSpeed = initMoveInterval
+	s.moveSpeed = s.initMoveSpeed
 	s.rampIndex = 0
+	s.steps = 0
+	s.spawnChosen = false
 	s.atSurface = true
 	s.terminal = false
+	s.reason = game.NotTerminated
+}
+
+// SoftReset resets the game to a starting state, but keeps the current
+// difficulty ramp
+func (s *SeaQuest) SoftReset() {
+	spawnSpeed, moveSpeed, rampIndex := s.eSpawnSpeed, s.moveSpeed,
+		s.rampIndex
+	s.Reset()
+
+	s.eSpawnSpeed, s.eSpawnTimer = spawnSpeed, spawnSpeed
+	s.moveSpeed = moveSpeed
+	s.rampIndex = rampIndex
 }
 
 // Act takes on environmental step given some action a and returns the
@@ -159,69 +395,92 @@ func (s *SeaQuest) Act(a int) (float64, bool, error) {
 			a, len(s.actionMap))
 	}
 
+	s.cache.Invalidate()
+	s.rewardEvents = s.rewardEvents[:0]
+	chosen := s.spawnChosen
+	s.spawnChosen = false
 	reward := 0.
 	if s.terminal {
 		return reward, s.terminal, nil
 	}
 
-	// Spawn enemy if timer is up
-	if s.eSpawnTimer == 0 {
-		s.spawnEnemy()
-		s.eSpawnTimer = s.eSpawnSpeed
+	if s.config.SpawnScheduler != nil {
+		// Spawn entities as scheduled
+		for _, spawn := range s.config.SpawnScheduler.Spawns(s.steps) {
+			if err := s.spawn(spawn); err != nil {
+				return -1, s.terminal, fmt.Errorf("act: %v", err)
+			}
+		}
+	} else {
+		// Spawn enemy if timer is up
+		if s.eSpawnTimer == 0 {
+			s.spawnEnemy(chosen)
+			s.eSpawnTimer = s.eSpawnSpeed
+		}
+
+		// Spawn diver if timer is up
+		if s.dSpawnTimer == 0 {
+			s.spawnDiver()
+			s.dSpawnTimer = diverSpawnSpeed
+		}
 	}
+	s.steps++
 
-	// Spawn diver if timer is up
-	if s.dSpawnTimer == 0 {
-		s.spawnDiver()
-		s.dSpawnTimer = diverSpawnSpeed
+	var prev positions
+	if s.config.SweptCollisions {
+		prev = s.positions()
 	}
 
 	// Resolve action
 	action := s.actionMap[a]
 	switch action {
-	case 'f':
+	case game.Fire:
 		if s.agent.canShoot() {
-			s.fBullets = append(s.fBullets, newBullet(s.agent.x(),
-				s.agent.y(), s.agent.orientedRight()))
+			s.fBullets.Add(recycledSwimmer(s.fBullets).init(s.agent.x(),
+				s.agent.y(), s.agent.orientedRight(), 0))
 			s.agent.setShotTimer(shotCoolDown)
 		}
 
-	case 'l':
+	case game.Left:
 		s.agent.moveLeft()
 
-	case 'r':
+	case game.Right:
 		s.agent.moveRight()
 
-	case 'u':
+	case game.Up:
 		s.agent.moveUp()
 
-	case 'd':
+	case game.Down:
 		s.agent.moveDown()
 	}
 
 	// Update friendly bullets
-	for i := len(s.fBullets) - 1; i > -1; i-- {
-		reward += s.updateFriendlyBullet(i)
-	}
+	s.fBullets.EachReverse(func(id entity.ID, e entity.Entity) {
+		reward += s.updateFriendlyBullet(id, e.(*swimmer))
+	})
 
 	// Update divers
-	for i := len(s.divers) - 1; i > -1; i-- {
-		s.updateDiver(i)
-	}
+	s.divers.EachReverse(func(id entity.ID, e entity.Entity) {
+		reward += s.updateDiver(id, e.(*swimmer))
+	})
 
 	// Update enemy submarines
-	for i := len(s.eSubs) - 1; i > -1; i-- {
-		reward += s.updateEnemySubmarine(i)
-	}
+	s.eSubs.EachReverse(func(id entity.ID, e entity.Entity) {
+		reward += s.updateEnemySubmarine(id, e.(*submarine))
+	})
 
 	// Update enemy bullets
-	for i := len(s.eBullets) - 1; i > -1; i-- {
-		s.updateEnemyBullet(i)
-	}
+	s.eBullets.EachReverse(func(id entity.ID, e entity.Entity) {
+		s.updateEnemyBullet(id, e.(*swimmer))
+	})
 
 	// Update enemy fish
-	for i := len(s.eFish) - 1; i > -1; i-- {
-		reward += s.updateEnemyFish(i)
+	s.eFish.EachReverse(func(id entity.ID, e entity.Entity) {
+		reward += s.updateEnemyFish(id, e.(*swimmer))
+	})
+
+	if s.config.SweptCollisions {
+		reward += s.resolveSwaps(prev)
 	}
 
 	// Update timers
@@ -238,7 +497,7 @@ func (s *SeaQuest) Act(a int) (float64, bool, error) {
 	}
 
 	if s.agent.oxygen() < 0 {
-		s.terminal = true
+		s.terminate(game.OutOfOxygen)
 	}
 
 	if s.agent.y() > 0 {
@@ -246,7 +505,7 @@ func (s *SeaQuest) Act(a int) (float64, bool, error) {
 		s.atSurface = false
 	} else if !s.atSurface {
 		if s.agent.divers() == 0 {
-			s.terminal = true
+			s.terminate(game.SurfacedWithoutDivers)
 		} else {
 			reward += s.surface()
 		}
@@ -257,8 +516,11 @@ func (s *SeaQuest) Act(a int) (float64, bool, error) {
 
 // State returns the current state observation
 func (s *SeaQuest) State() ([]float64, error) {
-	state := make([]float64, rows*cols*s.NChannels())
+	return s.cache.State(rows*cols*s.NChannels(), s.observe)
+}
 
+// observe fills the zeroed slice state with the state observation
+func (s *SeaQuest) observe(state []float64) error {
 	state[rows*cols*s.channels["sub_front"]+cols*s.agent.y()+s.agent.x()] = 1.0
 
 	var backX int
@@ -269,32 +531,51 @@ func (s *SeaQuest) State() ([]float64, error) {
 	}
 	state[rows*cols*s.channels["sub_back"]+cols*s.agent.y()+backX] = 1.0
 
-	// Fill oxygen guage
-	for i := 0; i < s.agent.oxygen()*10/maxOxygen; i++ {
-		state[rows*cols*s.channels["oxygen_guage"]+(rows-1)*cols+i] = 1.0
+	if !s.config.ScalarGauges {
+		oxygen := state[rows*cols*s.channels[oxygenGauge.Channel]:]
+		oxygenGauge.Draw(oxygen[:rows*cols], cols, s.agent.oxygen())
+
+		divers := state[rows*cols*s.channels[diverGauge.Channel]:]
+		diverGauge.Draw(divers[:rows*cols], cols, s.agent.divers())
 	}
 
-	// Add the diver guage
-	for i := (rows - 1) - s.agent.divers(); i < (rows - 1); i++ {
-		state[rows*cols*s.channels["diver_guage"]+(rows-1)*cols+i] = 1.0
+	if s.oxygenLow() {
+		warning := state[rows*cols*s.channels["oxygen_warning"]:]
+		for i := 0; i < rows*cols; i++ {
+			warning[i] = 1.0
+		}
 	}
 
 	// Set friendly bullets
-	for _, bullet := range s.fBullets {
-		state[rows*cols*s.channels["friendly_bullet"]+bullet.y()*cols+
-			bullet.x()] = 1.0
-	}
+	s.fBullets.Each(func(_ entity.ID, e entity.Entity) {
+		x, y := e.Position()
+		draw(state, s.channels["friendly_bullet"], x, y)
+	})
 
 	// Set enemy bullets
-	for _, bullet := range s.eBullets {
-		state[rows*cols*s.channels["enemy_bullet"]+bullet.y()*cols+
-			bullet.x()] = 1.0
-	}
+	s.eBullets.Each(func(_ entity.ID, e entity.Entity) {
+		bullet := e.(*swimmer)
+		x, y := bullet.x(), bullet.y()
+		draw(state, s.channels["enemy_bullet"], x, y)
+		if !s.config.BulletTrails {
+			return
+		}
+
+		// Set the trail behind the bullet, denoting direction of movement
+		backX := x + 1
+		if bullet.orientedRight() {
+			backX = x - 1
+		}
+		if backX >= 0 && backX <= cols-1 {
+			trail := rows * cols * s.channels["enemy_bullet_trail"]
+			state[trail+y*cols+backX] = 1.0
+		}
+	})
 
 	// Set the fish
-	for _, fish := range s.eFish {
-		state[rows*cols*s.channels["enemy_fish"]+fish.y()*cols+
-			fish.x()] = 1.0
+	s.eFish.Each(func(_ entity.ID, e entity.Entity) {
+		fish := e.(*swimmer)
+		draw(state, s.channels["enemy_fish"], fish.x(), fish.y())
 
 		// Set the trail behind fish, denoting direction of movement
 		var backX int
@@ -303,15 +584,17 @@ func (s *SeaQuest) State() ([]float64, error) {
 		} else {
 			backX = fish.x() + 1
 		}
+		backY := fish.y() - fish.lastDrift
 
 		if backX >= 0 && backX <= rows-1 {
-			state[rows*cols*s.channels["trail"]+fish.y()*cols+backX] = 1.0
+			state[rows*cols*s.channels["trail"]+backY*cols+backX] = 1.0
 		}
-	}
+	})
 
 	// Set the submarines
-	for _, sub := range s.eSubs {
-		state[rows*cols*s.channels["enemy_sub"]+cols*sub.y()+sub.x()] = 1.0
+	s.eSubs.Each(func(_ entity.ID, e entity.Entity) {
+		sub := e.(*submarine)
+		draw(state, s.channels["enemy_sub"], sub.x(), sub.y())
 
 		// Set the trail behind sub, denoting direction of movement
 		var backX int
@@ -324,10 +607,11 @@ func (s *SeaQuest) State() ([]float64, error) {
 		if backX >= 0 && backX <= rows-1 {
 			state[rows*cols*s.channels["trail"]+sub.y()*cols+backX] = 1.0
 		}
-	}
+	})
 
 	// Set the divers
-	for _, diver := range s.divers {
+	s.divers.Each(func(_ entity.ID, e entity.Entity) {
+		diver := e.(*swimmer)
 		state[rows*cols*s.channels["diver"]+cols*diver.y()+diver.x()] = 1.0
 
 		// Set the trail behind the diver, denoting direction of movement
@@ -341,14 +625,14 @@ func (s *SeaQuest) State() ([]float64, error) {
 		if backX >= 0 && backX <= rows-1 {
 			state[rows*cols*s.channels["trail"]+diver.y()*cols+backX] = 1.0
 		}
-	}
+	})
 
-	return state, nil
+	return nil
 }
 
-// StateShape returns the shape of state observations
-func (s *SeaQuest) StateShape() []int {
-	return []int{s.NChannels(), rows, cols}
+// Shape returns the shape of state observations
+func (s *SeaQuest) Shape() game.Shape {
+	return game.Shape{Channels: s.NChannels(), Rows: rows, Cols: cols}
 }
 
 // MinimalActionSet returns the actions that actually affect the game
@@ -361,11 +645,139 @@ func (s *SeaQuest) MinimalActionSet() []int {
 	return minActions
 }
 
+// ActionEffect returns a description of the effect of action a
+func (s *SeaQuest) ActionEffect(a game.Action) string {
+	effects := map[game.Action]string{
+		game.NoOp:  "do nothing",
+		game.Left:  "move the submarine left and face left",
+		game.Up:    "move the submarine up",
+		game.Right: "move the submarine right and face right",
+		game.Down:  "move the submarine down",
+		game.Fire:  "fire a bullet from the front of the submarine",
+	}
+	if effect, ok := effects[a]; ok {
+		return effect
+	}
+	return "no effect"
+}
+
 // DifficultyRamp returns the current difficulty level of the game
 func (s *SeaQuest) DifficultyRamp() int {
 	return s.rampIndex
 }
 
+// RewardRange returns the minimum and maximum reward that can be
+// received on a single step. The maximum is attained by surfacing with
+// a full oxygen gauge and a full load of divers (10), while each of the
+// friendly bullets on the screen strikes two enemies on the same step.
+// With Config.ShootableDivers, the minimum is attained when each of the
+// friendly bullets strikes a diver on the same step.
+func (s *SeaQuest) RewardRange() (min, max float64) {
+	maxBullets := (cols + shotCoolDown - 1) / shotCoolDown
+	if s.config.ShootableDivers {
+		min = -float64(maxBullets)
+	}
+	if s.config.SparseSurfacingReward {
+		return min, 10
+	}
+	return min, float64(10 + 2*maxBullets)
+}
+
+// Seed seeds the random number generator of the game. The game is not
+// reset.
+func (s *SeaQuest) Seed(seed int64) {
+	s.rng.Seed(seed)
+}
+
+// Clone returns a deep copy of the game. The copy behaves identically
+// to the original given the same actions, but does not share any state
+// with it.
+func (s *SeaQuest) Clone() game.Game {
+	clone := *s
+	clone.rng = s.rng.Clone()
+	clone.cache = game.StateCache{}
+
+	sub := *s.agent.submarine
+	swimmer := *sub.swimmer
+	sub.swimmer = &swimmer
+	agent := *s.agent
+	agent.submarine = &sub
+	clone.agent = &agent
+
+	clone.fBullets = s.fBullets.Clone(cloneSwimmer)
+	clone.eBullets = s.eBullets.Clone(cloneSwimmer)
+	clone.eFish = s.eFish.Clone(cloneSwimmer)
+	clone.divers = s.divers.Clone(cloneSwimmer)
+	clone.eSubs = s.eSubs.Clone(cloneSubmarine)
+
+	clone.rewardEvents = append([]game.RewardEvent(nil),
+		s.rewardEvents...)
+	return &clone
+}
+
+// SetStochasticity sets the fraction of random draws made by the game
+// which are random rather than deterministic
+func (s *SeaQuest) SetStochasticity(level float64) {
+	s.rng.SetStochasticity(level)
+}
+
+// StateHash returns a hash of the underlying game state, including
+// all timers. Two games with the same hash will, with high
+// probability, behave identically given the same actions and random
+// numbers.
+func (s *SeaQuest) StateHash() uint64 {
+	h := game.NewHasher()
+	hashSwimmer := func(sw *swimmer) {
+		h.Int(sw.x(), sw.y(), sw.direction(), sw.moveTimer)
+	}
+
+	hashSwimmer(s.agent.swimmer)
+	h.Int(s.agent.shotTimer, s.agent.oxygen(), s.agent.divers())
+
+	hashSwimmers := func(m *entity.Manager) {
+		h.Int(m.Len())
+		m.Each(func(_ entity.ID, e entity.Entity) {
+			hashSwimmer(e.(*swimmer))
+		})
+	}
+
+	hashSwimmers(s.fBullets)
+	hashSwimmers(s.eBullets)
+	hashSwimmers(s.eFish)
+	if s.config.DriftingFish > 0 {
+		s.eFish.Each(func(_ entity.ID, e entity.Entity) {
+			fish := e.(*swimmer)
+			h.Int(fish.driftDirection, fish.driftTimer, fish.lastDrift)
+		})
+	}
+	h.Int(s.eSubs.Len())
+	s.eSubs.Each(func(_ entity.ID, e entity.Entity) {
+		sub := e.(*submarine)
+		hashSwimmer(sub.swimmer)
+		h.Int(sub.shotTimer)
+	})
+	hashSwimmers(s.divers)
+
+	h.Int(s.moveSpeed, s.eSpawnSpeed, s.eSpawnTimer, s.dSpawnTimer,
+		s.rampIndex)
+	h.Bool(s.atSurface)
+	h.Bool(s.terminal)
+
+	return h.Sum()
+}
+
+// RNGHash returns a hash of the state of the game's random number
+// generator, which is not included in StateHash
+func (s *SeaQuest) RNGHash() uint64 {
+	return s.rng.Hash()
+}
+
+// RewardEvents returns the events which resulted in rewards on the
+// last step. The returned slice is only valid until the next step.
+func (s *SeaQuest) RewardEvents() []game.RewardEvent {
+	return s.rewardEvents
+}
+
 // Channel returns the state observation at channel i
 func (s *SeaQuest) Channel(i int) ([]float64, error) {
 	if i >= s.NChannels() {
@@ -389,6 +801,36 @@ func (s *SeaQuest) NChannels() int {
 	return len(s.channels)
 }
 
+// oxygenLow returns whether the oxygen warning is active
+func (s *SeaQuest) oxygenLow() bool {
+	return s.config.OxygenWarning && float64(s.agent.oxygen()) <
+		s.config.OxygenWarningLevel*float64(maxOxygen)
+}
+
+// Scalars returns the scalar observations of the game. If the game
+// was configured with ScalarGauges, these are the remaining oxygen and
+// the number of divers carried, each normalized to [0, 1]. Otherwise,
+// no scalar observations exist and Scalars returns nil.
+func (s *SeaQuest) Scalars() []float64 {
+	if !s.config.ScalarGauges {
+		return nil
+	}
+
+	return []float64{
+		oxygenGauge.Fraction(s.agent.oxygen()),
+		diverGauge.Fraction(s.agent.divers()),
+	}
+}
+
+// ScalarNames returns the names of the scalar observations returned by
+// Scalars
+func (s *SeaQuest) ScalarNames() []string {
+	if !s.config.ScalarGauges {
+		return nil
+	}
+	return []string{"oxygen", "divers"}
+}
+
 // surface performs the housekeeping when the agent reaches the surface
 // of the water, and returns the reward for reaching the surface.
 func (s *SeaQuest) surface() float64 {
@@ -397,29 +839,114 @@ func (s *SeaQuest) surface() float64 {
 
 	if s.agent.divers() == maxDivers {
 		s.agent.setDivers(0)
-		reward = float64(s.agent.oxygen() * 10 / maxOxygen)
+
+		// The bonus is one point per filled cell of the oxygen gauge,
+		// each of which is recorded as a separate reward event
+		from, to := oxygenGauge.Span(s.agent.oxygen())
+		for x := from; x < to; x++ {
+			s.rewardEvents = append(s.rewardEvents, game.RewardEvent{
+				Type:   game.Bonus,
+				Amount: 1,
+				X:      x,
+				Y:      oxygenGauge.Row,
+				Entity: "oxygen",
+			})
+		}
+		reward = float64(to - from)
 	} else {
 		reward = 0
 		s.agent.setOxygen(maxOxygen)
 		s.agent.decrementDivers()
 
-		if s.ramping && (s.eSpawnSpeed > 1 || s.moveSpeed > 2) {
-			if s.moveSpeed > 2 && s.rampIndex%2 == 1 {
+		if s.ramping && (s.eSpawnSpeed > minSpawnSpeed ||
+			s.moveSpeed > minMoveInterval) {
+			if s.moveSpeed > minMoveInterval && s.rampIndex%2 == 1 {
 				s.moveSpeed--
 			}
-			if s.eSpawnSpeed > 1 {
+			if s.eSpawnSpeed > minSpawnSpeed {
 				s.eSpawnSpeed--
 			}
 			s.rampIndex++
+			if s.log.On() {
+				s.log.Debugf("seaquest: difficulty ramp %v: spawn "+
+					"interval %v, move interval %v", s.rampIndex,
+					s.eSpawnSpeed, s.moveSpeed)
+			}
 		}
 	}
 	return reward
 }
 
-// spawnEnemy spawns an enemy into the game at a random position
-func (s *SeaQuest) spawnEnemy() {
-	lr := s.rng.Intn(2)
-	isSub := s.rng.Intn(3) == 0
+// cloneSwimmer returns a deep copy of a *swimmer entity
+func cloneSwimmer(e entity.Entity) entity.Entity {
+	s := *e.(*swimmer)
+	return &s
+}
+
+// cloneSubmarine returns a deep copy of a *submarine entity
+func cloneSubmarine(e entity.Entity) entity.Entity {
+	sub := *e.(*submarine)
+	swimmer := *sub.swimmer
+	sub.swimmer = &swimmer
+	return &sub
+}
+
+// shootEnemy records the reward event of the player shooting an enemy
+// of the given kind at position (x, y), and returns the reward for
+// shooting it
+func (s *SeaQuest) shootEnemy(x, y int, kind string) float64 {
+	if s.log.On() {
+		s.log.Debugf("seaquest: bullet collided with %v at (%v, %v)", kind,
+			x, y)
+	}
+	if s.config.SparseSurfacingReward {
+		return 0
+	}
+
+	s.rewardEvents = append(s.rewardEvents, game.RewardEvent{
+		Type:   game.Destroy,
+		Amount: 1,
+		X:      x,
+		Y:      y,
+		Entity: kind,
+	})
+	return 1
+}
+
+// shootDiver records the penalty for shooting a diver at (x, y) and
+// returns it
+func (s *SeaQuest) shootDiver(x, y int) float64 {
+	s.rewardEvents = append(s.rewardEvents, game.RewardEvent{
+		Type:   game.Destroy,
+		Amount: -1,
+		X:      x,
+		Y:      y,
+		Entity: "diver",
+	})
+	return -1
+}
+
+// shoot returns the reward for shooting an entity of the given kind at
+// (x, y), which is either an enemy or, with Config.ShootableDivers, a
+// diver
+func (s *SeaQuest) shoot(x, y int, kind string) float64 {
+	if kind == "diver" {
+		return s.shootDiver(x, y)
+	}
+	return s.shootEnemy(x, y, kind)
+}
+
+// spawnEnemy spawns an enemy into the game. If chosen, the enemy
+// spawns at the position chosen by an adversary, and otherwise at a
+// random position.
+func (s *SeaQuest) spawnEnemy(chosen bool) {
+	var lr int
+	if !chosen {
+		lr = s.rng.Intn("enemy side", 2)
+	} else if s.chosenSpawn.Right {
+		lr = 1
+	}
+	isSub := s.rng.Intn("enemy submarine", 3) == 0
 
 	var x int
 	if lr == 1 {
@@ -428,38 +955,73 @@ func (s *SeaQuest) spawnEnemy() {
 		x = rows - 1
 	}
 
-	y := s.rng.Intn(rows-2) + 1
+	var y int
+	if chosen {
+		y = s.chosenSpawn.Row
+	} else {
+		y = s.rng.Intn("enemy row", rows-2) + 1
+	}
+	s.addEnemy(x, y, lr == 1, isSub)
+}
+
+// blocked returns whether an enemy cannot spawn in row y moving in
+// the given direction, because the row is taken by another enemy
+func (s *SeaQuest) blocked(y int, orientedRight bool) bool {
+	lr := 0
+	if orientedRight {
+		lr = 1
+	}
 
-	// Don't spawn in a row already taken an enemy with opposite direction
-	// to the enemy fish currently in the row
-	for _, enemy := range s.eFish {
-		if enemy.y() == y && enemy.direction() != lr {
-			// Enemy has same row (y-position) and opposite direction
-			// as current enemy in that row
-			return
+	// Don't spawn in a row already taken by an enemy with opposite
+	// direction to the new enemy
+	opposite := func(e entity.Entity) bool {
+		_, enemyY := e.Position()
+		var direction int
+		switch enemy := e.(type) {
+		case *swimmer:
+			direction = enemy.direction()
+		case *submarine:
+			direction = enemy.direction()
 		}
+		return enemyY == y && direction != lr
 	}
-	for _, enemy := range s.eSubs {
-		if enemy.y() == y && enemy.direction() != lr {
-			// Enemy has same row (y-position) and opposite direction
-			// to the enemy submarine currently in that row
-			return
+	return s.eFish.Any(opposite) || s.eSubs.Any(opposite)
+}
+
+// addEnemy adds an enemy submarine or fish at column x and row y,
+// unless the row is taken by another enemy which blocks the spawn
+func (s *SeaQuest) addEnemy(x, y int, orientedRight, isSub bool) {
+	if s.blocked(y, orientedRight) {
+		return
+	}
+
+	if s.log.On() {
+		kind := "enemy_fish"
+		if isSub {
+			kind = "enemy_sub"
 		}
+		s.log.Debugf("seaquest: spawned %v at (%v, %v)", kind, x, y)
 	}
 
 	// Spawn enemy
-	orientedRight := lr == 1
 	if isSub {
-		s.eSubs = append(s.eSubs, newSubmarine(x, y, orientedRight,
+		s.eSubs.Add(recycledSubmarine(s.eSubs).init(x, y, orientedRight,
 			s.moveSpeed, enemyShotInterval))
 	} else {
-		s.eFish = append(s.eFish, newSwimmer(x, y, orientedRight, s.moveSpeed))
+		fish := recycledSwimmer(s.eFish).init(x, y, orientedRight,
+			s.moveSpeed)
+		if s.config.DriftingFish > 0 &&
+			s.rng.Float64("fish drift") < s.config.DriftingFish {
+			up := s.rng.Intn("fish drift direction", 2) == 0
+			fish.setDrift(up, s.config.DriftInterval)
+		}
+		s.eFish.Add(fish)
 	}
 }
 
 // spawnDiver spawns a diver into the game at a random position
 func (s *SeaQuest) spawnDiver() {
-	lr := s.rng.Intn(2)
+	lr := s.rng.Intn(diverSide, 2)
 
 	var x int
 	if lr == 1 {
@@ -468,80 +1030,119 @@ func (s *SeaQuest) spawnDiver() {
 		x = rows - 1
 	}
 
-	y := s.rng.Intn(rows-2) + 1
+	y := s.rng.Intn("diver row", rows-2) + 1
 
 	orientedRight := lr == 1
-	s.divers = append(s.divers, newSwimmer(x, y, orientedRight,
+	s.divers.Add(recycledSwimmer(s.divers).init(x, y, orientedRight,
 		diverMoveInterval))
+	if s.log.On() {
+		s.log.Debugf("seaquest: spawned diver at (%v, %v)", x, y)
+	}
 }
 
-// updateFriendlyBullet updates the friendly bullet at location i in
-// the s.fBullet slice and returns the reward for shooting any enemies.
-func (s *SeaQuest) updateFriendlyBullet(i int) float64 {
-	bullet := s.fBullets[i]
+// spawn spawns the entity chosen by the spawn scheduler
+func (s *SeaQuest) spawn(sp game.Spawn) error {
+	if sp.Row < 1 || sp.Row > rows-2 {
+		return fmt.Errorf("spawn: row %v ∉ [1, %v]", sp.Row, rows-2)
+	}
+
+	x := rows - 1
+	if sp.Right {
+		x = 0
+	}
+	switch sp.Kind {
+	case "enemy_fish", "enemy_sub":
+		s.addEnemy(x, sp.Row, sp.Right, sp.Kind == "enemy_sub")
+	case "diver":
+		s.divers.Add(recycledSwimmer(s.divers).init(x, sp.Row, sp.Right,
+			diverMoveInterval))
+		if s.log.On() {
+			s.log.Debugf("seaquest: spawned diver at (%v, %v)", x, sp.Row)
+		}
+	default:
+		return fmt.Errorf("spawn: cannot spawn %q", sp.Kind)
+	}
+	return nil
+}
+
+// updateFriendlyBullet updates the friendly bullet with the given ID
+// and returns the reward for shooting any enemies.
+func (s *SeaQuest) updateFriendlyBullet(id entity.ID, bullet *swimmer) float64 {
 	reward := 0.
 
 	// Move bullet
 	bullet.move()
 
 	// Remove the bullet if it leaves the screen
-	if bullet.x() < 0 || bullet.y() > rows-1 {
-		s.fBullets = append(s.fBullets[:i], s.fBullets[i+1:]...)
-	} else {
-		removed := false
-		// Check if the player shot any enemy fishes
-		for i, fish := range s.eFish {
-			if bullet.x() == fish.x() && bullet.y() == fish.y() {
-				// Remove fish if bullet hit it
-				s.eFish = append(s.eFish[:i], s.eFish[i+1:]...)
-				reward += 1
-				removed = true
-				break
-			}
-		}
-
-		if !removed {
-			// Check if the player shot any enemy submarines
-			for i, sub := range s.eSubs {
-				if bullet.x() == sub.x() && bullet.y() == sub.y() {
-					// Remove fish if bullet hit it
-					s.eSubs = append(s.eSubs[:i], s.eSubs[i+1:]...)
-					reward += 1
-					removed = true
-					break
-				}
-			}
+	if s.leftScreen(bullet) {
+		s.fBullets.Remove(id)
+	} else if fishID, _, ok := s.eFish.At(bullet.Position()); ok {
+		// Remove fish if bullet hit it
+		s.eFish.Remove(fishID)
+		reward += s.shootEnemy(bullet.x(), bullet.y(), "enemy_fish")
+	} else if subID, _, ok := s.eSubs.At(bullet.Position()); ok {
+		// Remove submarine if bullet hit it
+		s.eSubs.Remove(subID)
+		reward += s.shootEnemy(bullet.x(), bullet.y(), "enemy_sub")
+	} else if s.config.ShootableDivers {
+		if diverID, _, ok := s.divers.At(bullet.Position()); ok {
+			// Remove diver and bullet if bullet hit the diver
+			s.divers.Remove(diverID)
+			s.fBullets.Remove(id)
+			reward += s.shootDiver(bullet.x(), bullet.y())
 		}
 	}
 	return reward
 }
 
-// updateEnemyBullet updates the enemy bullet at location i in the
-// s.eBullets slice and determines if the game has ended due to the
-// agent being shot
-func (s *SeaQuest) updateEnemyBullet(i int) {
-	bullet := s.eBullets[i]
-	if bullet.x() == s.agent.x() && bullet.y() == s.agent.y() {
-		s.terminal = true
+// updateEnemyBullet updates the enemy bullet with the given ID and
+// determines if the game has ended due to the agent being shot
+func (s *SeaQuest) updateEnemyBullet(id entity.ID, bullet *swimmer) {
+	if game.Collides(bullet, s.agent) {
+		s.terminate(game.HitByBullet)
 	}
 
 	// Move bullet
 	bullet.move()
 
 	// Remove bullet if travelling off screen
-	if bullet.x() < 0 || bullet.y() > rows-1 {
-		s.eBullets = append(s.eBullets[:i], s.eBullets[i+1:]...)
-	} else if bullet.x() == s.agent.x() && bullet.y() == s.agent.y() {
-		s.terminal = true
+	if s.leftScreen(bullet) {
+		s.eBullets.Remove(id)
+	} else if game.Collides(bullet, s.agent) {
+		s.terminate(game.HitByBullet)
+	}
+}
+
+// leftScreen returns whether the bullet or fish w has left the screen.
+// Version 1 checked the row of w rather than its column against the
+// right edge of the screen, so that bullets and fish moving right were
+// never removed.
+func (s *SeaQuest) leftScreen(w *swimmer) bool {
+	if s.config.Version == 1 {
+		return w.x() < 0 || w.y() > rows-1
+	}
+	return w.x() < 0 || w.x() > cols-1
+}
+
+// draw sets the cell at column x and row y of channel ch of state. In
+// version 1, objects which have left the screen are kept, and are drawn
+// as the original port drew them, into the cells which follow their row
+// in state. Objects which would be drawn past the end of state, for
+// which the original port panicked, are not drawn.
+func draw(state []float64, ch, x, y int) {
+	if i := rows*cols*ch + y*cols + x; i >= 0 && i < len(state) {
+		state[i] = 1.0
 	}
 }
 
-// updateDiver updates the diver at position i in the s.divers slice
-func (s *SeaQuest) updateDiver(i int) {
-	diver := s.divers[i]
-	if diver.x() == s.agent.x() && diver.y() == s.agent.y() &&
+// updateDiver updates the diver with the given ID and returns the
+// reward if the diver was shot
+func (s *SeaQuest) updateDiver(id entity.ID, diver *swimmer) float64 {
+	reward := 0.0
+
+	if game.Collides(diver, s.agent) &&
 		s.agent.divers() < maxDivers {
-		s.divers = append(s.divers[:i], s.divers[i+1:]...)
+		s.divers.Remove(id)
 		s.agent.incrementDivers()
 	} else {
 		if diver.canMove() {
@@ -552,28 +1153,35 @@ func (s *SeaQuest) updateDiver(i int) {
 
 			// Remove diver if leaving the screen
 			if diver.x() < 0 || diver.x() > rows-1 {
-				s.divers = append(s.divers[:i], s.divers[i+1:]...)
+				s.divers.Remove(id)
 			} else if diver.x() == s.agent.x() &&
 				diver.y() == s.agent.y() && s.agent.divers() < maxDivers {
-				s.divers = append(s.divers[:i], s.divers[i+1:]...)
+				s.divers.Remove(id)
 				s.agent.incrementDivers()
+			} else if s.config.ShootableDivers {
+				if bulletID, _, ok := s.fBullets.At(diver.Position()); ok {
+					// Diver is hit by bullet, remove it
+					s.divers.Remove(id)
+					s.fBullets.Remove(bulletID)
+					reward += s.shootDiver(diver.x(), diver.y())
+				}
 			}
 		} else {
 			diver.decrementMoveTimer()
 		}
 	}
+	return reward
 }
 
-// updateEnemySubmarine updates the enemy submarine at index i in the
-// s.eSubs slice, determines if the game is over due to the enemy
-// crashing into the player, and returns the reward for if the
-// submarine was shot by the player
-func (s *SeaQuest) updateEnemySubmarine(i int) float64 {
-	sub := s.eSubs[i]
+// updateEnemySubmarine updates the enemy submarine with the given ID,
+// determines if the game is over due to the enemy crashing into the
+// player, and returns the reward for if the submarine was shot by the
+// player
+func (s *SeaQuest) updateEnemySubmarine(id entity.ID, sub *submarine) float64 {
 	reward := 0.
 
-	if sub.x() == s.agent.x() && sub.y() == s.agent.y() {
-		s.terminal = true
+	if game.Collides(sub, s.agent) {
+		s.terminate(game.Collision)
 	}
 
 	if sub.canMove() {
@@ -582,22 +1190,20 @@ func (s *SeaQuest) updateEnemySubmarine(i int) float64 {
 		// Move submarine
 		sub.move()
 
-		// Remove submarine if leaving screen
+		// Remove submarine if leaving screen. Since version 2, a
+		// submarine which has left the screen cannot shoot.
 		if sub.x() < 0 || sub.x() > rows-1 {
-			s.eSubs = append(s.eSubs[:i], s.eSubs[i+1:]...)
-		} else if sub.x() == s.agent.x() && sub.y() == s.agent.y() {
-			s.terminal = true
-		} else {
-			for j, bullet := range s.fBullets {
-				if sub.x() == bullet.x() && sub.y() == bullet.y() {
-					// Submarine is hit by bullet, remove it
-					s.eSubs = append(s.eSubs[:i], s.eSubs[i+1:]...)
-					s.fBullets = append(s.fBullets[:j],
-						s.fBullets[j+1:]...)
-					reward += 1
-					break
-				}
+			s.eSubs.Remove(id)
+			if s.config.Version != 1 {
+				return reward
 			}
+		} else if game.Collides(sub, s.agent) {
+			s.terminate(game.Collision)
+		} else if bulletID, _, ok := s.fBullets.At(sub.Position()); ok {
+			// Submarine is hit by bullet, remove it
+			s.eSubs.Remove(id)
+			s.fBullets.Remove(bulletID)
+			reward += s.shootEnemy(sub.x(), sub.y(), "enemy_sub")
 		}
 	} else {
 		sub.decrementMoveTimer()
@@ -605,23 +1211,22 @@ func (s *SeaQuest) updateEnemySubmarine(i int) float64 {
 
 	if sub.canShoot() {
 		sub.setShotTimer(enemyShotInterval)
-		bullet := newBullet(sub.x(), sub.y(), sub.orientedRight())
-		s.eBullets = append(s.eBullets, bullet)
+		s.eBullets.Add(recycledSwimmer(s.eBullets).init(sub.x(), sub.y(),
+			sub.orientedRight(), 0))
 	} else {
 		sub.decrementShotTimer()
 	}
 	return reward
 }
 
-// updateEnemyFish updates the fish at index i in the s.eFish slice,
-// determines if the game has ended due to the fish crashing into the
-// player and returns the reward if the enemy fish was shot
-func (s *SeaQuest) updateEnemyFish(i int) float64 {
-	fish := s.eFish[i]
+// updateEnemyFish updates the fish with the given ID, determines if
+// the game has ended due to the fish crashing into the player and
+// returns the reward if the enemy fish was shot
+func (s *SeaQuest) updateEnemyFish(id entity.ID, fish *swimmer) float64 {
 	reward := 0.0
 
-	if fish.x() == s.agent.x() && fish.y() == s.agent.y() {
-		s.terminal = true
+	if game.Collides(fish, s.agent) {
+		s.terminate(game.Collision)
 	}
 
 	if fish.canMove() {
@@ -629,23 +1234,18 @@ func (s *SeaQuest) updateEnemyFish(i int) float64 {
 
 		// Move fish
 		fish.move()
+		fish.drift(1, rows-2)
 
 		// Remove fish if travelling off screen
-		if fish.x() < 0 || fish.y() > rows-1 {
-			s.eFish = append(s.eFish[:i], s.eFish[i+1:]...)
-		} else if fish.x() == s.agent.x() && fish.y() == s.agent.y() {
-			s.terminal = true
-		} else {
-			// Check if hit by friendly bullet
-			for j, bullet := range s.fBullets {
-				if fish.x() == bullet.x() && fish.y() == bullet.y() {
-					s.eFish = append(s.eFish[:i], s.eFish[i+1:]...)
-					s.fBullets = append(s.fBullets[:j],
-						s.fBullets[j+1:]...)
-					reward += 1
-					break
-				}
-			}
+		if s.leftScreen(fish) {
+			s.eFish.Remove(id)
+		} else if game.Collides(fish, s.agent) {
+			s.terminate(game.Collision)
+		} else if bulletID, _, ok := s.fBullets.At(fish.Position()); ok {
+			// Fish is hit by bullet, remove it
+			s.eFish.Remove(id)
+			s.fBullets.Remove(bulletID)
+			reward += s.shootEnemy(fish.x(), fish.y(), "enemy_fish")
 		}
 	} else {
 		fish.decrementMoveTimer()
@@ -653,3 +1253,136 @@ func (s *SeaQuest) updateEnemyFish(i int) float64 {
 
 	return reward
 }
+
+// positions holds the positions of the player and of each entity at
+// the start of a step, which are used to detect objects which pass
+// through each other during the step
+type positions struct {
+	agent    game.Point
+	fBullets map[entity.ID]game.Point
+	eBullets map[entity.ID]game.Point
+	eFish    map[entity.ID]game.Point
+	eSubs    map[entity.ID]game.Point
+	divers   map[entity.ID]game.Point
+}
+
+// positions returns the current positions of the player and of each
+// entity
+func (s *SeaQuest) positions() positions {
+	of := func(m *entity.Manager) map[entity.ID]game.Point {
+		p := make(map[entity.ID]game.Point, m.Len())
+		m.Each(func(id entity.ID, e entity.Entity) {
+			p[id] = game.PointOf(e)
+		})
+		return p
+	}
+
+	return positions{
+		agent:    game.PointOf(s.agent),
+		fBullets: of(s.fBullets),
+		eBullets: of(s.eBullets),
+		eFish:    of(s.eFish),
+		eSubs:    of(s.eSubs),
+		divers:   of(s.divers),
+	}
+}
+
+// resolveSwaps resolves the collisions between objects which swapped
+// cells during the step, given the positions at the start of the step,
+// and returns the reward for shooting any enemies, or divers with
+// Config.ShootableDivers. Objects which end the step in the same cell
+// have already collided.
+func (s *SeaQuest) resolveSwaps(prev positions) float64 {
+	// prevOf returns the position of e at the start of the step.
+	// Entities spawned during the step have not moved.
+	prevOf := func(p map[entity.ID]game.Point, id entity.ID,
+		e entity.Entity) game.Point {
+		if pos, ok := p[id]; ok {
+			return pos
+		}
+		return game.PointOf(e)
+	}
+
+	type target struct {
+		m    *entity.Manager
+		prev map[entity.ID]game.Point
+		kind string
+	}
+	targets := []target{
+		{s.eFish, prev.eFish, "enemy_fish"},
+		{s.eSubs, prev.eSubs, "enemy_sub"},
+	}
+	if s.config.ShootableDivers {
+		targets = append(targets, target{s.divers, prev.divers, "diver"})
+	}
+
+	reward := 0.0
+	s.fBullets.Each(func(bulletID entity.ID, bullet entity.Entity) {
+		prevBullet := prevOf(prev.fBullets, bulletID, bullet)
+
+		for _, enemies := range targets {
+			hit := false
+			enemies.m.Each(func(id entity.ID, e entity.Entity) {
+				if hit || !game.Swapped(prevBullet, bullet,
+					prevOf(enemies.prev, id, e), e) {
+					return
+				}
+
+				hit = true
+				x, y := e.Position()
+				enemies.m.Remove(id)
+				s.fBullets.Remove(bulletID)
+				reward += s.shoot(x, y, enemies.kind)
+			})
+			if hit {
+				return
+			}
+		}
+	})
+
+	// The player is destroyed by passing through any enemy or enemy
+	// bullet
+	for _, enemies := range []struct {
+		m      *entity.Manager
+		prev   map[entity.ID]game.Point
+		reason game.TerminationReason
+	}{
+		{s.eBullets, prev.eBullets, game.HitByBullet},
+		{s.eFish, prev.eFish, game.Collision},
+		{s.eSubs, prev.eSubs, game.Collision},
+	} {
+		enemies.m.Each(func(id entity.ID, e entity.Entity) {
+			if game.Swapped(prev.agent, s.agent, prevOf(enemies.prev, id,
+				e), e) {
+				s.terminate(enemies.reason)
+			}
+		})
+	}
+
+	return reward
+}
+
+// SetLogger makes the game log its events to l, or discard them if l
+// is nil
+func (s *SeaQuest) SetLogger(l game.Logger) {
+	s.log.SetLogger(l)
+}
+
+// TerminationReason returns the reason the episode ended, or
+// game.NotTerminated if the episode has not ended
+func (s *SeaQuest) TerminationReason() game.TerminationReason {
+	return s.reason
+}
+
+// terminate ends the episode for the given reason. If the episode has
+// already ended on this step, the first reason is kept.
+func (s *SeaQuest) terminate(reason game.TerminationReason) {
+	if !s.terminal {
+		s.reason = reason
+		if s.log.On() {
+			s.log.Debugf("seaquest: terminated after %v steps: %v",
+				s.steps, reason)
+		}
+	}
+	s.terminal = true
+}
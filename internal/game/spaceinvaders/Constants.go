@@ -0,0 +1,14 @@
+package spaceinvaders
+
+// Constants returns the constants of the game's dynamics, by the name
+// of the constant in MinAtar where it has one. The minimum move
+// interval is given by Config.MinMoveInterval, and parameters changed
+// with SetParam are not reflected.
+func (s *SpaceInvaders) Constants() map[string]int {
+	return map[string]int{
+		"shot_cool_down":      shotCoolDown,
+		"enemy_move_interval": enemyMoveInterval,
+		"enemy_shot_interval": enemyShotInterval,
+		"min_move_interval":   s.config.MinMoveInterval,
+	}
+}
@@ -0,0 +1,45 @@
+package spaceinvaders
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// Factors returns the column of the cannon, the direction in which the
+// aliens move, the number of remaining aliens, the row of the lowest
+// alien, or -1 if there are none, and the number of the player's and
+// of alien bullets. With Config.UFO, these are followed by the column
+// and direction of the UFO, which are -1 and 0 when the UFO is not on
+// the screen.
+func (s *SpaceInvaders) Factors() []game.Factor {
+	aliens, bottom := 0, -1
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			if s.aliens.At(y, x) != 0 {
+				aliens++
+				bottom = y
+			}
+		}
+	}
+
+	factors := []game.Factor{
+		{Name: "cannon_x", Value: s.agent.x(), Reflection: game.Column},
+		{Name: "alien_dx", Value: s.alienDir, Reflection: game.Horizontal},
+		{Name: "aliens", Value: aliens},
+		{Name: "alien_bottom", Value: bottom},
+		{Name: "friendly_bullets", Value: s.fBullets.Len()},
+		{Name: "enemy_bullets", Value: s.eBullets.Len()},
+	}
+	if s.config.UFO {
+		x := -1
+		if s.ufo.active {
+			x = s.ufo.x
+		}
+		factors = append(factors,
+			game.Factor{Name: "ufo_x", Value: x, Reflection: game.Column},
+			game.Factor{
+				Name:       "ufo_dx",
+				Value:      s.ufo.dir,
+				Reflection: game.Horizontal,
+			},
+		)
+	}
+	return factors
+}
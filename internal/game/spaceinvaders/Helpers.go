@@ -1,6 +1,9 @@
 package spaceinvaders
 
-import "github.com/samuelfneumann/goatar/internal/game"
+import (
+	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/entity"
+)
 
 // player implements a player in the game SpaceInvaders
 type player struct {
@@ -50,3 +53,35 @@ func (p *player) moveLeft() {
 func (p *player) moveRight() {
 	p.setX(game.MinInt(cols-1, p.x()+1))
 }
+
+// bullet implements a bullet in the game SpaceInvaders, fired either
+// by the player or by an alien
+type bullet struct {
+	x, y int
+}
+
+// init places the bullet at column x and row y and returns the bullet
+func (b *bullet) init(x, y int) *bullet {
+	b.x, b.y = x, y
+	return b
+}
+
+// recycledBullet returns a bullet removed from m which can be reused,
+// or a new bullet if there is none
+func recycledBullet(m *entity.Manager) *bullet {
+	if b, ok := m.Recycled().(*bullet); ok {
+		return b
+	}
+	return new(bullet)
+}
+
+// Position returns the x and y position of the bullet
+func (b *bullet) Position() (x, y int) {
+	return b.x, b.y
+}
+
+// cloneBullet returns a copy of a *bullet entity
+func cloneBullet(e entity.Entity) entity.Entity {
+	b := *e.(*bullet)
+	return &b
+}
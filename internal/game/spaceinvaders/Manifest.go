@@ -0,0 +1,60 @@
+package spaceinvaders
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// channelDescriptions describes each channel of state observations
+var channelDescriptions = map[string]string{
+	"cannon":          "position of the player's cannon",
+	"alien":           "positions of aliens",
+	"alien_left":      "positions of aliens when they are moving left",
+	"alien_right":     "positions of aliens when they are moving right",
+	"friendly_bullet": "positions of the player's bullets",
+	"enemy_bullet":    "positions of alien bullets",
+	"ufo":             "position of the UFO",
+	"enemy_bullet_trail": "previous positions of alien bullets, " +
+		"indicating their direction of movement",
+}
+
+// Manifest returns a description of the game
+func (s *SpaceInvaders) Manifest() game.Manifest {
+	termination := []string{
+		"the cannon is hit by an alien or an alien bullet",
+	}
+	if s.config.Respawn == NoRespawn {
+		termination = append(termination, "all aliens in the wave are shot")
+	}
+
+	var trails []string
+	if s.config.BulletTrails {
+		trails = []string{"enemy_bullet_trail"}
+	}
+
+	rewards := []game.RewardInfo{{Event: "alien shot", Reward: "+1"}}
+	if s.config.UFO {
+		rewards = append(rewards, game.RewardInfo{
+			Event:  "UFO shot",
+			Reward: "+5",
+		})
+	}
+
+	return game.Manifest{
+		Description: "The player controls a cannon at the bottom of the " +
+			"screen which shoots at a cluster of aliens moving across " +
+			"and down the screen. The aliens shoot back.",
+		Channels:    game.ChannelInfos(s.channels, channelDescriptions),
+		Actions:     game.ActionInfos(s),
+		Rewards:     rewards,
+		Termination: termination,
+		Ramping: "each time a wave of aliens is cleared, the next wave " +
+			"moves faster",
+		Mirror: game.HorizontalMirror([2]string{
+			"alien_left", "alien_right",
+		}).OrientedBy("alien_dx"),
+		ZOrder: []string{
+			"alien", "alien_left", "alien_right", "enemy_bullet_trail",
+			"enemy_bullet",
+			"friendly_bullet", "ufo", "cannon",
+		},
+		Trails: trails,
+	}
+}
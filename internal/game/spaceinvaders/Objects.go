@@ -0,0 +1,56 @@
+package spaceinvaders
+
+import (
+	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/entity"
+)
+
+// Objects returns the cannon, followed by each alien from left to
+// right and top to bottom, the player's bullets, alien bullets, and the
+// UFO, if it is on the screen.
+// Aliens are oriented in the direction the formation moves, the
+// player's bullets move up, and alien bullets move down.
+func (s *SpaceInvaders) Objects() []game.Object {
+	objects := []game.Object{
+		{Type: "cannon", X: s.agent.x(), Y: rows - 1},
+	}
+
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			if s.aliens.At(y, x) != 0 {
+				objects = append(objects, game.Object{
+					Type:        "alien",
+					X:           x,
+					Y:           y,
+					Orientation: game.HorizontalOrientation(s.alienDir),
+				})
+			}
+		}
+	}
+
+	add := func(typ string, orientation game.Orientation,
+		bullets *entity.Manager) {
+		bullets.Each(func(_ entity.ID, e entity.Entity) {
+			x, y := e.Position()
+			objects = append(objects, game.Object{
+				Type:        typ,
+				X:           x,
+				Y:           y,
+				Orientation: orientation,
+			})
+		})
+	}
+	add("friendly_bullet", game.FacingUp, s.fBullets)
+	add("enemy_bullet", game.FacingDown, s.eBullets)
+
+	if s.ufo.active {
+		objects = append(objects, game.Object{
+			Type:        "ufo",
+			X:           s.ufo.x,
+			Y:           0,
+			Orientation: game.HorizontalOrientation(s.ufo.dir),
+		})
+	}
+
+	return objects
+}
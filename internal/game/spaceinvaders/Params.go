@@ -0,0 +1,50 @@
+package spaceinvaders
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// Params returns the names of the game's parameters
+func (s *SpaceInvaders) Params() []string {
+	return []string{game.RampingParam, game.MoveIntervalParam}
+}
+
+// Param returns the current value of the named parameter
+func (s *SpaceInvaders) Param(name string) (float64, bool) {
+	switch name {
+	case game.RampingParam:
+		return game.BoolParam(s.ramping), true
+
+	case game.MoveIntervalParam:
+		return float64(s.enemyMoveInterval), true
+
+	default:
+		return 0, false
+	}
+}
+
+// SetParam sets the value of the named parameter. The alien move
+// interval must be non-negative and is rounded to the nearest integer.
+func (s *SpaceInvaders) SetParam(name string, value float64) error {
+	s.cache.Invalidate()
+	switch name {
+	case game.RampingParam:
+		s.ramping = value != 0
+
+	case game.MoveIntervalParam:
+		interval, ok := game.IntParam(value, 0)
+		if !ok {
+			return fmt.Errorf("setParam: %v must be non-negative but got "+
+				"%v", name, value)
+		}
+		s.initMoveInterval = interval
+		s.enemyMoveInterval = interval
+		s.alienMoveTimer = game.MinInt(s.alienMoveTimer, interval)
+
+	default:
+		return fmt.Errorf("setParam: no such parameter %v", name)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package spaceinvaders
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar/internal/game"
+)
+
+// The methods in this file set the underlying state of the game by
+// hand, so that scripted scenarios can be constructed and stepped.
+
+// Empty removes all aliens and bullets from the screen. If no aliens
+// are placed afterwards, a new wave spawns on the next step.
+func (s *SpaceInvaders) Empty() {
+	s.cache.Invalidate()
+	s.aliens.Zero()
+	s.fBullets.Clear()
+	s.eBullets.Clear()
+}
+
+// PlaceCannon moves the cannon to column x
+func (s *SpaceInvaders) PlaceCannon(x int) error {
+	if x < 0 || x >= cols {
+		return fmt.Errorf("placeCannon: column %v ∉ [0, %v)", x, cols)
+	}
+	s.cache.Invalidate()
+	s.agent.setX(x)
+	return nil
+}
+
+// SetShotTimer sets the number of steps until the cannon can fire
+func (s *SpaceInvaders) SetShotTimer(steps int) error {
+	if steps < 0 {
+		return fmt.Errorf("setShotTimer: steps must be non-negative but "+
+			"got %v", steps)
+	}
+	s.agent.setShotTimer(steps)
+	return nil
+}
+
+// PlaceAlien places an alien at column x and row y
+func (s *SpaceInvaders) PlaceAlien(x, y int) error {
+	if x < 0 || x >= cols || y < 0 || y >= rows {
+		return fmt.Errorf("placeAlien: position (%v, %v) out of bounds", x, y)
+	}
+	s.cache.Invalidate()
+	s.aliens.Set(y, x, 1)
+	return nil
+}
+
+// SetAliens sets the direction in which the aliens move, which is
+// either left or right, and the number of steps until the aliens next
+// move and shoot
+func (s *SpaceInvaders) SetAliens(o game.Orientation, moveTimer,
+	shotTimer int) error {
+	var dir int
+	switch o {
+	case game.FacingLeft:
+		dir = -1
+	case game.FacingRight:
+		dir = 1
+	default:
+		return fmt.Errorf("setAliens: aliens cannot move %q", o)
+	}
+	if moveTimer < 0 || shotTimer < 0 {
+		return fmt.Errorf("setAliens: timers must be non-negative but got "+
+			"%v and %v", moveTimer, shotTimer)
+	}
+
+	s.cache.Invalidate()
+	s.alienDir = dir
+	s.alienMoveTimer = moveTimer
+	s.alienShotTimer = shotTimer
+	return nil
+}
+
+// PlaceFriendlyBullet places a bullet fired by the cannon at column x
+// and row y
+func (s *SpaceInvaders) PlaceFriendlyBullet(x, y int) error {
+	if x < 0 || x >= cols || y < 0 || y >= rows {
+		return fmt.Errorf("placeFriendlyBullet: position (%v, %v) out of "+
+			"bounds", x, y)
+	}
+	s.cache.Invalidate()
+	s.fBullets.Add(recycledBullet(s.fBullets).init(x, y))
+	return nil
+}
+
+// PlaceEnemyBullet places a bullet fired by an alien at column x and
+// row y
+func (s *SpaceInvaders) PlaceEnemyBullet(x, y int) error {
+	if x < 0 || x >= cols || y < 0 || y >= rows {
+		return fmt.Errorf("placeEnemyBullet: position (%v, %v) out of "+
+			"bounds", x, y)
+	}
+	s.cache.Invalidate()
+	s.eBullets.Add(recycledBullet(s.eBullets).init(x, y))
+	return nil
+}
+
+// RemoveAt removes the alien, bullets, and UFO at column x and row y,
+// and returns the number of objects removed
+func (s *SpaceInvaders) RemoveAt(x, y int) int {
+	s.cache.Invalidate()
+	n := s.fBullets.RemoveAt(x, y) + s.eBullets.RemoveAt(x, y)
+	if x >= 0 && x < cols && y >= 0 && y < rows && s.aliens.At(y, x) != 0 {
+		s.aliens.Set(y, x, 0)
+		n++
+	}
+	if s.ufo.active && s.ufo.x == x && y == 0 {
+		s.resetUFO()
+		n++
+	}
+	return n
+}
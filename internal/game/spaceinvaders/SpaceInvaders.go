@@ -17,12 +17,10 @@ package spaceinvaders
 
 import (
 	"fmt"
-	"math"
-	"math/rand"
-	"sort"
 
 	"github.com/samuelfneumann/goatar/internal/game"
-	"gonum.org/v1/gonum/mat"
+	"github.com/samuelfneumann/goatar/internal/game/entity"
+	"github.com/samuelfneumann/goatar/internal/grid"
 )
 
 const (
@@ -41,9 +39,10 @@ const (
 // See the package documentation for more details
 //
 // Underlying state is represented as a *player, denoting the player's
-// position, and a *mat.Dense denoting the positions of the player's
-// bullets, the enemies' bullets, and the aliens. Each element in these
-// *mat.Dense represent a specific position on the screen.
+// position, a *grid.Grid denoting the positions of the aliens, and an
+// *entity.Manager of *bullet for each of the player's bullets and the
+// enemies' bullets. Each element of the *grid.Grid represents a
+// specific position on the screen.
 //
 // State observations consist of a 6 x rows x cols tensor. Each of the
 // six channels represents:
@@ -62,29 +61,163 @@ const (
 // an enemy alien is in position (j, i).
 type SpaceInvaders struct {
 	channels  map[string]int
-	actionMap []rune
-	rng       *rand.Rand
+	actionMap []game.Action
+	rng       *game.Random
 	ramping   bool
+	config    Config
 	rampIndex int
+	wave      int // Number of waves of aliens cleared
 	terminal  bool
+	reason    game.TerminationReason // Why the episode ended, if it has
 
 	agent    *player
-	fBullets *mat.Dense
+	fBullets *entity.Manager // Friendly bullets, each a *bullet
 
-	eBullets          *mat.Dense
-	aliens            *mat.Dense
+	eBullets          *entity.Manager // Enemy bullets, each a *bullet
+	aliens            *grid.Grid
 	alienDir          int
 	enemyMoveInterval int
 	alienMoveTimer    int
 	alienShotTimer    int
+	targeting         targetingStrategy // Chooses the alien which shoots
 
-	// currentState caches the last state of the environment to increase
-	// computational efficiency if State() is called many times
-	currentState []float64
+	// Horizontal movement of the cannon on the last step, used by
+	// LeadingShot
+	cannonDx int
+
+	// Alien move interval at the start of each episode
+	initMoveInterval int
+
+	ufo ufo // Bonus enemy, if Config.UFO is set
+
+	rewardEvents []game.RewardEvent // Reward events of the last step
+
+	cache game.StateCache // Cached state observation
+	log   game.Log        // Logs the events of the game
+}
+
+// Respawn determines what happens when a wave of aliens is cleared
+type Respawn int
+
+const (
+	// RespawnTop spawns each new wave at the top of the screen
+	RespawnTop Respawn = iota
+
+	// RespawnLower spawns each new wave one row lower than the last,
+	// until the wave would reach the second last row of the screen
+	RespawnLower
+
+	// NoRespawn ends the episode when a wave is cleared
+	NoRespawn
+)
+
+// Versions describes the changes in behaviour of each version of the
+// game, where element i describes version i+1
+var Versions = []string{
+	"the original port of MinAtar",
+	"aliens shoot from the column nearest the cannon, and aliens in " +
+		"the top row or leftmost column can shoot, as in MinAtar; " +
+		"random numbers are drawn with xoshiro256** rather than " +
+		"math/rand, and sticky actions restart with each episode",
+	"aliens speed up as they are shot, and a cleared wave is replaced " +
+		"by a new wave, as in MinAtar",
+}
+
+// LatestVersion is the latest version of the game
+const LatestVersion = 3
+
+// Config configures a SpaceInvaders game. The zero value is the
+// default configuration, which matches MinAtar.
+type Config struct {
+	// Version is the version of the game's behaviour, see Versions. If
+	// 0, the latest version is used.
+	Version int
+
+	// AlienRows is the number of rows of aliens in each wave. If zero,
+	// waves have 4 rows.
+	AlienRows int
+
+	// AlienCols is the number of columns of aliens in each wave, which
+	// are centred on the screen. If zero, waves have 6 columns.
+	AlienCols int
+
+	// Respawn determines what happens when a wave is cleared. Before
+	// version 3, cleared waves go unnoticed, and so are neither
+	// replaced nor end the episode.
+	Respawn Respawn
+
+	// UFO adds a bonus enemy which periodically crosses the top row of
+	// the screen, shown in its own channel, and which is worth +5 when
+	// shot
+	UFO bool
+
+	// Targeting determines which alien shoots at the cannon
+	Targeting Targeting
+
+	// BulletTrails adds an "enemy_bullet_trail" channel, which is
+	// active in the cell above each alien bullet, so that the direction
+	// in which bullets move can be read from a single observation
+	BulletTrails bool
+
+	// MinMoveInterval is the alien move interval at which difficulty
+	// ramping stops. By default, each cleared wave moves faster until
+	// aliens move on every step, while MinAtar stops ramping at an
+	// interval of 6.
+	MinMoveInterval int
+}
+
+// withDefaults returns the configuration with zero values replaced by
+// their defaults
+func (c Config) withDefaults() Config {
+	if c.Version == 0 {
+		c.Version = LatestVersion
+	}
+	if c.AlienRows == 0 {
+		c.AlienRows = 4 * rows / 10
+	}
+	if c.AlienCols == 0 {
+		c.AlienCols = cols - 4
+	}
+	return c
+}
+
+// validate returns an error if the configuration is invalid
+func (c Config) validate() error {
+	if c.Version < 0 || c.Version > LatestVersion {
+		return fmt.Errorf("version %v ∉ [1, %v]", c.Version, LatestVersion)
+	}
+	if c.AlienRows < 0 || c.AlienRows > rows-2 {
+		return fmt.Errorf("alien rows %v ∉ [0, %v]", c.AlienRows, rows-2)
+	}
+	if c.AlienCols < 0 || c.AlienCols > cols {
+		return fmt.Errorf("alien cols %v ∉ [0, %v]", c.AlienCols, cols)
+	}
+	if c.Respawn < RespawnTop || c.Respawn > NoRespawn {
+		return fmt.Errorf("unknown respawn behaviour %v", c.Respawn)
+	}
+	if c.Targeting < NearestColumn || c.Targeting > LeadingShot {
+		return fmt.Errorf("unknown targeting %v", c.Targeting)
+	}
+	if c.MinMoveInterval < 0 || c.MinMoveInterval > enemyMoveInterval {
+		return fmt.Errorf("minimum move interval %v ∉ [0, %v]",
+			c.MinMoveInterval, enemyMoveInterval)
+	}
+	return nil
 }
 
 // New returns a new SpaceInvaders game
 func New(ramping bool, seed int64) (game.Game, error) {
+	return NewWithConfig(ramping, seed, Config{})
+}
+
+// NewWithConfig returns a new SpaceInvaders game with the given
+// configuration
+func NewWithConfig(ramping bool, seed int64, config Config) (game.Game,
+	error) {
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("newWithConfig: %v", err)
+	}
+
 	channels := map[string]int{
 		"cannon":          0,
 		"alien":           1,
@@ -93,14 +226,25 @@ func New(ramping bool, seed int64) (game.Game, error) {
 		"friendly_bullet": 4,
 		"enemy_bullet":    5,
 	}
-	actionMap := []rune{'n', 'l', 'u', 'r', 'd', 'f'}
-	rng := rand.New(rand.NewSource(seed))
+	if config.UFO {
+		channels["ufo"] = len(channels)
+	}
+	if config.BulletTrails {
+		channels["enemy_bullet_trail"] = len(channels)
+	}
+	actionMap := []game.Action{game.NoOp, game.Left, game.Up, game.Right,
+		game.Down, game.Fire}
+	rng := game.NewVersionedRandom(seed, config.withDefaults().Version)
 
 	spaceInvaders := &SpaceInvaders{
 		channels:  channels,
 		actionMap: actionMap,
 		rng:       rng,
 		ramping:   ramping,
+		config:    config.withDefaults(),
+		targeting: newTargetingStrategy(config.withDefaults()),
+
+		initMoveInterval: enemyMoveInterval,
 	}
 	spaceInvaders.Reset()
 
@@ -115,87 +259,119 @@ func (s *SpaceInvaders) Act(a int) (float64, bool, error) {
 			a, len(s.actionMap))
 	}
 
+	s.cache.Invalidate()
+	s.rewardEvents = s.rewardEvents[:0]
 	reward := 0.0
 	if s.terminal {
 		return reward, s.terminal, nil
 	}
 
 	// Resolve player action
+	x := s.agent.x()
 	action := s.actionMap[a]
 	switch action {
-	case 'f':
+	case game.Fire:
 		if s.agent.canShoot() {
-			s.fBullets.Set(rows-1, s.agent.x(), 1.0)
+			s.fBullets.Add(recycledBullet(s.fBullets).init(s.agent.x(),
+				rows-1))
 			s.agent.setShotTimer(shotCoolDown)
 		}
 
-	case 'l':
+	case game.Left:
 		s.agent.moveLeft()
 
-	case 'r':
+	case game.Right:
 		s.agent.moveRight()
 	}
+	s.cannonDx = s.agent.x() - x
 
 	// Update friendly bullets
-	game.RollRowsUp(s.fBullets)
-	s.fBullets.SetRow(rows-1, make([]float64, cols))
+	s.fBullets.Each(func(id entity.ID, e entity.Entity) {
+		b := e.(*bullet)
+		b.y--
+		if b.y < 0 {
+			s.fBullets.Remove(id)
+		}
+	})
 
 	// Update enemy bullets
-	game.RollRowsDown(s.eBullets)
-	s.eBullets.SetRow(0, make([]float64, cols))
-	if s.eBullets.At(rows-1, s.agent.x()) == 1.0 {
-		s.terminal = true
+	s.eBullets.Each(func(id entity.ID, e entity.Entity) {
+		b := e.(*bullet)
+		b.y++
+		if b.y > rows-1 {
+			s.eBullets.Remove(id)
+		}
+	})
+	if _, _, ok := s.eBullets.At(s.agent.x(), rows-1); ok {
+		s.terminate(game.HitByBullet)
 	}
 
 	// Update aliens
 	if s.aliens.At(rows-1, s.agent.x()) == 1.0 {
-		s.terminal = true
+		s.terminate(game.Collision)
 	}
 	if s.alienMoveTimer == 0 {
 		s.alienMoveTimer = game.MinInt(s.enemyMoveInterval,
-			game.CountNonZero(s.aliens))
+			s.alienCount())
 
-		if (mat.Sum(s.aliens.ColView(0)) > 0 && s.alienDir < 0) ||
-			(mat.Sum(s.aliens.ColView(cols-1)) > 0 && s.alienDir > 0) {
+		if (s.aliens.ColSum(0) > 0 && s.alienDir < 0) ||
+			(s.aliens.ColSum(cols-1) > 0 && s.alienDir > 0) {
 			s.alienDir = -s.alienDir
 
 			// Aliens have made it to the bottom of the screen
-			if mat.Sum(s.aliens.RowView(rows-1)) > 0 {
-				s.terminal = true
+			if s.aliens.RowSum(rows-1) > 0 {
+				s.terminate(game.AliensReachedBottom)
 			}
 
-			game.RollRowsDown(s.aliens)
+			s.aliens.RollRowsDown()
 		} else {
 			// Move aliens left or right
 			if s.alienDir < 0 {
-				game.RollColsLeft(s.aliens)
+				s.aliens.RollColsLeft()
 			} else {
-				game.RollColsRight(s.aliens)
+				s.aliens.RollColsRight()
 			}
 		}
 		if s.aliens.At(rows-1, s.agent.x()) == 1.0 {
-			s.terminal = true
+			s.terminate(game.Collision)
 		}
 	}
 	if s.alienShotTimer == 0 {
-		// Shoot from the nearest alien
+		// Shoot from the alien chosen by the targeting strategy
 		s.alienShotTimer = enemyShotInterval
-		nearestAlienX, nearestAlienY := s.nearestAlien(s.agent.x())
-		if nearestAlienX > 0 && nearestAlienY > 0 {
-			s.eBullets.Set(nearestAlienX, nearestAlienY, 1.0)
+		row, col := s.targeting.shooter(s)
+		if s.alienCanShoot(row, col) {
+			s.eBullets.Add(recycledBullet(s.eBullets).init(col, row))
 		}
 	}
 
-	// Find where the aliens were killed
-	for r := 0; r < rows; r++ {
-		for c := 0; c < cols; c++ {
-			if s.fBullets.At(r, c) == 1.0 && s.aliens.At(r, c) == 1.0 {
-				reward++
-				s.aliens.Set(r, c, 0.0)
-				s.fBullets.Set(r, c, 0.0)
+	s.updateUFO()
+
+	// Find where the aliens were killed. The oldest bullets are
+	// highest on the screen, so aliens are killed from the top down.
+	// Aliens in the top row shield the UFO from bullets.
+	s.fBullets.Each(func(id entity.ID, e entity.Entity) {
+		b := e.(*bullet)
+		if s.aliens.At(b.y, b.x) == 1.0 {
+			if s.log.On() {
+				s.log.Debugf("spaceinvaders: bullet collided with alien "+
+					"at (%v, %v)", b.x, b.y)
 			}
+			reward++
+			s.rewardEvents = append(s.rewardEvents, game.RewardEvent{
+				Type:   game.Destroy,
+				Amount: 1,
+				X:      b.x,
+				Y:      b.y,
+				Entity: "alien",
+			})
+			s.aliens.Set(b.y, b.x, 0.0)
+			s.fBullets.Remove(id)
+		} else if r := s.shootUFO(b.x, b.y); r > 0 {
+			reward += r
+			s.fBullets.Remove(id)
 		}
-	}
+	})
 
 	// Update timers
 	if !s.agent.canShoot() {
@@ -205,48 +381,46 @@ func (s *SpaceInvaders) Act(a int) (float64, bool, error) {
 	s.alienMoveTimer--
 	s.alienShotTimer--
 
-	// All aliens have been destroyed, reset them at the top and increase
+	// All aliens have been destroyed, spawn a new wave and increase
 	// the difficulty
-	if game.CountNonZero(s.aliens) == 0 {
-		if s.enemyMoveInterval > 0 && s.ramping { // MinAtar has > 6
-			s.enemyMoveInterval--
-			s.rampIndex++
-		}
-		// Set the aliens
-		aliens := make([]float64, cols)
-		for i := 2; i < cols-2; i++ {
-			aliens[i] = 1
-		}
-		s.aliens = mat.NewDense(rows, cols, nil)
-		for i := 0; i < 4*rows/10; i++ {
-			s.aliens.SetRow(i, aliens)
+	if s.alienCount() == 0 {
+		s.wave++
+		if s.config.Respawn == NoRespawn {
+			s.terminate(game.WaveCleared)
+		} else {
+			if s.enemyMoveInterval > s.config.MinMoveInterval &&
+				s.ramping {
+				s.enemyMoveInterval--
+				s.rampIndex++
+				if s.log.On() {
+					s.log.Debugf("spaceinvaders: difficulty ramp %v: "+
+						"move interval %v", s.rampIndex,
+						s.enemyMoveInterval)
+				}
+			}
+			s.spawnWave()
 		}
 	}
 
-	// Clear current state so next time State() is called it will be
-	// recalculated and cached
-	s.currentState = nil
-
 	return reward, s.terminal, nil
 }
 
 // State returns the current state observation
 func (s *SpaceInvaders) State() ([]float64, error) {
-	if s.currentState != nil {
-		return s.currentState, nil
-	}
-
-	state := make([]float64, rows*cols*s.NChannels())
+	return s.cache.State(rows*cols*s.NChannels(), s.observe)
+}
 
+// observe fills the zeroed slice state with the state observation
+func (s *SpaceInvaders) observe(state []float64) error {
 	// Set the cannon at the bottom of the screen
 	state[rows*cols*s.channels["cannon"]+(rows-1)*cols+s.agent.x()] = 1.0
 
 	// Set the aliens channel
 	start := rows * cols * (s.channels["alien"])
 	end := rows * cols * (s.channels["alien"] + 1)
-	copied := copy(state[start:end], s.aliens.RawMatrix().Data)
+	copied := copy(state[start:end], s.aliens.Data())
 	if copied != rows*cols {
-		return nil, fmt.Errorf("state: could not copy aliens channel " +
+		return fmt.Errorf("state: could not copy aliens channel " +
 			"into state observation tensor")
 	}
 
@@ -258,61 +432,74 @@ func (s *SpaceInvaders) State() ([]float64, error) {
 		start = rows * cols * (s.channels["alien_right"])
 		end = rows * cols * (s.channels["alien_right"] + 1)
 	}
-	copied = copy(state[start:end], s.aliens.RawMatrix().Data)
+	copied = copy(state[start:end], s.aliens.Data())
 	if copied != rows*cols {
-		return nil, fmt.Errorf("state: could not copy aliens direction " +
+		return fmt.Errorf("state: could not copy aliens direction " +
 			"channel into state observation tensor")
 	}
 
 	// Set the friendly bullet channel
-	start = rows * cols * (s.channels["friendly_bullet"])
-	end = rows * cols * (s.channels["friendly_bullet"] + 1)
-	copied = copy(state[start:end], s.fBullets.RawMatrix().Data)
-	if copied != rows*cols {
-		return nil, fmt.Errorf("state: could not copy friendly bullets " +
-			"channel into state observation tensor")
-	}
+	s.fBullets.Each(func(_ entity.ID, e entity.Entity) {
+		x, y := e.Position()
+		state[rows*cols*s.channels["friendly_bullet"]+y*cols+x] = 1.0
+	})
 
-	// Set the enemy bullet channel
-	start = rows * cols * (s.channels["enemy_bullet"])
-	end = rows * cols * (s.channels["enemy_bullet"] + 1)
-	copied = copy(state[start:end], s.eBullets.RawMatrix().Data)
-	if copied != rows*cols {
-		return nil, fmt.Errorf("state: could not copy enemy bullets " +
-			"channel into state observation tensor")
-	}
+	// Set the enemy bullet channel, and the trail above each bullet
+	// which shows that it moves down
+	s.eBullets.Each(func(_ entity.ID, e entity.Entity) {
+		x, y := e.Position()
+		state[rows*cols*s.channels["enemy_bullet"]+y*cols+x] = 1.0
+		if s.config.BulletTrails && y > 0 {
+			trail := rows * cols * s.channels["enemy_bullet_trail"]
+			state[trail+(y-1)*cols+x] = 1.0
+		}
+	})
 
-	// Cache the state observation
-	s.currentState = state
+	if s.ufo.active {
+		state[rows*cols*s.channels["ufo"]+s.ufo.x] = 1.0
+	}
 
-	return state, nil
+	return nil
 }
 
 // Reset resets the environment to some starting state
 func (s *SpaceInvaders) Reset() {
-	start := s.rng.Intn(rows/4) + rows/2
-	s.agent = newPlayer(start, 0)
-	s.fBullets = mat.NewDense(rows, cols, nil)
-	s.eBullets = mat.NewDense(rows, cols, nil)
-
-	// Set the aliens
-	aliens := make([]float64, cols)
-	for i := 2; i < cols-2; i++ {
-		aliens[i] = 1
-	}
-	s.aliens = mat.NewDense(rows, cols, nil)
-	for i := 0; i < 4*rows/10; i++ {
-		s.aliens.SetRow(i, aliens)
+	s.cache.Invalidate()
+	s.rewardEvents = s.rewardEvents[:0]
+	start := s.rng.Intn("player start", rows/4) + rows/2
+	if s.agent == nil {
+		s.agent = newPlayer(start, 0)
+		s.fBullets = entity.NewManager()
+		s.eBullets = entity.NewManager()
+	} else {
+		*s.agent = player{start, 0}
+		s.fBullets.Clear()
+		s.eBullets.Clear()
 	}
 
+	s.wave = 0
+	s.spawnWave()
+
 	s.alienDir = -1
-	s.enemyMoveInterval = enemyMoveInterval
+	s.enemyMoveInterval = s.initMoveInterval
 	s.alienMoveTimer = s.enemyMoveInterval
 	s.alienShotTimer = enemyShotInterval
 	s.rampIndex = 0
+	s.cannonDx = 0
+	s.resetUFO()
 	s.terminal = false
+	s.reason = game.NotTerminated
+}
 
-	s.currentState = nil
+// SoftReset resets the game to a starting state, but keeps the current
+// difficulty ramp. The first wave of aliens is spawned at the top of
+// the screen, as after Reset, but moves at the current speed.
+func (s *SpaceInvaders) SoftReset() {
+	moveInterval, rampIndex := s.enemyMoveInterval, s.rampIndex
+	s.Reset()
+
+	s.enemyMoveInterval, s.alienMoveTimer = moveInterval, moveInterval
+	s.rampIndex = rampIndex
 }
 
 // Channel returns the channel at index i of the state observation
@@ -345,15 +532,100 @@ func (s *SpaceInvaders) DifficultyRamp() int {
 	return s.rampIndex
 }
 
-// StateShape returns the shape of state observation tensors
-func (s *SpaceInvaders) StateShape() []int {
-	return []int{s.NChannels(), rows, cols}
+// RewardRange returns the minimum and maximum reward that can be
+// received on a single step. Each friendly bullet on the screen can
+// destroy one alien or the UFO each step.
+func (s *SpaceInvaders) RewardRange() (min, max float64) {
+	maxBullets := (rows + shotCoolDown - 1) / shotCoolDown
+	if s.config.UFO {
+		return 0, float64(maxBullets - 1 + ufoReward)
+	}
+	return 0, float64(maxBullets)
+}
+
+// Seed seeds the random number generator of the game. The game is not
+// reset.
+func (s *SpaceInvaders) Seed(seed int64) {
+	s.rng.Seed(seed)
+}
+
+// Clone returns a deep copy of the game. The copy behaves identically
+// to the original given the same actions, but does not share any state
+// with it.
+func (s *SpaceInvaders) Clone() game.Game {
+	clone := *s
+	clone.rng = s.rng.Clone()
+
+	agent := *s.agent
+	clone.agent = &agent
+
+	clone.fBullets = s.fBullets.Clone(cloneBullet)
+	clone.eBullets = s.eBullets.Clone(cloneBullet)
+	clone.aliens = s.aliens.Clone()
+	clone.cache = game.StateCache{}
+
+	clone.rewardEvents = append([]game.RewardEvent(nil),
+		s.rewardEvents...)
+	return &clone
+}
+
+// SetStochasticity sets the fraction of random draws made by the game
+// which are random rather than deterministic
+func (s *SpaceInvaders) SetStochasticity(level float64) {
+	s.rng.SetStochasticity(level)
+}
+
+// StateHash returns a hash of the underlying game state, including
+// all timers. Two games with the same hash will, with high
+// probability, behave identically given the same actions and random
+// numbers.
+func (s *SpaceInvaders) StateHash() uint64 {
+	h := game.NewHasher()
+	h.Int(s.agent.x(), s.agent.shotTimer)
+	hashBullets := func(m *entity.Manager) {
+		h.Int(m.Len())
+		m.Each(func(_ entity.ID, e entity.Entity) {
+			h.Int(e.Position())
+		})
+	}
+	hashBullets(s.fBullets)
+	hashBullets(s.eBullets)
+	h.Float(s.aliens.Data()...)
+	h.Int(s.alienDir, s.enemyMoveInterval, s.alienMoveTimer,
+		s.alienShotTimer, s.rampIndex, s.wave)
+	h.Bool(s.terminal)
+	if s.config.UFO {
+		h.Int(s.ufo.x, s.ufo.dir, s.ufo.timer)
+		h.Bool(s.ufo.active)
+	}
+	if s.config.Targeting == LeadingShot {
+		h.Int(s.cannonDx)
+	}
+
+	return h.Sum()
+}
+
+// RNGHash returns a hash of the state of the game's random number
+// generator, which is not included in StateHash
+func (s *SpaceInvaders) RNGHash() uint64 {
+	return s.rng.Hash()
+}
+
+// RewardEvents returns the events which resulted in rewards on the
+// last step. The returned slice is only valid until the next step.
+func (s *SpaceInvaders) RewardEvents() []game.RewardEvent {
+	return s.rewardEvents
+}
+
+// Shape returns the shape of state observation tensors
+func (s *SpaceInvaders) Shape() game.Shape {
+	return game.Shape{Channels: s.NChannels(), Rows: rows, Cols: cols}
 }
 
 // MinimalActionSet returns the actions which actually have an effect
 // on the environment.
 func (s *SpaceInvaders) MinimalActionSet() []int {
-	minimalActions := []rune{'n', 'l', 'r', 'f'}
+	minimalActions := []game.Action{game.NoOp, game.Left, game.Right, game.Fire}
 	minimalIntActions := make([]int, len(minimalActions))
 
 	for i, minimalAction := range minimalActions {
@@ -366,28 +638,77 @@ func (s *SpaceInvaders) MinimalActionSet() []int {
 	return minimalIntActions
 }
 
-// nearestAlien finds the alien closest to pos in terms of Manhattan
-// distance. This is usually used to find the alien that will shoot
-// next.
-func (s *SpaceInvaders) nearestAlien(pos int) (x, y int) {
-	searchOrder := make([]int, rows)
-	for i := range searchOrder {
-		searchOrder[i] = i
+// ActionEffect returns a description of the effect of action a
+func (s *SpaceInvaders) ActionEffect(a game.Action) string {
+	effects := map[game.Action]string{
+		game.NoOp:  "do nothing",
+		game.Left:  "move the cannon left",
+		game.Right: "move the cannon right",
+		game.Fire:  "fire a bullet upwards from the cannon",
 	}
+	if effect, ok := effects[a]; ok {
+		return effect
+	}
+	return "no effect"
+}
 
-	sort.Slice(searchOrder, func(i, j int) bool {
-		return math.Abs(float64(i-pos)) < math.Abs(float64(j-pos))
-	})
+// alienCount returns the number of aliens. Before version 3, the empty
+// cells of the screen were counted instead, so that aliens did not speed
+// up as they were shot and cleared waves were never replaced.
+func (s *SpaceInvaders) alienCount() int {
+	n := game.CountNonZero(s.aliens)
+	if s.config.Version < 3 {
+		return len(s.aliens.Data()) - n
+	}
+	return n
+}
 
-	for _, i := range searchOrder {
-		if mat.Sum(s.aliens.ColView(i)) > 0. {
-			aliensAt := game.Where(s.aliens.ColView(i), func(i float64) bool {
-				return i != 0.0
-			})
-			x = game.MaxInt(aliensAt...)
-			y = i
-			return
+// spawnWave replaces the aliens with a new wave of aliens. With
+// RespawnLower, each wave starts one row lower than the last.
+func (s *SpaceInvaders) spawnWave() {
+	top := 0
+	if s.config.Respawn == RespawnLower {
+		top = game.MinInt(s.wave, rows-1-s.config.AlienRows)
+	}
+	left := (cols - s.config.AlienCols) / 2
+
+	if s.aliens == nil {
+		s.aliens = grid.New(rows, cols, nil)
+	} else {
+		s.aliens.Zero()
+	}
+	for i := top; i < top+s.config.AlienRows; i++ {
+		for j := left; j < left+s.config.AlienCols; j++ {
+			s.aliens.Set(i, j, 1)
+		}
+	}
+	if s.log.On() {
+		s.log.Debugf("spaceinvaders: spawned wave %v at (%v, %v)", s.wave,
+			left, top)
+	}
+}
+
+// SetLogger makes the game log its events to l, or discard them if l
+// is nil
+func (s *SpaceInvaders) SetLogger(l game.Logger) {
+	s.log.SetLogger(l)
+}
+
+// TerminationReason returns the reason the episode ended, or
+// game.NotTerminated if the episode has not ended
+func (s *SpaceInvaders) TerminationReason() game.TerminationReason {
+	return s.reason
+}
+
+// terminate ends the episode for the given reason. If the episode has
+// already ended on this step, the first reason is kept.
+func (s *SpaceInvaders) terminate(reason game.TerminationReason) {
+	if !s.terminal {
+		s.reason = reason
+		if s.log.On() {
+			s.log.Debugf("spaceinvaders: terminated after clearing %v "+
+				"waves: %v", s.wave, reason)
 		}
 	}
-	return -1, -1
+	s.terminal = true
 }
@@ -0,0 +1,163 @@
+package spaceinvaders
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+// Targeting determines which alien shoots at the cannon each time the
+// aliens shoot. The alien which shoots is always the lowest alien in
+// its column.
+type Targeting int
+
+const (
+	// NearestColumn shoots from the column of aliens nearest the
+	// cannon, preferring the left column when two are equally near, as
+	// in MinAtar
+	NearestColumn Targeting = iota
+
+	// RandomColumn shoots from a column of aliens chosen uniformly at
+	// random
+	RandomColumn
+
+	// LeadingShot shoots from the column of aliens nearest to where
+	// the cannon will be when the bullet reaches the bottom row, if the
+	// cannon keeps moving as it moved on the last step
+	LeadingShot
+)
+
+// targetingStrategy chooses the alien which shoots at the cannon
+type targetingStrategy interface {
+	// shooter returns the row and column of the alien which shoots
+	// next, or -1, -1 if there are no aliens
+	shooter(s *SpaceInvaders) (row, col int)
+}
+
+// newTargetingStrategy returns the targeting strategy of the
+// configuration
+func newTargetingStrategy(config Config) targetingStrategy {
+	switch config.Targeting {
+	case RandomColumn:
+		return randomColumn{}
+	case LeadingShot:
+		return leadingShot{}
+	default:
+		if config.Version == 1 {
+			return legacyNearestColumn{}
+		}
+		return nearestColumn{}
+	}
+}
+
+// lowestAlien returns the row of the lowest alien in column col, or -1
+// if the column has no aliens
+func (s *SpaceInvaders) lowestAlien(col int) int {
+	if s.aliens.ColSum(col) == 0 {
+		return -1
+	}
+	for r := rows - 1; r >= 0; r-- {
+		if s.aliens.At(r, col) != 0.0 {
+			return r
+		}
+	}
+	return -1
+}
+
+// nearestColumn implements NearestColumn
+type nearestColumn struct{}
+
+// shooter returns the lowest alien of the column of aliens nearest the
+// cannon
+func (nearestColumn) shooter(s *SpaceInvaders) (row, col int) {
+	return s.nearestAlien(func(int) int { return s.agent.x() })
+}
+
+// nearestAlien returns the lowest alien of the column of aliens which
+// is nearest to the column target(r), where r is the row of the lowest
+// alien of the column. The left column is preferred when two columns
+// are equally near.
+func (s *SpaceInvaders) nearestAlien(target func(row int) int) (row,
+	col int) {
+	row, col = -1, -1
+	best := cols
+	for c := 0; c < cols; c++ {
+		r := s.lowestAlien(c)
+		if r < 0 {
+			continue
+		}
+
+		dist := c - target(r)
+		if dist < 0 {
+			dist = -dist
+		}
+		if dist < best {
+			row, col, best = r, c, dist
+		}
+	}
+	return row, col
+}
+
+// legacyNearestColumn implements NearestColumn in version 1 of the
+// game. It shoots from the first column containing aliens when
+// searching from the cannon's column leftwards to column 0, and then
+// rightwards from the column right of the cannon. This was the search
+// order of the original port of MinAtar, which sorted the columns by
+// comparing their indices rather than their distances to the cannon.
+type legacyNearestColumn struct{}
+
+// shooter returns the lowest alien of the first column containing
+// aliens in the legacy search order
+func (legacyNearestColumn) shooter(s *SpaceInvaders) (row, col int) {
+	pos := s.agent.x()
+	for i := 0; i < cols; i++ {
+		col := pos - i
+		if col < 0 {
+			col = i
+		}
+		if r := s.lowestAlien(col); r >= 0 {
+			return r, col
+		}
+	}
+	return -1, -1
+}
+
+// randomColumn implements RandomColumn
+type randomColumn struct{}
+
+// shooter returns the lowest alien of a random column of aliens
+func (randomColumn) shooter(s *SpaceInvaders) (row, col int) {
+	columns := make([]int, 0, cols)
+	for c := 0; c < cols; c++ {
+		if s.aliens.ColSum(c) > 0 {
+			columns = append(columns, c)
+		}
+	}
+	if len(columns) == 0 {
+		return -1, -1
+	}
+
+	col = columns[s.rng.Intn("alien target", len(columns))]
+	return s.lowestAlien(col), col
+}
+
+// leadingShot implements LeadingShot
+type leadingShot struct{}
+
+// shooter returns the lowest alien of the column of aliens nearest to
+// where the cannon will be when a bullet fired from the column reaches
+// the bottom row
+func (leadingShot) shooter(s *SpaceInvaders) (row, col int) {
+	return s.nearestAlien(func(r int) int {
+		// A bullet fired from row r reaches the bottom row after
+		// rows-1-r steps
+		x := s.agent.x() + s.cannonDx*(rows-1-r)
+		return game.MaxInt(0, game.MinInt(x, cols-1))
+	})
+}
+
+// alienCanShoot returns whether the alien at the given row and column,
+// as returned by a targeting strategy, can shoot. In version 1 of the
+// game, aliens in the top row or the leftmost column never shoot.
+func (s *SpaceInvaders) alienCanShoot(row, col int) bool {
+	if s.config.Version == 1 {
+		return row > 0 && col > 0
+	}
+	return row >= 0 && col >= 0
+}
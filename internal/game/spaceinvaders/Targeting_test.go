@@ -0,0 +1,111 @@
+package spaceinvaders
+
+import "testing"
+
+// target is the alien chosen to shoot, and whether it can shoot
+type target struct {
+	row, col int
+	canShoot bool
+}
+
+// TestNearestColumn checks which alien shoots at the cannon with
+// NearestColumn targeting in each version of the game
+func TestNearestColumn(t *testing.T) {
+	tests := []struct {
+		name   string
+		cannon int
+		aliens [][2]int // Column and row of each alien
+
+		// want holds the alien chosen in each version
+		want map[int]target
+	}{
+		{
+			name:   "nearest column right of the cannon",
+			cannon: 4,
+			aliens: [][2]int{{1, 3}, {5, 3}},
+			want: map[int]target{
+				1: {3, 1, true},
+				2: {3, 5, true},
+			},
+		},
+		{
+			name:   "nearest column left of the cannon",
+			cannon: 4,
+			aliens: [][2]int{{3, 2}, {8, 2}},
+			want: map[int]target{
+				1: {2, 3, true},
+				2: {2, 3, true},
+			},
+		},
+		{
+			name:   "tie prefers the left column",
+			cannon: 5,
+			aliens: [][2]int{{7, 1}, {3, 1}},
+			want: map[int]target{
+				1: {1, 3, true},
+				2: {1, 3, true},
+			},
+		},
+		{
+			name:   "lowest alien of the column",
+			cannon: 6,
+			aliens: [][2]int{{6, 1}, {6, 4}, {6, 2}},
+			want: map[int]target{
+				1: {4, 6, true},
+				2: {4, 6, true},
+			},
+		},
+		{
+			name:   "top row",
+			cannon: 4,
+			aliens: [][2]int{{4, 0}},
+			want: map[int]target{
+				1: {0, 4, false},
+				2: {0, 4, true},
+			},
+		},
+		{
+			name:   "leftmost column",
+			cannon: 2,
+			aliens: [][2]int{{0, 3}},
+			want: map[int]target{
+				1: {3, 0, false},
+				2: {3, 0, true},
+			},
+		},
+		{
+			name:   "no aliens",
+			cannon: 4,
+			want: map[int]target{
+				1: {-1, -1, false},
+				2: {-1, -1, false},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		for v, want := range test.want {
+			g, err := NewWithConfig(false, 0, Config{Version: v})
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := g.(*SpaceInvaders)
+			s.Empty()
+			if err := s.PlaceCannon(test.cannon); err != nil {
+				t.Fatal(err)
+			}
+			for _, a := range test.aliens {
+				if err := s.PlaceAlien(a[0], a[1]); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			row, col := s.targeting.shooter(s)
+			got := target{row, col, s.alienCanShoot(row, col)}
+			if got != want {
+				t.Errorf("%v: version %v: got %+v, want %+v", test.name, v,
+					got, want)
+			}
+		}
+	}
+}
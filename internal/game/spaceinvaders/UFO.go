@@ -0,0 +1,66 @@
+package spaceinvaders
+
+import "github.com/samuelfneumann/goatar/internal/game"
+
+const (
+	ufoInterval = 40 // Steps between a UFO leaving and the next arriving
+	ufoReward   = 5
+)
+
+// ufo is the bonus enemy which crosses the top row of the screen, see
+// Config.UFO
+type ufo struct {
+	x, dir int
+	active bool // Whether the UFO is on the screen
+	timer  int  // Steps until the next UFO arrives
+}
+
+// resetUFO removes the UFO from the screen and restarts the timer
+// until the next UFO arrives
+func (s *SpaceInvaders) resetUFO() {
+	s.ufo = ufo{timer: ufoInterval}
+}
+
+// updateUFO moves the UFO one cell across the top row, removing it once
+// it leaves the screen, or sends in a new UFO from a random side of the
+// screen once the timer runs out
+func (s *SpaceInvaders) updateUFO() {
+	if !s.config.UFO {
+		return
+	}
+
+	if s.ufo.active {
+		s.ufo.x += s.ufo.dir
+		if s.ufo.x < 0 || s.ufo.x > cols-1 {
+			s.resetUFO()
+		}
+		return
+	}
+
+	s.ufo.timer--
+	if s.ufo.timer <= 0 {
+		if s.rng.Intn("ufo side", 2) == 0 {
+			s.ufo = ufo{x: 0, dir: 1, active: true}
+		} else {
+			s.ufo = ufo{x: cols - 1, dir: -1, active: true}
+		}
+	}
+}
+
+// shootUFO shoots the UFO if it is at position (x, y), and returns the
+// reward for shooting it
+func (s *SpaceInvaders) shootUFO(x, y int) float64 {
+	if !s.ufo.active || s.ufo.x != x || y != 0 {
+		return 0
+	}
+
+	s.resetUFO()
+	s.rewardEvents = append(s.rewardEvents, game.RewardEvent{
+		Type:   game.Destroy,
+		Amount: ufoReward,
+		X:      x,
+		Y:      y,
+		Entity: "ufo",
+	})
+	return ufoReward
+}
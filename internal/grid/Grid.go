@@ -0,0 +1,190 @@
+// Package grid implements a lightweight, dense, row-major grid of
+// float64 values. The games use grids to track the positions of
+// objects on the screen, so that stepping a game requires no
+// dependencies outside the standard library.
+package grid
+
+import "fmt"
+
+// Grid is a dense, row-major grid of float64 values
+type Grid struct {
+	rows, cols int
+	data       []float64
+}
+
+// New returns a new Grid with the given number of rows and columns. If
+// data is nil, the Grid is filled with zeros. Otherwise, data is used
+// as the backing slice of the Grid in row-major order and must have
+// length rows*cols.
+func New(rows, cols int, data []float64) *Grid {
+	if rows <= 0 || cols <= 0 {
+		panic(fmt.Sprintf("new: invalid dimensions (%v, %v)", rows, cols))
+	}
+	if data == nil {
+		data = make([]float64, rows*cols)
+	} else if len(data) != rows*cols {
+		panic(fmt.Sprintf("new: data of length %v does not match "+
+			"dimensions (%v, %v)", len(data), rows, cols))
+	}
+
+	return &Grid{rows: rows, cols: cols, data: data}
+}
+
+// Dims returns the number of rows and columns of the Grid
+func (g *Grid) Dims() (rows, cols int) {
+	return g.rows, g.cols
+}
+
+// At returns the element at row r and column c
+func (g *Grid) At(r, c int) float64 {
+	return g.data[g.index(r, c)]
+}
+
+// Set sets the element at row r and column c to v
+func (g *Grid) Set(r, c int, v float64) {
+	g.data[g.index(r, c)] = v
+}
+
+// Data returns the backing slice of the Grid in row-major order.
+// Changes to the returned slice are reflected in the Grid.
+func (g *Grid) Data() []float64 {
+	return g.data
+}
+
+// Row returns row r of the Grid. Changes to the returned slice are
+// reflected in the Grid.
+func (g *Grid) Row(r int) []float64 {
+	if r < 0 || r >= g.rows {
+		panic(fmt.Sprintf("row: index out of range [%v] with length %v",
+			r, g.rows))
+	}
+	return g.data[r*g.cols : (r+1)*g.cols]
+}
+
+// SetRow copies row into row r of the Grid
+func (g *Grid) SetRow(r int, row []float64) {
+	if len(row) != g.cols {
+		panic(fmt.Sprintf("setRow: row of length %v does not match %v "+
+			"columns", len(row), g.cols))
+	}
+	copy(g.Row(r), row)
+}
+
+// Col returns a copy of column c of the Grid
+func (g *Grid) Col(c int) []float64 {
+	col := make([]float64, g.rows)
+	for r := range col {
+		col[r] = g.At(r, c)
+	}
+	return col
+}
+
+// SetCol copies col into column c of the Grid
+func (g *Grid) SetCol(c int, col []float64) {
+	if len(col) != g.rows {
+		panic(fmt.Sprintf("setCol: column of length %v does not match %v "+
+			"rows", len(col), g.rows))
+	}
+	for r, v := range col {
+		g.Set(r, c, v)
+	}
+}
+
+// RowSum returns the sum of the elements in row r of the Grid
+func (g *Grid) RowSum(r int) float64 {
+	return sum(g.Row(r))
+}
+
+// ColSum returns the sum of the elements in column c of the Grid
+func (g *Grid) ColSum(c int) float64 {
+	total := 0.0
+	for r := 0; r < g.rows; r++ {
+		total += g.At(r, c)
+	}
+	return total
+}
+
+// Clone returns a deep copy of the Grid
+func (g *Grid) Clone() *Grid {
+	return &Grid{
+		rows: g.rows,
+		cols: g.cols,
+		data: append([]float64(nil), g.data...),
+	}
+}
+
+// Zero sets every element of the Grid to zero
+func (g *Grid) Zero() {
+	for i := range g.data {
+		g.data[i] = 0
+	}
+}
+
+// RollRowsUp rolls the rows of the Grid upwards. Rows that would go
+// off the Grid's top wrap around back to the bottom.
+func (g *Grid) RollRowsUp() {
+	for c := 0; c < g.cols; c++ {
+		first := g.data[c]
+		for r := 0; r < g.rows-1; r++ {
+			g.data[r*g.cols+c] = g.data[(r+1)*g.cols+c]
+		}
+		g.data[(g.rows-1)*g.cols+c] = first
+	}
+}
+
+// RollRowsDown rolls the rows of the Grid downwards. Rows that would
+// go off the Grid's bottom wrap around back to the top.
+func (g *Grid) RollRowsDown() {
+	for c := 0; c < g.cols; c++ {
+		last := g.data[(g.rows-1)*g.cols+c]
+		for r := g.rows - 1; r > 0; r-- {
+			g.data[r*g.cols+c] = g.data[(r-1)*g.cols+c]
+		}
+		g.data[c] = last
+	}
+}
+
+// RollColsLeft rolls the columns of the Grid left. Columns that would
+// go off the Grid's side wrap around back to the other side.
+func (g *Grid) RollColsLeft() {
+	for r := 0; r < g.rows; r++ {
+		row := g.Row(r)
+		first := row[0]
+		copy(row, row[1:])
+		row[g.cols-1] = first
+	}
+}
+
+// RollColsRight rolls the columns of the Grid right. Columns that
+// would go off the Grid's side wrap around back to the other side.
+func (g *Grid) RollColsRight() {
+	for r := 0; r < g.rows; r++ {
+		row := g.Row(r)
+		last := row[g.cols-1]
+		copy(row[1:], row)
+		row[0] = last
+	}
+}
+
+// index returns the index into the backing slice of the element at
+// row r and column c
+func (g *Grid) index(r, c int) int {
+	if r < 0 || r >= g.rows {
+		panic(fmt.Sprintf("at: row index out of range [%v] with length %v",
+			r, g.rows))
+	}
+	if c < 0 || c >= g.cols {
+		panic(fmt.Sprintf("at: column index out of range [%v] with "+
+			"length %v", c, g.cols))
+	}
+	return r*g.cols + c
+}
+
+// sum returns the sum of the elements of values
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
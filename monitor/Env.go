@@ -0,0 +1,38 @@
+package monitor
+
+import (
+	"github.com/samuelfneumann/goatar"
+)
+
+// Monitored wraps an environment so that each of its steps is reported
+// to a Registry
+type Monitored struct {
+	goatar.Env
+	tracker *Tracker
+}
+
+// Monitor returns a new Monitored which reports the steps of env to r
+func Monitor(env goatar.Env, r *Registry) *Monitored {
+	return &Monitored{
+		Env:     env,
+		tracker: r.Track(env.GameName()),
+	}
+}
+
+// Act takes one environmental step given some action a and records
+// the step
+func (m *Monitored) Act(a int) (float64, bool, error) {
+	reward, done, err := m.Env.Act(a)
+	if err != nil {
+		return reward, done, err
+	}
+	m.tracker.Record(reward, done)
+	return reward, done, nil
+}
+
+// Reset resets the environment, discarding the current episode if it
+// has not ended
+func (m *Monitored) Reset() {
+	m.Env.Reset()
+	m.tracker.Reset()
+}
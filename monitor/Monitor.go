@@ -0,0 +1,293 @@
+// Package monitor exports metrics of long-running GoAtar actors, such
+// as the environments of a pool.Pool or of the goatar-http server, so
+// that fleets of actors can be monitored.
+//
+// A Registry counts the steps and episodes taken in each game, and
+// keeps histograms of episode returns and lengths. Each environment
+// reports to the registry through its own Tracker:
+//
+//	reg := monitor.NewRegistry()
+//	reg.Publish("goatar")
+//	http.Handle("/metrics", reg)
+//
+//	tracker := reg.Track(env.GameName())
+//	for {
+//		reward, done, err := env.Act(agent.Act(state))
+//		...
+//		tracker.Record(reward, done)
+//	}
+//
+// A Registry is an http.Handler which serves its metrics in the
+// Prometheus text exposition format, and Publish exports the same
+// metrics as JSON through expvar at /debug/vars.
+package monitor
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// returnBounds are the upper bounds of the buckets of episode return
+// histograms
+var returnBounds = []float64{0, 1, 2, 5, 10, 20, 50, 100, 200, 500, 1000}
+
+// lengthBounds are the upper bounds of the buckets of episode length
+// histograms
+var lengthBounds = []float64{10, 50, 100, 250, 500, 1000, 2500, 5000,
+	10000}
+
+// Histogram is a histogram of values. Counts[i] is the number of
+// values which were at most Bounds[i] and larger than Bounds[i-1]. The
+// final element of Counts counts the values larger than every bound.
+type Histogram struct {
+	Bounds []float64 `json:"bounds"`
+	Counts []int64   `json:"counts"`
+	Count  int64     `json:"count"`
+	Sum    float64   `json:"sum"`
+}
+
+// newHistogram returns a new, empty Histogram with the given bounds
+func newHistogram(bounds []float64) Histogram {
+	return Histogram{
+		Bounds: bounds,
+		Counts: make([]int64, len(bounds)+1),
+	}
+}
+
+// record records a single value in the histogram
+func (h *Histogram) record(v float64) {
+	i := 0
+	for i < len(h.Bounds) && v > h.Bounds[i] {
+		i++
+	}
+	h.Counts[i]++
+	h.Count++
+	h.Sum += v
+}
+
+// Mean returns the mean value recorded in the histogram
+func (h Histogram) Mean() float64 {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / float64(h.Count)
+}
+
+// clone returns a deep copy of the histogram
+func (h Histogram) clone() Histogram {
+	h.Counts = append([]int64(nil), h.Counts...)
+	return h
+}
+
+// GameStats are the metrics of a single game
+type GameStats struct {
+	// Steps and Episodes are the number of steps taken and episodes
+	// completed
+	Steps    int64 `json:"steps"`
+	Episodes int64 `json:"episodes"`
+
+	// StepsPerSecond is the mean number of steps taken per second
+	// since the registry was created
+	StepsPerSecond float64 `json:"steps_per_second"`
+
+	// Returns and Lengths hold the returns and lengths of completed
+	// episodes
+	Returns Histogram `json:"returns"`
+	Lengths Histogram `json:"lengths"`
+}
+
+// gameStats holds the metrics of a single game of a Registry
+type gameStats struct {
+	mu    sync.Mutex
+	stats GameStats
+}
+
+// Registry collects the metrics of environments. It is safe for
+// concurrent use.
+type Registry struct {
+	mu    sync.Mutex
+	games map[string]*gameStats
+	start time.Time
+}
+
+// NewRegistry returns a new, empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		games: make(map[string]*gameStats),
+		start: time.Now(),
+	}
+}
+
+// Track returns a new Tracker which reports the steps of a single
+// environment of the game name to r
+func (r *Registry) Track(name string) *Tracker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	g, ok := r.games[name]
+	if !ok {
+		g = &gameStats{stats: GameStats{
+			Returns: newHistogram(returnBounds),
+			Lengths: newHistogram(lengthBounds),
+		}}
+		r.games[name] = g
+	}
+	return &Tracker{game: g}
+}
+
+// Snapshot returns a copy of the metrics of each game, keyed by game
+// name
+func (r *Registry) Snapshot() map[string]GameStats {
+	r.mu.Lock()
+	games := make(map[string]*gameStats, len(r.games))
+	for name, g := range r.games {
+		games[name] = g
+	}
+	r.mu.Unlock()
+
+	elapsed := time.Since(r.start).Seconds()
+	snapshot := make(map[string]GameStats, len(games))
+	for name, g := range games {
+		g.mu.Lock()
+		stats := g.stats
+		stats.Returns = g.stats.Returns.clone()
+		stats.Lengths = g.stats.Lengths.clone()
+		g.mu.Unlock()
+
+		if elapsed > 0 {
+			stats.StepsPerSecond = float64(stats.Steps) / elapsed
+		}
+		snapshot[name] = stats
+	}
+	return snapshot
+}
+
+// Publish exports the snapshot of r through expvar under the given
+// name. Like expvar.Publish, it panics if the name is already in use.
+func (r *Registry) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return r.Snapshot()
+	}))
+}
+
+// ServeHTTP serves the metrics of r in the Prometheus text exposition
+// format
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	r.WritePrometheus(w)
+}
+
+// WritePrometheus writes the metrics of r to w in the Prometheus text
+// exposition format
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	snapshot := r.Snapshot()
+	names := make([]string, 0, len(snapshot))
+	for name := range snapshot {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	counter := func(metric, help string, value func(GameStats) float64) {
+		fmt.Fprintf(&b, "# HELP %v %v\n# TYPE %v counter\n", metric, help,
+			metric)
+		for _, name := range names {
+			fmt.Fprintf(&b, "%v{game=%v} %v\n", metric, label(name),
+				value(snapshot[name]))
+		}
+	}
+	gauge := func(metric, help string, value func(GameStats) float64) {
+		fmt.Fprintf(&b, "# HELP %v %v\n# TYPE %v gauge\n", metric, help,
+			metric)
+		for _, name := range names {
+			fmt.Fprintf(&b, "%v{game=%v} %v\n", metric, label(name),
+				value(snapshot[name]))
+		}
+	}
+	histogram := func(metric, help string, value func(GameStats) Histogram) {
+		fmt.Fprintf(&b, "# HELP %v %v\n# TYPE %v histogram\n", metric, help,
+			metric)
+		for _, name := range names {
+			h := value(snapshot[name])
+			var cumulative int64
+			for i, bound := range h.Bounds {
+				cumulative += h.Counts[i]
+				fmt.Fprintf(&b, "%v_bucket{game=%v,le=\"%v\"} %v\n", metric,
+					label(name), bound, cumulative)
+			}
+			fmt.Fprintf(&b, "%v_bucket{game=%v,le=\"+Inf\"} %v\n", metric,
+				label(name), h.Count)
+			fmt.Fprintf(&b, "%v_sum{game=%v} %v\n", metric, label(name),
+				h.Sum)
+			fmt.Fprintf(&b, "%v_count{game=%v} %v\n", metric, label(name),
+				h.Count)
+		}
+	}
+
+	counter("goatar_steps_total", "Number of environment steps taken.",
+		func(s GameStats) float64 { return float64(s.Steps) })
+	counter("goatar_episodes_total", "Number of episodes completed.",
+		func(s GameStats) float64 { return float64(s.Episodes) })
+	gauge("goatar_steps_per_second", "Mean number of environment steps "+
+		"taken per second since the registry was created.",
+		func(s GameStats) float64 { return s.StepsPerSecond })
+	histogram("goatar_episode_return", "Returns of completed episodes.",
+		func(s GameStats) Histogram { return s.Returns })
+	histogram("goatar_episode_length", "Lengths of completed episodes in "+
+		"steps.", func(s GameStats) Histogram { return s.Lengths })
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// label returns v as a quoted Prometheus label value
+func label(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
+
+// labelEscaper escapes the characters which must be escaped in
+// Prometheus label values
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Tracker reports the steps of a single environment to a Registry. A
+// Tracker is not safe for concurrent use, but the Trackers of a
+// Registry may be used concurrently.
+type Tracker struct {
+	game *gameStats
+
+	episodeReturn float64
+	episodeLength int
+}
+
+// Record records a step of the environment which received the given
+// reward and on which the episode ended if done
+func (t *Tracker) Record(reward float64, done bool) {
+	t.episodeReturn += reward
+	t.episodeLength++
+
+	t.game.mu.Lock()
+	t.game.stats.Steps++
+	if done {
+		t.game.stats.Episodes++
+		t.game.stats.Returns.record(t.episodeReturn)
+		t.game.stats.Lengths.record(float64(t.episodeLength))
+	}
+	t.game.mu.Unlock()
+
+	if done {
+		t.Reset()
+	}
+}
+
+// Reset discards the current episode, which is not recorded. It should
+// be called when the environment is reset before its episode ends.
+func (t *Tracker) Reset() {
+	t.episodeReturn = 0
+	t.episodeLength = 0
+}
@@ -0,0 +1,18 @@
+package policy_test
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/samuelfneumann/goatar/policy"
+)
+
+func ExampleEpsilonGreedy() {
+	rng := rand.New(rand.NewSource(0))
+	q := []float64{0.1, 0.5, 0.2}
+
+	// With epsilon 0, the greedy action is always chosen
+	fmt.Println(policy.EpsilonGreedy(q, 0, rng))
+	// Output:
+	// 1
+}
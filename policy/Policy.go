@@ -0,0 +1,211 @@
+// Package policy implements action selection helpers and simple
+// policies for GoAtar environments, so that baselines and tests do not
+// need to reimplement sampling.
+//
+// The functions EpsilonGreedy, Boltzmann, and Greedy select an action
+// given action values. The policies returned by Random, Constant, and
+// FromValues implement rollout.Policy, so they can be evaluated
+// directly with rollout.Evaluate:
+//
+//	result, err := rollout.Evaluate(env, policy.Random(env), 100)
+package policy
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/rollout"
+)
+
+// Greedy returns the index of the largest value in q, breaking ties
+// uniformly at random using rng
+func Greedy(q []float64, rng *rand.Rand) int {
+	best := 0
+	ties := 1
+	for i := 1; i < len(q); i++ {
+		switch {
+		case q[i] > q[best]:
+			best = i
+			ties = 1
+
+		case q[i] == q[best]:
+			// Reservoir sampling selects each tied action with equal
+			// probability in a single pass
+			ties++
+			if rng.Intn(ties) == 0 {
+				best = i
+			}
+		}
+	}
+	return best
+}
+
+// EpsilonGreedy returns a uniformly random index of q with probability
+// eps, and otherwise returns the greedy index of q, breaking ties
+// uniformly at random using rng
+func EpsilonGreedy(q []float64, eps float64, rng *rand.Rand) int {
+	if rng.Float64() < eps {
+		return rng.Intn(len(q))
+	}
+	return Greedy(q, rng)
+}
+
+// Boltzmann samples an index of q from the softmax distribution over
+// q with the given temperature. A temperature of 0 is greedy.
+func Boltzmann(q []float64, temperature float64, rng *rand.Rand) int {
+	if temperature <= 0 {
+		return Greedy(q, rng)
+	}
+
+	// Subtract the maximum value for numerical stability
+	max := math.Inf(-1)
+	for _, v := range q {
+		max = math.Max(max, v)
+	}
+
+	weights := make([]float64, len(q))
+	total := 0.0
+	for i, v := range q {
+		weights[i] = math.Exp((v - max) / temperature)
+		total += weights[i]
+	}
+
+	u := rng.Float64() * total
+	for i, w := range weights {
+		if u < w {
+			return i
+		}
+		u -= w
+	}
+	return len(q) - 1
+}
+
+// config holds the configuration of a policy
+type config struct {
+	seed    int64
+	minimal bool
+}
+
+// Option configures a policy
+type Option func(*config)
+
+// WithSeed seeds the random number generator of a policy. By default,
+// policies use seed 0.
+func WithSeed(seed int64) Option {
+	return func(c *config) {
+		c.seed = seed
+	}
+}
+
+// WithFullActionSet makes a policy choose from the full action set of
+// the environment. By default, policies choose only from the minimal
+// action set.
+func WithFullActionSet() Option {
+	return func(c *config) {
+		c.minimal = false
+	}
+}
+
+// newConfig returns the configuration given by opts
+func newConfig(opts []Option) config {
+	c := config{minimal: true}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// actions returns the actions of env from which a policy chooses
+func (c config) actions(env goatar.Env) []int {
+	if c.minimal {
+		return env.MinimalActionSet()
+	}
+	return env.FullActionSet()
+}
+
+// sampler is a random number generator which is safe for concurrent
+// use, so that policies can be used by many rollout workers
+type sampler struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// newSampler returns a new sampler seeded with seed
+func newSampler(seed int64) *sampler {
+	return &sampler{rng: rand.New(rand.NewSource(seed))}
+}
+
+// do calls f with the random number generator of the sampler
+func (s *sampler) do(f func(rng *rand.Rand) int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return f(s.rng)
+}
+
+// Random returns a policy which selects actions of env uniformly at
+// random. The policy is safe for concurrent use.
+func Random(env goatar.Env, opts ...Option) rollout.Policy {
+	c := newConfig(opts)
+	actions := c.actions(env)
+	s := newSampler(c.seed)
+
+	return rollout.PolicyFunc(func([]float64) (int, error) {
+		return s.do(func(rng *rand.Rand) int {
+			return actions[rng.Intn(len(actions))]
+		}), nil
+	})
+}
+
+// Constant returns a policy which always selects action a
+func Constant(a int) rollout.Policy {
+	return rollout.PolicyFunc(func([]float64) (int, error) {
+		return a, nil
+	})
+}
+
+// ValueFunc returns the value of each action of an environment in a
+// state, where element i of the returned slice is the value of action
+// i
+type ValueFunc func(state []float64) ([]float64, error)
+
+// FromValues returns a policy which selects actions of env using
+// choose, given the action values returned by q. Element i of the
+// action values must be the value of action i of env. If the policy
+// chooses from the minimal action set, choose only chooses between the
+// values of the actions in the minimal action set. The policy is safe
+// for concurrent use if q is.
+//
+// For example, an epsilon-greedy policy is given by:
+//
+//	policy.FromValues(env, q, func(q []float64, rng *rand.Rand) int {
+//		return policy.EpsilonGreedy(q, 0.05, rng)
+//	})
+func FromValues(env goatar.Env, q ValueFunc,
+	choose func(q []float64, rng *rand.Rand) int,
+	opts ...Option) rollout.Policy {
+	c := newConfig(opts)
+	actions := c.actions(env)
+	s := newSampler(c.seed)
+
+	return rollout.PolicyFunc(func(state []float64) (int, error) {
+		values, err := q(state)
+		if err != nil {
+			return -1, fmt.Errorf("act: %v", err)
+		}
+
+		subset := make([]float64, len(actions))
+		for i, a := range actions {
+			if a >= len(values) {
+				return -1, fmt.Errorf("act: no value for action %v", a)
+			}
+			subset[i] = values[a]
+		}
+
+		return actions[s.do(func(rng *rand.Rand) int {
+			return choose(subset, rng)
+		})], nil
+	})
+}
@@ -0,0 +1,59 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/pool"
+)
+
+func ExampleNew() {
+	p, err := pool.New(goatar.Breakout, 4, pool.WithSeed(1),
+		pool.WithWorkers(2))
+	if err != nil {
+		panic(err)
+	}
+	defer p.Close()
+
+	// The states of all environments are written to a single buffer
+	states := make([]float64, p.Len()*p.StateSize())
+	if err := p.Reset(states); err != nil {
+		panic(err)
+	}
+
+	actions := make([]int, p.Len())
+	var total float64
+	for step := 0; step < 500; step++ {
+		for i := range actions {
+			actions[i] = (step + i) % p.NumActions()
+		}
+		rewards, _, err := p.Step(actions, states)
+		if err != nil {
+			panic(err)
+		}
+		for _, r := range rewards {
+			total += r
+		}
+	}
+	fmt.Println(p.Len(), p.StateSize(), total)
+	// Output:
+	// 4 400 116
+}
+
+func ExamplePool_StepCtx() {
+	p, err := pool.New(goatar.Breakout, 4)
+	if err != nil {
+		panic(err)
+	}
+	defer p.Close()
+
+	// A cancelled request does not step the environments
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = p.StepCtx(ctx, make([]int, p.Len()), nil)
+	fmt.Println(errors.Is(err, context.Canceled))
+	// Output:
+	// true
+}
@@ -0,0 +1,172 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+)
+
+// Layout is the memory layout of the observations of a pool in a
+// contiguous buffer
+type Layout int
+
+const (
+	// NCHW stores observations as (environment, channel, row, col), so
+	// that each environment's observation has the layout returned by
+	// goatar.Environment.State
+	NCHW Layout = iota
+
+	// NHWC stores observations as (environment, row, col, channel), so
+	// that the channels of each cell are contiguous
+	NHWC
+)
+
+// String returns the name of the layout
+func (l Layout) String() string {
+	switch l {
+	case NCHW:
+		return "NCHW"
+	case NHWC:
+		return "NHWC"
+	default:
+		return fmt.Sprintf("Layout(%d)", int(l))
+	}
+}
+
+// SendFloat32 is like Send, but copies the states of the environments
+// into a []float32 buffer of Len() × StateSize() elements with the
+// given layout. The buffer can be shared with tensor libraries or
+// passed through cgo without conversion.
+func (p *Pool) SendFloat32(actions []int, states []float32,
+	layout Layout) error {
+	buf, err := p.float32Buffer(states, layout)
+	if err != nil {
+		return fmt.Errorf("sendFloat32: %v", err)
+	}
+	if err := p.sendActions(context.Background(), actions, buf); err != nil {
+		return fmt.Errorf("sendFloat32: %v", err)
+	}
+	return nil
+}
+
+// StepFloat32 is like Step, but copies states into a []float32 buffer
+// with the given layout as in SendFloat32
+func (p *Pool) StepFloat32(actions []int, states []float32,
+	layout Layout) (rewards []float64, dones []bool, err error) {
+	if err := p.SendFloat32(actions, states, layout); err != nil {
+		return nil, nil, fmt.Errorf("stepFloat32: %v", err)
+	}
+
+	rewards, dones, err = p.Recv()
+	if err != nil {
+		return nil, nil, fmt.Errorf("stepFloat32: %v", err)
+	}
+	return rewards, dones, nil
+}
+
+// ResetFloat32 is like Reset, but copies the initial states into a
+// []float32 buffer with the given layout as in SendFloat32
+func (p *Pool) ResetFloat32(states []float32, layout Layout) error {
+	buf, err := p.float32Buffer(states, layout)
+	if err != nil {
+		return fmt.Errorf("resetFloat32: %v", err)
+	}
+	if err := p.reset(context.Background(), buf); err != nil {
+		return fmt.Errorf("resetFloat32: %v", err)
+	}
+	return nil
+}
+
+// StatesFloat32 is like States, but copies the current states into a
+// []float32 buffer with the given layout
+func (p *Pool) StatesFloat32(states []float32, layout Layout) error {
+	if states == nil {
+		return fmt.Errorf("statesFloat32: nil state buffer")
+	}
+
+	buf, err := p.float32Buffer(states, layout)
+	if err != nil {
+		return fmt.Errorf("statesFloat32: %v", err)
+	}
+	if err := p.states(buf); err != nil {
+		return fmt.Errorf("statesFloat32: %v", err)
+	}
+	return nil
+}
+
+// float32Buffer returns states as a buffer with the given layout, or
+// nil if states is nil
+func (p *Pool) float32Buffer(states []float32, layout Layout) (buffer,
+	error) {
+	if layout != NCHW && layout != NHWC {
+		return nil, fmt.Errorf("unknown layout %v", layout)
+	}
+	if states == nil {
+		return nil, nil
+	}
+
+	shape := p.Shape()
+	return &float32Buffer{
+		data:     states,
+		layout:   layout,
+		channels: shape.Channels,
+		cells:    shape.ChannelSize(),
+	}, nil
+}
+
+// buffer is a contiguous buffer holding the state observations of
+// every environment of a pool. Each environment's observation is
+// written by a single worker, so distinct environments may be written
+// concurrently.
+type buffer interface {
+	// len returns the number of elements in the buffer
+	len() int
+
+	// put writes the state observation of environment i, in the
+	// layout returned by goatar.Environment.State
+	put(i int, state []float64)
+}
+
+// float64Buffer is a []float64 buffer with the NCHW layout
+type float64Buffer []float64
+
+// len returns the number of elements in the buffer
+func (b float64Buffer) len() int {
+	return len(b)
+}
+
+// put writes the state observation of environment i
+func (b float64Buffer) put(i int, state []float64) {
+	copy(b[i*len(state):(i+1)*len(state)], state)
+}
+
+// float32Buffer is a []float32 buffer with an arbitrary layout
+type float32Buffer struct {
+	data     []float32
+	layout   Layout
+	channels int
+	cells    int // Number of cells in a single channel
+}
+
+// len returns the number of elements in the buffer
+func (b *float32Buffer) len() int {
+	return len(b.data)
+}
+
+// put writes the state observation of environment i
+func (b *float32Buffer) put(i int, state []float64) {
+	dst := b.data[i*len(state) : (i+1)*len(state)]
+
+	if b.layout == NCHW {
+		for j, v := range state {
+			dst[j] = float32(v)
+		}
+		return
+	}
+
+	for ch := 0; ch < b.channels; ch++ {
+		src := state[ch*b.cells : (ch+1)*b.cells]
+		for cell, v := range src {
+			dst[cell*b.channels+ch] = float32(v)
+		}
+	}
+}
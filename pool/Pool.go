@@ -0,0 +1,518 @@
+// Package pool implements a pool of GoAtar environments which are
+// stepped in parallel, for high-throughput training.
+//
+// Each environment in a Pool is pinned to a single worker goroutine,
+// which is in turn locked to an OS thread, so that an environment's
+// memory stays local to the thread which steps it. Environments are
+// stepped with a request/response API: Send hands one action per
+// environment to the workers and returns immediately, and Recv waits
+// for the workers to finish. While stepping, each worker copies the
+// states of its environments into a caller-provided contiguous buffer
+// of n × channels × rows × cols elements, which can be handed directly
+// to a learner without further copying. Observations can also be
+// written as float32 in either the NCHW or NHWC layout with
+// SendFloat32, for zero-copy interop with tensor libraries:
+//
+//	p, err := pool.New(goatar.Breakout, 64, pool.WithWorkers(8))
+//	...
+//	defer p.Close()
+//	states := make([]float64, p.Len()*p.StateSize())
+//	p.States(states)
+//	for {
+//		actions := agent.Act(states)
+//		rewards, dones, err := p.Step(actions, states)
+//		...
+//	}
+//
+// SendCtx, StepCtx, and ResetCtx take a context.Context, so that a
+// long-running service can time out or cancel a request. Workers check
+// the context before stepping each of their environments, so a
+// cancelled request returns after at most one step per worker, and
+// never leaves a worker busy once it has returned.
+package pool
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/monitor"
+)
+
+// config holds the configuration of a Pool
+type config struct {
+	stickyActionsProb float64
+	ramping           bool
+	seed              int64
+	workers           int
+	envOpts           []goatar.Option
+	registry          *monitor.Registry
+}
+
+// Option configures a Pool
+type Option func(*config)
+
+// WithStickyActions sets the sticky actions probability of each
+// environment. By default, actions are not sticky.
+func WithStickyActions(prob float64) Option {
+	return func(c *config) {
+		c.stickyActionsProb = prob
+	}
+}
+
+// WithRamping enables difficulty ramping in each environment. By
+// default, difficulty ramping is disabled.
+func WithRamping() Option {
+	return func(c *config) {
+		c.ramping = true
+	}
+}
+
+// WithSeed seeds the environments of the pool. Environment i is
+// seeded with seed+i. By default, the first environment uses seed 0.
+func WithSeed(seed int64) Option {
+	return func(c *config) {
+		c.seed = seed
+	}
+}
+
+// WithWorkers steps the environments using n worker goroutines. By
+// default, runtime.GOMAXPROCS(0) workers are used. There are never
+// more workers than environments.
+func WithWorkers(n int) Option {
+	return func(c *config) {
+		c.workers = n
+	}
+}
+
+// WithEnvOptions applies opts to each environment when it is created
+func WithEnvOptions(opts ...goatar.Option) Option {
+	return func(c *config) {
+		c.envOpts = append(c.envOpts, opts...)
+	}
+}
+
+// WithMonitor reports the steps of each environment to r, so that the
+// throughput and episode returns of the pool can be monitored
+func WithMonitor(r *monitor.Registry) Option {
+	return func(c *config) {
+		c.registry = r
+	}
+}
+
+// Pool is a pool of environments of the same game which are stepped in
+// parallel by worker goroutines. Environments which terminate are reset
+// automatically, so that the states copied after a step are always
+// states of ongoing episodes.
+//
+// A Pool must only be used by one goroutine at a time.
+type Pool struct {
+	envs     []*goatar.Environment
+	trackers []*monitor.Tracker // Tracker of each environment, may be nil
+	workers  []*worker
+	size     int // Number of elements in a single state observation
+
+	rewards []float64
+	dones   []bool
+
+	pending bool // Whether a request has been sent but not received
+	closed  bool
+}
+
+// request is a request from a Pool to a worker
+type request struct {
+	ctx     context.Context
+	reset   bool   // Reset rather than step the environments
+	actions []int  // Action of each environment of the pool
+	states  buffer // Buffer for the states of the pool, may be nil
+}
+
+// worker steps a contiguous range of the environments of a Pool
+type worker struct {
+	pool       *Pool
+	start, end int // Range of environments stepped by the worker
+
+	requests chan request
+	done     chan error
+}
+
+// New returns a new Pool of n environments of the game name
+func New(name goatar.GameName, n int, opts ...Option) (*Pool, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("new: number of environments must be "+
+			"positive but got %v", n)
+	}
+
+	c := config{workers: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.workers <= 0 {
+		return nil, fmt.Errorf("new: workers must be positive but got %v",
+			c.workers)
+	}
+	if c.workers > n {
+		c.workers = n
+	}
+
+	envs := make([]*goatar.Environment, n)
+	for i := range envs {
+		env, err := goatar.New(name, c.stickyActionsProb, c.ramping,
+			c.seed+int64(i), c.envOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("new: %v", err)
+		}
+		envs[i] = env
+	}
+
+	p := &Pool{
+		envs:    envs,
+		size:    envs[0].Shape().Size(),
+		rewards: make([]float64, n),
+		dones:   make([]bool, n),
+	}
+	if c.registry != nil {
+		p.trackers = make([]*monitor.Tracker, n)
+		for i := range p.trackers {
+			p.trackers[i] = c.registry.Track(envs[i].GameName())
+		}
+	}
+
+	// Split the environments as evenly as possible between workers
+	for i := 0; i < c.workers; i++ {
+		w := &worker{
+			pool:     p,
+			start:    i * n / c.workers,
+			end:      (i + 1) * n / c.workers,
+			requests: make(chan request),
+			done:     make(chan error, 1),
+		}
+		p.workers = append(p.workers, w)
+		go w.run()
+	}
+
+	return p, nil
+}
+
+// Len returns the number of environments in the pool
+func (p *Pool) Len() int {
+	return len(p.envs)
+}
+
+// Workers returns the number of worker goroutines of the pool
+func (p *Pool) Workers() int {
+	return len(p.workers)
+}
+
+// Shape returns the shape of the state observations of a single
+// environment
+func (p *Pool) Shape() goatar.Shape {
+	return p.envs[0].Shape()
+}
+
+// StateShape returns the shape of the state observations of a single
+// environment as (channels, rows, cols)
+func (p *Pool) StateShape() []int {
+	return p.envs[0].StateShape()
+}
+
+// StateSize returns the number of elements in the state observation of
+// a single environment
+func (p *Pool) StateSize() int {
+	return p.size
+}
+
+// NumActions returns the number of actions of each environment
+func (p *Pool) NumActions() int {
+	return p.envs[0].NumActions()
+}
+
+// Env returns environment i of the pool. The environment must not be
+// used while a request is pending.
+func (p *Pool) Env(i int) *goatar.Environment {
+	return p.envs[i]
+}
+
+// Send requests that environment i take action actions[i], for each
+// environment in the pool, and returns without waiting for the steps
+// to be taken. If states is not nil, the state of environment i after
+// its step is copied into states[i*StateSize():(i+1)*StateSize()].
+// Neither actions nor states may be modified until Recv returns.
+func (p *Pool) Send(actions []int, states []float64) error {
+	err := p.sendActions(context.Background(), actions,
+		p.float64Buffer(states))
+	if err != nil {
+		return fmt.Errorf("send: %v", err)
+	}
+	return nil
+}
+
+// SendCtx is like Send, but the request is cancelled if ctx is done
+// before every environment has been stepped. The environments stepped
+// before cancellation keep their new states, those which were not
+// stepped keep their old states, and Recv returns an error wrapping
+// ctx.Err(). Since the environments may then be at different steps,
+// the pool should usually be Reset or closed after a cancellation.
+func (p *Pool) SendCtx(ctx context.Context, actions []int,
+	states []float64) error {
+	err := p.sendActions(ctx, actions, p.float64Buffer(states))
+	if err != nil {
+		return fmt.Errorf("sendCtx: %w", err)
+	}
+	return nil
+}
+
+// Recv waits for the request made with Send to complete and returns
+// the reward and whether the episode ended for each environment. The
+// returned slices are owned by the pool and are overwritten by the
+// next request.
+func (p *Pool) Recv() (rewards []float64, dones []bool, err error) {
+	if !p.pending {
+		return nil, nil, fmt.Errorf("recv: no pending request")
+	}
+
+	if err := p.wait(); err != nil {
+		return nil, nil, fmt.Errorf("recv: %w", err)
+	}
+	return p.rewards, p.dones, nil
+}
+
+// Step takes action actions[i] in environment i, for each environment
+// in the pool, and waits for the steps to complete. It is equivalent
+// to Send followed by Recv.
+func (p *Pool) Step(actions []int, states []float64) (rewards []float64,
+	dones []bool, err error) {
+	if err := p.Send(actions, states); err != nil {
+		return nil, nil, fmt.Errorf("step: %v", err)
+	}
+
+	rewards, dones, err = p.Recv()
+	if err != nil {
+		return nil, nil, fmt.Errorf("step: %v", err)
+	}
+	return rewards, dones, nil
+}
+
+// StepCtx is like Step, but the request is cancelled if ctx is done, as
+// with SendCtx
+func (p *Pool) StepCtx(ctx context.Context, actions []int,
+	states []float64) (rewards []float64, dones []bool, err error) {
+	if err := p.SendCtx(ctx, actions, states); err != nil {
+		return nil, nil, fmt.Errorf("stepCtx: %w", err)
+	}
+
+	rewards, dones, err = p.Recv()
+	if err != nil {
+		return nil, nil, fmt.Errorf("stepCtx: %w", err)
+	}
+	return rewards, dones, nil
+}
+
+// Reset resets every environment in the pool. If states is not nil,
+// the initial states are copied into states as in Send.
+func (p *Pool) Reset(states []float64) error {
+	err := p.reset(context.Background(), p.float64Buffer(states))
+	if err != nil {
+		return fmt.Errorf("reset: %v", err)
+	}
+	return nil
+}
+
+// ResetCtx is like Reset, but is cancelled if ctx is done before every
+// environment has been reset. Environments which were not reset keep
+// their old states.
+func (p *Pool) ResetCtx(ctx context.Context, states []float64) error {
+	if err := p.reset(ctx, p.float64Buffer(states)); err != nil {
+		return fmt.Errorf("resetCtx: %w", err)
+	}
+	return nil
+}
+
+// States copies the current state of environment i into
+// states[i*StateSize():(i+1)*StateSize()], for each environment in the
+// pool
+func (p *Pool) States(states []float64) error {
+	if err := p.states(float64Buffer(states)); err != nil {
+		return fmt.Errorf("states: %v", err)
+	}
+	return nil
+}
+
+// Close stops the workers of the pool. The pool cannot be used once it
+// has been closed.
+func (p *Pool) Close() error {
+	if p.closed {
+		return fmt.Errorf("close: pool already closed")
+	}
+	if p.pending {
+		p.wait()
+	}
+
+	for _, w := range p.workers {
+		close(w.requests)
+	}
+	p.closed = true
+	return nil
+}
+
+// sendActions sends a request to step the environments of the pool,
+// copying states into states if it is not nil
+func (p *Pool) sendActions(ctx context.Context, actions []int,
+	states buffer) error {
+	if err := p.checkSend(states); err != nil {
+		return err
+	}
+	if len(actions) != len(p.envs) {
+		return fmt.Errorf("expected %v actions but got %v", len(p.envs),
+			len(actions))
+	}
+
+	p.send(request{ctx: ctx, actions: actions, states: states})
+	return nil
+}
+
+// reset resets the environments of the pool, copying the initial
+// states into states if it is not nil
+func (p *Pool) reset(ctx context.Context, states buffer) error {
+	if err := p.checkSend(states); err != nil {
+		return err
+	}
+
+	p.send(request{ctx: ctx, reset: true, states: states})
+	return p.wait()
+}
+
+// states copies the current states of the environments of the pool
+// into states
+func (p *Pool) states(states buffer) error {
+	if p.pending {
+		return fmt.Errorf("request pending")
+	}
+	if err := p.checkBuffer(states); err != nil {
+		return err
+	}
+
+	for i, env := range p.envs {
+		if err := p.copyState(env, i, states); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSend returns an error if a request cannot be sent with the
+// state buffer states
+func (p *Pool) checkSend(states buffer) error {
+	if p.closed {
+		return fmt.Errorf("pool closed")
+	}
+	if p.pending {
+		return fmt.Errorf("request pending")
+	}
+	if states != nil {
+		return p.checkBuffer(states)
+	}
+	return nil
+}
+
+// checkBuffer returns an error if states cannot hold the states of all
+// environments in the pool
+func (p *Pool) checkBuffer(states buffer) error {
+	if states.len() != len(p.envs)*p.size {
+		return fmt.Errorf("expected state buffer of length %v but got %v",
+			len(p.envs)*p.size, states.len())
+	}
+	return nil
+}
+
+// float64Buffer returns states as a buffer, or nil if states is nil
+func (p *Pool) float64Buffer(states []float64) buffer {
+	if states == nil {
+		return nil
+	}
+	return float64Buffer(states)
+}
+
+// send sends req to every worker
+func (p *Pool) send(req request) {
+	for _, w := range p.workers {
+		w.requests <- req
+	}
+	p.pending = true
+}
+
+// wait waits for every worker to complete the pending request and
+// returns the first error encountered by a worker
+func (p *Pool) wait() error {
+	var err error
+	for _, w := range p.workers {
+		if werr := <-w.done; werr != nil && err == nil {
+			err = werr
+		}
+	}
+	p.pending = false
+	return err
+}
+
+// copyState copies the state of env, which is environment i of the
+// pool, into its slot of states
+func (p *Pool) copyState(env *goatar.Environment, i int,
+	states buffer) error {
+	state, err := env.State()
+	if err != nil {
+		return fmt.Errorf("environment %v: %v", i, err)
+	}
+	states.put(i, state)
+	return nil
+}
+
+// run handles the requests made to the worker until its request channel
+// is closed
+func (w *worker) run() {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	for req := range w.requests {
+		w.done <- w.handle(req)
+	}
+}
+
+// handle steps or resets the worker's environments as requested
+func (w *worker) handle(req request) error {
+	p := w.pool
+	for i := w.start; i < w.end; i++ {
+		if err := req.ctx.Err(); err != nil {
+			return err
+		}
+		env := p.envs[i]
+
+		if req.reset {
+			env.Reset()
+			p.rewards[i], p.dones[i] = 0, false
+			if p.trackers != nil {
+				p.trackers[i].Reset()
+			}
+		} else {
+			reward, done, err := env.Act(req.actions[i])
+			if err != nil {
+				return fmt.Errorf("environment %v: %v", i, err)
+			}
+			p.rewards[i], p.dones[i] = reward, done
+			if p.trackers != nil {
+				p.trackers[i].Record(reward, done)
+			}
+
+			if done {
+				env.Reset()
+			}
+		}
+
+		if req.states != nil {
+			if err := p.copyState(env, i, req.states); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
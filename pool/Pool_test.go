@@ -0,0 +1,188 @@
+package pool_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/pool"
+)
+
+// TestPoolStates checks that the states copied into each kind of
+// buffer are the states of the environments of the pool
+func TestPoolStates(t *testing.T) {
+	const n = 5
+	p, err := pool.New(goatar.SpaceInvaders, n, pool.WithSeed(3),
+		pool.WithWorkers(2), pool.WithStickyActions(0.1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	shape := p.Shape()
+	size := p.StateSize()
+	if size != shape.Size() {
+		t.Fatalf("got state size %v, want %v", size, shape.Size())
+	}
+
+	// check checks the buffers against the state of each environment
+	check := func(step int, states []float64, nchw, nhwc []float32) {
+		for i := 0; i < n; i++ {
+			want, err := p.Env(i).State()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for j, v := range want {
+				ch, cell := j/shape.ChannelSize(), j%shape.ChannelSize()
+				if states[i*size+j] != v || nchw[i*size+j] != float32(v) ||
+					nhwc[i*size+cell*shape.Channels+ch] != float32(v) {
+					t.Fatalf("step %v: environment %v: state differs at "+
+						"channel %v cell %v", step, i, ch, cell)
+				}
+			}
+		}
+	}
+
+	states := make([]float64, n*size)
+	nchw := make([]float32, n*size)
+	nhwc := make([]float32, n*size)
+	if err := p.Reset(states); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.StatesFloat32(nchw, pool.NCHW); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.StatesFloat32(nhwc, pool.NHWC); err != nil {
+		t.Fatal(err)
+	}
+	check(0, states, nchw, nhwc)
+
+	actions := make([]int, n)
+	for step := 1; step <= 200; step++ {
+		for i := range actions {
+			actions[i] = (step + i) % goatar.NumActions
+		}
+		if _, _, err := p.Step(actions, states); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.StatesFloat32(nchw, pool.NCHW); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.StatesFloat32(nhwc, pool.NHWC); err != nil {
+			t.Fatal(err)
+		}
+		check(step, states, nchw, nhwc)
+
+		// States copied by a step with a float32 buffer match too
+		if _, _, err := p.StepFloat32(actions, nhwc, pool.NHWC); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.States(states); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.StatesFloat32(nchw, pool.NCHW); err != nil {
+			t.Fatal(err)
+		}
+		check(step, states, nchw, nhwc)
+	}
+}
+
+// TestPoolBufferSize checks that buffers of the wrong size and action
+// slices of the wrong length are rejected
+func TestPoolBufferSize(t *testing.T) {
+	const n = 3
+	p, err := pool.New(goatar.Breakout, n, pool.WithWorkers(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	size := n * p.StateSize()
+	actions := make([]int, n)
+	for _, length := range []int{0, size - 1, size + 1} {
+		if _, _, err := p.Step(actions, make([]float64, length)); err ==
+			nil {
+			t.Errorf("step: expected error for buffer of length %v",
+				length)
+		}
+		if err := p.Reset(make([]float64, length)); err == nil {
+			t.Errorf("reset: expected error for buffer of length %v",
+				length)
+		}
+		if err := p.States(make([]float64, length)); err == nil {
+			t.Errorf("states: expected error for buffer of length %v",
+				length)
+		}
+		for _, layout := range []pool.Layout{pool.NCHW, pool.NHWC} {
+			buf := make([]float32, length)
+			if _, _, err := p.StepFloat32(actions, buf, layout); err ==
+				nil {
+				t.Errorf("stepFloat32: expected error for %v buffer of "+
+					"length %v", layout, length)
+			}
+			if err := p.ResetFloat32(buf, layout); err == nil {
+				t.Errorf("resetFloat32: expected error for %v buffer of "+
+					"length %v", layout, length)
+			}
+		}
+	}
+
+	for _, length := range []int{n - 1, n + 1} {
+		if _, _, err := p.Step(make([]int, length), nil); err == nil {
+			t.Errorf("expected error for %v actions", length)
+		}
+	}
+	if err := p.ResetFloat32(make([]float32, size), pool.Layout(2)); err ==
+		nil {
+		t.Error("expected error for unknown layout")
+	}
+
+	// Rejected requests leave the pool usable
+	if _, _, err := p.Step(actions, make([]float64, size)); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestPoolClose checks that closing a pool stops its workers, and that
+// a closed pool cannot be used
+func TestPoolClose(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	p, err := pool.New(goatar.Freeway, 8, pool.WithWorkers(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := make([]int, p.Len())
+	if _, _, err := p.Step(actions, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Close waits for a pending request
+	if err := p.Send(actions, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Workers exit after Close returns, so wait for them to finish
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%v goroutines still running after close",
+			after-before)
+	}
+
+	if err := p.Close(); err == nil {
+		t.Error("expected error closing twice")
+	}
+	if _, _, err := p.Step(actions, nil); err == nil {
+		t.Error("expected error stepping a closed pool")
+	}
+	if err := p.Reset(nil); err == nil {
+		t.Error("expected error resetting a closed pool")
+	}
+}
@@ -0,0 +1,60 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// Colours used by Diff
+var (
+	DiffAppeared    = color.RGBA{0, 200, 0, 255}   // Cell became active
+	DiffDisappeared = color.RGBA{200, 0, 0, 255}   // Cell became inactive
+	DiffChanged     = color.RGBA{200, 200, 0, 255} // Both of the above
+	DiffUnchanged   = color.RGBA{64, 64, 64, 255}  // Active in both states
+	DiffEmpty       = color.RGBA{0, 0, 0, 255}     // Inactive in both
+)
+
+// Diff renders the difference between two state observations of the
+// given shape as an image with one pixel per cell. A cell is
+// DiffAppeared if some channel became active at the cell,
+// DiffDisappeared if some channel became inactive, and DiffChanged if
+// both happened. Cells which did not change are DiffUnchanged if some
+// channel is active and DiffEmpty otherwise. Diff panics if the
+// lengths of prev or curr do not match shape.
+func Diff(prev, curr []float64, shape []int) image.Image {
+	channels, rows, cols := checkShape(curr, shape)
+	if len(prev) != len(curr) {
+		panic(fmt.Sprintf("render: previous state of length %v does not "+
+			"match shape %v", len(prev), shape))
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, cols, rows))
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			var appeared, disappeared, active bool
+			for ch := 0; ch < channels; ch++ {
+				i := ch*rows*cols + r*cols + c
+				before, after := prev[i] != 0, curr[i] != 0
+				appeared = appeared || (after && !before)
+				disappeared = disappeared || (before && !after)
+				active = active || after
+			}
+
+			colour := DiffEmpty
+			switch {
+			case appeared && disappeared:
+				colour = DiffChanged
+			case appeared:
+				colour = DiffAppeared
+			case disappeared:
+				colour = DiffDisappeared
+			case active:
+				colour = DiffUnchanged
+			}
+			img.SetRGBA(c, r, colour)
+		}
+	}
+
+	return img
+}
@@ -0,0 +1,64 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// HUD is a heads-up display of information about an episode, which
+// is drawn below a frame by Overlay
+type HUD struct {
+	Step   int
+	Return float64
+	Action string // Name of the last action taken, omitted if empty
+	Ramp   int    // Difficulty ramp index
+}
+
+// Lines returns the lines of text shown by the HUD
+func (h HUD) Lines() []string {
+	lines := []string{
+		fmt.Sprintf("step %v", h.Step),
+		fmt.Sprintf("return %v", h.Return),
+	}
+	if h.Action != "" {
+		lines = append(lines, fmt.Sprintf("action %v", h.Action))
+	}
+	return append(lines, fmt.Sprintf("ramp %v", h.Ramp))
+}
+
+// Overlay returns a copy of img with hud drawn in a strip below it
+func Overlay(img *image.RGBA, hud HUD, palette Palette) *image.RGBA {
+	return Caption(img, hud.Lines(), palette.Background(), color.White)
+}
+
+// Caption returns a copy of img with lines of text drawn in a strip
+// below it. Text is drawn at double size if it fits the width of img.
+func Caption(img *image.RGBA, lines []string, background,
+	foreground color.Color) *image.RGBA {
+	const margin = 2
+
+	// Draw text at double size if every line fits the image
+	b := img.Bounds()
+	scale := 2
+	for _, line := range lines {
+		if w, _ := TextSize(line, scale); w > b.Dx()-2*margin {
+			scale = 1
+		}
+	}
+
+	lineHeight := (glyphHeight + 2) * scale
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(),
+		b.Dy()+len(lines)*lineHeight+2*margin))
+	fill(out, out.Bounds(), rgba(background))
+	for y := 0; y < b.Dy(); y++ {
+		copy(out.Pix[out.PixOffset(0, y):], img.Pix[img.PixOffset(b.Min.X,
+			b.Min.Y+y):img.PixOffset(b.Max.X, b.Min.Y+y)])
+	}
+
+	for i, line := range lines {
+		DrawText(out, margin, b.Dy()+margin+i*lineHeight, line, foreground,
+			scale)
+	}
+	return out
+}
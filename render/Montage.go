@@ -0,0 +1,110 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// montageGap is the number of pixels between the tiles of a montage
+const montageGap = 4
+
+// Montage renders each channel of a state observation of the given
+// shape as its own grayscale tile, and arranges the tiles in a grid,
+// in channel order from left to right and top to bottom. Unlike Frame,
+// which colours each cell by the highest active channel, Montage shows
+// every channel in full, so objects which share a cell are never
+// hidden. Cells are drawn white where a channel is 1 and black where
+// it is 0, with intermediate values drawn in shades of gray.
+//
+// If labels is not nil, labels[i] is drawn above the tile of channel
+// i. Each cell is drawn as in Rasterize. Montage panics if the length
+// of state does not match shape, if labels is not nil and does not
+// have one label per channel, or if opts.CellSize is not positive.
+func Montage(state []float64, shape []int, labels []string,
+	opts Options) *image.RGBA {
+	channels, rows, cols := checkShape(state, shape)
+	if labels != nil && len(labels) != channels {
+		panic(fmt.Sprintf("render: expected %v labels but got %v", channels,
+			len(labels)))
+	}
+
+	// Render each channel as a single channel observation
+	tiles := make([]*image.RGBA, channels)
+	for ch := range tiles {
+		tiles[ch] = grayscale(state[ch*rows*cols:(ch+1)*rows*cols], rows,
+			cols, opts)
+	}
+	tileW, tileH := tiles[0].Bounds().Dx(), tiles[0].Bounds().Dy()
+
+	labelH := 0
+	if labels != nil {
+		_, h := TextSize("", 1)
+		labelH = h + montageGap
+	}
+
+	// Arrange the tiles in a grid which is as close to square as
+	// possible
+	gridCols := int(math.Ceil(math.Sqrt(float64(channels))))
+	gridRows := (channels + gridCols - 1) / gridCols
+	w := gridCols*(tileW+montageGap) + montageGap
+	h := gridRows*(tileH+labelH+montageGap) + montageGap
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	fill(img, img.Bounds(), color.RGBA{64, 64, 64, 255})
+	for ch, tile := range tiles {
+		x := montageGap + (ch%gridCols)*(tileW+montageGap)
+		y := montageGap + (ch/gridCols)*(tileH+labelH+montageGap)
+
+		if labels != nil {
+			DrawText(img, x, y, labels[ch], color.White, 1)
+			y += labelH
+		}
+		for row := 0; row < tileH; row++ {
+			copy(img.Pix[img.PixOffset(x, y+row):],
+				tile.Pix[tile.PixOffset(0, row):tile.PixOffset(tileW, row)])
+		}
+	}
+
+	return img
+}
+
+// grayscale rasterizes a single channel of rows × cols cells as in
+// Rasterize, drawing each cell in a shade of gray proportional to its
+// value, which is clamped to [0, 1]
+func grayscale(channel []float64, rows, cols int, opts Options) *image.RGBA {
+	if opts.CellSize <= 0 {
+		panic(fmt.Sprintf("render: cell size must be positive but got %v",
+			opts.CellSize))
+	}
+
+	line := 0
+	if opts.GridLines {
+		line = 1
+	}
+	stride := opts.CellSize + line
+	img := image.NewRGBA(image.Rect(0, 0, cols*stride-line,
+		rows*stride-line))
+
+	if opts.GridLines {
+		grid := color.RGBA{A: 255}
+		if opts.GridColor != nil {
+			grid = rgba(opts.GridColor)
+		}
+		fill(img, img.Bounds(), grid)
+	}
+
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			v := math.Max(0, math.Min(1, channel[r*cols+c]))
+			shade := uint8(math.Round(v * 255))
+
+			cell := image.Rect(c*stride, r*stride, c*stride+opts.CellSize,
+				r*stride+opts.CellSize)
+			fill(img, cell, color.RGBA{shade, shade, shade, 255})
+		}
+	}
+
+	return img
+}
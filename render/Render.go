@@ -0,0 +1,223 @@
+// Package render renders GoAtar state observations as images.
+//
+// Rendering is a pure function of a state observation and a palette,
+// so the same state is always rendered as the same image. This makes
+// rendered frames suitable for golden-image tests.
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand"
+)
+
+// Palette holds the colours used to render state observations. The
+// first colour is the background, and channel i is rendered with
+// colour i+1.
+type Palette []color.Color
+
+// DefaultPalette is the default Palette
+var DefaultPalette = Palette{
+	color.RGBA{3, 3, 3, 255},
+	color.RGBA{26, 71, 84, 255},
+	color.RGBA{93, 135, 55, 255},
+	color.RGBA{205, 126, 151, 255},
+	color.RGBA{199, 206, 243, 255},
+	color.RGBA{205, 229, 242, 255},
+	color.RGBA{205, 169, 230, 255},
+	color.RGBA{101, 132, 59, 255},
+	color.RGBA{32, 47, 73, 255},
+	color.RGBA{92, 109, 146, 255},
+	color.RGBA{132, 90, 108, 255},
+	color.RGBA{198, 185, 217, 255},
+}
+
+// Background returns the background colour
+func (p Palette) Background() color.Color {
+	return p.Channel(-1)
+}
+
+// Channel returns the colour of channel ch. If the palette does not
+// have enough colours, a colour is generated from the channel index,
+// so that the same channel always has the same colour.
+func (p Palette) Channel(ch int) color.Color {
+	if ch+1 < len(p) {
+		return p[ch+1]
+	}
+
+	rng := rand.New(rand.NewSource(int64(ch)))
+	return color.RGBA{
+		R: uint8(rng.Intn(256)),
+		G: uint8(rng.Intn(256)),
+		B: uint8(rng.Intn(256)),
+		A: 255,
+	}
+}
+
+// Options configures the rasterization of state observations
+type Options struct {
+	CellSize  int         // Width and height of each cell in pixels
+	GridLines bool        // Whether to draw lines between cells
+	GridColor color.Color // Colour of grid lines, black if nil
+
+	// ZOrder lists channel indices from bottom to top. When several
+	// channels are active at a cell, the cell is coloured by the
+	// topmost of them. Channels which are not listed are below all
+	// listed channels, in channel order. If nil, higher channels are
+	// drawn above lower channels.
+	ZOrder []int
+}
+
+// Frame renders a state observation of the given shape, in (channels,
+// rows, columns) order, as an image with one pixel per cell. Each cell
+// is coloured by the highest channel which is active at that cell, or
+// by the background colour if no channel is active. Frame panics if
+// the length of state does not match shape.
+func Frame(state []float64, shape []int, palette Palette) image.Image {
+	return Rasterize(state, shape, palette, Options{CellSize: 1})
+}
+
+// Rasterize renders a state observation like Frame, but draws each
+// cell as a square of opts.CellSize pixels, optionally separated by
+// one pixel wide grid lines. Pixels are written directly, so
+// Rasterize is fast enough to render every frame in real time.
+// Rasterize panics if the length of state does not match shape, if
+// opts.CellSize is not positive, or if opts.ZOrder contains an invalid
+// or repeated channel.
+func Rasterize(state []float64, shape []int, palette Palette,
+	opts Options) *image.RGBA {
+	channels, rows, cols := checkShape(state, shape)
+	if opts.CellSize <= 0 {
+		panic(fmt.Sprintf("render: cell size must be positive but got %v",
+			opts.CellSize))
+	}
+
+	// Convert the palette once rather than once per pixel
+	colours := make([]color.RGBA, channels+1)
+	colours[0] = rgba(palette.Background())
+	for ch := 0; ch < channels; ch++ {
+		colours[ch+1] = rgba(palette.Channel(ch))
+	}
+
+	line := 0
+	if opts.GridLines {
+		line = 1
+	}
+	stride := opts.CellSize + line
+	w := cols*stride - line
+	h := rows*stride - line
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	if opts.GridLines {
+		grid := color.RGBA{A: 255}
+		if opts.GridColor != nil {
+			grid = rgba(opts.GridColor)
+		}
+		fill(img, img.Bounds(), grid)
+	}
+
+	top := drawOrder(channels, opts.ZOrder)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			colour := colours[0]
+			for _, ch := range top {
+				if state[ch*rows*cols+r*cols+c] != 0 {
+					colour = colours[ch+1]
+					break
+				}
+			}
+
+			cell := image.Rect(c*stride, r*stride, c*stride+opts.CellSize,
+				r*stride+opts.CellSize)
+			fill(img, cell, colour)
+		}
+	}
+
+	return img
+}
+
+// drawOrder returns the channels from top to bottom given the z-order
+// zOrder, which lists channels from bottom to top. drawOrder panics if
+// zOrder contains an invalid or repeated channel.
+func drawOrder(channels int, zOrder []int) []int {
+	listed := make([]bool, channels)
+	for _, ch := range zOrder {
+		if ch < 0 || ch >= channels {
+			panic(fmt.Sprintf("render: z-order channel %v ∉ [0, %v)", ch,
+				channels))
+		}
+		if listed[ch] {
+			panic(fmt.Sprintf("render: z-order channel %v repeated", ch))
+		}
+		listed[ch] = true
+	}
+
+	top := make([]int, 0, channels)
+	for i := len(zOrder) - 1; i >= 0; i-- {
+		top = append(top, zOrder[i])
+	}
+	for ch := channels - 1; ch >= 0; ch-- {
+		if !listed[ch] {
+			top = append(top, ch)
+		}
+	}
+	return top
+}
+
+// fill fills rect of img with colour
+func fill(img *image.RGBA, rect image.Rectangle, colour color.RGBA) {
+	if rect.Empty() {
+		return
+	}
+
+	// Fill the first row, then copy it to the remaining rows
+	first := img.Pix[img.PixOffset(rect.Min.X, rect.Min.Y):img.PixOffset(
+		rect.Max.X, rect.Min.Y)]
+	for i := 0; i < len(first); i += 4 {
+		first[i] = colour.R
+		first[i+1] = colour.G
+		first[i+2] = colour.B
+		first[i+3] = colour.A
+	}
+	for y := rect.Min.Y + 1; y < rect.Max.Y; y++ {
+		copy(img.Pix[img.PixOffset(rect.Min.X, y):], first)
+	}
+}
+
+// rgba converts c to a color.RGBA
+func rgba(c color.Color) color.RGBA {
+	return color.RGBAModel.Convert(c).(color.RGBA)
+}
+
+// Resize resizes img to w × h pixels using nearest-neighbour sampling,
+// which keeps cells sharp
+func Resize(img image.Image, w, h int) image.Image {
+	bounds := img.Bounds()
+	resized := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		srcY := bounds.Min.Y + y*bounds.Dy()/h
+		for x := 0; x < w; x++ {
+			srcX := bounds.Min.X + x*bounds.Dx()/w
+			resized.Set(x, y, img.At(srcX, srcY))
+		}
+	}
+
+	return resized
+}
+
+// checkShape returns the number of channels, rows, and columns in
+// shape, and panics if the length of state does not match shape
+func checkShape(state []float64, shape []int) (channels, rows, cols int) {
+	if len(shape) != 3 {
+		panic(fmt.Sprintf("render: shape must have 3 dimensions but got %v",
+			len(shape)))
+	}
+
+	channels, rows, cols = shape[0], shape[1], shape[2]
+	if len(state) != channels*rows*cols {
+		panic(fmt.Sprintf("render: state of length %v does not match "+
+			"shape %v", len(state), shape))
+	}
+	return channels, rows, cols
+}
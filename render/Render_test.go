@@ -0,0 +1,272 @@
+package render_test
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/render"
+)
+
+var update = flag.Bool("update", false, "update golden images")
+
+// goldenSteps is the number of steps taken before rendering each game
+const goldenSteps = 25
+
+// goldenFrame returns the frame of game after taking goldenSteps
+// deterministic actions
+func goldenFrame(t *testing.T, game goatar.GameName) image.Image {
+	env, err := goatar.New(game, 0.0, true, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < goldenSteps; i++ {
+		_, done, err := env.Act(i * 5 % env.NumActions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done {
+			env.Reset()
+		}
+	}
+
+	state, err := env.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return render.Frame(state, env.StateShape(), render.DefaultPalette)
+}
+
+func TestFrameGolden(t *testing.T) {
+	for _, game := range goatar.Games() {
+		game := game
+		t.Run(game.String(), func(t *testing.T) {
+			got := goldenFrame(t, game)
+			name := strings.ReplaceAll(strings.ToLower(game.String()), " ",
+				"")
+			path := filepath.Join("testdata", name+".png")
+
+			if *update {
+				writePNG(t, path, got)
+				return
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create golden images)",
+					err)
+			}
+			defer f.Close()
+			want, err := png.Decode(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := compare(got, want); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestFrameDeterministic(t *testing.T) {
+	for _, game := range goatar.Games() {
+		if err := compare(goldenFrame(t, game),
+			goldenFrame(t, game)); err != nil {
+			t.Errorf("%v: %v", game, err)
+		}
+	}
+}
+
+func TestFrameOverlap(t *testing.T) {
+	// Two channels active at the same cell: the highest channel wins
+	state := []float64{
+		1, 0,
+		1, 1,
+	}
+	img := render.Frame(state, []int{2, 1, 2}, render.DefaultPalette)
+
+	if err := sameColour(img.At(0, 0), render.DefaultPalette.Channel(1)); err != nil {
+		t.Errorf("cell (0, 0): %v", err)
+	}
+	if err := sameColour(img.At(1, 0), render.DefaultPalette.Channel(1)); err != nil {
+		t.Errorf("cell (1, 0): %v", err)
+	}
+}
+
+func TestPaletteChannel(t *testing.T) {
+	p := render.Palette{render.DefaultPalette[0]}
+	if err := sameColour(p.Channel(20), p.Channel(20)); err != nil {
+		t.Errorf("generated colours differ: %v", err)
+	}
+}
+
+// compare returns an error if got and want are not identical
+func compare(got, want image.Image) error {
+	if got.Bounds() != want.Bounds() {
+		return fmt.Errorf("bounds %v, want %v", got.Bounds(), want.Bounds())
+	}
+
+	b := got.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if err := sameColour(got.At(x, y), want.At(x, y)); err != nil {
+				return fmt.Errorf("pixel (%v, %v): %v", x, y, err)
+			}
+		}
+	}
+	return nil
+}
+
+// sameColour returns an error if colours got and want differ
+func sameColour(got, want interface{ RGBA() (r, g, b, a uint32) }) error {
+	gr, gg, gb, ga := got.RGBA()
+	wr, wg, wb, wa := want.RGBA()
+	if gr != wr || gg != wg || gb != wb || ga != wa {
+		return fmt.Errorf("colour %v, want %v", got, want)
+	}
+	return nil
+}
+
+// writePNG writes img to path
+func writePNG(t *testing.T, path string, img image.Image) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRasterizeGridLines(t *testing.T) {
+	state := []float64{1, 0, 0, 0}
+	opts := render.Options{CellSize: 3, GridLines: true}
+	img := render.Rasterize(state, []int{1, 2, 2}, render.DefaultPalette,
+		opts)
+
+	if got, want := img.Bounds().Dx(), 2*3+1; got != want {
+		t.Fatalf("width %v, want %v", got, want)
+	}
+	if err := sameColour(img.At(3, 0), color.Black); err != nil {
+		t.Errorf("grid line: %v", err)
+	}
+	if err := sameColour(img.At(2, 2), render.DefaultPalette.Channel(0)); err != nil {
+		t.Errorf("cell (0, 0): %v", err)
+	}
+	if err := sameColour(img.At(4, 4), render.DefaultPalette.Background()); err != nil {
+		t.Errorf("cell (1, 1): %v", err)
+	}
+}
+
+func BenchmarkRasterize(b *testing.B) {
+	env, err := goatar.New(goatar.SeaQuest, 0.0, true, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	state, err := env.State()
+	if err != nil {
+		b.Fatal(err)
+	}
+	shape := env.StateShape()
+	opts := render.Options{CellSize: 32, GridLines: true}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		render.Rasterize(state, shape, render.DefaultPalette, opts)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	// Channel 0 moves from cell 0 to cell 1, channel 1 stays at cell 2,
+	// and at cell 3 channel 0 is replaced by channel 1
+	prev := []float64{
+		1, 0, 0, 1,
+		0, 0, 1, 0,
+	}
+	curr := []float64{
+		0, 1, 0, 0,
+		0, 0, 1, 1,
+	}
+	img := render.Diff(prev, curr, []int{2, 1, 4})
+
+	want := []color.Color{
+		render.DiffDisappeared,
+		render.DiffAppeared,
+		render.DiffUnchanged,
+		render.DiffChanged,
+	}
+	for x, w := range want {
+		if err := sameColour(img.At(x, 0), w); err != nil {
+			t.Errorf("cell %v: %v", x, err)
+		}
+	}
+}
+
+func TestMontage(t *testing.T) {
+	// Both channels are active at cell 0, which Frame would hide
+	state := []float64{
+		1, 0, 0, 0,
+		1, 0.5, 0, 0,
+	}
+	img := render.Montage(state, []int{2, 2, 2}, nil,
+		render.Options{CellSize: 1})
+
+	// Two 2 × 2 tiles side by side, separated and surrounded by gaps
+	if got, want := img.Bounds(), image.Rect(0, 0, 3*4+2*2, 2*4+2); got != want {
+		t.Fatalf("bounds %v, want %v", got, want)
+	}
+	for _, tc := range []struct {
+		x, y int
+		want color.Color
+	}{
+		{4, 4, color.White},
+		{5, 4, color.Black},
+		{10, 4, color.White},
+		{11, 4, color.RGBA{128, 128, 128, 255}},
+		{10, 5, color.Black},
+	} {
+		if err := sameColour(img.At(tc.x, tc.y), tc.want); err != nil {
+			t.Errorf("pixel (%v, %v): %v", tc.x, tc.y, err)
+		}
+	}
+
+	labelled := render.Montage(state, []int{2, 2, 2}, []string{"a", "b"},
+		render.Options{CellSize: 1})
+	if labelled.Bounds().Dy() <= img.Bounds().Dy() {
+		t.Errorf("labels not drawn above tiles")
+	}
+}
+
+func TestRasterizeZOrder(t *testing.T) {
+	// All three channels are active at the single cell
+	state := []float64{1, 1, 1}
+	shape := []int{3, 1, 1}
+
+	for _, tc := range []struct {
+		zOrder []int
+		want   int // Channel whose colour is drawn
+	}{
+		{nil, 2},
+		{[]int{2, 1, 0}, 0},
+		{[]int{1}, 1},
+		{[]int{0, 2}, 2},
+	} {
+		img := render.Rasterize(state, shape, render.DefaultPalette,
+			render.Options{CellSize: 1, ZOrder: tc.zOrder})
+		want := render.DefaultPalette.Channel(tc.want)
+		if err := sameColour(img.At(0, 0), want); err != nil {
+			t.Errorf("z-order %v: %v", tc.zOrder, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"strings"
+)
+
+// Dimensions of glyphs in the built-in bitmap font, in unscaled pixels
+const (
+	glyphWidth   = 3
+	glyphHeight  = 5
+	glyphAdvance = glyphWidth + 1
+)
+
+// glyphs is a tiny built-in bitmap font, so that text can be drawn
+// without loading font files. Each glyph is glyphHeight rows of
+// glyphWidth pixels, where '#' is drawn and '.' is not. Lower case
+// letters are drawn as upper case letters.
+var glyphs = map[rune][glyphHeight]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", ".##", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", ".#.", ".#."},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'A': {".#.", "#.#", "###", "#.#", "#.#"},
+	'B': {"##.", "#.#", "##.", "#.#", "##."},
+	'C': {".##", "#..", "#..", "#..", ".##"},
+	'D': {"##.", "#.#", "#.#", "#.#", "##."},
+	'E': {"###", "#..", "##.", "#..", "###"},
+	'F': {"###", "#..", "##.", "#..", "#.."},
+	'G': {".##", "#..", "#.#", "#.#", ".##"},
+	'H': {"#.#", "#.#", "###", "#.#", "#.#"},
+	'I': {"###", ".#.", ".#.", ".#.", "###"},
+	'J': {"..#", "..#", "..#", "#.#", ".#."},
+	'K': {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L': {"#..", "#..", "#..", "#..", "###"},
+	'M': {"#.#", "###", "###", "#.#", "#.#"},
+	'N': {"##.", "#.#", "#.#", "#.#", "#.#"},
+	'O': {".#.", "#.#", "#.#", "#.#", ".#."},
+	'P': {"##.", "#.#", "##.", "#..", "#.."},
+	'Q': {".#.", "#.#", "#.#", "##.", ".##"},
+	'R': {"##.", "#.#", "##.", "#.#", "#.#"},
+	'S': {".##", "#..", ".#.", "..#", "##."},
+	'T': {"###", ".#.", ".#.", ".#.", ".#."},
+	'U': {"#.#", "#.#", "#.#", "#.#", "###"},
+	'V': {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'W': {"#.#", "#.#", "###", "###", "#.#"},
+	'X': {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y': {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z': {"###", "..#", ".#.", "#..", "###"},
+	' ': {"...", "...", "...", "...", "..."},
+	'.': {"...", "...", "...", "...", ".#."},
+	',': {"...", "...", "...", ".#.", "#.."},
+	'-': {"...", "...", "###", "...", "..."},
+	'+': {"...", ".#.", "###", ".#.", "..."},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'/': {"..#", "..#", ".#.", "#..", "#.."},
+	'(': {".#.", "#..", "#..", "#..", ".#."},
+	')': {".#.", "..#", "..#", "..#", ".#."},
+	'=': {"...", "###", "...", "###", "..."},
+	'_': {"...", "...", "...", "...", "###"},
+	'?': {"###", "..#", ".#.", "...", ".#."},
+}
+
+// TextSize returns the width and height in pixels of text drawn with
+// DrawText at the given scale
+func TextSize(text string, scale int) (w, h int) {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0, glyphHeight * scale
+	}
+	return (n*glyphAdvance - 1) * scale, glyphHeight * scale
+}
+
+// DrawText draws text onto img with its top left corner at (x, y),
+// using a built-in bitmap font in which each pixel is drawn as a
+// scale × scale square. Characters missing from the font are drawn as
+// '?'.
+func DrawText(img *image.RGBA, x, y int, text string, colour color.Color,
+	scale int) {
+	c := rgba(colour)
+	for i, r := range []rune(strings.ToUpper(text)) {
+		glyph, ok := glyphs[r]
+		if !ok {
+			glyph = glyphs['?']
+		}
+
+		gx := x + i*glyphAdvance*scale
+		for row, line := range glyph {
+			for col, px := range line {
+				if px != '#' {
+					continue
+				}
+				rect := image.Rect(gx+col*scale, y+row*scale,
+					gx+(col+1)*scale, y+(row+1)*scale)
+				fill(img, rect.Intersect(img.Bounds()), c)
+			}
+		}
+	}
+}
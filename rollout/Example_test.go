@@ -0,0 +1,30 @@
+package rollout_test
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/policy"
+	"github.com/samuelfneumann/goatar/rollout"
+)
+
+func ExampleEvaluate() {
+	env, err := goatar.New(goatar.SpaceInvaders, 0.1, false, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	// Evaluate a uniform random policy over 5 episodes
+	random := policy.Random(env, policy.WithSeed(1))
+	result, err := rollout.Evaluate(env, random, 5, rollout.WithSeed(10))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(result.Returns)
+	fmt.Println(result.Lengths)
+	fmt.Printf("%.2f\n", result.MeanReturn)
+	// Output:
+	// [6 3 2 5 2]
+	// [115 87 17 66 17]
+	// 3.60
+}
@@ -0,0 +1,240 @@
+// Package rollout implements Monte-Carlo evaluation of policies in
+// GoAtar environments.
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// Policy selects an action given a state observation
+type Policy interface {
+	Act(state []float64) (int, error)
+}
+
+// PolicyFunc is an adapter which allows ordinary functions to be used
+// as a Policy
+type PolicyFunc func(state []float64) (int, error)
+
+// Act returns the action selected by the policy in state
+func (p PolicyFunc) Act(state []float64) (int, error) {
+	return p(state)
+}
+
+// Result is the result of evaluating a policy
+type Result struct {
+	Returns []float64 // Return of each episode
+	Lengths []int     // Number of steps in each episode
+	Seeds   []int64   // Seed used for each episode
+
+	MeanReturn   float64
+	StdDevReturn float64
+	MeanLength   float64
+}
+
+// config holds the configuration of an evaluation
+type config struct {
+	seed     int64
+	maxSteps int
+	workers  int
+	newEnv   func() (goatar.Env, error)
+	ctx      context.Context
+}
+
+// Option configures an evaluation
+type Option func(*config)
+
+// WithSeed sets the seed of the first episode of evaluation. Episode i
+// is seeded with seed+i. By default, the first episode uses seed 0.
+func WithSeed(seed int64) Option {
+	return func(c *config) {
+		c.seed = seed
+	}
+}
+
+// WithMaxSteps truncates each episode after n steps. By default,
+// episodes run until termination.
+func WithMaxSteps(n int) Option {
+	return func(c *config) {
+		c.maxSteps = n
+	}
+}
+
+// WithWorkers evaluates episodes in parallel using n goroutines. The
+// environment passed to Evaluate is used by the first worker and
+// newEnv is used to construct an environment for each other worker.
+// Environments returned by newEnv should be configured identically to
+// the environment passed to Evaluate. The policy must be safe for
+// concurrent use.
+func WithWorkers(n int, newEnv func() (goatar.Env, error)) Option {
+	return func(c *config) {
+		c.workers = n
+		c.newEnv = newEnv
+	}
+}
+
+// WithContext stops evaluation when ctx is done. Each worker checks ctx
+// before every step, and Evaluate waits for all workers to stop before
+// returning an error which wraps ctx.Err(). By default, evaluation
+// runs until every episode has finished.
+func WithContext(ctx context.Context) Option {
+	return func(c *config) {
+		c.ctx = ctx
+	}
+}
+
+// Evaluate evaluates policy on env for the given number of episodes.
+// Before each episode, the environment is seeded with a per-episode
+// seed and reset, so that results are reproducible regardless of the
+// number of workers used, provided the policy is deterministic.
+func Evaluate(env goatar.Env, policy Policy, episodes int,
+	opts ...Option) (Result, error) {
+	if episodes <= 0 {
+		return Result{}, fmt.Errorf("evaluate: episodes must be positive "+
+			"but got %v", episodes)
+	}
+
+	c := config{workers: 1, ctx: context.Background()}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.workers <= 0 {
+		return Result{}, fmt.Errorf("evaluate: workers must be positive "+
+			"but got %v", c.workers)
+	}
+	if c.workers > 1 && c.newEnv == nil {
+		return Result{}, fmt.Errorf("evaluate: cannot construct " +
+			"environments for workers")
+	}
+	if c.workers > episodes {
+		c.workers = episodes
+	}
+
+	// Construct one environment per worker
+	envs := []goatar.Env{env}
+	for i := 1; i < c.workers; i++ {
+		e, err := c.newEnv()
+		if err != nil {
+			return Result{}, fmt.Errorf("evaluate: %v", err)
+		}
+		envs = append(envs, e)
+	}
+
+	result := Result{
+		Returns: make([]float64, episodes),
+		Lengths: make([]int, episodes),
+		Seeds:   make([]int64, episodes),
+	}
+
+	jobs := make(chan int)
+	errs := make(chan error, c.workers)
+	var wg sync.WaitGroup
+	for _, e := range envs {
+		wg.Add(1)
+		go func(e goatar.Env) {
+			defer wg.Done()
+			for i := range jobs {
+				seed := c.seed + int64(i)
+				ret, length, err := episode(c.ctx, e, policy, seed,
+					c.maxSteps)
+				if err != nil {
+					errs <- fmt.Errorf("episode %v: %w", i, err)
+					return
+				}
+				result.Returns[i] = ret
+				result.Lengths[i] = length
+				result.Seeds[i] = seed
+			}
+		}(e)
+	}
+
+	// Distribute episodes to workers, stopping early on error
+	var err error
+	for i := 0; i < episodes && err == nil; i++ {
+		select {
+		case jobs <- i:
+		case err = <-errs:
+		case <-c.ctx.Done():
+			err = c.ctx.Err()
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err == nil && len(errs) > 0 {
+		err = <-errs
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("evaluate: %w", err)
+	}
+
+	result.MeanReturn, result.StdDevReturn = meanStdDev(result.Returns)
+	lengths := make([]float64, len(result.Lengths))
+	for i, l := range result.Lengths {
+		lengths[i] = float64(l)
+	}
+	result.MeanLength, _ = meanStdDev(lengths)
+
+	return result, nil
+}
+
+// episode runs a single episode of policy on env and returns the
+// episodic return and number of steps taken, stopping early if ctx is
+// done
+func episode(ctx context.Context, env goatar.Env, policy Policy,
+	seed int64, maxSteps int) (float64, int, error) {
+	env.Seed(seed)
+	env.Reset()
+
+	ret := 0.0
+	steps := 0
+	for done := false; !done && (maxSteps <= 0 || steps < maxSteps); steps++ {
+		if err := ctx.Err(); err != nil {
+			return ret, steps, err
+		}
+
+		state, err := env.State()
+		if err != nil {
+			return ret, steps, err
+		}
+
+		action, err := policy.Act(state)
+		if err != nil {
+			return ret, steps, err
+		}
+
+		var reward float64
+		reward, done, err = env.Act(action)
+		if err != nil {
+			return ret, steps, err
+		}
+		ret += reward
+	}
+
+	return ret, steps, nil
+}
+
+// meanStdDev returns the mean and sample standard deviation of x
+func meanStdDev(x []float64) (float64, float64) {
+	mean := 0.0
+	for _, v := range x {
+		mean += v
+	}
+	mean /= float64(len(x))
+
+	if len(x) < 2 {
+		return mean, 0
+	}
+
+	variance := 0.0
+	for _, v := range x {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(x) - 1)
+
+	return mean, math.Sqrt(variance)
+}
@@ -0,0 +1,80 @@
+package scenario
+
+import (
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/asterix"
+)
+
+// AsterixScenario builds an Asterix game in a hand-specified state. The
+// game starts with no enemies or gold, and with the player where it is
+// at the start of an episode.
+type AsterixScenario struct {
+	steps []func(a *asterix.Asterix) error
+}
+
+// Asterix returns a new builder of an Asterix game
+func Asterix() *AsterixScenario {
+	return &AsterixScenario{}
+}
+
+// Player moves the player to column x and row y
+func (s *AsterixScenario) Player(x, y int) *AsterixScenario {
+	return s.add(func(a *asterix.Asterix) error {
+		return a.PlacePlayer(x, y)
+	})
+}
+
+// Timers sets the number of steps until the player and entities next
+// move, and until the next entity spawns
+func (s *AsterixScenario) Timers(move, spawn int) *AsterixScenario {
+	return s.add(func(a *asterix.Asterix) error {
+		return a.SetTimers(move, spawn)
+	})
+}
+
+// Enemy places an enemy at column x and row y, moving left or right
+func (s *AsterixScenario) Enemy(x, y int, o goatar.Orientation) *AsterixScenario {
+	return s.add(func(a *asterix.Asterix) error {
+		return a.PlaceEnemy(x, y, o)
+	})
+}
+
+// Gold places gold at column x and row y, moving left or right
+func (s *AsterixScenario) Gold(x, y int, o goatar.Orientation) *AsterixScenario {
+	return s.add(func(a *asterix.Asterix) error {
+		return a.PlaceGold(x, y, o)
+	})
+}
+
+// Build returns a new Asterix environment in the scenario's state,
+// with the given seed and options
+func (s *AsterixScenario) Build(seed int64,
+	opts ...goatar.Option) (*goatar.Environment, error) {
+	return build(goatar.Asterix, seed, opts, s.arrange)
+}
+
+// Apply places the scenario's objects in the current state of env,
+// which must play Asterix. Unlike Build, the screen is not emptied
+// first, so that the state of an ongoing game can be edited.
+func (s *AsterixScenario) Apply(env *goatar.Environment) error {
+	return apply(env, goatar.Asterix, s.arrange)
+}
+
+// arrange takes the steps of the scenario in g
+func (s *AsterixScenario) arrange(g game.Game) error {
+	a := g.(*asterix.Asterix)
+	for _, step := range s.steps {
+		if err := step(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add adds a step to the scenario and returns the scenario
+func (s *AsterixScenario) add(
+	step func(a *asterix.Asterix) error) *AsterixScenario {
+	s.steps = append(s.steps, step)
+	return s
+}
@@ -0,0 +1,73 @@
+package scenario
+
+import (
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/breakout"
+)
+
+// BreakoutScenario builds a Breakout game in a hand-specified state.
+// The game starts with no bricks, and with the paddle and ball where
+// they are at the start of an episode.
+type BreakoutScenario struct {
+	steps []func(b *breakout.Breakout) error
+}
+
+// Breakout returns a new builder of a Breakout game
+func Breakout() *BreakoutScenario {
+	return &BreakoutScenario{}
+}
+
+// Paddle moves the paddle to column x
+func (s *BreakoutScenario) Paddle(x int) *BreakoutScenario {
+	return s.add(func(b *breakout.Breakout) error {
+		return b.PlacePaddle(x)
+	})
+}
+
+// Ball moves the ball to column x and row y, travelling diagonally in
+// the direction o
+func (s *BreakoutScenario) Ball(x, y int, o goatar.Orientation) *BreakoutScenario {
+	return s.add(func(b *breakout.Breakout) error {
+		return b.PlaceBall(x, y, o)
+	})
+}
+
+// Brick places a brick at column x and row y
+func (s *BreakoutScenario) Brick(x, y int) *BreakoutScenario {
+	return s.add(func(b *breakout.Breakout) error {
+		return b.PlaceBrick(x, y)
+	})
+}
+
+// Build returns a new Breakout environment in the scenario's state,
+// with the given seed and options
+func (s *BreakoutScenario) Build(seed int64,
+	opts ...goatar.Option) (*goatar.Environment, error) {
+	return build(goatar.Breakout, seed, opts, s.arrange)
+}
+
+// Apply places the scenario's objects in the current state of env,
+// which must play Breakout. Unlike Build, the screen is not emptied
+// first, so that the state of an ongoing game can be edited.
+func (s *BreakoutScenario) Apply(env *goatar.Environment) error {
+	return apply(env, goatar.Breakout, s.arrange)
+}
+
+// arrange takes the steps of the scenario in g
+func (s *BreakoutScenario) arrange(g game.Game) error {
+	b := g.(*breakout.Breakout)
+	for _, step := range s.steps {
+		if err := step(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add adds a step to the scenario and returns the scenario
+func (s *BreakoutScenario) add(
+	step func(b *breakout.Breakout) error) *BreakoutScenario {
+	s.steps = append(s.steps, step)
+	return s
+}
@@ -0,0 +1,76 @@
+package scenario
+
+import (
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/freeway"
+)
+
+// FreewayScenario builds a Freeway game in a hand-specified state. The
+// game starts as at the start of an episode, with the chicken at the
+// bottom of the screen and a car at the left edge of each lane. Lanes
+// are the rows 1 to 8 of the screen.
+type FreewayScenario struct {
+	steps []func(f *freeway.Freeway) error
+}
+
+// Freeway returns a new builder of a Freeway game
+func Freeway() *FreewayScenario {
+	return &FreewayScenario{}
+}
+
+// Chicken moves the chicken to row y
+func (s *FreewayScenario) Chicken(y int) *FreewayScenario {
+	return s.add(func(f *freeway.Freeway) error {
+		return f.PlaceChicken(y)
+	})
+}
+
+// MoveTimer sets the number of steps until the chicken can move
+func (s *FreewayScenario) MoveTimer(steps int) *FreewayScenario {
+	return s.add(func(f *freeway.Freeway) error {
+		return f.SetMoveTimer(steps)
+	})
+}
+
+// Car places the car of the lane in row y at column x, driving left or
+// right with a speed in [1, 4]. A car with speed s moves one cell every
+// s+1 steps, and first moves on step s+1.
+func (s *FreewayScenario) Car(x, y int, o goatar.Orientation,
+	speed int) *FreewayScenario {
+	return s.add(func(f *freeway.Freeway) error {
+		return f.PlaceCar(x, y, o, speed)
+	})
+}
+
+// Build returns a new Freeway environment in the scenario's state,
+// with the given seed and options
+func (s *FreewayScenario) Build(seed int64,
+	opts ...goatar.Option) (*goatar.Environment, error) {
+	return build(goatar.Freeway, seed, opts, s.arrange)
+}
+
+// Apply places the scenario's objects in the current state of env,
+// which must play Freeway. Unlike Build, the screen is not emptied
+// first, so that the state of an ongoing game can be edited.
+func (s *FreewayScenario) Apply(env *goatar.Environment) error {
+	return apply(env, goatar.Freeway, s.arrange)
+}
+
+// arrange takes the steps of the scenario in g
+func (s *FreewayScenario) arrange(g game.Game) error {
+	f := g.(*freeway.Freeway)
+	for _, step := range s.steps {
+		if err := step(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add adds a step to the scenario and returns the scenario
+func (s *FreewayScenario) add(
+	step func(f *freeway.Freeway) error) *FreewayScenario {
+	s.steps = append(s.steps, step)
+	return s
+}
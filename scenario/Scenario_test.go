@@ -0,0 +1,775 @@
+package scenario_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/scenario"
+)
+
+// step takes action a in env and returns the reward and whether the
+// episode ended
+func step(t *testing.T, env *goatar.Environment, a goatar.Action) (float64,
+	bool) {
+	t.Helper()
+	reward, done, err := env.Act(int(a))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reward, done
+}
+
+// find returns the objects of env with the given type
+func find(env *goatar.Environment, typ string) []goatar.Object {
+	var objects []goatar.Object
+	for _, o := range env.Objects() {
+		if o.Type == typ {
+			objects = append(objects, o)
+		}
+	}
+	return objects
+}
+
+// assertObjects fails the test if the objects of env with the given
+// type are not want
+func assertObjects(t *testing.T, env *goatar.Environment, typ string,
+	want ...goatar.Object) {
+	t.Helper()
+	got := find(env, typ)
+	if len(got) != len(want) {
+		t.Fatalf("got %v %v objects %v, want %v", len(got), typ, got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("got %v object %v, want %v", typ, got[i], want[i])
+		}
+	}
+}
+
+func TestBreakoutBrick(t *testing.T) {
+	env, err := scenario.Breakout().
+		Paddle(4).
+		Ball(3, 3, goatar.FacingUpRight).
+		Brick(4, 2).
+		Build(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reward, done := step(t, env, goatar.NoOp); reward != 1 || done {
+		t.Fatalf("got reward %v and done %v, want 1 and false", reward, done)
+	}
+	assertObjects(t, env, "brick")
+	assertObjects(t, env, "ball",
+		goatar.Object{Type: "ball", X: 4, Y: 3,
+			Orientation: goatar.FacingDownRight})
+}
+
+func TestBreakoutPaddle(t *testing.T) {
+	env, err := scenario.Breakout().
+		Paddle(5).
+		Ball(4, 8, goatar.FacingDownRight).
+		Build(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The ball is bounced back the way it came off the paddle's edge
+	if _, done := step(t, env, goatar.NoOp); done {
+		t.Fatal("episode ended when the ball hit the paddle")
+	}
+	assertObjects(t, env, "ball",
+		goatar.Object{Type: "ball", X: 5, Y: 8,
+			Orientation: goatar.FacingUpLeft})
+
+	env, err = scenario.Breakout().
+		Paddle(0).
+		Ball(4, 8, goatar.FacingDownRight).
+		Build(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, done := step(t, env, goatar.NoOp); !done {
+		t.Fatal("episode did not end when the ball missed the paddle")
+	}
+	if reason := env.TerminationReason(); reason != goatar.BallMissed {
+		t.Errorf("got termination reason %v, want %v", reason,
+			goatar.BallMissed)
+	}
+}
+
+func TestSpaceInvadersShootAlien(t *testing.T) {
+	env, err := scenario.SpaceInvaders().
+		Cannon(4).
+		Alien(4, 5).
+		Alien(7, 1).
+		Aliens(goatar.FacingLeft, 5, 5).
+		FriendlyBullet(4, 6).
+		Build(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reward, done := step(t, env, goatar.NoOp); reward != 1 || done {
+		t.Fatalf("got reward %v and done %v, want 1 and false", reward, done)
+	}
+	assertObjects(t, env, "friendly_bullet")
+	assertObjects(t, env, "alien",
+		goatar.Object{Type: "alien", X: 7, Y: 1,
+			Orientation: goatar.FacingLeft})
+}
+
+func TestSpaceInvadersAlienSpeed(t *testing.T) {
+	// Since version 3, the last alien moves on every step, while before
+	// version 3 aliens do not speed up as they are shot
+	for v, want := range map[int][]int{
+		1: {5, 4, 4, 4},
+		3: {5, 4, 3, 2},
+	} {
+		env, err := scenario.SpaceInvaders().
+			Cannon(0).
+			Alien(4, 1).
+			Alien(5, 1).
+			Aliens(goatar.FacingLeft, 1, 100).
+			FriendlyBullet(4, 2).
+			Build(0, goatar.WithVersion(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, x := range want {
+			step(t, env, goatar.NoOp)
+			assertObjects(t, env, "alien",
+				goatar.Object{Type: "alien", X: x, Y: 1,
+					Orientation: goatar.FacingLeft})
+			if t.Failed() {
+				t.Fatalf("version %v: alien in the wrong place after %v "+
+					"steps", v, i+1)
+			}
+		}
+	}
+}
+
+func TestSpaceInvadersWaveCleared(t *testing.T) {
+	// Since version 3, a cleared wave is replaced by a new wave
+	for v, replaced := range map[int]bool{1: false, 3: true} {
+		env, err := scenario.SpaceInvaders().
+			Cannon(4).
+			Alien(4, 5).
+			Aliens(goatar.FacingLeft, 5, 5).
+			FriendlyBullet(4, 6).
+			Build(0, goatar.WithVersion(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if reward, _ := step(t, env, goatar.NoOp); reward != 1 {
+			t.Fatalf("version %v: got reward %v, want 1", v, reward)
+		}
+		if got := len(find(env, "alien")) > 0; got != replaced {
+			t.Errorf("version %v: got wave replaced %v, want %v", v, got,
+				replaced)
+		}
+	}
+}
+
+func TestSpaceInvadersHitByBullet(t *testing.T) {
+	env, err := scenario.SpaceInvaders().
+		Cannon(4).
+		Alien(0, 0).
+		Aliens(goatar.FacingRight, 5, 5).
+		EnemyBullet(4, 8).
+		Build(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, done := step(t, env, goatar.NoOp); !done {
+		t.Fatal("episode did not end when the cannon was shot")
+	}
+	if reason := env.TerminationReason(); reason != goatar.HitByBullet {
+		t.Errorf("got termination reason %v, want %v", reason,
+			goatar.HitByBullet)
+	}
+}
+
+func TestSpaceInvadersTargeting(t *testing.T) {
+	nearest := goatar.WithSpaceInvadersConfig(goatar.SpaceInvadersConfig{
+		Targeting: goatar.NearestColumn,
+	})
+	leading := goatar.WithSpaceInvadersConfig(goatar.SpaceInvadersConfig{
+		Targeting: goatar.LeadingShot,
+	})
+	v1 := goatar.WithVersion(1)
+
+	tests := []struct {
+		name   string
+		opts   []goatar.Option
+		cannon int
+		action goatar.Action
+		aliens [][2]int
+
+		// Position of the alien which shoots, or nil if no alien shoots
+		want []int
+	}{
+		{"nearest", []goatar.Option{nearest}, 5, goatar.NoOp,
+			[][2]int{{2, 3}, {7, 2}}, []int{7, 2}},
+		{"nearest tie", []goatar.Option{nearest}, 5, goatar.NoOp,
+			[][2]int{{3, 1}, {7, 1}}, []int{3, 1}},
+		{"nearest lowest", []goatar.Option{nearest}, 6, goatar.NoOp,
+			[][2]int{{6, 1}, {6, 3}}, []int{6, 3}},
+		{"nearest leftmost column", []goatar.Option{nearest}, 0, goatar.NoOp,
+			[][2]int{{0, 2}}, []int{0, 2}},
+		{"nearest top row", []goatar.Option{nearest}, 4, goatar.NoOp,
+			[][2]int{{4, 0}}, []int{4, 0}},
+
+		// Version 1 searches leftwards from the cannon before searching
+		// rightwards, and aliens in the top row or leftmost column never
+		// shoot
+		{"v1 nearest", []goatar.Option{v1}, 5, goatar.NoOp,
+			[][2]int{{2, 3}, {7, 2}}, []int{2, 3}},
+		{"v1 leftmost column", []goatar.Option{v1}, 0, goatar.NoOp,
+			[][2]int{{0, 2}}, nil},
+		{"v1 top row", []goatar.Option{v1}, 4, goatar.NoOp,
+			[][2]int{{4, 0}}, nil},
+
+		// The cannon moves right to column 5, and a bullet fired from row
+		// 3 reaches the bottom row 6 steps later
+		{"leading", []goatar.Option{leading}, 4, goatar.Right,
+			[][2]int{{5, 3}, {9, 3}}, []int{9, 3}},
+		{"leading still", []goatar.Option{leading}, 5, goatar.NoOp,
+			[][2]int{{5, 3}, {9, 3}}, []int{5, 3}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := scenario.SpaceInvaders().
+				Cannon(test.cannon).
+				Aliens(goatar.FacingRight, 5, 0)
+			for _, a := range test.aliens {
+				s.Alien(a[0], a[1])
+			}
+			env, err := s.Build(0, test.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			step(t, env, test.action)
+			var want []goatar.Object
+			if test.want != nil {
+				want = append(want, goatar.Object{Type: "enemy_bullet",
+					X: test.want[0], Y: test.want[1],
+					Orientation: goatar.FacingDown})
+			}
+			assertObjects(t, env, "enemy_bullet", want...)
+		})
+	}
+}
+
+func TestSpaceInvadersRandomTargeting(t *testing.T) {
+	// Each column of aliens is chosen by some seed, and the lowest alien
+	// of the column always shoots
+	shooters := make(map[goatar.Object]bool)
+	for seed := int64(0); seed < 20; seed++ {
+		env, err := scenario.SpaceInvaders().
+			Cannon(5).
+			Aliens(goatar.FacingRight, 5, 0).
+			Alien(1, 1).
+			Alien(1, 2).
+			Alien(8, 4).
+			Build(seed, goatar.WithSpaceInvadersConfig(
+				goatar.SpaceInvadersConfig{Targeting: goatar.RandomColumn}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		step(t, env, goatar.NoOp)
+		bullets := find(env, "enemy_bullet")
+		if len(bullets) != 1 {
+			t.Fatalf("got enemy bullets %v, want 1", bullets)
+		}
+		shooters[bullets[0]] = true
+	}
+
+	want := []goatar.Object{
+		{Type: "enemy_bullet", X: 1, Y: 2, Orientation: goatar.FacingDown},
+		{Type: "enemy_bullet", X: 8, Y: 4, Orientation: goatar.FacingDown},
+	}
+	if len(shooters) != len(want) {
+		t.Errorf("got shooters %v, want %v", shooters, want)
+	}
+	for _, o := range want {
+		if !shooters[o] {
+			t.Errorf("alien at (%v, %v) never shot", o.X, o.Y)
+		}
+	}
+}
+
+func TestBulletTrails(t *testing.T) {
+	// trail returns the cells of the enemy bullet trail channel of env
+	// which are active
+	trail := func(env *goatar.Environment) [][2]int {
+		obs, err := env.Observation()
+		if err != nil {
+			t.Fatal(err)
+		}
+		channel, err := obs.Channel("enemy_bullet_trail")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cells [][2]int
+		for i, v := range channel {
+			if v != 0 {
+				cells = append(cells, [2]int{i % obs.Shape.Cols,
+					i / obs.Shape.Cols})
+			}
+		}
+		return cells
+	}
+
+	spaceInvaders, err := scenario.SpaceInvaders().
+		EnemyBullet(3, 4).
+		Build(0, goatar.WithBulletTrails())
+	if err != nil {
+		t.Fatal(err)
+	}
+	seaQuest, err := scenario.SeaQuest().
+		EnemyBullet(5, 3, goatar.FacingLeft).
+		EnemyBullet(2, 6, goatar.FacingRight).
+		Build(0, goatar.WithBulletTrails())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		env  *goatar.Environment
+		want [][2]int
+	}{
+		{spaceInvaders, [][2]int{{3, 3}}},
+		{seaQuest, [][2]int{{6, 3}, {1, 6}}},
+	} {
+		name := test.env.GameName()
+		got := trail(test.env)
+		if len(got) != len(test.want) {
+			t.Fatalf("%v: got trail %v, want %v", name, got, test.want)
+		}
+		for _, cell := range test.want {
+			found := false
+			for _, g := range got {
+				found = found || g == cell
+			}
+			if !found {
+				t.Errorf("%v: got trail %v, want %v", name, got, test.want)
+			}
+		}
+
+		last := test.env.Manifest().Channels[test.env.NChannels()-1]
+		if last.Name != "enemy_bullet_trail" {
+			t.Errorf("%v: got last channel %v, want enemy_bullet_trail",
+				name, last.Name)
+		}
+	}
+
+	if _, err := goatar.New(goatar.Breakout, 0, false, 0,
+		goatar.WithBulletTrails()); err == nil {
+		t.Error("created Breakout with bullet trails")
+	}
+}
+
+func TestSeaQuestSurface(t *testing.T) {
+	env, err := scenario.SeaQuest().
+		Sub(5, 1, goatar.FacingLeft).
+		Divers(6).
+		Oxygen(150).
+		Build(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Surfacing with a full load of divers gives one point per filled
+	// cell of the oxygen gauge
+	reward, done := step(t, env, goatar.Up)
+	if reward != 7 || done {
+		t.Fatalf("got reward %v and done %v, want 7 and false", reward, done)
+	}
+	if events := env.Info().RewardEvents; len(events) != 7 {
+		t.Errorf("got %v reward events, want 7", len(events))
+	}
+
+	env, err = scenario.SeaQuest().Sub(5, 1, goatar.FacingLeft).Build(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, done := step(t, env, goatar.Up); !done {
+		t.Fatal("episode did not end when surfacing without divers")
+	}
+	if reason := env.TerminationReason(); reason !=
+		goatar.SurfacedWithoutDivers {
+		t.Errorf("got termination reason %v, want %v", reason,
+			goatar.SurfacedWithoutDivers)
+	}
+}
+
+func TestSeaQuestShootFish(t *testing.T) {
+	env, err := scenario.SeaQuest().
+		Sub(0, 1, goatar.FacingRight).
+		FriendlyBullet(4, 4, goatar.FacingRight).
+		Fish(5, 4, goatar.FacingLeft).
+		Diver(7, 6, goatar.FacingLeft).
+		Build(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reward, done := step(t, env, goatar.NoOp); reward != 1 || done {
+		t.Fatalf("got reward %v and done %v, want 1 and false", reward, done)
+	}
+	assertObjects(t, env, "enemy_fish")
+
+	// Bullets are not stopped by the enemies they hit
+	assertObjects(t, env, "friendly_bullet",
+		goatar.Object{Type: "friendly_bullet", X: 5, Y: 4,
+			Orientation: goatar.FacingRight})
+	assertObjects(t, env, "diver",
+		goatar.Object{Type: "diver", X: 7, Y: 6,
+			Orientation: goatar.FacingLeft})
+}
+
+func TestSeaQuestShootDiver(t *testing.T) {
+	env, err := scenario.SeaQuest().
+		Sub(0, 1, goatar.FacingRight).
+		FriendlyBullet(4, 4, goatar.FacingRight).
+		Diver(5, 4, goatar.FacingLeft).
+		FriendlyBullet(2, 6, goatar.FacingRight).
+		Build(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without shootable divers, bullets pass over divers
+	if reward, _ := step(t, env, goatar.NoOp); reward != 0 {
+		t.Fatalf("got reward %v, want 0", reward)
+	}
+	assertObjects(t, env, "diver",
+		goatar.Object{Type: "diver", X: 5, Y: 4,
+			Orientation: goatar.FacingLeft})
+
+	env, err = scenario.SeaQuest().
+		Sub(0, 1, goatar.FacingRight).
+		FriendlyBullet(4, 4, goatar.FacingRight).
+		Diver(5, 4, goatar.FacingLeft).
+		FriendlyBullet(2, 6, goatar.FacingRight).
+		Build(0, goatar.WithShootableDivers())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The diver is removed along with the bullet which hit it
+	if reward, done := step(t, env, goatar.NoOp); reward != -1 || done {
+		t.Fatalf("got reward %v and done %v, want -1 and false", reward,
+			done)
+	}
+	assertObjects(t, env, "diver")
+	assertObjects(t, env, "friendly_bullet",
+		goatar.Object{Type: "friendly_bullet", X: 3, Y: 6,
+			Orientation: goatar.FacingRight})
+
+	events := env.Info().RewardEvents
+	want := goatar.RewardEvent{Type: "destroy", Amount: -1, X: 5, Y: 4,
+		Entity: "diver"}
+	if len(events) != 1 || events[0] != want {
+		t.Errorf("got reward events %v, want [%v]", events, want)
+	}
+	if min, _ := env.RewardRange(); min >= 0 {
+		t.Errorf("got minimum reward %v, want negative", min)
+	}
+}
+
+func TestSeaQuestLeaveScreen(t *testing.T) {
+	build := func(v int) *goatar.Environment {
+		env, err := scenario.SeaQuest().
+			SpawnTimers(100, 100).
+			FriendlyBullet(9, 4, goatar.FacingRight).
+			EnemyBullet(9, 6, goatar.FacingRight).
+			Build(0, goatar.WithVersion(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		step(t, env, goatar.NoOp)
+		return env
+	}
+	v1, v2 := build(1), build(2)
+
+	// In version 1, bullets which leave the right edge of the screen
+	// are kept, and are drawn into the first cell of the next row, as
+	// in the original port
+	assertObjects(t, v1, "friendly_bullet",
+		goatar.Object{Type: "friendly_bullet", X: 10, Y: 4,
+			Orientation: goatar.FacingRight})
+	assertObjects(t, v1, "enemy_bullet",
+		goatar.Object{Type: "enemy_bullet", X: 10, Y: 6,
+			Orientation: goatar.FacingRight})
+	assertObjects(t, v2, "friendly_bullet")
+	assertObjects(t, v2, "enemy_bullet")
+
+	channel := func(name string) int {
+		for i, c := range v1.Manifest().Channels {
+			if c.Name == name {
+				return i
+			}
+		}
+		t.Fatalf("no channel %q", name)
+		return -1
+	}
+	want := []int{
+		100*channel("friendly_bullet") + 5*10,
+		100*channel("enemy_bullet") + 7*10,
+	}
+
+	s1, err := v1.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := v2.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int
+	for i := range s1 {
+		if s1[i] != s2[i] {
+			got = append(got, i)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got version 1 observation differing in cells %v, want %v",
+			got, want)
+	}
+}
+
+func TestSeaQuestGauges(t *testing.T) {
+	env, err := scenario.SeaQuest().Oxygen(100).Divers(3).Build(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obs, err := env.Observation()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both gauges are drawn along the bottom row, the oxygen gauge
+	// from the left and the diver gauge leftwards from the second last
+	// column
+	for _, test := range []struct {
+		channel, want string
+	}{
+		{"oxygen_guage", "1111100000"},
+		{"diver_guage", "0000001110"},
+	} {
+		channel, err := obs.Channel(test.channel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got strings.Builder
+		for col := 0; col < obs.Shape.Cols; col++ {
+			v := channel[(obs.Shape.Rows-1)*obs.Shape.Cols+col]
+			fmt.Fprint(&got, v)
+		}
+		if got.String() != test.want {
+			t.Errorf("got bottom row %v of %v, want %v", got.String(),
+				test.channel, test.want)
+		}
+	}
+
+	if gauges := env.Manifest().Gauges; len(gauges) != 2 {
+		t.Errorf("got manifest gauges %v, want 2", gauges)
+	}
+}
+
+func TestAsterixGold(t *testing.T) {
+	env, err := scenario.Asterix().
+		Player(4, 4).
+		Timers(5, 5).
+		Gold(5, 4, goatar.FacingLeft).
+		Enemy(5, 6, goatar.FacingLeft).
+		Build(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reward, done := step(t, env, goatar.Right); reward != 1 || done {
+		t.Fatalf("got reward %v and done %v, want 1 and false", reward, done)
+	}
+	assertObjects(t, env, "gold")
+
+	step(t, env, goatar.Down)
+	if _, done := step(t, env, goatar.Down); !done {
+		t.Fatal("episode did not end when the player hit an enemy")
+	}
+	if reason := env.TerminationReason(); reason != goatar.Collision {
+		t.Errorf("got termination reason %v, want %v", reason,
+			goatar.Collision)
+	}
+}
+
+func TestFreewayCross(t *testing.T) {
+	env, err := scenario.Freeway().Chicken(1).MoveTimer(0).Build(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reward, done := step(t, env, goatar.Up); reward != 1 || done {
+		t.Fatalf("got reward %v and done %v, want 1 and false", reward, done)
+	}
+	assertObjects(t, env, "chicken", goatar.Object{Type: "chicken", X: 4,
+		Y: 9})
+}
+
+func TestFreewayHitByCar(t *testing.T) {
+	env, err := scenario.Freeway().
+		Chicken(3).
+		Car(3, 3, goatar.FacingRight, 1).
+		Build(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	step(t, env, goatar.NoOp)
+	assertObjects(t, env, "chicken", goatar.Object{Type: "chicken", X: 4,
+		Y: 3})
+
+	// The car moves onto the chicken, which is sent back to the bottom
+	step(t, env, goatar.NoOp)
+	assertObjects(t, env, "chicken", goatar.Object{Type: "chicken", X: 4,
+		Y: 9})
+}
+
+func TestInvalidScenario(t *testing.T) {
+	builds := map[string]func() (*goatar.Environment, error){
+		"ball": func() (*goatar.Environment, error) {
+			return scenario.Breakout().Ball(4, 4, goatar.FacingLeft).Build(0)
+		},
+		"alien": func() (*goatar.Environment, error) {
+			return scenario.SpaceInvaders().Alien(10, 0).Build(0)
+		},
+		"diver": func() (*goatar.Environment, error) {
+			return scenario.SeaQuest().Diver(4, 0, goatar.FacingLeft).Build(0)
+		},
+		"gold": func() (*goatar.Environment, error) {
+			return scenario.Asterix().Gold(4, 4, goatar.FacingUp).Build(0)
+		},
+		"car": func() (*goatar.Environment, error) {
+			return scenario.Freeway().Car(4, 9, goatar.FacingLeft, 1).Build(0)
+		},
+	}
+
+	for name, build := range builds {
+		if _, err := build(); err == nil {
+			t.Errorf("%v: expected an error", name)
+		}
+	}
+}
+
+func TestApplyRemoveClear(t *testing.T) {
+	env, err := goatar.New(goatar.Asterix, 0, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = scenario.Asterix().Enemy(3, 5, goatar.FacingRight).
+		Gold(6, 2, goatar.FacingLeft).Apply(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enemies := find(env, "enemy"); len(enemies) != 1 {
+		t.Fatalf("got enemies %v after applying, want 1", enemies)
+	}
+
+	if n, err := scenario.Remove(env, 3, 5); err != nil || n != 1 {
+		t.Fatalf("got %v objects removed and error %v, want 1 and nil", n,
+			err)
+	}
+	assertObjects(t, env, "enemy")
+	if gold := find(env, "gold"); len(gold) != 1 {
+		t.Fatalf("got gold %v after removing the enemy, want 1", gold)
+	}
+
+	if err := scenario.Clear(env); err != nil {
+		t.Fatal(err)
+	}
+	assertObjects(t, env, "gold")
+
+	err = scenario.Breakout().Brick(0, 0).Apply(env)
+	if err == nil {
+		t.Error("applied a Breakout scenario to an Asterix environment")
+	}
+}
+
+// TestSoftResetSpaceInvaders checks that clearing a wave of aliens
+// ramps the difficulty of Space Invaders, and that SoftReset keeps the
+// ramp while spawning a new wave
+func TestSoftResetSpaceInvaders(t *testing.T) {
+	env, err := goatar.New(goatar.SpaceInvaders, 0, true, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := scenario.Clear(env); err != nil {
+		t.Fatal(err)
+	}
+	step(t, env, goatar.NoOp)
+	if env.DifficultyRamp() != 1 {
+		t.Fatalf("got difficulty ramp %v after clearing a wave, want 1",
+			env.DifficultyRamp())
+	}
+
+	env.SoftReset()
+	if env.DifficultyRamp() != 1 {
+		t.Fatalf("got difficulty ramp %v after soft reset, want 1",
+			env.DifficultyRamp())
+	}
+	aliens := 0
+	for _, o := range env.Objects() {
+		if o.Type == "alien" {
+			aliens++
+		}
+	}
+	if aliens == 0 {
+		t.Fatal("soft reset did not spawn a wave of aliens")
+	}
+
+	env.Reset()
+	if env.DifficultyRamp() != 0 {
+		t.Fatalf("got difficulty ramp %v after reset, want 0",
+			env.DifficultyRamp())
+	}
+}
+
+func TestSpaceInvadersMinMoveInterval(t *testing.T) {
+	env, err := goatar.New(goatar.SpaceInvaders, 0, true, 0,
+		goatar.WithSpaceInvadersConfig(goatar.SpaceInvadersConfig{
+			MinMoveInterval: 10,
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if err := scenario.Clear(env); err != nil {
+			t.Fatal(err)
+		}
+		step(t, env, goatar.NoOp)
+	}
+	if env.DifficultyRamp() != 2 {
+		t.Errorf("got difficulty ramp %v after clearing 4 waves, want 2",
+			env.DifficultyRamp())
+	}
+	interval, err := env.Param(goatar.MoveIntervalParam)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if interval != 10 {
+		t.Errorf("got move interval %v, want 10", interval)
+	}
+}
@@ -0,0 +1,127 @@
+package scenario
+
+import (
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/seaquest"
+)
+
+// SeaQuestScenario builds a SeaQuest game in a hand-specified state.
+// The game starts with no bullets, enemies, or divers, and with the
+// player's submarine at the surface with full oxygen and no divers, as
+// at the start of an episode. Enemies, bullets, and divers are placed
+// below the surface.
+type SeaQuestScenario struct {
+	steps []func(s *seaquest.SeaQuest) error
+}
+
+// SeaQuest returns a new builder of a SeaQuest game
+func SeaQuest() *SeaQuestScenario {
+	return &SeaQuestScenario{}
+}
+
+// Sub moves the front of the player's submarine to column x and row y,
+// facing left or right. A submarine placed at the surface has already
+// surfaced, while a submarine placed below the surface surfaces when
+// it next reaches the surface.
+func (s *SeaQuestScenario) Sub(x, y int, o goatar.Orientation) *SeaQuestScenario {
+	return s.add(func(sq *seaquest.SeaQuest) error {
+		return sq.PlaceSub(x, y, o)
+	})
+}
+
+// Oxygen sets the oxygen remaining in the player's submarine
+func (s *SeaQuestScenario) Oxygen(oxygen int) *SeaQuestScenario {
+	return s.add(func(sq *seaquest.SeaQuest) error {
+		return sq.SetOxygen(oxygen)
+	})
+}
+
+// Divers sets the number of divers in the player's submarine
+func (s *SeaQuestScenario) Divers(divers int) *SeaQuestScenario {
+	return s.add(func(sq *seaquest.SeaQuest) error {
+		return sq.SetDivers(divers)
+	})
+}
+
+// SpawnTimers sets the number of steps until the next enemy and diver
+// spawn
+func (s *SeaQuestScenario) SpawnTimers(enemy, diver int) *SeaQuestScenario {
+	return s.add(func(sq *seaquest.SeaQuest) error {
+		return sq.SetSpawnTimers(enemy, diver)
+	})
+}
+
+// FriendlyBullet places a bullet fired by the player at column x and
+// row y, travelling left or right
+func (s *SeaQuestScenario) FriendlyBullet(x, y int,
+	o goatar.Orientation) *SeaQuestScenario {
+	return s.add(func(sq *seaquest.SeaQuest) error {
+		return sq.PlaceFriendlyBullet(x, y, o)
+	})
+}
+
+// EnemyBullet places a bullet fired by an enemy submarine at column x
+// and row y, travelling left or right
+func (s *SeaQuestScenario) EnemyBullet(x, y int,
+	o goatar.Orientation) *SeaQuestScenario {
+	return s.add(func(sq *seaquest.SeaQuest) error {
+		return sq.PlaceEnemyBullet(x, y, o)
+	})
+}
+
+// Fish places an enemy fish at column x and row y, swimming left or
+// right
+func (s *SeaQuestScenario) Fish(x, y int, o goatar.Orientation) *SeaQuestScenario {
+	return s.add(func(sq *seaquest.SeaQuest) error {
+		return sq.PlaceFish(x, y, o)
+	})
+}
+
+// EnemySub places an enemy submarine at column x and row y, swimming
+// left or right
+func (s *SeaQuestScenario) EnemySub(x, y int,
+	o goatar.Orientation) *SeaQuestScenario {
+	return s.add(func(sq *seaquest.SeaQuest) error {
+		return sq.PlaceEnemySub(x, y, o)
+	})
+}
+
+// Diver places a diver at column x and row y, swimming left or right
+func (s *SeaQuestScenario) Diver(x, y int, o goatar.Orientation) *SeaQuestScenario {
+	return s.add(func(sq *seaquest.SeaQuest) error {
+		return sq.PlaceDiver(x, y, o)
+	})
+}
+
+// Build returns a new SeaQuest environment in the scenario's state,
+// with the given seed and options
+func (s *SeaQuestScenario) Build(seed int64,
+	opts ...goatar.Option) (*goatar.Environment, error) {
+	return build(goatar.SeaQuest, seed, opts, s.arrange)
+}
+
+// Apply places the scenario's objects in the current state of env,
+// which must play SeaQuest. Unlike Build, the screen is not emptied
+// first, so that the state of an ongoing game can be edited.
+func (s *SeaQuestScenario) Apply(env *goatar.Environment) error {
+	return apply(env, goatar.SeaQuest, s.arrange)
+}
+
+// arrange takes the steps of the scenario in g
+func (s *SeaQuestScenario) arrange(g game.Game) error {
+	sq := g.(*seaquest.SeaQuest)
+	for _, step := range s.steps {
+		if err := step(sq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add adds a step to the scenario and returns the scenario
+func (s *SeaQuestScenario) add(
+	step func(sq *seaquest.SeaQuest) error) *SeaQuestScenario {
+	s.steps = append(s.steps, step)
+	return s
+}
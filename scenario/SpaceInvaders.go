@@ -0,0 +1,98 @@
+package scenario
+
+import (
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/internal/game"
+	"github.com/samuelfneumann/goatar/internal/game/spaceinvaders"
+)
+
+// SpaceInvadersScenario builds a Space Invaders game in a
+// hand-specified state. The game starts with no aliens or bullets, and
+// with the cannon where it is at the start of an episode. If no aliens
+// are placed, a new wave spawns on the first step.
+type SpaceInvadersScenario struct {
+	steps []func(s *spaceinvaders.SpaceInvaders) error
+}
+
+// SpaceInvaders returns a new builder of a Space Invaders game
+func SpaceInvaders() *SpaceInvadersScenario {
+	return &SpaceInvadersScenario{}
+}
+
+// Cannon moves the cannon to column x
+func (s *SpaceInvadersScenario) Cannon(x int) *SpaceInvadersScenario {
+	return s.add(func(si *spaceinvaders.SpaceInvaders) error {
+		return si.PlaceCannon(x)
+	})
+}
+
+// ShotTimer sets the number of steps until the cannon can fire
+func (s *SpaceInvadersScenario) ShotTimer(steps int) *SpaceInvadersScenario {
+	return s.add(func(si *spaceinvaders.SpaceInvaders) error {
+		return si.SetShotTimer(steps)
+	})
+}
+
+// Alien places an alien at column x and row y
+func (s *SpaceInvadersScenario) Alien(x, y int) *SpaceInvadersScenario {
+	return s.add(func(si *spaceinvaders.SpaceInvaders) error {
+		return si.PlaceAlien(x, y)
+	})
+}
+
+// Aliens sets the direction in which the aliens move, which is either
+// left or right, and the number of steps until the aliens next move
+// and shoot
+func (s *SpaceInvadersScenario) Aliens(o goatar.Orientation, moveTimer,
+	shotTimer int) *SpaceInvadersScenario {
+	return s.add(func(si *spaceinvaders.SpaceInvaders) error {
+		return si.SetAliens(o, moveTimer, shotTimer)
+	})
+}
+
+// FriendlyBullet places a bullet fired by the cannon at column x and
+// row y
+func (s *SpaceInvadersScenario) FriendlyBullet(x, y int) *SpaceInvadersScenario {
+	return s.add(func(si *spaceinvaders.SpaceInvaders) error {
+		return si.PlaceFriendlyBullet(x, y)
+	})
+}
+
+// EnemyBullet places a bullet fired by an alien at column x and row y
+func (s *SpaceInvadersScenario) EnemyBullet(x, y int) *SpaceInvadersScenario {
+	return s.add(func(si *spaceinvaders.SpaceInvaders) error {
+		return si.PlaceEnemyBullet(x, y)
+	})
+}
+
+// Build returns a new Space Invaders environment in the scenario's
+// state, with the given seed and options
+func (s *SpaceInvadersScenario) Build(seed int64,
+	opts ...goatar.Option) (*goatar.Environment, error) {
+	return build(goatar.SpaceInvaders, seed, opts, s.arrange)
+}
+
+// Apply places the scenario's objects in the current state of env,
+// which must play Space Invaders. Unlike Build, the screen is not
+// emptied first, so that the state of an ongoing game can be edited.
+func (s *SpaceInvadersScenario) Apply(env *goatar.Environment) error {
+	return apply(env, goatar.SpaceInvaders, s.arrange)
+}
+
+// arrange takes the steps of the scenario in g
+func (s *SpaceInvadersScenario) arrange(g game.Game) error {
+	si := g.(*spaceinvaders.SpaceInvaders)
+	for _, step := range s.steps {
+		if err := step(si); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add adds a step to the scenario and returns the scenario
+func (s *SpaceInvadersScenario) add(
+	step func(si *spaceinvaders.SpaceInvaders) error) *SpaceInvadersScenario {
+	s.steps = append(s.steps, step)
+	return s
+}
@@ -0,0 +1,136 @@
+// Package score implements normalized scoring of returns in GoAtar
+// games, so that the performance of agents can be compared and
+// aggregated across the games in the suite.
+//
+// A raw return r in some game is normalized as
+//
+//	(r - random) / (reference - random)
+//
+// where random is the mean return of a uniform random policy in the
+// game and reference is the mean return of some reference agent, which
+// is supplied by the caller with Table.SetReference. A
+// normalized score of 0 therefore indicates random performance, and a
+// normalized score of 1 indicates performance equal to the reference
+// agent.
+package score
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// Scores holds the baseline scores of a single game
+type Scores struct {
+	Version   int     // Version of the game the scores were measured on
+	Random    float64 // Mean return of a uniform random policy
+	Reference float64 // Mean return of a reference agent, 0 if unset
+}
+
+// Table is a table of baseline scores for each game
+type Table map[goatar.GameName]Scores
+
+// RandomBaselines returns a new table holding the mean return of a
+// uniform random policy over all 6 actions in the latest version of
+// each game, measured over 1000 episodes of at most 10000 steps with
+// sticky action probability 0.1 and difficulty ramping enabled, as
+// reported by:
+//
+//	goatar-baseline --episodes 1000 --constant=false
+//
+// The table holds no reference scores, since no published reference
+// scores have been checked in for GoAtar. Reference scores must be set
+// with SetReference before normalizing; until then, normalized scores
+// are NaN. Scores measured on other versions of a game should not be
+// compared with these baselines.
+func RandomBaselines() Table {
+	return Table{
+		goatar.Asterix:       {Version: 2, Random: 0.505},
+		goatar.Breakout:      {Version: 2, Random: 0.391},
+		goatar.Freeway:       {Version: 2, Random: 0.263},
+		goatar.SeaQuest:      {Version: 2, Random: 0.112},
+		goatar.SpaceInvaders: {Version: 3, Random: 4.040},
+	}
+}
+
+// SetReference sets the reference score of game in the table
+func (t Table) SetReference(game goatar.GameName, reference float64) {
+	scores := t[game]
+	scores.Reference = reference
+	t[game] = scores
+}
+
+// Normalize returns the normalized score of the raw return in game.
+// If the table contains no scores for game, the reference score for
+// game is unset, or it is equal to the random score, then Normalize
+// returns NaN.
+func (t Table) Normalize(game goatar.GameName, raw float64) float64 {
+	scores, ok := t[game]
+	if !ok || scores.Reference == 0 || scores.Reference == scores.Random {
+		return math.NaN()
+	}
+	return (raw - scores.Random) / (scores.Reference - scores.Random)
+}
+
+// Mean returns the mean of the normalized scores
+func Mean(scores []float64) (float64, error) {
+	if len(scores) == 0 {
+		return 0, fmt.Errorf("mean: no scores")
+	}
+
+	total := 0.0
+	for _, s := range scores {
+		total += s
+	}
+	return total / float64(len(scores)), nil
+}
+
+// Median returns the median of the normalized scores
+func Median(scores []float64) (float64, error) {
+	if len(scores) == 0 {
+		return 0, fmt.Errorf("median: no scores")
+	}
+
+	sorted := sortedCopy(scores)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2], nil
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2, nil
+}
+
+// IQM returns the interquartile mean of the normalized scores, which
+// is the mean of the middle 50% of scores. When the number of scores
+// is not divisible by 4, the scores at the boundaries of the middle
+// 50% are weighted fractionally.
+func IQM(scores []float64) (float64, error) {
+	if len(scores) == 0 {
+		return 0, fmt.Errorf("iqm: no scores")
+	}
+
+	sorted := sortedCopy(scores)
+	n := float64(len(sorted))
+	lower, upper := n/4, 3*n/4
+
+	// Weight each score by the fraction of it which lies in the middle
+	// 50% of scores
+	total := 0.0
+	for i, s := range sorted {
+		start, end := float64(i), float64(i+1)
+		weight := math.Min(end, upper) - math.Max(start, lower)
+		if weight > 0 {
+			total += weight * s
+		}
+	}
+	return total / (upper - lower), nil
+}
+
+// sortedCopy returns a sorted copy of scores
+func sortedCopy(scores []float64) []float64 {
+	sorted := make([]float64, len(scores))
+	copy(sorted, scores)
+	sort.Float64s(sorted)
+	return sorted
+}
@@ -0,0 +1,58 @@
+package score
+
+import (
+	"math"
+	"testing"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// TestNormalize checks that the random and reference scores of each
+// game normalize to 0 and 1, and that games without reference scores
+// normalize to NaN
+func TestNormalize(t *testing.T) {
+	table := RandomBaselines()
+	for _, g := range goatar.Games() {
+		if got := table.Normalize(g, table[g].Random); !math.IsNaN(got) {
+			t.Errorf("%v: got normalized score %v without a reference "+
+				"score, want NaN", g, got)
+		}
+
+		table.SetReference(g, 10*table[g].Random+1)
+		if got := table.Normalize(g, table[g].Random); got != 0 {
+			t.Errorf("%v: got normalized random score %v, want 0", g, got)
+		}
+		if got := table.Normalize(g, table[g].Reference); got != 1 {
+			t.Errorf("%v: got normalized reference score %v, want 1", g,
+				got)
+		}
+	}
+
+	if got := (Table{}).Normalize(goatar.Breakout, 1); !math.IsNaN(got) {
+		t.Errorf("got normalized score %v for a missing game, want NaN", got)
+	}
+}
+
+// TestRandomBaselines checks that the random baselines were measured on
+// the latest version of each game, so that they are re-measured when a
+// game's behaviour changes
+func TestRandomBaselines(t *testing.T) {
+	table := RandomBaselines()
+	for _, g := range goatar.Games() {
+		scores, ok := table[g]
+		if !ok {
+			t.Errorf("%v: no random baseline", g)
+			continue
+		}
+		if want := goatar.LatestVersion(g); scores.Version != want {
+			t.Errorf("%v: random baseline measured on version %v, want "+
+				"version %v", g, scores.Version, want)
+		}
+	}
+
+	// Each call returns a new table
+	RandomBaselines().SetReference(goatar.Breakout, 1)
+	if got := RandomBaselines()[goatar.Breakout].Reference; got != 0 {
+		t.Errorf("got reference score %v in a new table, want 0", got)
+	}
+}
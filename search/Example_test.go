@@ -0,0 +1,43 @@
+package search_test
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/search"
+)
+
+func ExampleMCTS_Search() {
+	env, err := goatar.New(goatar.Breakout, 0, false, 1)
+	if err != nil {
+		panic(err)
+	}
+
+	// A shallow search is enough to keep the ball in play for 500 steps
+	// and clear the bricks several times, since a missed ball ends the
+	// episode within a few steps. A random policy scores less than one
+	// point per episode.
+	m, err := search.New(env, search.WithIterations(50),
+		search.WithRolloutDepth(10), search.WithSeed(1))
+	if err != nil {
+		panic(err)
+	}
+
+	ret := 0.0
+	done := false
+	for step := 0; step < 500 && !done; step++ {
+		result, err := m.Search()
+		if err != nil {
+			panic(err)
+		}
+		var reward float64
+		reward, done, err = env.Act(result.Action)
+		if err != nil {
+			panic(err)
+		}
+		ret += reward
+	}
+	fmt.Println(ret, done)
+	// Output:
+	// 49 false
+}
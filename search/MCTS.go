@@ -0,0 +1,403 @@
+// Package search implements Monte-Carlo tree search (MCTS) for GoAtar
+// environments.
+//
+// The search uses the environment itself as a simulator: each node of
+// the search tree holds a goatar.Snapshot of the state reached by the
+// actions leading to it, and the environment is restored to a node's
+// snapshot whenever the search continues from that node. Since
+// snapshots include the state of the environment's random number
+// generators, the search plans against the exact transitions which the
+// environment would produce, including sticky actions.
+//
+// Leaves are evaluated by following a rollout policy for a fixed number
+// of steps and, optionally, bootstrapping from a value function:
+//
+//	m, err := search.New(env, search.WithIterations(100),
+//		search.WithRolloutDepth(20))
+//	...
+//	for !done {
+//		result, err := m.Search()
+//		...
+//		_, done, err = env.Act(result.Action)
+//	}
+//
+// Search leaves the environment in the state it was called in, but the
+// steps it simulates are taken with Act, so step callbacks, events,
+// and monitors of the environment observe them. Environments which are
+// searched should not use these features, or a separate environment
+// should be searched and kept in sync with Restore.
+package search
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/policy"
+	"github.com/samuelfneumann/goatar/rollout"
+)
+
+// ValueFunc estimates the discounted return which follows a state
+type ValueFunc func(state []float64) (float64, error)
+
+// config holds the configuration of an MCTS
+type config struct {
+	iterations   int
+	maxDepth     int
+	rolloutDepth int
+	exploration  float64
+	discount     float64
+	seed         int64
+	fullActions  bool
+	rollout      rollout.Policy
+	value        ValueFunc
+}
+
+// Option configures an MCTS
+type Option func(*config)
+
+// WithIterations runs n iterations of selection, expansion, evaluation,
+// and backup on each search. By default, 100 iterations are run.
+func WithIterations(n int) Option {
+	return func(c *config) {
+		c.iterations = n
+	}
+}
+
+// WithMaxDepth limits the depth of the search tree to n actions below
+// the root. By default, the depth of the tree is not limited.
+func WithMaxDepth(n int) Option {
+	return func(c *config) {
+		c.maxDepth = n
+	}
+}
+
+// WithRolloutDepth evaluates leaves by following the rollout policy for
+// at most n steps. A depth of 0 evaluates leaves with the value
+// function alone. By default, rollouts take 20 steps.
+func WithRolloutDepth(n int) Option {
+	return func(c *config) {
+		c.rolloutDepth = n
+	}
+}
+
+// WithExploration sets the exploration constant of the UCT rule used to
+// select actions in the tree. By default, the constant is √2.
+func WithExploration(c float64) Option {
+	return func(conf *config) {
+		conf.exploration = c
+	}
+}
+
+// WithDiscount sets the discount used to compute returns. By default,
+// returns are undiscounted.
+func WithDiscount(gamma float64) Option {
+	return func(c *config) {
+		c.discount = gamma
+	}
+}
+
+// WithSeed seeds the random number generator used to break ties and to
+// choose which actions to expand, as well as the default rollout
+// policy. By default, seed 0 is used.
+func WithSeed(seed int64) Option {
+	return func(c *config) {
+		c.seed = seed
+	}
+}
+
+// WithFullActionSet searches over the full action set of the
+// environment. By default, only the minimal action set is searched.
+func WithFullActionSet() Option {
+	return func(c *config) {
+		c.fullActions = true
+	}
+}
+
+// WithRolloutPolicy evaluates leaves by following p. By default, the
+// rollout policy selects actions uniformly at random from the searched
+// action set.
+func WithRolloutPolicy(p rollout.Policy) Option {
+	return func(c *config) {
+		c.rollout = p
+	}
+}
+
+// WithValueFunc bootstraps the return of rollouts which do not end in a
+// terminal state from the value given by v of their last state. By
+// default, the return after a rollout is taken to be 0.
+func WithValueFunc(v ValueFunc) Option {
+	return func(c *config) {
+		c.value = v
+	}
+}
+
+// MCTS is a Monte-Carlo tree search over the actions of an environment,
+// which selects actions in the tree with the UCT rule. A new tree is
+// built on each call to Search.
+type MCTS struct {
+	env     *goatar.Environment
+	c       config
+	rng     *rand.Rand
+	actions []int // Searched actions of the environment
+}
+
+// Result is the result of a search
+type Result struct {
+	// Action is the action of the environment to take, which is the
+	// most visited action at the root of the tree
+	Action int
+
+	Actions []int     // Searched actions of the environment
+	Visits  []int     // Number of visits to each searched action
+	Values  []float64 // Estimated return of each searched action
+}
+
+// node is a node of the search tree, which is the state reached by
+// taking the actions on the path from the root
+type node struct {
+	snapshot *goatar.Snapshot
+	reward   float64 // Reward received on entering the node
+	terminal bool
+
+	visits int
+	total  float64 // Sum of the returns which followed the node
+
+	// children holds the child reached by each searched action, or nil
+	// if the action has not been expanded
+	children []*node
+	expanded int // Number of non-nil children
+}
+
+// value returns the estimated return of taking the action leading to n
+func (n *node) value(discount float64) float64 {
+	if n.visits == 0 {
+		return n.reward
+	}
+	return n.reward + discount*n.total/float64(n.visits)
+}
+
+// New returns a new MCTS which searches env
+func New(env *goatar.Environment, opts ...Option) (*MCTS, error) {
+	c := config{
+		iterations:   100,
+		rolloutDepth: 20,
+		exploration:  math.Sqrt2,
+		discount:     1,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if c.iterations <= 0 {
+		return nil, fmt.Errorf("new: iterations must be positive but "+
+			"got %v", c.iterations)
+	}
+	if c.maxDepth < 0 {
+		return nil, fmt.Errorf("new: maximum depth must be non-negative "+
+			"but got %v", c.maxDepth)
+	}
+	if c.rolloutDepth < 0 {
+		return nil, fmt.Errorf("new: rollout depth must be non-negative "+
+			"but got %v", c.rolloutDepth)
+	}
+	if c.discount < 0 || c.discount > 1 {
+		return nil, fmt.Errorf("new: discount %v ∉ [0, 1]", c.discount)
+	}
+
+	actions := env.MinimalActionSet()
+	policyOpts := []policy.Option{policy.WithSeed(c.seed)}
+	if c.fullActions {
+		actions = env.FullActionSet()
+		policyOpts = append(policyOpts, policy.WithFullActionSet())
+	}
+	if c.rollout == nil {
+		c.rollout = policy.Random(env, policyOpts...)
+	}
+
+	return &MCTS{
+		env:     env,
+		c:       c,
+		rng:     rand.New(rand.NewSource(c.seed)),
+		actions: actions,
+	}, nil
+}
+
+// Search builds a search tree rooted at the current state of the
+// environment and returns the action to take. The environment is
+// restored to its current state before Search returns.
+func (m *MCTS) Search() (Result, error) {
+	root := &node{
+		snapshot: m.env.Snapshot(),
+		children: make([]*node, len(m.actions)),
+	}
+	defer m.env.Restore(root.snapshot)
+
+	for i := 0; i < m.c.iterations; i++ {
+		if err := m.iterate(root); err != nil {
+			return Result{}, fmt.Errorf("search: %v", err)
+		}
+	}
+
+	r := Result{
+		Action:  -1,
+		Actions: m.actions,
+		Visits:  make([]int, len(m.actions)),
+		Values:  make([]float64, len(m.actions)),
+	}
+	best := -1
+	for i, child := range root.children {
+		if child == nil {
+			continue
+		}
+		r.Visits[i] = child.visits
+		r.Values[i] = child.value(m.c.discount)
+
+		if best < 0 || r.Visits[i] > r.Visits[best] ||
+			(r.Visits[i] == r.Visits[best] && r.Values[i] > r.Values[best]) {
+			best = i
+		}
+	}
+	r.Action = m.actions[best]
+	return r, nil
+}
+
+// iterate runs a single iteration of the search from root
+func (m *MCTS) iterate(root *node) error {
+	path := []*node{root}
+	n := root
+
+	// Select children with UCT until reaching a node which is not fully
+	// expanded, then expand one of its untried actions
+	for !n.terminal && (m.c.maxDepth == 0 || len(path) <= m.c.maxDepth) {
+		if n.expanded < len(n.children) {
+			child, err := m.expand(n)
+			if err != nil {
+				return err
+			}
+			n = child
+			path = append(path, n)
+			break
+		}
+		n = m.selectChild(n)
+		path = append(path, n)
+	}
+
+	ret := 0.0
+	if !n.terminal {
+		if err := m.env.Restore(n.snapshot); err != nil {
+			return err
+		}
+		var err error
+		if ret, err = m.evaluate(); err != nil {
+			return err
+		}
+	}
+
+	// Back up the return to the root
+	for i := len(path) - 1; i >= 0; i-- {
+		path[i].visits++
+		path[i].total += ret
+		ret = path[i].reward + m.c.discount*ret
+	}
+	return nil
+}
+
+// expand adds a child to n for an untried action, chosen uniformly at
+// random, and returns it
+func (m *MCTS) expand(n *node) (*node, error) {
+	k := m.rng.Intn(len(n.children) - n.expanded)
+	i := 0
+	for ; ; i++ {
+		if n.children[i] == nil {
+			if k == 0 {
+				break
+			}
+			k--
+		}
+	}
+
+	if err := m.env.Restore(n.snapshot); err != nil {
+		return nil, err
+	}
+	reward, done, err := m.env.Act(m.actions[i])
+	if err != nil {
+		return nil, err
+	}
+
+	child := &node{
+		snapshot: m.env.Snapshot(),
+		reward:   reward,
+		terminal: done,
+		children: make([]*node, len(m.actions)),
+	}
+	n.children[i] = child
+	n.expanded++
+	return child, nil
+}
+
+// selectChild returns the child of a fully expanded node n which
+// maximizes the UCT rule, breaking ties uniformly at random
+func (m *MCTS) selectChild(n *node) *node {
+	logN := math.Log(float64(n.visits))
+	var best *node
+	bestScore := math.Inf(-1)
+	ties := 0
+	for _, child := range n.children {
+		score := child.value(m.c.discount) +
+			m.c.exploration*math.Sqrt(logN/float64(child.visits))
+
+		switch {
+		case score > bestScore:
+			best, bestScore, ties = child, score, 1
+		case score == bestScore:
+			ties++
+			if m.rng.Intn(ties) == 0 {
+				best = child
+			}
+		}
+	}
+	return best
+}
+
+// evaluate follows the rollout policy from the current state of the
+// environment and returns the discounted return, bootstrapped from the
+// value function if the rollout does not terminate
+func (m *MCTS) evaluate() (float64, error) {
+	ret := 0.0
+	weight := 1.0
+	for step := 0; step < m.c.rolloutDepth; step++ {
+		state, err := m.env.State()
+		if err != nil {
+			return 0, err
+		}
+		a, err := m.c.rollout.Act(state)
+		if err != nil {
+			return 0, err
+		}
+
+		reward, done, err := m.env.Act(a)
+		if err != nil {
+			return 0, err
+		}
+		ret += weight * reward
+		weight *= m.c.discount
+		if done {
+			return ret, nil
+		}
+	}
+
+	if m.c.value == nil {
+		return ret, nil
+	}
+	state, err := m.env.State()
+	if err != nil {
+		return 0, err
+	}
+	v, err := m.c.value(state)
+	if err != nil {
+		return 0, err
+	}
+	return ret + weight*v, nil
+}
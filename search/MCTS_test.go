@@ -0,0 +1,187 @@
+package search_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/search"
+)
+
+// newEnv returns a Breakout environment with sticky actions, so that
+// searches which disturb the random number generators are detected
+func newEnv(t *testing.T) *goatar.Environment {
+	env, err := goatar.New(goatar.Breakout, 0.1, false, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env
+}
+
+// trajectory returns the states and rewards of n steps of env under a
+// fixed sequence of actions
+func trajectory(t *testing.T, env *goatar.Environment, n int) ([][]float64,
+	[]float64) {
+	var states [][]float64
+	var rewards []float64
+	for i := 0; i < n; i++ {
+		r, done, err := env.Act((i*5 + i/3) % env.NumActions())
+		if err != nil {
+			t.Fatal(err)
+		}
+		state, err := env.State()
+		if err != nil {
+			t.Fatal(err)
+		}
+		states = append(states, state)
+		rewards = append(rewards, r)
+		if done {
+			env.Reset()
+		}
+	}
+	return states, rewards
+}
+
+// TestSearch checks that the visits of the root's children sum to the
+// number of iterations, that the most visited action is returned, that
+// searches with the same seed are identical, and that the environment
+// is left unchanged by a search
+func TestSearch(t *testing.T) {
+	const iterations = 40
+	for _, opts := range [][]search.Option{
+		nil,
+		{search.WithMaxDepth(1)},
+		{search.WithMaxDepth(3), search.WithFullActionSet()},
+		{search.WithDiscount(0.9), search.WithExploration(0.5)},
+	} {
+		opts = append(opts, search.WithIterations(iterations),
+			search.WithRolloutDepth(5), search.WithSeed(7))
+
+		env, twin := newEnv(t), newEnv(t)
+		trajectory(t, env, 10)
+		trajectory(t, twin, 10)
+
+		state, err := env.State()
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash, rngHash := env.StateHash(), env.RNGHash()
+
+		m, err := search.New(env, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := m.Search()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		visits := 0
+		chosen := got.Visits[indexOf(got.Actions, got.Action)]
+		for i, v := range got.Visits {
+			visits += v
+			if v > chosen {
+				t.Errorf("action %v was visited %v times, more than the "+
+					"%v visits of the chosen action %v", got.Actions[i], v,
+					chosen, got.Action)
+			}
+		}
+		if visits != iterations {
+			t.Errorf("got %v visits at the root, want %v", visits,
+				iterations)
+		}
+
+		// The same search of the restored state gives the same result
+		m, err = search.New(env, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := m.Search()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(again, got) {
+			t.Errorf("got result %+v, want %+v from identical search",
+				again, got)
+		}
+
+		// The environment is restored, including its random number
+		// generators
+		after, err := env.State()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(after, state) || env.StateHash() != hash ||
+			env.RNGHash() != rngHash {
+			t.Error("environment changed by search")
+		}
+		gotStates, gotRewards := trajectory(t, env, 100)
+		wantStates, wantRewards := trajectory(t, twin, 100)
+		if !reflect.DeepEqual(gotStates, wantStates) ||
+			!reflect.DeepEqual(gotRewards, wantRewards) {
+			t.Error("trajectory after search differs from trajectory " +
+				"without search")
+		}
+	}
+}
+
+// indexOf returns the index of v in s, or -1 if s does not contain v
+func indexOf(s []int, v int) int {
+	for i := range s {
+		if s[i] == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// TestSearchValueFunc checks that leaves are evaluated by the value
+// function alone with a rollout depth of 0
+func TestSearchValueFunc(t *testing.T) {
+	env, err := goatar.New(goatar.Freeway, 0, false, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	m, err := search.New(env, search.WithIterations(20),
+		search.WithRolloutDepth(0), search.WithSeed(1),
+		search.WithValueFunc(func([]float64) (float64, error) {
+			calls++
+			return 1, nil
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := m.Search()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 20 {
+		t.Errorf("got %v calls of the value function, want 20", calls)
+	}
+
+	// No rewards are reachable within a few steps of the start, so each
+	// action is worth the value of the leaves below it
+	for i, v := range r.Values {
+		if v != 1 {
+			t.Errorf("action %v: got value %v, want 1", r.Actions[i], v)
+		}
+	}
+}
+
+// TestNewErrors checks that invalid options are rejected
+func TestNewErrors(t *testing.T) {
+	env := newEnv(t)
+	for _, opt := range []search.Option{
+		search.WithIterations(0),
+		search.WithMaxDepth(-1),
+		search.WithRolloutDepth(-1),
+		search.WithDiscount(-0.1),
+		search.WithDiscount(1.1),
+	} {
+		if _, err := search.New(env, opt); err == nil {
+			t.Error("expected error")
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package sweep
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/score"
+)
+
+// RunResult is the result of evaluating a policy for a number of
+// episodes with a single game, seed, sticky actions probability, and
+// difficulty ramping setting
+type RunResult struct {
+	Game              string
+	Seed              int64
+	StickyActionsProb float64
+	DifficultyRamping bool
+	Episodes          int
+
+	MeanReturn   float64
+	StdDevReturn float64 // Sample standard deviation of the returns
+	MeanLength   float64
+
+	// NormalizedScore is the normalized score of MeanReturn, see the
+	// score package. It is NaN if the game has no reference score.
+	NormalizedScore float64
+
+	game goatar.GameName
+}
+
+// Summary aggregates the runs of a single game, sticky actions
+// probability, and difficulty ramping setting over all seeds
+type Summary struct {
+	Game              string
+	StickyActionsProb float64
+	DifficultyRamping bool
+	Runs              int
+
+	MeanReturn   float64 // Mean of the mean returns of the runs
+	StdDevReturn float64 // Sample standard deviation over runs
+
+	// NormalizedScore is the normalized score of MeanReturn, see the
+	// score package. It is NaN if the game has no reference score.
+	NormalizedScore float64
+}
+
+// Report is the report of a sweep. Runs and Summaries are tidy, with
+// one row per run and one row per setting respectively, in the order
+// given by the sweep's configuration.
+type Report struct {
+	Runs      []RunResult
+	Summaries []Summary
+}
+
+// newReport returns the report of the given runs
+func newReport(runs []RunResult, scores score.Table) *Report {
+	type setting struct {
+		game    goatar.GameName
+		sticky  float64
+		ramping bool
+	}
+
+	var order []setting
+	returns := make(map[setting][]float64)
+	for _, r := range runs {
+		s := setting{r.game, r.StickyActionsProb, r.DifficultyRamping}
+		if _, ok := returns[s]; !ok {
+			order = append(order, s)
+		}
+		returns[s] = append(returns[s], r.MeanReturn)
+	}
+
+	summaries := make([]Summary, len(order))
+	for i, s := range order {
+		mean, std := meanStdDev(returns[s])
+		summaries[i] = Summary{
+			Game:              s.game.String(),
+			StickyActionsProb: s.sticky,
+			DifficultyRamping: s.ramping,
+			Runs:              len(returns[s]),
+			MeanReturn:        mean,
+			StdDevReturn:      std,
+			NormalizedScore:   scores.Normalize(s.game, mean),
+		}
+	}
+
+	return &Report{Runs: runs, Summaries: summaries}
+}
+
+// WriteCSV writes the runs of the report to w as CSV, with a header
+// row. Normalized scores which are NaN are written as empty fields.
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"game", "seed", "sticky_actions_prob",
+		"difficulty_ramping", "episodes", "mean_return", "std_return",
+		"mean_length", "normalized_score"})
+	for _, run := range r.Runs {
+		cw.Write([]string{
+			run.Game,
+			strconv.FormatInt(run.Seed, 10),
+			formatFloat(run.StickyActionsProb),
+			strconv.FormatBool(run.DifficultyRamping),
+			strconv.Itoa(run.Episodes),
+			formatFloat(run.MeanReturn),
+			formatFloat(run.StdDevReturn),
+			formatFloat(run.MeanLength),
+			formatFloat(run.NormalizedScore),
+		})
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("writeCSV: %v", err)
+	}
+	return nil
+}
+
+// WriteSummaryCSV writes the summaries of the report to w as CSV, with
+// a header row. Normalized scores which are NaN are written as empty
+// fields.
+func (r *Report) WriteSummaryCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"game", "sticky_actions_prob", "difficulty_ramping",
+		"runs", "mean_return", "std_return", "normalized_score"})
+	for _, s := range r.Summaries {
+		cw.Write([]string{
+			s.Game,
+			formatFloat(s.StickyActionsProb),
+			strconv.FormatBool(s.DifficultyRamping),
+			strconv.Itoa(s.Runs),
+			formatFloat(s.MeanReturn),
+			formatFloat(s.StdDevReturn),
+			formatFloat(s.NormalizedScore),
+		})
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("writeSummaryCSV: %v", err)
+	}
+	return nil
+}
+
+// jsonRun is the JSON encoding of a RunResult
+type jsonRun struct {
+	Game              string   `json:"game"`
+	Seed              int64    `json:"seed"`
+	StickyActionsProb float64  `json:"sticky_actions_prob"`
+	DifficultyRamping bool     `json:"difficulty_ramping"`
+	Episodes          int      `json:"episodes"`
+	MeanReturn        float64  `json:"mean_return"`
+	StdDevReturn      float64  `json:"std_return"`
+	MeanLength        float64  `json:"mean_length"`
+	NormalizedScore   *float64 `json:"normalized_score"`
+}
+
+// jsonSummary is the JSON encoding of a Summary
+type jsonSummary struct {
+	Game              string   `json:"game"`
+	StickyActionsProb float64  `json:"sticky_actions_prob"`
+	DifficultyRamping bool     `json:"difficulty_ramping"`
+	Runs              int      `json:"runs"`
+	MeanReturn        float64  `json:"mean_return"`
+	StdDevReturn      float64  `json:"std_return"`
+	NormalizedScore   *float64 `json:"normalized_score"`
+}
+
+// WriteJSON writes the report to w as a JSON object with the fields
+// "runs" and "summaries". Normalized scores which are NaN are written
+// as null.
+func (r *Report) WriteJSON(w io.Writer) error {
+	report := struct {
+		Runs      []jsonRun     `json:"runs"`
+		Summaries []jsonSummary `json:"summaries"`
+	}{
+		Runs:      make([]jsonRun, len(r.Runs)),
+		Summaries: make([]jsonSummary, len(r.Summaries)),
+	}
+	for i, run := range r.Runs {
+		report.Runs[i] = jsonRun{
+			Game:              run.Game,
+			Seed:              run.Seed,
+			StickyActionsProb: run.StickyActionsProb,
+			DifficultyRamping: run.DifficultyRamping,
+			Episodes:          run.Episodes,
+			MeanReturn:        run.MeanReturn,
+			StdDevReturn:      run.StdDevReturn,
+			MeanLength:        run.MeanLength,
+			NormalizedScore:   optional(run.NormalizedScore),
+		}
+	}
+	for i, s := range r.Summaries {
+		report.Summaries[i] = jsonSummary{
+			Game:              s.Game,
+			StickyActionsProb: s.StickyActionsProb,
+			DifficultyRamping: s.DifficultyRamping,
+			Runs:              s.Runs,
+			MeanReturn:        s.MeanReturn,
+			StdDevReturn:      s.StdDevReturn,
+			NormalizedScore:   optional(s.NormalizedScore),
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("writeJSON: %v", err)
+	}
+	return nil
+}
+
+// optional returns nil if x is NaN and a pointer to x otherwise
+func optional(x float64) *float64 {
+	if math.IsNaN(x) {
+		return nil
+	}
+	return &x
+}
+
+// formatFloat formats x for CSV, with NaN formatted as an empty field
+func formatFloat(x float64) string {
+	if math.IsNaN(x) {
+		return ""
+	}
+	return strconv.FormatFloat(x, 'g', -1, 64)
+}
+
+// meanStdDev returns the mean and sample standard deviation of x
+func meanStdDev(x []float64) (float64, float64) {
+	mean := 0.0
+	for _, v := range x {
+		mean += v
+	}
+	mean /= float64(len(x))
+
+	if len(x) < 2 {
+		return mean, 0
+	}
+
+	variance := 0.0
+	for _, v := range x {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(x) - 1)
+
+	return mean, math.Sqrt(variance)
+}
@@ -0,0 +1,222 @@
+// Package sweep implements the standard evaluation loop of GoAtar
+// experiments: a policy is evaluated on a grid of games, seeds, sticky
+// actions probabilities, and difficulty ramping settings, and the
+// results are collected into a tidy report which can be written as CSV
+// or JSON.
+//
+// For example, to evaluate a uniform random policy on every game over
+// 5 seeds, with and without difficulty ramping:
+//
+//	report, err := sweep.Run(sweep.SweepConfig{
+//		Seeds:             []int64{0, 1, 2, 3, 4},
+//		DifficultyRamping: []bool{false, true},
+//		Episodes:          100,
+//		Workers:           8,
+//	})
+//	...
+//	err = report.WriteCSV(os.Stdout)
+package sweep
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/policy"
+	"github.com/samuelfneumann/goatar/rollout"
+	"github.com/samuelfneumann/goatar/score"
+)
+
+// PolicyFactory returns the policy evaluated in env on the run with
+// the given seed. A new policy is constructed for each run, so
+// policies need not be safe for concurrent use.
+type PolicyFactory func(env goatar.Env, seed int64) (rollout.Policy, error)
+
+// SweepConfig describes a sweep. Each combination of game, seed,
+// sticky actions probability, and difficulty ramping setting is a
+// single run, which evaluates the policy for a number of episodes.
+type SweepConfig struct {
+	// Games are the games to evaluate. If empty, all games are used.
+	Games []goatar.GameName
+
+	// Seeds are the seeds of each run. Episode i of the run with seed
+	// s is seeded with s*Episodes + i, so that runs with different
+	// seeds never share episodes. If empty, a single run with seed 0
+	// is used.
+	Seeds []int64
+
+	// StickyActionsProbs are the sticky actions probabilities to
+	// evaluate. If empty, only probability 0.1 is used.
+	StickyActionsProbs []float64
+
+	// DifficultyRamping are the difficulty ramping settings to
+	// evaluate. If empty, only difficulty ramping enabled is used.
+	DifficultyRamping []bool
+
+	// Options are applied to every environment of the sweep
+	Options []goatar.Option
+
+	Episodes int // Number of episodes in each run
+	MaxSteps int // Maximum steps per episode, or 0 for no limit
+
+	// Workers is the number of runs evaluated in parallel. If 0, runs
+	// are evaluated sequentially.
+	Workers int
+
+	// Policy constructs the policy evaluated in each run. If nil, a
+	// uniform random policy over the minimal action set is used.
+	Policy PolicyFactory
+
+	// Scores are used to compute normalized scores, see
+	// score.RandomBaselines. If nil, normalized scores are NaN.
+	Scores score.Table
+}
+
+// withDefaults returns the configuration with default values filled in
+func (c SweepConfig) withDefaults() SweepConfig {
+	if len(c.Games) == 0 {
+		c.Games = goatar.Games()
+	}
+	if len(c.Seeds) == 0 {
+		c.Seeds = []int64{0}
+	}
+	if len(c.StickyActionsProbs) == 0 {
+		c.StickyActionsProbs = []float64{0.1}
+	}
+	if len(c.DifficultyRamping) == 0 {
+		c.DifficultyRamping = []bool{true}
+	}
+	if c.Workers == 0 {
+		c.Workers = 1
+	}
+	if c.Policy == nil {
+		c.Policy = func(env goatar.Env, seed int64) (rollout.Policy, error) {
+			return policy.Random(env, policy.WithSeed(seed)), nil
+		}
+	}
+	return c
+}
+
+// validate returns an error if the configuration is invalid
+func (c SweepConfig) validate() error {
+	if c.Episodes <= 0 {
+		return fmt.Errorf("episodes must be positive but got %v", c.Episodes)
+	}
+	if c.MaxSteps < 0 {
+		return fmt.Errorf("maximum steps must be non-negative but got %v",
+			c.MaxSteps)
+	}
+	if c.Workers < 0 {
+		return fmt.Errorf("workers must be non-negative but got %v",
+			c.Workers)
+	}
+	for _, p := range c.StickyActionsProbs {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("sticky actions probability %v ∉ [0, 1]", p)
+		}
+	}
+	return nil
+}
+
+// runs returns the runs of the sweep in order, without results
+func (c SweepConfig) runs() []RunResult {
+	var runs []RunResult
+	for _, g := range c.Games {
+		for _, ramping := range c.DifficultyRamping {
+			for _, sticky := range c.StickyActionsProbs {
+				for _, seed := range c.Seeds {
+					runs = append(runs, RunResult{
+						Game:              g.String(),
+						Seed:              seed,
+						StickyActionsProb: sticky,
+						DifficultyRamping: ramping,
+						Episodes:          c.Episodes,
+						game:              g,
+					})
+				}
+			}
+		}
+	}
+	return runs
+}
+
+// Run evaluates the sweep described by cfg. Runs are evaluated in
+// parallel by cfg.Workers goroutines, and the results are the same
+// regardless of the number of workers, provided the policy is
+// deterministic given its seed.
+func Run(cfg SweepConfig) (*Report, error) {
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("run: %v", err)
+	}
+
+	runs := cfg.runs()
+	workers := cfg.Workers
+	if workers > len(runs) {
+		workers = len(runs)
+	}
+
+	jobs := make(chan int)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := cfg.evaluate(&runs[i]); err != nil {
+					errs <- fmt.Errorf("%v seed %v: %v", runs[i].Game,
+						runs[i].Seed, err)
+					return
+				}
+			}
+		}()
+	}
+
+	// Distribute runs to workers, stopping early on error
+	var err error
+	for i := 0; i < len(runs) && err == nil; i++ {
+		select {
+		case jobs <- i:
+		case err = <-errs:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err == nil && len(errs) > 0 {
+		err = <-errs
+	}
+	if err != nil {
+		return nil, fmt.Errorf("run: %v", err)
+	}
+
+	return newReport(runs, cfg.Scores), nil
+}
+
+// evaluate evaluates a single run and stores its results in run
+func (c SweepConfig) evaluate(run *RunResult) error {
+	env, err := goatar.New(run.game, run.StickyActionsProb,
+		run.DifficultyRamping, run.Seed, c.Options...)
+	if err != nil {
+		return err
+	}
+
+	p, err := c.Policy(env, run.Seed)
+	if err != nil {
+		return err
+	}
+
+	result, err := rollout.Evaluate(env, p, c.Episodes,
+		rollout.WithSeed(run.Seed*int64(c.Episodes)),
+		rollout.WithMaxSteps(c.MaxSteps))
+	if err != nil {
+		return err
+	}
+
+	run.MeanReturn = result.MeanReturn
+	run.StdDevReturn = result.StdDevReturn
+	run.MeanLength = result.MeanLength
+	run.NormalizedScore = c.Scores.Normalize(run.game, result.MeanReturn)
+	return nil
+}
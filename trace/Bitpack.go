@@ -0,0 +1,79 @@
+package trace
+
+import "fmt"
+
+// Encoding is the encoding of the state observations of a trace
+type Encoding string
+
+const (
+	// Float stores each element of a state observation as a number,
+	// which is float64 in JSON traces and float32 in binary traces
+	Float Encoding = ""
+
+	// Bitpack stores state observations packed one bit per element,
+	// which shrinks JSON traces by over an order of magnitude. Only
+	// binary state observations, with elements of 0 or 1, can be
+	// bit-packed, so that e.g. the ramp channel cannot be stored.
+	Bitpack Encoding = "bitpack"
+)
+
+// validate returns an error if the encoding cannot be used in a trace
+// of the given header
+func (e Encoding) validate(h Header) error {
+	switch e {
+	case Float:
+		return nil
+	case Bitpack:
+		if len(h.Shape) == 0 {
+			return fmt.Errorf("bit-packed traces must have a state shape")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown encoding %q", e)
+	}
+}
+
+// stateSize returns the number of elements in each state observation
+// of the trace, as given by its shape
+func (h Header) stateSize() int {
+	size := 1
+	for _, dim := range h.Shape {
+		size *= dim
+	}
+	return size
+}
+
+// packBits packs the binary state observation state into bytes, one
+// bit per element with the first element in the least significant bit
+// of the first byte
+func packBits(state []float64) ([]byte, error) {
+	packed := make([]byte, (len(state)+7)/8)
+	for i, v := range state {
+		switch v {
+		case 0:
+		case 1:
+			packed[i/8] |= 1 << uint(i%8)
+		default:
+			return nil, fmt.Errorf("cannot bit-pack non-binary value %v at "+
+				"index %v", v, i)
+		}
+	}
+	return packed, nil
+}
+
+// unpackBits unpacks a state observation of size elements packed by
+// packBits
+func unpackBits(packed []byte, size int) ([]float64, error) {
+	if len(packed) != (size+7)/8 {
+		return nil, fmt.Errorf("expected %v bytes of bit-packed state but "+
+			"got %v", (size+7)/8, len(packed))
+	}
+
+	state := make([]float64, size)
+	for i := range state {
+		if packed[i/8]&(1<<uint(i%8)) != 0 {
+			state[i] = 1
+		}
+	}
+	return state, nil
+}
@@ -0,0 +1,156 @@
+package trace
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// binaryState returns a binary state observation of the given size
+// with an irregular pattern of set elements
+func binaryState(size int) []float64 {
+	state := make([]float64, size)
+	for i := range state {
+		if (i*i+i/3)%5 < 2 {
+			state[i] = 1
+		}
+	}
+	return state
+}
+
+// ones returns a state observation of the given size whose elements
+// are all 1
+func ones(size int) []float64 {
+	state := make([]float64, size)
+	for i := range state {
+		state[i] = 1
+	}
+	return state
+}
+
+// TestPackBits checks that binary state observations of sizes which are
+// and are not multiples of 8 are unchanged by packing and unpacking,
+// and that the unused bits of the last byte are zero
+func TestPackBits(t *testing.T) {
+	for _, size := range []int{0, 1, 7, 8, 9, 15, 16, 17, 100, 600} {
+		for _, state := range [][]float64{
+			make([]float64, size),
+			binaryState(size),
+			ones(size),
+		} {
+			packed, err := packBits(state)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := (size + 7) / 8; len(packed) != want {
+				t.Errorf("size %v: got %v bytes, want %v", size, len(packed),
+					want)
+			}
+			if size%8 != 0 && packed[len(packed)-1]>>uint(size%8) != 0 {
+				t.Errorf("size %v: got padding bits %08b", size,
+					packed[len(packed)-1])
+			}
+
+			got, err := unpackBits(packed, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, state) {
+				t.Errorf("size %v: got state %v, want %v", size, got, state)
+			}
+		}
+	}
+
+	// The first element is the least significant bit of the first byte
+	packed, err := packBits([]float64{1, 0, 0, 1, 0, 0, 0, 0, 0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0x09, 0x02}; !bytes.Equal(packed, want) {
+		t.Errorf("got packed state % x, want % x", packed, want)
+	}
+}
+
+// TestPackBitsErrors checks that non-binary values cannot be packed and
+// that packed states of the wrong length cannot be unpacked
+func TestPackBitsErrors(t *testing.T) {
+	for _, state := range [][]float64{{0, 1, 2}, {-1}, {0.5, 1}} {
+		if _, err := packBits(state); err == nil {
+			t.Errorf("%v: expected error", state)
+		}
+	}
+
+	for _, test := range []struct {
+		packed []byte
+		size   int
+	}{
+		{[]byte{}, 1},
+		{[]byte{0}, 0},
+		{[]byte{0}, 9},
+		{[]byte{0, 0}, 8},
+		{[]byte{0, 0}, 17},
+	} {
+		if _, err := unpackBits(test.packed, test.size); err == nil {
+			t.Errorf("%v bytes of %v elements: expected error",
+				len(test.packed), test.size)
+		}
+	}
+}
+
+// TestWriterBitpack checks that bit-packed JSON traces with states
+// whose size is not a multiple of 8 are read back unchanged
+func TestWriterBitpack(t *testing.T) {
+	h := Header{Game: "Freeway", Shape: []int{3, 1, 5}, Encoding: Bitpack}
+	want := []Transition{
+		{State: binaryState(15)},
+		{Episode: 1, Step: 3, State: ones(15), Action: 2, Reward: 1,
+			Terminal: true},
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tr := range want {
+		if err := w.Write(tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Write(Transition{State: []float64{0, 2}}); err == nil {
+		t.Error("expected error bit-packing a non-binary state")
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Header(); !reflect.DeepEqual(got, h) {
+		t.Errorf("got header %+v, want %+v", got, h)
+	}
+	for i, tr := range want {
+		got, err := r.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tr) {
+			t.Errorf("transition %v: got %+v, want %+v", i, got, tr)
+		}
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("got error %v at the end of the trace, want EOF", err)
+	}
+
+	// A bit-packed trace must have a state shape
+	if _, err := NewWriter(io.Discard, Header{Encoding: Bitpack}); err ==
+		nil {
+		t.Error("expected error writing a bit-packed trace without a shape")
+	}
+	if _, err := NewWriter(io.Discard, Header{Encoding: "zip"}); err == nil {
+		t.Error("expected error writing a trace of an unknown encoding")
+	}
+}
@@ -0,0 +1,431 @@
+package trace
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// parquetMagic begins and ends every Parquet file
+const parquetMagic = "PAR1"
+
+// parquetRowGroupSize is the number of buffered transitions after which
+// a ParquetWriter writes a row group, which bounds the memory used by
+// the writer
+const parquetRowGroupSize = 4096
+
+// Enums of the Parquet file format
+const (
+	parquetBoolean = 0 // Physical types
+	parquetInt32   = 1
+	parquetInt64   = 2
+	parquetFloat   = 4
+	parquetDouble  = 5
+
+	parquetRequired = 0 // Repetition types
+	parquetRepeated = 2
+
+	parquetList = 3 // Converted type of lists
+
+	parquetPlain = 0 // Encodings
+	parquetRLE   = 3
+
+	parquetGzip     = 2 // Compression codec
+	parquetDataPage = 0 // Page type
+)
+
+// ParquetWriter writes a trace as an Apache Parquet file, which data
+// analysis tools such as pandas, polars, and Arrow load directly:
+//
+//	df = pandas.read_parquet("trace.parquet")
+//
+// Each transition is a row with the columns episode, step, action,
+// reward, terminal, and state, where state is a list of float32 holding
+// the flat state observation. The header is stored as JSON in the
+// key-value metadata of the file under the key "goatar.header", so that
+// state observations can be reshaped using its Shape. Columns are
+// compressed with gzip, which shrinks the mostly empty state
+// observations greatly, so the Bitpack encoding is not supported.
+//
+// Transitions are buffered and written in row groups of up to 4096
+// rows, or whenever Flush is called. The file is only complete once
+// Close has been called, which writes the metadata of the file.
+type ParquetWriter struct {
+	w      io.Writer
+	header []byte // Header encoded as JSON
+	offset int64  // Number of bytes written to w
+
+	episode, step, action, reward, terminal, state *parquetColumn
+
+	rows   int // Number of buffered transitions
+	groups []parquetRowGroup
+	closed bool
+}
+
+// parquetColumn buffers the values of a column of a trace in the
+// current row group
+type parquetColumn struct {
+	path   []string // Path of the column in the schema
+	typ    int32    // Physical type
+	values []byte   // PLAIN-encoded values, or one byte per boolean
+	n      int      // Number of values, including empty lists
+
+	// list is whether the column is a list, in which case rep and def
+	// are its repetition and definition levels
+	list     bool
+	rep, def levels
+}
+
+// levels are the repetition or definition levels of a list column with
+// a maximum level of 1, stored as runs of equal levels
+type levels []levelRun
+
+// levelRun is a run of equal levels
+type levelRun struct {
+	level byte
+	count int
+}
+
+// parquetChunk describes a column chunk which has been written
+type parquetChunk struct {
+	offset       int64 // Offset of the data page of the chunk
+	values       int64
+	uncompressed int64 // Size of the chunk before compression
+	compressed   int64
+}
+
+// parquetRowGroup describes a row group which has been written
+type parquetRowGroup struct {
+	chunks []parquetChunk // Column chunks, in the order of the schema
+	rows   int64
+}
+
+// NewParquetWriter returns a new ParquetWriter which writes to w. The
+// header h is written to the metadata of the file when it is closed.
+func NewParquetWriter(w io.Writer, h Header) (*ParquetWriter, error) {
+	if err := h.Encoding.validate(h); err != nil {
+		return nil, fmt.Errorf("newParquetWriter: %v", err)
+	}
+	if h.Encoding != Float {
+		return nil, fmt.Errorf("newParquetWriter: encoding %q is not "+
+			"supported by parquet traces", h.Encoding)
+	}
+	header, err := json.Marshal(h)
+	if err != nil {
+		return nil, fmt.Errorf("newParquetWriter: %v", err)
+	}
+
+	column := func(typ int32, path ...string) *parquetColumn {
+		return &parquetColumn{path: path, typ: typ}
+	}
+	pw := &ParquetWriter{
+		w:        w,
+		header:   header,
+		episode:  column(parquetInt64, "episode"),
+		step:     column(parquetInt64, "step"),
+		action:   column(parquetInt32, "action"),
+		reward:   column(parquetDouble, "reward"),
+		terminal: column(parquetBoolean, "terminal"),
+		state:    column(parquetFloat, "state", "list", "element"),
+	}
+	pw.state.list = true
+	if err := pw.write([]byte(parquetMagic)); err != nil {
+		return nil, fmt.Errorf("newParquetWriter: %v", err)
+	}
+	return pw, nil
+}
+
+// columns returns the columns of the trace in the order of the schema
+func (w *ParquetWriter) columns() []*parquetColumn {
+	return []*parquetColumn{w.episode, w.step, w.action, w.reward,
+		w.terminal, w.state}
+}
+
+// Write writes a single transition to the trace
+func (w *ParquetWriter) Write(t Transition) error {
+	if w.closed {
+		return fmt.Errorf("write: writer is closed")
+	}
+
+	w.episode.values = appendUint64(w.episode.values, uint64(t.Episode))
+	w.step.values = appendUint64(w.step.values, uint64(t.Step))
+	w.action.values = appendUint32(w.action.values, uint32(t.Action))
+	w.reward.values = appendUint64(w.reward.values,
+		math.Float64bits(t.Reward))
+	if t.Terminal {
+		w.terminal.values = append(w.terminal.values, 1)
+	} else {
+		w.terminal.values = append(w.terminal.values, 0)
+	}
+	for _, c := range []*parquetColumn{w.episode, w.step, w.action,
+		w.reward, w.terminal} {
+		c.n++
+	}
+
+	// Each element of the state is a level entry, with the first
+	// starting a new row, while an empty state is a single entry
+	// with no value
+	s := w.state
+	if len(t.State) == 0 {
+		s.rep.add(0, 1)
+		s.def.add(0, 1)
+		s.n++
+	} else {
+		s.rep.add(0, 1)
+		s.rep.add(1, len(t.State)-1)
+		s.def.add(1, len(t.State))
+		s.n += len(t.State)
+	}
+	for _, v := range t.State {
+		s.values = appendUint32(s.values, math.Float32bits(float32(v)))
+	}
+
+	w.rows++
+	if w.rows >= parquetRowGroupSize {
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("write: %v", err)
+		}
+	}
+	return nil
+}
+
+// Flush writes the buffered transitions to the underlying io.Writer as
+// a row group
+func (w *ParquetWriter) Flush() error {
+	if w.rows == 0 {
+		return nil
+	}
+
+	group := parquetRowGroup{rows: int64(w.rows)}
+	for _, c := range w.columns() {
+		chunk, err := w.writeChunk(c)
+		if err != nil {
+			return fmt.Errorf("flush: %v", err)
+		}
+		group.chunks = append(group.chunks, chunk)
+	}
+	w.groups = append(w.groups, group)
+	w.rows = 0
+	return nil
+}
+
+// writeChunk writes the buffered values of column c as a column chunk
+// of a single data page, and clears the buffered values
+func (w *ParquetWriter) writeChunk(c *parquetColumn) (parquetChunk, error) {
+	var body []byte
+	if c.list {
+		body = c.rep.encode(body)
+		body = c.def.encode(body)
+	}
+	if c.typ == parquetBoolean {
+		body = append(body, packBools(c.values)...)
+	} else {
+		body = append(body, c.values...)
+	}
+	compressed := gzipBytes(body)
+
+	e := newThriftEncoder()
+	e.i32(1, parquetDataPage)
+	e.i32(2, int32(len(body)))
+	e.i32(3, int32(len(compressed)))
+	e.beginStruct(5)
+	e.i32(1, int32(c.n))
+	e.i32(2, parquetPlain)
+	e.i32(3, parquetRLE)
+	e.i32(4, parquetRLE)
+	e.endStruct()
+	header := e.bytes()
+
+	chunk := parquetChunk{
+		offset:       w.offset,
+		values:       int64(c.n),
+		uncompressed: int64(len(header) + len(body)),
+		compressed:   int64(len(header) + len(compressed)),
+	}
+	if err := w.write(header); err != nil {
+		return parquetChunk{}, err
+	}
+	if err := w.write(compressed); err != nil {
+		return parquetChunk{}, err
+	}
+
+	c.values = c.values[:0]
+	c.n = 0
+	c.rep = c.rep[:0]
+	c.def = c.def[:0]
+	return chunk, nil
+}
+
+// Close writes any buffered transitions and the metadata of the file,
+// after which no more transitions can be written. The underlying
+// io.Writer is not closed.
+func (w *ParquetWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("close: %v", err)
+	}
+	w.closed = true
+
+	metadata := w.metadata()
+	if err := w.write(metadata); err != nil {
+		return fmt.Errorf("close: %v", err)
+	}
+	footer := appendUint32(nil, uint32(len(metadata)))
+	if err := w.write(append(footer, parquetMagic...)); err != nil {
+		return fmt.Errorf("close: %v", err)
+	}
+	return nil
+}
+
+// metadata returns the FileMetaData of the file
+func (w *ParquetWriter) metadata() []byte {
+	var rows int64
+	for _, g := range w.groups {
+		rows += g.rows
+	}
+
+	e := newThriftEncoder()
+	e.i32(1, 1)
+
+	// The schema is flattened depth-first, with each group followed by
+	// its children
+	schema := []struct {
+		name                string
+		typ, repetition     int32 // -1 if not set
+		children, converted int32 // -1 if not set
+	}{
+		{"schema", -1, -1, 6, -1},
+		{"episode", parquetInt64, parquetRequired, -1, -1},
+		{"step", parquetInt64, parquetRequired, -1, -1},
+		{"action", parquetInt32, parquetRequired, -1, -1},
+		{"reward", parquetDouble, parquetRequired, -1, -1},
+		{"terminal", parquetBoolean, parquetRequired, -1, -1},
+		{"state", -1, parquetRequired, 1, parquetList},
+		{"list", -1, parquetRepeated, 1, -1},
+		{"element", parquetFloat, parquetRequired, -1, -1},
+	}
+	e.beginList(2, len(schema), compactStruct)
+	for _, el := range schema {
+		e.listStruct()
+		if el.typ >= 0 {
+			e.i32(1, el.typ)
+		}
+		if el.repetition >= 0 {
+			e.i32(3, el.repetition)
+		}
+		e.string(4, el.name)
+		if el.children >= 0 {
+			e.i32(5, el.children)
+		}
+		if el.converted >= 0 {
+			e.i32(6, el.converted)
+		}
+		e.endStruct()
+	}
+	e.i64(3, rows)
+
+	columns := w.columns()
+	e.beginList(4, len(w.groups), compactStruct)
+	for _, g := range w.groups {
+		var size int64
+		e.listStruct()
+		e.beginList(1, len(g.chunks), compactStruct)
+		for i, chunk := range g.chunks {
+			c := columns[i]
+			e.listStruct()
+			e.i64(2, chunk.offset)
+			e.beginStruct(3)
+			e.i32(1, c.typ)
+			if c.list {
+				e.beginList(2, 2, compactI32)
+				e.listI32(parquetPlain)
+				e.listI32(parquetRLE)
+			} else {
+				e.beginList(2, 1, compactI32)
+				e.listI32(parquetPlain)
+			}
+			e.beginList(3, len(c.path), compactBinary)
+			for _, name := range c.path {
+				e.listString(name)
+			}
+			e.i32(4, parquetGzip)
+			e.i64(5, chunk.values)
+			e.i64(6, chunk.uncompressed)
+			e.i64(7, chunk.compressed)
+			e.i64(9, chunk.offset)
+			e.endStruct()
+			e.endStruct()
+			size += chunk.uncompressed
+		}
+		e.i64(2, size)
+		e.i64(3, g.rows)
+		e.endStruct()
+	}
+
+	e.beginList(5, 1, compactStruct)
+	e.listStruct()
+	e.string(1, "goatar.header")
+	e.string(2, string(w.header))
+	e.endStruct()
+	e.string(6, "goatar")
+	return e.bytes()
+}
+
+// write writes b to the underlying io.Writer
+func (w *ParquetWriter) write(b []byte) error {
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	return err
+}
+
+// add appends count levels of the given level
+func (l *levels) add(level byte, count int) {
+	if count == 0 {
+		return
+	}
+	if n := len(*l); n > 0 && (*l)[n-1].level == level {
+		(*l)[n-1].count += count
+		return
+	}
+	*l = append(*l, levelRun{level: level, count: count})
+}
+
+// encode appends the levels to buf in the RLE encoding of data pages,
+// prefixed by the length of the encoding. Levels have a bit width of 1,
+// so each run is its length followed by a single byte holding the
+// level.
+func (l levels) encode(buf []byte) []byte {
+	var runs []byte
+	for _, r := range l {
+		runs = appendUvarint(runs, uint64(r.count)<<1)
+		runs = append(runs, r.level)
+	}
+	buf = appendUint32(buf, uint32(len(runs)))
+	return append(buf, runs...)
+}
+
+// packBools packs booleans, given as one byte each, one bit per value
+// with the first value in the least significant bit of the first byte
+func packBools(values []byte) []byte {
+	packed := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v != 0 {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return packed
+}
+
+// gzipBytes returns b compressed with gzip
+func gzipBytes(b []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(b)
+	zw.Close()
+	return buf.Bytes()
+}
@@ -0,0 +1,629 @@
+package trace
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// thriftReader decodes structs in the Thrift compact protocol, as an
+// independent check of thriftEncoder. Structs are decoded to maps from
+// field ids to values, lists to slices, integers to int64, and binary
+// fields to strings.
+type thriftReader struct {
+	b   []byte
+	pos int
+}
+
+// byte returns the next byte
+func (r *thriftReader) byte() (byte, error) {
+	if r.pos >= len(r.b) {
+		return 0, fmt.Errorf("unexpected end of data")
+	}
+	r.pos++
+	return r.b[r.pos-1], nil
+}
+
+// uvarint returns the next varint
+func (r *thriftReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid varint at %v", r.pos)
+	}
+	r.pos += n
+	return v, nil
+}
+
+// int returns the next zigzag-encoded varint
+func (r *thriftReader) int() (int64, error) {
+	v, err := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1), err
+}
+
+// value returns the next value of the given compact type
+func (r *thriftReader) value(typ byte) (interface{}, error) {
+	switch typ {
+	case 1, 2: // Booleans in lists
+		b, err := r.byte()
+		return b == 1, err
+	case 3:
+		b, err := r.byte()
+		return int64(int8(b)), err
+	case 4, compactI32, compactI64:
+		return r.int()
+	case compactBinary:
+		n, err := r.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if r.pos+int(n) > len(r.b) {
+			return nil, fmt.Errorf("binary of %v bytes overflows data", n)
+		}
+		r.pos += int(n)
+		return string(r.b[r.pos-int(n) : r.pos]), nil
+	case compactList:
+		h, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		n := uint64(h >> 4)
+		if n == 15 {
+			if n, err = r.uvarint(); err != nil {
+				return nil, err
+			}
+		}
+		list := []interface{}{}
+		for i := uint64(0); i < n; i++ {
+			v, err := r.value(h & 0x0f)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case compactStruct:
+		return r.structure()
+	default:
+		return nil, fmt.Errorf("unsupported type %v", typ)
+	}
+}
+
+// structure returns the next struct
+func (r *thriftReader) structure() (map[int]interface{}, error) {
+	s := map[int]interface{}{}
+	id := 0
+	for {
+		h, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if h == 0 {
+			return s, nil
+		}
+
+		if delta := int(h >> 4); delta != 0 {
+			id += delta
+		} else {
+			v, err := r.int()
+			if err != nil {
+				return nil, err
+			}
+			id = int(v)
+		}
+		if _, ok := s[id]; ok {
+			return nil, fmt.Errorf("duplicate field %v", id)
+		}
+
+		switch typ := h & 0x0f; typ {
+		case 1, 2: // Booleans of struct fields are encoded in the type
+			s[id] = typ == 1
+		default:
+			if s[id], err = r.value(typ); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// field returns the field of struct s at the given path of field ids
+// and list indices, failing the test if it does not exist
+func field(t *testing.T, s interface{}, path ...int) interface{} {
+	t.Helper()
+	for i, id := range path {
+		switch v := s.(type) {
+		case map[int]interface{}:
+			s = v[id]
+		case []interface{}:
+			if id >= len(v) {
+				t.Fatalf("index %v out of range", path[:i+1])
+			}
+			s = v[id]
+		}
+		if s == nil {
+			t.Fatalf("missing field %v", path[:i+1])
+		}
+	}
+	return s
+}
+
+// parquetFile is a Parquet file decoded by readParquet
+type parquetFile struct {
+	metadata    map[int]interface{} // FileMetaData
+	rowGroups   []int               // Number of rows of each row group
+	transitions []Transition
+}
+
+// readParquet decodes a trace written by a ParquetWriter following the
+// Parquet format specification, checking the layout of the file
+func readParquet(t *testing.T, b []byte) parquetFile {
+	t.Helper()
+	if len(b) < 12 || string(b[:4]) != parquetMagic ||
+		string(b[len(b)-4:]) != parquetMagic {
+		t.Fatalf("file of %v bytes does not begin and end with %q",
+			len(b), parquetMagic)
+	}
+	n := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	start := len(b) - 8 - n
+	if start < 4 {
+		t.Fatalf("metadata of %v bytes overflows file of %v bytes", n,
+			len(b))
+	}
+	r := &thriftReader{b: b[:len(b)-8], pos: start}
+	metadata, err := r.structure()
+	if err != nil {
+		t.Fatalf("decoding metadata: %v", err)
+	}
+	if r.pos != len(b)-8 {
+		t.Fatalf("metadata ends at %v, want %v", r.pos, len(b)-8)
+	}
+
+	f := parquetFile{metadata: metadata}
+	offset := int64(4) // Column chunks follow each other from the magic
+	for g, group := range field(t, metadata, 4).([]interface{}) {
+		rows := int(field(t, group, 3).(int64))
+		f.rowGroups = append(f.rowGroups, rows)
+		transitions := make([]Transition, rows)
+
+		var size int64
+		for c, chunk := range field(t, group, 1).([]interface{}) {
+			meta := field(t, chunk, 3)
+			if got := field(t, chunk, 2).(int64); got != offset {
+				t.Fatalf("row group %v column %v: got offset %v, want %v",
+					g, c, got, offset)
+			}
+			if got := field(t, meta, 9).(int64); got != offset {
+				t.Fatalf("row group %v column %v: got data page offset "+
+					"%v, want %v", g, c, got, offset)
+			}
+
+			page := &thriftReader{b: b[:start], pos: int(offset)}
+			header, err := page.structure()
+			if err != nil {
+				t.Fatalf("row group %v column %v: %v", g, c, err)
+			}
+			compressed := int(field(t, header, 3).(int64))
+			if page.pos+compressed > start {
+				t.Fatalf("row group %v column %v: page overflows data",
+					g, c)
+			}
+			zr, err := gzip.NewReader(bytes.NewReader(
+				b[page.pos : page.pos+compressed]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := ioutil.ReadAll(zr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := int64(len(body)); got != field(t, header, 2) {
+				t.Fatalf("row group %v column %v: got page of %v bytes, "+
+					"want %v", g, c, got, field(t, header, 2))
+			}
+
+			headerSize := int64(page.pos) - offset
+			if got, want := field(t, meta, 7), headerSize+
+				int64(compressed); got != want {
+				t.Fatalf("row group %v column %v: got compressed size "+
+					"%v, want %v", g, c, got, want)
+			}
+			if got, want := field(t, meta, 6), headerSize+
+				int64(len(body)); got != want {
+				t.Fatalf("row group %v column %v: got uncompressed size "+
+					"%v, want %v", g, c, got, want)
+			}
+			if got, want := field(t, meta, 5), field(t, header, 5,
+				1); got != want {
+				t.Fatalf("row group %v column %v: got %v values, want %v",
+					g, c, got, want)
+			}
+			offset = int64(page.pos + compressed)
+			size += headerSize + int64(len(body))
+
+			name := field(t, meta, 3, 0).(string)
+			values := int(field(t, header, 5, 1).(int64))
+			decodeColumn(t, name, body, values, transitions)
+		}
+		if got := field(t, group, 2).(int64); got != size {
+			t.Errorf("row group %v: got size %v, want %v", g, got, size)
+		}
+		f.transitions = append(f.transitions, transitions...)
+	}
+	if offset != int64(start) {
+		t.Fatalf("column chunks end at %v, want %v", offset, start)
+	}
+	return f
+}
+
+// decodeColumn decodes the data page body of the named column, which
+// holds the given number of values, into the transitions of a row
+// group
+func decodeColumn(t *testing.T, name string, body []byte, values int,
+	transitions []Transition) {
+	t.Helper()
+	if name != "state" {
+		if values != len(transitions) {
+			t.Fatalf("column %v: got %v values, want %v", name, values,
+				len(transitions))
+		}
+		want := values * map[string]int{"episode": 8, "step": 8,
+			"action": 4, "reward": 8}[name]
+		if name == "terminal" {
+			want = (values + 7) / 8 // Booleans are bit-packed
+		}
+		if len(body) != want {
+			t.Fatalf("column %v: got %v bytes of values, want %v", name,
+				len(body), want)
+		}
+		for i := range transitions {
+			tr := &transitions[i]
+			switch name {
+			case "episode":
+				tr.Episode = int(int64(binary.LittleEndian.Uint64(
+					body[8*i:])))
+			case "step":
+				tr.Step = int(int64(binary.LittleEndian.Uint64(body[8*i:])))
+			case "action":
+				tr.Action = int(int32(binary.LittleEndian.Uint32(
+					body[4*i:])))
+			case "reward":
+				tr.Reward = math.Float64frombits(binary.LittleEndian.Uint64(
+					body[8*i:]))
+			case "terminal":
+				tr.Terminal = body[i/8]&(1<<uint(i%8)) != 0
+			default:
+				t.Fatalf("unknown column %v", name)
+			}
+		}
+		return
+	}
+
+	rep, body := decodeLevels(t, body, values)
+	def, body := decodeLevels(t, body, values)
+	row := -1
+	for i := range rep {
+		if rep[i] == 0 {
+			row++
+			if row >= len(transitions) {
+				t.Fatalf("column state: more than %v rows", len(transitions))
+			}
+			transitions[row].State = []float64{}
+		}
+		if def[i] == 0 {
+			if rep[i] != 0 {
+				t.Fatalf("column state: empty list continues a row")
+			}
+			continue
+		}
+		if len(body) < 4 {
+			t.Fatalf("column state: too few values")
+		}
+		v := math.Float32frombits(binary.LittleEndian.Uint32(body))
+		transitions[row].State = append(transitions[row].State, float64(v))
+		body = body[4:]
+	}
+	if row != len(transitions)-1 || len(body) != 0 {
+		t.Fatalf("column state: got %v rows and %v bytes of unused values, "+
+			"want %v rows", row+1, len(body), len(transitions))
+	}
+}
+
+// decodeLevels decodes n repetition or definition levels of bit width
+// 1 in the RLE encoding from the start of body, and returns the levels
+// and the remainder of body
+func decodeLevels(t *testing.T, body []byte, n int) ([]byte, []byte) {
+	t.Helper()
+	if len(body) < 4 {
+		t.Fatalf("missing length of levels")
+	}
+	size := int(binary.LittleEndian.Uint32(body))
+	if 4+size > len(body) {
+		t.Fatalf("levels of %v bytes overflow page", size)
+	}
+	runs, rest := body[4:4+size], body[4+size:]
+
+	var levels []byte
+	for len(runs) > 0 {
+		h, k := binary.Uvarint(runs)
+		if k <= 0 {
+			t.Fatalf("invalid run header")
+		}
+		runs = runs[k:]
+		if h&1 == 0 {
+			if len(runs) == 0 || runs[0] > 1 {
+				t.Fatalf("invalid value of run")
+			}
+			for i := uint64(0); i < h>>1; i++ {
+				levels = append(levels, runs[0])
+			}
+			runs = runs[1:]
+			continue
+		}
+
+		// Bit-packed runs hold groups of 8 levels in one byte each
+		groups := int(h >> 1)
+		if len(runs) < groups {
+			t.Fatalf("bit-packed run overflows levels")
+		}
+		for _, b := range runs[:groups] {
+			for j := uint(0); j < 8; j++ {
+				levels = append(levels, b>>j&1)
+			}
+		}
+		runs = runs[groups:]
+	}
+	if len(levels) < n {
+		t.Fatalf("got %v levels, want %v", len(levels), n)
+	}
+	return levels[:n], rest
+}
+
+// writeParquet writes the transitions to a parquet trace with header h,
+// calling Flush after the transitions at the given indices
+func writeParquet(t *testing.T, h Header, transitions []Transition,
+	flushes ...int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewParquetWriter(&buf, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tr := range transitions {
+		if err := w.Write(tr); err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range flushes {
+			if f == i {
+				if err := w.Flush(); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// float32Transitions returns the transitions with their states rounded
+// to float32, as stored in parquet traces, and empty states non-nil
+func float32Transitions(transitions []Transition) []Transition {
+	rounded := make([]Transition, len(transitions))
+	for i, tr := range transitions {
+		rounded[i] = tr
+		rounded[i].State = []float64{}
+		for _, v := range tr.State {
+			rounded[i].State = append(rounded[i].State,
+				float64(float32(v)))
+		}
+	}
+	return rounded
+}
+
+// TestParquetMetadata checks the magic bytes, footer, and Thrift-encoded
+// FileMetaData of a parquet trace against the Parquet format
+// specification
+func TestParquetMetadata(t *testing.T) {
+	h := Header{Game: "Breakout", Shape: []int{1, 2, 2}, Seed: 3,
+		StickyActionsProb: 0.1}
+	f := readParquet(t, writeParquet(t, h, transitions))
+	m := f.metadata
+
+	if got := field(t, m, 1); got != int64(1) {
+		t.Errorf("got version %v, want 1", got)
+	}
+	if got := field(t, m, 3); got != int64(len(transitions)) {
+		t.Errorf("got %v rows, want %v", got, len(transitions))
+	}
+	if got := field(t, m, 6); got != "goatar" {
+		t.Errorf("got created_by %q, want %q", got, "goatar")
+	}
+
+	// Schema elements are (type, repetition, name, children, converted
+	// type), with nil for unset fields
+	want := [][]interface{}{
+		{nil, nil, "schema", int64(6), nil},
+		{int64(parquetInt64), int64(parquetRequired), "episode", nil, nil},
+		{int64(parquetInt64), int64(parquetRequired), "step", nil, nil},
+		{int64(parquetInt32), int64(parquetRequired), "action", nil, nil},
+		{int64(parquetDouble), int64(parquetRequired), "reward", nil, nil},
+		{int64(parquetBoolean), int64(parquetRequired), "terminal", nil,
+			nil},
+		{nil, int64(parquetRequired), "state", int64(1),
+			int64(parquetList)},
+		{nil, int64(parquetRepeated), "list", int64(1), nil},
+		{int64(parquetFloat), int64(parquetRequired), "element", nil, nil},
+	}
+	schema := field(t, m, 2).([]interface{})
+	if len(schema) != len(want) {
+		t.Fatalf("got %v schema elements, want %v", len(schema), len(want))
+	}
+	for i, el := range schema {
+		s := el.(map[int]interface{})
+		got := []interface{}{s[1], s[3], s[4], s[5], s[6]}
+		if len(s) != 5-countNil(want[i]) || !reflect.DeepEqual(got,
+			want[i]) {
+			t.Errorf("schema element %v: got %v, want %v", i, s, want[i])
+		}
+	}
+
+	if len(f.rowGroups) != 1 {
+		t.Fatalf("got %v row groups, want 1", len(f.rowGroups))
+	}
+	paths := [][]interface{}{{"episode"}, {"step"}, {"action"},
+		{"reward"}, {"terminal"}, {"state", "list", "element"}}
+	types := []int64{parquetInt64, parquetInt64, parquetInt32,
+		parquetDouble, parquetBoolean, parquetFloat}
+	for i, chunk := range field(t, m, 4, 0, 1).([]interface{}) {
+		meta := field(t, chunk, 3)
+		if got := field(t, meta, 1); got != types[i] {
+			t.Errorf("column %v: got type %v, want %v", i, got, types[i])
+		}
+		if got := field(t, meta, 3); !reflect.DeepEqual(got, paths[i]) {
+			t.Errorf("column %v: got path %v, want %v", i, got, paths[i])
+		}
+		if got := field(t, meta, 4); got != int64(parquetGzip) {
+			t.Errorf("column %v: got codec %v, want %v", i, got,
+				parquetGzip)
+		}
+	}
+
+	kv := field(t, m, 5).([]interface{})
+	if len(kv) != 1 || field(t, kv, 0, 1) != "goatar.header" {
+		t.Fatalf("got key-value metadata %v", kv)
+	}
+	var got Header
+	if err := json.Unmarshal([]byte(field(t, kv, 0, 2).(string)),
+		&got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, h) {
+		t.Errorf("got header %+v, want %+v", got, h)
+	}
+
+	if want := float32Transitions(transitions); !reflect.DeepEqual(
+		f.transitions, want) {
+		t.Errorf("got transitions %+v, want %+v", f.transitions, want)
+	}
+}
+
+// countNil returns the number of nil elements of s
+func countNil(s []interface{}) int {
+	n := 0
+	for _, v := range s {
+		if v == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// TestParquetRowGroups checks that transitions are written in row
+// groups of up to 4096 rows and on each Flush, with boolean columns
+// spanning partial bytes and empty state observations in every row
+// group
+func TestParquetRowGroups(t *testing.T) {
+	const n = 2*parquetRowGroupSize + 13
+	want := make([]Transition, n)
+	for i := range want {
+		want[i] = Transition{
+			Episode:  i / 100,
+			Step:     i % 100,
+			Action:   i % 6,
+			Reward:   float64(i%7) - 3,
+			Terminal: (i*i)%3 == 1,
+		}
+		if i%5 != 2 {
+			want[i].State = binaryState(i % 11)
+		}
+	}
+
+	h := Header{Game: "Freeway", Shape: []int{1, 1, 10}}
+	f := readParquet(t, writeParquet(t, h, want, 2, n-6))
+	wantGroups := []int{3, parquetRowGroupSize, parquetRowGroupSize, 5, 5}
+	if !reflect.DeepEqual(f.rowGroups, wantGroups) {
+		t.Errorf("got row groups of %v rows, want %v", f.rowGroups,
+			wantGroups)
+	}
+	if got := field(t, f.metadata, 3); got != int64(n) {
+		t.Errorf("got %v rows, want %v", got, n)
+	}
+	if !reflect.DeepEqual(f.transitions, float32Transitions(want)) {
+		t.Error("transitions differ")
+	}
+
+	// An empty trace has no row groups
+	f = readParquet(t, writeParquet(t, h, nil))
+	if len(f.rowGroups) != 0 || field(t, f.metadata, 3) != int64(0) {
+		t.Errorf("got %v rows in row groups %v, want none",
+			f.metadata[3], f.rowGroups)
+	}
+}
+
+// TestParquetWriterErrors checks that bit-packed traces are rejected,
+// and that transitions cannot be written after closing
+func TestParquetWriterErrors(t *testing.T) {
+	if _, err := NewParquetWriter(&bytes.Buffer{}, Header{
+		Shape: []int{4}, Encoding: Bitpack}); err == nil {
+		t.Error("expected error writing a bit-packed parquet trace")
+	}
+
+	var buf bytes.Buffer
+	w, err := NewParquetWriter(&buf, Header{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	n := buf.Len()
+	if err := w.Close(); err != nil {
+		t.Errorf("got error %v closing twice", err)
+	}
+	if err := w.Write(Transition{}); err == nil {
+		t.Error("expected error writing after closing")
+	}
+	if buf.Len() != n {
+		t.Errorf("got %v bytes after closing twice, want %v", buf.Len(), n)
+	}
+}
+
+// TestParquetGolden checks a small parquet trace against the golden file
+// testdata/trace.parquet, which can be inspected with e.g.
+//
+//	python -c 'import pyarrow.parquet as pq; \
+//		print(pq.read_table("trace/testdata/trace.parquet"))'
+//
+// Run with -update to rewrite the golden file.
+func TestParquetGolden(t *testing.T) {
+	h := Header{Game: "Breakout", Shape: []int{1, 2, 2}, Seed: 3}
+	got := writeParquet(t, h, transitions)
+	path := filepath.Join("testdata", "trace.parquet")
+
+	if *update {
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create the golden file)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %v bytes differing from the %v bytes of %v",
+			len(got), len(want), path)
+	}
+	readParquet(t, want)
+}
@@ -0,0 +1,352 @@
+package trace
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// maxMessageSize is the largest message which a ProtoReader will read,
+// which protects readers from corrupted or malicious length prefixes
+const maxMessageSize = 64 << 20
+
+// Episode is the sequence of transitions of a single episode
+type Episode []Transition
+
+// MarshalBinary encodes the header as a Header message of the
+// protocol buffer schema in trace.proto
+func (h Header) MarshalBinary() ([]byte, error) {
+	var e encoder
+	e.bytes(1, []byte(h.Game))
+	e.packedInt32(2, h.Shape)
+	e.int64(3, h.Seed)
+	e.double(4, h.StickyActionsProb)
+	e.bool(5, h.DifficultyRamping)
+	e.bytes(6, []byte(h.Encoding))
+	return e.buf, nil
+}
+
+// UnmarshalBinary decodes a Header message of the protocol buffer
+// schema in trace.proto
+func (h *Header) UnmarshalBinary(data []byte) error {
+	*h = Header{}
+	d := decoder{buf: data}
+	for !d.done() {
+		field, wireType, err := d.next()
+		if err != nil {
+			return fmt.Errorf("unmarshalBinary: %v", err)
+		}
+
+		switch field {
+		case 1:
+			var b []byte
+			b, err = d.bytesField(wireType)
+			h.Game = string(b)
+		case 2:
+			h.Shape, err = d.int32s(wireType, h.Shape)
+		case 3:
+			var v uint64
+			v, err = d.varintField(wireType)
+			h.Seed = int64(v)
+		case 4:
+			h.StickyActionsProb, err = d.doubleField(wireType)
+		case 5:
+			var v uint64
+			v, err = d.varintField(wireType)
+			h.DifficultyRamping = v != 0
+		case 6:
+			var b []byte
+			b, err = d.bytesField(wireType)
+			h.Encoding = Encoding(b)
+		default:
+			err = d.skip(wireType)
+		}
+		if err != nil {
+			return fmt.Errorf("unmarshalBinary: field %v: %v", field, err)
+		}
+	}
+	return nil
+}
+
+// MarshalBinary encodes the transition as a Transition message of the
+// protocol buffer schema in trace.proto. The state observation is
+// stored as float32, which represents every value produced by the
+// GoAtar games exactly.
+func (t Transition) MarshalBinary() ([]byte, error) {
+	return t.marshal(Float)
+}
+
+// marshal encodes the transition as a Transition message, storing the
+// state observation with the given encoding
+func (t Transition) marshal(encoding Encoding) ([]byte, error) {
+	var e encoder
+	e.int64(1, int64(t.Episode))
+	e.int64(2, int64(t.Step))
+	if encoding == Bitpack {
+		bits, err := packBits(t.State)
+		if err != nil {
+			return nil, err
+		}
+		e.bytes(7, bits)
+	} else {
+		e.packedFloat(3, t.State)
+	}
+	e.int64(4, int64(t.Action))
+	e.double(5, t.Reward)
+	e.bool(6, t.Terminal)
+	return e.buf, nil
+}
+
+// UnmarshalBinary decodes a Transition message of the protocol buffer
+// schema in trace.proto. Transitions with bit-packed state
+// observations can only be decoded by a ProtoReader, which knows the
+// shape of the state observations from the trace header.
+func (t *Transition) UnmarshalBinary(data []byte) error {
+	return t.unmarshal(data, -1)
+}
+
+// unmarshal decodes a Transition message whose bit-packed state
+// observation, if any, has size elements. If size is negative,
+// bit-packed state observations cannot be decoded.
+func (t *Transition) unmarshal(data []byte, size int) error {
+	*t = Transition{}
+	d := decoder{buf: data}
+	for !d.done() {
+		field, wireType, err := d.next()
+		if err != nil {
+			return fmt.Errorf("unmarshalBinary: %v", err)
+		}
+
+		var v uint64
+		switch field {
+		case 1:
+			v, err = d.varintField(wireType)
+			t.Episode = int(int64(v))
+		case 2:
+			v, err = d.varintField(wireType)
+			t.Step = int(int64(v))
+		case 3:
+			t.State, err = d.floats(wireType, t.State)
+		case 4:
+			v, err = d.varintField(wireType)
+			t.Action = int(int32(v))
+		case 5:
+			t.Reward, err = d.doubleField(wireType)
+		case 6:
+			v, err = d.varintField(wireType)
+			t.Terminal = v != 0
+		case 7:
+			var b []byte
+			b, err = d.bytesField(wireType)
+			if err == nil && size < 0 {
+				err = fmt.Errorf("bit-packed state observations must be " +
+					"read with a ProtoReader")
+			} else if err == nil {
+				t.State, err = unpackBits(b, size)
+			}
+		default:
+			err = d.skip(wireType)
+		}
+		if err != nil {
+			return fmt.Errorf("unmarshalBinary: field %v: %v", field, err)
+		}
+	}
+	return nil
+}
+
+// MarshalBinary encodes the episode as an Episode message of the
+// protocol buffer schema in trace.proto
+func (ep Episode) MarshalBinary() ([]byte, error) {
+	var e encoder
+	for _, t := range ep {
+		b, err := t.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("marshalBinary: %v", err)
+		}
+		e.message(1, b)
+	}
+	return e.buf, nil
+}
+
+// UnmarshalBinary decodes an Episode message of the protocol buffer
+// schema in trace.proto
+func (ep *Episode) UnmarshalBinary(data []byte) error {
+	*ep = nil
+	d := decoder{buf: data}
+	for !d.done() {
+		field, wireType, err := d.next()
+		if err != nil {
+			return fmt.Errorf("unmarshalBinary: %v", err)
+		}
+
+		if field != 1 {
+			if err := d.skip(wireType); err != nil {
+				return fmt.Errorf("unmarshalBinary: field %v: %v", field,
+					err)
+			}
+			continue
+		}
+
+		b, err := d.bytesField(wireType)
+		if err != nil {
+			return fmt.Errorf("unmarshalBinary: field %v: %v", field, err)
+		}
+		var t Transition
+		if err := t.UnmarshalBinary(b); err != nil {
+			return fmt.Errorf("unmarshalBinary: transition %v: %v",
+				len(*ep), err)
+		}
+		*ep = append(*ep, t)
+	}
+	return nil
+}
+
+// ProtoWriter writes a trace in the binary format described in
+// trace.proto. Unlike the JSON format written by Writer, messages can
+// be decoded without parsing text and by any language with protocol
+// buffer support, which makes the format suitable for streaming
+// experience from distributed actors to a learner written in another
+// language.
+type ProtoWriter struct {
+	buf      *bufio.Writer
+	encoding Encoding
+}
+
+// NewProtoWriter returns a new ProtoWriter which writes to w. The
+// header h is written immediately.
+func NewProtoWriter(w io.Writer, h Header) (*ProtoWriter, error) {
+	if err := h.Encoding.validate(h); err != nil {
+		return nil, fmt.Errorf("newProtoWriter: %v", err)
+	}
+	pw := &ProtoWriter{buf: bufio.NewWriter(w), encoding: h.Encoding}
+
+	b, _ := h.MarshalBinary()
+	if err := pw.writeMessage(b); err != nil {
+		return nil, fmt.Errorf("newProtoWriter: could not write header: %v",
+			err)
+	}
+	return pw, nil
+}
+
+// Write writes a single transition to the trace
+func (w *ProtoWriter) Write(t Transition) error {
+	b, err := t.marshal(w.encoding)
+	if err != nil {
+		return fmt.Errorf("write: %v", err)
+	}
+	if err := w.writeMessage(b); err != nil {
+		return fmt.Errorf("write: %v", err)
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the underlying io.Writer
+func (w *ProtoWriter) Flush() error {
+	return w.buf.Flush()
+}
+
+// writeMessage writes a length-delimited message
+func (w *ProtoWriter) writeMessage(b []byte) error {
+	if _, err := w.buf.Write(appendUvarint(nil, uint64(len(b)))); err != nil {
+		return err
+	}
+	_, err := w.buf.Write(b)
+	return err
+}
+
+// ProtoReader reads a trace in the binary format written by
+// ProtoWriter
+type ProtoReader struct {
+	buf    *bufio.Reader
+	header Header
+}
+
+// NewProtoReader returns a new ProtoReader which reads from r. The
+// header of the trace is read immediately.
+func NewProtoReader(r io.Reader) (*ProtoReader, error) {
+	pr := &ProtoReader{buf: bufio.NewReader(r)}
+
+	b, err := pr.readMessage()
+	if err != nil {
+		return nil, fmt.Errorf("newProtoReader: could not read header: %v",
+			err)
+	}
+	if err := pr.header.UnmarshalBinary(b); err != nil {
+		return nil, fmt.Errorf("newProtoReader: could not read header: %v",
+			err)
+	}
+	if err := pr.header.Encoding.validate(pr.header); err != nil {
+		return nil, fmt.Errorf("newProtoReader: %v", err)
+	}
+	return pr, nil
+}
+
+// Header returns the header of the trace
+func (r *ProtoReader) Header() Header {
+	return r.header
+}
+
+// Read reads the next transition from the trace. When no transitions
+// remain, Read returns io.EOF.
+func (r *ProtoReader) Read() (Transition, error) {
+	var t Transition
+	b, err := r.readMessage()
+	if err != nil {
+		if err == io.EOF {
+			return t, err
+		}
+		return t, fmt.Errorf("read: %v", err)
+	}
+
+	size := -1
+	if r.header.Encoding == Bitpack {
+		size = r.header.stateSize()
+	}
+	if err := t.unmarshal(b, size); err != nil {
+		return t, fmt.Errorf("read: %v", err)
+	}
+	return t, nil
+}
+
+// readMessage reads a length-delimited message. If the stream ends
+// before the message begins, io.EOF is returned.
+func (r *ProtoReader) readMessage() ([]byte, error) {
+	n, err := readUvarint(r.buf)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxMessageSize {
+		return nil, fmt.Errorf("message of %v bytes exceeds maximum of %v",
+			n, maxMessageSize)
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.buf, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+// readUvarint reads a varint from r. If r is empty, io.EOF is
+// returned, while if r ends within the varint, io.ErrUnexpectedEOF is
+// returned.
+func readUvarint(r io.ByteReader) (uint64, error) {
+	var x uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF && shift > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		x |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return x, nil
+		}
+	}
+	return 0, fmt.Errorf("varint overflows 64 bits")
+}
@@ -0,0 +1,235 @@
+package trace
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// transitions are transitions with zero, negative, and positive fields
+var transitions = []Transition{
+	{},
+	{Episode: 1, Step: 2, State: []float64{1, 0, 0, 1}, Action: 3,
+		Reward: 1, Terminal: true},
+	{Episode: -1, Step: -7, State: []float64{0, 0, 0, 0}, Action: -2,
+		Reward: -0.5},
+	{Episode: math.MaxInt32 + 1, Step: math.MinInt32 - 1,
+		State: []float64{-1.5, 0.25, 1e6, 0}, Action: math.MinInt32,
+		Reward: math.Copysign(0, -1)},
+	{State: []float64{0, 1, 1, 1}, Action: math.MaxInt32,
+		Reward: math.Inf(-1), Terminal: true},
+}
+
+// sameTransition returns whether a and b are equal, treating the
+// signs of zero rewards as significant
+func sameTransition(a, b Transition) bool {
+	return reflect.DeepEqual(a, b) &&
+		math.Signbit(a.Reward) == math.Signbit(b.Reward)
+}
+
+// TestTransitionMarshalBinary checks that transitions are unchanged by
+// encoding and decoding
+func TestTransitionMarshalBinary(t *testing.T) {
+	for i, want := range transitions {
+		b, err := want.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Transition
+		if err := got.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if !sameTransition(got, want) {
+			t.Errorf("transition %v: got %+v, want %+v", i, got, want)
+		}
+	}
+
+	packed, err := transitions[1].marshal(Bitpack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Transition
+	if err := got.UnmarshalBinary(packed); err == nil {
+		t.Error("expected error decoding a bit-packed state without a " +
+			"ProtoReader")
+	}
+}
+
+// TestTransitionWireFormat checks the encoding of a transition against
+// bytes encoded by hand from trace.proto, and that the unpacked
+// encoding of repeated fields and unknown fields are accepted
+func TestTransitionWireFormat(t *testing.T) {
+	tr := Transition{Episode: 1, State: []float64{1, 0}, Action: -1,
+		Reward: 0.5, Terminal: true}
+	want := []byte{
+		0x08, 0x01, // episode
+		0x1a, 0x08, // state, packed
+		0x00, 0x00, 0x80, 0x3f, 0x00, 0x00, 0x00, 0x00,
+		0x20, // action, sign-extended to 64 bits
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01,
+		0x29, // reward
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xe0, 0x3f,
+		0x30, 0x01, // terminal
+	}
+	got, err := tr.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got encoding % x, want % x", got, want)
+	}
+
+	unpacked := []byte{
+		0x1d, 0x00, 0x00, 0x80, 0x3f, // state[0]
+		0x45, 0x01, 0x02, 0x03, 0x04, // unknown fixed32 field 8
+		0x1d, 0x00, 0x00, 0x00, 0x00, // state[1]
+		0x52, 0x01, 0xff, // unknown bytes field 10
+		0x08, 0x01, // episode
+	}
+	var decoded Transition
+	if err := decoded.UnmarshalBinary(unpacked); err != nil {
+		t.Fatal(err)
+	}
+	if w := (Transition{Episode: 1, State: []float64{1, 0}}); !reflect.
+		DeepEqual(decoded, w) {
+		t.Errorf("got %+v, want %+v", decoded, w)
+	}
+
+	for _, b := range [][]byte{
+		{0x08},                         // truncated varint
+		{0x29, 0x00, 0x00},             // truncated double
+		{0x1a, 0x03, 0x00, 0x00},       // truncated packed floats
+		{0x1a, 0x02, 0x00, 0x00},       // packed floats of invalid length
+		{0x0d, 0x00, 0x00, 0x00, 0x00}, // episode with wrong wire type
+		{0x00, 0x00},                   // field 0
+	} {
+		if err := decoded.UnmarshalBinary(b); err == nil {
+			t.Errorf("% x: expected error", b)
+		}
+	}
+}
+
+// TestHeaderMarshalBinary checks that headers are unchanged by encoding
+// and decoding
+func TestHeaderMarshalBinary(t *testing.T) {
+	for _, want := range []Header{
+		{},
+		{Game: "Breakout", Shape: []int{4, 10, 10}, Seed: 3,
+			StickyActionsProb: 0.1, DifficultyRamping: true},
+		{Game: "Space Invaders", Shape: []int{6, 10, 10}, Seed: -42,
+			Encoding: Bitpack},
+	} {
+		b, err := want.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Header
+		if err := got.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got header %+v, want %+v", got, want)
+		}
+	}
+}
+
+// TestEpisodeMarshalBinary checks that episodes, including empty
+// transitions, are unchanged by encoding and decoding
+func TestEpisodeMarshalBinary(t *testing.T) {
+	for _, want := range []Episode{nil, {{}}, transitions} {
+		b, err := want.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Episode
+		if err := got.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("got %v transitions, want %v", len(got), len(want))
+		}
+		for i := range want {
+			if !sameTransition(got[i], want[i]) {
+				t.Errorf("transition %v: got %+v, want %+v", i, got[i],
+					want[i])
+			}
+		}
+	}
+}
+
+// TestProtoWriter checks that traces written by a ProtoWriter are read
+// back unchanged by a ProtoReader, with both encodings
+func TestProtoWriter(t *testing.T) {
+	for _, encoding := range []Encoding{Float, Bitpack} {
+		h := Header{Game: "Breakout", Shape: []int{1, 2, 2}, Seed: -1,
+			Encoding: encoding}
+		want := []Transition{transitions[1], transitions[4]}
+		if encoding == Float {
+			want = transitions
+		}
+
+		var buf bytes.Buffer
+		w, err := NewProtoWriter(&buf, h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tr := range want {
+			if err := w.Write(tr); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Truncating the trace within the last message is an error
+		truncated := buf.Bytes()[:buf.Len()-1]
+		r, err := NewProtoReader(bytes.NewReader(truncated))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < len(want)-1; i++ {
+			if _, err := r.Read(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := r.Read(); err == nil || err == io.EOF {
+			t.Errorf("%q: got error %v reading a truncated trace",
+				encoding, err)
+		}
+
+		r, err = NewProtoReader(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.Header(); !reflect.DeepEqual(got, h) {
+			t.Errorf("%q: got header %+v, want %+v", encoding, got, h)
+		}
+		for i, tr := range want {
+			got, err := r.Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sameTransition(got, tr) {
+				t.Errorf("%q: transition %v: got %+v, want %+v", encoding,
+					i, got, tr)
+			}
+		}
+		if _, err := r.Read(); err != io.EOF {
+			t.Errorf("%q: got error %v at the end of the trace, want EOF",
+				encoding, err)
+		}
+	}
+
+	// Only binary states can be bit-packed
+	w, err := NewProtoWriter(io.Discard, Header{Shape: []int{4},
+		Encoding: Bitpack})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(transitions[3]); err == nil {
+		t.Error("expected error bit-packing a non-binary state")
+	}
+}
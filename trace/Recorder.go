@@ -0,0 +1,103 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// TransitionWriter writes transitions to a trace. It is implemented by
+// Writer and ProtoWriter.
+type TransitionWriter interface {
+	Write(Transition) error
+	Flush() error
+}
+
+// Recorder wraps an environment and records each transition taken in
+// the environment to a trace. Recorder satisfies the goatar.Env
+// interface.
+type Recorder struct {
+	goatar.Env
+	w       TransitionWriter
+	episode int
+	step    int
+}
+
+// NewRecorder returns a new Recorder which records transitions in env
+// to w
+func NewRecorder(env goatar.Env, w TransitionWriter) *Recorder {
+	return &Recorder{
+		Env: env,
+		w:   w,
+	}
+}
+
+// Act takes one environmental step given some action a, records the
+// transition, and returns the reward for the action as well as
+// whether the episode is finished.
+func (r *Recorder) Act(a int) (float64, bool, error) {
+	state, err := r.Env.State()
+	if err != nil {
+		return 0, false, fmt.Errorf("act: %v", err)
+	}
+
+	// State may be cached by the environment, so store a copy of it
+	s := make([]float64, len(state))
+	copy(s, state)
+
+	reward, done, err := r.Env.Act(a)
+	if err != nil {
+		return reward, done, fmt.Errorf("act: %v", err)
+	}
+
+	t := Transition{
+		Episode:  r.episode,
+		Step:     r.step,
+		State:    s,
+		Action:   a,
+		Reward:   reward,
+		Terminal: done,
+	}
+	if err := r.w.Write(t); err != nil {
+		return reward, done, fmt.Errorf("act: %v", err)
+	}
+	r.step++
+
+	return reward, done, nil
+}
+
+// ActCtx is like Act, but does not step the environment if ctx is
+// done. In that case, the transitions recorded so far are flushed to
+// the underlying trace, so that a cancelled recording is not left
+// partially buffered, and the returned error wraps ctx.Err().
+func (r *Recorder) ActCtx(ctx context.Context, a int) (float64, bool,
+	error) {
+	if err := ctx.Err(); err != nil {
+		if ferr := r.w.Flush(); ferr != nil {
+			return 0, false, fmt.Errorf("actCtx: %v", ferr)
+		}
+		return 0, false, fmt.Errorf("actCtx: %w", err)
+	}
+
+	reward, done, err := r.Act(a)
+	if err != nil {
+		return reward, done, fmt.Errorf("actCtx: %v", err)
+	}
+	return reward, done, nil
+}
+
+// Reset resets the environment to some starting state and begins
+// recording a new episode
+func (r *Recorder) Reset() {
+	r.Env.Reset()
+	if r.step > 0 {
+		r.episode++
+		r.step = 0
+	}
+}
+
+// Flush writes any buffered transitions to the underlying trace
+func (r *Recorder) Flush() error {
+	return r.w.Flush()
+}
@@ -0,0 +1,111 @@
+package trace
+
+// Thrift compact protocol types, as used in Parquet file metadata
+const (
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// thriftEncoder appends a struct in the Thrift compact protocol, which
+// is used to encode the metadata of Parquet files. Only the types
+// needed by ParquetWriter are supported.
+type thriftEncoder struct {
+	buf []byte
+
+	// last holds the id of the last field written in each open struct,
+	// innermost last, since field ids are encoded as deltas
+	last []int
+}
+
+// newThriftEncoder returns a new thriftEncoder with an open top-level
+// struct
+func newThriftEncoder() *thriftEncoder {
+	return &thriftEncoder{last: []int{0}}
+}
+
+// field appends the header of a field
+func (e *thriftEncoder) field(id, typ int) {
+	top := len(e.last) - 1
+	if delta := id - e.last[top]; delta > 0 && delta <= 15 {
+		e.buf = append(e.buf, byte(delta<<4|typ))
+	} else {
+		e.buf = append(e.buf, byte(typ))
+		e.buf = appendUvarint(e.buf, zigzag(int64(id)))
+	}
+	e.last[top] = id
+}
+
+// i32 appends an i32 or enum field
+func (e *thriftEncoder) i32(id int, v int32) {
+	e.field(id, compactI32)
+	e.buf = appendUvarint(e.buf, zigzag(int64(v)))
+}
+
+// i64 appends an i64 field
+func (e *thriftEncoder) i64(id int, v int64) {
+	e.field(id, compactI64)
+	e.buf = appendUvarint(e.buf, zigzag(v))
+}
+
+// string appends a string field
+func (e *thriftEncoder) string(id int, s string) {
+	e.field(id, compactBinary)
+	e.listString(s)
+}
+
+// beginStruct appends the header of a struct field, whose fields
+// follow until endStruct is called
+func (e *thriftEncoder) beginStruct(id int) {
+	e.field(id, compactStruct)
+	e.last = append(e.last, 0)
+}
+
+// endStruct ends the innermost open struct
+func (e *thriftEncoder) endStruct() {
+	e.buf = append(e.buf, 0)
+	e.last = e.last[:len(e.last)-1]
+}
+
+// beginList appends the header of a list field of n elements of the
+// given type, which must then be appended with the list methods
+func (e *thriftEncoder) beginList(id, n, typ int) {
+	e.field(id, compactList)
+	if n < 15 {
+		e.buf = append(e.buf, byte(n<<4|typ))
+	} else {
+		e.buf = append(e.buf, byte(0xf0|typ))
+		e.buf = appendUvarint(e.buf, uint64(n))
+	}
+}
+
+// listI32 appends an i32 or enum element of a list
+func (e *thriftEncoder) listI32(v int32) {
+	e.buf = appendUvarint(e.buf, zigzag(int64(v)))
+}
+
+// listString appends a string element of a list
+func (e *thriftEncoder) listString(s string) {
+	e.buf = appendUvarint(e.buf, uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// listStruct begins a struct element of a list, whose fields follow
+// until endStruct is called
+func (e *thriftEncoder) listStruct() {
+	e.last = append(e.last, 0)
+}
+
+// bytes ends the top-level struct and returns the encoding
+func (e *thriftEncoder) bytes() []byte {
+	e.endStruct()
+	return e.buf
+}
+
+// zigzag maps signed integers to unsigned integers so that integers of
+// small magnitude have short varint encodings
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
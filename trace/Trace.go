@@ -0,0 +1,187 @@
+// Package trace implements the GoAtar trace format, which is used to
+// store trajectories of interaction with an environment, such as
+// human demonstrations or the experience of an agent.
+//
+// A trace is a stream of newline-delimited JSON objects. The first
+// object in the stream is a Header describing the environment which
+// generated the trace. Each following object is a single Transition.
+//
+// Traces can also be written in a binary protocol buffer format,
+// described by the schema in trace.proto, with ProtoWriter and read
+// with ProtoReader. Headers, Transitions, and Episodes implement
+// encoding.BinaryMarshaler using the same schema, so that they can be
+// sent individually over the network, or encoded with encoding/gob.
+// For analysis, ParquetWriter writes traces as Apache Parquet files,
+// which can be loaded directly as data frames by pandas or polars.
+//
+// State observations are stored as numbers, unless the Encoding of the
+// header is Bitpack, in which case binary state observations are
+// packed one bit per element. Readers unpack each state as its
+// transition is read, so that large bit-packed datasets can be
+// streamed without being unpacked in full.
+package trace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Header describes the environment which generated a trace
+type Header struct {
+	Game              string  `json:"game"`
+	Shape             []int   `json:"shape"`
+	Seed              int64   `json:"seed"`
+	StickyActionsProb float64 `json:"sticky_actions_prob"`
+	DifficultyRamping bool    `json:"difficulty_ramping"`
+
+	// Encoding is the encoding of state observations, which requires
+	// Shape to be set if it is Bitpack
+	Encoding Encoding `json:"encoding,omitempty"`
+}
+
+// Transition is a single step of interaction with an environment.
+// State is the state observation in which Action was taken, and Reward
+// and Terminal are the outcome of taking Action.
+type Transition struct {
+	Episode  int       `json:"episode"`
+	Step     int       `json:"step"`
+	State    []float64 `json:"state"`
+	Action   int       `json:"action"`
+	Reward   float64   `json:"reward"`
+	Terminal bool      `json:"terminal"`
+}
+
+// packedTransition is a Transition whose state observation is
+// bit-packed, as stored in JSON traces with the Bitpack encoding. The
+// packed state is encoded in base64.
+type packedTransition struct {
+	Episode   int     `json:"episode"`
+	Step      int     `json:"step"`
+	StateBits []byte  `json:"state_bits"`
+	Action    int     `json:"action"`
+	Reward    float64 `json:"reward"`
+	Terminal  bool    `json:"terminal"`
+}
+
+// Writer writes a trace to an underlying io.Writer
+type Writer struct {
+	buf      *bufio.Writer
+	enc      *json.Encoder
+	encoding Encoding
+}
+
+// NewWriter returns a new Writer which writes to w. The header h is
+// written immediately.
+func NewWriter(w io.Writer, h Header) (*Writer, error) {
+	if err := h.Encoding.validate(h); err != nil {
+		return nil, fmt.Errorf("newWriter: %v", err)
+	}
+
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+
+	if err := enc.Encode(h); err != nil {
+		return nil, fmt.Errorf("newWriter: could not write header: %v", err)
+	}
+
+	return &Writer{buf: buf, enc: enc, encoding: h.Encoding}, nil
+}
+
+// Write writes a single transition to the trace
+func (w *Writer) Write(t Transition) error {
+	var v interface{} = t
+	if w.encoding == Bitpack {
+		bits, err := packBits(t.State)
+		if err != nil {
+			return fmt.Errorf("write: %v", err)
+		}
+		v = packedTransition{
+			Episode:   t.Episode,
+			Step:      t.Step,
+			StateBits: bits,
+			Action:    t.Action,
+			Reward:    t.Reward,
+			Terminal:  t.Terminal,
+		}
+	}
+
+	if err := w.enc.Encode(v); err != nil {
+		return fmt.Errorf("write: %v", err)
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the underlying io.Writer
+func (w *Writer) Flush() error {
+	return w.buf.Flush()
+}
+
+// Reader reads a trace from an underlying io.Reader
+type Reader struct {
+	dec    *json.Decoder
+	header Header
+}
+
+// NewReader returns a new Reader which reads from r. The header of the
+// trace is read immediately.
+func NewReader(r io.Reader) (*Reader, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	var h Header
+	if err := dec.Decode(&h); err != nil {
+		return nil, fmt.Errorf("newReader: could not read header: %v", err)
+	}
+	if err := h.Encoding.validate(h); err != nil {
+		return nil, fmt.Errorf("newReader: %v", err)
+	}
+
+	return &Reader{dec: dec, header: h}, nil
+}
+
+// Header returns the header of the trace
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Read reads the next transition from the trace. When no transitions
+// remain, Read returns io.EOF.
+func (r *Reader) Read() (Transition, error) {
+	if r.header.Encoding == Bitpack {
+		return r.readPacked()
+	}
+
+	var t Transition
+	if err := r.dec.Decode(&t); err != nil {
+		if err == io.EOF {
+			return t, err
+		}
+		return t, fmt.Errorf("read: %v", err)
+	}
+	return t, nil
+}
+
+// readPacked reads the next transition from a bit-packed trace
+func (r *Reader) readPacked() (Transition, error) {
+	var p packedTransition
+	if err := r.dec.Decode(&p); err != nil {
+		if err == io.EOF {
+			return Transition{}, err
+		}
+		return Transition{}, fmt.Errorf("read: %v", err)
+	}
+
+	state, err := unpackBits(p.StateBits, r.header.stateSize())
+	if err != nil {
+		return Transition{}, fmt.Errorf("read: %v", err)
+	}
+	return Transition{
+		Episode:  p.Episode,
+		Step:     p.Step,
+		State:    state,
+		Action:   p.Action,
+		Reward:   p.Reward,
+		Terminal: p.Terminal,
+	}, nil
+}
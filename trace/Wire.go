@@ -0,0 +1,274 @@
+package trace
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Protocol buffer wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// encoder appends fields to a protocol buffer message. As in proto3,
+// fields with default values are not written.
+type encoder struct {
+	buf []byte
+}
+
+// tag appends the tag of a field
+func (e *encoder) tag(field, wireType int) {
+	e.buf = appendUvarint(e.buf, uint64(field)<<3|uint64(wireType))
+}
+
+// int64 appends an int32, int64, or bool field
+func (e *encoder) int64(field int, v int64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.buf = appendUvarint(e.buf, uint64(v))
+}
+
+// bool appends a bool field
+func (e *encoder) bool(field int, v bool) {
+	if v {
+		e.int64(field, 1)
+	}
+}
+
+// double appends a double field
+func (e *encoder) double(field int, v float64) {
+	if v == 0 && !math.Signbit(v) {
+		return
+	}
+	e.tag(field, wireFixed64)
+	e.buf = appendUint64(e.buf, math.Float64bits(v))
+}
+
+// bytes appends a string, bytes, or embedded message field
+func (e *encoder) bytes(field int, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	e.tag(field, wireBytes)
+	e.buf = appendUvarint(e.buf, uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// message appends an embedded message field, which is written even if
+// the message is empty
+func (e *encoder) message(field int, b []byte) {
+	e.tag(field, wireBytes)
+	e.buf = appendUvarint(e.buf, uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// packedInt32 appends a packed repeated int32 field
+func (e *encoder) packedInt32(field int, v []int) {
+	if len(v) == 0 {
+		return
+	}
+	var packed []byte
+	for _, x := range v {
+		packed = appendUvarint(packed, uint64(int64(x)))
+	}
+	e.bytes(field, packed)
+}
+
+// packedFloat appends a packed repeated float field. Values are
+// converted to float32.
+func (e *encoder) packedFloat(field int, v []float64) {
+	if len(v) == 0 {
+		return
+	}
+	e.tag(field, wireBytes)
+	e.buf = appendUvarint(e.buf, uint64(4*len(v)))
+	for _, x := range v {
+		e.buf = appendUint32(e.buf,
+			math.Float32bits(float32(x)))
+	}
+}
+
+// decoder reads the fields of a protocol buffer message
+type decoder struct {
+	buf []byte
+}
+
+// done returns whether all fields have been read
+func (d *decoder) done() bool {
+	return len(d.buf) == 0
+}
+
+// next reads the tag of the next field
+func (d *decoder) next() (field, wireType int, err error) {
+	tag, err := d.uvarint()
+	if err != nil {
+		return 0, 0, err
+	}
+	field, wireType = int(tag>>3), int(tag&7)
+	if field <= 0 {
+		return 0, 0, fmt.Errorf("invalid field number %v", field)
+	}
+	return field, wireType, nil
+}
+
+// uvarint reads a varint
+func (d *decoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid varint")
+	}
+	d.buf = d.buf[n:]
+	return v, nil
+}
+
+// fixed64 reads a fixed-width 64-bit value
+func (d *decoder) fixed64() (uint64, error) {
+	if len(d.buf) < 8 {
+		return 0, fmt.Errorf("unexpected end of message")
+	}
+	v := binary.LittleEndian.Uint64(d.buf)
+	d.buf = d.buf[8:]
+	return v, nil
+}
+
+// fixed32 reads a fixed-width 32-bit value
+func (d *decoder) fixed32() (uint32, error) {
+	if len(d.buf) < 4 {
+		return 0, fmt.Errorf("unexpected end of message")
+	}
+	v := binary.LittleEndian.Uint32(d.buf)
+	d.buf = d.buf[4:]
+	return v, nil
+}
+
+// bytes reads a length-delimited value
+func (d *decoder) bytes() ([]byte, error) {
+	n, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(d.buf)) {
+		return nil, fmt.Errorf("unexpected end of message")
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b, nil
+}
+
+// varintField reads the value of a varint field
+func (d *decoder) varintField(wireType int) (uint64, error) {
+	if wireType != wireVarint {
+		return 0, fmt.Errorf("expected wire type %v but got %v",
+			wireVarint, wireType)
+	}
+	return d.uvarint()
+}
+
+// doubleField reads the value of a double field
+func (d *decoder) doubleField(wireType int) (float64, error) {
+	if wireType != wireFixed64 {
+		return 0, fmt.Errorf("expected wire type %v but got %v",
+			wireFixed64, wireType)
+	}
+	v, err := d.fixed64()
+	return math.Float64frombits(v), err
+}
+
+// bytesField reads the value of a length-delimited field
+func (d *decoder) bytesField(wireType int) ([]byte, error) {
+	if wireType != wireBytes {
+		return nil, fmt.Errorf("expected wire type %v but got %v",
+			wireBytes, wireType)
+	}
+	return d.bytes()
+}
+
+// int32s reads a repeated int32 field, appending its values to v. Both
+// packed and unpacked encodings are accepted.
+func (d *decoder) int32s(wireType int, v []int) ([]int, error) {
+	if wireType == wireVarint {
+		x, err := d.uvarint()
+		return append(v, int(int32(x))), err
+	}
+
+	packed, err := d.bytesField(wireType)
+	if err != nil {
+		return v, err
+	}
+	p := decoder{buf: packed}
+	for !p.done() {
+		x, err := p.uvarint()
+		if err != nil {
+			return v, err
+		}
+		v = append(v, int(int32(x)))
+	}
+	return v, nil
+}
+
+// floats reads a repeated float field, appending its values to v. Both
+// packed and unpacked encodings are accepted.
+func (d *decoder) floats(wireType int, v []float64) ([]float64, error) {
+	if wireType == wireFixed32 {
+		x, err := d.fixed32()
+		return append(v, float64(math.Float32frombits(x))), err
+	}
+
+	packed, err := d.bytesField(wireType)
+	if err != nil {
+		return v, err
+	}
+	if len(packed)%4 != 0 {
+		return v, fmt.Errorf("invalid packed float length %v", len(packed))
+	}
+	for i := 0; i < len(packed); i += 4 {
+		x := binary.LittleEndian.Uint32(packed[i:])
+		v = append(v, float64(math.Float32frombits(x)))
+	}
+	return v, nil
+}
+
+// skip skips the value of a field with the given wire type
+func (d *decoder) skip(wireType int) error {
+	var err error
+	switch wireType {
+	case wireVarint:
+		_, err = d.uvarint()
+	case wireFixed64:
+		_, err = d.fixed64()
+	case wireBytes:
+		_, err = d.bytes()
+	case wireFixed32:
+		_, err = d.fixed32()
+	default:
+		err = fmt.Errorf("unsupported wire type %v", wireType)
+	}
+	return err
+}
+
+// appendUvarint appends the varint encoding of x to buf
+func appendUvarint(buf []byte, x uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], x)
+	return append(buf, b[:n]...)
+}
+
+// appendUint64 appends the little-endian encoding of x to buf
+func appendUint64(buf []byte, x uint64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], x)
+	return append(buf, b[:]...)
+}
+
+// appendUint32 appends the little-endian encoding of x to buf
+func appendUint32(buf []byte, x uint32) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], x)
+	return append(buf, b[:]...)
+}
@@ -0,0 +1,194 @@
+// Package video exports episodes of GoAtar environments as videos.
+//
+// Videos are encoded with ffmpeg when it is installed, which supports
+// MP4 and WebM output. Without ffmpeg, videos can still be written as
+// animated GIFs using a pure-Go encoder.
+package video
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Encoder encodes a sequence of equally sized frames as a video
+type Encoder interface {
+	// WriteFrame adds a frame to the video
+	WriteFrame(img *image.RGBA) error
+
+	// Close finishes writing the video
+	Close() error
+}
+
+// HasFFmpeg returns whether ffmpeg is installed
+func HasFFmpeg() bool {
+	_, err := exec.LookPath("ffmpeg")
+	return err == nil
+}
+
+// NewEncoder returns an Encoder which writes a video with the given
+// frame rate and frame size to path. The format is determined by the
+// extension of path: ".gif" uses the pure-Go GIF encoder, while any
+// other extension, such as ".mp4" or ".webm", requires ffmpeg.
+func NewEncoder(path string, fps float64, w, h int) (Encoder, error) {
+	if fps <= 0 {
+		return nil, fmt.Errorf("newEncoder: frame rate must be positive "+
+			"but got %v", fps)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".gif") {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("newEncoder: %v", err)
+		}
+		return NewGIF(f, fps), nil
+	}
+
+	if !HasFFmpeg() {
+		return nil, fmt.Errorf("newEncoder: ffmpeg is required to write "+
+			"%v, use a .gif file instead", path)
+	}
+	enc, err := NewFFmpeg(path, fps, w, h)
+	if err != nil {
+		return nil, fmt.Errorf("newEncoder: %v", err)
+	}
+	return enc, nil
+}
+
+// FFmpeg encodes videos by piping raw frames to an ffmpeg process
+type FFmpeg struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	w, h  int
+}
+
+// NewFFmpeg starts an ffmpeg process which writes a video with the
+// given frame rate and frame size to path. ffmpeg chooses the video
+// format based on the extension of path.
+func NewFFmpeg(path string, fps float64, w, h int) (*FFmpeg, error) {
+	args := []string{
+		"-y", "-loglevel", "error",
+		"-f", "rawvideo", "-pix_fmt", "rgba",
+		"-s", fmt.Sprintf("%vx%v", w, h),
+		"-r", fmt.Sprint(fps),
+		"-i", "-",
+		// Most codecs require even dimensions
+		"-vf", "pad=ceil(iw/2)*2:ceil(ih/2)*2",
+	}
+	if strings.EqualFold(filepath.Ext(path), ".mp4") {
+		args = append(args, "-pix_fmt", "yuv420p")
+	}
+	args = append(args, path)
+
+	cmd := exec.Command("ffmpeg", args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("newFFmpeg: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("newFFmpeg: %v", err)
+	}
+
+	return &FFmpeg{cmd: cmd, stdin: stdin, w: w, h: h}, nil
+}
+
+// WriteFrame adds a frame to the video
+func (f *FFmpeg) WriteFrame(img *image.RGBA) error {
+	if img.Bounds().Dx() != f.w || img.Bounds().Dy() != f.h {
+		return fmt.Errorf("writeFrame: frame size %vx%v does not match "+
+			"video size %vx%v", img.Bounds().Dx(), img.Bounds().Dy(), f.w,
+			f.h)
+	}
+
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		row := img.Pix[img.PixOffset(img.Bounds().Min.X, y):img.PixOffset(
+			img.Bounds().Max.X, y)]
+		if _, err := f.stdin.Write(row); err != nil {
+			return fmt.Errorf("writeFrame: %v", err)
+		}
+	}
+	return nil
+}
+
+// Close finishes writing the video and waits for ffmpeg to exit
+func (f *FFmpeg) Close() error {
+	if err := f.stdin.Close(); err != nil {
+		return fmt.Errorf("close: %v", err)
+	}
+	if err := f.cmd.Wait(); err != nil {
+		return fmt.Errorf("close: %v", err)
+	}
+	return nil
+}
+
+// GIF encodes videos as animated GIFs
+type GIF struct {
+	w     io.WriteCloser
+	delay int // Delay between frames in 100ths of a second
+	anim  gif.GIF
+}
+
+// NewGIF returns a new GIF encoder which writes an animated GIF with
+// the given frame rate to w. GIF frame delays have a resolution of
+// 10ms, so the frame rate is approximate.
+func NewGIF(w io.WriteCloser, fps float64) *GIF {
+	delay := int(100/fps + 0.5)
+	if delay < 1 {
+		delay = 1
+	}
+	return &GIF{w: w, delay: delay}
+}
+
+// WriteFrame adds a frame to the video. Frames are buffered in memory
+// until Close is called.
+func (g *GIF) WriteFrame(img *image.RGBA) error {
+	g.anim.Image = append(g.anim.Image, paletted(img))
+	g.anim.Delay = append(g.anim.Delay, g.delay)
+	return nil
+}
+
+// Close writes the animated GIF and closes the underlying writer
+func (g *GIF) Close() error {
+	if len(g.anim.Image) > 0 {
+		if err := gif.EncodeAll(g.w, &g.anim); err != nil {
+			g.w.Close()
+			return fmt.Errorf("close: %v", err)
+		}
+	}
+
+	if err := g.w.Close(); err != nil {
+		return fmt.Errorf("close: %v", err)
+	}
+	return nil
+}
+
+// paletted converts img to a paletted image. Rendered frames use few
+// colours, so the palette is usually exact. Frames with more than 256
+// colours are approximated with the Plan 9 palette.
+func paletted(img *image.RGBA) *image.Paletted {
+	var p color.Palette
+	seen := make(map[color.RGBA]bool)
+	for i := 0; i+3 < len(img.Pix) && len(p) <= 256; i += 4 {
+		c := color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]}
+		if !seen[c] {
+			seen[c] = true
+			p = append(p, c)
+		}
+	}
+	if len(p) > 256 {
+		p = palette.Plan9
+	}
+
+	out := image.NewPaletted(img.Bounds(), p)
+	draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Src)
+	return out
+}
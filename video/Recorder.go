@@ -0,0 +1,182 @@
+package video
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/render"
+)
+
+// Options configures the videos written by a Recorder. The heads-up
+// display shows the step count, return, last action, and difficulty
+// ramp index below each frame.
+type Options struct {
+	FPS     float64        // Frame rate, 10 if 0
+	Palette render.Palette // Colours, render.DefaultPalette if nil
+	HUD     bool           // Whether to show a heads-up display
+
+	// Render configures the cell size, grid lines, and z-order of
+	// frames. The cell size is 16 pixels if 0. If the z-order is nil
+	// and the environment is a *goatar.Environment, the z-order of its
+	// game is used.
+	Render render.Options
+}
+
+// Recorder wraps an environment and writes a video of each episode.
+// The first frame of each video is the initial state of the episode,
+// and each following frame is the state after a step.
+type Recorder struct {
+	goatar.Env
+	pattern string
+	opts    Options
+
+	enc           Encoder
+	episode       int
+	step          int
+	episodeReturn float64
+	lastAction    string
+}
+
+// NewRecorder returns a new Recorder which records videos of env.
+// The video of episode i is written to fmt.Sprintf(pattern, i), e.g.
+// with pattern "episode-%03d.mp4". See NewEncoder for the supported
+// formats.
+func NewRecorder(env goatar.Env, pattern string, opts Options) *Recorder {
+	if opts.FPS == 0 {
+		opts.FPS = 10
+	}
+	if opts.Palette == nil {
+		opts.Palette = render.DefaultPalette
+	}
+	if opts.Render.CellSize == 0 {
+		opts.Render.CellSize = 16
+	}
+	if e, ok := env.(*goatar.Environment); ok && opts.Render.ZOrder == nil {
+		opts.Render.ZOrder = e.ZOrder()
+	}
+
+	return &Recorder{Env: env, pattern: pattern, opts: opts}
+}
+
+// Act takes one environmental step given some action a and adds the
+// resulting state to the video of the current episode. The video is
+// finished when the episode ends.
+func (r *Recorder) Act(a int) (float64, bool, error) {
+	if r.enc == nil {
+		if err := r.start(); err != nil {
+			return 0, false, fmt.Errorf("act: %v", err)
+		}
+	}
+
+	reward, done, err := r.Env.Act(a)
+	if err != nil {
+		return reward, done, err
+	}
+	r.step++
+	r.episodeReturn += reward
+	r.lastAction = actionName(r.Env, a)
+
+	if err := r.writeFrame(); err != nil {
+		return reward, done, fmt.Errorf("act: %v", err)
+	}
+	if done {
+		if err := r.finish(); err != nil {
+			return reward, done, fmt.Errorf("act: %v", err)
+		}
+	}
+
+	return reward, done, nil
+}
+
+// Reset resets the environment to some starting state. The video of
+// an unfinished episode is finished before resetting.
+func (r *Recorder) Reset() {
+	if r.enc != nil {
+		r.finish()
+	}
+	if r.step > 0 {
+		r.episode++
+	}
+	r.step = 0
+	r.episodeReturn = 0
+	r.lastAction = ""
+	r.Env.Reset()
+}
+
+// Close finishes the video of the current episode, if any
+func (r *Recorder) Close() error {
+	if r.enc == nil {
+		return nil
+	}
+	if err := r.finish(); err != nil {
+		return fmt.Errorf("close: %v", err)
+	}
+	return nil
+}
+
+// start begins the video of the current episode with the current
+// state
+func (r *Recorder) start() error {
+	frame, err := r.frame()
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf(r.pattern, r.episode)
+	enc, err := NewEncoder(path, r.opts.FPS, frame.Bounds().Dx(),
+		frame.Bounds().Dy())
+	if err != nil {
+		return err
+	}
+	r.enc = enc
+
+	return r.enc.WriteFrame(frame)
+}
+
+// finish finishes the video of the current episode
+func (r *Recorder) finish() error {
+	enc := r.enc
+	r.enc = nil
+	return enc.Close()
+}
+
+// writeFrame adds the current state to the video
+func (r *Recorder) writeFrame() error {
+	frame, err := r.frame()
+	if err != nil {
+		return err
+	}
+	return r.enc.WriteFrame(frame)
+}
+
+// frame renders the current state
+func (r *Recorder) frame() (*image.RGBA, error) {
+	state, err := r.Env.State()
+	if err != nil {
+		return nil, err
+	}
+	img := render.Rasterize(state, r.Env.StateShape(), r.opts.Palette,
+		r.opts.Render)
+
+	if r.opts.HUD {
+		hud := render.HUD{
+			Step:   r.step,
+			Return: r.episodeReturn,
+			Action: r.lastAction,
+			Ramp:   r.Env.DifficultyRamp(),
+		}
+		img = render.Overlay(img, hud, r.opts.Palette)
+	}
+	return img, nil
+}
+
+// actionName returns the name of action a in env
+func actionName(env goatar.Env, a int) string {
+	if env, ok := env.(interface{ ActionSet() []goatar.Action }); ok {
+		if actions := env.ActionSet(); a >= 0 && a < len(actions) {
+			return actions[a].String()
+		}
+	}
+	return goatar.Action(a).String()
+}
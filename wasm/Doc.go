@@ -0,0 +1,24 @@
+// Package wasm exposes GoAtar environments to JavaScript when compiled
+// to WebAssembly, so that games can run entirely client-side in the
+// browser.
+//
+// Register installs a global goatar object with the following
+// functions:
+//
+//	goatar.CreateEnv(game, {sticky, ramping, seed}) // returns an env id
+//	goatar.Step(id, action)                          // returns {reward, done}
+//	goatar.Reset(id)
+//	goatar.State(id)                                 // returns a Float64Array
+//	goatar.StateShape(id)                            // returns [channels, rows, cols]
+//	goatar.NumActions(id)
+//	goatar.RenderToCanvas(id, canvas, cellSize)
+//	goatar.CloseEnv(id)
+//
+// The options passed to CreateEnv may be omitted, in which case sticky
+// actions are disabled, difficulty ramping is enabled, and the seed is
+// 0. Functions which fail return a JavaScript Error rather than
+// throwing.
+//
+// Package wasm only contains code when built with GOOS=js and
+// GOARCH=wasm. See cmd/goatar-wasm for a complete in-browser demo.
+package wasm
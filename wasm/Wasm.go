@@ -0,0 +1,219 @@
+//go:build js && wasm
+// +build js,wasm
+
+package wasm
+
+import (
+	"fmt"
+	"sync"
+	"syscall/js"
+
+	"github.com/samuelfneumann/goatar"
+	"github.com/samuelfneumann/goatar/render"
+)
+
+// envs holds the environments created from JavaScript, keyed by id
+var (
+	mu     sync.Mutex
+	envs   = make(map[int]*goatar.Environment)
+	nextID int
+)
+
+// Register installs the global goatar object in JavaScript. Register
+// should be called once from the main function of a WebAssembly
+// program, which must then block so that the functions remain
+// callable.
+func Register() {
+	js.Global().Set("goatar", js.ValueOf(map[string]interface{}{
+		"CreateEnv":      js.FuncOf(createEnv),
+		"Step":           js.FuncOf(step),
+		"Reset":          js.FuncOf(reset),
+		"State":          js.FuncOf(state),
+		"StateShape":     js.FuncOf(stateShape),
+		"NumActions":     js.FuncOf(numActions),
+		"RenderToCanvas": js.FuncOf(renderToCanvas),
+		"CloseEnv":       js.FuncOf(closeEnv),
+	}))
+}
+
+// createEnv creates a new environment and returns its id
+func createEnv(_ js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return jsError(fmt.Errorf("createEnv: expected game name"))
+	}
+	name, err := goatar.ParseGameName(args[0].String())
+	if err != nil {
+		return jsError(fmt.Errorf("createEnv: %v", err))
+	}
+
+	sticky, ramping, seed := 0.0, true, int64(0)
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		opts := args[1]
+		if v := opts.Get("sticky"); v.Type() == js.TypeNumber {
+			sticky = v.Float()
+		}
+		if v := opts.Get("ramping"); v.Type() == js.TypeBoolean {
+			ramping = v.Bool()
+		}
+		if v := opts.Get("seed"); v.Type() == js.TypeNumber {
+			seed = int64(v.Int())
+		}
+	}
+
+	env, err := goatar.New(name, sticky, ramping, seed)
+	if err != nil {
+		return jsError(fmt.Errorf("createEnv: %v", err))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	id := nextID
+	nextID++
+	envs[id] = env
+	return id
+}
+
+// step takes one step in an environment with some action
+func step(_ js.Value, args []js.Value) interface{} {
+	env, err := lookup(args)
+	if err != nil {
+		return jsError(fmt.Errorf("step: %v", err))
+	}
+	if len(args) < 2 || args[1].Type() != js.TypeNumber {
+		return jsError(fmt.Errorf("step: expected action"))
+	}
+
+	reward, done, err := env.Act(args[1].Int())
+	if err != nil {
+		return jsError(fmt.Errorf("step: %v", err))
+	}
+	return map[string]interface{}{
+		"reward": reward,
+		"done":   done,
+	}
+}
+
+// reset resets an environment
+func reset(_ js.Value, args []js.Value) interface{} {
+	env, err := lookup(args)
+	if err != nil {
+		return jsError(fmt.Errorf("reset: %v", err))
+	}
+	env.Reset()
+	return nil
+}
+
+// state returns the state observation of an environment as a
+// Float64Array
+func state(_ js.Value, args []js.Value) interface{} {
+	env, err := lookup(args)
+	if err != nil {
+		return jsError(fmt.Errorf("state: %v", err))
+	}
+	obs, err := env.State()
+	if err != nil {
+		return jsError(fmt.Errorf("state: %v", err))
+	}
+
+	array := js.Global().Get("Float64Array").New(len(obs))
+	for i, v := range obs {
+		array.SetIndex(i, v)
+	}
+	return array
+}
+
+// stateShape returns the shape of the state observations of an
+// environment
+func stateShape(_ js.Value, args []js.Value) interface{} {
+	env, err := lookup(args)
+	if err != nil {
+		return jsError(fmt.Errorf("stateShape: %v", err))
+	}
+
+	shape := env.StateShape()
+	values := make([]interface{}, len(shape))
+	for i, s := range shape {
+		values[i] = s
+	}
+	return values
+}
+
+// numActions returns the number of actions of an environment
+func numActions(_ js.Value, args []js.Value) interface{} {
+	env, err := lookup(args)
+	if err != nil {
+		return jsError(fmt.Errorf("numActions: %v", err))
+	}
+	return env.NumActions()
+}
+
+// renderToCanvas draws the current state of an environment onto a
+// canvas element, resizing the canvas to fit
+func renderToCanvas(_ js.Value, args []js.Value) interface{} {
+	env, err := lookup(args)
+	if err != nil {
+		return jsError(fmt.Errorf("renderToCanvas: %v", err))
+	}
+	if len(args) < 2 || args[1].Type() != js.TypeObject {
+		return jsError(fmt.Errorf("renderToCanvas: expected canvas"))
+	}
+	canvas := args[1]
+
+	cellSize := 20
+	if len(args) > 2 && args[2].Type() == js.TypeNumber {
+		cellSize = args[2].Int()
+	}
+	if cellSize <= 0 {
+		return jsError(fmt.Errorf("renderToCanvas: cell size must be "+
+			"positive but got %v", cellSize))
+	}
+
+	obs, err := env.State()
+	if err != nil {
+		return jsError(fmt.Errorf("renderToCanvas: %v", err))
+	}
+	img := render.Rasterize(obs, env.StateShape(), render.DefaultPalette,
+		render.Options{CellSize: cellSize, ZOrder: env.ZOrder()})
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	pixels := js.Global().Get("Uint8ClampedArray").New(len(img.Pix))
+	js.CopyBytesToJS(pixels, img.Pix)
+	data := js.Global().Get("ImageData").New(pixels, w, h)
+
+	canvas.Set("width", w)
+	canvas.Set("height", h)
+	canvas.Call("getContext", "2d").Call("putImageData", data, 0, 0)
+	return nil
+}
+
+// closeEnv releases an environment
+func closeEnv(_ js.Value, args []js.Value) interface{} {
+	if _, err := lookup(args); err != nil {
+		return jsError(fmt.Errorf("closeEnv: %v", err))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	delete(envs, args[0].Int())
+	return nil
+}
+
+// lookup returns the environment whose id is the first argument
+func lookup(args []js.Value) (*goatar.Environment, error) {
+	if len(args) < 1 || args[0].Type() != js.TypeNumber {
+		return nil, fmt.Errorf("expected environment id")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	env, ok := envs[args[0].Int()]
+	if !ok {
+		return nil, fmt.Errorf("no environment with id %v", args[0].Int())
+	}
+	return env, nil
+}
+
+// jsError converts err to a JavaScript Error
+func jsError(err error) js.Value {
+	return js.Global().Get("Error").New(err.Error())
+}
@@ -0,0 +1,205 @@
+package wrappers
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// actionSetter is implemented by environments with a custom action
+// set, such as goatar.Environment
+type actionSetter interface {
+	ActionSet() []goatar.Action
+}
+
+// DelayedAction wraps an environment so that each action is taken k
+// steps after it is chosen, simulating the latency between an agent
+// and a real system. On each call to Act, the given action is queued
+// and the action chosen k steps earlier is taken instead.
+//
+// Actions never leak across episode boundaries. On the first k steps
+// of an episode, before any queued action is due, the no-op action is
+// taken. Calling Reset discards any actions which have not yet been
+// taken.
+type DelayedAction struct {
+	goatar.Env
+	delay  int
+	noOp   int   // Action taken before any queued action is due
+	queued []int // Actions waiting to be taken, oldest first
+}
+
+// ActionDelay returns a new DelayedAction which delays the actions
+// taken in env by k steps. A delay of 0 takes actions immediately. If
+// env has a custom action set which does not contain goatar.NoOp,
+// action 0 is taken before any queued action is due.
+func ActionDelay(env goatar.Env, k int) (*DelayedAction, error) {
+	if k < 0 {
+		return nil, fmt.Errorf("actionDelay: delay must be non-negative "+
+			"but got %v", k)
+	}
+
+	noOp := 0
+	if e, ok := env.(actionSetter); ok {
+		for i, a := range e.ActionSet() {
+			if a == goatar.NoOp {
+				noOp = i
+				break
+			}
+		}
+	}
+
+	return &DelayedAction{
+		Env:    env,
+		delay:  k,
+		noOp:   noOp,
+		queued: make([]int, 0, k+1),
+	}, nil
+}
+
+// Act queues action a and takes the action chosen k steps earlier,
+// returning the reward for that action and whether the episode has
+// ended
+func (d *DelayedAction) Act(a int) (float64, bool, error) {
+	if a < 0 || a >= d.NumActions() {
+		return -1, false, fmt.Errorf("act: action %v ∉ [0, %v)", a,
+			d.NumActions())
+	}
+	d.queued = append(d.queued, a)
+
+	action := d.noOp
+	if len(d.queued) > d.delay {
+		action = d.queued[0]
+		copy(d.queued, d.queued[1:])
+		d.queued = d.queued[:len(d.queued)-1]
+	}
+
+	r, done, err := d.Env.Act(action)
+	if err != nil {
+		return r, done, fmt.Errorf("act: %v", err)
+	}
+	return r, done, nil
+}
+
+// Reset resets the environment to some starting state and discards
+// any actions which have not yet been taken
+func (d *DelayedAction) Reset() {
+	d.queued = d.queued[:0]
+	d.Env.Reset()
+}
+
+// Delay returns the number of steps by which actions are delayed
+func (d *DelayedAction) Delay() int {
+	return d.delay
+}
+
+// DelayedObservation wraps an environment so that state observations
+// are k steps stale, simulating the latency between a real system and
+// an agent. After each call to Act, the state observation is that of
+// the wrapped environment k steps earlier.
+//
+// Observations never leak across episode boundaries. On the first k
+// steps of an episode, the observation is the first observation of
+// the episode. Calling Reset discards all stale observations.
+type DelayedObservation struct {
+	goatar.Env
+	delay int
+
+	// stale holds the observations of the last k+1 steps, oldest
+	// first, or is empty if no step has been taken this episode
+	stale [][]float64
+}
+
+// ObservationDelay returns a new DelayedObservation which delays the
+// state observations of env by k steps. A delay of 0 returns current
+// observations.
+func ObservationDelay(env goatar.Env, k int) (*DelayedObservation, error) {
+	if k < 0 {
+		return nil, fmt.Errorf("observationDelay: delay must be "+
+			"non-negative but got %v", k)
+	}
+
+	return &DelayedObservation{
+		Env:   env,
+		delay: k,
+		stale: make([][]float64, 0, k+2),
+	}, nil
+}
+
+// Act takes one environmental step given some action a and returns
+// the reward for that action and whether the episode has ended
+func (d *DelayedObservation) Act(a int) (float64, bool, error) {
+	// Record the first observation of the episode, which is stale
+	// until k steps have been taken
+	if len(d.stale) == 0 {
+		if err := d.record(); err != nil {
+			return -1, false, fmt.Errorf("act: %v", err)
+		}
+	}
+
+	r, done, err := d.Env.Act(a)
+	if err != nil {
+		return r, done, fmt.Errorf("act: %v", err)
+	}
+	if err := d.record(); err != nil {
+		return r, done, fmt.Errorf("act: %v", err)
+	}
+
+	if len(d.stale) > d.delay+1 {
+		d.stale[0] = nil
+		d.stale = append(d.stale[:0], d.stale[1:]...)
+	}
+	return r, done, nil
+}
+
+// record appends the current observation of the wrapped environment
+// to the stale observations
+func (d *DelayedObservation) record() error {
+	state, err := d.Env.State()
+	if err != nil {
+		return err
+	}
+	d.stale = append(d.stale, state)
+	return nil
+}
+
+// State returns the state observation of k steps earlier
+func (d *DelayedObservation) State() ([]float64, error) {
+	if len(d.stale) == 0 {
+		return d.Env.State()
+	}
+	return append([]float64(nil), d.stale[0]...), nil
+}
+
+// Channel returns the channel at index i of the state observation of
+// k steps earlier
+func (d *DelayedObservation) Channel(i int) ([]float64, error) {
+	if len(d.stale) == 0 {
+		return d.Env.Channel(i)
+	}
+
+	if i >= d.NChannels() {
+		return nil, fmt.Errorf("channel: index out of range [%v] with "+
+			"length %v", i, d.NChannels())
+	} else if i < 0 {
+		return nil, fmt.Errorf("channel: invalid slice index %v (index "+
+			"must be non-negative)", i)
+	}
+
+	size := d.Shape().ChannelSize()
+	return append([]float64(nil), d.stale[0][size*i:size*(i+1)]...), nil
+}
+
+// Reset resets the environment to some starting state and discards
+// all stale observations
+func (d *DelayedObservation) Reset() {
+	for i := range d.stale {
+		d.stale[i] = nil
+	}
+	d.stale = d.stale[:0]
+	d.Env.Reset()
+}
+
+// Delay returns the number of steps by which observations are delayed
+func (d *DelayedObservation) Delay() int {
+	return d.delay
+}
@@ -0,0 +1,196 @@
+package wrappers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// actionSetStub is a stubEnv with a custom action set
+type actionSetStub struct {
+	*stubEnv
+	actionSet []goatar.Action
+}
+
+func (s actionSetStub) ActionSet() []goatar.Action {
+	return s.actionSet
+}
+
+// TestDelayedAction checks that actions are taken k steps late, that
+// the no-op action is taken before any queued action is due, and that
+// no actions leak across episode boundaries
+func TestDelayedAction(t *testing.T) {
+	tests := []struct {
+		name  string
+		delay int
+
+		// episodes holds the actions chosen in each episode, after
+		// which Reset is called
+		episodes [][]int
+
+		// want holds the actions taken in the wrapped environment
+		want []int
+	}{
+		{
+			name:     "no delay",
+			delay:    0,
+			episodes: [][]int{{1, 2, 3}, {4, 5}},
+			want:     []int{1, 2, 3, 4, 5},
+		},
+		{
+			name:     "delay",
+			delay:    2,
+			episodes: [][]int{{1, 2, 3, 4}, {5, 4, 3, 2}},
+			want:     []int{0, 0, 1, 2, 0, 0, 5, 4},
+		},
+		{
+			name:     "reset discards queued",
+			delay:    1,
+			episodes: [][]int{{1, 2}, {3, 4, 5, 1}},
+			want:     []int{0, 1, 0, 3, 4, 5},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := newStub(0, 0, 0, 0)
+			d, err := ActionDelay(env, test.delay)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, actions := range test.episodes {
+				for _, a := range actions {
+					if _, _, err := d.Act(a); err != nil {
+						t.Fatal(err)
+					}
+				}
+				d.Reset()
+			}
+
+			if !reflect.DeepEqual(env.actions, test.want) {
+				t.Errorf("got actions %v, want %v", env.actions, test.want)
+			}
+		})
+	}
+
+	if _, err := ActionDelay(newStub(1), -1); err == nil {
+		t.Error("expected error for negative delay")
+	}
+	d, err := ActionDelay(newStub(1), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := d.Act(goatar.NumActions); err == nil {
+		t.Error("expected error for invalid action")
+	}
+}
+
+// TestDelayedActionNoOp checks that the no-op action is found in a
+// custom action set
+func TestDelayedActionNoOp(t *testing.T) {
+	env := newStub(0, 0)
+	d, err := ActionDelay(actionSetStub{env, []goatar.Action{goatar.Fire,
+		goatar.NoOp, goatar.Left}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.Act(2)
+	d.Act(0)
+	if want := []int{1, 2}; !reflect.DeepEqual(env.actions, want) {
+		t.Errorf("got actions %v, want %v", env.actions, want)
+	}
+}
+
+// TestDelayedObservation checks that observations are k steps stale,
+// that the first observation of each episode is returned until k steps
+// have been taken, and that no observations leak across episode
+// boundaries
+func TestDelayedObservation(t *testing.T) {
+	tests := []struct {
+		name  string
+		delay int
+
+		// steps holds the number of steps taken in each episode, after
+		// which Reset is called
+		steps []int
+
+		// want holds the observed number of steps taken in the wrapped
+		// environment, which every element of the stub's observations
+		// is set to, after each step of each episode
+		want [][]float64
+	}{
+		{
+			name:  "no delay",
+			delay: 0,
+			steps: []int{3, 2},
+			want:  [][]float64{{1, 2, 3}, {1, 2}},
+		},
+		{
+			name:  "delay",
+			delay: 2,
+			steps: []int{4, 4},
+			want:  [][]float64{{0, 0, 1, 2}, {0, 0, 1, 2}},
+		},
+		{
+			name:  "delay longer than episode",
+			delay: 5,
+			steps: []int{4, 2},
+			want:  [][]float64{{0, 0, 0, 0}, {0, 0}},
+		},
+		{
+			name:  "reset discards stale",
+			delay: 1,
+			steps: []int{2, 4},
+			want:  [][]float64{{0, 1}, {0, 1, 2, 3}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := newStub(0, 0, 0, 0)
+			d, err := ObservationDelay(env, test.delay)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for episode, steps := range test.steps {
+				var got []float64
+				for i := 0; i < steps; i++ {
+					if _, _, err := d.Act(0); err != nil {
+						t.Fatal(err)
+					}
+					state, err := d.State()
+					if err != nil {
+						t.Fatal(err)
+					}
+					ch, err := d.Channel(1)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !reflect.DeepEqual(ch, state[3:]) {
+						t.Errorf("episode %v step %v: got channel %v of "+
+							"state %v", episode, i, ch, state)
+					}
+					got = append(got, state[0])
+				}
+				if !reflect.DeepEqual(got, test.want[episode]) {
+					t.Errorf("episode %v: got observations %v, want %v",
+						episode, got, test.want[episode])
+				}
+
+				d.Reset()
+				if state, _ := d.State(); state[0] != 0 {
+					t.Errorf("episode %v: got observation %v after reset, "+
+						"want 0", episode, state[0])
+				}
+			}
+		})
+	}
+
+	if _, err := ObservationDelay(newStub(1), -1); err == nil {
+		t.Error("expected error for negative delay")
+	}
+}
@@ -0,0 +1,124 @@
+package wrappers
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// Symmetric is implemented by environments which can describe how they
+// map onto themselves when the screen is flipped horizontally, such as
+// *goatar.Environment
+type Symmetric interface {
+	goatar.Env
+	HorizontalSymmetry() (goatar.Symmetry, error)
+}
+
+// HorizontallyFlipped wraps an environment so that state observations
+// are mirrored horizontally and the left and right actions are
+// swapped. Since the game is horizontally symmetric, the wrapped
+// environment is itself a valid environment, which makes it useful for
+// data augmentation and for agents which exploit symmetry.
+//
+// Horizontal flips are supported by Asterix, Breakout, and
+// SpaceInvaders. In SpaceInvaders, the channels of aliens moving left
+// and right are also swapped.
+type HorizontallyFlipped struct {
+	goatar.Env
+	symmetry goatar.Symmetry
+}
+
+// HorizontalFlip returns a new HorizontallyFlipped which mirrors env.
+// The environment must implement Symmetric and be horizontally
+// symmetric.
+func HorizontalFlip(env goatar.Env) (*HorizontallyFlipped, error) {
+	s, ok := env.(Symmetric)
+	if !ok {
+		return nil, fmt.Errorf("horizontalFlip: environment does not " +
+			"describe its symmetries")
+	}
+
+	symmetry, err := s.HorizontalSymmetry()
+	if err != nil {
+		return nil, fmt.Errorf("horizontalFlip: %v", err)
+	}
+
+	return &HorizontallyFlipped{Env: env, symmetry: symmetry}, nil
+}
+
+// Act takes one environmental step given some action a, where left and
+// right are mirrored, and returns the reward as well as whether the
+// episode is finished
+func (h *HorizontallyFlipped) Act(a int) (float64, bool, error) {
+	if a < 0 || a >= len(h.symmetry.Actions) {
+		return -1, false, fmt.Errorf("act: invalid action %v ∉ [0, %v)",
+			a, len(h.symmetry.Actions))
+	}
+
+	r, done, err := h.Env.Act(h.symmetry.Actions[a])
+	if err != nil {
+		return r, done, fmt.Errorf("act: %v", err)
+	}
+	return r, done, nil
+}
+
+// State returns the current horizontally flipped state observation
+func (h *HorizontallyFlipped) State() ([]float64, error) {
+	state, err := h.Env.State()
+	if err != nil {
+		return nil, fmt.Errorf("state: %v", err)
+	}
+
+	rows, cols := h.channelShape()
+	size := rows * cols
+	flipped := make([]float64, len(state))
+	for ch, src := range h.symmetry.Channels {
+		h.flip(flipped[size*ch:size*(ch+1)], state[size*src:size*(src+1)])
+	}
+	return flipped, nil
+}
+
+// Channel returns the channel at index i of the horizontally flipped
+// state observation
+func (h *HorizontallyFlipped) Channel(i int) ([]float64, error) {
+	if i >= h.NChannels() {
+		return nil, fmt.Errorf("channel: index out of range [%v] with "+
+			"length %v", i, h.NChannels())
+	} else if i < 0 {
+		return nil, fmt.Errorf("channel: invalid slice index %v (index "+
+			"must be non-negative)", i)
+	}
+
+	ch, err := h.Env.Channel(h.symmetry.Channels[i])
+	if err != nil {
+		return nil, fmt.Errorf("channel: %v", err)
+	}
+
+	flipped := make([]float64, len(ch))
+	h.flip(flipped, ch)
+	return flipped, nil
+}
+
+// HorizontalSymmetry returns the symmetry of the flipped environment,
+// which is the same as that of the wrapped environment
+func (h *HorizontallyFlipped) HorizontalSymmetry() (goatar.Symmetry, error) {
+	return h.symmetry, nil
+}
+
+// flip copies the channel src into dst, reversing the order of the
+// columns
+func (h *HorizontallyFlipped) flip(dst, src []float64) {
+	rows, cols := h.channelShape()
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			dst[r*cols+c] = src[r*cols+cols-1-c]
+		}
+	}
+}
+
+// channelShape returns the number of rows and columns in a single
+// channel
+func (h *HorizontallyFlipped) channelShape() (rows, cols int) {
+	shape := h.Env.Shape()
+	return shape.Rows, shape.Cols
+}
@@ -0,0 +1,109 @@
+package wrappers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// symmetricStub is a stubEnv which swaps its two channels and the left
+// and right actions under horizontal flips
+type symmetricStub struct {
+	*stubEnv
+}
+
+func (s symmetricStub) HorizontalSymmetry() (goatar.Symmetry, error) {
+	actions := make([]int, goatar.NumActions)
+	for i := range actions {
+		actions[i] = i
+	}
+	actions[goatar.Left], actions[goatar.Right] = int(goatar.Right),
+		int(goatar.Left)
+	return goatar.Symmetry{Actions: actions, Channels: []int{1, 0}}, nil
+}
+
+// TestHorizontalFlip checks that observations are mirrored with their
+// channels swapped, and that the left and right actions are swapped
+func TestHorizontalFlip(t *testing.T) {
+	env := newStub(0, 0, 0)
+	env.states = [][]float64{
+		{1, 2, 3, 4, 5, 6},
+		{1, 0, 0, 0, 0, 1},
+		{0, 1, 1, 0, 0, 0},
+	}
+	f, err := HorizontalFlip(symmetricStub{env})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actions := []goatar.Action{goatar.Left, goatar.Right, goatar.Fire}
+	want := [][]float64{
+		{6, 5, 4, 3, 2, 1},
+		{1, 0, 0, 0, 0, 1},
+		{0, 0, 0, 1, 1, 0},
+	}
+	for i, a := range actions {
+		if _, _, err := f.Act(int(a)); err != nil {
+			t.Fatal(err)
+		}
+		state, err := f.State()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(state, want[i]) {
+			t.Errorf("step %v: got state %v, want %v", i, state, want[i])
+		}
+
+		ch, err := f.Channel(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(ch, want[i][:3]) {
+			t.Errorf("step %v: got channel %v, want %v", i, ch, want[i][:3])
+		}
+	}
+
+	wantActions := []int{int(goatar.Right), int(goatar.Left),
+		int(goatar.Fire)}
+	if !reflect.DeepEqual(env.actions, wantActions) {
+		t.Errorf("got actions %v, want %v", env.actions, wantActions)
+	}
+
+	if _, _, err := f.Act(goatar.NumActions); err == nil {
+		t.Error("expected error for invalid action")
+	}
+	if _, err := HorizontalFlip(env); err == nil {
+		t.Error("expected error for environment without symmetries")
+	}
+}
+
+// TestHorizontalFlipBreakout checks that moving left in a flipped
+// Breakout moves the paddle right
+func TestHorizontalFlipBreakout(t *testing.T) {
+	env, err := goatar.New(goatar.Breakout, 0, true, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := HorizontalFlip(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paddle := func() int {
+		for _, o := range env.Objects() {
+			if o.Type == "paddle" {
+				return o.X
+			}
+		}
+		t.Fatal("no paddle")
+		return -1
+	}
+	x := paddle()
+	if _, _, err := f.Act(int(goatar.Left)); err != nil {
+		t.Fatal(err)
+	}
+	if got := paddle(); got != x+1 {
+		t.Errorf("got paddle at %v after moving left, want %v", got, x+1)
+	}
+}
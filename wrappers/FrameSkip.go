@@ -0,0 +1,135 @@
+package wrappers
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// SkippedFrames wraps an environment so that each action is repeated
+// for k steps of the wrapped environment, and the rewards of those
+// steps are summed. If the episode ends before k steps are taken, the
+// action is not repeated further.
+//
+// With max pooling, the state observation after a call to Act is the
+// element-wise maximum of the observations after each of the repeated
+// steps, as with the max pooling of frames in the Arcade Learning
+// Environment. Objects which move quickly, such as bullets, then
+// appear at every cell they passed through, rather than being missed
+// between returned observations.
+type SkippedFrames struct {
+	goatar.Env
+	skip    int
+	maxPool bool
+
+	// pooled is the element-wise maximum of the observations of the
+	// last call to Act, or nil if the observation is not pooled
+	pooled []float64
+}
+
+// FrameSkip returns a new SkippedFrames which repeats each action
+// taken in env k times. If maxPool is true, state observations are
+// max pooled over the repeated steps.
+func FrameSkip(env goatar.Env, k int, maxPool bool) (*SkippedFrames, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("frameSkip: number of steps must be "+
+			"positive but got %v", k)
+	}
+
+	return &SkippedFrames{Env: env, skip: k, maxPool: maxPool}, nil
+}
+
+// Act repeats action a for k steps, or until the episode ends, and
+// returns the sum of the rewards and whether the episode has ended
+func (s *SkippedFrames) Act(a int) (float64, bool, error) {
+	s.pooled = s.pooled[:0]
+
+	reward := 0.0
+	for i := 0; i < s.skip; i++ {
+		r, done, err := s.Env.Act(a)
+		if err != nil {
+			return reward, done, fmt.Errorf("act: %v", err)
+		}
+		reward += r
+
+		if s.maxPool {
+			if err := s.pool(); err != nil {
+				return reward, done, fmt.Errorf("act: %v", err)
+			}
+		}
+		if done {
+			return reward, done, nil
+		}
+	}
+	return reward, false, nil
+}
+
+// pool takes the element-wise maximum of the pooled observation and
+// the current observation of the wrapped environment
+func (s *SkippedFrames) pool() error {
+	state, err := s.Env.State()
+	if err != nil {
+		return err
+	}
+
+	if len(s.pooled) == 0 {
+		s.pooled = append(s.pooled, state...)
+		return nil
+	}
+	for i, v := range state {
+		if v > s.pooled[i] {
+			s.pooled[i] = v
+		}
+	}
+	return nil
+}
+
+// State returns the current state observation. With max pooling, this
+// is the element-wise maximum of the observations after each step of
+// the last call to Act.
+func (s *SkippedFrames) State() ([]float64, error) {
+	if len(s.pooled) == 0 {
+		return s.Env.State()
+	}
+	return append([]float64(nil), s.pooled...), nil
+}
+
+// Channel returns the channel at index i of the current state
+// observation
+func (s *SkippedFrames) Channel(i int) ([]float64, error) {
+	if len(s.pooled) == 0 {
+		return s.Env.Channel(i)
+	}
+
+	if i >= s.NChannels() {
+		return nil, fmt.Errorf("channel: index out of range [%v] with "+
+			"length %v", i, s.NChannels())
+	} else if i < 0 {
+		return nil, fmt.Errorf("channel: invalid slice index %v (index "+
+			"must be non-negative)", i)
+	}
+
+	size := s.Shape().ChannelSize()
+	return append([]float64(nil), s.pooled[size*i:size*(i+1)]...), nil
+}
+
+// Reset resets the environment to some starting state
+func (s *SkippedFrames) Reset() {
+	s.pooled = s.pooled[:0]
+	s.Env.Reset()
+}
+
+// RewardRange returns the minimum and maximum reward that can be
+// received on a single call to Act, which sums the rewards of up to k
+// steps
+func (s *SkippedFrames) RewardRange() (min, max float64) {
+	min, max = s.Env.RewardRange()
+	k := float64(s.skip)
+	return math.Min(min, k*min), math.Max(max, k*max)
+}
+
+// Skip returns the number of steps for which each action is repeated
+func (s *SkippedFrames) Skip() int {
+	return s.skip
+}
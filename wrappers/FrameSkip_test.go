@@ -0,0 +1,166 @@
+package wrappers
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestFrameSkip checks that rewards are summed over the skipped steps,
+// and that skipping stops at the end of each episode
+func TestFrameSkip(t *testing.T) {
+	tests := []struct {
+		name    string
+		skip    int
+		rewards []float64
+
+		// want and wantDone hold the rewards and terminals returned in
+		// each episode
+		want     []float64
+		wantDone []bool
+	}{
+		{
+			name:     "no skip",
+			skip:     1,
+			rewards:  []float64{1, 0, -1},
+			want:     []float64{1, 0, -1},
+			wantDone: []bool{false, false, true},
+		},
+		{
+			name:     "skip",
+			skip:     2,
+			rewards:  []float64{1, 2, 3, 4, 5},
+			want:     []float64{1 + 2, 3 + 4, 5},
+			wantDone: []bool{false, false, true},
+		},
+		{
+			name:     "skip whole episode",
+			skip:     4,
+			rewards:  []float64{1, 1, -1},
+			want:     []float64{1},
+			wantDone: []bool{true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := newStub(test.rewards...)
+			s, err := FrameSkip(env, test.skip, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for episode := 0; episode < 2; episode++ {
+				var got []float64
+				var gotDone []bool
+				for done := false; !done; {
+					var r float64
+					r, done, err = s.Act(0)
+					if err != nil {
+						t.Fatal(err)
+					}
+					got = append(got, r)
+					gotDone = append(gotDone, done)
+				}
+				if !reflect.DeepEqual(got, test.want) ||
+					!reflect.DeepEqual(gotDone, test.wantDone) {
+					t.Errorf("episode %v: got rewards %v and terminals %v, "+
+						"want %v and %v", episode, got, gotDone, test.want,
+						test.wantDone)
+				}
+				s.Reset()
+			}
+
+			if len(env.actions) != 2*len(test.rewards) {
+				t.Errorf("took %v steps, want %v", len(env.actions),
+					2*len(test.rewards))
+			}
+		})
+	}
+
+	if _, err := FrameSkip(newStub(1), 0, false); err == nil {
+		t.Error("expected error for non-positive skip")
+	}
+}
+
+// TestFrameSkipMaxPool checks that observations are max pooled over the
+// steps of the last call to Act only, and are not pooled after a reset
+func TestFrameSkipMaxPool(t *testing.T) {
+	states := [][]float64{
+		{1, 0, 0, 0, 0, 2},
+		{0, 1, 0, 0, 0, 1},
+		{0, 0, 1, 0, 0, 0},
+	}
+	tests := []struct {
+		maxPool bool
+		want    [][]float64
+	}{
+		{
+			maxPool: false,
+			want:    [][]float64{states[1], states[2]},
+		},
+		{
+			maxPool: true,
+			want: [][]float64{
+				{1, 1, 0, 0, 0, 2},
+				{0, 0, 1, 0, 0, 0},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		env := newStub(0, 0, 0)
+		env.states = states
+		s, err := FrameSkip(env, 2, test.maxPool)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for episode := 0; episode < 2; episode++ {
+			for step, want := range test.want {
+				if _, _, err := s.Act(0); err != nil {
+					t.Fatal(err)
+				}
+				state, err := s.State()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(state, want) {
+					t.Errorf("max pool %v episode %v step %v: got state %v, "+
+						"want %v", test.maxPool, episode, step, state, want)
+				}
+
+				ch, err := s.Channel(1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(ch, want[3:]) {
+					t.Errorf("max pool %v episode %v step %v: got channel "+
+						"%v, want %v", test.maxPool, episode, step, ch,
+						want[3:])
+				}
+			}
+
+			s.Reset()
+			state, err := s.State()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := make([]float64, 6); !reflect.DeepEqual(state, want) {
+				t.Errorf("max pool %v: got state %v after reset, want %v",
+					test.maxPool, state, want)
+			}
+		}
+	}
+}
+
+// TestFrameSkipRewardRange checks that the reward range covers the sum
+// of the rewards of the skipped steps
+func TestFrameSkipRewardRange(t *testing.T) {
+	s, err := FrameSkip(newStub(1), 3, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if min, max := s.RewardRange(); min != -3 || max != 3 {
+		t.Errorf("got reward range (%v, %v), want (-3, 3)", min, max)
+	}
+}
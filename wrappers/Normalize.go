@@ -0,0 +1,175 @@
+package wrappers
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// normalizeEpsilon is added to the variance of the discounted return
+// before scaling rewards, to avoid dividing by zero
+const normalizeEpsilon = 1e-8
+
+// RewardStats are the running statistics which a NormalizedReward uses
+// to scale rewards
+type RewardStats struct {
+	Mean  float64 // Running mean of the discounted return
+	Var   float64 // Running variance of the discounted return
+	Count float64 // Number of steps included in Mean and Var
+
+	// Return is the discounted return of the current episode so far
+	Return float64
+}
+
+// update includes the discounted return x in the running statistics
+func (s *RewardStats) update(x float64) {
+	count := s.Count + 1
+	delta := x - s.Mean
+	s.Mean += delta / count
+	s.Var = (s.Var*s.Count + delta*delta*s.Count/count) / count
+	s.Count = count
+}
+
+// NormalizedReward wraps an environment so that rewards are divided by
+// a running estimate of the standard deviation of the discounted
+// return, as with the NormalizeReward wrapper of Gym. This keeps the
+// scale of value targets roughly constant across games, whose rewards
+// differ in magnitude and frequency. The mean is not subtracted, so
+// the sign of each reward is kept.
+//
+// The statistics are updated on every step, including during
+// evaluation. They can be saved and restored with Stats and SetStats,
+// or together with the state of the wrapped environment with Snapshot
+// and Restore.
+type NormalizedReward struct {
+	goatar.Env
+	gamma float64
+	stats RewardStats
+}
+
+// NormalizeReward returns a new NormalizedReward which normalizes the
+// rewards of env using the return discounted by gamma
+func NormalizeReward(env goatar.Env, gamma float64) (*NormalizedReward,
+	error) {
+	if gamma < 0 || gamma > 1 {
+		return nil, fmt.Errorf("normalizeReward: discount %v ∉ [0, 1]",
+			gamma)
+	}
+
+	// As in Gym, the statistics start at a unit variance with a tiny
+	// count, so that early rewards are not scaled by extreme amounts
+	return &NormalizedReward{
+		Env:   env,
+		gamma: gamma,
+		stats: RewardStats{Var: 1, Count: 1e-4},
+	}, nil
+}
+
+// Act takes one environmental step given some action a and returns
+// the normalized reward as well as whether the episode is finished.
+func (n *NormalizedReward) Act(a int) (float64, bool, error) {
+	r, done, err := n.Env.Act(a)
+	if err != nil {
+		return r, done, fmt.Errorf("act: %v", err)
+	}
+
+	n.stats.Return = n.stats.Return*n.gamma + r
+	n.stats.update(n.stats.Return)
+	if done {
+		n.stats.Return = 0
+	}
+
+	return r / math.Sqrt(n.stats.Var+normalizeEpsilon), done, nil
+}
+
+// Reset resets the environment to some starting state and restarts the
+// discounted return. The running statistics are kept.
+func (n *NormalizedReward) Reset() {
+	n.stats.Return = 0
+	n.Env.Reset()
+}
+
+// RewardRange returns the minimum and maximum reward that can be
+// received on a single step. Since the scale of rewards changes as the
+// statistics are updated, rewards are unbounded in the direction of
+// any non-zero reward of the wrapped environment.
+func (n *NormalizedReward) RewardRange() (min, max float64) {
+	min, max = n.Env.RewardRange()
+	if min < 0 {
+		min = math.Inf(-1)
+	} else {
+		min = 0
+	}
+	if max > 0 {
+		max = math.Inf(1)
+	} else {
+		max = 0
+	}
+	return min, max
+}
+
+// Gamma returns the discount used to compute the discounted return
+func (n *NormalizedReward) Gamma() float64 {
+	return n.gamma
+}
+
+// Stats returns the current running statistics
+func (n *NormalizedReward) Stats() RewardStats {
+	return n.stats
+}
+
+// SetStats replaces the running statistics, e.g. to continue training
+// or to evaluate with the statistics of a previous run
+func (n *NormalizedReward) SetStats(stats RewardStats) error {
+	if stats.Var < 0 || stats.Count <= 0 {
+		return fmt.Errorf("setStats: invalid statistics with variance %v "+
+			"and count %v", stats.Var, stats.Count)
+	}
+	n.stats = stats
+	return nil
+}
+
+// snapshotter is implemented by environments whose state can be saved
+// and restored, such as goatar.Environment
+type snapshotter interface {
+	Snapshot() *goatar.Snapshot
+	Restore(*goatar.Snapshot) error
+}
+
+// NormalizedRewardSnapshot is a saved state of a NormalizedReward,
+// including the state of the environment it wraps
+type NormalizedRewardSnapshot struct {
+	env   *goatar.Snapshot
+	stats RewardStats
+}
+
+// Snapshot returns a snapshot of the current state of the wrapped
+// environment and the running statistics. The wrapped environment
+// must support snapshots, as goatar.Environment does.
+func (n *NormalizedReward) Snapshot() (*NormalizedRewardSnapshot, error) {
+	env, ok := n.Env.(snapshotter)
+	if !ok {
+		return nil, fmt.Errorf("snapshot: environment does not support " +
+			"snapshots")
+	}
+	return &NormalizedRewardSnapshot{
+		env:   env.Snapshot(),
+		stats: n.stats,
+	}, nil
+}
+
+// Restore returns the wrapped environment and the running statistics
+// to the state saved in s
+func (n *NormalizedReward) Restore(s *NormalizedRewardSnapshot) error {
+	env, ok := n.Env.(snapshotter)
+	if !ok {
+		return fmt.Errorf("restore: environment does not support " +
+			"snapshots")
+	}
+	if err := env.Restore(s.env); err != nil {
+		return fmt.Errorf("restore: %v", err)
+	}
+	n.stats = s.stats
+	return nil
+}
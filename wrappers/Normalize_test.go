@@ -0,0 +1,111 @@
+package wrappers
+
+import (
+	"math"
+	"testing"
+)
+
+// TestNormalizedReward checks the normalized rewards and running
+// statistics against hand-computed values over an episode boundary and
+// a reset in the middle of an episode
+func TestNormalizedReward(t *testing.T) {
+	type step struct {
+		reward float64 // Normalized reward
+		stats  RewardStats
+	}
+	tests := []struct {
+		name  string
+		gamma float64
+
+		// want holds the normalized reward and statistics after each
+		// step of an episode of rewards 2, 0, and 4, followed by the
+		// first step of the next episode, which is then reset
+		want []step
+	}{
+		{
+			name:  "discounted",
+			gamma: 0.5,
+			want: []step{
+				{2 / math.Sqrt(1.5), RewardStats{1, 1.5, 2, 2}},
+				{0, RewardStats{1, 1, 3, 1}},
+				{4 / math.Sqrt(3.046875), RewardStats{1.875, 3.046875, 4, 0}},
+				{2 / math.Sqrt(2.44), RewardStats{1.9, 2.44, 5, 2}},
+			},
+		},
+		{
+			name:  "undiscounted",
+			gamma: 0,
+			want: []step{
+				{2 / math.Sqrt(1.5), RewardStats{1, 1.5, 2, 2}},
+				{0, RewardStats{2. / 3, 11. / 9, 3, 0}},
+				{4 / math.Sqrt(3), RewardStats{1.5, 3, 4, 0}},
+				{2 / math.Sqrt(2.44), RewardStats{1.6, 2.44, 5, 2}},
+			},
+		},
+	}
+
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := NormalizeReward(newStub(2, 0, 4), test.gamma)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := n.SetStats(RewardStats{Var: 1, Count: 1}); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, want := range test.want {
+				r, done, err := n.Act(0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if done {
+					n.Reset()
+				}
+
+				got := n.Stats()
+				if !near(r, want.reward) || !near(got.Mean, want.stats.Mean) ||
+					!near(got.Var, want.stats.Var) ||
+					got.Count != want.stats.Count ||
+					!near(got.Return, want.stats.Return) {
+					t.Errorf("step %v: got reward %v and statistics %+v, "+
+						"want %v and %+v", i, r, got, want.reward, want.stats)
+				}
+			}
+
+			// Resetting restarts the return but keeps the statistics
+			want := n.Stats()
+			want.Return = 0
+			n.Reset()
+			if got := n.Stats(); got != want {
+				t.Errorf("got statistics %+v after reset, want %+v", got,
+					want)
+			}
+		})
+	}
+}
+
+// TestNormalizedRewardStats checks the initial statistics and that
+// invalid statistics and discounts are rejected
+func TestNormalizedRewardStats(t *testing.T) {
+	n, err := NormalizeReward(newStub(1), 0.99)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := n.Stats(), (RewardStats{Var: 1, Count: 1e-4}); got !=
+		want {
+		t.Errorf("got initial statistics %+v, want %+v", got, want)
+	}
+
+	for _, stats := range []RewardStats{{Var: -1, Count: 1}, {Var: 1}} {
+		if err := n.SetStats(stats); err == nil {
+			t.Errorf("statistics %+v: expected error", stats)
+		}
+	}
+	for _, gamma := range []float64{-0.1, 1.1} {
+		if _, err := NormalizeReward(newStub(1), gamma); err == nil {
+			t.Errorf("discount %v: expected error", gamma)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package wrappers
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// CroppedObservation wraps an environment so that state observations
+// contain only a subset of the channels of the wrapped environment's
+// state observations. Channel i of a cropped observation is channel
+// channels[i] of the wrapped environment's observation.
+type CroppedObservation struct {
+	goatar.Env
+	channels []int
+}
+
+// CropObservation returns a new CroppedObservation which keeps only
+// the given channels of env's state observations, in the given order.
+// Channels may not be repeated.
+func CropObservation(env goatar.Env, channels []int) (*CroppedObservation,
+	error) {
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("cropObservation: no channels")
+	}
+
+	seen := make(map[int]bool, len(channels))
+	for _, ch := range channels {
+		if ch < 0 || ch >= env.NChannels() {
+			return nil, fmt.Errorf("cropObservation: channel %v ∉ [0, %v)",
+				ch, env.NChannels())
+		}
+		if seen[ch] {
+			return nil, fmt.Errorf("cropObservation: repeated channel %v",
+				ch)
+		}
+		seen[ch] = true
+	}
+
+	return &CroppedObservation{
+		Env:      env,
+		channels: append([]int(nil), channels...),
+	}, nil
+}
+
+// State returns the current cropped state observation
+func (c *CroppedObservation) State() ([]float64, error) {
+	state, err := c.Env.State()
+	if err != nil {
+		return nil, fmt.Errorf("state: %v", err)
+	}
+
+	size := c.channelSize()
+	cropped := make([]float64, size*len(c.channels))
+	for i, ch := range c.channels {
+		copy(cropped[size*i:size*(i+1)], state[size*ch:size*(ch+1)])
+	}
+
+	return cropped, nil
+}
+
+// Shape returns the shape of cropped state observations
+func (c *CroppedObservation) Shape() goatar.Shape {
+	shape := c.Env.Shape()
+	shape.Channels = len(c.channels)
+	return shape
+}
+
+// StateShape returns the shape of cropped state observations as
+// (channels, rows, cols)
+func (c *CroppedObservation) StateShape() []int {
+	return c.Shape().Ints()
+}
+
+// NChannels returns the number of channels in cropped state
+// observations
+func (c *CroppedObservation) NChannels() int {
+	return len(c.channels)
+}
+
+// Channel returns the channel at index i of the cropped state
+// observation
+func (c *CroppedObservation) Channel(i int) ([]float64, error) {
+	if i >= c.NChannels() {
+		return nil, fmt.Errorf("channel: index out of range [%v] with "+
+			"length %v", i, c.NChannels())
+	} else if i < 0 {
+		return nil, fmt.Errorf("channel: invalid slice index %v (index "+
+			"must be non-negative)", i)
+	}
+
+	ch, err := c.Env.Channel(c.channels[i])
+	if err != nil {
+		return nil, fmt.Errorf("channel: %v", err)
+	}
+	return ch, nil
+}
+
+// Channels returns the channels of the wrapped environment which are
+// kept in cropped state observations
+func (c *CroppedObservation) Channels() []int {
+	return append([]int(nil), c.channels...)
+}
+
+// channelSize returns the number of elements in a single channel
+func (c *CroppedObservation) channelSize() int {
+	return c.Env.Shape().ChannelSize()
+}
@@ -0,0 +1,64 @@
+package wrappers
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestCroppedObservation checks that cropped observations hold the
+// chosen channels in the chosen order, across an episode boundary
+func TestCroppedObservation(t *testing.T) {
+	env := newStub(0, 0)
+	env.shape.Channels = 3
+	env.states = [][]float64{
+		{1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1},
+	}
+	c, err := CropObservation(env, []int{2, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.StateShape(); !reflect.DeepEqual(got, []int{2, 1, 3}) {
+		t.Errorf("got shape %v, want [2 1 3]", got)
+	}
+
+	want := [][]float64{
+		{7, 8, 9, 1, 2, 3},
+		{3, 2, 1, 9, 8, 7},
+	}
+	for episode := 0; episode < 2; episode++ {
+		for step := range want {
+			if _, _, err := c.Act(0); err != nil {
+				t.Fatal(err)
+			}
+			state, err := c.State()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(state, want[step]) {
+				t.Errorf("episode %v step %v: got state %v, want %v",
+					episode, step, state, want[step])
+			}
+
+			ch, err := c.Channel(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ch, want[step][3:]) {
+				t.Errorf("episode %v step %v: got channel %v, want %v",
+					episode, step, ch, want[step][3:])
+			}
+		}
+		c.Reset()
+	}
+
+	if _, err := c.Channel(2); err == nil {
+		t.Error("expected error for channel out of range")
+	}
+	for _, channels := range [][]int{nil, {0, 0}, {3}, {-1}} {
+		if _, err := CropObservation(env, channels); err == nil {
+			t.Errorf("channels %v: expected error", channels)
+		}
+	}
+}
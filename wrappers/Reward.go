@@ -0,0 +1,158 @@
+// Package wrappers implements wrappers around GoAtar environments.
+// Each wrapper satisfies the goatar.Env interface, so wrappers can be
+// nested and used anywhere an unwrapped goatar.Environment can be.
+package wrappers
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// DelayedReward wraps an environment so that rewards are delivered k
+// steps after the step on which they were generated. This is useful
+// for studying credit assignment.
+//
+// Rewards never leak across episode boundaries. When the wrapped
+// environment terminates, all pending rewards are delivered together
+// with the reward of the terminal step. Calling Reset discards any
+// rewards which have not yet been delivered.
+type DelayedReward struct {
+	goatar.Env
+	delay   int
+	pending []float64 // Rewards waiting to be delivered, oldest first
+}
+
+// DelayReward returns a new DelayedReward which delays the rewards of
+// env by k steps. A delay of 0 delivers rewards immediately.
+func DelayReward(env goatar.Env, k int) (*DelayedReward, error) {
+	if k < 0 {
+		return nil, fmt.Errorf("delayReward: delay must be non-negative "+
+			"but got %v", k)
+	}
+
+	return &DelayedReward{
+		Env:     env,
+		delay:   k,
+		pending: make([]float64, 0, k+1),
+	}, nil
+}
+
+// Act takes one environmental step given some action a and returns
+// the delayed reward as well as whether the episode is finished.
+func (d *DelayedReward) Act(a int) (float64, bool, error) {
+	r, done, err := d.Env.Act(a)
+	if err != nil {
+		return r, done, fmt.Errorf("act: %v", err)
+	}
+	d.pending = append(d.pending, r)
+
+	// Flush all pending rewards at the end of the episode
+	if done {
+		reward := 0.0
+		for _, r := range d.pending {
+			reward += r
+		}
+		d.pending = d.pending[:0]
+		return reward, done, nil
+	}
+
+	if len(d.pending) <= d.delay {
+		return 0.0, done, nil
+	}
+
+	reward := d.pending[0]
+	copy(d.pending, d.pending[1:])
+	d.pending = d.pending[:len(d.pending)-1]
+	return reward, done, nil
+}
+
+// Reset resets the environment to some starting state and discards
+// any rewards which have not yet been delivered
+func (d *DelayedReward) Reset() {
+	d.pending = d.pending[:0]
+	d.Env.Reset()
+}
+
+// RewardRange returns the minimum and maximum reward that can be
+// received on a single step. Since no reward is delivered on the first
+// steps of an episode and all pending rewards are delivered on the
+// terminal step, the range is wider than that of the wrapped
+// environment.
+func (d *DelayedReward) RewardRange() (min, max float64) {
+	min, max = d.Env.RewardRange()
+	steps := float64(d.delay + 1)
+	return math.Min(0, min*steps), math.Max(0, max*steps)
+}
+
+// Delay returns the number of steps by which rewards are delayed
+func (d *DelayedReward) Delay() int {
+	return d.delay
+}
+
+// NoisedReward wraps an environment so that zero-mean Gaussian noise
+// with standard deviation sigma is added to each reward.
+type NoisedReward struct {
+	goatar.Env
+	sigma float64
+	rng   *rand.Rand
+}
+
+// NoiseOption configures a NoisedReward
+type NoiseOption func(*NoisedReward)
+
+// WithNoiseSeed seeds the random number generator from which the noise
+// is drawn. By default, the generator is seeded with 0.
+func WithNoiseSeed(seed int64) NoiseOption {
+	return func(n *NoisedReward) {
+		n.rng.Seed(seed)
+	}
+}
+
+// NoisyReward returns a new NoisedReward which adds Gaussian noise
+// with standard deviation sigma to each reward of env
+func NoisyReward(env goatar.Env, sigma float64,
+	opts ...NoiseOption) (*NoisedReward, error) {
+	if sigma < 0 {
+		return nil, fmt.Errorf("noisyReward: standard deviation must be "+
+			"non-negative but got %v", sigma)
+	}
+
+	n := &NoisedReward{
+		Env:   env,
+		sigma: sigma,
+		rng:   rand.New(rand.NewSource(0)),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n, nil
+}
+
+// Act takes one environmental step given some action a and returns
+// the noisy reward as well as whether the episode is finished.
+func (n *NoisedReward) Act(a int) (float64, bool, error) {
+	r, done, err := n.Env.Act(a)
+	if err != nil {
+		return r, done, fmt.Errorf("act: %v", err)
+	}
+
+	return r + n.rng.NormFloat64()*n.sigma, done, nil
+}
+
+// RewardRange returns the minimum and maximum reward that can be
+// received on a single step. If sigma is non-zero, rewards are
+// unbounded.
+func (n *NoisedReward) RewardRange() (min, max float64) {
+	if n.sigma == 0 {
+		return n.Env.RewardRange()
+	}
+	return math.Inf(-1), math.Inf(1)
+}
+
+// Sigma returns the standard deviation of the reward noise
+func (n *NoisedReward) Sigma() float64 {
+	return n.sigma
+}
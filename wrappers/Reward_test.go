@@ -0,0 +1,140 @@
+package wrappers
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDelayedReward checks that rewards are delivered k steps late,
+// that pending rewards are flushed on the terminal step, and that no
+// rewards leak across episode boundaries
+func TestDelayedReward(t *testing.T) {
+	tests := []struct {
+		name    string
+		delay   int
+		rewards []float64
+		resetAt int // Step after which Reset is called, 0 if never
+
+		// want holds the rewards returned over two episodes
+		want []float64
+	}{
+		{
+			name:    "no delay",
+			delay:   0,
+			rewards: []float64{1, 2, 3},
+			want:    []float64{1, 2, 3, 1, 2, 3},
+		},
+		{
+			name:    "flush on terminal",
+			delay:   2,
+			rewards: []float64{1, 2, 3, 4},
+			want:    []float64{0, 0, 1, 2 + 3 + 4, 0, 0, 1, 9},
+		},
+		{
+			name:    "delay longer than episode",
+			delay:   5,
+			rewards: []float64{1, -1, 2},
+			want:    []float64{0, 0, 2, 0, 0, 2},
+		},
+		{
+			name:    "reset discards pending",
+			delay:   1,
+			rewards: []float64{1, 2, 3, 4},
+			resetAt: 2,
+			want:    []float64{0, 1, 0, 1, 2, 3 + 4},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := newStub(test.rewards...)
+			d, err := DelayReward(env, test.delay)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []float64
+			for episode := 0; episode < 2; episode++ {
+				for step := 1; ; step++ {
+					r, done, err := d.Act(0)
+					if err != nil {
+						t.Fatal(err)
+					}
+					got = append(got, r)
+					if done || (episode == 0 && step == test.resetAt) {
+						break
+					}
+				}
+				d.Reset()
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got rewards %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	if _, err := DelayReward(newStub(1), -1); err == nil {
+		t.Error("expected error for negative delay")
+	}
+}
+
+// TestDelayedRewardRange checks that the reward range widens to cover
+// the rewards flushed on the terminal step
+func TestDelayedRewardRange(t *testing.T) {
+	d, err := DelayReward(newStub(1), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if min, max := d.RewardRange(); min != -3 || max != 3 {
+		t.Errorf("got reward range (%v, %v), want (-3, 3)", min, max)
+	}
+}
+
+// TestNoisedReward checks that noise is reproducible from the seed,
+// which defaults to 0, and that no noise is added with a standard
+// deviation of 0
+func TestNoisedReward(t *testing.T) {
+	rewards := []float64{1, 0, -1, 0, 1}
+
+	exact, err := NoisyReward(newStub(rewards...), 0, WithNoiseSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range rewards {
+		if r, _, _ := exact.Act(0); r != want {
+			t.Errorf("step %v: got reward %v, want %v", i, r, want)
+		}
+	}
+
+	noisy := func(opts ...NoiseOption) []float64 {
+		n, err := NoisyReward(newStub(rewards...), 0.5, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []float64
+		for range rewards {
+			r, _, _ := n.Act(0)
+			got = append(got, r)
+		}
+		return got
+	}
+	first, second := noisy(WithNoiseSeed(3)), noisy(WithNoiseSeed(3))
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("noise is not reproducible: %v and %v", first, second)
+	}
+	if other := noisy(WithNoiseSeed(4)); reflect.DeepEqual(first, other) {
+		t.Errorf("got the same noise %v with different seeds", first)
+	}
+	if got, want := noisy(), noisy(WithNoiseSeed(0)); !reflect.DeepEqual(
+		got, want) {
+		t.Errorf("got noise %v by default, want %v with seed 0", got, want)
+	}
+	if reflect.DeepEqual(first, rewards) {
+		t.Errorf("no noise was added to rewards %v", rewards)
+	}
+
+	if _, err := NoisyReward(newStub(1), -1); err == nil {
+		t.Error("expected error for negative standard deviation")
+	}
+}
@@ -0,0 +1,90 @@
+package wrappers
+
+import (
+	"fmt"
+
+	"github.com/samuelfneumann/goatar"
+)
+
+// stubEnv is a scripted goatar.Env for testing wrappers. Each episode
+// lasts len(rewards) steps, and step i of an episode returns
+// rewards[i]. The state observation is states[i] after step i+1 of the
+// episode if states is set, and otherwise has every element set to the
+// number of steps taken in the episode. The actions taken are recorded.
+type stubEnv struct {
+	rewards []float64
+	states  [][]float64
+	shape   goatar.Shape
+
+	steps   int   // Steps taken in the current episode
+	actions []int // Actions taken over all episodes
+	resets  int
+}
+
+// newStub returns a new stubEnv with the given rewards and a shape of
+// 2 channels of 1 row and 3 columns
+func newStub(rewards ...float64) *stubEnv {
+	return &stubEnv{
+		rewards: rewards,
+		shape:   goatar.Shape{Channels: 2, Rows: 1, Cols: 3},
+	}
+}
+
+func (s *stubEnv) Act(a int) (float64, bool, error) {
+	if a < 0 || a >= s.NumActions() {
+		return -1, false, fmt.Errorf("act: invalid action %v", a)
+	}
+	if s.steps >= len(s.rewards) {
+		return 0, true, nil
+	}
+
+	s.actions = append(s.actions, a)
+	r := s.rewards[s.steps]
+	s.steps++
+	return r, s.steps == len(s.rewards), nil
+}
+
+func (s *stubEnv) State() ([]float64, error) {
+	if s.states != nil && s.steps > 0 {
+		return append([]float64(nil), s.states[s.steps-1]...), nil
+	}
+
+	state := make([]float64, s.shape.Size())
+	for i := range state {
+		state[i] = float64(s.steps)
+	}
+	return state, nil
+}
+
+func (s *stubEnv) Reset() {
+	s.steps = 0
+	s.resets++
+}
+
+func (s *stubEnv) Shape() goatar.Shape { return s.shape }
+func (s *stubEnv) StateShape() []int   { return s.shape.Ints() }
+func (s *stubEnv) NChannels() int      { return s.shape.Channels }
+
+func (s *stubEnv) Channel(i int) ([]float64, error) {
+	state, err := s.State()
+	if err != nil {
+		return nil, err
+	}
+	size := s.shape.ChannelSize()
+	return state[size*i : size*(i+1)], nil
+}
+
+func (s *stubEnv) NumActions() int         { return goatar.NumActions }
+func (s *stubEnv) MinimalActionSet() []int { return []int{0, 1, 3} }
+func (s *stubEnv) DifficultyRamp() int     { return 0 }
+func (s *stubEnv) GameName() string        { return "stub" }
+func (s *stubEnv) Seed(int64)              {}
+func (s *stubEnv) StateHash() uint64       { return uint64(s.steps) }
+
+func (s *stubEnv) FullActionSet() []int {
+	return []int{0, 1, 2, 3, 4, 5}
+}
+
+func (s *stubEnv) RewardRange() (min, max float64) {
+	return -1, 1
+}